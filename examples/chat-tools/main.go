@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/realtime-ai/realtime-ai/pkg/elements"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// weatherTool is the function the model may call
+var weatherTool = elements.ToolDefinition{
+	Name:        "get_weather",
+	Description: "Get the current weather for a city",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city": map[string]any{
+				"type":        "string",
+				"description": "City name, e.g. Paris",
+			},
+		},
+		"required": []string{"city"},
+	},
+}
+
+// getWeather returns canned weather data; a real assistant would call a weather API here
+func getWeather(arguments string) map[string]any {
+	var args struct {
+		City string `json:"city"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil || args.City == "" {
+		return map[string]any{"error": "city is required"}
+	}
+	return map[string]any{
+		"city":        args.City,
+		"condition":   "sunny",
+		"temperature": 22,
+		"unit":        "celsius",
+	}
+}
+
+func main() {
+	// Load environment variables from .env file
+	godotenv.Load()
+
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		log.Fatal("OPENAI_API_KEY environment variable is not set")
+	}
+
+	// Create chat element with the weather tool
+	chat, err := elements.NewChatElement(elements.ChatConfig{
+		APIKey:       apiKey,
+		Model:        "gpt-4o-mini",
+		SystemPrompt: "You are a helpful voice assistant. Use the get_weather tool for weather questions.",
+		Streaming:    true,
+		Tools:        []elements.ToolDefinition{weatherTool},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create chat element: %v", err)
+	}
+
+	p := pipeline.NewPipeline("chat-tools-demo")
+	p.AddElement(chat)
+
+	// Run tool calls and hand the results back to the chat element
+	toolCalls := make(chan pipeline.Event, 10)
+	p.Bus().Subscribe(pipeline.EventToolCall, toolCalls)
+	go func() {
+		for evt := range toolCalls {
+			call, ok := evt.Payload.(*pipeline.ToolCallPayload)
+			if !ok || call.Name != weatherTool.Name {
+				continue
+			}
+			fmt.Printf("  [tool] %s(%s)\n", call.Name, call.Arguments)
+			if err := chat.SendToolResult(call.CallID, getWeather(call.Arguments)); err != nil {
+				log.Printf("Failed to send tool result: %v", err)
+			}
+		}
+	}()
+
+	ctx := context.Background()
+	if err := p.Start(ctx); err != nil {
+		log.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+
+	// Print the assistant's answers
+	go func() {
+		for {
+			msg := p.Pull()
+			if msg == nil {
+				return
+			}
+			if msg.TextData != nil {
+				fmt.Printf("Assistant: %s\n", msg.TextData.Data)
+			}
+		}
+	}()
+
+	fmt.Println("Ask about the weather (e.g. \"What's the weather in Paris?\"), Ctrl+D to quit")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if err := p.PushText("chat-tools", text); err != nil {
+			log.Printf("Failed to push text: %v", err)
+		}
+	}
+}
@@ -78,13 +78,14 @@ func createPipeline(_ context.Context, session *realtimeapi.Session, apiKey stri
 
 		// Gemini AI processing with default model
 		gemini := elements.NewGeminiLiveElementWithConfig(elements.GeminiLiveConfig{
-			Model:  elements.DefaultGeminiLiveModel,
-			APIKey: apiKey,
+			Model:        elements.DefaultGeminiLiveModel,
+			APIKey:       apiKey,
+			GoogleSearch: true, // Ground factual answers with Google Search
 		})
 
-		// Resample output to 48kHz for WebRTC
-		// Note: Gemini outputs at 24kHz, we need to resample to 48kHz
-		outputResample := elements.NewAudioResampleElement(24000, 48000, 1, 1)
+		// Resample output to the clock rate negotiated for WebRTC (usually 48kHz)
+		// Note: Gemini outputs at 24kHz
+		outputResample := elements.NewAudioResampleElement(24000, session.OutputSampleRate(), 1, 1)
 
 		// Audio pacer for rate control
 		audioPacer := elements.NewAudioPacerSinkElement()
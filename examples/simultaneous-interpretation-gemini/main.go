@@ -133,10 +133,11 @@ func createRealtimePipeline(
 	p.AddElement(gemini)
 	log.Printf("  [2/3] GeminiLive (%s → %s, %s domain)", sourceLang, targetLang, domain)
 
-	// Element 3: Resample to 48kHz for WebRTC
-	outputResample := elements.NewAudioResampleElement(24000, 48000, 1, 1)
+	// Element 3: Resample to the clock rate negotiated for WebRTC
+	outputRate := session.OutputSampleRate()
+	outputResample := elements.NewAudioResampleElement(24000, outputRate, 1, 1)
 	p.AddElement(outputResample)
-	log.Println("  [3/3] AudioResample (24kHz → 48kHz)")
+	log.Printf("  [3/3] AudioResample (24kHz → %dHz)", outputRate)
 
 	// Link pipeline
 	p.Link(inputResample, gemini)
@@ -195,6 +195,9 @@ func createInterpretationPipeline(
 ) (*pipeline.Pipeline, error) {
 	p := pipeline.NewPipeline("simultaneous-interpretation")
 
+	// Languages are configured once; STT, translate and TTS read them from the pipeline
+	p.SetLanguageContext(pipeline.NewLanguageContext(sourceLang, targetLang))
+
 	log.Println("Building interpretation pipeline:")
 
 	// ============================================================
@@ -235,7 +238,6 @@ func createInterpretationPipeline(
 	// ElevenLabs Scribe V2 provides ~150ms latency real-time ASR
 	elevenLabsConfig := elements.ElevenLabsRealtimeSTTConfig{
 		APIKey:               os.Getenv("ELEVENLABS_API_KEY"),
-		Language:             sourceLang,
 		Model:                "", // Use default scribe_v2_realtime
 		EnablePartialResults: true,
 		VADEnabled:           vadElement != nil,
@@ -265,12 +267,10 @@ func createInterpretationPipeline(
 	}
 
 	translateConfig := elements.TranslateConfig{
-		Provider:   translateProvider,
-		APIKey:     translateAPIKey,
-		SourceLang: sourceLang,
-		TargetLang: targetLang,
-		Model:      translateModel,
-		Streaming:  false, // Set to true for lower latency
+		Provider:  translateProvider,
+		APIKey:    translateAPIKey,
+		Model:     translateModel,
+		Streaming: false, // Set to true for lower latency
 	}
 
 	translateElement, err := elements.NewTranslateElement(translateConfig)
@@ -291,6 +291,9 @@ func createInterpretationPipeline(
 	// Set TTS voice
 	ttsElement.SetProperty("voice", ttsVoice)
 
+	// Speed up translated speech (up to 20%) when it falls behind the speaker
+	ttsElement.SetPlaybackRateRange(1.0, 1.2)
+
 	p.AddElement(ttsElement)
 	log.Printf("  [5] UniversalTTSElement (Provider: OpenAI, Voice: %s)", ttsVoice)
 
@@ -304,11 +307,16 @@ func createInterpretationPipeline(
 	p.AddElement(resample48k)
 	log.Println("  [6] AudioResampleElement (24kHz → 48kHz)")
 
-	// 7. Opus Encode Element (for WebRTC transmission)
+	// 7. Loudness Normalize Element (consistent volume across voices and responses)
+	loudness := elements.NewLoudnessNormalizeElement(elements.DefaultLoudnessNormalizeConfig())
+	p.AddElement(loudness)
+	log.Println("  [7] LoudnessNormalizeElement (-16 LUFS)")
+
+	// 8. Opus Encode Element (for WebRTC transmission)
 	// NewOpusEncodeElement(bufferSize, sampleRate, channels)
 	opusEncode := elements.NewOpusEncodeElement(960, 48000, 1)
 	p.AddElement(opusEncode)
-	log.Println("  [7] OpusEncodeElement (Audio compression)")
+	log.Println("  [8] OpusEncodeElement (Audio compression)")
 
 	// ============================================================
 	// Link all elements in the pipeline
@@ -330,11 +338,12 @@ func createInterpretationPipeline(
 	p.Link(sttElement, translateElement)
 	log.Println("  ElevenLabs STT → Translate")
 
-	// Output path: Translate → TTS → Resample → Opus → Audio Output
+	// Output path: Translate → TTS → Resample → Loudness → Opus → Audio Output
 	p.Link(translateElement, ttsElement)
 	p.Link(ttsElement, resample48k)
-	p.Link(resample48k, opusEncode)
-	log.Println("  Translate → TTS → Resample(48kHz) → Opus Encode → Audio Output")
+	p.Link(resample48k, loudness)
+	p.Link(loudness, opusEncode)
+	log.Println("  Translate → TTS → Resample(48kHz) → Loudness → Opus Encode → Audio Output")
 
 	// Subscribe to pipeline events for logging and subtitles
 	subscribeToEvents(p, conn, enableSubtitles)
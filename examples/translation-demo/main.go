@@ -170,6 +170,9 @@ func main() {
 func createPipeline(conn connection.Connection, sourceLang, targetLang, translateProvider, translateModel string) (*pipeline.Pipeline, error) {
 	p := pipeline.NewPipeline("translation-pipeline")
 
+	// Languages are configured once; STT, translate and TTS read them from the pipeline
+	p.SetLanguageContext(pipeline.NewLanguageContext(sourceLang, targetLang))
+
 	// 1. Audio Resample Element (ensure 16kHz for Whisper)
 	// AudioResampleElement(inputRate, outputRate, inputChannels, outputChannels)
 	resampleElement := elements.NewAudioResampleElement(48000, 16000, 1, 1)
@@ -199,7 +202,6 @@ func createPipeline(conn connection.Connection, sourceLang, targetLang, translat
 	// 3. Whisper STT Element
 	whisperConfig := elements.WhisperSTTConfig{
 		APIKey:               os.Getenv("OPENAI_API_KEY"),
-		Language:             sourceLang,
 		Model:                "whisper-1",
 		EnablePartialResults: false,
 		VADEnabled:           vadElement != nil,
@@ -214,7 +216,7 @@ func createPipeline(conn connection.Connection, sourceLang, targetLang, translat
 		return nil, fmt.Errorf("failed to create Whisper STT element: %v", err)
 	}
 	p.AddElement(whisperElement)
-	log.Printf("Added: WhisperSTTElement (Language: %s, VAD: %v)", whisperConfig.Language, whisperConfig.VADEnabled)
+	log.Printf("Added: WhisperSTTElement (Language: %s, VAD: %v)", sourceLang, whisperConfig.VADEnabled)
 
 	// 4. Translate Element
 	translateAPIKey := os.Getenv("OPENAI_API_KEY")
@@ -226,12 +228,10 @@ func createPipeline(conn connection.Connection, sourceLang, targetLang, translat
 	}
 
 	translateConfig := elements.TranslateConfig{
-		Provider:   translateProvider,
-		APIKey:     translateAPIKey,
-		SourceLang: sourceLang,
-		TargetLang: targetLang,
-		Model:      translateModel,
-		Streaming:  false, // Set to true for lower latency
+		Provider:  translateProvider,
+		APIKey:    translateAPIKey,
+		Model:     translateModel,
+		Streaming: false, // Set to true for lower latency
 	}
 
 	translateElement, err := elements.NewTranslateElement(translateConfig)
@@ -32,7 +32,9 @@
 //
 // Environment Variables:
 //   - OPENAI_API_KEY: OpenAI API key for GPT-4
-//   - ELEVENLABS_API_KEY: ElevenLabs API key for STT and TTS
+//   - ELEVENLABS_API_KEY: ElevenLabs API key for STT and TTS (not needed in realtime mode)
+//   - USE_OPENAI_REALTIME: "true" to use OpenAI Realtime over WebSocket
+//     (speech-to-speech) instead of the VAD → STT → GPT → TTS chain
 //   - TWILIO_STREAM_URL: Public WebSocket URL for Twilio (e.g., wss://your-domain.com/media)
 //   - PORT: HTTP server port (default: 8080)
 //
@@ -53,7 +55,6 @@ import (
 	"strings"
 	"sync"
 	"syscall"
-	"time"
 
 	"github.com/realtime-ai/realtime-ai/pkg/connection"
 	"github.com/realtime-ai/realtime-ai/pkg/elements"
@@ -81,6 +82,9 @@ type Config struct {
 
 	// Assistant
 	SystemPrompt string
+
+	// UseOpenAIRealtime replaces the STT → GPT → TTS chain with OpenAI Realtime
+	UseOpenAIRealtime bool
 }
 
 func main() {
@@ -130,6 +134,7 @@ func loadConfig() *Config {
 		ElevenLabsVoice:  getEnv("ELEVENLABS_VOICE_ID", "21m00Tcm4TlvDq8ikWAM"), // Rachel
 		VADModelPath:     getEnv("VAD_MODEL_PATH", "models/silero_vad.onnx"),
 		VADEnabled:       getEnv("VAD_ENABLED", "true") == "true",
+		UseOpenAIRealtime: getEnv("USE_OPENAI_REALTIME", "false") == "true",
 		SystemPrompt: getEnv("SYSTEM_PROMPT", `You are a helpful AI phone assistant for customer service.
 
 Guidelines:
@@ -152,7 +157,7 @@ func validateConfig(config *Config) {
 	if config.OpenAIAPIKey == "" {
 		missing = append(missing, "OPENAI_API_KEY")
 	}
-	if config.ElevenLabsAPIKey == "" {
+	if config.ElevenLabsAPIKey == "" && !config.UseOpenAIRealtime {
 		missing = append(missing, "ELEVENLABS_API_KEY")
 	}
 
@@ -176,6 +181,10 @@ type voiceAssistantFactory struct {
 func (f *voiceAssistantFactory) CreatePipeline(ctx context.Context, conn *connection.TwilioConnection) (*pipeline.Pipeline, error) {
 	log.Printf("[Factory] Creating voice assistant pipeline for call %s", conn.CallSid())
 
+	if f.config.UseOpenAIRealtime {
+		return f.createRealtimePipeline(ctx, conn)
+	}
+
 	// Create pipeline
 	p := pipeline.NewPipeline("voice-assistant-" + conn.CallSid())
 
@@ -283,6 +292,30 @@ func (f *voiceAssistantFactory) CreatePipeline(ctx context.Context, conn *connec
 	return p, nil
 }
 
+// createRealtimePipeline creates a single-element pipeline backed by OpenAI Realtime.
+// Audio travels as base64 PCM over a server-side WebSocket; the Twilio connection
+// resamples 16kHz input / 24kHz output to and from μ-law 8kHz.
+func (f *voiceAssistantFactory) createRealtimePipeline(ctx context.Context, conn *connection.TwilioConnection) (*pipeline.Pipeline, error) {
+	p := pipeline.NewPipeline("voice-assistant-realtime-" + conn.CallSid())
+
+	realtimeElem := elements.NewOpenAIRealtimeAPIElementWithConfig(elements.OpenAIRealtimeConfig{
+		APIKey:       f.config.OpenAIAPIKey,
+		Instructions: f.config.SystemPrompt,
+		StreamAudio:  true, // Forward audio deltas immediately for low latency on the phone
+	})
+	p.AddElement(realtimeElem)
+	log.Printf("[Factory] OpenAI Realtime element added")
+
+	outputHandler := &twilioOutputHandler{
+		conn: conn,
+		wg:   &sync.WaitGroup{},
+	}
+	outputHandler.wg.Add(1)
+	go outputHandler.handleOutput(ctx, p)
+
+	return p, nil
+}
+
 // twilioOutputHandler sends pipeline output to Twilio.
 type twilioOutputHandler struct {
 	conn *connection.TwilioConnection
@@ -292,22 +325,15 @@ type twilioOutputHandler struct {
 func (h *twilioOutputHandler) handleOutput(ctx context.Context, p *pipeline.Pipeline) {
 	defer h.wg.Done()
 
-	ticker := time.NewTicker(20 * time.Millisecond)
-	defer ticker.Stop()
-
 	for {
-		select {
-		case <-ctx.Done():
+		msg, err := p.PullContext(ctx)
+		if err != nil {
+			// Call ended or pipeline closed
 			return
-		case <-ticker.C:
-			msg := p.Pull()
-			if msg == nil {
-				continue
-			}
+		}
 
-			if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil {
-				h.conn.SendMessage(msg)
-			}
+		if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil {
+			h.conn.SendMessage(msg)
 		}
 	}
 }
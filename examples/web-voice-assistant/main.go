@@ -10,6 +10,7 @@
 //   - Low-latency streaming responses (gpt-4o-mini)
 //   - Voice activity detection with interrupt support
 //   - ElevenLabs high-quality ASR and TTS
+//   - Optional greeting spoken as soon as the call connects (VOICE_ASSISTANT_GREETING)
 //
 // Usage:
 //
@@ -66,6 +67,7 @@ func main() {
 	voice := getEnv("VOICE_ASSISTANT_VOICE", "Rachel")
 	systemPrompt := getEnv("VOICE_ASSISTANT_SYSTEM_PROMPT",
 		"You are a helpful voice assistant. Keep your responses concise, natural, and conversational. Respond in the same language as the user.")
+	greeting := getEnv("VOICE_ASSISTANT_GREETING", "Hi! How can I help you today?")
 
 	// Create server configuration
 	config := server.DefaultWebRTCRealtimeConfig()
@@ -83,6 +85,7 @@ func main() {
 			VADModelPath:  vadModelPath,
 			Voice:         voice,
 			SystemPrompt:  systemPrompt,
+			Greeting:      greeting,
 		})
 	})
 
@@ -93,6 +96,7 @@ func main() {
 
 	// HTTP handlers
 	http.HandleFunc("/session", srv.HandleNegotiate)
+	http.HandleFunc("/diagnose", srv.HandleDiagnose) // Output audio diagnostics with a test signal
 	http.Handle("/", http.FileServer(http.Dir("examples/web-voice-assistant")))
 
 	log.Println("===========================================")
@@ -116,6 +120,7 @@ type PipelineConfig struct {
 	VADModelPath  string
 	Voice         string
 	SystemPrompt  string
+	Greeting      string // Spoken when the connection is established (empty = none)
 }
 
 // createPipeline creates the voice assistant pipeline
@@ -138,7 +143,9 @@ func createPipeline(ctx context.Context, session *realtimeapi.Session, cfg Pipel
 	elems = append(elems, inputResample)
 	prevElem = inputResample
 
-	// 2. VAD (optional, but recommended for interrupt)
+	// 2. VAD (recommended for interrupt): Silero when a model is available,
+	// otherwise the energy-based VAD
+	var vadElem pipeline.Element
 	if cfg.VADModelPath != "" {
 		vadConfig := elements.SileroVADConfig{
 			ModelPath:       cfg.VADModelPath,
@@ -147,20 +154,25 @@ func createPipeline(ctx context.Context, session *realtimeapi.Session, cfg Pipel
 			SpeechPadMs:     30,
 			Mode:            elements.VADModePassthrough,
 		}
-		vadElem, err := elements.NewSileroVADElement(vadConfig)
+		sileroElem, err := elements.NewSileroVADElement(vadConfig)
 		if err != nil {
 			log.Printf("[Pipeline] Warning: Failed to create VAD element: %v", err)
+		} else if err := sileroElem.Init(ctx); err != nil {
+			log.Printf("[Pipeline] Warning: Failed to init VAD element: %v", err)
 		} else {
-			if err := vadElem.Init(ctx); err != nil {
-				log.Printf("[Pipeline] Warning: Failed to init VAD element: %v", err)
-			} else {
-				elems = append(elems, vadElem)
-				p.Link(prevElem, vadElem)
-				prevElem = vadElem
-				log.Printf("[Pipeline] VAD enabled")
-			}
+			vadElem = sileroElem
+			log.Printf("[Pipeline] Silero VAD enabled")
 		}
 	}
+	if vadElem == nil {
+		energyConfig := elements.DefaultEnergyVADConfig()
+		energyConfig.MinSilenceMs = 500
+		vadElem = elements.NewEnergyVADElement(energyConfig)
+		log.Printf("[Pipeline] Energy VAD enabled (set a Silero model for better accuracy)")
+	}
+	elems = append(elems, vadElem)
+	p.Link(prevElem, vadElem)
+	prevElem = vadElem
 
 	// 3. ElevenLabs ASR
 	asrConfig := elements.ElevenLabsRealtimeSTTConfig{
@@ -213,16 +225,23 @@ func createPipeline(ctx context.Context, session *realtimeapi.Session, cfg Pipel
 	p.Link(prevElem, ttsElem)
 	prevElem = ttsElem
 
-	// 6. Output resample: 16kHz → 48kHz (processing to WebRTC)
-	// Note: ElevenLabs outputs 22050Hz or 24000Hz depending on model
-	// We'll use 24000 → 48000 for eleven_turbo_v2_5
-	outputResample := elements.NewAudioResampleElement(24000, 48000, 1, 1)
+	// 6. Output resample: TTS rate → negotiated WebRTC clock rate (usually 48kHz)
+	// The input rate is read from each frame, since TTS providers differ
+	// (e.g. ElevenLabs outputs 22050Hz or 24000Hz depending on model)
+	outputResample := elements.NewAutoResampleElement(session.OutputSampleRate(), 1)
 	elems = append(elems, outputResample)
 	p.Link(prevElem, outputResample)
 
 	// Add all elements to pipeline
 	p.AddElements(elems)
 
+	// Typed messages (conversation.item.create + response.create) go straight to chat
+	p.SetTextInput(chatElem)
+
+	// Greet the user before they say anything
+	p.SetSpeechInput(ttsElem)
+	p.PlayOnStart(cfg.Greeting)
+
 	log.Printf("[Pipeline] Created voice assistant pipeline for session %s", session.ID)
 	log.Printf("[Pipeline] Flow: Resample(48k→16k) → VAD → ASR(11labs) → Chat(gpt-4o-mini) → TTS(11labs) → Resample(24k→48k)")
 
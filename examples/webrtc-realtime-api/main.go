@@ -77,6 +77,9 @@ func createPipeline(ctx context.Context, session *realtimeapi.Session, apiKey st
 	interruptConfig.MinSpeechForConfirmMs = 300 // Confirm interrupt after 300ms speech
 	p.EnableInterruptManager(interruptConfig)
 
+	// Tell the browser when the assistant audio is actually playing
+	p.EnableSpeakingTracker(pipeline.SpeakingConfig{})
+
 	if apiKey != "" {
 		// Full pipeline with Gemini AI
 		// Input: WebRTC audio at 48kHz
@@ -86,9 +89,9 @@ func createPipeline(ctx context.Context, session *realtimeapi.Session, apiKey st
 		// Gemini AI processing
 		gemini := elements.NewGeminiElement()
 
-		// Resample output to 48kHz for WebRTC
-		// Note: Gemini outputs at 24kHz, we need to resample to 48kHz
-		outputResample := elements.NewAudioResampleElement(24000, 48000, 1, 1)
+		// Resample output to the clock rate negotiated for WebRTC (usually 48kHz)
+		// Note: Gemini outputs at 24kHz
+		outputResample := elements.NewAudioResampleElement(24000, session.OutputSampleRate(), 1, 1)
 
 		// Add elements
 		p.AddElements([]pipeline.Element{inputResample, gemini, outputResample})
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/realtime-ai/realtime-ai/pkg/connection"
+	"github.com/realtime-ai/realtime-ai/pkg/elements"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/server"
+)
+
+// transcriptionFactory creates a VAD + Whisper pipeline for each client.
+// Transcripts are sent back to the client as JSON text messages by the server.
+type transcriptionFactory struct {
+	apiKey string
+}
+
+func (f *transcriptionFactory) CreatePipeline(ctx context.Context, conn connection.Connection) (*pipeline.Pipeline, error) {
+	p := pipeline.NewPipeline("websocket-stt-" + conn.PeerID())
+
+	// Client audio is already 16kHz mono, no resampling needed
+	vad := elements.NewEnergyVADElement(elements.DefaultEnergyVADConfig())
+
+	stt, err := elements.NewWhisperSTTElement(elements.WhisperSTTConfig{
+		APIKey:        f.apiKey,
+		Language:      "auto",
+		VADEnabled:    true,
+		SampleRate:    16000,
+		Channels:      1,
+		BitsPerSample: 16,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.AddElements([]pipeline.Element{vad, stt})
+	p.Link(vad, stt)
+
+	return p, nil
+}
+
+func main() {
+	// Load environment variables
+	godotenv.Load()
+
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		log.Fatal("OPENAI_API_KEY environment variable is required")
+	}
+
+	addr := os.Getenv("SERVER_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	srv := server.NewWebSocketAudioServer(server.WebSocketAudioServerConfig{
+		Address:       addr,
+		WebSocketPath: "/audio",
+		SampleRate:    16000,
+		FrameMs:       20,
+	}, &transcriptionFactory{apiKey: apiKey})
+
+	if err := srv.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+	log.Printf("Stream 16kHz mono PCM to ws://localhost%s/audio", addr)
+
+	// Wait for interrupt signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down...")
+	srv.Stop()
+}
@@ -0,0 +1,688 @@
+// Package asr provides a unified interface for Automatic Speech Recognition (ASR) systems.
+//
+// AssemblyAIProvider implements real-time speech recognition using AssemblyAI's
+// Universal Streaming (v3) WebSocket API.
+//
+// Features:
+//   - Real-time streaming ASR via WebSocket, raw PCM (pcm_s16le) sent as binary frames
+//   - Partial and final transcript support: a Turn is partial until end_of_turn,
+//     and final once its formatted version arrives
+//   - Speaker diarization: with SpeakerLabels the speaker of each turn is
+//     reported in the result metadata ("speaker", "speaker_label")
+//   - Manual commit (ForceEndpoint) for VAD integration
+//   - Connection retry with exponential backoff
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+const (
+	// AssemblyAI streaming WebSocket endpoint
+	assemblyAIRealtimeWSURL = "wss://streaming.assemblyai.com/v3/ws"
+
+	// Connection configuration
+	assemblyAIMaxRetryAttempts  = 3
+	assemblyAIInitialRetryDelay = 1 * time.Second
+	assemblyAIMaxRetryDelay     = 4 * time.Second
+	assemblyAIConnectionTimeout = 10 * time.Second
+)
+
+// AssemblyAIProvider implements the Provider interface using AssemblyAI's streaming API.
+// It uses WebSocket for true streaming speech recognition.
+type AssemblyAIProvider struct {
+	apiKey        string
+	model         string
+	url           string
+	speakerLabels bool
+	maxSpeakers   int
+	headers       map[string]string
+	mu            sync.RWMutex
+}
+
+// AssemblyAIConfig holds configuration for AssemblyAIProvider.
+type AssemblyAIConfig struct {
+	// APIKey is the AssemblyAI API key (required)
+	APIKey string
+
+	// Model is the streaming speech model (default: AssemblyAI's default,
+	// English; "universal-streaming-multilingual" for other languages)
+	Model string
+
+	// URL is the WebSocket endpoint (default: "wss://streaming.assemblyai.com/v3/ws").
+	// Override it for the EU endpoint, self-hosted proxies or tests.
+	URL string
+
+	// SpeakerLabels enables speaker diarization: each turn carries the
+	// label of the speaker who said it
+	SpeakerLabels bool
+
+	// MaxSpeakers caps the number of speakers diarization distinguishes
+	// (default: 0, decided by AssemblyAI). Only used with SpeakerLabels.
+	MaxSpeakers int
+
+	// Headers are extra headers sent on every connection (API gateway keys,
+	// org IDs, tracing headers). They override the provider's own headers of
+	// the same name.
+	Headers map[string]string
+}
+
+// NewAssemblyAIProvider creates a new AssemblyAI streaming ASR provider.
+func NewAssemblyAIProvider(config AssemblyAIConfig) (*AssemblyAIProvider, error) {
+	if config.APIKey == "" {
+		return nil, &Error{
+			Code:    ErrCodeInvalidConfig,
+			Message: "AssemblyAI API key is required",
+		}
+	}
+
+	wsURL := config.URL
+	if wsURL == "" {
+		wsURL = assemblyAIRealtimeWSURL
+	}
+
+	return &AssemblyAIProvider{
+		apiKey:        config.APIKey,
+		model:         config.Model,
+		url:           wsURL,
+		speakerLabels: config.SpeakerLabels,
+		maxSpeakers:   config.MaxSpeakers,
+		headers:       config.Headers,
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *AssemblyAIProvider) Name() string {
+	return "assemblyai"
+}
+
+// Recognize performs speech recognition on a complete audio segment.
+// It streams the audio over a short-lived session and waits for the final transcript.
+func (p *AssemblyAIProvider) Recognize(ctx context.Context, audio io.Reader, audioConfig AudioConfig, config RecognitionConfig) (*RecognitionResult, error) {
+	audioData, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, &Error{
+			Code:    ErrCodeInvalidAudio,
+			Message: "failed to read audio data",
+			Err:     err,
+		}
+	}
+
+	if len(audioData) == 0 {
+		return nil, &Error{
+			Code:    ErrCodeInvalidAudio,
+			Message: "audio data is empty",
+		}
+	}
+
+	recognizer, err := p.StreamingRecognize(ctx, audioConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	defer recognizer.Close()
+
+	if err := recognizer.SendAudio(ctx, audioData); err != nil {
+		return nil, err
+	}
+	if err := recognizer.(*assemblyAIStreamingRecognizer).Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// Wait for final result with timeout
+	timeout := time.After(30 * time.Second)
+	for {
+		select {
+		case result, ok := <-recognizer.Results():
+			if !ok {
+				return emptyAssemblyAIResult(config.Language), nil
+			}
+			if result.IsFinal {
+				return result, nil
+			}
+		case <-timeout:
+			return emptyAssemblyAIResult(config.Language), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// emptyAssemblyAIResult is returned by Recognize when no transcript arrived.
+func emptyAssemblyAIResult(language string) *RecognitionResult {
+	return &RecognitionResult{
+		Text:       "",
+		IsFinal:    true,
+		Confidence: -1,
+		Language:   language,
+		Timestamp:  time.Now(),
+	}
+}
+
+// StreamingRecognize creates a streaming recognizer for continuous audio input.
+func (p *AssemblyAIProvider) StreamingRecognize(ctx context.Context, audioConfig AudioConfig, config RecognitionConfig) (StreamingRecognizer, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if audioConfig.Encoding != "" && audioConfig.Encoding != "pcm" {
+		return nil, &Error{
+			Code:    ErrCodeInvalidConfig,
+			Message: fmt.Sprintf("AssemblyAI streaming expects raw PCM audio, got %q", audioConfig.Encoding),
+		}
+	}
+	if audioConfig.Channels > 1 {
+		return nil, &Error{
+			Code:    ErrCodeInvalidConfig,
+			Message: fmt.Sprintf("AssemblyAI streaming expects mono audio, got %d channels", audioConfig.Channels),
+		}
+	}
+
+	recognizer := &assemblyAIStreamingRecognizer{
+		provider:    p,
+		audioConfig: audioConfig,
+		config:      config,
+		resultsChan: make(chan *RecognitionResult, 10),
+		sendChan:    make(chan []byte, 100),
+		commitChan:  make(chan struct{}, 1),
+	}
+
+	if err := recognizer.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	return recognizer, nil
+}
+
+// SupportsStreaming indicates if the provider supports streaming recognition.
+func (p *AssemblyAIProvider) SupportsStreaming() bool {
+	return true
+}
+
+// SupportedLanguages returns a list of supported language codes.
+func (p *AssemblyAIProvider) SupportedLanguages() []string {
+	// English, plus the languages of the multilingual streaming model
+	return []string{"en", "es", "fr", "de", "it", "pt"}
+}
+
+// Close releases any resources held by the provider.
+func (p *AssemblyAIProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return nil
+}
+
+// assemblyAIStreamingRecognizer implements StreamingRecognizer for AssemblyAI.
+type assemblyAIStreamingRecognizer struct {
+	provider    *AssemblyAIProvider
+	audioConfig AudioConfig
+	config      RecognitionConfig
+	resultsChan chan *RecognitionResult
+	sendChan    chan []byte
+	commitChan  chan struct{}
+	conn        *websocket.Conn
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	closed      atomic.Bool
+	startTime   time.Time
+	health      *utils.WSHealth
+}
+
+// AssemblyAI message types
+type assemblyAIMessage struct {
+	Type string `json:"type"`
+
+	// Begin
+	ID string `json:"id,omitempty"`
+
+	// Turn
+	TurnOrder           int              `json:"turn_order"`
+	TurnIsFormatted     bool             `json:"turn_is_formatted"`
+	EndOfTurn           bool             `json:"end_of_turn"`
+	Transcript          string           `json:"transcript"`
+	EndOfTurnConfidence float32          `json:"end_of_turn_confidence"`
+	Words               []assemblyAIWord `json:"words"`
+	SpeakerLabel        string           `json:"speaker_label,omitempty"`
+	LanguageCode        string           `json:"language_code,omitempty"`
+
+	// Error messages
+	Error string `json:"error,omitempty"`
+}
+
+type assemblyAIWord struct {
+	Text        string  `json:"text"`
+	WordIsFinal bool    `json:"word_is_final"`
+	Start       int     `json:"start"`
+	End         int     `json:"end"`
+	Confidence  float32 `json:"confidence"`
+	Speaker     string  `json:"speaker,omitempty"`
+}
+
+type assemblyAIControlMessage struct {
+	Type string `json:"type"`
+}
+
+// connect establishes WebSocket connection with retry logic.
+func (r *assemblyAIStreamingRecognizer) connect(ctx context.Context) error {
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.startTime = time.Now()
+
+	var lastErr error
+	retryDelay := assemblyAIInitialRetryDelay
+
+	for attempt := 0; attempt < assemblyAIMaxRetryAttempts; attempt++ {
+		if err := r.doConnect(); err != nil {
+			lastErr = err
+			log.Printf("[AssemblyAI] Connection attempt %d/%d failed: %v", attempt+1, assemblyAIMaxRetryAttempts, err)
+
+			if attempt < assemblyAIMaxRetryAttempts-1 {
+				select {
+				case <-time.After(retryDelay):
+					retryDelay *= 2
+					if retryDelay > assemblyAIMaxRetryDelay {
+						retryDelay = assemblyAIMaxRetryDelay
+					}
+				case <-ctx.Done():
+					r.cancel()
+					return ctx.Err()
+				}
+			}
+			continue
+		}
+
+		// Successfully connected
+		return nil
+	}
+
+	r.cancel()
+	return &Error{
+		Code:    ErrCodeNetworkError,
+		Message: fmt.Sprintf("failed to connect after %d attempts", assemblyAIMaxRetryAttempts),
+		Err:     lastErr,
+	}
+}
+
+// streamURL builds the /v3/ws URL with the recognition options.
+func (r *assemblyAIStreamingRecognizer) streamURL() string {
+	sampleRate := r.audioConfig.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 16000
+	}
+
+	model := r.config.Model
+	if model == "" {
+		model = r.provider.model
+	}
+
+	params := url.Values{}
+	params.Set("sample_rate", strconv.Itoa(sampleRate))
+	params.Set("encoding", "pcm_s16le")
+	params.Set("format_turns", "true")
+	if model != "" {
+		params.Set("speech_model", model)
+	}
+	if r.config.Language == "auto" {
+		params.Set("language_detection", "true")
+	}
+	if r.provider.speakerLabels {
+		params.Set("speaker_labels", "true")
+		if r.provider.maxSpeakers > 0 {
+			params.Set("max_speakers", strconv.Itoa(r.provider.maxSpeakers))
+		}
+	}
+
+	return r.provider.url + "?" + params.Encode()
+}
+
+// doConnect performs the actual WebSocket connection.
+func (r *assemblyAIStreamingRecognizer) doConnect() error {
+	wsURL := r.streamURL()
+	log.Printf("[AssemblyAI] Connecting to %s", wsURL)
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: assemblyAIConnectionTimeout,
+	}
+
+	headers := http.Header{
+		"Authorization": {r.provider.apiKey},
+	}
+	utils.MergeHeaders(headers, r.provider.headers)
+
+	conn, resp, err := dialer.DialContext(r.ctx, wsURL, headers)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("websocket dial failed (HTTP %d): %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("websocket dial failed: %w", err)
+	}
+
+	r.conn = conn
+	r.health = utils.NewWSHealth(conn, utils.DefaultWSPingInterval)
+	log.Printf("[AssemblyAI] WebSocket connected")
+
+	// Audio may be sent as soon as the WebSocket is open; Begin follows
+	r.wg.Add(2)
+	go r.readLoop()
+	go r.writeLoop()
+
+	return nil
+}
+
+// readLoop handles incoming WebSocket messages.
+func (r *assemblyAIStreamingRecognizer) readLoop() {
+	defer r.wg.Done()
+	defer r.health.Close()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		default:
+		}
+
+		_, message, err := r.conn.ReadMessage()
+		if err != nil {
+			if !r.closed.Load() && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("[AssemblyAI] WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		r.health.Touch()
+		r.handleMessage(message)
+	}
+}
+
+// writeLoop sends audio as binary frames and ForceEndpoint on commit.
+func (r *assemblyAIStreamingRecognizer) writeLoop() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+
+		case audioData, ok := <-r.sendChan:
+			if !ok {
+				return
+			}
+			r.write(websocket.BinaryMessage, audioData)
+
+		case <-r.commitChan:
+			// Audio queued before the commit belongs to the turn being ended
+			for drained := false; !drained; {
+				select {
+				case audioData, ok := <-r.sendChan:
+					if !ok {
+						return
+					}
+					r.write(websocket.BinaryMessage, audioData)
+				default:
+					drained = true
+				}
+			}
+			r.sendControl("ForceEndpoint")
+			log.Printf("[AssemblyAI] Sent force endpoint")
+		}
+	}
+}
+
+// sendControl sends a JSON control message (ForceEndpoint, Terminate).
+func (r *assemblyAIStreamingRecognizer) sendControl(messageType string) {
+	data, err := json.Marshal(assemblyAIControlMessage{Type: messageType})
+	if err != nil {
+		log.Printf("[AssemblyAI] Failed to marshal %s: %v", messageType, err)
+		return
+	}
+	r.write(websocket.TextMessage, data)
+}
+
+func (r *assemblyAIStreamingRecognizer) write(messageType int, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil {
+		if err := r.conn.WriteMessage(messageType, data); err != nil {
+			log.Printf("[AssemblyAI] Failed to send message: %v", err)
+		}
+	}
+}
+
+// handleMessage processes incoming WebSocket messages.
+func (r *assemblyAIStreamingRecognizer) handleMessage(data []byte) {
+	var msg assemblyAIMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Printf("[AssemblyAI] Failed to parse message: %v", err)
+		return
+	}
+
+	if msg.Error != "" {
+		log.Printf("[AssemblyAI] Error: %s", msg.Error)
+		return
+	}
+
+	switch msg.Type {
+	case "Begin":
+		log.Printf("[AssemblyAI] Session started: %s", msg.ID)
+
+	case "Turn":
+		r.handleTurn(msg)
+
+	case "Termination":
+		log.Printf("[AssemblyAI] Session terminated")
+
+	default:
+		log.Printf("[AssemblyAI] Unknown message type: %s", msg.Type)
+	}
+}
+
+// handleTurn maps Turn messages to partial and final results:
+//   - end_of_turn=false: the turn so far, a partial
+//   - end_of_turn=true, turn_is_formatted=false: the turn is over but its
+//     punctuated version follows; sent as a partial
+//   - end_of_turn=true, turn_is_formatted=true: the final result
+func (r *assemblyAIStreamingRecognizer) handleTurn(msg assemblyAIMessage) {
+	isFinal := msg.EndOfTurn && msg.TurnIsFormatted
+	if !isFinal {
+		if msg.Transcript == "" || !r.config.EnablePartialResults {
+			return
+		}
+		r.emit(msg, false)
+		return
+	}
+
+	r.emit(msg, true)
+	r.startTime = time.Now()
+}
+
+func (r *assemblyAIStreamingRecognizer) emit(msg assemblyAIMessage, isFinal bool) {
+	language := r.config.Language
+	if msg.LanguageCode != "" {
+		language = msg.LanguageCode
+	}
+
+	result := &RecognitionResult{
+		Text:       msg.Transcript,
+		IsFinal:    isFinal,
+		Confidence: assemblyAIConfidence(msg.Words),
+		Language:   language,
+		Duration:   time.Since(r.startTime),
+		Timestamp:  time.Now(),
+		Metadata: map[string]interface{}{
+			"turn_order": msg.TurnOrder,
+		},
+	}
+	if label := assemblyAISpeakerLabel(msg); label != "" {
+		if speaker, ok := assemblyAISpeakerIndex(label); ok {
+			result.Metadata["speaker"] = speaker
+			result.Metadata["speaker_label"] = label
+		}
+	}
+	if isFinal {
+		result.Metadata["words"] = msg.Words
+		log.Printf("[AssemblyAI] Final: %s", msg.Transcript)
+	}
+
+	select {
+	case r.resultsChan <- result:
+	case <-r.ctx.Done():
+	default:
+		log.Printf("[AssemblyAI] Results channel full, dropping result")
+	}
+}
+
+// assemblyAIConfidence averages the word confidences, or -1 without words.
+func assemblyAIConfidence(words []assemblyAIWord) float32 {
+	if len(words) == 0 {
+		return -1
+	}
+	var sum float32
+	for _, w := range words {
+		sum += w.Confidence
+	}
+	return sum / float32(len(words))
+}
+
+// assemblyAISpeakerLabel returns the speaker of a turn: the turn's label,
+// or the first labelled word's speaker.
+func assemblyAISpeakerLabel(msg assemblyAIMessage) string {
+	if msg.SpeakerLabel != "" {
+		return msg.SpeakerLabel
+	}
+	for _, w := range msg.Words {
+		if w.Speaker != "" {
+			return w.Speaker
+		}
+	}
+	return ""
+}
+
+// assemblyAISpeakerIndex converts a speaker label to a 0-based index:
+// "A" is 0, "B" is 1 and so on; numeric labels are used as is.
+// Labels for unattributed speech (e.g. "UNKNOWN") have no index.
+func assemblyAISpeakerIndex(label string) (int, bool) {
+	if n, err := strconv.Atoi(label); err == nil && n >= 0 {
+		return n, true
+	}
+	label = strings.ToUpper(label)
+	if len(label) == 1 && label[0] >= 'A' && label[0] <= 'Z' {
+		return int(label[0] - 'A'), true
+	}
+	return 0, false
+}
+
+// SendAudio sends audio data to the recognizer.
+func (r *assemblyAIStreamingRecognizer) SendAudio(ctx context.Context, audioData []byte) error {
+	if r.closed.Load() {
+		return &Error{
+			Code:    ErrCodeProviderError,
+			Message: "recognizer is closed",
+		}
+	}
+
+	select {
+	case r.sendChan <- audioData:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// Commit ends the current turn, so it is transcribed and returned as a
+// final result without waiting for AssemblyAI's turn detection.
+func (r *assemblyAIStreamingRecognizer) Commit(ctx context.Context) error {
+	if r.closed.Load() {
+		return &Error{
+			Code:    ErrCodeProviderError,
+			Message: "recognizer is closed",
+		}
+	}
+
+	select {
+	case r.commitChan <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// Results returns a channel that receives recognition results.
+func (r *assemblyAIStreamingRecognizer) Results() <-chan *RecognitionResult {
+	return r.resultsChan
+}
+
+// Healthy reports whether the WebSocket is open and the server has sent
+// a message or pong within the last two ping intervals.
+func (r *assemblyAIStreamingRecognizer) Healthy() bool {
+	return !r.closed.Load() && r.health.Healthy()
+}
+
+// Close stops recognition and releases resources.
+func (r *assemblyAIStreamingRecognizer) Close() error {
+	if r.closed.Swap(true) {
+		return nil // Already closed
+	}
+
+	log.Printf("[AssemblyAI] Closing recognizer")
+
+	// Let the server end the session right away
+	r.sendControl("Terminate")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	r.mu.Lock()
+	if r.conn != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+	r.mu.Unlock()
+
+	// Close channels
+	close(r.sendChan)
+	close(r.commitChan)
+
+	// Wait for goroutines
+	r.wg.Wait()
+
+	// Close results channel
+	close(r.resultsChan)
+
+	log.Printf("[AssemblyAI] Recognizer closed")
+	return nil
+}
+
+// AssemblyAIStreamingRecognizer interface for accessing Commit method
+type AssemblyAIStreamingRecognizer interface {
+	StreamingRecognizer
+	// Commit ends the current turn (AssemblyAI's ForceEndpoint message).
+	Commit(ctx context.Context) error
+}
+
+// Ensure assemblyAIStreamingRecognizer implements AssemblyAIStreamingRecognizer
+var _ AssemblyAIStreamingRecognizer = (*assemblyAIStreamingRecognizer)(nil)
+
+// IsAssemblyAIRecognizer checks if a recognizer is an AssemblyAI recognizer.
+func IsAssemblyAIRecognizer(r StreamingRecognizer) (AssemblyAIStreamingRecognizer, bool) {
+	ar, ok := r.(*assemblyAIStreamingRecognizer)
+	return ar, ok
+}
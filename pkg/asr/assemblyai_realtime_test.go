@@ -0,0 +1,217 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNewAssemblyAIProvider_NoAPIKey(t *testing.T) {
+	if _, err := NewAssemblyAIProvider(AssemblyAIConfig{}); err == nil {
+		t.Error("Expected error when API key is missing")
+	}
+
+	provider, err := NewAssemblyAIProvider(AssemblyAIConfig{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if provider.Name() != "assemblyai" || provider.url != assemblyAIRealtimeWSURL {
+		t.Errorf("Unexpected provider %s with URL %s", provider.Name(), provider.url)
+	}
+}
+
+// assemblyAITurn builds a Turn message
+func assemblyAITurn(order int, transcript string, endOfTurn, formatted bool, speaker string) []byte {
+	msg := map[string]interface{}{
+		"type":              "Turn",
+		"turn_order":        order,
+		"end_of_turn":       endOfTurn,
+		"turn_is_formatted": formatted,
+		"transcript":        transcript,
+		"words": []map[string]interface{}{
+			{"text": transcript, "word_is_final": endOfTurn, "confidence": 0.9},
+		},
+	}
+	if speaker != "" {
+		msg["speaker_label"] = speaker
+	}
+	data, _ := json.Marshal(msg)
+	return data
+}
+
+// startMockAssemblyAIServer accepts streaming connections, refusing the
+// first failures attempts. Binary frames are recorded as audio; on
+// ForceEndpoint it replies with script.
+func startMockAssemblyAIServer(t *testing.T, failures int32, script [][]byte) (*httptest.Server, <-chan *http.Request, <-chan string) {
+	t.Helper()
+	var attempts atomic.Int32
+	requests := make(chan *http.Request, 1)
+	received := make(chan string, 20)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= failures {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		requests <- r
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"Begin","id":"session-1"}`))
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.BinaryMessage {
+				received <- "audio:" + string(msg)
+				continue
+			}
+			var control assemblyAIControlMessage
+			json.Unmarshal(msg, &control)
+			received <- control.Type
+			if control.Type == "ForceEndpoint" {
+				for _, reply := range script {
+					conn.WriteMessage(websocket.TextMessage, reply)
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, received
+}
+
+func TestAssemblyAIStreamingRecognize(t *testing.T) {
+	server, requests, received := startMockAssemblyAIServer(t, 1, [][]byte{
+		assemblyAITurn(0, "hello", false, false, "A"),
+		assemblyAITurn(0, "hello there", true, false, "A"),
+		assemblyAITurn(0, "Hello there.", true, true, "A"),
+		assemblyAITurn(1, "hi", false, false, "B"),
+		assemblyAITurn(1, "Hi, how are you?", true, true, "B"),
+	})
+
+	provider, err := NewAssemblyAIProvider(AssemblyAIConfig{
+		APIKey:        "test-api-key",
+		URL:           "ws" + strings.TrimPrefix(server.URL, "http") + "/v3/ws",
+		SpeakerLabels: true,
+		MaxSpeakers:   4,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	// The first connection attempt is refused and retried
+	recognizer, err := provider.StreamingRecognize(context.Background(),
+		AudioConfig{SampleRate: 16000, Channels: 1, Encoding: "pcm", BitsPerSample: 16},
+		RecognitionConfig{Language: "en", EnablePartialResults: true})
+	if err != nil {
+		t.Fatalf("StreamingRecognize failed: %v", err)
+	}
+	defer recognizer.Close()
+
+	req := <-requests
+	if auth := req.Header.Get("Authorization"); auth != "test-api-key" {
+		t.Errorf("Unexpected Authorization header %q", auth)
+	}
+	q := req.URL.Query()
+	for key, want := range map[string]string{
+		"sample_rate": "16000", "encoding": "pcm_s16le", "format_turns": "true",
+		"speaker_labels": "true", "max_speakers": "4",
+	} {
+		if got := q.Get(key); got != want {
+			t.Errorf("Expected %s=%s, got %q", key, want, got)
+		}
+	}
+
+	ar, ok := IsAssemblyAIRecognizer(recognizer)
+	if !ok {
+		t.Fatal("Expected an AssemblyAI recognizer")
+	}
+	ctx := context.Background()
+	if err := ar.SendAudio(ctx, []byte("pcm")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ar.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"audio:pcm", "ForceEndpoint"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("Expected server to receive %q, got %q", want, got)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Timeout waiting for %q", want)
+		}
+	}
+
+	type result struct {
+		text    string
+		isFinal bool
+		speaker int
+	}
+	want := []result{
+		{"hello", false, 0},
+		{"hello there", false, 0},
+		{"Hello there.", true, 0},
+		{"hi", false, 1},
+		{"Hi, how are you?", true, 1},
+	}
+	for i, w := range want {
+		select {
+		case r := <-recognizer.Results():
+			if r.Text != w.text || r.IsFinal != w.isFinal {
+				t.Fatalf("Result %d: expected %+v, got %q (final %v)", i, w, r.Text, r.IsFinal)
+			}
+			if speaker, ok := r.Metadata["speaker"].(int); !ok || speaker != w.speaker {
+				t.Errorf("Result %d: expected speaker %d, got %v", i, w.speaker, r.Metadata["speaker"])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for result %d", i)
+		}
+	}
+}
+
+func TestAssemblyAIFinalsOnly(t *testing.T) {
+	r := &assemblyAIStreamingRecognizer{
+		config:      RecognitionConfig{EnablePartialResults: false},
+		resultsChan: make(chan *RecognitionResult, 10),
+		ctx:         context.Background(),
+	}
+
+	// Without partial results only the formatted end of turn is reported
+	r.handleMessage(assemblyAITurn(0, "book a table", false, false, ""))
+	r.handleMessage(assemblyAITurn(0, "book a table for two", true, false, ""))
+	r.handleMessage(assemblyAITurn(0, "Book a table for two.", true, true, ""))
+
+	if len(r.resultsChan) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(r.resultsChan))
+	}
+	result := <-r.resultsChan
+	if result.Text != "Book a table for two." || !result.IsFinal {
+		t.Errorf("Expected final 'Book a table for two.', got %q (final %v)", result.Text, result.IsFinal)
+	}
+	if _, ok := result.Metadata["speaker"]; ok {
+		t.Error("Expected no speaker without diarization")
+	}
+}
+
+func TestAssemblyAISpeakerIndex(t *testing.T) {
+	for label, want := range map[string]int{"A": 0, "b": 1, "C": 2, "3": 3} {
+		if got, ok := assemblyAISpeakerIndex(label); !ok || got != want {
+			t.Errorf("Speaker %q: expected %d, got %d (%v)", label, want, got, ok)
+		}
+	}
+	if _, ok := assemblyAISpeakerIndex("UNKNOWN"); ok {
+		t.Error("Expected no index for UNKNOWN")
+	}
+}
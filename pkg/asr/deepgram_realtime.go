@@ -0,0 +1,706 @@
+// Package asr provides a unified interface for Automatic Speech Recognition (ASR) systems.
+//
+// DeepgramProvider implements real-time speech recognition using Deepgram's
+// streaming /v1/listen WebSocket API (Nova-3 by default).
+//
+// Features:
+//   - Real-time streaming ASR via WebSocket, raw PCM (linear16) sent as binary frames
+//   - Interim and final transcript support: is_final segments are accumulated
+//     and the utterance is final on speech_final (endpointing) or after Commit
+//   - Manual commit (Finalize) for VAD integration
+//   - KeepAlive while no audio is flowing, so the session survives silence
+//   - Connection retry with exponential backoff
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+const (
+	// Deepgram streaming WebSocket endpoint
+	deepgramRealtimeWSURL = "wss://api.deepgram.com/v1/listen"
+
+	// Default model
+	deepgramDefaultModel = "nova-3"
+
+	// Deepgram closes the stream after 10s without audio; keep it alive well before that
+	deepgramKeepAliveInterval = 5 * time.Second
+
+	// Connection configuration
+	deepgramMaxRetryAttempts  = 3
+	deepgramInitialRetryDelay = 1 * time.Second
+	deepgramMaxRetryDelay     = 4 * time.Second
+	deepgramConnectionTimeout = 10 * time.Second
+)
+
+// DeepgramProvider implements the Provider interface using Deepgram's streaming API.
+// It uses WebSocket for true streaming speech recognition.
+type DeepgramProvider struct {
+	apiKey      string
+	model       string
+	url         string
+	endpointing int
+	headers     map[string]string
+	mu          sync.RWMutex
+}
+
+// DeepgramConfig holds configuration for DeepgramProvider.
+type DeepgramConfig struct {
+	// APIKey is the Deepgram API key (required)
+	APIKey string
+
+	// Model to use (default: "nova-3")
+	Model string
+
+	// URL is the WebSocket endpoint (default: "wss://api.deepgram.com/v1/listen").
+	// Override it for self-hosted deployments or proxies.
+	URL string
+
+	// EndpointingMs is how much trailing silence Deepgram waits for before
+	// marking an utterance speech_final (default: 0, Deepgram's default).
+	// A negative value disables endpointing, so finals only follow Commit;
+	// use it when an upstream VAD drives turn detection.
+	EndpointingMs int
+
+	// Headers are extra headers sent on every connection (API gateway keys,
+	// org IDs, tracing headers). They override the provider's own headers of
+	// the same name.
+	Headers map[string]string
+}
+
+// NewDeepgramProvider creates a new Deepgram streaming ASR provider.
+func NewDeepgramProvider(config DeepgramConfig) (*DeepgramProvider, error) {
+	if config.APIKey == "" {
+		return nil, &Error{
+			Code:    ErrCodeInvalidConfig,
+			Message: "Deepgram API key is required",
+		}
+	}
+
+	model := config.Model
+	if model == "" {
+		model = deepgramDefaultModel
+	}
+	wsURL := config.URL
+	if wsURL == "" {
+		wsURL = deepgramRealtimeWSURL
+	}
+
+	return &DeepgramProvider{
+		apiKey:      config.APIKey,
+		model:       model,
+		url:         wsURL,
+		endpointing: config.EndpointingMs,
+		headers:     config.Headers,
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *DeepgramProvider) Name() string {
+	return "deepgram"
+}
+
+// Recognize performs speech recognition on a complete audio segment.
+// It streams the audio over a short-lived session and waits for the final transcript.
+func (p *DeepgramProvider) Recognize(ctx context.Context, audio io.Reader, audioConfig AudioConfig, config RecognitionConfig) (*RecognitionResult, error) {
+	audioData, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, &Error{
+			Code:    ErrCodeInvalidAudio,
+			Message: "failed to read audio data",
+			Err:     err,
+		}
+	}
+
+	if len(audioData) == 0 {
+		return nil, &Error{
+			Code:    ErrCodeInvalidAudio,
+			Message: "audio data is empty",
+		}
+	}
+
+	recognizer, err := p.StreamingRecognize(ctx, audioConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	defer recognizer.Close()
+
+	if err := recognizer.SendAudio(ctx, audioData); err != nil {
+		return nil, err
+	}
+	if err := recognizer.(*deepgramStreamingRecognizer).Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// Wait for final result with timeout
+	timeout := time.After(30 * time.Second)
+	for {
+		select {
+		case result, ok := <-recognizer.Results():
+			if !ok {
+				return emptyDeepgramResult(config.Language), nil
+			}
+			if result.IsFinal {
+				return result, nil
+			}
+		case <-timeout:
+			return emptyDeepgramResult(config.Language), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// emptyDeepgramResult is returned by Recognize when no transcript arrived.
+func emptyDeepgramResult(language string) *RecognitionResult {
+	return &RecognitionResult{
+		Text:       "",
+		IsFinal:    true,
+		Confidence: -1,
+		Language:   language,
+		Timestamp:  time.Now(),
+	}
+}
+
+// StreamingRecognize creates a streaming recognizer for continuous audio input.
+func (p *DeepgramProvider) StreamingRecognize(ctx context.Context, audioConfig AudioConfig, config RecognitionConfig) (StreamingRecognizer, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if audioConfig.Encoding != "" && audioConfig.Encoding != "pcm" {
+		return nil, &Error{
+			Code:    ErrCodeInvalidConfig,
+			Message: fmt.Sprintf("Deepgram streaming expects raw PCM audio, got %q", audioConfig.Encoding),
+		}
+	}
+
+	recognizer := &deepgramStreamingRecognizer{
+		provider:    p,
+		audioConfig: audioConfig,
+		config:      config,
+		resultsChan: make(chan *RecognitionResult, 10),
+		sendChan:    make(chan []byte, 100),
+		commitChan:  make(chan struct{}, 1),
+	}
+
+	if err := recognizer.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	return recognizer, nil
+}
+
+// SupportsStreaming indicates if the provider supports streaming recognition.
+func (p *DeepgramProvider) SupportsStreaming() bool {
+	return true
+}
+
+// SupportedLanguages returns a list of supported language codes.
+func (p *DeepgramProvider) SupportedLanguages() []string {
+	// Nova-3 language coverage; "multi" enables code-switching between them
+	return []string{
+		"en", "es", "fr", "de", "it", "pt", "nl", "ru", "ja", "ko",
+		"hi", "tr", "sv", "da", "no", "pl", "multi",
+	}
+}
+
+// Close releases any resources held by the provider.
+func (p *DeepgramProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return nil
+}
+
+// deepgramStreamingRecognizer implements StreamingRecognizer for Deepgram.
+type deepgramStreamingRecognizer struct {
+	provider    *DeepgramProvider
+	audioConfig AudioConfig
+	config      RecognitionConfig
+	resultsChan chan *RecognitionResult
+	sendChan    chan []byte
+	commitChan  chan struct{}
+	conn        *websocket.Conn
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	closed      atomic.Bool
+	startTime   time.Time
+	health      *utils.WSHealth
+
+	// Finalized (is_final) segments of the current utterance, only touched by readLoop
+	segments []string
+	words    []deepgramWord
+}
+
+// Deepgram message types
+type deepgramMessage struct {
+	Type         string          `json:"type"`
+	IsFinal      bool            `json:"is_final"`
+	SpeechFinal  bool            `json:"speech_final"`
+	FromFinalize bool            `json:"from_finalize"`
+	Start        float64         `json:"start"`
+	Duration     float64         `json:"duration"`
+	Channel      deepgramChannel `json:"channel"`
+	// Error messages
+	Description string `json:"description,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+type deepgramChannel struct {
+	Alternatives []deepgramAlternative `json:"alternatives"`
+}
+
+type deepgramAlternative struct {
+	Transcript string         `json:"transcript"`
+	Confidence float32        `json:"confidence"`
+	Words      []deepgramWord `json:"words"`
+}
+
+type deepgramWord struct {
+	Word           string  `json:"word"`
+	PunctuatedWord string  `json:"punctuated_word,omitempty"`
+	Start          float64 `json:"start"`
+	End            float64 `json:"end"`
+	Confidence     float32 `json:"confidence"`
+}
+
+type deepgramControlMessage struct {
+	Type string `json:"type"`
+}
+
+// connect establishes WebSocket connection with retry logic.
+func (r *deepgramStreamingRecognizer) connect(ctx context.Context) error {
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.startTime = time.Now()
+
+	var lastErr error
+	retryDelay := deepgramInitialRetryDelay
+
+	for attempt := 0; attempt < deepgramMaxRetryAttempts; attempt++ {
+		if err := r.doConnect(); err != nil {
+			lastErr = err
+			log.Printf("[Deepgram] Connection attempt %d/%d failed: %v", attempt+1, deepgramMaxRetryAttempts, err)
+
+			if attempt < deepgramMaxRetryAttempts-1 {
+				select {
+				case <-time.After(retryDelay):
+					retryDelay *= 2
+					if retryDelay > deepgramMaxRetryDelay {
+						retryDelay = deepgramMaxRetryDelay
+					}
+				case <-ctx.Done():
+					r.cancel()
+					return ctx.Err()
+				}
+			}
+			continue
+		}
+
+		// Successfully connected
+		return nil
+	}
+
+	r.cancel()
+	return &Error{
+		Code:    ErrCodeNetworkError,
+		Message: fmt.Sprintf("failed to connect after %d attempts", deepgramMaxRetryAttempts),
+		Err:     lastErr,
+	}
+}
+
+// listenURL builds the /v1/listen URL with the recognition options.
+func (r *deepgramStreamingRecognizer) listenURL() string {
+	sampleRate := r.audioConfig.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 16000
+	}
+	channels := r.audioConfig.Channels
+	if channels == 0 {
+		channels = 1
+	}
+
+	model := r.config.Model
+	if model == "" {
+		model = r.provider.model
+	}
+
+	params := url.Values{}
+	params.Set("model", model)
+	params.Set("encoding", "linear16")
+	params.Set("sample_rate", strconv.Itoa(sampleRate))
+	params.Set("channels", strconv.Itoa(channels))
+	params.Set("interim_results", strconv.FormatBool(r.config.EnablePartialResults))
+	params.Set("punctuate", "true")
+	params.Set("smart_format", "true")
+	if r.config.ProfanityFilter {
+		params.Set("profanity_filter", "true")
+	}
+	if language := r.normalizeLanguage(r.config.Language); language != "" {
+		params.Set("language", language)
+	}
+	switch {
+	case r.provider.endpointing < 0:
+		params.Set("endpointing", "false")
+	case r.provider.endpointing > 0:
+		params.Set("endpointing", strconv.Itoa(r.provider.endpointing))
+	}
+
+	return r.provider.url + "?" + params.Encode()
+}
+
+// doConnect performs the actual WebSocket connection.
+func (r *deepgramStreamingRecognizer) doConnect() error {
+	wsURL := r.listenURL()
+	log.Printf("[Deepgram] Connecting to %s", wsURL)
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: deepgramConnectionTimeout,
+	}
+
+	headers := http.Header{
+		"Authorization": {"Token " + r.provider.apiKey},
+	}
+	utils.MergeHeaders(headers, r.provider.headers)
+
+	conn, resp, err := dialer.DialContext(r.ctx, wsURL, headers)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("websocket dial failed (HTTP %d): %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("websocket dial failed: %w", err)
+	}
+
+	r.conn = conn
+	r.health = utils.NewWSHealth(conn, utils.DefaultWSPingInterval)
+	log.Printf("[Deepgram] WebSocket connected")
+
+	// The stream is ready as soon as the WebSocket is open
+	r.wg.Add(2)
+	go r.readLoop()
+	go r.writeLoop()
+
+	return nil
+}
+
+// readLoop handles incoming WebSocket messages.
+func (r *deepgramStreamingRecognizer) readLoop() {
+	defer r.wg.Done()
+	defer r.health.Close()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		default:
+		}
+
+		_, message, err := r.conn.ReadMessage()
+		if err != nil {
+			if !r.closed.Load() && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("[Deepgram] WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		r.health.Touch()
+		r.handleMessage(message)
+	}
+}
+
+// writeLoop sends audio as binary frames and Finalize on commit. While no
+// audio flows (e.g. VAD only forwards speech) it sends KeepAlive messages.
+func (r *deepgramStreamingRecognizer) writeLoop() {
+	defer r.wg.Done()
+
+	keepAlive := time.NewTicker(deepgramKeepAliveInterval)
+	defer keepAlive.Stop()
+	sentAudio := false
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+
+		case audioData, ok := <-r.sendChan:
+			if !ok {
+				return
+			}
+			r.write(websocket.BinaryMessage, audioData)
+			sentAudio = true
+
+		case <-r.commitChan:
+			// Audio queued before the commit belongs to the utterance being finalized
+			for drained := false; !drained; {
+				select {
+				case audioData, ok := <-r.sendChan:
+					if !ok {
+						return
+					}
+					r.write(websocket.BinaryMessage, audioData)
+					sentAudio = true
+				default:
+					drained = true
+				}
+			}
+			r.sendControl("Finalize")
+			log.Printf("[Deepgram] Sent finalize")
+
+		case <-keepAlive.C:
+			if !sentAudio {
+				r.sendControl("KeepAlive")
+			}
+			sentAudio = false
+		}
+	}
+}
+
+// sendControl sends a JSON control message (Finalize, KeepAlive, CloseStream).
+func (r *deepgramStreamingRecognizer) sendControl(messageType string) {
+	data, err := json.Marshal(deepgramControlMessage{Type: messageType})
+	if err != nil {
+		log.Printf("[Deepgram] Failed to marshal %s: %v", messageType, err)
+		return
+	}
+	r.write(websocket.TextMessage, data)
+}
+
+func (r *deepgramStreamingRecognizer) write(messageType int, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil {
+		if err := r.conn.WriteMessage(messageType, data); err != nil {
+			log.Printf("[Deepgram] Failed to send message: %v", err)
+		}
+	}
+}
+
+// handleMessage processes incoming WebSocket messages.
+func (r *deepgramStreamingRecognizer) handleMessage(data []byte) {
+	var msg deepgramMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Printf("[Deepgram] Failed to parse message: %v", err)
+		return
+	}
+
+	switch msg.Type {
+	case "Results":
+		r.handleResults(msg)
+
+	case "Metadata", "SpeechStarted", "UtteranceEnd":
+		// Informational, turn boundaries come from speech_final or Commit
+
+	case "Error":
+		log.Printf("[Deepgram] Error: %s %s", msg.Description, msg.Message)
+
+	default:
+		log.Printf("[Deepgram] Unknown message type: %s", msg.Type)
+	}
+}
+
+// handleResults maps Deepgram's flags to partial and final results:
+//   - is_final=false: interim hypothesis, a partial of the utterance so far
+//   - is_final=true: the segment will not change; it is kept and, with
+//     interim results enabled, the utterance so far is sent as a partial
+//   - speech_final=true or from_finalize=true: the utterance is complete and
+//     all its segments are sent as one final result
+func (r *deepgramStreamingRecognizer) handleResults(msg deepgramMessage) {
+	var alt deepgramAlternative
+	if len(msg.Channel.Alternatives) > 0 {
+		alt = msg.Channel.Alternatives[0]
+	}
+
+	if !msg.IsFinal {
+		if alt.Transcript == "" || !r.config.EnablePartialResults {
+			return
+		}
+		r.emit(r.utterance(alt.Transcript), false, alt.Confidence, nil)
+		return
+	}
+
+	if alt.Transcript != "" {
+		r.segments = append(r.segments, alt.Transcript)
+		r.words = append(r.words, alt.Words...)
+	}
+
+	if !msg.SpeechFinal && !msg.FromFinalize {
+		if alt.Transcript != "" && r.config.EnablePartialResults {
+			r.emit(r.utterance(""), false, alt.Confidence, nil)
+		}
+		return
+	}
+
+	// Finalize on an empty buffer still yields a (blank) final, so commits always get an answer
+	text := r.utterance("")
+	words := r.words
+	r.segments = nil
+	r.words = nil
+	if text == "" && !msg.FromFinalize {
+		return
+	}
+	r.emit(text, true, alt.Confidence, words)
+	r.startTime = time.Now()
+}
+
+// utterance joins the finalized segments and an optional interim tail.
+func (r *deepgramStreamingRecognizer) utterance(tail string) string {
+	parts := r.segments
+	if tail != "" {
+		parts = append(parts[:len(parts):len(parts)], tail)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r *deepgramStreamingRecognizer) emit(text string, isFinal bool, confidence float32, words []deepgramWord) {
+	result := &RecognitionResult{
+		Text:       text,
+		IsFinal:    isFinal,
+		Confidence: confidence,
+		Language:   r.config.Language,
+		Duration:   time.Since(r.startTime),
+		Timestamp:  time.Now(),
+	}
+	if isFinal {
+		result.Metadata = map[string]interface{}{
+			"words": words,
+		}
+		log.Printf("[Deepgram] Final: %s", text)
+	}
+
+	select {
+	case r.resultsChan <- result:
+	case <-r.ctx.Done():
+	default:
+		log.Printf("[Deepgram] Results channel full, dropping result")
+	}
+}
+
+// SendAudio sends audio data to the recognizer.
+func (r *deepgramStreamingRecognizer) SendAudio(ctx context.Context, audioData []byte) error {
+	if r.closed.Load() {
+		return &Error{
+			Code:    ErrCodeProviderError,
+			Message: "recognizer is closed",
+		}
+	}
+
+	select {
+	case r.sendChan <- audioData:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// Commit finalizes the audio sent so far, so the current utterance is
+// transcribed and returned as a final result without waiting for endpointing.
+func (r *deepgramStreamingRecognizer) Commit(ctx context.Context) error {
+	if r.closed.Load() {
+		return &Error{
+			Code:    ErrCodeProviderError,
+			Message: "recognizer is closed",
+		}
+	}
+
+	select {
+	case r.commitChan <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// Results returns a channel that receives recognition results.
+func (r *deepgramStreamingRecognizer) Results() <-chan *RecognitionResult {
+	return r.resultsChan
+}
+
+// Healthy reports whether the WebSocket is open and the server has sent
+// a message or pong within the last two ping intervals.
+func (r *deepgramStreamingRecognizer) Healthy() bool {
+	return !r.closed.Load() && r.health.Healthy()
+}
+
+// Close stops recognition and releases resources.
+func (r *deepgramStreamingRecognizer) Close() error {
+	if r.closed.Swap(true) {
+		return nil // Already closed
+	}
+
+	log.Printf("[Deepgram] Closing recognizer")
+
+	// Let the server release the stream right away
+	r.sendControl("CloseStream")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	r.mu.Lock()
+	if r.conn != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+	r.mu.Unlock()
+
+	// Close channels
+	close(r.sendChan)
+	close(r.commitChan)
+
+	// Wait for goroutines
+	r.wg.Wait()
+
+	// Close results channel
+	close(r.resultsChan)
+
+	log.Printf("[Deepgram] Recognizer closed")
+	return nil
+}
+
+// normalizeLanguage converts a language code to Deepgram's format.
+// Empty keeps Deepgram's default (English); "auto" uses multilingual mode.
+func (r *deepgramStreamingRecognizer) normalizeLanguage(language string) string {
+	switch language {
+	case "":
+		return ""
+	case "auto":
+		return "multi"
+	}
+	// Deepgram accepts BCP-47 tags such as "en-US"
+	return strings.ReplaceAll(language, "_", "-")
+}
+
+// DeepgramStreamingRecognizer interface for accessing Commit method
+type DeepgramStreamingRecognizer interface {
+	StreamingRecognizer
+	// Commit finalizes the current utterance (Deepgram's Finalize message).
+	Commit(ctx context.Context) error
+}
+
+// Ensure deepgramStreamingRecognizer implements DeepgramStreamingRecognizer
+var _ DeepgramStreamingRecognizer = (*deepgramStreamingRecognizer)(nil)
+
+// IsDeepgramRecognizer checks if a recognizer is a Deepgram recognizer.
+func IsDeepgramRecognizer(r StreamingRecognizer) (DeepgramStreamingRecognizer, bool) {
+	dr, ok := r.(*deepgramStreamingRecognizer)
+	return dr, ok
+}
@@ -0,0 +1,189 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNewDeepgramProvider_NoAPIKey(t *testing.T) {
+	if _, err := NewDeepgramProvider(DeepgramConfig{}); err == nil {
+		t.Error("Expected error when API key is missing")
+	}
+
+	provider, err := NewDeepgramProvider(DeepgramConfig{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if provider.Name() != "deepgram" || provider.model != "nova-3" {
+		t.Errorf("Unexpected provider %s with model %s", provider.Name(), provider.model)
+	}
+}
+
+// deepgramResults builds a Results message
+func deepgramResults(transcript string, isFinal, speechFinal, fromFinalize bool) []byte {
+	msg := map[string]interface{}{
+		"type":          "Results",
+		"is_final":      isFinal,
+		"speech_final":  speechFinal,
+		"from_finalize": fromFinalize,
+		"channel": map[string]interface{}{
+			"alternatives": []map[string]interface{}{
+				{"transcript": transcript, "confidence": 0.9},
+			},
+		},
+	}
+	data, _ := json.Marshal(msg)
+	return data
+}
+
+// startMockDeepgramServer accepts one streaming connection. Binary frames are
+// recorded as audio; on Finalize it replies with script.
+func startMockDeepgramServer(t *testing.T, script [][]byte) (*httptest.Server, <-chan *http.Request, <-chan string) {
+	t.Helper()
+	requests := make(chan *http.Request, 1)
+	received := make(chan string, 20)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.BinaryMessage {
+				received <- "audio:" + string(msg)
+				continue
+			}
+			var control deepgramControlMessage
+			json.Unmarshal(msg, &control)
+			received <- control.Type
+			if control.Type == "Finalize" {
+				for _, reply := range script {
+					conn.WriteMessage(websocket.TextMessage, reply)
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, received
+}
+
+func TestDeepgramStreamingRecognize(t *testing.T) {
+	server, requests, received := startMockDeepgramServer(t, [][]byte{
+		deepgramResults("hello", false, false, false),
+		deepgramResults("hello world", true, false, false),
+		deepgramResults("how", false, false, false),
+		deepgramResults("how are you", true, false, true),
+		// Finalize with nothing pending still answers with a blank final
+		deepgramResults("", true, false, true),
+	})
+
+	provider, err := NewDeepgramProvider(DeepgramConfig{
+		APIKey:        "test-api-key",
+		URL:           "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/listen",
+		EndpointingMs: -1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	recognizer, err := provider.StreamingRecognize(context.Background(),
+		AudioConfig{SampleRate: 8000, Channels: 1, Encoding: "pcm", BitsPerSample: 16},
+		RecognitionConfig{Language: "en-US", EnablePartialResults: true})
+	if err != nil {
+		t.Fatalf("StreamingRecognize failed: %v", err)
+	}
+	defer recognizer.Close()
+
+	req := <-requests
+	if auth := req.Header.Get("Authorization"); auth != "Token test-api-key" {
+		t.Errorf("Unexpected Authorization header %q", auth)
+	}
+	q := req.URL.Query()
+	for key, want := range map[string]string{
+		"model": "nova-3", "encoding": "linear16", "sample_rate": "8000", "channels": "1",
+		"interim_results": "true", "language": "en-US", "endpointing": "false",
+	} {
+		if got := q.Get(key); got != want {
+			t.Errorf("Expected %s=%s, got %q", key, want, got)
+		}
+	}
+
+	dr, ok := IsDeepgramRecognizer(recognizer)
+	if !ok {
+		t.Fatal("Expected a Deepgram recognizer")
+	}
+	ctx := context.Background()
+	if err := dr.SendAudio(ctx, []byte("pcm")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dr.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"audio:pcm", "Finalize"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("Expected server to receive %q, got %q", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for %q", want)
+		}
+	}
+
+	type result struct {
+		text    string
+		isFinal bool
+	}
+	want := []result{
+		{"hello", false},
+		{"hello world", false},
+		{"hello world how", false},
+		{"hello world how are you", true},
+		{"", true},
+	}
+	for i, w := range want {
+		select {
+		case r := <-recognizer.Results():
+			if r.Text != w.text || r.IsFinal != w.isFinal {
+				t.Fatalf("Result %d: expected %+v, got %q (final %v)", i, w, r.Text, r.IsFinal)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for result %d", i)
+		}
+	}
+}
+
+func TestDeepgramSpeechFinal(t *testing.T) {
+	r := &deepgramStreamingRecognizer{
+		config:      RecognitionConfig{EnablePartialResults: false},
+		resultsChan: make(chan *RecognitionResult, 10),
+		ctx:         context.Background(),
+	}
+
+	// Without interim results only the endpointed utterance is reported
+	r.handleMessage(deepgramResults("book a table", true, false, false))
+	r.handleMessage(deepgramResults("for two", true, true, false))
+	// Endpointing on silence carries no transcript and produces nothing
+	r.handleMessage(deepgramResults("", true, true, false))
+
+	if len(r.resultsChan) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(r.resultsChan))
+	}
+	result := <-r.resultsChan
+	if result.Text != "book a table for two" || !result.IsFinal {
+		t.Errorf("Expected final 'book a table for two', got %q (final %v)", result.Text, result.IsFinal)
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
 const (
@@ -48,9 +50,10 @@ const (
 // ElevenLabsProvider implements the Provider interface using ElevenLabs Scribe V2 Realtime API.
 // It uses WebSocket for true streaming speech recognition.
 type ElevenLabsProvider struct {
-	apiKey string
-	model  string
-	mu     sync.RWMutex
+	apiKey  string
+	model   string
+	headers map[string]string
+	mu      sync.RWMutex
 }
 
 // ElevenLabsConfig holds configuration for ElevenLabsProvider.
@@ -60,6 +63,11 @@ type ElevenLabsConfig struct {
 
 	// Model to use (default: "scribe_v2_realtime")
 	Model string
+
+	// Headers are extra headers sent on every connection (API gateway keys,
+	// org IDs, tracing headers). They override the provider's own headers of
+	// the same name.
+	Headers map[string]string
 }
 
 // NewElevenLabsProvider creates a new ElevenLabs Realtime ASR provider.
@@ -77,8 +85,9 @@ func NewElevenLabsProvider(config ElevenLabsConfig) (*ElevenLabsProvider, error)
 	}
 
 	return &ElevenLabsProvider{
-		apiKey: config.APIKey,
-		model:  model,
+		apiKey:  config.APIKey,
+		model:   model,
+		headers: config.Headers,
 	}, nil
 }
 
@@ -189,6 +198,8 @@ func (p *ElevenLabsProvider) StreamingRecognize(ctx context.Context, audioConfig
 		resultsChan: make(chan *RecognitionResult, 10),
 		sendChan:    make(chan []byte, 100),
 		commitChan:  make(chan struct{}, 1),
+		ready:       make(chan struct{}),
+		prebuffer:   newAudioPrebuffer("ElevenLabs", audioConfig, config.PrebufferMs),
 	}
 
 	// Start connection
@@ -235,8 +246,12 @@ type elevenlabsStreamingRecognizer struct {
 	mu           sync.Mutex
 	closed       atomic.Bool
 	sessionReady atomic.Bool
+	ready        chan struct{} // closed once the session is ready
+	prebuffer    *audioPrebuffer
 	startTime    time.Time
 	sentenceID   string
+	health       *utils.WSHealth
+	connErr      atomic.Pointer[error] // why the connection ended, see ConnectionError
 }
 
 // ElevenLabs message types
@@ -250,10 +265,13 @@ type elevenlabsMessage struct {
 	Error       *elevenlabsError `json:"error,omitempty"`
 }
 
+// elevenlabsWord is an entry of committed_transcript_with_timestamps;
+// start and end are in seconds
 type elevenlabsWord struct {
 	Text       string  `json:"text"`
-	StartTime  int     `json:"start_time"`
-	EndTime    int     `json:"end_time"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Type       string  `json:"type"` // "word", "spacing" or "audio_event"
 	Confidence float32 `json:"confidence"`
 }
 
@@ -314,6 +332,10 @@ func (r *elevenlabsStreamingRecognizer) doConnect() error {
 	params := url.Values{}
 	params.Set("model_id", r.provider.model)
 	params.Set("commit_strategy", "manual")
+	if r.config.Timestamps != "" {
+		// Finals then arrive as committed_transcript_with_timestamps
+		params.Set("include_timestamps", "true")
+	}
 
 	// Add language_code if specified
 	if r.config.Language != "" && r.config.Language != "auto" {
@@ -332,6 +354,7 @@ func (r *elevenlabsStreamingRecognizer) doConnect() error {
 	headers := map[string][]string{
 		"xi-api-key": {r.provider.apiKey},
 	}
+	utils.MergeHeaders(headers, r.provider.headers)
 
 	conn, _, err := dialer.DialContext(r.ctx, wsURL, headers)
 	if err != nil {
@@ -339,6 +362,7 @@ func (r *elevenlabsStreamingRecognizer) doConnect() error {
 	}
 
 	r.conn = conn
+	r.health = utils.NewWSHealth(conn, utils.DefaultWSPingInterval)
 	log.Printf("[ElevenLabs] WebSocket connected")
 
 	// Start message handlers
@@ -369,6 +393,7 @@ func (r *elevenlabsStreamingRecognizer) doConnect() error {
 // readLoop handles incoming WebSocket messages.
 func (r *elevenlabsStreamingRecognizer) readLoop() {
 	defer r.wg.Done()
+	defer r.health.Close()
 
 	for {
 		select {
@@ -381,47 +406,85 @@ func (r *elevenlabsStreamingRecognizer) readLoop() {
 		if err != nil {
 			if !r.closed.Load() && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 				log.Printf("[ElevenLabs] WebSocket read error: %v", err)
+				r.connErr.Store(&err)
 			}
 			return
 		}
 
+		r.health.Touch()
 		r.handleMessage(message)
 	}
 }
 
 // writeLoop handles outgoing messages.
+// Audio and commits that arrive before the session is ready are held in the
+// prebuffer and sent, in order, once it is.
 func (r *elevenlabsStreamingRecognizer) writeLoop() {
 	defer r.wg.Done()
 
+	ready := r.ready
+	flushIfReady := func() bool {
+		if ready == nil {
+			return true
+		}
+		select {
+		case <-ready:
+		default:
+			return false
+		}
+		ready = nil
+		chunks, commit := r.prebuffer.drain()
+		for _, chunk := range chunks {
+			r.sendAudioChunk(chunk, false)
+		}
+		if commit {
+			r.sendCommit()
+		}
+		return true
+	}
+
 	for {
 		select {
 		case <-r.ctx.Done():
 			return
 
+		case <-ready:
+			flushIfReady()
+
 		case audioData, ok := <-r.sendChan:
 			if !ok {
 				return
 			}
 
-			if !r.sessionReady.Load() {
-				log.Printf("[ElevenLabs] Session not ready, queuing audio")
+			if !flushIfReady() {
+				r.prebuffer.add(audioData)
 				continue
 			}
-
 			r.sendAudioChunk(audioData, false)
 
 		case <-r.commitChan:
-			if !r.sessionReady.Load() {
+			if !flushIfReady() {
+				r.prebuffer.commit = true
 				continue
 			}
-
-			// Send empty audio with commit=true
-			r.sendAudioChunk([]byte{}, true)
-			log.Printf("[ElevenLabs] Sent commit")
+			r.sendCommit()
 		}
 	}
 }
 
+// sendCommit sends an empty audio chunk with commit=true.
+func (r *elevenlabsStreamingRecognizer) sendCommit() {
+	r.sendAudioChunk([]byte{}, true)
+	log.Printf("[ElevenLabs] Sent commit")
+}
+
+// markReady marks the session as ready and releases the prebuffered audio.
+func (r *elevenlabsStreamingRecognizer) markReady() {
+	if r.sessionReady.CompareAndSwap(false, true) {
+		close(r.ready)
+	}
+}
+
 // sendAudioChunk sends an audio chunk to the WebSocket.
 func (r *elevenlabsStreamingRecognizer) sendAudioChunk(audioData []byte, commit bool) {
 	chunk := elevenlabsAudioChunk{
@@ -457,7 +520,7 @@ func (r *elevenlabsStreamingRecognizer) handleMessage(data []byte) {
 	switch msg.MessageType {
 	case "session_started":
 		log.Printf("[ElevenLabs] Session started")
-		r.sessionReady.Store(true)
+		r.markReady()
 		r.startTime = time.Now()
 
 	case "partial_transcript":
@@ -490,6 +553,10 @@ func (r *elevenlabsStreamingRecognizer) handleMessage(data []byte) {
 		}
 
 	case "committed_transcript", "committed_transcript_with_timestamps":
+		if msg.MessageType == "committed_transcript" && r.config.Timestamps != "" {
+			// The same transcript follows with timestamps
+			return
+		}
 		if msg.Text != "" {
 			confidence := float32(0.95)
 			if msg.Confidence != nil {
@@ -508,6 +575,7 @@ func (r *elevenlabsStreamingRecognizer) handleMessage(data []byte) {
 					"words":       msg.Words,
 				},
 			}
+			setElevenLabsTimings(result, msg.Words)
 
 			log.Printf("[ElevenLabs] Final: %s", msg.Text)
 
@@ -533,6 +601,25 @@ func (r *elevenlabsStreamingRecognizer) handleMessage(data []byte) {
 	}
 }
 
+// setElevenLabsTimings copies the word timings of a committed transcript into
+// result. The result spans from the first word to the last.
+func setElevenLabsTimings(result *RecognitionResult, words []elevenlabsWord) {
+	for _, word := range words {
+		if word.Type != "" && word.Type != "word" {
+			continue
+		}
+		result.Words = append(result.Words, WordTiming{
+			Word:      word.Text,
+			Start: secondsToDuration(word.Start),
+			End:   secondsToDuration(word.End),
+		})
+	}
+	if n := len(result.Words); n > 0 {
+		result.StartTime = result.Words[0].Start
+		result.EndTime = result.Words[n-1].End
+	}
+}
+
 // SendAudio sends audio data to the recognizer.
 func (r *elevenlabsStreamingRecognizer) SendAudio(ctx context.Context, audioData []byte) error {
 	if r.closed.Load() {
@@ -576,6 +663,21 @@ func (r *elevenlabsStreamingRecognizer) Results() <-chan *RecognitionResult {
 	return r.resultsChan
 }
 
+// Healthy reports whether the WebSocket is open and the server has sent
+// a message or pong within the last two ping intervals.
+func (r *elevenlabsStreamingRecognizer) Healthy() bool {
+	return !r.closed.Load() && r.health.Healthy()
+}
+
+// ConnectionError returns the read error that dropped the WebSocket, if any.
+// It implements ConnectionErrorReporter.
+func (r *elevenlabsStreamingRecognizer) ConnectionError() error {
+	if err := r.connErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
 // Close stops recognition and releases resources.
 func (r *elevenlabsStreamingRecognizer) Close() error {
 	if r.closed.Swap(true) {
@@ -651,6 +753,7 @@ type ElevenLabsStreamingRecognizer interface {
 
 // Ensure elevenlabsStreamingRecognizer implements ElevenLabsStreamingRecognizer
 var _ ElevenLabsStreamingRecognizer = (*elevenlabsStreamingRecognizer)(nil)
+var _ ConnectionErrorReporter = (*elevenlabsStreamingRecognizer)(nil)
 
 // IsElevenLabsRecognizer checks if a recognizer is an ElevenLabs recognizer.
 func IsElevenLabsRecognizer(r StreamingRecognizer) (ElevenLabsStreamingRecognizer, bool) {
@@ -258,6 +258,37 @@ func TestIsElevenLabsRecognizer(t *testing.T) {
 	// but we've verified the interface compliance at compile time via the var _ check
 }
 
+func TestElevenLabsRecognizer_CommittedTimestamps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &elevenlabsStreamingRecognizer{
+		config:      RecognitionConfig{Timestamps: TimestampsWord},
+		resultsChan: make(chan *RecognitionResult, 10),
+		ctx:         ctx,
+	}
+
+	// The plain committed transcript is followed by the same text with timestamps
+	r.handleMessage([]byte(`{"message_type":"committed_transcript","text":"Hi there"}`))
+	r.handleMessage([]byte(`{"message_type":"committed_transcript_with_timestamps","text":"Hi there","words":[
+		{"text":"Hi","start":1.2,"end":1.5,"type":"word"},
+		{"text":" ","start":1.5,"end":1.6,"type":"spacing"},
+		{"text":"there","start":1.6,"end":2.05,"type":"word"}]}`))
+
+	if n := len(r.resultsChan); n != 1 {
+		t.Fatalf("Expected one final result, got %d", n)
+	}
+	result := <-r.resultsChan
+	if !result.IsFinal || result.Text != "Hi there" {
+		t.Errorf("Unexpected result %q (final: %v)", result.Text, result.IsFinal)
+	}
+	if result.StartTime != 1200*time.Millisecond || result.EndTime != 2050*time.Millisecond {
+		t.Errorf("Expected result to span 1.2s-2.05s, got %v-%v", result.StartTime, result.EndTime)
+	}
+	if len(result.Words) != 2 || result.Words[0].Word != "Hi" || result.Words[1].Word != "there" {
+		t.Errorf("Expected the two words without spacing, got %+v", result.Words)
+	}
+}
+
 // Integration test that requires a valid ElevenLabs API key
 func TestElevenLabsProvider_Integration(t *testing.T) {
 	apiKey := os.Getenv("ELEVENLABS_API_KEY")
@@ -29,10 +29,27 @@ type RecognitionResult struct {
 	// Timestamp when recognition completed
 	Timestamp time.Time
 
+	// StartTime and EndTime locate the recognized speech in the audio sent to
+	// the recognizer, as offsets from its first sample. Both are zero if the
+	// provider doesn't report timings.
+	StartTime time.Duration
+	EndTime   time.Duration
+
+	// Words holds word-level timings (same offsets as StartTime), if the
+	// provider supplies them
+	Words []WordTiming
+
 	// Additional provider-specific metadata
 	Metadata map[string]interface{}
 }
 
+// WordTiming is the timing of one recognized word.
+type WordTiming struct {
+	Word  string
+	Start time.Duration
+	End   time.Duration
+}
+
 // AudioConfig specifies the audio format for recognition.
 type AudioConfig struct {
 	// SampleRate in Hz (e.g., 16000, 48000)
@@ -70,13 +87,49 @@ type RecognitionConfig struct {
 	// Prompt or context to guide the recognition (if supported)
 	Prompt string
 
+	// PromptFunc, if set, is evaluated before each transcription and overrides
+	// Prompt, so the context can follow the conversation (recent turns,
+	// expected vocabulary). It may be called from the recognizer's goroutines.
+	PromptFunc func() string
+
 	// Temperature for sampling (OpenAI Whisper specific, 0.0-1.0)
 	Temperature float32
 
+	// Task is "transcribe" (default) or "translate" (OpenAI Whisper specific:
+	// transcribe and translate to English in a single call)
+	Task string
+
+	// PrebufferMs is how much audio (in milliseconds) a streaming recognizer
+	// holds while its session is still being set up; it is sent once the
+	// session is ready instead of being dropped. 0 uses the default (2000ms),
+	// a negative value disables buffering. Only used by WebSocket providers.
+	PrebufferMs int
+
+	// Timestamps requests timings in the results: TimestampsSegment for
+	// the start and end of each result, TimestampsWord for word timings as
+	// well. Empty (default) requests none; providers that always report
+	// timings ignore it.
+	Timestamps string
+
 	// Additional provider-specific configuration
 	Extra map[string]interface{}
 }
 
+// Timestamp granularities for RecognitionConfig.Timestamps
+const (
+	TimestampsSegment = "segment"
+	TimestampsWord    = "word"
+)
+
+// CurrentPrompt returns the prompt for the next transcription: the result of
+// PromptFunc if set, otherwise Prompt.
+func (c RecognitionConfig) CurrentPrompt() string {
+	if c.PromptFunc != nil {
+		return c.PromptFunc()
+	}
+	return c.Prompt
+}
+
 // StreamingRecognizer handles continuous speech recognition from an audio stream.
 type StreamingRecognizer interface {
 	// SendAudio sends audio data to the recognizer.
@@ -91,6 +144,13 @@ type StreamingRecognizer interface {
 	Close() error
 }
 
+// ConnectionErrorReporter is implemented by streaming recognizers that hold a
+// connection to the provider. ConnectionError returns the error that ended
+// the connection, or nil while it is open or after a normal close.
+type ConnectionErrorReporter interface {
+	ConnectionError() error
+}
+
 // Provider is the main interface for ASR systems.
 type Provider interface {
 	// Name returns the provider name (e.g., "openai-whisper", "google-cloud", "azure")
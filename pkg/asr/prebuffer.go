@@ -0,0 +1,91 @@
+// Package asr provides a unified interface for Automatic Speech Recognition (ASR) systems.
+//
+// audioPrebuffer implements RecognitionConfig.PrebufferMs for the streaming
+// providers that connect lazily (ElevenLabs, Qwen). The first words of a call
+// arrive while the WebSocket is still connecting; they are held, up to
+// PrebufferMs of audio (default 2000ms), and flushed in order once the session
+// is ready. A commit requested meanwhile is replayed after the flush.
+package asr
+
+import "log"
+
+// defaultPrebufferMs is how much audio is held by default while a streaming
+// session is being set up
+const defaultPrebufferMs = 2000
+
+// audioPrebuffer holds audio sent to a streaming recognizer before its
+// session is ready, so the start of the first utterance is not lost while
+// the WebSocket connects. It is bounded: when full, the oldest audio is
+// dropped. It is only used from the recognizer's write loop.
+type audioPrebuffer struct {
+	name     string // provider name for logging
+	maxBytes int
+	chunks   [][]byte
+	size     int
+	dropped  int
+	commit   bool // a commit was requested while buffering
+}
+
+// newAudioPrebuffer creates a prebuffer for RecognitionConfig.PrebufferMs of audio
+func newAudioPrebuffer(name string, audioConfig AudioConfig, prebufferMs int) *audioPrebuffer {
+	if prebufferMs == 0 {
+		prebufferMs = defaultPrebufferMs
+	}
+	if prebufferMs < 0 {
+		prebufferMs = 0
+	}
+
+	sampleRate := audioConfig.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+	channels := audioConfig.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	bytesPerSample := audioConfig.BitsPerSample / 8
+	if bytesPerSample <= 0 {
+		bytesPerSample = 2
+	}
+
+	return &audioPrebuffer{
+		name:     name,
+		maxBytes: sampleRate * channels * bytesPerSample * prebufferMs / 1000,
+	}
+}
+
+// add buffers a chunk, dropping the oldest audio if the buffer is full
+func (b *audioPrebuffer) add(audioData []byte) {
+	if len(audioData) > b.maxBytes {
+		b.dropped += b.size + len(audioData)
+		b.chunks = nil
+		b.size = 0
+		return
+	}
+
+	b.chunks = append(b.chunks, audioData)
+	b.size += len(audioData)
+	for b.size > b.maxBytes {
+		b.size -= len(b.chunks[0])
+		b.dropped += len(b.chunks[0])
+		b.chunks = b.chunks[1:]
+	}
+}
+
+// drain returns the buffered audio in order and whether a commit was
+// requested after it, and empties the buffer
+func (b *audioPrebuffer) drain() (chunks [][]byte, commit bool) {
+	if b.dropped > 0 {
+		log.Printf("[%s] Session was not ready in time, dropped %d bytes of early audio", b.name, b.dropped)
+	}
+	if len(b.chunks) > 0 {
+		log.Printf("[%s] Session ready, flushing %d bytes of buffered audio", b.name, b.size)
+	}
+
+	chunks, commit = b.chunks, b.commit
+	b.chunks = nil
+	b.size = 0
+	b.dropped = 0
+	b.commit = false
+	return chunks, commit
+}
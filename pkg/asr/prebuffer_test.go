@@ -0,0 +1,134 @@
+package asr
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestAudioPrebuffer_Bounded(t *testing.T) {
+	// 100ms of 16kHz mono 16-bit audio = 3200 bytes
+	b := newAudioPrebuffer("test", AudioConfig{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, 100)
+	if b.maxBytes != 3200 {
+		t.Fatalf("Expected 3200 bytes, got %d", b.maxBytes)
+	}
+
+	for i := byte(0); i < 5; i++ {
+		b.add([]byte{i})
+	}
+	b.add(make([]byte, 3199)) // Pushes out the oldest chunks
+	chunks, commit := b.drain()
+	if len(chunks) != 2 || chunks[0][0] != 4 || commit {
+		t.Errorf("Expected the newest chunk and the large one, got %d chunks", len(chunks))
+	}
+
+	disabled := newAudioPrebuffer("test", AudioConfig{SampleRate: 16000}, -1)
+	disabled.add([]byte{1, 2})
+	if chunks, _ := disabled.drain(); len(chunks) != 0 {
+		t.Errorf("Expected no buffering when disabled, got %d chunks", len(chunks))
+	}
+}
+
+// startRecordingWSServer accepts one WebSocket connection and forwards every
+// text message it receives
+func startRecordingWSServer(t *testing.T) (*httptest.Server, <-chan []byte) {
+	t.Helper()
+	received := make(chan []byte, 20)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- msg
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestQwenRealtime_AudioBeforeSessionReady(t *testing.T) {
+	server, received := startRecordingWSServer(t)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &qwenRealtimeStreamingRecognizer{
+		provider:    &QwenRealtimeProvider{model: "test"},
+		resultsChan: make(chan *RecognitionResult, 10),
+		sendChan:    make(chan []byte, 100),
+		commitChan:  make(chan struct{}, 1),
+		ready:       make(chan struct{}),
+		prebuffer:   newAudioPrebuffer("QwenRealtime", AudioConfig{SampleRate: 16000}, 0),
+		conn:        conn,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	r.wg.Add(1)
+	go r.writeLoop()
+	defer r.Close()
+
+	// The first utterance is spoken and committed while the session is still being set up
+	ctx = context.Background()
+	if err := r.SendAudio(ctx, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SendAudio(ctx, []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-received:
+		t.Fatalf("Expected nothing to be sent before the session is ready, got %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r.handleSessionUpdated([]byte(`{"type":"session.updated","session":{"id":"sess_1"}}`))
+	if err := r.SendAudio(ctx, []byte("third")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case msg := <-received:
+			var event qwenAudioAppendEvent
+			if err := json.Unmarshal(msg, &event); err != nil {
+				t.Fatalf("Invalid event %s: %v", msg, err)
+			}
+			if event.Type == "input_audio_buffer.commit" {
+				got = append(got, "commit")
+				continue
+			}
+			audio, _ := base64.StdEncoding.DecodeString(event.Audio)
+			got = append(got, string(audio))
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for buffered audio, got %v", got)
+		}
+	}
+
+	want := []string{"first", "second", "commit", "third"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
 const (
@@ -31,9 +33,10 @@ const (
 // QwenRealtimeProvider implements the Provider interface using Alibaba Cloud DashScope Qwen Realtime ASR API.
 // It uses WebSocket for true streaming speech recognition.
 type QwenRealtimeProvider struct {
-	apiKey string
-	model  string
-	mu     sync.RWMutex
+	apiKey  string
+	model   string
+	headers map[string]string
+	mu      sync.RWMutex
 }
 
 // QwenRealtimeConfig holds configuration for QwenRealtimeProvider.
@@ -43,6 +46,11 @@ type QwenRealtimeConfig struct {
 
 	// Model to use (default: "qwen3-asr-flash-realtime")
 	Model string
+
+	// Headers are extra headers sent on every connection (API gateway keys,
+	// org IDs, tracing headers). They override the provider's own headers of
+	// the same name.
+	Headers map[string]string
 }
 
 // NewQwenRealtimeProvider creates a new Qwen Realtime ASR provider.
@@ -60,8 +68,9 @@ func NewQwenRealtimeProvider(config QwenRealtimeConfig) (*QwenRealtimeProvider,
 	}
 
 	return &QwenRealtimeProvider{
-		apiKey: config.APIKey,
-		model:  model,
+		apiKey:  config.APIKey,
+		model:   model,
+		headers: config.Headers,
 	}, nil
 }
 
@@ -145,6 +154,8 @@ func (p *QwenRealtimeProvider) StreamingRecognize(ctx context.Context, audioConf
 		resultsChan: make(chan *RecognitionResult, 10),
 		sendChan:    make(chan []byte, 100),
 		commitChan:  make(chan struct{}, 1),
+		ready:       make(chan struct{}),
+		prebuffer:   newAudioPrebuffer("QwenRealtime", audioConfig, config.PrebufferMs),
 	}
 
 	// Start connection
@@ -188,7 +199,11 @@ type qwenRealtimeStreamingRecognizer struct {
 	mu          sync.Mutex
 	closed      atomic.Bool
 	sessionReady atomic.Bool
+	ready       chan struct{} // closed once the session is ready
+	prebuffer   *audioPrebuffer
 	startTime   time.Time
+	health      *utils.WSHealth
+	connErr     atomic.Pointer[error] // why the connection ended, see ConnectionError
 }
 
 // Qwen Realtime ASR event types
@@ -212,7 +227,13 @@ type qwenSession struct {
 }
 
 type qwenAudioTranscription struct {
-	Language string `json:"language"`
+	Language string      `json:"language"`
+	Corpus   *qwenCorpus `json:"corpus,omitempty"`
+}
+
+// qwenCorpus is the context text used to bias recognition toward domain terms
+type qwenCorpus struct {
+	Text string `json:"text"`
 }
 
 type qwenTurnDetection struct {
@@ -315,6 +336,7 @@ func (r *qwenRealtimeStreamingRecognizer) doConnect() error {
 		"Authorization": {fmt.Sprintf("Bearer %s", r.provider.apiKey)},
 		"OpenAI-Beta":   {"realtime=v1"},
 	}
+	utils.MergeHeaders(headers, r.provider.headers)
 
 	conn, _, err := dialer.DialContext(r.ctx, url, headers)
 	if err != nil {
@@ -322,6 +344,7 @@ func (r *qwenRealtimeStreamingRecognizer) doConnect() error {
 	}
 
 	r.conn = conn
+	r.health = utils.NewWSHealth(conn, utils.DefaultWSPingInterval)
 	log.Printf("[QwenRealtime] WebSocket connected")
 
 	// Start message handlers
@@ -339,6 +362,11 @@ func (r *qwenRealtimeStreamingRecognizer) doConnect() error {
 func (r *qwenRealtimeStreamingRecognizer) sendSessionUpdate() {
 	language := r.normalizeLanguage(r.config.Language)
 
+	var corpus *qwenCorpus
+	if prompt := r.config.CurrentPrompt(); prompt != "" {
+		corpus = &qwenCorpus{Text: prompt}
+	}
+
 	sampleRate := r.audioConfig.SampleRate
 	if sampleRate == 0 {
 		sampleRate = 16000
@@ -353,6 +381,7 @@ func (r *qwenRealtimeStreamingRecognizer) sendSessionUpdate() {
 			SampleRate:       sampleRate,
 			InputAudioTranscription: qwenAudioTranscription{
 				Language: language,
+				Corpus:   corpus,
 			},
 			// Manual mode - VAD disabled for explicit control
 			TurnDetection: nil,
@@ -380,6 +409,7 @@ func (r *qwenRealtimeStreamingRecognizer) sendSessionUpdate() {
 func (r *qwenRealtimeStreamingRecognizer) readLoop() {
 	defer r.wg.Done()
 	defer r.Close()
+	defer r.health.Close()
 
 	for {
 		select {
@@ -392,80 +422,130 @@ func (r *qwenRealtimeStreamingRecognizer) readLoop() {
 		if err != nil {
 			if !r.closed.Load() && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 				log.Printf("[QwenRealtime] WebSocket read error: %v", err)
+				r.connErr.Store(&err)
 			}
 			return
 		}
 
+		r.health.Touch()
 		r.handleMessage(message)
 	}
 }
 
 // writeLoop handles outgoing messages.
+// Audio and commits that arrive before the session is ready are held in the
+// prebuffer and sent, in order, once it is.
 func (r *qwenRealtimeStreamingRecognizer) writeLoop() {
 	defer r.wg.Done()
 
+	ready := r.ready
+	flushIfReady := func() bool {
+		if ready == nil {
+			return true
+		}
+		select {
+		case <-ready:
+		default:
+			return false
+		}
+		ready = nil
+		chunks, commit := r.prebuffer.drain()
+		for _, chunk := range chunks {
+			r.sendAudio(chunk)
+		}
+		if commit {
+			r.sendCommit()
+		}
+		return true
+	}
+
 	for {
 		select {
 		case <-r.ctx.Done():
 			return
 
+		case <-ready:
+			flushIfReady()
+
 		case audioData, ok := <-r.sendChan:
 			if !ok {
 				return
 			}
 
-			if !r.sessionReady.Load() {
-				// Wait for session to be ready
+			if !flushIfReady() {
+				r.prebuffer.add(audioData)
 				continue
 			}
+			r.sendAudio(audioData)
 
-			event := qwenAudioAppendEvent{
-				EventID: fmt.Sprintf("audio_%d", time.Now().UnixNano()),
-				Type:    "input_audio_buffer.append",
-				Audio:   base64.StdEncoding.EncodeToString(audioData),
-			}
-
-			data, err := json.Marshal(event)
-			if err != nil {
-				log.Printf("[QwenRealtime] Failed to marshal audio append: %v", err)
+		case <-r.commitChan:
+			if !flushIfReady() {
+				r.prebuffer.commit = true
 				continue
 			}
+			r.sendCommit()
+		}
+	}
+}
 
-			r.mu.Lock()
-			if r.conn != nil {
-				if err := r.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-					log.Printf("[QwenRealtime] Failed to send audio: %v", err)
-				}
-			}
-			r.mu.Unlock()
+// sendAudio sends an input_audio_buffer.append event.
+func (r *qwenRealtimeStreamingRecognizer) sendAudio(audioData []byte) {
+	event := qwenAudioAppendEvent{
+		EventID: fmt.Sprintf("audio_%d", time.Now().UnixNano()),
+		Type:    "input_audio_buffer.append",
+		Audio:   base64.StdEncoding.EncodeToString(audioData),
+	}
 
-		case <-r.commitChan:
-			if !r.sessionReady.Load() {
-				continue
-			}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[QwenRealtime] Failed to marshal audio append: %v", err)
+		return
+	}
 
-			event := qwenAudioCommitEvent{
-				EventID: fmt.Sprintf("commit_%d", time.Now().UnixNano()),
-				Type:    "input_audio_buffer.commit",
-			}
+	r.mu.Lock()
+	if r.conn != nil {
+		if err := r.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			log.Printf("[QwenRealtime] Failed to send audio: %v", err)
+		}
+	}
+	r.mu.Unlock()
+}
 
-			data, err := json.Marshal(event)
-			if err != nil {
-				log.Printf("[QwenRealtime] Failed to marshal commit: %v", err)
-				continue
-			}
+// sendCommit sends an input_audio_buffer.commit event. With a PromptFunc the
+// session context is refreshed first, so each utterance is transcribed with
+// the latest prompt.
+func (r *qwenRealtimeStreamingRecognizer) sendCommit() {
+	if r.config.PromptFunc != nil {
+		r.sendSessionUpdate()
+	}
 
-			r.mu.Lock()
-			if r.conn != nil {
-				if err := r.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-					log.Printf("[QwenRealtime] Failed to send commit: %v", err)
-				} else {
-					log.Printf("[QwenRealtime] Audio buffer committed")
-				}
-			}
-			r.mu.Unlock()
+	event := qwenAudioCommitEvent{
+		EventID: fmt.Sprintf("commit_%d", time.Now().UnixNano()),
+		Type:    "input_audio_buffer.commit",
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[QwenRealtime] Failed to marshal commit: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	if r.conn != nil {
+		if err := r.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			log.Printf("[QwenRealtime] Failed to send commit: %v", err)
+		} else {
+			log.Printf("[QwenRealtime] Audio buffer committed")
 		}
 	}
+	r.mu.Unlock()
+}
+
+// markReady marks the session as ready and releases the prebuffered audio.
+func (r *qwenRealtimeStreamingRecognizer) markReady() {
+	if r.sessionReady.CompareAndSwap(false, true) {
+		close(r.ready)
+	}
 }
 
 // handleMessage processes incoming WebSocket messages.
@@ -514,7 +594,7 @@ func (r *qwenRealtimeStreamingRecognizer) handleSessionUpdated(data []byte) {
 	}
 
 	log.Printf("[QwenRealtime] Session configured successfully (ID: %s)", event.Session.ID)
-	r.sessionReady.Store(true)
+	r.markReady()
 	r.startTime = time.Now()
 }
 
@@ -643,6 +723,21 @@ func (r *qwenRealtimeStreamingRecognizer) Results() <-chan *RecognitionResult {
 	return r.resultsChan
 }
 
+// Healthy reports whether the WebSocket is open and the server has sent
+// a message or pong within the last two ping intervals.
+func (r *qwenRealtimeStreamingRecognizer) Healthy() bool {
+	return !r.closed.Load() && r.health.Healthy()
+}
+
+// ConnectionError returns the read error that dropped the WebSocket, if any.
+// It implements ConnectionErrorReporter.
+func (r *qwenRealtimeStreamingRecognizer) ConnectionError() error {
+	if err := r.connErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
 // Close stops recognition and releases resources.
 func (r *qwenRealtimeStreamingRecognizer) Close() error {
 	if r.closed.Swap(true) {
@@ -719,6 +814,7 @@ type QwenRealtimeStreamingRecognizer interface {
 
 // Ensure qwenRealtimeStreamingRecognizer implements QwenRealtimeStreamingRecognizer
 var _ QwenRealtimeStreamingRecognizer = (*qwenRealtimeStreamingRecognizer)(nil)
+var _ ConnectionErrorReporter = (*qwenRealtimeStreamingRecognizer)(nil)
 
 // IsQwenRealtimeRecognizer checks if a recognizer is a Qwen Realtime recognizer
 // and returns it casted to QwenRealtimeStreamingRecognizer if so.
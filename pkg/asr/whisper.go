@@ -6,17 +6,27 @@ import (
 	"encoding/binary"
 	"io"
 	"log"
+	"math"
+	"net/http"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 	"github.com/sashabaranov/go-openai"
 )
 
+// Whisper tasks for RecognitionConfig.Task
+const (
+	WhisperTaskTranscribe = "transcribe"
+	WhisperTaskTranslate  = "translate" // Translate speech to English via /audio/translations
+)
+
 // WhisperProvider implements the Provider interface using OpenAI's Whisper API.
 type WhisperProvider struct {
-	client *openai.Client
-	mu     sync.RWMutex
+	client       *openai.Client
+	clientConfig openai.ClientConfig
+	mu           sync.RWMutex
 }
 
 // NewWhisperProvider creates a new OpenAI Whisper ASR provider.
@@ -37,10 +47,22 @@ func NewWhisperProvider(apiKey string) (*WhisperProvider, error) {
 	client := openai.NewClientWithConfig(clientConfig)
 
 	return &WhisperProvider{
-		client: client,
+		client:       client,
+		clientConfig: clientConfig,
 	}, nil
 }
 
+// SetHeaders sets extra headers sent on every request (API gateway keys,
+// org IDs, tracing headers). They override the provider's own headers of the
+// same name.
+func (w *WhisperProvider) SetHeaders(headers map[string]string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.clientConfig.HTTPClient = &http.Client{Transport: &utils.HeaderTransport{Headers: headers}}
+	w.client = openai.NewClientWithConfig(w.clientConfig)
+}
+
 // Name returns the provider name.
 func (w *WhisperProvider) Name() string {
 	return "openai-whisper"
@@ -88,7 +110,7 @@ func (w *WhisperProvider) Recognize(ctx context.Context, audio io.Reader, audioC
 		Model:    config.Model,
 		FilePath: "audio.wav", // Filename hint for API
 		Reader:   bytes.NewReader(fileBytes),
-		Prompt:   config.Prompt,
+		Prompt:   config.CurrentPrompt(),
 		Language: config.Language,
 	}
 
@@ -101,9 +123,30 @@ func (w *WhisperProvider) Recognize(ctx context.Context, audio io.Reader, audioC
 		req.Temperature = config.Temperature
 	}
 
+	// Timings are only returned in the verbose_json format
+	if config.Timestamps != "" {
+		req.Format = openai.AudioResponseFormatVerboseJSON
+		req.TimestampGranularities = []openai.TranscriptionTimestampGranularity{
+			openai.TranscriptionTimestampGranularitySegment,
+		}
+		if config.Timestamps == TimestampsWord {
+			req.TimestampGranularities = append(req.TimestampGranularities,
+				openai.TranscriptionTimestampGranularityWord)
+		}
+	}
+
 	// Call Whisper API
 	startTime := time.Now()
-	resp, err := w.client.CreateTranscription(ctx, req)
+	var resp openai.AudioResponse
+	if config.Task == WhisperTaskTranslate {
+		// The translations endpoint always outputs English and takes no language;
+		// verbose_json still reports segment timings, but granularities are not accepted
+		req.Language = ""
+		req.TimestampGranularities = nil
+		resp, err = w.client.CreateTranslation(ctx, req)
+	} else {
+		resp, err = w.client.CreateTranscription(ctx, req)
+	}
 	if err != nil {
 		return nil, &Error{
 			Code:    ErrCodeProviderError,
@@ -126,6 +169,15 @@ func (w *WhisperProvider) Recognize(ctx context.Context, audio io.Reader, audioC
 		},
 	}
 
+	setWhisperTimings(result, resp)
+
+	if config.Task == WhisperTaskTranslate {
+		result.Language = "en"
+		result.Metadata["task"] = WhisperTaskTranslate
+		result.Metadata["source_language"] = config.Language
+		return result, nil
+	}
+
 	// If language was auto-detected, include it in metadata
 	if config.Language == "" || config.Language == "auto" {
 		// Whisper doesn't return detected language in basic transcription
@@ -136,6 +188,32 @@ func (w *WhisperProvider) Recognize(ctx context.Context, audio io.Reader, audioC
 	return result, nil
 }
 
+// setWhisperTimings copies the segment and word timings of a verbose_json
+// response into result. The result spans from the first segment to the last.
+func setWhisperTimings(result *RecognitionResult, resp openai.AudioResponse) {
+	if n := len(resp.Segments); n > 0 {
+		result.StartTime = secondsToDuration(resp.Segments[0].Start)
+		result.EndTime = secondsToDuration(resp.Segments[n-1].End)
+	}
+	for _, word := range resp.Words {
+		result.Words = append(result.Words, WordTiming{
+			Word:  word.Word,
+			Start: secondsToDuration(word.Start),
+			End:   secondsToDuration(word.End),
+		})
+	}
+	if len(resp.Segments) == 0 && len(result.Words) > 0 {
+		result.StartTime = result.Words[0].Start
+		result.EndTime = result.Words[len(result.Words)-1].End
+	}
+}
+
+// secondsToDuration converts a provider timestamp in seconds, rounded to the
+// microsecond so that e.g. 2.05 doesn't become 2.049999999s.
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Round(s*1e6)) * time.Microsecond
+}
+
 // StreamingRecognize creates a streaming recognizer for continuous audio input.
 func (w *WhisperProvider) StreamingRecognize(ctx context.Context, audioConfig AudioConfig, config RecognitionConfig) (StreamingRecognizer, error) {
 	w.mu.RLock()
@@ -188,6 +266,7 @@ type whisperStreamingRecognizer struct {
 	resultsChan chan *RecognitionResult
 	audioChan   chan []byte
 	audioBuffer []byte
+	offset      time.Duration // audio consumed by earlier recognitions, added to result timings
 	ctx         context.Context
 	cancel      context.CancelFunc
 	mu          sync.Mutex
@@ -310,6 +389,10 @@ func (r *whisperStreamingRecognizer) processBufferedAudio(ctx context.Context) {
 	audioData := make([]byte, len(r.audioBuffer))
 	copy(audioData, r.audioBuffer)
 	r.audioBuffer = r.audioBuffer[:0] // Clear buffer
+	offset := r.offset
+	if bytesPerSecond := r.audioConfig.SampleRate * r.audioConfig.Channels * (r.audioConfig.BitsPerSample / 8); bytesPerSecond > 0 {
+		r.offset += time.Duration(len(audioData)) * time.Second / time.Duration(bytesPerSecond)
+	}
 	r.mu.Unlock()
 
 	// Send partial result if enabled
@@ -340,6 +423,16 @@ func (r *whisperStreamingRecognizer) processBufferedAudio(ctx context.Context) {
 		return
 	}
 
+	// Timings are relative to this chunk; make them relative to the stream
+	if result.EndTime > 0 {
+		result.StartTime += offset
+		result.EndTime += offset
+		for i := range result.Words {
+			result.Words[i].Start += offset
+			result.Words[i].End += offset
+		}
+	}
+
 	// Send result
 	select {
 	case r.resultsChan <- result:
@@ -3,7 +3,10 @@ package asr
 import (
 	"bytes"
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -135,8 +138,143 @@ func TestWhisperProvider_Recognize_EmptyAudio(t *testing.T) {
 	}
 }
 
+func TestWhisperProvider_Recognize_PromptFunc(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		prompts = append(prompts, r.FormValue("prompt"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"hello"}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	provider, err := NewWhisperProvider("test-api-key")
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	// The context follows the conversation: each utterance sees the latest turns
+	history := []string{"Glossary: Kubernetes, etcd."}
+	config := RecognitionConfig{
+		Prompt: "static prompt",
+		PromptFunc: func() string {
+			return strings.Join(history, " ")
+		},
+	}
+	audioConfig := AudioConfig{SampleRate: 16000, Channels: 1, Encoding: "pcm", BitsPerSample: 16}
+	audio := make([]byte, 3200)
+
+	for _, transcript := range []string{"How do I restart etcd?", "And check its health?"} {
+		if _, err := provider.Recognize(context.Background(), bytes.NewReader(audio), audioConfig, config); err != nil {
+			t.Fatalf("Recognize failed: %v", err)
+		}
+		history = append(history, transcript)
+	}
+
+	expected := []string{"Glossary: Kubernetes, etcd.", "Glossary: Kubernetes, etcd. How do I restart etcd?"}
+	if len(prompts) != 2 || prompts[0] != expected[0] || prompts[1] != expected[1] {
+		t.Errorf("Expected prompts %q, got %q", expected, prompts)
+	}
+}
+
 // TestWhisperProvider_Recognize_Integration is an integration test that requires
 // a valid OpenAI API key. It is skipped by default.
+func TestWhisperProvider_Recognize_TranslateTask(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		if r.URL.Path == "/v1/audio/translations" && r.FormValue("language") != "" {
+			t.Errorf("Translations request should not send language, got %q", r.FormValue("language"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"hello"}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	provider, err := NewWhisperProvider("test-api-key")
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	audioConfig := AudioConfig{SampleRate: 16000, Channels: 1, Encoding: "pcm", BitsPerSample: 16}
+	audio := make([]byte, 3200)
+
+	result, err := provider.Recognize(context.Background(), bytes.NewReader(audio), audioConfig,
+		RecognitionConfig{Language: "zh", Task: WhisperTaskTranslate})
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if result.Text != "hello" || result.Language != "en" {
+		t.Errorf("Expected English text 'hello', got %q (%s)", result.Text, result.Language)
+	}
+
+	if _, err := provider.Recognize(context.Background(), bytes.NewReader(audio), audioConfig,
+		RecognitionConfig{Language: "zh"}); err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+
+	expected := []string{"/v1/audio/translations", "/v1/audio/transcriptions"}
+	if len(paths) != 2 || paths[0] != expected[0] || paths[1] != expected[1] {
+		t.Errorf("Expected requests to %v, got %v", expected, paths)
+	}
+}
+
+func TestWhisperProvider_Recognize_Timestamps(t *testing.T) {
+	var formats, granularities []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		formats = append(formats, r.FormValue("response_format"))
+		granularities = append(granularities, strings.Join(r.MultipartForm.Value["timestamp_granularities[]"], ","))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"Hello there. Bye.","duration":3.5,
+			"segments":[{"start":0.4,"end":1.6,"text":"Hello there."},{"start":2.2,"end":3.1,"text":"Bye."}],
+			"words":[{"word":"Hello","start":0.4,"end":0.8},{"word":"there","start":0.9,"end":1.6},{"word":"Bye","start":2.2,"end":3.1}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	provider, err := NewWhisperProvider("test-api-key")
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	audioConfig := AudioConfig{SampleRate: 16000, Channels: 1, Encoding: "pcm", BitsPerSample: 16}
+	audio := make([]byte, 3200)
+
+	result, err := provider.Recognize(context.Background(), bytes.NewReader(audio), audioConfig,
+		RecognitionConfig{Timestamps: TimestampsWord})
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if result.StartTime != 400*time.Millisecond || result.EndTime != 3100*time.Millisecond {
+		t.Errorf("Expected result to span 400ms-3.1s, got %v-%v", result.StartTime, result.EndTime)
+	}
+	if len(result.Words) != 3 || result.Words[1] != (WordTiming{Word: "there", Start: 900 * time.Millisecond, End: 1600 * time.Millisecond}) {
+		t.Errorf("Unexpected word timings: %+v", result.Words)
+	}
+
+	// Without Timestamps the default json format is used
+	if _, err := provider.Recognize(context.Background(), bytes.NewReader(audio), audioConfig, RecognitionConfig{}); err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+
+	if len(formats) != 2 || formats[0] != "verbose_json" || formats[1] != "" {
+		t.Errorf("Expected response formats [verbose_json \"\"], got %q", formats)
+	}
+	if granularities[0] != "segment,word" || granularities[1] != "" {
+		t.Errorf("Expected granularities segment,word then none, got %q", granularities)
+	}
+}
+
 func TestWhisperProvider_Recognize_Integration(t *testing.T) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
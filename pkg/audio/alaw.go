@@ -0,0 +1,131 @@
+// Package audio provides audio processing utilities.
+//
+// alaw.go implements A-law (G.711) audio codec conversions.
+// A-law is the standard audio encoding for telephone systems in Europe and
+// most international links.
+//
+// Features:
+//   - A-law to Linear PCM (16-bit signed) conversion
+//   - Linear PCM to A-law conversion
+//   - Lookup table for fast decoding
+//
+// Reference: ITU-T G.711 specification
+
+package audio
+
+// A-law codec constants
+const (
+	ALawSignBit   = 0x80 // Sign bit of an A-law byte
+	ALawSegShift  = 4
+	ALawSegMask   = 0x70
+	ALawQuantMask = 0x0f
+	ALawToggle    = 0x55 // Even bits are inverted on the wire
+)
+
+// aLawSegmentTable is the segment end lookup for A-law encoding (13-bit magnitude)
+var aLawSegmentTable = [8]int16{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+
+// aLawDecompressTable maps each A-law byte to a 16-bit signed PCM value.
+var aLawDecompressTable [256]int16
+
+func init() {
+	for i := range aLawDecompressTable {
+		aLawDecompressTable[i] = aLawDecodeSlow(byte(i))
+	}
+}
+
+// aLawDecodeSlow computes the linear value of an A-law byte.
+func aLawDecodeSlow(alaw byte) int16 {
+	alaw ^= ALawToggle
+
+	t := int16(alaw&ALawQuantMask) << 4
+	segment := (alaw & ALawSegMask) >> ALawSegShift
+	switch segment {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= segment - 1
+	}
+
+	if alaw&ALawSignBit != 0 {
+		return t
+	}
+	return -t
+}
+
+// ALawDecode converts a single A-law byte to a 16-bit signed PCM sample.
+func ALawDecode(alaw byte) int16 {
+	return aLawDecompressTable[alaw]
+}
+
+// ALawEncode converts a 16-bit signed PCM sample to A-law.
+func ALawEncode(pcm int16) byte {
+	// A-law works on 13-bit magnitudes
+	value := pcm >> 3
+
+	mask := byte(ALawToggle | ALawSignBit)
+	if value < 0 {
+		mask = ALawToggle
+		value = -value - 1
+	}
+
+	// Find segment
+	segment := 8
+	for i := 0; i < 8; i++ {
+		if value <= aLawSegmentTable[i] {
+			segment = i
+			break
+		}
+	}
+	if segment == 8 {
+		return 0x7F ^ mask
+	}
+
+	// Combine segment and quantization
+	aval := byte(segment) << ALawSegShift
+	if segment < 2 {
+		aval |= byte(value>>1) & ALawQuantMask
+	} else {
+		aval |= byte(value>>segment) & ALawQuantMask
+	}
+	return aval ^ mask
+}
+
+// ALawDecodeBuf converts A-law encoded bytes to 16-bit signed PCM.
+// Output buffer must be 2x the size of input (2 bytes per sample).
+func ALawDecodeBuf(alaw []byte, pcm []byte) {
+	for i, b := range alaw {
+		sample := aLawDecompressTable[b]
+		pcm[i*2] = byte(sample)
+		pcm[i*2+1] = byte(sample >> 8)
+	}
+}
+
+// ALawEncodeBuf converts 16-bit signed PCM to A-law encoded bytes.
+// Output buffer must be half the size of input.
+func ALawEncodeBuf(pcm []byte, alaw []byte) {
+	numSamples := len(pcm) / 2
+	for i := 0; i < numSamples; i++ {
+		sample := int16(pcm[i*2]) | (int16(pcm[i*2+1]) << 8)
+		alaw[i] = ALawEncode(sample)
+	}
+}
+
+// ALawToPCM converts A-law encoded audio to 16-bit signed PCM.
+// Returns a new slice containing the PCM data.
+func ALawToPCM(alaw []byte) []byte {
+	pcm := make([]byte, len(alaw)*2)
+	ALawDecodeBuf(alaw, pcm)
+	return pcm
+}
+
+// PCMToALaw converts 16-bit signed PCM audio to A-law.
+// Returns a new slice containing the A-law data.
+func PCMToALaw(pcm []byte) []byte {
+	alaw := make([]byte, len(pcm)/2)
+	ALawEncodeBuf(pcm, alaw)
+	return alaw
+}
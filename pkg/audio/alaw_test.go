@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"testing"
+)
+
+func TestALawKnownValues(t *testing.T) {
+	tests := []struct {
+		pcm  int16
+		alaw byte
+	}{
+		{0, 0xD5},
+		{-1, 0x55},
+		{32767, 0xAA},
+		{-32768, 0x2A},
+	}
+
+	for _, tt := range tests {
+		if got := ALawEncode(tt.pcm); got != tt.alaw {
+			t.Errorf("ALawEncode(%d) = %02x, want %02x", tt.pcm, got, tt.alaw)
+		}
+	}
+
+	if got := ALawDecode(0xD5); got != 8 {
+		t.Errorf("ALawDecode(0xD5) = %d, want 8", got)
+	}
+	if got := ALawDecode(0x55); got != -8 {
+		t.Errorf("ALawDecode(0x55) = %d, want -8", got)
+	}
+	if got := ALawDecode(0xAA); got != 32256 {
+		t.Errorf("ALawDecode(0xAA) = %d, want 32256", got)
+	}
+}
+
+func TestALawEncodeDecode(t *testing.T) {
+	// Every code decodes to a value that encodes back to the same code
+	for i := 0; i < 256; i++ {
+		code := byte(i)
+		if got := ALawEncode(ALawDecode(code)); got != code {
+			t.Errorf("A-law code %02x: decoded %d re-encodes to %02x", code, ALawDecode(code), got)
+		}
+	}
+
+	// Round-trip error stays within the segment's quantization step
+	for s := -32768; s <= 32767; s += 7 {
+		original := int16(s)
+		decoded := ALawDecode(ALawEncode(original))
+
+		diff := int(original) - int(decoded)
+		if diff < 0 {
+			diff = -diff
+		}
+		abs := int(original)
+		if abs < 0 {
+			abs = -abs
+		}
+		maxError := abs / 32
+		if maxError < 16 {
+			maxError = 16
+		}
+		if diff > maxError {
+			t.Fatalf("A-law round-trip for %d: decoded=%d, diff=%d (max allowed: %d)", original, decoded, diff, maxError)
+		}
+	}
+}
+
+func TestALawToPCM(t *testing.T) {
+	samples := []int16{0, 1000, -1000, 10000, -10000}
+	pcm := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		pcm[i*2] = byte(s)
+		pcm[i*2+1] = byte(s >> 8)
+	}
+
+	alaw := PCMToALaw(pcm)
+	if len(alaw) != len(samples) {
+		t.Fatalf("Expected A-law length %d, got %d", len(samples), len(alaw))
+	}
+
+	decoded := ALawToPCM(alaw)
+	for i, b := range alaw {
+		if b != ALawEncode(samples[i]) {
+			t.Errorf("Sample %d (%d): expected %02x, got %02x", i, samples[i], ALawEncode(samples[i]), b)
+		}
+		got := int16(decoded[i*2]) | (int16(decoded[i*2+1]) << 8)
+		if got != ALawDecode(b) {
+			t.Errorf("Sample %d: expected %d, got %d", i, ALawDecode(b), got)
+		}
+	}
+}
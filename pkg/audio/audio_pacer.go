@@ -3,6 +3,7 @@ package audio
 import (
 	"log"
 	"sync"
+	"time"
 )
 
 const (
@@ -14,12 +15,36 @@ const (
 	BytesPerSample = 2
 	// 帧时长 (毫秒)
 	FrameDurationMs = 20
+	// 清空后重新积累的默认帧数 (200ms)
+	defaultRefillFrames = 10
+	// 缓冲耗尽后在该帧数内又收到数据，视为一次欠载 (500ms)
+	underrunGapFrames = 25
+	// 抖动缓冲: 欠载时最多插入的舒适噪声帧数 (60ms)
+	concealFrames = 3
+	// 抖动缓冲: 舒适噪声幅度 (约 -60dBFS)
+	comfortNoiseLevel = 32
+	// 抖动缓冲: 到达延迟超过该时长视为新的一段语音，不计入抖动
+	talkSpurtGap = underrunGapFrames * FrameDurationMs * time.Millisecond
+	// 抖动缓冲: 抖动估计按写入的音频时长回落，约经过该时长的准时数据回落到 1/e
+	jitterDecay = 2 * time.Second
 )
 
 // AudioPacerConfig 配置
 type AudioPacerConfig struct {
 	SampleRate int // 采样率
 	Channels   int // 通道数
+
+	// TargetBufferMs 目标缓冲深度（毫秒）
+	// 开始播放前以及缓冲耗尽后，先积累到该深度再输出，用延迟换取抗抖动能力
+	// 0 表示不预缓冲（仅在 Clear 后积累 200ms）
+	TargetBufferMs int
+
+	// FadeInMs 每段音频开始播放时（静音、清空或欠载之后）的淡入时长（毫秒），0 表示不淡入
+	FadeInMs int
+
+	// EndFadeMs 缓冲读空时对最后一帧音频末尾的淡出时长（毫秒），0 表示不淡出
+	// 避免音频结束或欠载时从非零采样直接跳到静音产生爆音；打断时的淡出见 ClearWithFadeOut
+	EndFadeMs int
 }
 
 // DefaultAudioPacerConfig 返回默认配置
@@ -36,18 +61,48 @@ func DefaultAudioPacerConfig() AudioPacerConfig {
 // 主要功能:
 //   - 固定 20ms 帧输出
 //   - 缓冲积累控制 (避免初始抖动)
-//   - 打断时快速清空和淡出
+//   - 打断时快速清空和淡出，或在当前短语即将播完时先播完（MarkPhraseEnd、ClearAfterPhrase）
 //   - 暂停/恢复支持 (用于混合模式打断)
+//   - 输出增益 (用于双讲时压低音量)
+//   - 每段音频开始时淡入、缓冲读空时淡出
+//   - 目标缓冲深度和欠载统计
+//   - 自适应抖动缓冲（SetJitterBuffer）：按到达抖动调整播放延迟，欠载时插入舒适噪声
 type AudioPacer struct {
 	buffer       []byte
+	boundaries   []int // MarkPhraseEnd 记录的短语结束位置（buffer 中的偏移）
 	mu           sync.Mutex
 	accumulating bool // 是否正在积累数据
 	paused       bool // 是否暂停输出
 
+	// 输出增益，变化时在一帧内线性过渡，避免爆音
+	gain        float64 // 目标增益
+	appliedGain float64 // 上一帧结束时的增益
+
+	// 淡入淡出，单位为采样帧（每通道一个采样）
+	fadeInFrames  int
+	endFadeFrames int
+	fadeInPos     int // 当前淡入已进行的帧数，等于 fadeInFrames 时淡入结束
+
+	// 欠载统计
+	playing    bool  // 上一帧是否输出了音频数据
+	drained    bool  // 播放中缓冲区被读空
+	idleFrames int   // 读空后输出的静音帧数
+	underruns  int64 // 欠载次数
+
 	// 配置
 	sampleRate    int
 	channels      int
 	bytesPerFrame int
+	targetFrames  int // 目标缓冲帧数，0 表示不预缓冲
+
+	// 自适应抖动缓冲，jitterMaxFrames 为 0 表示未启用
+	jitterMinFrames int
+	jitterMaxFrames int
+	jitter          time.Duration // 到达延迟估计
+	nextDue         time.Time     // 按实时播放，已写入音频播完的时刻
+	waitedFrames    int           // 积累状态下缓冲非空后已等待的帧数
+	concealLeft     int           // 还可插入的舒适噪声帧数
+	noiseSeed       uint32
 }
 
 // NewAudioPacer 创建新的 AudioPacer (使用默认配置)
@@ -68,27 +123,69 @@ func NewAudioPacerWithConfig(cfg AudioPacerConfig) (*AudioPacer, error) {
 	samplesPerFrame := cfg.SampleRate * FrameDurationMs / 1000
 	bytesPerFrame := samplesPerFrame * BytesPerSample * cfg.Channels
 
+	targetFrames := 0
+	if cfg.TargetBufferMs > 0 {
+		targetFrames = (cfg.TargetBufferMs + FrameDurationMs - 1) / FrameDurationMs
+	}
+
 	return &AudioPacer{
 		buffer:        make([]byte, 0, bytesPerFrame*100), // 预分配2秒的容量
-		accumulating:  false,
+		accumulating:  targetFrames > 0,
+		gain:          1,
+		appliedGain:   1,
+		fadeInFrames:  cfg.SampleRate * cfg.FadeInMs / 1000,
+		endFadeFrames: cfg.SampleRate * cfg.EndFadeMs / 1000,
 		sampleRate:    cfg.SampleRate,
 		channels:      cfg.Channels,
 		bytesPerFrame: bytesPerFrame,
+		targetFrames:  targetFrames,
 	}, nil
 }
 
 // Write 写入 PCM 音频数据
 func (ap *AudioPacer) Write(data []byte) error {
+	return ap.WriteAt(data, time.Now())
+}
+
+// WriteAt 写入 PCM 音频数据，now 为数据到达时间，启用抖动缓冲时用于估计到达抖动
+func (ap *AudioPacer) WriteAt(data []byte, now time.Time) error {
 	if len(data) == 0 {
 		return nil
 	}
 
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
+
+	if ap.jitterMaxFrames > 0 {
+		ap.trackArrival(len(data), now)
+	}
+
+	// 播放中途读空后很快又来了数据，说明是输入跟不上播放，而不是一段音频正常结束
+	if ap.drained {
+		if ap.idleFrames <= underrunGapFrames {
+			ap.underruns++
+			log.Printf("audio pacer underrun after %d silent frames (total %d)", ap.idleFrames, ap.underruns)
+		}
+		ap.drained = false
+	}
+
 	ap.buffer = append(ap.buffer, data...)
 	return nil
 }
 
+// MarkPhraseEnd 把已写入的数据末尾记为短语边界，供 ClearAfterPhrase 使用
+// 一次 Write 不一定是完整的一句（流式 TTS、分块输出、重采样都会切分），需由调用方显式标记
+func (ap *AudioPacer) MarkPhraseEnd() {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	end := len(ap.buffer)
+	if end == 0 || (len(ap.boundaries) > 0 && ap.boundaries[len(ap.boundaries)-1] == end) {
+		return
+	}
+	ap.boundaries = append(ap.boundaries, end)
+}
+
 // ReadFrame 读取固定20ms的音频帧
 // 如果没有足够的数据，将返回静音数据
 func (ap *AudioPacer) ReadFrame() []byte {
@@ -103,41 +200,247 @@ func (ap *AudioPacer) ReadFrame() []byte {
 		return frame
 	}
 
-	// 如果正在积累数据且缓冲区小于200ms，返回静音
-	if ap.accumulating && len(ap.buffer) < ap.bytesPerFrame*10 { // 10帧 = 200ms
+	// 如果正在积累数据且缓冲区不足目标深度，返回静音（抖动缓冲欠载时为舒适噪声）
+	if ap.accumulating && !ap.readyToPlay() {
+		ap.idleFrames++
+		ap.conceal(frame)
 		return frame
 	}
 
 	// 如果有足够数据，关闭积累状态
-	if ap.accumulating && len(ap.buffer) >= ap.bytesPerFrame*10 {
+	if ap.accumulating {
 		ap.accumulating = false
 		log.Printf("accumulated enough data (%d bytes), starting playback", len(ap.buffer))
 	}
 
-	if len(ap.buffer) >= ap.bytesPerFrame {
-		// 有足够的数据，复制一帧
-		copy(frame, ap.buffer[:ap.bytesPerFrame])
+	if len(ap.buffer) > 0 {
+		// 复制一帧，数据不足一帧时其余填充静音
+		n := copy(frame, ap.buffer)
 		// 移除已读取的数据
-		ap.buffer = ap.buffer[ap.bytesPerFrame:]
-	} else if len(ap.buffer) > 0 {
-		// 有部分数据，复制可用部分，其余填充静音
-		copy(frame, ap.buffer)
-		// 清空缓冲区
-		ap.buffer = ap.buffer[:0]
+		ap.buffer = ap.buffer[n:]
+		ap.consumeBoundaries(n)
+
+		if !ap.playing {
+			ap.fadeInPos = 0
+		}
+		ap.applyFadeIn(frame[:n])
+		if len(ap.buffer) == 0 {
+			// 已淡出到静音，即使下一段数据紧接着到达也要重新淡入
+			ap.applyEndFade(frame[:n])
+			ap.fadeInPos = 0
+		}
+		ap.applyGain(frame)
+	} else {
+		// 如果没有数据，frame 保持为零值（静音）
+		if ap.playing {
+			ap.drained = true
+			ap.idleFrames = 0
+			// 设置了目标深度时重新积累，避免后续数据一到就播、播完又断
+			if ap.targetFrames > 0 {
+				ap.accumulating = true
+			}
+		}
+		ap.playing = false
+		ap.idleFrames++
+		ap.conceal(frame)
+		return frame
 	}
-	// 如果没有数据，frame 保持为零值（静音）
 
+	ap.playing = true
+	ap.concealLeft = 0
 	return frame
 }
 
+// readyToPlay 积累状态下判断是否开始播放
+// 未启用抖动缓冲时等缓冲达到目标深度；启用时从缓冲非空起延迟目标时长再播放，
+// 突发到达的音频也按固定延迟播出，后续到达的抖动由这段延迟吸收；缓冲达到上限时立即播放
+func (ap *AudioPacer) readyToPlay() bool {
+	if ap.jitterMaxFrames == 0 {
+		return len(ap.buffer) >= ap.bytesPerFrame*ap.refillFrames()
+	}
+	if len(ap.buffer) == 0 {
+		ap.waitedFrames = 0
+		return false
+	}
+	ap.waitedFrames++
+	if ap.waitedFrames < ap.targetFrames && len(ap.buffer) < ap.bytesPerFrame*ap.jitterMaxFrames {
+		return false
+	}
+	ap.waitedFrames = 0
+	return true
+}
+
+// conceal 抖动缓冲欠载后的前几帧用舒适噪声代替静音，避免播放中途出现完全无声的断口
+func (ap *AudioPacer) conceal(frame []byte) {
+	if ap.jitterMaxFrames == 0 {
+		return
+	}
+	if ap.drained && ap.idleFrames == 1 {
+		ap.concealLeft = concealFrames
+	}
+	if ap.concealLeft == 0 {
+		return
+	}
+	ap.concealLeft--
+
+	for i := 0; i+1 < len(frame); i += BytesPerSample {
+		// 线性同余伪随机数，均匀分布在 [-comfortNoiseLevel, comfortNoiseLevel]
+		ap.noiseSeed = ap.noiseSeed*1664525 + 1013904223
+		sample := int16(int32(ap.noiseSeed>>16)%(2*comfortNoiseLevel+1) - comfortNoiseLevel)
+		frame[i] = byte(sample)
+		frame[i+1] = byte(sample >> 8)
+	}
+}
+
+// trackArrival 估计到达抖动并调整目标延迟，需持有锁
+// 以实时播放为基准，数据晚于前面音频播完的时刻到达即为延迟；估计值遇到更大的延迟时立即升高，
+// 之后按写入的音频时长（而不是写入次数，突发到达的多帧不会让它骤降）缓慢回落；
+// 目标延迟为估计值加一帧，限制在 [min, max] 内
+func (ap *AudioPacer) trackArrival(size int, now time.Time) {
+	duration := time.Duration(size) * FrameDurationMs * time.Millisecond / time.Duration(ap.bytesPerFrame)
+
+	late := now.Sub(ap.nextDue)
+	if ap.nextDue.IsZero() || late > talkSpurtGap {
+		// 新的一段语音
+		ap.nextDue = now.Add(duration)
+	} else {
+		if late < 0 {
+			late = 0
+		}
+		if late > ap.jitter || duration >= jitterDecay {
+			ap.jitter = late
+		} else {
+			ap.jitter -= time.Duration(float64(ap.jitter-late) * float64(duration) / float64(jitterDecay))
+		}
+		if ap.nextDue.Before(now) {
+			ap.nextDue = now
+		}
+		ap.nextDue = ap.nextDue.Add(duration)
+	}
+
+	frameDuration := FrameDurationMs * time.Millisecond
+	target := int((ap.jitter + 2*frameDuration - 1) / frameDuration)
+	ap.targetFrames = min(max(target, ap.jitterMinFrames), ap.jitterMaxFrames)
+}
+
+// SetJitterBuffer 启用自适应抖动缓冲（毫秒），maxMs 为 0 时关闭
+//
+// 上游音频突发到达时（如实时模型一次推送多帧），按到达顺序直接播放会在两批之间断音。
+// 启用后每段语音开始（开始播放、欠载或打断清空之后）先延迟 targetMs 再播放，之后稳定按 20ms 输出；
+// 延迟按测得的到达抖动在 [minMs, maxMs] 内增减，缓冲达到 maxMs 时不再等待。
+// 播放中途缓冲耗尽时先输出几帧舒适噪声，而不是直接静音
+func (ap *AudioPacer) SetJitterBuffer(minMs, targetMs, maxMs int) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	if maxMs <= 0 {
+		ap.jitterMinFrames = 0
+		ap.jitterMaxFrames = 0
+		return
+	}
+
+	toFrames := func(ms int) int {
+		return max((ms+FrameDurationMs-1)/FrameDurationMs, 1)
+	}
+	ap.jitterMaxFrames = toFrames(maxMs)
+	ap.jitterMinFrames = min(toFrames(minMs), ap.jitterMaxFrames)
+	ap.targetFrames = min(max(toFrames(targetMs), ap.jitterMinFrames), ap.jitterMaxFrames)
+	ap.jitter = time.Duration(ap.targetFrames-1) * FrameDurationMs * time.Millisecond
+	ap.nextDue = time.Time{}
+	ap.accumulating = ap.accumulating || !ap.playing
+}
+
+// TargetBufferMs 返回当前的目标缓冲深度（毫秒），启用抖动缓冲时随到达抖动变化
+func (ap *AudioPacer) TargetBufferMs() int {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.targetFrames * FrameDurationMs
+}
+
+// applyGain 对输出帧应用增益，增益变化时从上一帧的增益线性过渡 (16-bit PCM)
+func (ap *AudioPacer) applyGain(frame []byte) {
+	if ap.gain == 1 && ap.appliedGain == 1 {
+		return
+	}
+
+	samples := len(frame) / BytesPerSample
+	for i := 0; i < samples; i++ {
+		factor := ap.gain
+		if ap.appliedGain != ap.gain {
+			factor = ap.appliedGain + (ap.gain-ap.appliedGain)*float64(i+1)/float64(samples)
+		}
+
+		idx := i * BytesPerSample
+		sample := int16(frame[idx]) | int16(frame[idx+1])<<8
+		sample = int16(float64(sample) * factor)
+		frame[idx] = byte(sample)
+		frame[idx+1] = byte(sample >> 8)
+	}
+	ap.appliedGain = ap.gain
+}
+
+// applyFadeIn 对一段音频开头的 fadeInFrames 帧从静音线性淡入，可跨越多次 ReadFrame (16-bit PCM)
+func (ap *AudioPacer) applyFadeIn(data []byte) {
+	frames := len(data) / (BytesPerSample * ap.channels)
+	for i := 0; i < frames && ap.fadeInPos < ap.fadeInFrames; i++ {
+		scaleFrame(data, i, ap.channels, float64(ap.fadeInPos)/float64(ap.fadeInFrames))
+		ap.fadeInPos++
+	}
+}
+
+// applyEndFade 对缓冲中最后一段音频的末尾 endFadeFrames 帧线性淡出到静音 (16-bit PCM)
+func (ap *AudioPacer) applyEndFade(data []byte) {
+	frames := len(data) / (BytesPerSample * ap.channels)
+	fade := ap.endFadeFrames
+	if fade > frames {
+		fade = frames
+	}
+	for i := 0; i < fade; i++ {
+		scaleFrame(data, frames-1-i, ap.channels, float64(i)/float64(fade))
+	}
+}
+
+// scaleFrame 把第 index 帧的所有通道乘以 factor（0-1，不会溢出）(16-bit PCM)
+func scaleFrame(data []byte, index, channels int, factor float64) {
+	for ch := 0; ch < channels; ch++ {
+		idx := (index*channels + ch) * BytesPerSample
+		sample := int16(data[idx]) | int16(data[idx+1])<<8
+		sample = int16(float64(sample) * factor)
+		data[idx] = byte(sample)
+		data[idx+1] = byte(sample >> 8)
+	}
+}
+
+// consumeBoundaries 读出 n 字节后平移短语边界，丢弃已播完的边界
+func (ap *AudioPacer) consumeBoundaries(n int) {
+	i := 0
+	for i < len(ap.boundaries) && ap.boundaries[i] <= n {
+		i++
+	}
+	ap.boundaries = ap.boundaries[i:]
+	for j := range ap.boundaries {
+		ap.boundaries[j] -= n
+	}
+}
+
+// refillFrames 返回积累状态下开始播放所需的帧数
+func (ap *AudioPacer) refillFrames() int {
+	if ap.targetFrames > 0 {
+		return ap.targetFrames
+	}
+	return defaultRefillFrames
+}
+
 // Clear 清空缓冲区并开始积累新数据
 func (ap *AudioPacer) Clear() {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
 	log.Printf("clear buffer: %d bytes, starting accumulation", len(ap.buffer))
 	ap.buffer = ap.buffer[:0]
+	ap.boundaries = nil
 	ap.accumulating = true
 	ap.paused = false
+	ap.resetPlayback()
 }
 
 // ClearWithFadeOut 清空缓冲区，对剩余音频应用淡出效果
@@ -145,7 +448,33 @@ func (ap *AudioPacer) Clear() {
 func (ap *AudioPacer) ClearWithFadeOut(fadeOutMs int) {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
+	ap.clearWithFadeOutLocked(fadeOutMs)
+}
 
+// ClearAfterPhrase 打断时如果当前短语（到下一个 MarkPhraseEnd 边界为止的音频）
+// 在 graceMs 内就能播完，保留它继续播放、丢弃之后的音频，并返回 true，
+// 在句子边界停下比在词中间截断更自然。
+// 否则（或暂停中）按 ClearWithFadeOut(fadeOutMs) 立即清空并返回 false
+func (ap *AudioPacer) ClearAfterPhrase(graceMs, fadeOutMs int) bool {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	graceBytes := ap.sampleRate * graceMs / 1000 * BytesPerSample * ap.channels
+	if !ap.paused && !ap.accumulating && len(ap.boundaries) > 0 && ap.boundaries[0] <= graceBytes {
+		end := ap.boundaries[0]
+		log.Printf("finishing current phrase (%d bytes), discarding %d bytes", end, len(ap.buffer)-end)
+		ap.buffer = ap.buffer[:end]
+		ap.boundaries = ap.boundaries[:1]
+		return true
+	}
+
+	ap.clearWithFadeOutLocked(fadeOutMs)
+	return false
+}
+
+// clearWithFadeOutLocked 见 ClearWithFadeOut，需持有锁
+func (ap *AudioPacer) clearWithFadeOutLocked(fadeOutMs int) {
+	ap.boundaries = nil
 	if fadeOutMs > 0 && len(ap.buffer) > 0 {
 		// 计算淡出需要的字节数
 		fadeOutBytes := ap.sampleRate * fadeOutMs / 1000 * BytesPerSample * ap.channels
@@ -181,6 +510,15 @@ func (ap *AudioPacer) ClearWithFadeOut(fadeOutMs int) {
 
 	ap.accumulating = true
 	ap.paused = false
+	ap.resetPlayback()
+}
+
+// resetPlayback 清空后重置欠载跟踪，打断导致的缓冲清空不算欠载
+func (ap *AudioPacer) resetPlayback() {
+	ap.playing = false
+	ap.drained = false
+	ap.concealLeft = 0
+	ap.waitedFrames = 0
 }
 
 // Pause 暂停音频输出，ReadFrame 将返回静音
@@ -203,6 +541,27 @@ func (ap *AudioPacer) Resume() {
 	}
 }
 
+// SetGain 设置输出增益（线性，0-1），用于双讲时压低 AI 音量，1 表示恢复原音量
+func (ap *AudioPacer) SetGain(gain float64) {
+	if gain < 0 {
+		gain = 0
+	}
+	if gain > 1 {
+		gain = 1
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.gain = gain
+}
+
+// Gain 返回当前输出增益
+func (ap *AudioPacer) Gain() float64 {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.gain
+}
+
 // IsPaused 返回当前是否暂停
 func (ap *AudioPacer) IsPaused() bool {
 	ap.mu.Lock()
@@ -217,6 +576,20 @@ func (ap *AudioPacer) Available() int {
 	return len(ap.buffer)
 }
 
+// BufferedMs 返回当前缓冲的音频时长（毫秒），即输出领先实际播放的深度
+func (ap *AudioPacer) BufferedMs() int {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return len(ap.buffer) * FrameDurationMs / ap.bytesPerFrame
+}
+
+// Underruns 返回播放中途缓冲耗尽的次数
+func (ap *AudioPacer) Underruns() int64 {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.underruns
+}
+
 // BytesPerFrame 返回每帧字节数
 func (ap *AudioPacer) BytesPerFrame() int {
 	return ap.bytesPerFrame
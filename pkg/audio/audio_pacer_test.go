@@ -1,7 +1,9 @@
 package audio
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -186,6 +188,108 @@ func TestAudioPacer_PauseResume(t *testing.T) {
 	})
 }
 
+func TestAudioPacer_Gain(t *testing.T) {
+	ap, err := NewAudioPacerWithConfig(AudioPacerConfig{SampleRate: 16000, Channels: 1})
+	require.NoError(t, err)
+	defer ap.Close()
+
+	sampleAt := func(frame []byte, i int) int16 {
+		return int16(frame[i*2]) | int16(frame[i*2+1])<<8
+	}
+
+	// 4 frames of constant 1000
+	const level = 1000
+	data := make([]byte, ap.BytesPerFrame()*4)
+	for i := 0; i < len(data); i += 2 {
+		data[i] = level & 0xff
+		data[i+1] = level >> 8
+	}
+	require.NoError(t, ap.Write(data))
+
+	frame := ap.ReadFrame()
+	assert.Equal(t, int16(1000), sampleAt(frame, 0))
+
+	// Ducking ramps down over one frame, then holds
+	ap.SetGain(0.25)
+	assert.Equal(t, 0.25, ap.Gain())
+	frame = ap.ReadFrame()
+	samples := len(frame) / 2
+	assert.Greater(t, sampleAt(frame, 0), int16(990), "Gain change should not jump")
+	assert.Equal(t, int16(250), sampleAt(frame, samples-1))
+
+	frame = ap.ReadFrame()
+	assert.Equal(t, int16(250), sampleAt(frame, 0))
+	assert.Equal(t, int16(250), sampleAt(frame, samples-1))
+
+	// Restoring ramps back up
+	ap.SetGain(1)
+	frame = ap.ReadFrame()
+	assert.Less(t, sampleAt(frame, 0), int16(260))
+	assert.Equal(t, int16(1000), sampleAt(frame, samples-1))
+}
+
+func TestAudioPacer_Fade(t *testing.T) {
+	const sampleRate = 16000
+	ap, err := NewAudioPacerWithConfig(AudioPacerConfig{
+		SampleRate: sampleRate,
+		Channels:   1,
+		FadeInMs:   10,
+		EndFadeMs:  10,
+	})
+	require.NoError(t, err)
+	defer ap.Close()
+
+	// Full-scale square wave, the worst case for clipping and clicks
+	frameSamples := ap.BytesPerFrame() / 2
+	in := make([]int16, frameSamples*3+frameSamples/2)
+	for i := range in {
+		in[i] = math.MaxInt16
+		if i%2 == 1 {
+			in[i] = math.MinInt16
+		}
+	}
+	data := make([]byte, len(in)*2)
+	for i, v := range in {
+		data[i*2] = byte(v)
+		data[i*2+1] = byte(v >> 8)
+	}
+	require.NoError(t, ap.Write(data))
+
+	var out []int16
+	for i := 0; i < 4; i++ {
+		frame := ap.ReadFrame()
+		for j := 0; j < len(frame); j += 2 {
+			out = append(out, int16(frame[j])|int16(frame[j+1])<<8)
+		}
+	}
+	out = out[:len(in)]
+
+	abs := func(v int16) float64 { return math.Abs(float64(v)) }
+	fade := sampleRate * 10 / 1000
+	for i, v := range out {
+		require.LessOrEqual(t, abs(v), abs(in[i]), "sample %d exceeds the input", i)
+		require.GreaterOrEqual(t, float64(in[i])*float64(v), 0.0, "sample %d changed sign", i)
+	}
+
+	// Fade-in ramps up from silence, then passes audio through unchanged
+	assert.Zero(t, out[0])
+	for i := 1; i < fade; i++ {
+		assert.GreaterOrEqual(t, abs(out[i])+1, abs(out[i-1]), "fade-in should not decrease at %d", i)
+	}
+	assert.Equal(t, in[fade:len(in)-fade], out[fade:len(in)-fade])
+
+	// Fade-out ramps the last samples before silence down to zero
+	assert.Zero(t, out[len(out)-1])
+	for i := len(out) - fade + 1; i < len(out); i++ {
+		assert.LessOrEqual(t, abs(out[i]), abs(out[i-1])+1, "fade-out should not increase at %d", i)
+	}
+
+	// The next response fades in again
+	require.NoError(t, ap.Write(data))
+	frame := ap.ReadFrame()
+	assert.Zero(t, int16(frame[0])|int16(frame[1])<<8)
+}
+
 func TestAudioPacer_ClearWithFadeOut(t *testing.T) {
 	ap, err := NewAudioPacerWithConfig(AudioPacerConfig{
 		SampleRate: 48000,
@@ -231,6 +335,100 @@ func TestAudioPacer_ClearWithFadeOut(t *testing.T) {
 	})
 }
 
+func TestAudioPacer_ClearAfterPhrase(t *testing.T) {
+	const sampleRate = 16000
+	newPacer := func() *AudioPacer {
+		ap, err := NewAudioPacerWithConfig(AudioPacerConfig{SampleRate: sampleRate, Channels: 1})
+		require.NoError(t, err)
+		ap.Clear() // Start playing once 200ms is buffered
+		return ap
+	}
+	// phrase returns ms of constant-level audio, so each phrase is recognizable
+	phrase := func(ms int, level int16) []byte {
+		data := make([]byte, sampleRate*ms/1000*2)
+		for i := 0; i < len(data); i += 2 {
+			data[i] = byte(level)
+			data[i+1] = byte(level >> 8)
+		}
+		return data
+	}
+	levelOf := func(frame []byte) int16 {
+		return int16(frame[0]) | int16(frame[1])<<8
+	}
+	writePhrase := func(t *testing.T, ap *AudioPacer, data []byte) {
+		require.NoError(t, ap.Write(data))
+		ap.MarkPhraseEnd()
+	}
+
+	t.Run("Near boundary finishes the phrase", func(t *testing.T) {
+		ap := newPacer()
+		defer ap.Close()
+		// The first phrase arrives in chunks, like streamed TTS audio
+		for i := 0; i < 3; i++ {
+			require.NoError(t, ap.Write(phrase(100, 1000)))
+		}
+		ap.MarkPhraseEnd()
+		writePhrase(t, ap, phrase(500, 2000))
+
+		// Play 240ms of the first phrase, leaving 60ms
+		for i := 0; i < 12; i++ {
+			require.Equal(t, int16(1000), levelOf(ap.ReadFrame()))
+		}
+
+		assert.True(t, ap.ClearAfterPhrase(100, 50))
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, int16(1000), levelOf(ap.ReadFrame()), "frame %d of the phrase should still play", i)
+		}
+		assert.Zero(t, ap.Available(), "the next phrase should be dropped")
+		assert.Zero(t, levelOf(ap.ReadFrame()))
+	})
+
+	t.Run("Far from boundary stops at once", func(t *testing.T) {
+		ap := newPacer()
+		defer ap.Close()
+		writePhrase(t, ap, phrase(300, 1000))
+		writePhrase(t, ap, phrase(500, 2000))
+
+		// 200ms into the second phrase, 300ms left
+		for i := 0; i < 25; i++ {
+			ap.ReadFrame()
+		}
+
+		assert.False(t, ap.ClearAfterPhrase(100, 50))
+		assert.LessOrEqual(t, ap.Available(), sampleRate*50/1000*2, "only the fade-out should remain")
+	})
+
+	t.Run("Paused output stops at once", func(t *testing.T) {
+		ap := newPacer()
+		defer ap.Close()
+		writePhrase(t, ap, phrase(220, 1000))
+		writePhrase(t, ap, phrase(500, 2000))
+		for i := 0; i < 10; i++ {
+			ap.ReadFrame()
+		}
+
+		ap.Pause()
+		assert.False(t, ap.ClearAfterPhrase(100, 0))
+		assert.Zero(t, ap.Available())
+	})
+
+	t.Run("Unmarked writes are not boundaries", func(t *testing.T) {
+		ap := newPacer()
+		defer ap.Close()
+		// Two chunks of the same sentence, the sentence ends after 800ms
+		require.NoError(t, ap.Write(phrase(300, 1000)))
+		writePhrase(t, ap, phrase(500, 1000))
+
+		// 60ms to the end of the first chunk, but 560ms to the end of the sentence
+		for i := 0; i < 12; i++ {
+			ap.ReadFrame()
+		}
+
+		assert.False(t, ap.ClearAfterPhrase(100, 50))
+		assert.LessOrEqual(t, ap.Available(), sampleRate*50/1000*2, "only the fade-out should remain")
+	})
+}
+
 func TestAudioPacerWithConfig(t *testing.T) {
 	t.Run("Custom sample rate 48kHz", func(t *testing.T) {
 		ap, err := NewAudioPacerWithConfig(AudioPacerConfig{
@@ -260,3 +458,154 @@ func TestAudioPacerWithConfig(t *testing.T) {
 		assert.Equal(t, 16000, ap.SampleRate())
 	})
 }
+
+func TestAudioPacer_TargetBuffer(t *testing.T) {
+	ap, err := NewAudioPacerWithConfig(AudioPacerConfig{
+		SampleRate:     16000,
+		Channels:       1,
+		TargetBufferMs: 100,
+	})
+	require.NoError(t, err)
+	defer ap.Close()
+
+	frameSize := ap.BytesPerFrame()
+	data := make([]byte, frameSize)
+	for i := range data {
+		data[i] = 0x11
+	}
+	isSilent := func(frame []byte) bool {
+		for _, b := range frame {
+			if b != 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	// 不足 100ms 时只输出静音
+	for i := 0; i < 4; i++ {
+		require.NoError(t, ap.Write(data))
+		assert.True(t, isSilent(ap.ReadFrame()), "frame %d should wait for target", i)
+	}
+	assert.Equal(t, 80, ap.BufferedMs())
+
+	// 达到 100ms 后开始播放
+	require.NoError(t, ap.Write(data))
+	assert.Equal(t, 100, ap.BufferedMs())
+	for i := 0; i < 5; i++ {
+		assert.False(t, isSilent(ap.ReadFrame()), "frame %d should play", i)
+	}
+	assert.Equal(t, 0, ap.BufferedMs())
+	assert.Equal(t, int64(0), ap.Underruns())
+
+	// 读空后数据很快又到达：计一次欠载，并重新积累到目标深度
+	assert.True(t, isSilent(ap.ReadFrame()))
+	require.NoError(t, ap.Write(data))
+	assert.Equal(t, int64(1), ap.Underruns())
+	assert.True(t, isSilent(ap.ReadFrame()), "should rebuffer after underrun")
+}
+
+func TestAudioPacer_Underruns(t *testing.T) {
+	ap, err := NewAudioPacerWithConfig(AudioPacerConfig{SampleRate: 16000, Channels: 1})
+	require.NoError(t, err)
+	defer ap.Close()
+
+	data := make([]byte, ap.BytesPerFrame())
+
+	t.Run("Gap in stream counts", func(t *testing.T) {
+		require.NoError(t, ap.Write(data))
+		ap.ReadFrame()
+		ap.ReadFrame() // 读空
+		ap.ReadFrame()
+		require.NoError(t, ap.Write(data))
+		assert.Equal(t, int64(1), ap.Underruns())
+		ap.ReadFrame()
+	})
+
+	t.Run("Long pause between responses does not count", func(t *testing.T) {
+		ap.ReadFrame() // 读空
+		for i := 0; i < underrunGapFrames+1; i++ {
+			ap.ReadFrame()
+		}
+		require.NoError(t, ap.Write(data))
+		assert.Equal(t, int64(1), ap.Underruns())
+		ap.ReadFrame()
+	})
+
+	t.Run("Clear does not count", func(t *testing.T) {
+		ap.Clear()
+		require.NoError(t, ap.Write(data))
+		assert.Equal(t, int64(1), ap.Underruns())
+	})
+}
+
+func TestAudioPacer_JitterBuffer(t *testing.T) {
+	ap, err := NewAudioPacerWithConfig(AudioPacerConfig{SampleRate: 16000, Channels: 1})
+	require.NoError(t, err)
+	defer ap.Close()
+
+	ap.SetJitterBuffer(40, 60, 200)
+	assert.Equal(t, 60, ap.TargetBufferMs())
+
+	data := make([]byte, ap.BytesPerFrame())
+	for i := range data {
+		data[i] = 0x11
+	}
+	start := time.Unix(0, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// 每批 5 帧 (100ms) 突发到达，第二批晚到 50ms
+	bursts := map[int]bool{0: true, 150: true, 200: true, 350: true, 400: true}
+	var played []int
+	for now := 0; now <= 600; now += 10 {
+		if bursts[now] {
+			for i := 0; i < 5; i++ {
+				require.NoError(t, ap.WriteAt(data, at(now)))
+			}
+		}
+		if now > 0 && now%20 == 0 {
+			if frame := ap.ReadFrame(); frame[0] == 0x11 {
+				played = append(played, now)
+			}
+		}
+		if now == 150 {
+			assert.Equal(t, 80, ap.TargetBufferMs(), "late burst should grow the target")
+		}
+	}
+
+	// 先延迟 60ms，之后所有帧连续按 20ms 输出，没有断口
+	require.Len(t, played, 25)
+	for i, ms := range played {
+		assert.Equal(t, 60+20*i, ms)
+	}
+	assert.Equal(t, int64(0), ap.Underruns())
+
+	// 到达稳定后目标延迟逐渐缩小到下限
+	now := 1200
+	for i := 0; i < 150; i++ {
+		require.NoError(t, ap.WriteAt(data, at(now)))
+		ap.ReadFrame()
+		now += 20
+	}
+	assert.Equal(t, 40, ap.TargetBufferMs())
+	for ap.Available() > 0 {
+		ap.ReadFrame()
+	}
+
+	// 欠载时先输出几帧舒适噪声，再恢复静音
+	for i := 0; i < concealFrames; i++ {
+		frame := ap.ReadFrame()
+		silent := true
+		for j := 0; j < len(frame); j += 2 {
+			sample := int16(frame[j]) | int16(frame[j+1])<<8
+			assert.LessOrEqual(t, math.Abs(float64(sample)), float64(comfortNoiseLevel))
+			if sample != 0 {
+				silent = false
+			}
+		}
+		assert.False(t, silent, "frame %d should be comfort noise", i)
+	}
+	for _, b := range ap.ReadFrame() {
+		require.Equal(t, byte(0), b)
+	}
+}
@@ -0,0 +1,229 @@
+// Package audio provides audio processing utilities.
+//
+// denoise.go implements single-channel noise suppression by spectral
+// subtraction. It is the pure-Go fallback for environments without RNNoise.
+//
+// Features:
+//   - Short-time Fourier analysis with sqrt-Hann windows and 50% overlap
+//   - Noise spectrum tracked continuously, so it adapts to changing noise
+//     without a separate noise-only calibration period
+//   - Decision-directed a priori SNR estimate with a Wiener gain, which keeps
+//     the "musical noise" of plain subtraction low
+//   - Strength in [0, 1] sets the maximum attenuation (0 leaves audio untouched)
+//
+// Reference: Y. Ephraim, D. Malah, "Speech enhancement using a minimum
+// mean-square error short-time spectral amplitude estimator", 1984.
+
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	denoiseFrameMs        = 20    // Upper bound of the analysis frame length
+	denoiseMaxAttenuation = 30.0  // dB, attenuation at strength 1
+	denoiseDDAlpha        = 0.98  // Decision-directed smoothing factor
+	denoiseNoiseAlpha     = 0.95  // Noise update rate while speech is absent
+	denoiseNoiseRise      = 1.002 // Per-frame noise growth while speech is present
+	denoiseSpeechRatio    = 4.0   // Power above noise*ratio counts as speech
+)
+
+// SpectralDenoiser suppresses stationary background noise in a mono stream.
+// Output is delayed by Latency samples. It is not safe for concurrent use.
+type SpectralDenoiser struct {
+	frameLen int
+	hop      int
+	window   []float64
+	floor    float64 // minimum gain, derived from strength
+
+	frame   []float64    // previous hop followed by the hop being filled
+	pending int          // input samples received since the last frame
+	overlap []float64    // overlap-add accumulator
+	out     []float32    // finished output not yet returned
+	spec    []complex128 // FFT scratch
+
+	power    []float64 // power spectrum of the current frame
+	noise    []float64 // estimated noise power per bin
+	prevGain []float64 // gain of the previous frame per bin
+	prevSNR  []float64 // posterior SNR of the previous frame per bin
+	primed   bool      // noise estimate initialized
+}
+
+// NewSpectralDenoiser creates a denoiser for mono audio at sampleRate.
+// strength is clamped to [0, 1].
+func NewSpectralDenoiser(sampleRate int, strength float64) *SpectralDenoiser {
+	// Largest power of two that fits in one frame, at least 64 samples
+	frameLen := 64
+	for frameLen*2 <= sampleRate*denoiseFrameMs/1000 {
+		frameLen *= 2
+	}
+	bins := frameLen/2 + 1
+
+	d := &SpectralDenoiser{
+		frameLen: frameLen,
+		hop:      frameLen / 2,
+		window:   make([]float64, frameLen),
+		frame:    make([]float64, frameLen),
+		overlap:  make([]float64, frameLen),
+		spec:     make([]complex128, frameLen),
+		power:    make([]float64, bins),
+		noise:    make([]float64, bins),
+		prevGain: make([]float64, bins),
+		prevSNR:  make([]float64, bins),
+	}
+	// Periodic sqrt-Hann: analysis*synthesis windows sum to 1 at 50% overlap
+	for i := range d.window {
+		d.window[i] = math.Sqrt(0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frameLen)))
+	}
+	d.SetStrength(strength)
+	d.Reset()
+	return d
+}
+
+// SetStrength changes the maximum attenuation; 0 disables suppression and 1
+// attenuates noise by up to 30dB
+func (d *SpectralDenoiser) SetStrength(strength float64) {
+	strength = math.Max(0, math.Min(1, strength))
+	d.floor = math.Pow(10, -strength*denoiseMaxAttenuation/20)
+}
+
+// Latency returns the delay of the output in samples
+func (d *SpectralDenoiser) Latency() int {
+	return d.frameLen
+}
+
+// Reset forgets the noise estimate and buffered audio
+func (d *SpectralDenoiser) Reset() {
+	clear(d.frame)
+	clear(d.overlap)
+	clear(d.noise)
+	clear(d.prevSNR)
+	for i := range d.prevGain {
+		d.prevGain[i] = 1
+	}
+	d.pending = 0
+	d.primed = false
+	// One hop of silence up front lets every call return as many samples as
+	// it was given, whatever the chunk size
+	d.out = append(d.out[:0], make([]float32, d.hop)...)
+}
+
+// Process denoises samples in [-1, 1] and returns the same number of samples,
+// delayed by Latency
+func (d *SpectralDenoiser) Process(samples []float32) []float32 {
+	for _, s := range samples {
+		d.frame[d.frameLen-d.hop+d.pending] = float64(s)
+		d.pending++
+		if d.pending == d.hop {
+			d.pending = 0
+			d.processFrame()
+			copy(d.frame, d.frame[d.hop:])
+		}
+	}
+
+	n := len(samples)
+	result := make([]float32, n)
+	copy(result, d.out[:n])
+	d.out = append(d.out[:0], d.out[n:]...)
+	return result
+}
+
+// processFrame filters the current frame and emits one hop of output
+func (d *SpectralDenoiser) processFrame() {
+	for i, s := range d.frame {
+		d.spec[i] = complex(s*d.window[i], 0)
+	}
+	fft(d.spec, false)
+
+	bins := len(d.noise)
+	power := d.power
+	for k := 0; k < bins; k++ {
+		re, im := real(d.spec[k]), imag(d.spec[k])
+		power[k] = re*re + im*im
+	}
+	d.updateNoise(power)
+
+	for k := 0; k < bins; k++ {
+		snr := power[k] / math.Max(d.noise[k], 1e-12)
+		// A priori SNR: mostly the previous frame's clean estimate, which
+		// smooths the gain over time
+		prior := denoiseDDAlpha*d.prevGain[k]*d.prevGain[k]*d.prevSNR[k] +
+			(1-denoiseDDAlpha)*math.Max(snr-1, 0)
+		gain := math.Max(prior/(1+prior), d.floor)
+		d.prevGain[k] = gain
+		d.prevSNR[k] = snr
+
+		d.spec[k] *= complex(gain, 0)
+		if k > 0 && k < d.frameLen-k {
+			d.spec[d.frameLen-k] = cmplx.Conj(d.spec[k])
+		}
+	}
+	fft(d.spec, true)
+
+	for i := range d.overlap {
+		d.overlap[i] += real(d.spec[i]) * d.window[i]
+	}
+	for _, v := range d.overlap[:d.hop] {
+		d.out = append(d.out, float32(v))
+	}
+	copy(d.overlap, d.overlap[d.hop:])
+	clear(d.overlap[d.frameLen-d.hop:])
+}
+
+// updateNoise tracks the noise power per bin: it follows the input while it
+// stays near the noise level and only creeps up while speech is present
+func (d *SpectralDenoiser) updateNoise(power []float64) {
+	if !d.primed {
+		copy(d.noise, power)
+		d.primed = true
+		return
+	}
+	for k, p := range power {
+		if p < d.noise[k]*denoiseSpeechRatio {
+			d.noise[k] = denoiseNoiseAlpha*d.noise[k] + (1-denoiseNoiseAlpha)*p
+		} else {
+			d.noise[k] *= denoiseNoiseRise
+		}
+	}
+}
+
+// fft computes an in-place radix-2 FFT; len(x) must be a power of two.
+// The inverse transform is scaled by 1/len(x).
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// noisySpeech returns 3s of white noise with a voiced-like tone burst from 1s
+// to 2s, at 16kHz
+func noisySpeech(seed int64) []float32 {
+	const sampleRate = 16000
+	rng := rand.New(rand.NewSource(seed))
+	samples := make([]float32, sampleRate*3)
+	for i := range samples {
+		samples[i] = float32(rng.NormFloat64() * 0.02)
+		if i >= sampleRate && i < 2*sampleRate {
+			t := float64(i) / sampleRate
+			samples[i] += float32(0.2*math.Sin(2*math.Pi*220*t) + 0.1*math.Sin(2*math.Pi*660*t))
+		}
+	}
+	return samples
+}
+
+func rmsF32(samples []float32) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// denoiseChunked runs samples through d in 20ms chunks and removes the latency
+func denoiseChunked(d *SpectralDenoiser, samples []float32) []float32 {
+	var out []float32
+	for off := 0; off < len(samples); off += 320 {
+		out = append(out, d.Process(samples[off:min(off+320, len(samples))])...)
+	}
+	if len(out) != len(samples) {
+		panic("denoiser changed the number of samples")
+	}
+	return append(out[d.Latency():], make([]float32, d.Latency())...)
+}
+
+func TestSpectralDenoiser_SuppressesNoise(t *testing.T) {
+	const sampleRate = 16000
+	in := noisySpeech(1)
+	out := denoiseChunked(NewSpectralDenoiser(sampleRate, 1), in)
+
+	// Noise-only tail after the estimate has settled
+	noiseIn := rmsF32(in[sampleRate*2+sampleRate/4:])
+	noiseOut := rmsF32(out[sampleRate*2+sampleRate/4 : len(out)-sampleRate/10])
+	if reduction := 20 * math.Log10(noiseIn/noiseOut); reduction < 15 {
+		t.Errorf("expected at least 15dB noise reduction, got %.1fdB", reduction)
+	}
+
+	// The tone burst keeps most of its energy
+	speechIn := rmsF32(in[sampleRate+sampleRate/4 : 2*sampleRate-sampleRate/4])
+	speechOut := rmsF32(out[sampleRate+sampleRate/4 : 2*sampleRate-sampleRate/4])
+	if loss := 20 * math.Log10(speechIn/speechOut); loss > 1.5 {
+		t.Errorf("expected speech loss under 1.5dB, got %.1fdB", loss)
+	}
+}
+
+func TestSpectralDenoiser_ZeroStrength(t *testing.T) {
+	in := noisySpeech(2)
+	d := NewSpectralDenoiser(16000, 0)
+	out := denoiseChunked(d, in)
+
+	// Without suppression the analysis/synthesis round trip is transparent
+	for i := 0; i < len(in)-d.Latency(); i++ {
+		if math.Abs(float64(out[i]-in[i])) > 1e-5 {
+			t.Fatalf("sample %d: expected %f, got %f", i, in[i], out[i])
+		}
+	}
+}
+
+func TestFFT_RoundTrip(t *testing.T) {
+	x := make([]complex128, 16)
+	x[1] = 1
+	fft(x, false)
+	for k, v := range x {
+		want := complexExp(-2 * math.Pi * float64(k) / 16)
+		if math.Abs(real(v)-real(want)) > 1e-9 || math.Abs(imag(v)-imag(want)) > 1e-9 {
+			t.Fatalf("bin %d: expected %v, got %v", k, want, v)
+		}
+	}
+	fft(x, true)
+	for i, v := range x {
+		want := 0.0
+		if i == 1 {
+			want = 1
+		}
+		if math.Abs(real(v)-want) > 1e-9 || math.Abs(imag(v)) > 1e-9 {
+			t.Fatalf("sample %d: expected %v, got %v", i, want, v)
+		}
+	}
+}
+
+func complexExp(phase float64) complex128 {
+	return complex(math.Cos(phase), math.Sin(phase))
+}
@@ -0,0 +1,289 @@
+// Package audio provides audio processing utilities.
+//
+// loudness.go measures loudness per ITU-R BS.1770 (K-weighted, in LUFS) and
+// normalizes a PCM stream toward a target loudness.
+//
+// Features:
+//   - K-weighting pre-filter computed for any sample rate
+//   - IntegratedLoudness: gated integrated loudness of a whole buffer
+//   - LoudnessNormalizer: streaming gain control toward a LUFS target, measured
+//     over a short-term window so silence between responses is ignored, with a
+//     smoothed gain and a peak ceiling to avoid clipping
+//
+// Reference: ITU-R BS.1770-4, "Algorithms to measure audio programme loudness
+// and true-peak audio level", 2015.
+
+package audio
+
+import "math"
+
+const (
+	loudnessAbsoluteGate = -70.0 // LUFS, BS.1770 absolute gate
+	loudnessRelativeGate = -10.0 // LU below the ungated mean, BS.1770 relative gate
+
+	loudnessBlockMs = 400 // Gating block length
+	loudnessHopMs   = 100 // Gating block hop (75% overlap)
+
+	normalizerWindowMs = 3000  // Short-term window the normalizer measures over
+	normalizerGate     = -50.0 // LUFS, quieter blocks are treated as silence
+	normalizerSmoothMs = 300   // Gain smoothing time constant
+	normalizerCeiling  = 0.99  // Output peak ceiling (about -0.1 dBFS)
+)
+
+// biquad is a direct form I second-order IIR filter
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting is the BS.1770 pre-filter: a high shelf modelling the head
+// followed by the RLB high-pass
+type kWeighting [2]biquad
+
+func newKWeighting(sampleRate int) kWeighting {
+	fs := float64(sampleRate)
+
+	// Stage 1: +4dB high shelf at 1500Hz
+	a := math.Pow(10, 4.0/40)
+	w0 := 2 * math.Pi * 1500 / fs
+	alpha := math.Sin(w0) / (2 / math.Sqrt2)
+	cos := math.Cos(w0)
+	sqrtA := math.Sqrt(a)
+	a0 := (a + 1) - (a-1)*cos + 2*sqrtA*alpha
+	shelf := biquad{
+		b0: a * ((a + 1) + (a-1)*cos + 2*sqrtA*alpha) / a0,
+		b1: -2 * a * ((a - 1) + (a+1)*cos) / a0,
+		b2: a * ((a + 1) + (a-1)*cos - 2*sqrtA*alpha) / a0,
+		a1: 2 * ((a - 1) - (a+1)*cos) / a0,
+		a2: ((a + 1) - (a-1)*cos - 2*sqrtA*alpha) / a0,
+	}
+
+	// Stage 2: high-pass at 38Hz
+	w0 = 2 * math.Pi * 38 / fs
+	alpha = math.Sin(w0) / (2 * 0.5)
+	cos = math.Cos(w0)
+	a0 = 1 + alpha
+	highPass := biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+
+	return kWeighting{shelf, highPass}
+}
+
+func (k *kWeighting) process(x float64) float64 {
+	return k[1].process(k[0].process(x))
+}
+
+// meanSquareToLUFS converts the channel-summed mean square of K-weighted
+// samples to LUFS
+func meanSquareToLUFS(ms float64) float64 {
+	if ms <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(ms)
+}
+
+// kWeightedPower returns the K-weighted power of each frame of interleaved
+// samples, summed over channels
+func kWeightedPower(samples []float32, channels, sampleRate int) []float64 {
+	filters := make([]kWeighting, channels)
+	for ch := range filters {
+		filters[ch] = newKWeighting(sampleRate)
+	}
+	frames := len(samples) / channels
+	power := make([]float64, frames)
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < channels; ch++ {
+			y := filters[ch].process(float64(samples[i*channels+ch]))
+			power[i] += y * y
+		}
+	}
+	return power
+}
+
+// IntegratedLoudness returns the gated integrated loudness (LUFS) of
+// interleaved samples in [-1, 1]. All channels are weighted equally, which
+// matches BS.1770 for mono and stereo. Audio shorter than one 400ms block or
+// entirely below the absolute gate returns -Inf.
+func IntegratedLoudness(samples []float32, channels, sampleRate int) float64 {
+	if channels <= 0 {
+		channels = 1
+	}
+	power := kWeightedPower(samples, channels, sampleRate)
+	blockLen := sampleRate * loudnessBlockMs / 1000
+	hop := sampleRate * loudnessHopMs / 1000
+	if blockLen == 0 || len(power) < blockLen {
+		return math.Inf(-1)
+	}
+
+	var blocks []float64
+	for start := 0; start+blockLen <= len(power); start += hop {
+		var sum float64
+		for _, p := range power[start : start+blockLen] {
+			sum += p
+		}
+		ms := sum / float64(blockLen)
+		if meanSquareToLUFS(ms) > loudnessAbsoluteGate {
+			blocks = append(blocks, ms)
+		}
+	}
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+
+	relativeGate := meanSquareToLUFS(mean(blocks)) + loudnessRelativeGate
+	var gated []float64
+	for _, ms := range blocks {
+		if meanSquareToLUFS(ms) > relativeGate {
+			gated = append(gated, ms)
+		}
+	}
+	return meanSquareToLUFS(mean(gated))
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// LoudnessNormalizer applies a slowly varying gain to a PCM stream so its
+// short-term loudness approaches a target. It is not safe for concurrent use.
+type LoudnessNormalizer struct {
+	channels   int
+	targetLUFS float64
+	maxGainDB  float64
+
+	filters  []kWeighting
+	hopLen   int       // frames per measurement block
+	blockSum float64   // K-weighted power accumulated in the current block
+	blockLen int       // frames accumulated in the current block
+	window   []float64 // mean square of recent non-silent blocks
+	maxBlock int       // blocks kept in window
+
+	desiredGain float64 // linear gain derived from the last measurement
+	gain        float64 // smoothed linear gain
+	smoothCoef  float64 // per-frame smoothing coefficient
+}
+
+// NewLoudnessNormalizer creates a normalizer for interleaved audio. The
+// applied gain is limited to ±maxGainDB so silence-like input is not boosted
+// into noise.
+func NewLoudnessNormalizer(sampleRate, channels int, targetLUFS, maxGainDB float64) *LoudnessNormalizer {
+	if channels <= 0 {
+		channels = 1
+	}
+	n := &LoudnessNormalizer{
+		channels:   channels,
+		targetLUFS: targetLUFS,
+		maxGainDB:  maxGainDB,
+		hopLen:     sampleRate * loudnessHopMs / 1000,
+		maxBlock:   normalizerWindowMs / loudnessHopMs,
+		smoothCoef: 1 - math.Exp(-1000/(normalizerSmoothMs*float64(sampleRate))),
+	}
+	n.filters = make([]kWeighting, channels)
+	for ch := range n.filters {
+		n.filters[ch] = newKWeighting(sampleRate)
+	}
+	n.Reset()
+	return n
+}
+
+// Reset forgets the measured loudness and returns to unity gain
+func (n *LoudnessNormalizer) Reset() {
+	n.blockSum = 0
+	n.blockLen = 0
+	n.window = n.window[:0]
+	n.desiredGain = 1
+	n.gain = 1
+}
+
+// Process normalizes interleaved samples in place
+func (n *LoudnessNormalizer) Process(samples []float32) {
+	frames := len(samples) / n.channels
+	if frames == 0 {
+		return
+	}
+
+	// Keep the output of this chunk under the ceiling
+	var peak float32
+	for _, s := range samples[:frames*n.channels] {
+		if s < 0 {
+			s = -s
+		}
+		if s > peak {
+			peak = s
+		}
+	}
+	limit := math.Inf(1)
+	if peak > 0 {
+		limit = normalizerCeiling / float64(peak)
+	}
+
+	for i := 0; i < frames; i++ {
+		frame := samples[i*n.channels : (i+1)*n.channels]
+		n.measure(frame)
+
+		n.gain += (n.desiredGain - n.gain) * n.smoothCoef
+		g := math.Min(n.gain, limit)
+		for ch := range frame {
+			frame[ch] = float32(float64(frame[ch]) * g)
+		}
+	}
+}
+
+// measure feeds one input frame to the loudness measurement
+func (n *LoudnessNormalizer) measure(frame []float32) {
+	for ch, s := range frame {
+		y := n.filters[ch].process(float64(s))
+		n.blockSum += y * y
+	}
+	n.blockLen++
+	if n.blockLen < n.hopLen {
+		return
+	}
+
+	ms := n.blockSum / float64(n.blockLen)
+	n.blockSum = 0
+	n.blockLen = 0
+	if meanSquareToLUFS(ms) <= normalizerGate {
+		// Silence keeps the current gain
+		return
+	}
+
+	n.window = append(n.window, ms)
+	if len(n.window) > n.maxBlock {
+		n.window = n.window[len(n.window)-n.maxBlock:]
+	}
+
+	gainDB := n.targetLUFS - meanSquareToLUFS(mean(n.window))
+	gainDB = math.Max(-n.maxGainDB, math.Min(n.maxGainDB, gainDB))
+	n.desiredGain = math.Pow(10, gainDB/20)
+}
+
+// Loudness returns the measured short-term loudness of the input (LUFS), or
+// -Inf before any non-silent audio has been seen
+func (n *LoudnessNormalizer) Loudness() float64 {
+	if len(n.window) == 0 {
+		return math.Inf(-1)
+	}
+	return meanSquareToLUFS(mean(n.window))
+}
+
+// GainDB returns the currently applied gain (dB), before the peak ceiling
+func (n *LoudnessNormalizer) GainDB() float64 {
+	return 20 * math.Log10(n.gain)
+}
@@ -0,0 +1,97 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// sineF32 generates a float32 sine wave with the given peak amplitude
+func sineF32(frames, channels, sampleRate int, freq, amplitude float64) []float32 {
+	samples := make([]float32, frames*channels)
+	for i := 0; i < frames; i++ {
+		v := float32(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+		for ch := 0; ch < channels; ch++ {
+			samples[i*channels+ch] = v
+		}
+	}
+	return samples
+}
+
+func TestIntegratedLoudness_Sine(t *testing.T) {
+	// BS.1770: a full-scale 997Hz sine in one channel reads -3.01 LUFS
+	for _, sampleRate := range []int{16000, 24000, 48000} {
+		samples := sineF32(sampleRate*2, 1, sampleRate, 997, 1)
+		got := IntegratedLoudness(samples, 1, sampleRate)
+		if math.Abs(got-(-3.01)) > 0.1 {
+			t.Errorf("%dHz: expected -3.01 LUFS, got %.2f", sampleRate, got)
+		}
+	}
+
+	// 20dB quieter
+	samples := sineF32(96000, 1, 48000, 997, 0.1)
+	if got := IntegratedLoudness(samples, 1, 48000); math.Abs(got-(-23.01)) > 0.1 {
+		t.Errorf("expected -23.01 LUFS, got %.2f", got)
+	}
+
+	if got := IntegratedLoudness(make([]float32, 96000), 1, 48000); !math.IsInf(got, -1) {
+		t.Errorf("expected -Inf for silence, got %.2f", got)
+	}
+}
+
+func TestLoudnessNormalizer_Converges(t *testing.T) {
+	const (
+		sampleRate = 24000
+		target     = -16.0
+	)
+
+	// Two "voices" 20dB apart both end up near the target
+	for _, amplitude := range []float64{0.05, 0.5} {
+		input := sineF32(sampleRate*6, 1, sampleRate, 440, amplitude)
+		before := IntegratedLoudness(input, 1, sampleRate)
+
+		n := NewLoudnessNormalizer(sampleRate, 1, target, 20)
+		// Feed 20ms chunks as a pipeline would
+		for off := 0; off < len(input); off += sampleRate / 50 {
+			n.Process(input[off : off+sampleRate/50])
+		}
+
+		// Measure the last 3 seconds, after the gain has settled
+		after := IntegratedLoudness(input[sampleRate*3:], 1, sampleRate)
+		if math.Abs(after-target) > 0.5 {
+			t.Errorf("amplitude %.2f: input %.1f LUFS, expected output near %.1f LUFS, got %.1f",
+				amplitude, before, target, after)
+		}
+		if math.Abs(n.Loudness()-before) > 0.5 {
+			t.Errorf("amplitude %.2f: expected measured input loudness %.1f, got %.1f", amplitude, before, n.Loudness())
+		}
+	}
+}
+
+func TestLoudnessNormalizer_SilenceAndLimits(t *testing.T) {
+	const sampleRate = 16000
+
+	// Silence is not boosted and leaves the gain at unity
+	n := NewLoudnessNormalizer(sampleRate, 1, -16, 20)
+	silence := make([]float32, sampleRate)
+	n.Process(silence)
+	if n.GainDB() != 0 || !math.IsInf(n.Loudness(), -1) {
+		t.Errorf("expected unity gain on silence, got %.1fdB", n.GainDB())
+	}
+
+	// Quiet input is boosted by at most maxGainDB
+	limited := NewLoudnessNormalizer(sampleRate, 1, -16, 6)
+	limited.Process(sineF32(sampleRate*3, 1, sampleRate, 440, 0.01))
+	if g := limited.GainDB(); g > 6.01 || g < 5.5 {
+		t.Errorf("expected gain limited to 6dB, got %.2fdB", g)
+	}
+
+	// Output never exceeds the peak ceiling
+	loud := sineF32(sampleRate*3, 2, sampleRate, 440, 0.5)
+	boost := NewLoudnessNormalizer(sampleRate, 2, 0, 20)
+	boost.Process(loud)
+	for _, s := range loud {
+		if s > normalizerCeiling+1e-6 || s < -normalizerCeiling-1e-6 {
+			t.Fatalf("sample %.3f exceeds the peak ceiling", s)
+		}
+	}
+}
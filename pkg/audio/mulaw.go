@@ -72,6 +72,10 @@ func MuLawEncode(pcm int16) byte {
 	// Determine sign and get magnitude
 	sign := (pcm >> 8) & 0x80
 	if sign != 0 {
+		// -32768 has no positive counterpart, clip before negating
+		if pcm < -MuLawClip {
+			pcm = -MuLawClip
+		}
 		pcm = -pcm
 	}
 	if pcm > MuLawClip {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/asticode/go-astiav"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 )
 
 type Resample struct {
@@ -14,10 +15,17 @@ type Resample struct {
 	outLayout astiav.ChannelLayout
 	inRate    int
 	outRate   int
+	format    pipeline.SampleFormat // 输入输出使用相同的采样格式
 }
 
-// NewResample 创建新的重采样器
+// NewResample 创建新的重采样器（16-bit PCM）
 func NewResample(inRate, outRate int, inLayout, outLayout astiav.ChannelLayout) (*Resample, error) {
+	return NewResampleWithFormat(inRate, outRate, inLayout, outLayout, pipeline.SampleFormatS16)
+}
+
+// NewResampleWithFormat 创建指定采样格式的重采样器，输出与输入格式相同
+// float32 输入直接在浮点域重采样，不经过 int16
+func NewResampleWithFormat(inRate, outRate int, inLayout, outLayout astiav.ChannelLayout, format pipeline.SampleFormat) (*Resample, error) {
 	// 验证参数
 	if inRate <= 0 {
 		return nil, fmt.Errorf("invalid input sample rate: %d", inRate)
@@ -25,12 +33,20 @@ func NewResample(inRate, outRate int, inLayout, outLayout astiav.ChannelLayout)
 	if outRate <= 0 {
 		return nil, fmt.Errorf("invalid output sample rate: %d", outRate)
 	}
+	if format == "" {
+		format = pipeline.SampleFormatS16
+	}
+	// FFmpeg 没有紧凑排列的 24-bit 格式
+	if format.BytesPerSample() == 0 || format == pipeline.SampleFormatS24 {
+		return nil, fmt.Errorf("unsupported sample format: %s", format)
+	}
 
 	r := &Resample{
 		inRate:    inRate,
 		outRate:   outRate,
 		inLayout:  inLayout,
 		outLayout: outLayout,
+		format:    format,
 	}
 
 	// 创建重采样上下文
@@ -82,7 +98,7 @@ func (r *Resample) Resample(inputData []byte) ([]byte, error) {
 	}
 
 	// 计算每个采样的字节数
-	bytesPerSample := 2 // S16 格式为 2 字节
+	bytesPerSample := r.format.BytesPerSample()
 	var inChannels int
 	if r.inLayout == astiav.ChannelLayoutMono {
 		inChannels = 1
@@ -105,13 +121,13 @@ func (r *Resample) Resample(inputData []byte) ([]byte, error) {
 
 	// 设置输入帧参数
 	r.inFrame.SetChannelLayout(r.inLayout)
-	r.inFrame.SetSampleFormat(astiav.SampleFormatS16)
+	r.inFrame.SetSampleFormat(r.avSampleFormat())
 	r.inFrame.SetSampleRate(r.inRate)
 	r.inFrame.SetNbSamples(numSamples)
 
 	// 设置输出帧参数
 	r.outFrame.SetChannelLayout(r.outLayout)
-	r.outFrame.SetSampleFormat(astiav.SampleFormatS16)
+	r.outFrame.SetSampleFormat(r.avSampleFormat())
 	r.outFrame.SetSampleRate(r.outRate)
 
 	// 计算输出采样点数，考虑采样率转换
@@ -144,10 +160,13 @@ func (r *Resample) Resample(inputData []byte) ([]byte, error) {
 
 	// 如果输入数据小于实际缓冲区大小，需要填充零
 	inputBuffer := inputData
-	if len(inputData) < actualBufferSize {
-		inputBuffer = make([]byte, actualBufferSize)
+	if len(inputData) < actualBufferSize || r.format == pipeline.SampleFormatS8 {
+		inputBuffer = make([]byte, max(actualBufferSize, len(inputData)))
 		copy(inputBuffer, inputData)
 	}
+	if r.format == pipeline.SampleFormatS8 {
+		flipSign(inputBuffer)
+	}
 
 	// 设置输入数据
 	if err := r.inFrame.Data().SetBytes(inputBuffer[:actualBufferSize], align); err != nil {
@@ -164,6 +183,35 @@ func (r *Resample) Resample(inputData []byte) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("getting output data failed: %w", err)
 	}
+	if r.format == pipeline.SampleFormatS8 {
+		flipSign(outputData)
+	}
 
 	return outputData, nil
 }
+
+// Format 返回重采样器的采样格式
+func (r *Resample) Format() pipeline.SampleFormat {
+	return r.format
+}
+
+// avSampleFormat 返回对应的 FFmpeg 采样格式
+// FFmpeg 没有有符号 8-bit 格式，s8 按 u8 处理，进出时翻转符号位
+func (r *Resample) avSampleFormat() astiav.SampleFormat {
+	switch r.format {
+	case pipeline.SampleFormatF32:
+		return astiav.SampleFormatFlt
+	case pipeline.SampleFormatS8:
+		return astiav.SampleFormatU8
+	case pipeline.SampleFormatS32:
+		return astiav.SampleFormatS32
+	}
+	return astiav.SampleFormatS16
+}
+
+// flipSign 在有符号和无符号 8-bit 样本之间转换
+func flipSign(data []byte) {
+	for i := range data {
+		data[i] ^= 0x80
+	}
+}
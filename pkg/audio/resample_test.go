@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/asticode/go-astiav"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -154,3 +155,33 @@ func TestResampleEmptyInput(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, output)
 }
+
+func TestResampleSampleFormats(t *testing.T) {
+	// 960 个 48kHz 样本（20ms）重采样到 16kHz 约 320 个样本，输出保持输入格式
+	samples := make([]float32, 960)
+	for i := range samples {
+		samples[i] = 0.5
+	}
+
+	for _, format := range []pipeline.SampleFormat{pipeline.SampleFormatS16, pipeline.SampleFormatF32, pipeline.SampleFormatS8} {
+		t.Run(string(format), func(t *testing.T) {
+			r, err := NewResampleWithFormat(48000, 16000, astiav.ChannelLayoutMono, astiav.ChannelLayoutMono, format)
+			assert.NoError(t, err)
+			defer r.Free()
+			assert.Equal(t, format, r.Format())
+
+			outputData, err := r.Resample(Float32ToBytes(samples, format))
+			assert.NoError(t, err)
+
+			expectedBytes := 320 * format.BytesPerSample()
+			assert.InDelta(t, expectedBytes, len(outputData), float64(expectedBytes/10))
+
+			// 直流信号重采样后中段仍接近原值（s8 需要正确处理符号位）
+			out := BytesToFloat32(outputData, format)
+			assert.InDelta(t, 0.5, out[len(out)/2], 0.05)
+		})
+	}
+
+	_, err := NewResampleWithFormat(48000, 16000, astiav.ChannelLayoutMono, astiav.ChannelLayoutMono, "u24")
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+// Package audio provides audio processing utilities.
+//
+// resize.go changes the length of a PCM chunk by a few frames with linear
+// interpolation. Spread over a whole chunk the change is a tiny resampling
+// ratio (well under 1% for drift correction), which shifts the pitch by an
+// inaudible amount and avoids the click of inserting or cutting samples.
+
+package audio
+
+// ResizeFrames linearly resamples interleaved samples to the given number of
+// frames, keeping the first and last frame in place. It returns samples
+// unchanged when the frame count already matches or either count is below 2.
+func ResizeFrames(samples []float32, channels, frames int) []float32 {
+	if channels <= 0 {
+		channels = 1
+	}
+	inFrames := len(samples) / channels
+	if frames == inFrames || frames < 2 || inFrames < 2 {
+		return samples
+	}
+
+	out := make([]float32, frames*channels)
+	step := float64(inFrames-1) / float64(frames-1)
+	for i := 0; i < frames; i++ {
+		pos := float64(i) * step
+		j := int(pos)
+		if j >= inFrames-1 {
+			j = inFrames - 2
+		}
+		frac := float32(pos - float64(j))
+		for ch := 0; ch < channels; ch++ {
+			a := samples[j*channels+ch]
+			b := samples[(j+1)*channels+ch]
+			out[i*channels+ch] = a + (b-a)*frac
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResizeFrames(t *testing.T) {
+	// Stereo ramp: left counts up, right counts down
+	in := make([]float32, 0, 200)
+	for i := 0; i < 100; i++ {
+		in = append(in, float32(i), float32(-i))
+	}
+
+	for _, frames := range []int{98, 101, 103} {
+		out := ResizeFrames(in, 2, frames)
+		if len(out) != frames*2 {
+			t.Fatalf("%d frames: got %d samples", frames, len(out))
+		}
+		// A linear ramp stays linear and keeps its end points
+		step := 99.0 / float64(frames-1)
+		for i := 0; i < frames; i++ {
+			want := float64(i) * step
+			if math.Abs(float64(out[2*i])-want) > 1e-3 || math.Abs(float64(out[2*i+1])+want) > 1e-3 {
+				t.Fatalf("%d frames: frame %d = (%v, %v), want ±%v", frames, i, out[2*i], out[2*i+1], want)
+			}
+		}
+	}
+
+	if out := ResizeFrames(in, 2, 100); &out[0] != &in[0] {
+		t.Error("Expected the input back when the length already matches")
+	}
+}
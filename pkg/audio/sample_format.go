@@ -0,0 +1,225 @@
+// Package audio provides audio processing utilities.
+//
+// sample_format.go converts PCM between the pipeline.SampleFormat sample
+// formats (s8, s16, s24, s32, f32). BytesToFloat32 and Float32ToBytes go
+// through normalized float32 samples, so f32 data is never truncated to
+// int16; ConvertSampleFormat converts directly between any two formats and
+// ConvertSampleFormatDither adds TPDF dither when reducing bit depth.
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// BytesToFloat32 将指定采样格式的 PCM 数据解码为 [-1, 1] 范围的 float32 样本
+// f32 数据直接解码，不经过 int16；未知格式返回 nil
+func BytesToFloat32(data []byte, format pipeline.SampleFormat) []float32 {
+	switch format {
+	case pipeline.SampleFormatS16, "":
+		samples := make([]float32, len(data)/2)
+		for i := range samples {
+			samples[i] = float32(int16(binary.LittleEndian.Uint16(data[i*2:]))) / 32768.0
+		}
+		return samples
+	case pipeline.SampleFormatF32:
+		samples := make([]float32, len(data)/4)
+		for i := range samples {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+		return samples
+	case pipeline.SampleFormatS8:
+		samples := make([]float32, len(data))
+		for i, b := range data {
+			samples[i] = float32(int8(b)) / 128.0
+		}
+		return samples
+	case pipeline.SampleFormatS24, pipeline.SampleFormatS32:
+		samples := make([]float32, len(data)/format.BytesPerSample())
+		for i := range samples {
+			samples[i] = float32(float64(alignedSample(data, format, i)) / 2147483648.0)
+		}
+		return samples
+	}
+	return nil
+}
+
+// Float32ToBytes 将 float32 样本编码为指定采样格式，整数格式超出范围时截断
+// 未知格式返回 nil
+func Float32ToBytes(samples []float32, format pipeline.SampleFormat) []byte {
+	switch format {
+	case pipeline.SampleFormatS16, "":
+		data := make([]byte, len(samples)*2)
+		for i, s := range samples {
+			binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(scaleSample(s, 32768))))
+		}
+		return data
+	case pipeline.SampleFormatF32:
+		data := make([]byte, len(samples)*4)
+		for i, s := range samples {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(s))
+		}
+		return data
+	case pipeline.SampleFormatS8:
+		data := make([]byte, len(samples))
+		for i, s := range samples {
+			data[i] = byte(int8(scaleSample(s, 128)))
+		}
+		return data
+	case pipeline.SampleFormatS24:
+		data := make([]byte, len(samples)*3)
+		for i, s := range samples {
+			putAlignedSample(data, format, i, int32(scaleSample(s, 8388608))<<8)
+		}
+		return data
+	case pipeline.SampleFormatS32:
+		// float32 无法精确表示 2^31-1，在 float64 中截断
+		data := make([]byte, len(samples)*4)
+		for i, s := range samples {
+			v := math.Max(math.Min(float64(s)*2147483648.0, math.MaxInt32), math.MinInt32)
+			putAlignedSample(data, format, i, int32(v))
+		}
+		return data
+	}
+	return nil
+}
+
+// ConvertSampleFormat 转换 PCM 数据的采样格式，格式相同时原样返回
+// 整数格式之间直接移位，不经过浮点：提高位深无损，降低位深截断低位
+func ConvertSampleFormat(data []byte, from, to pipeline.SampleFormat) ([]byte, error) {
+	from, to, err := normalizeFormats(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return data, nil
+	}
+
+	if IntSampleBits(from) > 0 && IntSampleBits(to) > 0 {
+		n := len(data) / from.BytesPerSample()
+		out := make([]byte, n*to.BytesPerSample())
+		for i := 0; i < n; i++ {
+			putAlignedSample(out, to, i, alignedSample(data, from, i))
+		}
+		return out, nil
+	}
+
+	return Float32ToBytes(BytesToFloat32(data, from), to), nil
+}
+
+// ConvertSampleFormatDither 与 ConvertSampleFormat 相同，但降低整数位深时先加入
+// ±1 LSB 的 TPDF 抖动再四舍五入，把截断产生的谐波失真变成低电平的白噪声；
+// 抖动后超出范围的样本截断到最大/最小值。提高位深或目标为 f32 时不加抖动
+func ConvertSampleFormatDither(data []byte, from, to pipeline.SampleFormat) ([]byte, error) {
+	from, to, err := normalizeFormats(from, to)
+	if err != nil {
+		return nil, err
+	}
+	toBits := IntSampleBits(to)
+	fromBits := IntSampleBits(from)
+	if from == pipeline.SampleFormatF32 {
+		fromBits = 32
+	}
+	if toBits == 0 || toBits >= fromBits {
+		return ConvertSampleFormat(data, from, to)
+	}
+
+	shift := 32 - toBits
+	step := int64(1) << shift
+	maxValue := int64(1)<<(toBits-1) - 1
+	minValue := -maxValue - 1
+
+	n := len(data) / from.BytesPerSample()
+	out := make([]byte, n*to.BytesPerSample())
+	for i := 0; i < n; i++ {
+		noise := rand.Int64N(step) + rand.Int64N(step) - step
+		q := (int64(alignedSample(data, from, i)) + noise + step/2) >> shift
+		q = min(max(q, minValue), maxValue)
+		putAlignedSample(out, to, i, int32(q<<shift))
+	}
+	return out, nil
+}
+
+// IntSampleBits 返回整数采样格式的位深，f32 和未知格式返回 0
+func IntSampleBits(format pipeline.SampleFormat) int {
+	switch format {
+	case pipeline.SampleFormatS8:
+		return 8
+	case pipeline.SampleFormatS16, "":
+		return 16
+	case pipeline.SampleFormatS24:
+		return 24
+	case pipeline.SampleFormatS32:
+		return 32
+	}
+	return 0
+}
+
+// normalizeFormats 校验转换的输入/输出格式，空格式按 s16 处理
+func normalizeFormats(from, to pipeline.SampleFormat) (pipeline.SampleFormat, pipeline.SampleFormat, error) {
+	if from.BytesPerSample() == 0 {
+		return "", "", fmt.Errorf("unsupported sample format: %s", from)
+	}
+	if to.BytesPerSample() == 0 {
+		return "", "", fmt.Errorf("unsupported sample format: %s", to)
+	}
+	if from == "" {
+		from = pipeline.SampleFormatS16
+	}
+	if to == "" {
+		to = pipeline.SampleFormatS16
+	}
+	return from, to, nil
+}
+
+// alignedSample 读取第 i 个样本，左对齐到 32 位有符号整数
+// f32 样本按 2^31 放大并截断
+func alignedSample(data []byte, format pipeline.SampleFormat, i int) int32 {
+	switch format {
+	case pipeline.SampleFormatS8:
+		return int32(int8(data[i])) << 24
+	case pipeline.SampleFormatS16, "":
+		return int32(int16(binary.LittleEndian.Uint16(data[i*2:]))) << 16
+	case pipeline.SampleFormatS24:
+		b := data[i*3:]
+		return int32(uint32(b[0])<<8 | uint32(b[1])<<16 | uint32(b[2])<<24)
+	case pipeline.SampleFormatS32:
+		return int32(binary.LittleEndian.Uint32(data[i*4:]))
+	case pipeline.SampleFormatF32:
+		v := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))) * 2147483648.0
+		return int32(math.Max(math.Min(v, math.MaxInt32), math.MinInt32))
+	}
+	return 0
+}
+
+// putAlignedSample 把左对齐到 32 位的样本写为整数格式的第 i 个样本，低位直接丢弃
+func putAlignedSample(data []byte, format pipeline.SampleFormat, i int, v int32) {
+	switch format {
+	case pipeline.SampleFormatS8:
+		data[i] = byte(v >> 24)
+	case pipeline.SampleFormatS16, "":
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(v>>16))
+	case pipeline.SampleFormatS24:
+		b := data[i*3:]
+		b[0], b[1], b[2] = byte(v>>8), byte(v>>16), byte(v>>24)
+	case pipeline.SampleFormatS32:
+		binary.LittleEndian.PutUint32(data[i*4:], uint32(v))
+	}
+}
+
+// scaleSample 按与解码相同的比例把浮点样本放大为整数，并截断到 [-scale, scale-1]
+// 保证整数格式经过 float32 往返后不变
+func scaleSample(s float32, scale float32) float32 {
+	v := s * scale
+	if v > scale-1 {
+		return scale - 1
+	}
+	if v < -scale {
+		return -scale
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleFormat_Float32RoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 0.123456, -1}
+
+	// f32 在往返过程中不损失精度
+	data := Float32ToBytes(samples, pipeline.SampleFormatF32)
+	assert.Len(t, data, len(samples)*4)
+	assert.Equal(t, samples, BytesToFloat32(data, pipeline.SampleFormatF32))
+
+	for _, format := range []pipeline.SampleFormat{pipeline.SampleFormatS16, pipeline.SampleFormatS8} {
+		data := Float32ToBytes(samples, format)
+		assert.Len(t, data, len(samples)*format.BytesPerSample())
+		got := BytesToFloat32(data, format)
+		for i := range samples {
+			assert.InDelta(t, samples[i], got[i], 0.01, "%s sample %d", format, i)
+		}
+	}
+}
+
+func TestSampleFormat_Clamp(t *testing.T) {
+	data := Float32ToBytes([]float32{1.5, -1.5}, pipeline.SampleFormatS16)
+	got := BytesToFloat32(data, pipeline.SampleFormatS16)
+	assert.InDelta(t, 1.0, got[0], 0.001)
+	assert.InDelta(t, -1.0, got[1], 0.001)
+}
+
+func TestConvertSampleFormat(t *testing.T) {
+	s16 := Float32ToBytes([]float32{0.25, -0.75}, pipeline.SampleFormatS16)
+
+	// 格式相同（包括未设置）时原样返回
+	out, err := ConvertSampleFormat(s16, "", pipeline.SampleFormatS16)
+	require.NoError(t, err)
+	assert.Equal(t, s16, out)
+
+	f32, err := ConvertSampleFormat(s16, pipeline.SampleFormatS16, pipeline.SampleFormatF32)
+	require.NoError(t, err)
+	assert.Len(t, f32, 8)
+	back, err := ConvertSampleFormat(f32, pipeline.SampleFormatF32, pipeline.SampleFormatS16)
+	require.NoError(t, err)
+	assert.Equal(t, s16, back)
+
+	// s8 -> s16 是无损的
+	s8 := []byte{0x40, 0xC0}
+	wide, err := ConvertSampleFormat(s8, pipeline.SampleFormatS8, pipeline.SampleFormatS16)
+	require.NoError(t, err)
+	narrow, err := ConvertSampleFormat(wide, pipeline.SampleFormatS16, pipeline.SampleFormatS8)
+	require.NoError(t, err)
+	assert.Equal(t, s8, narrow)
+
+	_, err = ConvertSampleFormat(s16, "u24", pipeline.SampleFormatS16)
+	assert.Error(t, err)
+}
+
+func TestConvertSampleFormat_BitDepths(t *testing.T) {
+	formats := []pipeline.SampleFormat{pipeline.SampleFormatS8, pipeline.SampleFormatS16, pipeline.SampleFormatS24, pipeline.SampleFormatS32}
+	s8 := []byte{0x00, 0x01, 0x7F, 0x80, 0xC0, 0xFF}
+
+	// 8-bit 样本在所有整数格式之间往返都是无损的
+	for _, from := range formats {
+		data, err := ConvertSampleFormat(s8, pipeline.SampleFormatS8, from)
+		require.NoError(t, err)
+		for _, to := range formats {
+			out, err := ConvertSampleFormat(data, from, to)
+			require.NoError(t, err)
+			assert.Len(t, out, len(s8)*to.BytesPerSample())
+			back, err := ConvertSampleFormat(out, to, pipeline.SampleFormatS8)
+			require.NoError(t, err)
+			assert.Equal(t, s8, back, "%s -> %s", from, to)
+		}
+	}
+
+	// 24-bit 紧凑排列，小端序
+	s24, err := ConvertSampleFormat([]byte{0x34, 0x12, 0xCC, 0xED}, pipeline.SampleFormatS16, pipeline.SampleFormatS24)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x34, 0x12, 0x00, 0xCC, 0xED}, s24)
+
+	// 与 float32 之间的转换保持刻度
+	samples := BytesToFloat32(s24, pipeline.SampleFormatS24)
+	assert.InDelta(t, float32(0x1234)/32768, samples[0], 1e-6)
+	s32 := Float32ToBytes([]float32{1.5, -1.5}, pipeline.SampleFormatS32)
+	got := BytesToFloat32(s32, pipeline.SampleFormatS32)
+	assert.InDelta(t, 1.0, got[0], 1e-6)
+	assert.Equal(t, float32(-1), got[1])
+}
+
+func TestConvertSampleFormatDither(t *testing.T) {
+	// 直流信号位于两个 8-bit 量化级之间：截断总是落到下方，抖动后的平均值保留原电平
+	const n = 20000
+	level := int16(0x1040) // 0x10 + 0.25 LSB
+	s16 := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		s16[i*2], s16[i*2+1] = byte(level), byte(level>>8)
+	}
+
+	truncated, err := ConvertSampleFormat(s16, pipeline.SampleFormatS16, pipeline.SampleFormatS8)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x10), truncated[0])
+
+	dithered, err := ConvertSampleFormatDither(s16, pipeline.SampleFormatS16, pipeline.SampleFormatS8)
+	require.NoError(t, err)
+	var sum float64
+	for _, b := range dithered {
+		v := int8(b)
+		assert.InDelta(t, 0x10, float64(v), 2, "dither stays within ±1 LSB of the rounded value")
+		sum += float64(v)
+	}
+	assert.InDelta(t, 16.25, sum/n, 0.05)
+
+	// 满幅样本抖动后截断，不会回绕
+	full := Float32ToBytes([]float32{1, -1, 1, -1}, pipeline.SampleFormatS24)
+	for i := 0; i < 100; i++ {
+		out, err := ConvertSampleFormatDither(full, pipeline.SampleFormatS24, pipeline.SampleFormatS16)
+		require.NoError(t, err)
+		samples := BytesToFloat32(out, pipeline.SampleFormatS16)
+		for j, s := range samples {
+			if j%2 == 0 {
+				assert.Greater(t, s, float32(0.99))
+			} else {
+				assert.Less(t, s, float32(-0.99))
+			}
+		}
+	}
+
+	// 提高位深时不加抖动
+	wide, err := ConvertSampleFormatDither(s16[:4], pipeline.SampleFormatS16, pipeline.SampleFormatS24)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x40, 0x10, 0x00, 0x40, 0x10}, wide)
+}
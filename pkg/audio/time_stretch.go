@@ -0,0 +1,139 @@
+// Package audio provides audio processing utilities.
+//
+// time_stretch.go implements WSOLA (Waveform Similarity Overlap-Add) time
+// stretching: it changes the duration of speech without changing its pitch.
+//
+// Features:
+//   - 16-bit interleaved PCM, any sample rate and channel count
+//   - Frames are aligned by waveform similarity, so voiced speech stays smooth
+//   - Best quality for modest rate changes (TimeStretchMinRate..TimeStretchMaxRate)
+//
+// Reference: W. Verhelst, M. Roelands, "An overlap-add technique based on
+// waveform similarity (WSOLA) for high quality time-scale modification of speech", 1993.
+
+package audio
+
+import "math"
+
+// Recommended rate range for speech. Larger changes are audible as artifacts.
+const (
+	TimeStretchMinRate = 0.8
+	TimeStretchMaxRate = 1.2
+)
+
+const (
+	wsolaFrameMs  = 20 // Analysis/synthesis frame length
+	wsolaSearchMs = 5  // Maximum frame offset searched for the best alignment
+)
+
+// TimeStretch plays samples at the given rate without changing the pitch.
+// rate > 1 makes the audio shorter (faster), rate < 1 longer (slower); the
+// output has about len(samples)/rate samples. Audio shorter than two frames
+// and rate 1 are returned unchanged.
+func TimeStretch(samples []int16, channels, sampleRate int, rate float64) []int16 {
+	if channels <= 0 {
+		channels = 1
+	}
+	frames := len(samples) / channels
+	frameLen := sampleRate * wsolaFrameMs / 1000
+	if rate <= 0 || rate == 1 || frameLen < 4 || frames < 2*frameLen {
+		return samples
+	}
+
+	hop := frameLen / 2
+	search := sampleRate * wsolaSearchMs / 1000
+	if search > hop {
+		search = hop
+	}
+	outFrames := int(float64(frames) / rate)
+
+	// Mono mix used for the similarity search
+	mono := make([]float64, frames)
+	for i := 0; i < frames; i++ {
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			sum += float64(samples[i*channels+ch])
+		}
+		mono[i] = sum / float64(channels)
+	}
+
+	// Periodic Hann window: overlapping windows at half a frame sum to 1
+	window := make([]float64, frameLen)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frameLen))
+	}
+
+	out := make([]float64, (outFrames+frameLen)*channels)
+	weight := make([]float64, outFrames+frameLen)
+
+	prev := 0
+	for synth := 0; synth < outFrames; synth += hop {
+		pos := 0
+		if synth > 0 {
+			nominal := int(math.Round(float64(synth) * rate))
+			pos = bestAlignment(mono, prev+hop, nominal, search, hop, frames-frameLen)
+		}
+
+		for i := 0; i < frameLen; i++ {
+			w := window[i]
+			weight[synth+i] += w
+			for ch := 0; ch < channels; ch++ {
+				out[(synth+i)*channels+ch] += w * float64(samples[(pos+i)*channels+ch])
+			}
+		}
+		prev = pos
+	}
+
+	result := make([]int16, outFrames*channels)
+	for i := 0; i < outFrames; i++ {
+		w := weight[i]
+		if w < 1e-6 {
+			continue
+		}
+		for ch := 0; ch < channels; ch++ {
+			v := out[i*channels+ch] / w
+			if v > math.MaxInt16 {
+				v = math.MaxInt16
+			} else if v < math.MinInt16 {
+				v = math.MinInt16
+			}
+			result[i*channels+ch] = int16(math.Round(v))
+		}
+	}
+	return result
+}
+
+// bestAlignment returns the frame position within search of nominal whose first
+// overlap samples best match the natural continuation of the previous frame.
+func bestAlignment(mono []float64, natural, nominal, search, overlap, maxPos int) int {
+	clamp := func(pos int) int {
+		if pos < 0 {
+			return 0
+		}
+		if pos > maxPos {
+			return maxPos
+		}
+		return pos
+	}
+
+	natural = clamp(natural)
+	best := clamp(nominal)
+	bestScore := math.Inf(-1)
+	for pos := clamp(nominal - search); pos <= clamp(nominal+search); pos++ {
+		var corr, energy float64
+		for i := 0; i < overlap; i++ {
+			v := mono[pos+i]
+			corr += v * mono[natural+i]
+			energy += v * v
+		}
+		score := corr
+		if energy > 0 {
+			score = corr / math.Sqrt(energy)
+		}
+		if score > bestScore {
+			bestScore = score
+			best = pos
+		}
+	}
+	return best
+}
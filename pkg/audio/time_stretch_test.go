@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// sine generates a 16-bit sine wave
+func sine(frames, channels, sampleRate int, freq float64) []int16 {
+	samples := make([]int16, frames*channels)
+	for i := 0; i < frames; i++ {
+		v := int16(8000 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+		for ch := 0; ch < channels; ch++ {
+			samples[i*channels+ch] = v
+		}
+	}
+	return samples
+}
+
+// dominantFrequency estimates the frequency of channel 0 from zero crossings
+func dominantFrequency(samples []int16, channels, sampleRate int) float64 {
+	frames := len(samples) / channels
+	crossings := 0
+	for i := 1; i < frames; i++ {
+		a, b := samples[(i-1)*channels], samples[i*channels]
+		if (a < 0) != (b < 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / 2 / (float64(frames) / float64(sampleRate))
+}
+
+func TestTimeStretch_DurationAndPitch(t *testing.T) {
+	const sampleRate = 24000
+	input := sine(sampleRate, 1, sampleRate, 220) // 1 second
+
+	for _, rate := range []float64{0.8, 0.9, 1.1, 1.2} {
+		out := TimeStretch(input, 1, sampleRate, rate)
+
+		wantLen := int(float64(len(input)) / rate)
+		if len(out) != wantLen {
+			t.Errorf("rate %.1f: expected %d samples, got %d", rate, wantLen, len(out))
+		}
+
+		// Skip the edges where the first/last frame is only half covered
+		freq := dominantFrequency(out[sampleRate/50:len(out)-sampleRate/50], 1, sampleRate)
+		if math.Abs(freq-220) > 220*0.03 {
+			t.Errorf("rate %.1f: expected pitch to stay at 220Hz, got %.1fHz", rate, freq)
+		}
+	}
+}
+
+func TestTimeStretch_Stereo(t *testing.T) {
+	const sampleRate = 16000
+	input := sine(sampleRate/2, 2, sampleRate, 300)
+
+	out := TimeStretch(input, 2, sampleRate, 1.2)
+	if len(out)%2 != 0 {
+		t.Fatalf("Expected interleaved stereo output, got %d samples", len(out))
+	}
+	for i := 0; i < len(out); i += 2 {
+		if out[i] != out[i+1] {
+			t.Fatalf("Expected identical channels at frame %d, got %d and %d", i/2, out[i], out[i+1])
+		}
+	}
+}
+
+func TestTimeStretch_Passthrough(t *testing.T) {
+	input := sine(24000, 1, 24000, 220)
+	if out := TimeStretch(input, 1, 24000, 1); len(out) != len(input) || &out[0] != &input[0] {
+		t.Error("Expected rate 1 to return the input unchanged")
+	}
+
+	short := sine(100, 1, 24000, 220)
+	if out := TimeStretch(short, 1, 24000, 1.2); len(out) != len(short) {
+		t.Error("Expected audio shorter than two frames to be returned unchanged")
+	}
+}
+
+func BenchmarkTimeStretch(b *testing.B) {
+	input := sine(24000, 1, 24000, 220)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TimeStretch(input, 1, 24000, 1.15)
+	}
+}
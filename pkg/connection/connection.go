@@ -21,6 +21,9 @@ const (
 	ConnectionStateFailed
 	// ConnectionStateClosed - Connection closed by user or server
 	ConnectionStateClosed
+	// ConnectionStateReconnecting - Connection lost, session and pipeline kept
+	// alive while waiting for the client to reconnect
+	ConnectionStateReconnecting
 )
 
 func (s ConnectionState) String() string {
@@ -37,6 +40,8 @@ func (s ConnectionState) String() string {
 		return "failed"
 	case ConnectionStateClosed:
 		return "closed"
+	case ConnectionStateReconnecting:
+		return "reconnecting"
 	default:
 		return "unknown"
 	}
@@ -75,4 +80,11 @@ type Connection interface {
 
 	// Close closes the connection and releases resources.
 	Close() error
+
+	// Hangup ends the session from the server side, e.g. after the assistant
+	// says goodbye. The peer is told that the server hung up (with reason),
+	// then the transport is torn down. Registered handlers receive
+	// ConnectionStateClosed, so the normal cleanup path (pipeline stop,
+	// session removal) runs exactly as for a client disconnect.
+	Hangup(reason string) error
 }
@@ -6,6 +6,8 @@
 // Features:
 //   - Twilio Media Streams WebSocket protocol handling
 //   - μ-law (8kHz) ↔ PCM (16kHz) audio conversion
+//   - Outgoing audio in other formats (rate, channels, sample format) is
+//     converted to μ-law 8kHz mono instead of being sent as-is
 //   - Bidirectional audio support
 //   - DTMF event handling
 //   - Mark events for audio synchronization
@@ -21,6 +23,7 @@ package connection
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 	"sync/atomic"
@@ -56,6 +59,11 @@ type TwilioConnection struct {
 	// Audio resampler
 	resampler8to16 *audio.Resample
 	resampler16to8 *audio.Resample
+	outputInRate   int // input rate of resampler16to8, follows the outgoing audio
+
+	// strictOutput drops outgoing audio that is not already μ-law 8kHz mono
+	// instead of converting it
+	strictOutput atomic.Bool
 
 	// I/O channels
 	inChan  chan *pipeline.PipelineMessage
@@ -155,6 +163,7 @@ func NewTwilioConnection(conn *websocket.Conn) (*TwilioConnection, error) {
 		outChan:        make(chan *pipeline.PipelineMessage, 100),
 		resampler8to16: resampler8to16,
 		resampler16to8: resampler16to8,
+		outputInRate:   PipelineSampleRate,
 		state:          ConnectionStateNew,
 		markChan:       make(chan string, 10),
 	}
@@ -183,12 +192,27 @@ func (tc *TwilioConnection) RegisterEventHandler(handler ConnectionEventHandler)
 	tc.handlers = append(tc.handlers, handler)
 }
 
+// SetStrictOutputFormat controls how outgoing audio that is not μ-law 8kHz
+// mono is handled. By default it is converted; in strict mode it is dropped
+// and logged, which helps catch a misconfigured TTS/resample chain.
+func (tc *TwilioConnection) SetStrictOutputFormat(strict bool) {
+	tc.strictOutput.Store(strict)
+}
+
 // SendMessage sends a pipeline message (audio) to Twilio.
+// Audio that cannot be converted to μ-law 8kHz mono is dropped.
 func (tc *TwilioConnection) SendMessage(msg *pipeline.PipelineMessage) {
 	if tc.closed.Load() {
 		return
 	}
 
+	if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil {
+		if err := tc.checkOutputAudio(msg.AudioData); err != nil {
+			log.Printf("[TwilioConn] Dropping outgoing audio: %v", err)
+			return
+		}
+	}
+
 	select {
 	case tc.outChan <- msg:
 	default:
@@ -218,13 +242,14 @@ func (tc *TwilioConnection) Close() error {
 		tc.conn.Close()
 	}
 
-	// Close channels
-	close(tc.inChan)
+	// Closing outChan stops the write pump
 	close(tc.outChan)
-	close(tc.markChan)
 
-	// Wait for goroutines to exit BEFORE freeing resamplers
+	// Wait for goroutines to exit BEFORE freeing resamplers, and before
+	// closing the channels the read pump sends on
 	tc.closeWg.Wait()
+	close(tc.inChan)
+	close(tc.markChan)
 
 	// Clean up resamplers (safe now that goroutines have exited)
 	if tc.resampler8to16 != nil {
@@ -257,7 +282,8 @@ func (tc *TwilioConnection) Start() {
 func (tc *TwilioConnection) readPump() {
 	defer tc.closeWg.Done()
 	defer func() {
-		tc.Close()
+		// Close waits for this goroutine to exit, so it cannot run here
+		go tc.Close()
 	}()
 
 	for {
@@ -457,21 +483,14 @@ func (tc *TwilioConnection) sendAudioToTwilio(audioData *pipeline.AudioData) {
 		return
 	}
 
-	// Get PCM data at pipeline sample rate
-	pcmData := audioData.Data
-
-	// Resample to 8kHz if needed
-	if audioData.SampleRate != TwilioOutputSampleRate {
-		var err error
-		pcmData, err = tc.resampler16to8.Resample(pcmData)
-		if err != nil {
-			log.Printf("[TwilioConn] Failed to resample output audio: %v", err)
-			return
-		}
+	mulawData, err := tc.encodeOutputAudio(audioData)
+	if err != nil {
+		log.Printf("[TwilioConn] Failed to convert output audio: %v", err)
+		return
+	}
+	if len(mulawData) == 0 {
+		return
 	}
-
-	// Convert PCM to μ-law
-	mulawData := audio.PCMToMuLaw(pcmData)
 
 	// Encode to base64
 	payload := base64.StdEncoding.EncodeToString(mulawData)
@@ -487,13 +506,121 @@ func (tc *TwilioConnection) sendAudioToTwilio(audioData *pipeline.AudioData) {
 
 	// Send to Twilio (synchronized write)
 	tc.writeMu.Lock()
-	err := tc.conn.WriteJSON(msg)
+	err = tc.conn.WriteJSON(msg)
 	tc.writeMu.Unlock()
 	if err != nil {
 		log.Printf("[TwilioConn] Failed to send audio: %v", err)
 	}
 }
 
+// checkOutputAudio asserts that outgoing audio is μ-law 8kHz mono, or that it
+// can be converted to it when strict mode is off.
+func (tc *TwilioConnection) checkOutputAudio(data *pipeline.AudioData) error {
+	if isTwilioNativeAudio(data) {
+		return nil
+	}
+	if tc.strictOutput.Load() {
+		return fmt.Errorf("audio is %s %dHz %d channel(s), Twilio expects %s %dHz mono",
+			data.MediaType, data.SampleRate, data.Channels, pipeline.AudioMediaTypeMuLaw, TwilioOutputSampleRate)
+	}
+
+	switch data.MediaType {
+	case pipeline.AudioMediaTypeMuLaw:
+	case pipeline.AudioMediaTypeRaw, pipeline.AudioMediaTypePCM, "":
+		if data.Format().BytesPerSample() == 0 {
+			return fmt.Errorf("unsupported sample format %s", data.Format())
+		}
+	default:
+		return fmt.Errorf("unsupported media type %s", data.MediaType)
+	}
+	if data.SampleRate < 0 || data.Channels < 0 {
+		return fmt.Errorf("invalid audio: %dHz %d channel(s)", data.SampleRate, data.Channels)
+	}
+	return nil
+}
+
+// encodeOutputAudio converts outgoing audio to μ-law 8kHz mono.
+// It must only be called from the write pump, which owns resampler16to8.
+func (tc *TwilioConnection) encodeOutputAudio(data *pipeline.AudioData) ([]byte, error) {
+	if isTwilioNativeAudio(data) {
+		return data.Data, nil
+	}
+
+	pcmData, err := twilioMonoPCM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resample to 8kHz if needed (e.g. 16kHz TTS or 24kHz OpenAI Realtime audio)
+	sampleRate := data.SampleRate
+	if sampleRate == 0 {
+		sampleRate = PipelineSampleRate
+		if data.MediaType == pipeline.AudioMediaTypeMuLaw {
+			sampleRate = TwilioOutputSampleRate
+		}
+	}
+	if sampleRate != TwilioOutputSampleRate {
+		if sampleRate != tc.outputInRate {
+			resampler, err := audio.NewResample(sampleRate, TwilioOutputSampleRate,
+				astiav.ChannelLayoutMono, astiav.ChannelLayoutMono)
+			if err != nil {
+				return nil, fmt.Errorf("create output resampler for %dHz: %w", sampleRate, err)
+			}
+			tc.resampler16to8.Free()
+			tc.resampler16to8 = resampler
+			tc.outputInRate = sampleRate
+		}
+
+		pcmData, err = tc.resampler16to8.Resample(pcmData)
+		if err != nil {
+			return nil, fmt.Errorf("resample output audio: %w", err)
+		}
+	}
+
+	// Convert PCM to μ-law
+	return audio.PCMToMuLaw(pcmData), nil
+}
+
+// isTwilioNativeAudio reports whether audio can be sent to Twilio unchanged.
+func isTwilioNativeAudio(data *pipeline.AudioData) bool {
+	return data.MediaType == pipeline.AudioMediaTypeMuLaw &&
+		(data.SampleRate == 0 || data.SampleRate == TwilioOutputSampleRate) &&
+		data.Channels <= 1
+}
+
+// twilioMonoPCM decodes outgoing audio to 16-bit mono PCM at its original rate.
+func twilioMonoPCM(data *pipeline.AudioData) ([]byte, error) {
+	var pcm []byte
+	if data.MediaType == pipeline.AudioMediaTypeMuLaw {
+		pcm = audio.MuLawToPCM(data.Data)
+	} else {
+		var err error
+		pcm, err = audio.ConvertSampleFormat(data.Data, data.Format(), pipeline.SampleFormatS16)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if data.Channels <= 1 {
+		return pcm, nil
+	}
+
+	// Downmix interleaved channels by averaging
+	frames := len(pcm) / 2 / data.Channels
+	mono := make([]byte, frames*2)
+	for i := 0; i < frames; i++ {
+		sum := 0
+		for c := 0; c < data.Channels; c++ {
+			off := (i*data.Channels + c) * 2
+			sum += int(int16(uint16(pcm[off]) | uint16(pcm[off+1])<<8))
+		}
+		v := uint16(int16(sum / data.Channels))
+		mono[i*2] = byte(v)
+		mono[i*2+1] = byte(v >> 8)
+	}
+	return mono, nil
+}
+
 // SendMark sends a mark message to Twilio for audio synchronization.
 func (tc *TwilioConnection) SendMark(name string) error {
 	if tc.streamSid == "" || tc.closed.Load() {
@@ -530,6 +657,41 @@ func (tc *TwilioConnection) ClearAudio() error {
 	return tc.conn.WriteJSON(msg)
 }
 
+// Hangup ends the call from the server side. Audio already queued (e.g. the
+// assistant's goodbye) is flushed and played out before the stream is closed,
+// waiting at most 5 seconds. Closing the media stream ends the
+// <Connect><Stream> verb, so Twilio hangs up the call.
+func (tc *TwilioConnection) Hangup(reason string) error {
+	return tc.HangupWithTimeout(reason, 5*time.Second)
+}
+
+// HangupWithTimeout is like Hangup with a custom limit on how long queued
+// audio may keep playing.
+func (tc *TwilioConnection) HangupWithTimeout(reason string, drainTimeout time.Duration) error {
+	if tc.closed.Load() {
+		return nil
+	}
+	log.Printf("[TwilioConn] Hanging up stream %s: %s", tc.streamSid, reason)
+
+	deadline := time.Now().Add(drainTimeout)
+
+	// Wait for the write loop to send everything that is queued
+	for len(tc.outChan) > 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Wait for Twilio to finish playing it
+	if tc.streamSid != "" {
+		if err := tc.SendMark("hangup"); err == nil {
+			if !tc.WaitForMark("hangup", time.Until(deadline)) {
+				log.Printf("[TwilioConn] Playback not finished before hangup")
+			}
+		}
+	}
+
+	return tc.Close()
+}
+
 // WaitForMark waits for a specific mark to be returned.
 func (tc *TwilioConnection) WaitForMark(name string, timeout time.Duration) bool {
 	timer := time.NewTimer(timeout)
@@ -537,7 +699,10 @@ func (tc *TwilioConnection) WaitForMark(name string, timeout time.Duration) bool
 
 	for {
 		select {
-		case mark := <-tc.markChan:
+		case mark, ok := <-tc.markChan:
+			if !ok {
+				return false
+			}
 			if mark == name {
 				return true
 			}
@@ -0,0 +1,221 @@
+package connection
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sinePCM generates a 16-bit mono sine wave.
+func sinePCM(freq float64, sampleRate, samples int, amplitude float64) []byte {
+	data := make([]byte, samples*2)
+	for i := 0; i < samples; i++ {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(v*32767)))
+	}
+	return data
+}
+
+// rms returns the normalized RMS level of 16-bit PCM.
+func rms(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		v := float64(int16(binary.LittleEndian.Uint16(pcm[i*2:]))) / 32768
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+func TestTwilioOutputAudio_NativeMuLawPassesThrough(t *testing.T) {
+	tc := &TwilioConnection{}
+	mulaw := audio.PCMToMuLaw(sinePCM(440, 8000, 160, 0.5))
+
+	out, err := tc.encodeOutputAudio(&pipeline.AudioData{
+		Data:       mulaw,
+		SampleRate: 8000,
+		Channels:   1,
+		MediaType:  pipeline.AudioMediaTypeMuLaw,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, mulaw, out)
+}
+
+func TestTwilioOutputAudio_PCM8kEncodedAsMuLaw(t *testing.T) {
+	tc := &TwilioConnection{}
+	pcm := sinePCM(440, 8000, 160, 0.5)
+
+	out, err := tc.encodeOutputAudio(&pipeline.AudioData{
+		Data:       pcm,
+		SampleRate: 8000,
+		Channels:   1,
+		MediaType:  pipeline.AudioMediaTypePCM,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, audio.PCMToMuLaw(pcm), out)
+}
+
+func TestTwilioOutputAudio_Float32StereoDownmixed(t *testing.T) {
+	tc := &TwilioConnection{}
+
+	// Left and right average to 0.25
+	samples := make([]float32, 0, 320)
+	for i := 0; i < 160; i++ {
+		samples = append(samples, 0.5, 0)
+	}
+
+	out, err := tc.encodeOutputAudio(&pipeline.AudioData{
+		Data:         audio.Float32ToBytes(samples, pipeline.SampleFormatF32),
+		SampleRate:   8000,
+		Channels:     2,
+		MediaType:    pipeline.AudioMediaTypeRaw,
+		SampleFormat: pipeline.SampleFormatF32,
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 160)
+
+	for _, b := range out {
+		assert.InDelta(t, 0.25, float64(audio.MuLawDecode(b))/32768, 0.01)
+	}
+}
+
+func TestTwilioOutputAudio_PCM16kResampled(t *testing.T) {
+	tc, err := NewTwilioConnection(nil)
+	require.NoError(t, err)
+	defer tc.resampler8to16.Free()
+	defer func() { tc.resampler16to8.Free() }()
+
+	// 400ms of 16kHz PCM in 20ms chunks
+	in := sinePCM(400, 16000, 6400, 0.5)
+	var mulaw []byte
+	for off := 0; off < len(in); off += 640 {
+		out, err := tc.encodeOutputAudio(&pipeline.AudioData{
+			Data:       in[off : off+640],
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypePCM,
+		})
+		require.NoError(t, err)
+		mulaw = append(mulaw, out...)
+	}
+
+	// Half as many samples at 8kHz, allowing for resampler delay
+	assert.InDelta(t, 3200, len(mulaw), 100)
+
+	// Sending 16kHz audio as-is would play back at the wrong pitch; the
+	// converted signal keeps the level of the 0.5 sine (RMS ≈ 0.354)
+	pcm := audio.MuLawToPCM(mulaw)
+	assert.InDelta(t, 0.5/math.Sqrt2, rms(pcm[len(pcm)/4:]), 0.03)
+
+	// 400Hz has 2 zero crossings per period: ~80 in the last 100ms at 8kHz
+	tail := pcm[len(pcm)-1600:]
+	crossings := 0
+	for i := 2; i < len(tail); i += 2 {
+		prev := int16(binary.LittleEndian.Uint16(tail[i-2:]))
+		cur := int16(binary.LittleEndian.Uint16(tail[i:]))
+		if (prev < 0) != (cur < 0) {
+			crossings++
+		}
+	}
+	assert.InDelta(t, 80, crossings, 4)
+}
+
+func TestTwilioCheckOutputAudio(t *testing.T) {
+	tc := &TwilioConnection{}
+
+	native := &pipeline.AudioData{SampleRate: 8000, Channels: 1, MediaType: pipeline.AudioMediaTypeMuLaw}
+	pcm16k := &pipeline.AudioData{SampleRate: 16000, Channels: 1, MediaType: pipeline.AudioMediaTypePCM}
+
+	assert.NoError(t, tc.checkOutputAudio(native))
+	assert.NoError(t, tc.checkOutputAudio(pcm16k))
+	assert.Error(t, tc.checkOutputAudio(&pipeline.AudioData{SampleRate: 48000, Channels: 2, MediaType: pipeline.AudioMediaTypeOpus}))
+	assert.Error(t, tc.checkOutputAudio(&pipeline.AudioData{SampleRate: 16000, Channels: 1, SampleFormat: "s24"}))
+
+	// Strict mode only accepts audio that needs no conversion
+	tc.SetStrictOutputFormat(true)
+	assert.NoError(t, tc.checkOutputAudio(native))
+	assert.Error(t, tc.checkOutputAudio(pcm16k))
+}
+
+// dialTwilioConnection serves a Twilio media stream and returns the started
+// server side connection, its handler and the connected Twilio side.
+func dialTwilioConnection(t *testing.T) (*TwilioConnection, *recordingHandler, *websocket.Conn) {
+	t.Helper()
+
+	handler := newRecordingHandler()
+	conns := make(chan *TwilioConnection, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		tc, err := NewTwilioConnection(ws)
+		if err != nil {
+			ws.Close()
+			return
+		}
+		tc.RegisterEventHandler(handler)
+		tc.Start()
+		conns <- tc
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case tc := <-conns:
+		t.Cleanup(func() { tc.Close() })
+		require.NoError(t, client.WriteJSON(TwilioMediaMessage{
+			Event:     "start",
+			StreamSid: "MZ123",
+			Start:     &TwilioStartPayload{StreamSid: "MZ123", CallSid: "CA123"},
+		}))
+		assert.Equal(t, ConnectionStateConnecting, <-handler.states)
+		assert.Equal(t, ConnectionStateConnected, <-handler.states)
+		return tc, handler, client
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for server connection")
+		return nil, nil, nil
+	}
+}
+
+func TestTwilioConnection_Hangup(t *testing.T) {
+	tc, handler, client := dialTwilioConnection(t)
+
+	hungUp := make(chan error, 1)
+	go func() { hungUp <- tc.Hangup("goodbye") }()
+
+	// Twilio acknowledges the mark once queued audio has played
+	var mark TwilioMediaMessage
+	require.NoError(t, client.ReadJSON(&mark))
+	require.Equal(t, "mark", mark.Event)
+	require.NoError(t, client.WriteJSON(TwilioMediaMessage{Event: "mark", StreamSid: "MZ123", Mark: mark.Mark}))
+
+	select {
+	case err := <-hungUp:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for Hangup")
+	}
+
+	// The media stream is closed, which ends the call, and the handler runs its cleanup
+	assert.Equal(t, ConnectionStateClosed, <-handler.states)
+	assert.Equal(t, ConnectionStateClosed, tc.State())
+	_, _, err := client.ReadMessage()
+	assert.Error(t, err)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hraban/opus"
 	"github.com/pion/webrtc/v4"
 	"github.com/pion/webrtc/v4/pkg/media"
@@ -22,11 +24,34 @@ const (
 	DefaultWebRTCBitRate    = 50000
 )
 
+// hangupFlushTimeout bounds how long Hangup waits for the hangup notice to
+// reach the peer before closing the PeerConnection.
+const hangupFlushTimeout = time.Second
+
 // WebRTCConfig holds configuration for WebRTC connection.
 type WebRTCConfig struct {
 	SampleRate int
 	Channels   int
 	BitRate    int
+
+	// ResumeGracePeriod is how long a dropped connection waits for the client
+	// to resume it before closing (0 disables resumption).
+	ResumeGracePeriod time.Duration
+}
+
+// ResumableConnection is a Connection whose PeerConnection can be replaced by
+// a reconnecting client, keeping the same handler, pipeline and conversation.
+type ResumableConnection interface {
+	Connection
+
+	// ResumeToken returns the token a client presents to resume this connection.
+	ResumeToken() string
+
+	// Resume replaces the underlying PeerConnection with pc and closes the old one.
+	Resume(pc *webrtc.PeerConnection) error
+
+	// Done is closed once the connection is closed for good.
+	Done() <-chan struct{}
 }
 
 // DefaultWebRTCConfig returns the default WebRTC configuration.
@@ -59,6 +84,11 @@ type webrtcConnection struct {
 	channels   int
 	bitRate    int
 
+	// Resumption
+	resumeToken string
+	resumeGrace time.Duration
+	resumeTimer *time.Timer
+
 	// Lifecycle management
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -67,7 +97,7 @@ type webrtcConnection struct {
 	mu     sync.RWMutex
 }
 
-var _ Connection = (*webrtcConnection)(nil)
+var _ ResumableConnection = (*webrtcConnection)(nil)
 
 // NewWebRTCConnection creates a new WebRTC connection with default config.
 func NewWebRTCConnection(peerID string, pc *webrtc.PeerConnection) Connection {
@@ -100,11 +130,15 @@ func NewWebRTCConnectionWithConfig(peerID string, pc *webrtc.PeerConnection, cfg
 		sampleRate:   cfg.SampleRate,
 		channels:     cfg.Channels,
 		bitRate:      cfg.BitRate,
+		resumeGrace:  cfg.ResumeGracePeriod,
 		ctx:          ctx,
 		cancel:       cancel,
 	}
+	if cfg.ResumeGracePeriod > 0 {
+		conn.resumeToken = uuid.New().String()
+	}
 
-	conn.start()
+	conn.start(pc)
 
 	return conn
 }
@@ -119,20 +153,19 @@ func (c *webrtcConnection) RegisterEventHandler(handler ConnectionEventHandler)
 	c.handler = handler
 }
 
-func (c *webrtcConnection) start() {
+func (c *webrtcConnection) start(pc *webrtc.PeerConnection) {
 	// Map WebRTC states to ConnectionState
-	c.pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
-		c.mu.RLock()
-		handler := c.handler
-		c.mu.RUnlock()
-
-		connState := mapWebRTCState(state)
-		handler.OnConnectionStateChange(connState)
+	pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		c.handlePeerState(pc, state)
 	})
 
 	// Handle incoming DataChannel for text messages
-	c.pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
 		c.mu.Lock()
+		if c.pc != pc {
+			c.mu.Unlock()
+			return
+		}
 		c.dataChannel = dc
 		c.mu.Unlock()
 
@@ -140,7 +173,7 @@ func (c *webrtcConnection) start() {
 	})
 
 	// Setup audio transceiver
-	transceiver, err := c.pc.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio, webrtc.RTPTransceiverInit{
+	transceiver, err := pc.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio, webrtc.RTPTransceiverInit{
 		Direction: webrtc.RTPTransceiverDirectionSendrecv,
 	})
 	if err != nil {
@@ -151,24 +184,117 @@ func (c *webrtcConnection) start() {
 	// Get local audio track from transceiver
 	if sender := transceiver.Sender(); sender != nil {
 		if track := sender.Track(); track != nil {
+			c.mu.Lock()
 			c.localAudioTrack = track.(*webrtc.TrackLocalStaticSample)
+			c.mu.Unlock()
 		}
 	}
 
 	// Handle incoming audio track
-	c.pc.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+	pc.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
 		log.Printf("[webrtc %s] OnTrack: %v, codec: %v", c.peerID, track.ID(), track.Codec().MimeType)
 		if track.Kind() == webrtc.RTPCodecTypeAudio {
 			c.mu.Lock()
+			if c.pc != pc {
+				c.mu.Unlock()
+				return
+			}
 			c.remoteAudioTrack = track
 			c.mu.Unlock()
 
 			c.wg.Add(1)
-			go c.readRemoteAudio()
+			go c.readRemoteAudio(track)
 		}
 	})
 }
 
+// handlePeerState forwards PeerConnection state changes to the handler. While
+// resumption is enabled, a dropped connection is reported as Reconnecting and
+// only closed once the grace period expires without a Resume.
+func (c *webrtcConnection) handlePeerState(pc *webrtc.PeerConnection, state webrtc.PeerConnectionState) {
+	c.mu.Lock()
+	// Ignore the old PeerConnection after a Resume
+	if c.pc != pc {
+		c.mu.Unlock()
+		return
+	}
+	handler := c.handler
+
+	connState := mapWebRTCState(state)
+	switch {
+	case c.resumeGrace > 0 && c.ctx.Err() == nil &&
+		(state == webrtc.PeerConnectionStateDisconnected || state == webrtc.PeerConnectionStateFailed):
+		if c.resumeTimer == nil {
+			log.Printf("[webrtc %s] connection lost, waiting %v for resume", c.peerID, c.resumeGrace)
+			c.resumeTimer = time.AfterFunc(c.resumeGrace, func() {
+				c.resumeExpired(pc)
+			})
+		}
+		connState = ConnectionStateReconnecting
+	case state == webrtc.PeerConnectionStateConnected && c.resumeTimer != nil:
+		c.resumeTimer.Stop()
+		c.resumeTimer = nil
+	}
+	c.mu.Unlock()
+
+	handler.OnConnectionStateChange(connState)
+}
+
+// resumeExpired closes the connection if the client did not resume in time.
+func (c *webrtcConnection) resumeExpired(pc *webrtc.PeerConnection) {
+	c.mu.Lock()
+	if c.pc != pc {
+		c.mu.Unlock()
+		return
+	}
+	c.resumeTimer = nil
+	c.mu.Unlock()
+
+	log.Printf("[webrtc %s] resume grace period expired", c.peerID)
+	c.Close()
+}
+
+func (c *webrtcConnection) ResumeToken() string {
+	return c.resumeToken
+}
+
+func (c *webrtcConnection) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Resume swaps in the PeerConnection of a reconnecting client. The handler and
+// audio codecs are kept, so the pipeline continues where it left off.
+func (c *webrtcConnection) Resume(pc *webrtc.PeerConnection) error {
+	if c.resumeGrace <= 0 {
+		return errors.New("resumption is not enabled")
+	}
+
+	c.mu.Lock()
+	if c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return errors.New("connection is closed")
+	}
+	old := c.pc
+	c.pc = pc
+	c.dataChannel = nil
+	c.remoteAudioTrack = nil
+	c.localAudioTrack = nil
+	if c.resumeTimer != nil {
+		c.resumeTimer.Stop()
+		c.resumeTimer = nil
+	}
+	c.mu.Unlock()
+
+	log.Printf("[webrtc %s] resuming connection", c.peerID)
+	c.start(pc)
+
+	// Closing the old PeerConnection ends its audio reader
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
 // dataChannelImagePayload 定义 DataChannel 中图像消息的 JSON 格式
 type dataChannelImagePayload struct {
 	Type     string `json:"type"`      // "image"
@@ -242,7 +368,7 @@ func (c *webrtcConnection) setupDataChannel(dc *webrtc.DataChannel) {
 	})
 }
 
-func (c *webrtcConnection) readRemoteAudio() {
+func (c *webrtcConnection) readRemoteAudio(track *webrtc.TrackRemote) {
 	defer c.wg.Done()
 
 	log.Printf("[webrtc %s] 开始读取远程音频...", c.peerID)
@@ -257,11 +383,12 @@ func (c *webrtcConnection) readRemoteAudio() {
 			return
 		default:
 			c.mu.RLock()
-			track := c.remoteAudioTrack
+			current := c.remoteAudioTrack
 			c.mu.RUnlock()
 
-			if track == nil {
-				log.Printf("[webrtc %s] 远程音频轨道为空，退出", c.peerID)
+			// 连接恢复后旧轨道被替换
+			if current != track {
+				log.Printf("[webrtc %s] 远程音频轨道已替换，退出", c.peerID)
 				return
 			}
 
@@ -376,15 +503,55 @@ func (c *webrtcConnection) sendAudioMessage(msg *pipeline.PipelineMessage) {
 
 func (c *webrtcConnection) Close() error {
 	c.once.Do(func() {
+		c.mu.Lock()
 		c.cancel()
-		c.wg.Wait()
-		if c.pc != nil {
-			c.pc.Close()
+		if c.resumeTimer != nil {
+			c.resumeTimer.Stop()
+			c.resumeTimer = nil
 		}
+		pc := c.pc
+		c.mu.Unlock()
+
+		// 先关闭 PeerConnection，阻塞在 ReadRTP 的读取协程才能退出
+		if pc != nil {
+			pc.Close()
+		}
+		c.wg.Wait()
 	})
 	return nil
 }
 
+// Hangup notifies the peer over the DataChannel (if open) and closes the
+// connection once the notice is delivered. Closing the PeerConnection reports
+// ConnectionStateClosed to the registered handler.
+func (c *webrtcConnection) Hangup(reason string) error {
+	log.Printf("[webrtc %s] hanging up: %s", c.peerID, reason)
+
+	c.mu.RLock()
+	dc := c.dataChannel
+	c.mu.RUnlock()
+
+	if dc != nil && dc.ReadyState() == webrtc.DataChannelStateOpen {
+		data, _ := json.Marshal(map[string]string{"type": "hangup", "reason": reason})
+		if err := dc.Send(data); err != nil {
+			log.Printf("[webrtc %s] failed to send hangup: %v", c.peerID, err)
+		} else {
+			// Closing the PeerConnection drops anything the DataChannel has not delivered yet
+			deadline := time.Now().Add(hangupFlushTimeout)
+			for dc.BufferedAmount() > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}
+
+	return c.Close()
+}
+
+// PeerConnectionState maps a WebRTC PeerConnectionState to ConnectionState.
+func PeerConnectionState(state webrtc.PeerConnectionState) ConnectionState {
+	return mapWebRTCState(state)
+}
+
 // mapWebRTCState maps WebRTC PeerConnectionState to ConnectionState.
 func mapWebRTCState(state webrtc.PeerConnectionState) ConnectionState {
 	switch state {
@@ -0,0 +1,184 @@
+package connection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHandler collects messages and state changes from a connection.
+type recordingHandler struct {
+	messages chan *pipeline.PipelineMessage
+	states   chan ConnectionState
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{
+		messages: make(chan *pipeline.PipelineMessage, 16),
+		states:   make(chan ConnectionState, 4),
+	}
+}
+
+func (h *recordingHandler) OnConnectionStateChange(state ConnectionState) { h.states <- state }
+func (h *recordingHandler) OnMessage(msg *pipeline.PipelineMessage)       { h.messages <- msg }
+func (h *recordingHandler) OnError(err error)                             {}
+
+func newPeerConnection(t *testing.T) *webrtc.PeerConnection {
+	t.Helper()
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	t.Cleanup(func() { pc.Close() })
+	return pc
+}
+
+// newResumableConnection returns a WebRTC connection with the given resume
+// grace period and its PeerConnection.
+func newResumableConnection(t *testing.T, grace time.Duration) (*webrtcConnection, *webrtc.PeerConnection) {
+	t.Helper()
+	pc := newPeerConnection(t)
+	cfg := DefaultWebRTCConfig()
+	cfg.ResumeGracePeriod = grace
+	conn := NewWebRTCConnectionWithConfig("test-peer", pc, cfg).(*webrtcConnection)
+	t.Cleanup(func() { conn.Close() })
+	return conn, pc
+}
+
+func isDone(conn ResumableConnection) bool {
+	select {
+	case <-conn.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWebRTCConnection_ResumeDisabled(t *testing.T) {
+	conn, pc := newResumableConnection(t, 0)
+	assert.Empty(t, conn.ResumeToken())
+	assert.Error(t, conn.Resume(newPeerConnection(t)))
+
+	// Without a grace period a dropped peer is reported as is
+	handler := newRecordingHandler()
+	conn.RegisterEventHandler(handler)
+	conn.handlePeerState(pc, webrtc.PeerConnectionStateFailed)
+	assert.Equal(t, ConnectionStateFailed, <-handler.states)
+}
+
+func TestWebRTCConnection_Resume(t *testing.T) {
+	conn, old := newResumableConnection(t, 100*time.Millisecond)
+	handler := newRecordingHandler()
+	conn.RegisterEventHandler(handler)
+	assert.NotEmpty(t, conn.ResumeToken())
+
+	// The peer drops: reported as reconnecting, the connection stays open
+	conn.handlePeerState(old, webrtc.PeerConnectionStateFailed)
+	assert.Equal(t, ConnectionStateReconnecting, <-handler.states)
+	assert.False(t, isDone(conn))
+
+	// The client comes back on a new PeerConnection within the grace period
+	pc := newPeerConnection(t)
+	require.NoError(t, conn.Resume(pc))
+	assert.Equal(t, webrtc.PeerConnectionStateClosed, old.ConnectionState(), "old PeerConnection should be closed")
+
+	// State changes of the old PeerConnection no longer reach the handler
+	conn.handlePeerState(old, webrtc.PeerConnectionStateClosed)
+	assert.Empty(t, handler.states)
+
+	// Resume stopped the grace timer
+	assert.Never(t, func() bool { return isDone(conn) }, 300*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestWebRTCConnection_ResumeExpired(t *testing.T) {
+	conn, pc := newResumableConnection(t, 50*time.Millisecond)
+
+	conn.handlePeerState(pc, webrtc.PeerConnectionStateDisconnected)
+	select {
+	case <-conn.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the grace period to expire")
+	}
+
+	assert.Error(t, conn.Resume(newPeerConnection(t)), "closed connection cannot be resumed")
+}
+
+// newLoopbackPeerConnection returns a PeerConnection that gathers loopback
+// candidates, so two of them connect without a network.
+func newLoopbackPeerConnection(t *testing.T) *webrtc.PeerConnection {
+	t.Helper()
+	se := webrtc.SettingEngine{}
+	se.SetIncludeLoopbackCandidate(true)
+	pc, err := webrtc.NewAPI(webrtc.WithSettingEngine(se)).NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	t.Cleanup(func() { pc.Close() })
+	return pc
+}
+
+// negotiate connects client to server with a single offer/answer exchange.
+func negotiate(t *testing.T, client, server *webrtc.PeerConnection) {
+	t.Helper()
+
+	offer, err := client.CreateOffer(nil)
+	require.NoError(t, err)
+	gathered := webrtc.GatheringCompletePromise(client)
+	require.NoError(t, client.SetLocalDescription(offer))
+	<-gathered
+
+	require.NoError(t, server.SetRemoteDescription(*client.LocalDescription()))
+	answer, err := server.CreateAnswer(nil)
+	require.NoError(t, err)
+	gathered = webrtc.GatheringCompletePromise(server)
+	require.NoError(t, server.SetLocalDescription(answer))
+	<-gathered
+
+	require.NoError(t, client.SetRemoteDescription(*server.LocalDescription()))
+}
+
+func TestWebRTCConnection_Hangup(t *testing.T) {
+	pc := newLoopbackPeerConnection(t)
+	conn := NewWebRTCConnectionWithConfig("test-peer", pc, DefaultWebRTCConfig()).(*webrtcConnection)
+	t.Cleanup(func() { conn.Close() })
+	handler := newRecordingHandler()
+	conn.RegisterEventHandler(handler)
+
+	client := newLoopbackPeerConnection(t)
+	dc, err := client.CreateDataChannel("events", nil)
+	require.NoError(t, err)
+	messages := make(chan string, 4)
+	dc.OnMessage(func(msg webrtc.DataChannelMessage) { messages <- string(msg.Data) })
+
+	negotiate(t, client, pc)
+	require.Eventually(t, func() bool {
+		conn.mu.RLock()
+		defer conn.mu.RUnlock()
+		return conn.dataChannel != nil && conn.dataChannel.ReadyState() == webrtc.DataChannelStateOpen
+	}, 5*time.Second, 10*time.Millisecond, "DataChannel should open")
+
+	require.NoError(t, conn.Hangup("goodbye"))
+
+	// The peer is told why, then the PeerConnection is closed
+	select {
+	case msg := <-messages:
+		assert.JSONEq(t, `{"type":"hangup","reason":"goodbye"}`, msg)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the hangup message")
+	}
+	assert.Equal(t, webrtc.PeerConnectionStateClosed, pc.ConnectionState())
+	assert.True(t, isDone(conn))
+
+	// The handler runs its cleanup
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case state := <-handler.states:
+			if state == ConnectionStateClosed {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Timeout waiting for closed state")
+		}
+	}
+}
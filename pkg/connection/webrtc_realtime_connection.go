@@ -5,8 +5,12 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,6 +42,34 @@ type WebRTCRealtimeEventHandler interface {
 	OnError(err error)
 }
 
+// AudioTrackInfo identifies an inbound audio track. ID and StreamID are the
+// MediaStreamTrack and MediaStream IDs signaled by the client (msid), so a
+// client publishing several tracks can label them, e.g. "mic" and "system".
+type AudioTrackInfo struct {
+	ID       string
+	StreamID string
+}
+
+// WebRTCRealtimeTrackHandler is implemented by event handlers that accept more
+// than one inbound audio track, e.g. a microphone and system audio. Audio from
+// every track is then delivered to OnTrackAudioReceived instead of
+// OnAudioReceived. Handlers that do not implement it only receive the first
+// audio track; if it ends, the next track added takes its place.
+//
+// Tracks may also be added after the connection is established, by
+// renegotiating with Renegotiate.
+type WebRTCRealtimeTrackHandler interface {
+	// OnAudioTrackAdded is called when an inbound audio track is added.
+	OnAudioTrackAdded(track AudioTrackInfo)
+
+	// OnTrackAudioReceived is called when audio is received on track.
+	// Data is PCM audio (int16 samples, little-endian).
+	OnTrackAudioReceived(track AudioTrackInfo, data []byte, sampleRate, channels int, timestamp time.Time)
+
+	// OnAudioTrackRemoved is called when an inbound audio track ends.
+	OnAudioTrackRemoved(track AudioTrackInfo)
+}
+
 // NoOpWebRTCRealtimeEventHandler is a no-op implementation of WebRTCRealtimeEventHandler.
 type NoOpWebRTCRealtimeEventHandler struct{}
 
@@ -59,6 +91,10 @@ type WebRTCRealtimeConnection interface {
 	// SessionID returns the session ID for this connection.
 	SessionID() string
 
+	// ResumeToken returns the secret token a client presents to resume or
+	// renegotiate this session. Unlike the session ID it is never logged.
+	ResumeToken() string
+
 	// RegisterEventHandler registers the event handler.
 	RegisterEventHandler(handler WebRTCRealtimeEventHandler)
 
@@ -77,24 +113,48 @@ type WebRTCRealtimeConnection interface {
 
 	// SupportsRTPAudio returns true (always supports RTP audio).
 	SupportsRTPAudio() bool
+
+	// SampleRate returns the clock rate negotiated for the outgoing Opus track.
+	// SendAudio expects PCM at this rate. It is 48000 until the connection is
+	// established.
+	SampleRate() int
+
+	// AudioTracks returns the inbound audio tracks, in the order they were added.
+	AudioTracks() []AudioTrackInfo
+
+	// Renegotiate applies a new SDP offer from the client to the established
+	// connection, e.g. one that adds an audio track, and returns the answer.
+	Renegotiate(offer webrtc.SessionDescription) (*webrtc.SessionDescription, error)
+
+	// Resume replaces the PeerConnection with pc after the client
+	// reconnected, keeping the session. The caller negotiates pc.
+	Resume(pc *webrtc.PeerConnection) error
 }
 
 // webrtcRealtimeConnectionImpl implements WebRTCRealtimeConnection.
 type webrtcRealtimeConnectionImpl struct {
-	peerID    string
-	sessionID string
+	peerID      string
+	sessionID   string
+	resumeToken string
 
 	// WebRTC core
 	pc          *webrtc.PeerConnection
 	dataChannel *webrtc.DataChannel
 
-	// Audio tracks
-	localAudioTrack  *webrtc.TrackLocalStaticSample
-	remoteAudioTrack *webrtc.TrackRemote
+	// Audio tracks. Inbound tracks are kept in the order they were added;
+	// each is decoded by its own reader.
+	localAudioTrack   *webrtc.TrackLocalStaticSample
+	remoteAudioTracks []remoteAudioTrack
 
-	// Audio codecs
+	// Audio codec
 	audioEncoder *opus.Encoder
-	audioDecoder *opus.Decoder
+	sampleRate   int // Output clock rate the encoder runs at
+
+	// Serializes SDP offer/answer exchanges and Resume
+	negotiateMu sync.Mutex
+
+	// Context passed to Start, reused for the readers of a resumed PeerConnection
+	ctx context.Context
 
 	// Event handler
 	handler WebRTCRealtimeEventHandler
@@ -110,17 +170,8 @@ func NewWebRTCRealtimeConnection(pc *webrtc.PeerConnection) (WebRTCRealtimeConne
 	peerID := uuid.New().String()[:8]
 	sessionID := "sess_" + uuid.New().String()[:12]
 
-	// Create Opus encoder for audio output (48kHz mono)
-	audioEncoder, err := opus.NewEncoder(48000, 1, opus.AppVoIP)
-	if err != nil {
-		return nil, err
-	}
-	audioEncoder.SetBitrate(50000)
-	audioEncoder.SetComplexity(10)
-	audioEncoder.SetDTX(true)
-
-	// Create Opus decoder for audio input (48kHz mono)
-	audioDecoder, err := opus.NewDecoder(48000, 1)
+	// Create Opus encoder for audio output (48kHz mono until negotiated)
+	audioEncoder, err := newRealtimeOpusEncoder(DefaultWebRTCSampleRate)
 	if err != nil {
 		return nil, err
 	}
@@ -128,13 +179,35 @@ func NewWebRTCRealtimeConnection(pc *webrtc.PeerConnection) (WebRTCRealtimeConne
 	return &webrtcRealtimeConnectionImpl{
 		peerID:       peerID,
 		sessionID:    sessionID,
+		resumeToken:  uuid.New().String(),
 		pc:           pc,
 		audioEncoder: audioEncoder,
-		audioDecoder: audioDecoder,
+		sampleRate:   DefaultWebRTCSampleRate,
 		handler:      &NoOpWebRTCRealtimeEventHandler{},
 	}, nil
 }
 
+// newRealtimeOpusEncoder creates the mono Opus encoder for audio output.
+func newRealtimeOpusEncoder(sampleRate int) (*opus.Encoder, error) {
+	encoder, err := opus.NewEncoder(sampleRate, 1, opus.AppVoIP)
+	if err != nil {
+		return nil, err
+	}
+	encoder.SetBitrate(50000)
+	encoder.SetComplexity(10)
+	encoder.SetDTX(true)
+	return encoder, nil
+}
+
+// isOpusSampleRate reports whether libopus can encode at rate.
+func isOpusSampleRate(rate int) bool {
+	switch rate {
+	case 8000, 12000, 16000, 24000, 48000:
+		return true
+	}
+	return false
+}
+
 func (c *webrtcRealtimeConnectionImpl) PeerID() string {
 	return c.peerID
 }
@@ -143,6 +216,10 @@ func (c *webrtcRealtimeConnectionImpl) SessionID() string {
 	return c.sessionID
 }
 
+func (c *webrtcRealtimeConnectionImpl) ResumeToken() string {
+	return c.resumeToken
+}
+
 func (c *webrtcRealtimeConnectionImpl) RegisterEventHandler(handler WebRTCRealtimeEventHandler) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -151,14 +228,54 @@ func (c *webrtcRealtimeConnectionImpl) RegisterEventHandler(handler WebRTCRealti
 
 // Start initializes WebRTC handlers and starts processing.
 func (c *webrtcRealtimeConnectionImpl) Start(ctx context.Context) error {
+	// Create local audio track explicitly for sending audio. It is kept
+	// across Resume so the pipeline output keeps its destination.
+	localAudioTrack, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus},
+		"audio",
+		"realtime-audio-"+c.sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create local audio track: %w", err)
+	}
+
+	c.mu.Lock()
+	c.ctx = ctx
+	c.localAudioTrack = localAudioTrack
+	pc := c.pc
+	c.mu.Unlock()
+
+	return c.attach(ctx, pc)
+}
+
+// attach registers the connection's handlers on pc and adds the local audio
+// track to it. Callbacks from a PeerConnection replaced by Resume are ignored.
+func (c *webrtcRealtimeConnectionImpl) attach(ctx context.Context, pc *webrtc.PeerConnection) error {
 	// Handle connection state changes
-	c.pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
-		c.handler.OnConnectionStateChange(state)
+	pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		c.mu.RLock()
+		current := c.pc == pc
+		handler := c.handler
+		c.mu.RUnlock()
+		if !current {
+			return
+		}
+
+		// Negotiation is complete once connected; match the encoder to it
+		// before the handler builds the pipeline
+		if state == webrtc.PeerConnectionStateConnected {
+			c.applyNegotiatedClockRate()
+		}
+		handler.OnConnectionStateChange(state)
 	})
 
 	// Handle incoming DataChannel (for Realtime API events)
-	c.pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
 		c.mu.Lock()
+		if c.pc != pc {
+			c.mu.Unlock()
+			return
+		}
 		c.dataChannel = dc
 		c.mu.Unlock()
 
@@ -171,38 +288,142 @@ func (c *webrtcRealtimeConnectionImpl) Start(ctx context.Context) error {
 		})
 	})
 
-	// Create local audio track explicitly for sending audio
-	localAudioTrack, err := webrtc.NewTrackLocalStaticSample(
-		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus},
-		"audio",
-		"realtime-audio-"+c.sessionID,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create local audio track: %w", err)
-	}
-	c.localAudioTrack = localAudioTrack
-
 	// Add the track to peer connection
-	_, err = c.pc.AddTrack(localAudioTrack)
-	if err != nil {
+	c.mu.RLock()
+	localAudioTrack := c.localAudioTrack
+	c.mu.RUnlock()
+	if _, err := pc.AddTrack(localAudioTrack); err != nil {
 		return fmt.Errorf("failed to add audio track: %w", err)
 	}
 
-	// Handle incoming audio track
-	c.pc.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
-		log.Printf("[webrtc-realtime %s] OnTrack: %v, codec: %v", c.sessionID, track.ID(), track.Codec().MimeType)
-		if track.Kind() == webrtc.RTPCodecTypeAudio {
-			c.mu.Lock()
-			c.remoteAudioTrack = track
+	// Handle incoming audio tracks, including ones added by renegotiation
+	pc.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+		log.Printf("[webrtc-realtime %s] OnTrack: %v (stream %v), codec: %v", c.sessionID, track.ID(), track.StreamID(), track.Codec().MimeType)
+		if track.Kind() != webrtc.RTPCodecTypeAudio {
+			return
+		}
+
+		info := AudioTrackInfo{ID: track.ID(), StreamID: track.StreamID()}
+		c.mu.Lock()
+		if c.pc != pc {
 			c.mu.Unlock()
+			return
+		}
+		c.remoteAudioTracks = append(c.remoteAudioTracks, remoteAudioTrack{info: info, track: track})
+		handler := c.handler
+		c.mu.Unlock()
 
-			go c.readRemoteAudio(ctx)
+		if h, ok := handler.(WebRTCRealtimeTrackHandler); ok {
+			h.OnAudioTrackAdded(info)
 		}
+		go c.readRemoteAudio(ctx, track, info)
 	})
 
 	return nil
 }
 
+// Resume replaces the PeerConnection with pc, negotiated by a client that
+// reconnected after losing its network. The session ID, handler and outgoing
+// audio track are kept, so the session's pipeline continues where it left off.
+// The old PeerConnection is closed and its events are ignored.
+func (c *webrtcRealtimeConnectionImpl) Resume(pc *webrtc.PeerConnection) error {
+	c.negotiateMu.Lock()
+	defer c.negotiateMu.Unlock()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return errors.New("connection is closed")
+	}
+	if c.ctx == nil {
+		c.mu.Unlock()
+		return errors.New("connection is not started")
+	}
+	old := c.pc
+	c.pc = pc
+	c.dataChannel = nil
+	c.remoteAudioTracks = nil
+	ctx := c.ctx
+	c.mu.Unlock()
+
+	if err := c.attach(ctx, pc); err != nil {
+		return err
+	}
+
+	// Closing the old PeerConnection ends its audio readers
+	if old != nil {
+		old.Close()
+	}
+	log.Printf("[webrtc-realtime %s] resumed on a new PeerConnection", c.sessionID)
+	return nil
+}
+
+// peerConnection returns the current PeerConnection.
+func (c *webrtcRealtimeConnectionImpl) peerConnection() *webrtc.PeerConnection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pc
+}
+
+// remoteAudioTrack is an inbound audio track.
+type remoteAudioTrack struct {
+	info  AudioTrackInfo
+	track *webrtc.TrackRemote
+}
+
+// AudioTracks returns the inbound audio tracks, in the order they were added.
+func (c *webrtcRealtimeConnectionImpl) AudioTracks() []AudioTrackInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tracks := make([]AudioTrackInfo, len(c.remoteAudioTracks))
+	for i, t := range c.remoteAudioTracks {
+		tracks[i] = t.info
+	}
+	return tracks
+}
+
+// Renegotiate applies a new SDP offer to the established connection and
+// returns the answer. New inbound tracks are reported through OnTrack.
+func (c *webrtcRealtimeConnectionImpl) Renegotiate(offer webrtc.SessionDescription) (*webrtc.SessionDescription, error) {
+	c.negotiateMu.Lock()
+	defer c.negotiateMu.Unlock()
+
+	if offer.Type != webrtc.SDPTypeOffer {
+		return nil, fmt.Errorf("expected an SDP offer, got %s", offer.Type)
+	}
+	pc := c.peerConnection()
+	if err := pc.SetRemoteDescription(offer); err != nil {
+		return nil, fmt.Errorf("failed to set remote description: %w", err)
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create answer: %w", err)
+	}
+	if err := pc.SetLocalDescription(answer); err != nil {
+		return nil, fmt.Errorf("failed to set local description: %w", err)
+	}
+	return pc.LocalDescription(), nil
+}
+
+// removeAudioTrack forgets an inbound track that has ended. Tracks of a
+// PeerConnection replaced by Resume are already forgotten and not reported.
+func (c *webrtcRealtimeConnectionImpl) removeAudioTrack(track *webrtc.TrackRemote, info AudioTrackInfo) {
+	c.mu.Lock()
+	n := len(c.remoteAudioTracks)
+	c.remoteAudioTracks = slices.DeleteFunc(c.remoteAudioTracks, func(t remoteAudioTrack) bool {
+		return t.track == track
+	})
+	removed := len(c.remoteAudioTracks) < n
+	handler := c.handler
+	closed := c.closed
+	c.mu.Unlock()
+
+	if h, ok := handler.(WebRTCRealtimeTrackHandler); ok && removed && !closed {
+		h.OnAudioTrackRemoved(info)
+	}
+}
+
 // imagePayload 定义 DataChannel 中图像消息的 JSON 格式
 type imagePayload struct {
 	Type     string `json:"type"`      // "image"
@@ -251,8 +472,17 @@ func (c *webrtcRealtimeConnectionImpl) handleDataChannelMessage(data []byte) {
 	c.handler.OnClientEvent(event)
 }
 
-// readRemoteAudio reads and decodes audio from the remote RTP track.
-func (c *webrtcRealtimeConnectionImpl) readRemoteAudio(ctx context.Context) {
+// readRemoteAudio reads and decodes audio from a remote RTP track until it ends.
+func (c *webrtcRealtimeConnectionImpl) readRemoteAudio(ctx context.Context, track *webrtc.TrackRemote, info AudioTrackInfo) {
+	defer c.removeAudioTrack(track, info)
+
+	// Each track has its own decoder state (48kHz mono)
+	decoder, err := opus.NewDecoder(48000, 1)
+	if err != nil {
+		log.Printf("[webrtc-realtime %s] failed to create Opus decoder for track %s: %v", c.sessionID, info.ID, err)
+		c.handler.OnError(err)
+		return
+	}
 	pcmBuf := make([]int16, 1920) // 20ms at 48kHz mono
 
 	for {
@@ -261,16 +491,25 @@ func (c *webrtcRealtimeConnectionImpl) readRemoteAudio(ctx context.Context) {
 			return
 		default:
 			c.mu.RLock()
-			track := c.remoteAudioTrack
 			closed := c.closed
+			handler := c.handler
+			primary := len(c.remoteAudioTracks) > 0 && c.remoteAudioTracks[0].track == track
+			current := slices.ContainsFunc(c.remoteAudioTracks, func(t remoteAudioTrack) bool {
+				return t.track == track
+			})
 			c.mu.RUnlock()
 
-			if closed || track == nil {
+			// Closed, or the track's PeerConnection was replaced by Resume
+			if closed || !current {
 				return
 			}
 
 			rtpPacket, _, err := track.ReadRTP()
 			if err != nil {
+				if errors.Is(err, io.EOF) {
+					log.Printf("[webrtc-realtime %s] audio track %s ended", c.sessionID, info.ID)
+					return
+				}
 				log.Printf("[webrtc-realtime %s] RTP read error: %v", c.sessionID, err)
 				continue
 			}
@@ -283,7 +522,7 @@ func (c *webrtcRealtimeConnectionImpl) readRemoteAudio(ctx context.Context) {
 				continue
 			}
 
-			n, err := c.audioDecoder.Decode(rtpPacket.Payload, pcmBuf)
+			n, err := decoder.Decode(rtpPacket.Payload, pcmBuf)
 			if err != nil {
 				log.Printf("[webrtc-realtime %s] Opus decode error: payload len=%d, error=%v",
 					c.sessionID, len(rtpPacket.Payload), err)
@@ -293,10 +532,71 @@ func (c *webrtcRealtimeConnectionImpl) readRemoteAudio(ctx context.Context) {
 			// Convert int16 to bytes
 			audioData := utils.Int16SliceToByteSlice(pcmBuf[:n])
 
-			// Notify handler
-			c.handler.OnAudioReceived(audioData, 48000, 1, time.Now())
+			// Notify handler: track-aware handlers get every track, others only the first
+			if h, ok := handler.(WebRTCRealtimeTrackHandler); ok {
+				h.OnTrackAudioReceived(info, audioData, 48000, 1, time.Now())
+			} else if primary {
+				handler.OnAudioReceived(audioData, 48000, 1, time.Now())
+			}
+		}
+	}
+}
+
+// negotiatedClockRate returns the Opus clock rate negotiated for the local
+// audio track, or 0 if it is not known yet.
+func (c *webrtcRealtimeConnectionImpl) negotiatedClockRate() int {
+	c.mu.RLock()
+	localTrack := c.localAudioTrack
+	c.mu.RUnlock()
+
+	if localTrack == nil {
+		return 0
+	}
+
+	for _, sender := range c.peerConnection().GetSenders() {
+		if sender.Track() != localTrack {
+			continue
+		}
+		for _, codec := range sender.GetParameters().Codecs {
+			if strings.EqualFold(codec.MimeType, webrtc.MimeTypeOpus) {
+				return int(codec.ClockRate)
+			}
 		}
 	}
+	return 0
+}
+
+// applyNegotiatedClockRate recreates the Opus encoder when the client
+// negotiated a clock rate other than 48kHz, so output audio plays at the
+// right pitch and speed.
+func (c *webrtcRealtimeConnectionImpl) applyNegotiatedClockRate() {
+	rate := c.negotiatedClockRate()
+	if rate == 0 || rate == c.SampleRate() {
+		return
+	}
+	if !isOpusSampleRate(rate) {
+		log.Printf("[webrtc-realtime %s] unsupported Opus clock rate %d, keeping %d", c.sessionID, rate, c.SampleRate())
+		return
+	}
+
+	encoder, err := newRealtimeOpusEncoder(rate)
+	if err != nil {
+		log.Printf("[webrtc-realtime %s] failed to create Opus encoder at %dHz: %v", c.sessionID, rate, err)
+		return
+	}
+
+	c.mu.Lock()
+	c.audioEncoder = encoder
+	c.sampleRate = rate
+	c.mu.Unlock()
+	log.Printf("[webrtc-realtime %s] negotiated Opus clock rate %dHz", c.sessionID, rate)
+}
+
+// SampleRate returns the clock rate negotiated for the outgoing audio track.
+func (c *webrtcRealtimeConnectionImpl) SampleRate() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sampleRate
 }
 
 // SendEvent sends a Realtime API server event via DataChannel.
@@ -327,6 +627,8 @@ func (c *webrtcRealtimeConnectionImpl) SendAudio(data []byte, sampleRate, channe
 	c.mu.RLock()
 	track := c.localAudioTrack
 	closed := c.closed
+	encoder := c.audioEncoder
+	rate := c.sampleRate
 	c.mu.RUnlock()
 
 	if closed || track == nil {
@@ -336,14 +638,14 @@ func (c *webrtcRealtimeConnectionImpl) SendAudio(data []byte, sampleRate, channe
 	// Convert bytes to int16 samples
 	samples := utils.ByteSliceToInt16Slice(data)
 
-	// Encode to Opus (20ms frames at 48kHz = 960 samples)
-	frameSize := 960
+	// Encode to Opus (20ms frames at the negotiated rate, 960 samples at 48kHz)
+	frameSize := rate / 50
 	opusBuf := make([]byte, 1275) // Max Opus frame size
 
 	// Process audio in frames
 	for offset := 0; offset+frameSize <= len(samples); offset += frameSize {
 		frame := samples[offset : offset+frameSize]
-		n, err := c.audioEncoder.Encode(frame, opusBuf)
+		n, err := encoder.Encode(frame, opusBuf)
 		if err != nil {
 			log.Printf("[webrtc-realtime %s] Opus encode error: %v", c.sessionID, err)
 			continue
@@ -371,10 +673,11 @@ func (c *webrtcRealtimeConnectionImpl) Close() error {
 	c.once.Do(func() {
 		c.mu.Lock()
 		c.closed = true
+		pc := c.pc
 		c.mu.Unlock()
 
-		if c.pc != nil {
-			c.pc.Close()
+		if pc != nil {
+			pc.Close()
 		}
 	})
 	return nil
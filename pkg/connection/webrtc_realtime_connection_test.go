@@ -0,0 +1,52 @@
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOpusAPI returns a webrtc API whose only audio codec is Opus at clockRate.
+func newOpusAPI(t *testing.T, clockRate uint32) *webrtc.API {
+	t.Helper()
+	m := &webrtc.MediaEngine{}
+	require.NoError(t, m.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: clockRate, Channels: 2},
+		PayloadType:        111,
+	}, webrtc.RTPCodecTypeAudio))
+	return webrtc.NewAPI(webrtc.WithMediaEngine(m))
+}
+
+func TestWebRTCRealtimeConnection_NegotiatedClockRate(t *testing.T) {
+	// The client only offers Opus at 16kHz instead of the usual 48kHz
+	api := newOpusAPI(t, 16000)
+	client, err := api.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio)
+	require.NoError(t, err)
+	offer, err := client.CreateOffer(nil)
+	require.NoError(t, err)
+	require.NoError(t, client.SetLocalDescription(offer))
+
+	pc, err := api.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	conn, err := NewWebRTCRealtimeConnection(pc)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.Start(context.Background()))
+	assert.Equal(t, DefaultWebRTCSampleRate, conn.SampleRate(), "48kHz until negotiated")
+
+	require.NoError(t, pc.SetRemoteDescription(offer))
+	answer, err := pc.CreateAnswer(nil)
+	require.NoError(t, err)
+	require.NoError(t, pc.SetLocalDescription(answer))
+
+	// Applied when the connection is established, before the pipeline is built
+	conn.(*webrtcRealtimeConnectionImpl).applyNegotiatedClockRate()
+	assert.Equal(t, 16000, conn.SampleRate())
+	assert.NoError(t, conn.SendAudio(make([]byte, 2*320), 16000, 1), "one 20ms frame at the negotiated rate")
+}
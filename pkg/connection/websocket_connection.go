@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/asticode/go-astiav"
 	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 )
 
@@ -16,6 +19,11 @@ const (
 	DefaultWSWriteWait  = 10 * time.Second
 	DefaultWSPongWait   = 60 * time.Second
 	DefaultWSPingPeriod = 54 * time.Second // Must be less than pongWait
+
+	// DefaultWSAudioSampleRate is the sample rate of raw binary audio clients.
+	DefaultWSAudioSampleRate = 16000
+	// DefaultWSAudioFrameMs is the duration of incoming binary audio frames.
+	DefaultWSAudioFrameMs = 20
 )
 
 // WebSocketConfig holds configuration for WebSocket connection.
@@ -25,6 +33,16 @@ type WebSocketConfig struct {
 	WriteWait  time.Duration
 	PongWait   time.Duration
 	PingPeriod time.Duration
+
+	// BinaryAudio sends outgoing audio as binary frames of raw 16-bit
+	// little-endian PCM at SampleRate instead of base64 JSON messages.
+	// Text is still sent as JSON. Incoming binary frames are always read
+	// as raw PCM at SampleRate.
+	BinaryAudio bool
+
+	// FrameMs splits incoming binary audio into frames of this duration.
+	// 0 delivers each binary message as received.
+	FrameMs int
 }
 
 // DefaultWebSocketConfig returns the default WebSocket configuration.
@@ -38,12 +56,27 @@ func DefaultWebSocketConfig() WebSocketConfig {
 	}
 }
 
+// DefaultWebSocketAudioConfig returns the configuration for clients that
+// stream raw 16kHz mono PCM as binary frames.
+func DefaultWebSocketAudioConfig() WebSocketConfig {
+	cfg := DefaultWebSocketConfig()
+	cfg.SampleRate = DefaultWSAudioSampleRate
+	cfg.BinaryAudio = true
+	cfg.FrameMs = DefaultWSAudioFrameMs
+	return cfg
+}
+
 // WSMessage represents the JSON message structure for WebSocket communication.
 type WSMessage struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 }
 
+// WSHangupPayload is sent to the peer when the server ends the session.
+type WSHangupPayload struct {
+	Reason string `json:"reason,omitempty"`
+}
+
 // WSAudioPayload represents audio data in WebSocket messages.
 type WSAudioPayload struct {
 	Data       string `json:"data"`        // Base64 encoded audio data
@@ -60,8 +93,18 @@ type websocketConnection struct {
 	handler ConnectionEventHandler
 
 	// Audio parameters
-	sampleRate int
-	channels   int
+	sampleRate  int
+	channels    int
+	binaryAudio bool
+
+	// frameBytes is the size of incoming binary audio frames; pending holds
+	// the remainder of the last binary message. Owned by the read pump.
+	frameBytes int
+	pending    []byte
+
+	// Output resampler for binary audio. Owned by the write pump.
+	resampler    *audio.Resample
+	outputInRate int
 
 	// Timing parameters
 	writeWait  time.Duration
@@ -71,6 +114,9 @@ type websocketConnection struct {
 	// Output channel for async writes
 	outChan chan *pipeline.PipelineMessage
 
+	// writeMu serializes writes to conn, which allows only one writer
+	writeMu sync.Mutex
+
 	// Lifecycle management
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -78,6 +124,10 @@ type websocketConnection struct {
 	once   sync.Once
 	mu     sync.RWMutex
 	closed bool
+
+	// hangupReason is sent to the peer on close when the server hangs up
+	hangupReason string
+	hangup       bool
 }
 
 var _ Connection = (*websocketConnection)(nil)
@@ -92,17 +142,21 @@ func NewWebSocketConnectionWithConfig(peerID string, conn *websocket.Conn, cfg W
 	ctx, cancel := context.WithCancel(context.Background())
 
 	ws := &websocketConnection{
-		peerID:     peerID,
-		conn:       conn,
-		handler:    &NoOpConnectionEventHandler{},
-		sampleRate: cfg.SampleRate,
-		channels:   cfg.Channels,
-		writeWait:  cfg.WriteWait,
-		pongWait:   cfg.PongWait,
-		pingPeriod: cfg.PingPeriod,
-		outChan:    make(chan *pipeline.PipelineMessage, 50),
-		ctx:        ctx,
-		cancel:     cancel,
+		peerID:      peerID,
+		conn:        conn,
+		handler:     &NoOpConnectionEventHandler{},
+		sampleRate:  cfg.SampleRate,
+		channels:    cfg.Channels,
+		binaryAudio: cfg.BinaryAudio,
+		writeWait:   cfg.WriteWait,
+		pongWait:    cfg.PongWait,
+		pingPeriod:  cfg.PingPeriod,
+		outChan:     make(chan *pipeline.PipelineMessage, 50),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	if cfg.FrameMs > 0 {
+		ws.frameBytes = cfg.SampleRate * cfg.FrameMs / 1000 * cfg.Channels * 2
 	}
 
 	ws.start()
@@ -148,15 +202,16 @@ func (w *websocketConnection) start() {
 }
 
 func (w *websocketConnection) readPump() {
-	defer w.wg.Done()
+	// Close waits for the pumps, so mark this one done first
 	defer w.Close()
+	defer w.wg.Done()
 
 	for {
 		select {
 		case <-w.ctx.Done():
 			return
 		default:
-			_, message, err := w.conn.ReadMessage()
+			messageType, message, err := w.conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNormalClosure) {
 					log.Printf("[websocket %s] read error: %v", w.peerID, err)
@@ -168,11 +223,56 @@ func (w *websocketConnection) readPump() {
 				return
 			}
 
+			if messageType == websocket.BinaryMessage {
+				w.handleBinaryAudio(message)
+				continue
+			}
 			w.handleMessage(message)
 		}
 	}
 }
 
+// handleBinaryAudio delivers a binary message of raw 16-bit PCM, split into
+// frameBytes-sized frames when a frame size is configured.
+func (w *websocketConnection) handleBinaryAudio(data []byte) {
+	if w.frameBytes == 0 {
+		w.deliverAudio(data)
+		return
+	}
+
+	w.pending = append(w.pending, data...)
+	off := 0
+	for len(w.pending)-off >= w.frameBytes {
+		frame := make([]byte, w.frameBytes)
+		copy(frame, w.pending[off:])
+		off += w.frameBytes
+		w.deliverAudio(frame)
+	}
+	n := copy(w.pending, w.pending[off:])
+	w.pending = w.pending[:n]
+}
+
+func (w *websocketConnection) deliverAudio(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	w.mu.RLock()
+	handler := w.handler
+	w.mu.RUnlock()
+
+	handler.OnMessage(&pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       data,
+			SampleRate: w.sampleRate,
+			Channels:   w.channels,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+			Timestamp:  time.Now(),
+		},
+	})
+}
+
 func (w *websocketConnection) handleMessage(data []byte) {
 	var wsMsg WSMessage
 	if err := json.Unmarshal(data, &wsMsg); err != nil {
@@ -281,6 +381,9 @@ func (w *websocketConnection) writePump() {
 }
 
 func (w *websocketConnection) writeMessage(msg *pipeline.PipelineMessage) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
 	w.conn.SetWriteDeadline(time.Now().Add(w.writeWait))
 
 	var wsMsg WSMessage
@@ -290,7 +393,11 @@ func (w *websocketConnection) writeMessage(msg *pipeline.PipelineMessage) {
 		if msg.AudioData == nil {
 			return
 		}
-	wsMsg.Type = "audio"
+		if w.binaryAudio {
+			w.writeBinaryAudio(msg.AudioData)
+			return
+		}
+		wsMsg.Type = "audio"
 		payload := WSAudioPayload{
 			Data:       base64.StdEncoding.EncodeToString(msg.AudioData.Data),
 			SampleRate: msg.AudioData.SampleRate,
@@ -311,14 +418,73 @@ func (w *websocketConnection) writeMessage(msg *pipeline.PipelineMessage) {
 	}
 
 	if err := w.conn.WriteJSON(wsMsg); err != nil {
-		log.Printf("[websocket %s] write error: %v", w.peerID, err)
-		w.mu.RLock()
-		handler := w.handler
-		w.mu.RUnlock()
-		handler.OnError(err)
+		w.writeFailed(err)
 	}
 }
 
+// writeBinaryAudio sends audio as a binary frame of 16-bit PCM at the
+// configured sample rate.
+func (w *websocketConnection) writeBinaryAudio(data *pipeline.AudioData) {
+	pcm, err := w.encodeBinaryAudio(data)
+	if err != nil {
+		log.Printf("[websocket %s] dropping audio: %v", w.peerID, err)
+		return
+	}
+	if len(pcm) == 0 {
+		return
+	}
+
+	if err := w.conn.WriteMessage(websocket.BinaryMessage, pcm); err != nil {
+		w.writeFailed(err)
+	}
+}
+
+// encodeBinaryAudio converts outgoing audio to 16-bit PCM at the configured
+// sample rate. It must only be called from the write pump, which owns the
+// resampler.
+func (w *websocketConnection) encodeBinaryAudio(data *pipeline.AudioData) ([]byte, error) {
+	switch data.MediaType {
+	case pipeline.AudioMediaTypeRaw, pipeline.AudioMediaTypePCM, "":
+	default:
+		return nil, fmt.Errorf("unsupported media type %s", data.MediaType)
+	}
+
+	pcm, err := audio.ConvertSampleFormat(data.Data, data.Format(), pipeline.SampleFormatS16)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRate := data.SampleRate
+	if sampleRate == 0 || sampleRate == w.sampleRate {
+		return pcm, nil
+	}
+	if data.Channels > 1 || w.channels > 1 {
+		return nil, fmt.Errorf("cannot resample %d channel(s) from %dHz to %dHz", data.Channels, sampleRate, w.sampleRate)
+	}
+
+	if sampleRate != w.outputInRate {
+		resampler, err := audio.NewResample(sampleRate, w.sampleRate,
+			astiav.ChannelLayoutMono, astiav.ChannelLayoutMono)
+		if err != nil {
+			return nil, fmt.Errorf("create output resampler for %dHz: %w", sampleRate, err)
+		}
+		if w.resampler != nil {
+			w.resampler.Free()
+		}
+		w.resampler = resampler
+		w.outputInRate = sampleRate
+	}
+	return w.resampler.Resample(pcm)
+}
+
+func (w *websocketConnection) writeFailed(err error) {
+	log.Printf("[websocket %s] write error: %v", w.peerID, err)
+	w.mu.RLock()
+	handler := w.handler
+	w.mu.RUnlock()
+	handler.OnError(err)
+}
+
 func (w *websocketConnection) pingPump() {
 	defer w.wg.Done()
 
@@ -330,8 +496,11 @@ func (w *websocketConnection) pingPump() {
 		case <-w.ctx.Done():
 			return
 		case <-ticker.C:
+			w.writeMu.Lock()
 			w.conn.SetWriteDeadline(time.Now().Add(w.writeWait))
-			if err := w.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			err := w.conn.WriteMessage(websocket.PingMessage, nil)
+			w.writeMu.Unlock()
+			if err != nil {
 				log.Printf("[websocket %s] ping error: %v", w.peerID, err)
 				return
 			}
@@ -372,14 +541,43 @@ func (w *websocketConnection) Close() error {
 		// Cancel context and wait for goroutines
 		w.cancel()
 
+		// Tell the peer the server hung up before closing
+		w.mu.RLock()
+		hangup, reason := w.hangup, w.hangupReason
+		w.mu.RUnlock()
+		w.writeMu.Lock()
+		if hangup {
+			w.conn.SetWriteDeadline(time.Now().Add(w.writeWait))
+			payload, _ := json.Marshal(WSHangupPayload{Reason: reason})
+			w.conn.WriteJSON(WSMessage{Type: "hangup", Payload: payload})
+		}
+
 		// Close the WebSocket connection with a proper close message
-		w.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		w.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason))
+		w.writeMu.Unlock()
 		w.conn.Close()
 
-		// Close output channel
-		close(w.outChan)
+		// outChan is left open: SendMessage may still race with Close, and the
+		// write pump exits on context cancellation
 
 		w.wg.Wait()
+
+		if w.resampler != nil {
+			w.resampler.Free()
+		}
 	})
 	return nil
 }
+
+// Hangup sends a "hangup" message and a normal close frame carrying reason,
+// then closes the connection.
+func (w *websocketConnection) Hangup(reason string) error {
+	log.Printf("[websocket %s] hanging up: %s", w.peerID, reason)
+
+	w.mu.Lock()
+	w.hangup = true
+	w.hangupReason = reason
+	w.mu.Unlock()
+
+	return w.Close()
+}
@@ -0,0 +1,197 @@
+package connection
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// next waits for the next message delivered to h.
+func (h *recordingHandler) next(t *testing.T) *pipeline.PipelineMessage {
+	t.Helper()
+	select {
+	case msg := <-h.messages:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for message")
+		return nil
+	}
+}
+
+// dialAudioConnection serves a WebSocket connection with cfg and returns the
+// server side connection, its handler and a connected client.
+func dialAudioConnection(t *testing.T, cfg WebSocketConfig) (Connection, *recordingHandler, *websocket.Conn) {
+	t.Helper()
+
+	handler := newRecordingHandler()
+	conns := make(chan Connection, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn := NewWebSocketConnectionWithConfig("test-peer", ws, cfg)
+		conn.RegisterEventHandler(handler)
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case conn := <-conns:
+		t.Cleanup(func() { conn.Close() })
+		return conn, handler, client
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for server connection")
+		return nil, nil, nil
+	}
+}
+
+func TestWebSocketConnection_BinaryAudioFramed(t *testing.T) {
+	_, handler, client := dialAudioConnection(t, DefaultWebSocketAudioConfig())
+
+	// 50ms of 16kHz audio in uneven chunks comes out as two 20ms frames
+	pcm := sinePCM(440, 16000, 800, 0.5)
+	require.NoError(t, client.WriteMessage(websocket.BinaryMessage, pcm[:1000]))
+	require.NoError(t, client.WriteMessage(websocket.BinaryMessage, pcm[1000:]))
+
+	for i := 0; i < 2; i++ {
+		msg := handler.next(t)
+		require.Equal(t, pipeline.MsgTypeAudio, msg.Type)
+		assert.Equal(t, 16000, msg.AudioData.SampleRate)
+		assert.Equal(t, 1, msg.AudioData.Channels)
+		assert.Equal(t, pipeline.AudioMediaTypeRaw, msg.AudioData.MediaType)
+		assert.Equal(t, pcm[i*640:(i+1)*640], msg.AudioData.Data)
+	}
+
+	// The 10ms remainder is held until the frame is complete
+	select {
+	case msg := <-handler.messages:
+		t.Fatalf("Unexpected partial frame: %d bytes", len(msg.AudioData.Data))
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// JSON text is still accepted
+	require.NoError(t, client.WriteJSON(map[string]any{"type": "text", "payload": "hello"}))
+	msg := handler.next(t)
+	require.Equal(t, pipeline.MsgTypeData, msg.Type)
+	assert.Equal(t, "hello", string(msg.TextData.Data))
+}
+
+func TestWebSocketConnection_BinaryAudioUnframed(t *testing.T) {
+	cfg := DefaultWebSocketAudioConfig()
+	cfg.FrameMs = 0
+	_, handler, client := dialAudioConnection(t, cfg)
+
+	pcm := sinePCM(440, 16000, 500, 0.5)
+	require.NoError(t, client.WriteMessage(websocket.BinaryMessage, pcm))
+
+	msg := handler.next(t)
+	assert.Equal(t, pcm, msg.AudioData.Data)
+}
+
+func TestWebSocketConnection_WritesBinaryAudioAndJSONText(t *testing.T) {
+	conn, _, client := dialAudioConnection(t, DefaultWebSocketAudioConfig())
+
+	pcm := sinePCM(440, 16000, 320, 0.5)
+	conn.SendMessage(&pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       pcm,
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	})
+	conn.SendMessage(&pipeline.PipelineMessage{
+		Type:     pipeline.MsgTypeData,
+		TextData: &pipeline.TextData{Data: []byte("transcript"), TextType: "text/final"},
+	})
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+
+	messageType, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.Equal(t, pcm, data)
+
+	messageType, data, err = client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, messageType)
+	var wsMsg WSMessage
+	require.NoError(t, json.Unmarshal(data, &wsMsg))
+	assert.Equal(t, "text", wsMsg.Type)
+	assert.JSONEq(t, `"transcript"`, string(wsMsg.Payload))
+}
+
+func TestWebSocketConnection_BinaryAudioResampled(t *testing.T) {
+	conn, _, client := dialAudioConnection(t, DefaultWebSocketAudioConfig())
+
+	// 100ms of 24kHz float audio arrives as roughly 100ms of 16kHz s16 PCM
+	conn.SendMessage(&pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:         make([]byte, 2400*4),
+			SampleRate:   24000,
+			Channels:     1,
+			MediaType:    pipeline.AudioMediaTypeRaw,
+			SampleFormat: pipeline.SampleFormatF32,
+		},
+	})
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	messageType, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.InDelta(t, 3200, len(data), 640)
+}
+
+func TestWebSocketConnection_ClientCloseNotifiesHandler(t *testing.T) {
+	_, handler, client := dialAudioConnection(t, DefaultWebSocketAudioConfig())
+
+	client.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case state := <-handler.states:
+			if state == ConnectionStateClosed {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Timeout waiting for closed state")
+		}
+	}
+}
+
+func TestWebSocketConnection_Hangup(t *testing.T) {
+	conn, handler, client := dialAudioConnection(t, DefaultWebSocketAudioConfig())
+
+	require.NoError(t, conn.Hangup("goodbye"))
+	assert.Equal(t, ConnectionStateClosed, <-handler.states, "handler runs its cleanup")
+
+	// The peer is told why, then the socket is closed normally
+	var msg WSMessage
+	require.NoError(t, client.ReadJSON(&msg))
+	assert.Equal(t, "hangup", msg.Type)
+	var payload WSHangupPayload
+	require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+	assert.Equal(t, "goodbye", payload.Reason)
+
+	_, _, err := client.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+	assert.Equal(t, "goodbye", closeErr.Text)
+}
@@ -0,0 +1,190 @@
+// Package elements provides pipeline processing elements.
+//
+// AssemblyAIRealtimeSTTElement wraps asr.AssemblyAIProvider as a realtime STT
+// element on the shared realtime STT base. With SpeakerLabels it also tracks
+// who is talking and publishes EventSpeakerTurn on every speaker change, for
+// meeting transcription and multi-party calls.
+//
+// Usage:
+//
+//	stt, err := elements.NewAssemblyAIRealtimeSTTElement(elements.AssemblyAIRealtimeSTTConfig{
+//		EnablePartialResults: true,
+//		SpeakerLabels:        true,
+//	})
+package elements
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Ensure AssemblyAIRealtimeSTTElement implements pipeline.Element
+var _ pipeline.Element = (*AssemblyAIRealtimeSTTElement)(nil)
+var _ pipeline.InputResetter = (*AssemblyAIRealtimeSTTElement)(nil)
+
+// AssemblyAIRealtimeSTTElement implements speech-to-text using AssemblyAI's Universal Streaming API.
+// It provides low-latency streaming ASR via WebSocket with optional speaker diarization,
+// suited to meeting transcription. Supports partial and final transcripts; with VAD the
+// speech end commits the turn, otherwise AssemblyAI's turn detection decides when it is final.
+// With SpeakerLabels, EventSpeakerTurn is published whenever a different speaker starts talking.
+type AssemblyAIRealtimeSTTElement struct {
+	*realtimeSTTBase
+
+	// Speaker of the latest result plus one (0 = none yet), used to publish EventSpeakerTurn
+	lastSpeaker atomic.Int32
+}
+
+// AssemblyAIRealtimeSTTConfig holds configuration for AssemblyAIRealtimeSTTElement.
+type AssemblyAIRealtimeSTTConfig struct {
+	// APIKey is the AssemblyAI API key (if empty, will use ASSEMBLYAI_API_KEY env var)
+	APIKey string
+
+	// Language code (e.g., "en", "zh", "auto" for auto-detection)
+	// Leave empty to use the pipeline LanguageContext source language,
+	// or auto-detection if none is set
+	Language string
+
+	// Model is the streaming speech model (default: AssemblyAI's English
+	// model; "universal-streaming-multilingual" for other languages)
+	Model string
+
+	// URL overrides the WebSocket endpoint (EU endpoint, proxies)
+	URL string
+
+	// EnablePartialResults enables interim results during recognition
+	EnablePartialResults bool
+
+	// VADEnabled determines if element should listen to VAD events
+	// When true, audio is sent while the user speaks and the speech end
+	// ends the turn (ForceEndpoint)
+	// When false, audio is sent continuously and AssemblyAI's turn
+	// detection decides when a turn is final
+	VADEnabled bool
+
+	// SpeakerLabels enables speaker diarization. Results carry the speaker
+	// in their metadata and EventSpeakerTurn is published when the speaker changes
+	SpeakerLabels bool
+
+	// MaxSpeakers caps the number of speakers told apart (default: 0, decided
+	// by AssemblyAI). Only used with SpeakerLabels
+	MaxSpeakers int
+
+	// SampleRate in Hz of the mono input (default: 16000; 8000 for telephone audio)
+	SampleRate int
+
+	// BitsPerSample (default: 16)
+	BitsPerSample int
+
+	// ResultTimeout is how long to wait for a final transcript after a commit
+	// before publishing EventNoResult (default: DefaultSTTResultTimeout, negative disables)
+	ResultTimeout time.Duration
+
+	// PartialIntervalMs coalesces partial results: at most one partial, the
+	// latest, is emitted per interval. Reduces downstream churn when the
+	// provider sends partials very frequently (default: 0, emit every partial)
+	PartialIntervalMs int
+
+	// EarlyCommitStableMs commits a partial as the final transcript once it
+	// has stayed unchanged for this long, instead of waiting for the
+	// provider's final. This shaves the provider's finalization latency off
+	// every turn. Later partials of the utterance are dropped; the provider's
+	// final is dropped if it matches the committed text, and otherwise sent as
+	// a "text/final" carrying STTCorrection metadata (default: 0, disabled)
+	EarlyCommitStableMs int
+
+	// EarlyCommitMinConfidence is the minimum partial confidence for an early
+	// commit. Results without a confidence score only qualify when this is 0
+	EarlyCommitMinConfidence float32
+
+	// Headers are extra headers sent on every provider request (API gateway
+	// keys, org IDs, tracing headers)
+	Headers map[string]string
+}
+
+// NewAssemblyAIRealtimeSTTElement creates a new AssemblyAI Realtime STT element.
+func NewAssemblyAIRealtimeSTTElement(config AssemblyAIRealtimeSTTConfig) (*AssemblyAIRealtimeSTTElement, error) {
+	// Get API key from config or environment
+	apiKey := config.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ASSEMBLYAI_API_KEY")
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("AssemblyAI API key is required (set APIKey or ASSEMBLYAI_API_KEY env var)")
+	}
+
+	// Create AssemblyAI provider
+	provider, err := asr.NewAssemblyAIProvider(asr.AssemblyAIConfig{
+		APIKey:        apiKey,
+		Model:         config.Model,
+		URL:           config.URL,
+		SpeakerLabels: config.SpeakerLabels,
+		MaxSpeakers:   config.MaxSpeakers,
+		Headers:       config.Headers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AssemblyAI provider: %w", err)
+	}
+
+	// Set defaults and validate
+	if config.SampleRate == 0 {
+		config.SampleRate = 16000
+	}
+	if config.BitsPerSample == 0 {
+		config.BitsPerSample = 16
+	}
+	if config.BitsPerSample != 16 {
+		return nil, fmt.Errorf("AssemblyAI streaming expects 16-bit PCM, got %d bits", config.BitsPerSample)
+	}
+
+	elem := &AssemblyAIRealtimeSTTElement{
+		realtimeSTTBase: newRealtimeSTTBase("assemblyai-realtime-stt", "AssemblyAISTT", provider, realtimeSTTConfig{
+			Language:                 config.Language,
+			Model:                    config.Model,
+			EnablePartialResults:     config.EnablePartialResults,
+			VADEnabled:               config.VADEnabled,
+			SampleRate:               config.SampleRate,
+			Channels:                 1,
+			BitsPerSample:            config.BitsPerSample,
+			ResultTimeout:            config.ResultTimeout,
+			PartialIntervalMs:        config.PartialIntervalMs,
+			EarlyCommitStableMs:      config.EarlyCommitStableMs,
+			EarlyCommitMinConfidence: config.EarlyCommitMinConfidence,
+		}),
+	}
+	elem.onResult = elem.trackSpeaker
+	// A new session labels speakers afresh
+	elem.onRestart = func() { elem.lastSpeaker.Store(0) }
+
+	return elem, nil
+}
+
+// trackSpeaker publishes EventSpeakerTurn when a result is attributed to a
+// different speaker than the previous one.
+func (e *AssemblyAIRealtimeSTTElement) trackSpeaker(result *asr.RecognitionResult, attrs pipeline.Attributes) {
+	speaker, ok := result.Metadata["speaker"].(int)
+	if !ok || e.lastSpeaker.Swap(int32(speaker)+1) == int32(speaker)+1 {
+		return
+	}
+
+	label, _ := result.Metadata["speaker_label"].(string)
+	e.logf("Speaker turn: speaker %d (%s)", speaker, label)
+
+	if e.BaseElement.Bus() != nil {
+		e.BaseElement.Bus().Publish(pipeline.Event{
+			Type:      pipeline.EventSpeakerTurn,
+			Timestamp: result.Timestamp,
+			Payload: &pipeline.SpeakerTurnPayload{
+				Source:  e.GetName(),
+				Speaker: speaker,
+				Label:   label,
+			},
+			Attributes: attrs,
+		})
+	}
+}
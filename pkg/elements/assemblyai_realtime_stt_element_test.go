@@ -0,0 +1,126 @@
+package elements
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assemblyAITurnMessage builds an AssemblyAI Turn message
+func assemblyAITurnMessage(transcript string, endOfTurn bool, speaker string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":              "Turn",
+		"end_of_turn":       endOfTurn,
+		"turn_is_formatted": endOfTurn,
+		"transcript":        transcript,
+		"speaker_label":     speaker,
+	})
+	return data
+}
+
+// startAssemblyAITestServer replies to ForceEndpoint with script
+func startAssemblyAITestServer(t *testing.T, script [][]byte) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.TextMessage && strings.Contains(string(msg), "ForceEndpoint") {
+				for _, reply := range script {
+					conn.WriteMessage(websocket.TextMessage, reply)
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestAssemblyAIRealtimeSTTElement_SpeakerTurns(t *testing.T) {
+	url := startAssemblyAITestServer(t, [][]byte{
+		assemblyAITurnMessage("Good morning", false, "A"),
+		assemblyAITurnMessage("Good morning, everyone.", true, "A"),
+		assemblyAITurnMessage("Thanks for joining.", true, "A"),
+		assemblyAITurnMessage("Happy to be here.", true, "B"),
+	})
+
+	elem, err := NewAssemblyAIRealtimeSTTElement(AssemblyAIRealtimeSTTConfig{
+		APIKey:               "test-api-key",
+		URL:                  url,
+		EnablePartialResults: true,
+		SpeakerLabels:        true,
+	})
+	require.NoError(t, err)
+
+	bus := pipeline.NewEventBus()
+	turns := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventSpeakerTurn, turns)
+	elem.SetBus(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	require.NoError(t, elem.Start(ctx))
+	defer elem.Stop()
+
+	elem.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       make([]byte, 640),
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+	require.NoError(t, elem.Commit(ctx))
+
+	want := []struct{ text, textType string }{
+		{"Good morning", "text/partial"},
+		{"Good morning, everyone.", "text/final"},
+		{"Thanks for joining.", "text/final"},
+		{"Happy to be here.", "text/final"},
+	}
+	for _, w := range want {
+		select {
+		case msg := <-elem.Out():
+			assert.Equal(t, w.text, string(msg.TextData.Data))
+			assert.Equal(t, w.textType, msg.TextData.TextType)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for %q", w.text)
+		}
+	}
+
+	// One event per change of speaker
+	for _, want := range []pipeline.SpeakerTurnPayload{
+		{Source: "assemblyai-realtime-stt", Speaker: 0, Label: "A"},
+		{Source: "assemblyai-realtime-stt", Speaker: 1, Label: "B"},
+	} {
+		select {
+		case evt := <-turns:
+			assert.Equal(t, &want, evt.Payload)
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for speaker turn %d", want.Speaker)
+		}
+	}
+	select {
+	case evt := <-turns:
+		t.Errorf("Unexpected speaker turn: %+v", evt.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
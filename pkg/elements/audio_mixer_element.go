@@ -0,0 +1,426 @@
+// Package elements provides pipeline processing elements.
+//
+// AudioMixerElement 把多路音频混合成一路输出，例如同传场景中把衰减后的原声
+// 和翻译后的 TTS 混成一条音轨。
+//
+// 主要功能:
+//   - 每路输入通过 AddInput 创建，返回的元素作为 Link 的下游；发给混音器自身的音频作为 "default" 输入
+//   - 每路输入单独缓冲，按 FrameMs 定时取一帧叠加，每路可设置增益，叠加结果超出范围时截断
+//   - 固定的输出采样率和通道数（s16 PCM）；采样率/通道数不同的输入按 Resample 自动重采样或丢弃
+//   - 抖动缓冲：一路输入缓冲到 JitterMs 才开始参与混音，发帧节奏不同的输入不会出现断续；
+//     数据不足一帧的输入只贡献已有的部分，不会拖住其他输入
+//   - 非音频消息原样透传
+//
+// 使用示例:
+//
+//	mixer := NewAudioMixerElement(DefaultAudioMixerConfig())
+//	p.AddElement(mixer)
+//	p.Link(original, mixer.AddInput("original", 0.3))
+//	p.Link(tts, mixer.AddInput("tts", 1.0))
+package elements
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure AudioMixerElement implements pipeline.Element
+var _ pipeline.Element = (*AudioMixerElement)(nil)
+
+// mixerDefaultInput 发给混音器自身的音频所属的输入
+const mixerDefaultInput = "default"
+
+// AudioMixerConfig 混音配置
+type AudioMixerConfig struct {
+	SampleRate  int  // 输出采样率，默认 48000
+	Channels    int  // 输出通道数（1 或 2），默认 1
+	FrameMs     int  // 输出帧时长，默认 20ms
+	JitterMs    int  // 每路输入开始混音前缓冲的时长，默认两帧
+	MaxBufferMs int  // 每路输入最多缓冲的时长，超出时丢弃最旧的音频，默认 5000ms
+	Resample    bool // 输入格式与输出不同时自动重采样；为 false 时丢弃这类音频
+	EmitSilence bool // 所有输入都没有音频时也输出静音帧，保持输出连续
+}
+
+// DefaultAudioMixerConfig 返回默认配置
+func DefaultAudioMixerConfig() AudioMixerConfig {
+	return AudioMixerConfig{
+		SampleRate:  48000,
+		Channels:    1,
+		FrameMs:     20,
+		MaxBufferMs: 5000,
+		Resample:    true,
+	}
+}
+
+// pcmResampler 重采样 float32 交织 PCM
+type pcmResampler interface {
+	Resample(data []byte) ([]byte, error)
+	Free()
+}
+
+// AudioMixerElement 混音元素
+type AudioMixerElement struct {
+	*pipeline.BaseElement
+
+	config        AudioMixerConfig
+	frameSamples  int // 每帧样本数（含所有通道）
+	jitterSamples int
+	maxSamples    int
+
+	mu        sync.Mutex
+	inputs    []*mixerInput
+	sessionID string          // 最近一条输入音频的会话 ID
+	runCtx    context.Context // 运行中时非 nil，新增的输入立即开始读取
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// mixerInput 混音器的一路输入，作为 Link 的下游接收音频
+type mixerInput struct {
+	*pipeline.BaseElement
+
+	name string
+
+	// 以下字段由 mixer.mu 保护
+	gain      float64
+	buf       []float32 // 待混音的样本，已转换为输出格式
+	playing   bool      // 是否已过抖动缓冲，正在参与混音
+	lastWrite time.Time
+	overflow  bool // 已提示过缓冲溢出
+
+	// 以下字段只由读取该输入的 goroutine 访问
+	resampler    pcmResampler
+	resampleRate int
+	resampleCh   int
+	rejected     bool // 已提示过格式不支持
+}
+
+// NewAudioMixerElement 创建混音元素
+func NewAudioMixerElement(config AudioMixerConfig) *AudioMixerElement {
+	defaults := DefaultAudioMixerConfig()
+	if config.SampleRate <= 0 {
+		config.SampleRate = defaults.SampleRate
+	}
+	if config.Channels != 2 {
+		config.Channels = 1
+	}
+	if config.FrameMs <= 0 {
+		config.FrameMs = defaults.FrameMs
+	}
+	if config.JitterMs <= 0 {
+		config.JitterMs = 2 * config.FrameMs
+	}
+	if config.MaxBufferMs <= 0 {
+		config.MaxBufferMs = defaults.MaxBufferMs
+	}
+	if config.MaxBufferMs < config.JitterMs+config.FrameMs {
+		config.MaxBufferMs = config.JitterMs + config.FrameMs
+	}
+
+	samplesPerMs := config.SampleRate * config.Channels / 1000
+	e := &AudioMixerElement{
+		BaseElement:   pipeline.NewBaseElement("audio-mixer-element", 100),
+		config:        config,
+		frameSamples:  samplesPerMs * config.FrameMs,
+		jitterSamples: samplesPerMs * config.JitterMs,
+		maxSamples:    samplesPerMs * config.MaxBufferMs,
+	}
+	e.inputs = append(e.inputs, e.newInput(mixerDefaultInput, 1.0))
+	return e
+}
+
+func (e *AudioMixerElement) newInput(name string, gain float64) *mixerInput {
+	return &mixerInput{
+		BaseElement: pipeline.NewBaseElement("audio-mixer-input-"+name, 100),
+		name:        name,
+		gain:        gain,
+	}
+}
+
+// AddInput 添加一路输入并返回其元素，用作 Link 的下游
+// 同名输入已存在时更新增益并返回已有的输入
+func (e *AudioMixerElement) AddInput(name string, gain float64) pipeline.Element {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, in := range e.inputs {
+		if in.name == name {
+			in.gain = gain
+			return in
+		}
+	}
+
+	in := e.newInput(name, gain)
+	e.inputs = append(e.inputs, in)
+	if e.runCtx != nil {
+		e.startInput(e.runCtx, in)
+	}
+	return in
+}
+
+// SetGain 设置一路输入的增益，对下一帧生效
+func (e *AudioMixerElement) SetGain(name string, gain float64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, in := range e.inputs {
+		if in.name == name {
+			in.gain = gain
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown mixer input: %q", name)
+}
+
+// OutputFormat 返回混音输出的采样率和通道数
+func (e *AudioMixerElement) OutputFormat() pipeline.AudioFormat {
+	return pipeline.AudioFormat{SampleRate: e.config.SampleRate, Channels: e.config.Channels}
+}
+
+func (e *AudioMixerElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.mu.Lock()
+	e.runCtx = ctx
+	defaultInput := e.inputs[0]
+	for _, in := range e.inputs[1:] {
+		e.startInput(ctx, in)
+	}
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(e.config.FrameMs) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-e.BaseElement.InChan:
+				if !ok {
+					return
+				}
+				if !e.receive(ctx, defaultInput, msg) {
+					return
+				}
+			case now := <-ticker.C:
+				if !e.emit(ctx, now) {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// startInput 开始读取一路输入，调用方须持有 e.mu
+func (e *AudioMixerElement) startInput(ctx context.Context, in *mixerInput) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-in.InChan:
+				if !ok {
+					return
+				}
+				if !e.receive(ctx, in, msg) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (e *AudioMixerElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+
+	e.mu.Lock()
+	e.runCtx = nil
+	for _, in := range e.inputs {
+		if in.resampler != nil {
+			in.resampler.Free()
+			in.resampler = nil
+		}
+		in.buf = nil
+		in.playing = false
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// receive 缓冲一路输入的音频，非音频消息透传；ctx 取消时返回 false
+func (e *AudioMixerElement) receive(ctx context.Context, in *mixerInput, msg *pipeline.PipelineMessage) bool {
+	if msg.Type != pipeline.MsgTypeAudio {
+		select {
+		case e.BaseElement.OutChan <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	samples := e.convert(in, msg.AudioData)
+	if len(samples) == 0 {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	in.buf = append(in.buf, samples...)
+	if excess := len(in.buf) - e.maxSamples; excess > 0 {
+		if !in.overflow {
+			log.Printf("[AudioMixer] Input %q buffered more than %dms, dropping oldest audio", in.name, e.config.MaxBufferMs)
+			in.overflow = true
+		}
+		in.buf = in.buf[excess:]
+	}
+	in.lastWrite = time.Now()
+	e.sessionID = msg.SessionID
+	return true
+}
+
+// convert 把输入音频转换为输出采样率和通道数的 float32 样本，不支持时返回 nil
+func (e *AudioMixerElement) convert(in *mixerInput, data *pipeline.AudioData) []float32 {
+	if data == nil || len(data.Data) == 0 {
+		return nil
+	}
+	if !isPCMMediaType(data.MediaType) {
+		if !in.rejected {
+			log.Printf("[AudioMixer] Input %q: unsupported media type %s, dropping audio", in.name, data.MediaType)
+			in.rejected = true
+		}
+		return nil
+	}
+
+	samples := audio.BytesToFloat32(data.Data, data.Format())
+	channels := max(data.Channels, 1)
+	if data.SampleRate == e.config.SampleRate && channels == e.config.Channels {
+		return samples
+	}
+
+	if !e.config.Resample {
+		if !in.rejected {
+			log.Printf("[AudioMixer] Input %q: %dHz/%dch does not match output %dHz/%dch, dropping audio",
+				in.name, data.SampleRate, channels, e.config.SampleRate, e.config.Channels)
+			in.rejected = true
+		}
+		return nil
+	}
+
+	// 输入格式变化时重建重采样器
+	if in.resampler == nil || in.resampleRate != data.SampleRate || in.resampleCh != channels {
+		if in.resampler != nil {
+			in.resampler.Free()
+			in.resampler = nil
+		}
+		resampler, err := newF32Resampler(data.SampleRate, e.config.SampleRate, channels, e.config.Channels)
+		if err != nil {
+			if !in.rejected {
+				log.Printf("[AudioMixer] Input %q: failed to create resampler: %v", in.name, err)
+				in.rejected = true
+			}
+			return nil
+		}
+		in.resampler = resampler
+		in.resampleRate = data.SampleRate
+		in.resampleCh = channels
+	}
+
+	out, err := in.resampler.Resample(audio.Float32ToBytes(samples, pipeline.SampleFormatF32))
+	if err != nil {
+		log.Printf("[AudioMixer] Input %q: resample error: %v", in.name, err)
+		return nil
+	}
+	return audio.BytesToFloat32(out, pipeline.SampleFormatF32)
+}
+
+// emit 混合一帧并输出；没有任何输入参与且未启用 EmitSilence 时不输出。ctx 取消时返回 false
+func (e *AudioMixerElement) emit(ctx context.Context, now time.Time) bool {
+	mix, sessionID := e.mixFrame(now)
+	if mix == nil {
+		return true
+	}
+
+	msg := &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		SessionID: sessionID,
+		Timestamp: now,
+		AudioData: &pipeline.AudioData{
+			// 超出 [-1, 1] 的样本在编码时截断，不会溢出回绕
+			Data:         audio.Float32ToBytes(mix, pipeline.SampleFormatS16),
+			SampleRate:   e.config.SampleRate,
+			Channels:     e.config.Channels,
+			MediaType:    pipeline.AudioMediaTypeRaw,
+			SampleFormat: pipeline.SampleFormatS16,
+			Timestamp:    now,
+		},
+	}
+
+	select {
+	case e.BaseElement.OutChan <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// mixFrame 从每路就绪的输入取最多一帧样本，按增益叠加
+func (e *AudioMixerElement) mixFrame(now time.Time) ([]float32, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mix := make([]float32, e.frameSamples)
+	active := false
+	for _, in := range e.inputs {
+		if !e.ready(in, now) {
+			continue
+		}
+		n := min(len(in.buf), len(mix))
+		gain := float32(in.gain)
+		for i := 0; i < n; i++ {
+			mix[i] += in.buf[i] * gain
+		}
+		in.buf = in.buf[n:]
+		active = true
+	}
+
+	if !active && !e.config.EmitSilence {
+		return nil, ""
+	}
+	return mix, e.sessionID
+}
+
+// ready 报告一路输入是否参与本帧混音，调用方须持有 e.mu
+// 输入先缓冲到 JitterMs 再开始播放，缓冲耗尽后重新缓冲；
+// 不足 JitterMs 但已有一段时间没有新音频时（例如一句话的结尾）直接播放
+func (e *AudioMixerElement) ready(in *mixerInput, now time.Time) bool {
+	if len(in.buf) == 0 {
+		in.playing = false
+		return false
+	}
+	if !in.playing {
+		waited := now.Sub(in.lastWrite) >= time.Duration(e.config.JitterMs)*time.Millisecond
+		in.playing = len(in.buf) >= e.jitterSamples || waited
+	}
+	return in.playing
+}
@@ -0,0 +1,168 @@
+package elements
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mixerAudioMessage wraps 16-bit PCM into an audio message
+func mixerAudioMessage(data []byte, sampleRate int) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		SessionID: "test-session",
+		AudioData: &pipeline.AudioData{
+			Data:       data,
+			SampleRate: sampleRate,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+}
+
+// pushFrames sends data to in as 20ms frames
+func pushFrames(t *testing.T, in pipeline.Element, data []byte, sampleRate int) {
+	t.Helper()
+	frameBytes := sampleRate / 50 * 2
+	for off := 0; off+frameBytes <= len(data); off += frameBytes {
+		select {
+		case in.In() <- mixerAudioMessage(data[off:off+frameBytes], sampleRate):
+		case <-time.After(time.Second):
+			t.Fatal("Timeout pushing audio")
+		}
+	}
+}
+
+// collectMixed reads output frames until at least n samples were received
+func collectMixed(t *testing.T, mixer *AudioMixerElement, n int) []float32 {
+	t.Helper()
+	var samples []float32
+	for len(samples) < n {
+		select {
+		case msg := <-mixer.Out():
+			require.Equal(t, pipeline.MsgTypeAudio, msg.Type)
+			assert.Equal(t, 16000, msg.AudioData.SampleRate)
+			assert.Equal(t, 1, msg.AudioData.Channels)
+			samples = append(samples, audio.BytesToFloat32(msg.AudioData.Data, msg.AudioData.Format())...)
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for mixed audio (%d of %d samples)", len(samples), n)
+		}
+	}
+	return samples
+}
+
+// toneMagnitude returns the normalized magnitude of frequency in samples (Goertzel)
+func toneMagnitude(samples []float32, frequency float64, sampleRate int) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*frequency/float64(sampleRate))
+	var s1, s2 float64
+	for _, x := range samples {
+		s0 := float64(x) + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	return 2 * math.Sqrt(math.Max(power, 0)) / float64(len(samples))
+}
+
+func newTestMixer(resample bool) *AudioMixerElement {
+	return NewAudioMixerElement(AudioMixerConfig{
+		SampleRate: 16000,
+		Channels:   1,
+		FrameMs:    20,
+		Resample:   resample,
+	})
+}
+
+func TestAudioMixerElement_MixesTones(t *testing.T) {
+	mixer := newTestMixer(false)
+	low := mixer.AddInput("low", 1.0)
+	high := mixer.AddInput("high", 0.5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, mixer.Start(ctx))
+	defer mixer.Stop()
+
+	// 200ms per source; the high tone arrives in a different cadence (two frames at once)
+	pushFrames(t, low, generateTone(3200, 440, 16000), 16000)
+	highTone := generateTone(3200, 1000, 16000)
+	for off := 0; off < len(highTone); off += 1280 {
+		high.In() <- mixerAudioMessage(highTone[off:off+1280], 16000)
+	}
+
+	samples := collectMixed(t, mixer, 3200)[:3200]
+
+	amplitude := 10000.0 / 32768
+	assert.InDelta(t, amplitude, toneMagnitude(samples, 440, 16000), amplitude*0.1)
+	assert.InDelta(t, amplitude*0.5, toneMagnitude(samples, 1000, 16000), amplitude*0.1)
+	assert.Less(t, toneMagnitude(samples, 700, 16000), amplitude*0.05)
+}
+
+func TestAudioMixerElement_Clipping(t *testing.T) {
+	mixer := newTestMixer(false)
+	a := mixer.AddInput("a", 1.0)
+	b := mixer.AddInput("b", 1.0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, mixer.Start(ctx))
+	defer mixer.Stop()
+
+	// Two loud constant signals sum beyond full scale
+	loud := make([]byte, 640*2)
+	for i := 0; i < len(loud); i += 2 {
+		binary.LittleEndian.PutUint16(loud[i:], uint16(int16(26000)))
+	}
+	pushFrames(t, a, loud, 16000)
+	pushFrames(t, b, loud, 16000)
+
+	for _, s := range collectMixed(t, mixer, 640)[:640] {
+		assert.InDelta(t, 1.0, s, 0.001, "sum should clip at full scale instead of wrapping")
+	}
+}
+
+func TestAudioMixerElement_RejectsRateMismatch(t *testing.T) {
+	mixer := newTestMixer(false)
+	in := mixer.AddInput("narrowband", 1.0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, mixer.Start(ctx))
+	defer mixer.Stop()
+
+	pushFrames(t, in, generateTone(1600, 440, 8000), 8000)
+
+	select {
+	case msg := <-mixer.Out():
+		t.Fatalf("Unexpected output for mismatched input: %v", msg)
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	// Non-audio messages pass through
+	mixer.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeData, TextData: &pipeline.TextData{Data: []byte("hi")}}
+	select {
+	case msg := <-mixer.Out():
+		assert.Equal(t, pipeline.MsgTypeData, msg.Type)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for passthrough message")
+	}
+}
+
+func TestAudioMixerElement_SetGain(t *testing.T) {
+	mixer := newTestMixer(false)
+	mixer.AddInput("tts", 1.0)
+
+	assert.NoError(t, mixer.SetGain("tts", 0.3))
+	assert.NoError(t, mixer.SetGain(mixerDefaultInput, 0.5))
+	assert.Error(t, mixer.SetGain("missing", 1.0))
+
+	// Adding an existing input returns it with the new gain
+	assert.Same(t, mixer.AddInput("tts", 0.8), mixer.AddInput("tts", 0.8))
+	assert.Equal(t, 0.8, mixer.inputs[1].gain)
+}
@@ -11,11 +11,28 @@ import (
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 )
 
+// maxPacerLag 输出协程落后超过该时长时放弃追赶
+const maxPacerLag = 100 * time.Millisecond
+
 // AudioPacerSinkConfig 配置
 type AudioPacerSinkConfig struct {
 	SampleRate int // 采样率
 	Channels   int // 通道数
 	FadeOutMs  int // 打断时淡出时长（毫秒），0 表示不淡出
+	FadeInMs   int // 每段回复开始播放时的淡入时长（毫秒），0 表示不淡入
+	EndFadeMs  int // 回复播完（缓冲读空）时末尾的淡出时长（毫秒），0 表示不淡出
+
+	// InterruptGraceMs 打断时如果当前短语（到下一条 EndOfSentence 音频为止，UniversalTTSElement
+	// 在每句结尾设置）在该时长内就能播完，先播完再停，避免在词中间截断；0 表示立即停止
+	// 上游没有标记句尾时没有短语边界，打断总是立即停止
+	InterruptGraceMs int
+
+	// TargetBufferMs 播放缓冲目标深度（毫秒），开始播放和欠载后先积累到该深度
+	// 调大可减少上游抖动导致的卡顿，代价是增加首帧延迟；0 表示不预缓冲
+	TargetBufferMs int
+
+	// Clock 输出节奏使用的时钟（默认 pipeline.SystemClock），测试时可注入 pipeline.ManualClock
+	Clock pipeline.Clock
 }
 
 // DefaultAudioPacerSinkConfig 返回默认配置
@@ -24,6 +41,8 @@ func DefaultAudioPacerSinkConfig() AudioPacerSinkConfig {
 		SampleRate: audio.DefaultSampleRate,
 		Channels:   audio.Channels,
 		FadeOutMs:  50, // 默认 50ms 淡出，避免爆音
+		FadeInMs:   10,
+		EndFadeMs:  10,
 	}
 }
 
@@ -32,8 +51,14 @@ func DefaultAudioPacerSinkConfig() AudioPacerSinkConfig {
 //
 // 主要功能:
 //   - 音频缓冲和 20ms 帧输出
-//   - 打断时快速清空缓冲 (支持淡出)
+//   - 打断时快速清空缓冲 (支持淡出)，当前短语即将播完时可先播完
+//   - 每段回复开始时淡入、播完时淡出，避免起止处的爆音
 //   - 暂停/恢复支持 (用于混合模式打断)
+//   - 双讲时按 EventAudioDuck/EventAudioUnduck 压低和恢复音量
+//   - 发布 EventPlaybackStart/End，供打断管理器判断 AI 是否在说话
+//   - 可配置的播放缓冲目标深度，BufferedMs/Underruns 用于观察缓冲占用和欠载次数
+//   - 自适应抖动缓冲（SetJitterBufferMs），上游突发到达的音频也按稳定的 20ms 节奏播出
+//   - 输出节奏使用可注入的 Clock，测试中可用 pipeline.ManualClock 推进时间
 type AudioPacerSinkElement struct {
 	*pipeline.BaseElement
 
@@ -42,9 +67,11 @@ type AudioPacerSinkElement struct {
 
 	sampleRate int
 	channels   int
+	clock      pipeline.Clock
 
 	// 打断配置
 	fadeOutMs int // 淡出时长（毫秒），0 表示不淡出
+	graceMs   int // 当前短语在该时长内播完时先播完（毫秒），0 表示不等待
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -63,10 +90,16 @@ func NewAudioPacerSinkElementWithConfig(cfg AudioPacerSinkConfig) *AudioPacerSin
 	if cfg.Channels <= 0 {
 		cfg.Channels = audio.Channels
 	}
+	if cfg.Clock == nil {
+		cfg.Clock = pipeline.SystemClock
+	}
 
 	pacer, err := audio.NewAudioPacerWithConfig(audio.AudioPacerConfig{
-		SampleRate: cfg.SampleRate,
-		Channels:   cfg.Channels,
+		SampleRate:     cfg.SampleRate,
+		Channels:       cfg.Channels,
+		TargetBufferMs: cfg.TargetBufferMs,
+		FadeInMs:       cfg.FadeInMs,
+		EndFadeMs:      cfg.EndFadeMs,
 	})
 	if err != nil {
 		log.Fatal("create audio buffer error: ", err)
@@ -86,7 +119,9 @@ func NewAudioPacerSinkElementWithConfig(cfg AudioPacerSinkConfig) *AudioPacerSin
 		dumper:      dumper,
 		sampleRate:  cfg.SampleRate,
 		channels:    cfg.Channels,
+		clock:       cfg.Clock,
 		fadeOutMs:   cfg.FadeOutMs,
+		graceMs:     cfg.InterruptGraceMs,
 	}
 }
 
@@ -139,6 +174,9 @@ func (e *AudioPacerSinkElement) run(ctx context.Context) {
 				}
 
 				if len(msg.AudioData.Data) == 0 {
+					if msg.AudioData.EndOfSentence {
+						e.pacer.MarkPhraseEnd()
+					}
 					continue
 				}
 
@@ -149,10 +187,13 @@ func (e *AudioPacerSinkElement) run(ctx context.Context) {
 					}
 				}
 
-				// 写入音频节奏控制器
-				if err := e.pacer.Write(msg.AudioData.Data); err != nil {
+				// 写入音频节奏控制器，到达时间用于估计抖动
+				if err := e.pacer.WriteAt(msg.AudioData.Data, e.clock.Now()); err != nil {
 					log.Printf("Failed to write to audio pacer: %v", err)
 				}
+				if msg.AudioData.EndOfSentence {
+					e.pacer.MarkPhraseEnd()
+				}
 			}
 		}
 	}()
@@ -161,21 +202,33 @@ func (e *AudioPacerSinkElement) run(ctx context.Context) {
 	go func() {
 		defer e.wg.Done()
 
-		ticker := time.NewTicker(5 * time.Millisecond)
+		ticker := e.clock.NewTicker(5 * time.Millisecond)
 		defer ticker.Stop()
 
-		lastSendTime := time.Now()
+		lastSendTime := e.clock.Now()
+		playing := false
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
+			case <-ticker.C():
 				// 从音频节奏控制器读取一帧数据
-				if time.Since(lastSendTime) >= 20*time.Millisecond {
+				if e.clock.Now().Sub(lastSendTime) >= 20*time.Millisecond {
 
 					lastSendTime = lastSendTime.Add(20 * time.Millisecond)
 
+					// 协程被调度延迟时不要连发追赶，否则下游会先收到一串突发帧再断流
+					if e.clock.Now().Sub(lastSendTime) > maxPacerLag {
+						lastSendTime = e.clock.Now()
+					}
+
+					// 缓冲区有数据即视为正在播放（暂停时同样保持播放状态）
+					if active := e.pacer.Available() > 0; active != playing {
+						playing = active
+						e.publishPlayback(active)
+					}
+
 					audioData := e.pacer.ReadFrame()
 
 					msg := &pipeline.PipelineMessage{
@@ -185,13 +238,14 @@ func (e *AudioPacerSinkElement) run(ctx context.Context) {
 							SampleRate: e.sampleRate,
 							Channels:   e.channels,
 							MediaType:  pipeline.AudioMediaTypeRaw,
-							Timestamp:  time.Now(),
+							Timestamp:  e.clock.Now(),
 						},
 					}
 
 					select {
 					case e.BaseElement.OutChan <- msg:
 					default:
+						e.RecordDropped()
 						log.Println("audio pacer sink element out chan is full")
 					}
 
@@ -201,24 +255,93 @@ func (e *AudioPacerSinkElement) run(ctx context.Context) {
 	}()
 }
 
-// listenEvent 监听打断、暂停、恢复事件
+// Drain 等待已收到的音频全部播放完（暂停期间会一直等到 ctx 到期），见 pipeline.Pipeline.StopGraceful
+func (e *AudioPacerSinkElement) Drain(ctx context.Context) error {
+	return waitUntil(ctx, func() bool {
+		return len(e.BaseElement.InChan) == 0 && (e.pacer == nil || e.pacer.Available() == 0)
+	})
+}
+
+// Flush 丢弃尚未写入播放缓冲的排队音频，打断时由 Pipeline 调用。
+// 播放缓冲本身由打断事件带淡出清空（见 handleInterrupt），这里不直接清空以免产生爆音
+func (e *AudioPacerSinkElement) Flush() {
+	if n := e.DropQueued(); n > 0 {
+		log.Printf("[AudioPacerSink] Flushed %d queued messages", n)
+	}
+}
+
+// SetJitterBufferMs 启用自适应抖动缓冲，maxMs 为 0 时关闭，可在运行中调用
+// 每段语音开始时先缓冲 targetMs 再按 20ms 节奏输出，缓冲深度随测得的到达抖动在 [minMs, maxMs] 内增减；
+// 播放中途欠载时插入短暂的舒适噪声而不是直接静音。详见 audio.AudioPacer.SetJitterBuffer
+func (e *AudioPacerSinkElement) SetJitterBufferMs(minMs, targetMs, maxMs int) {
+	if e.pacer != nil {
+		e.pacer.SetJitterBuffer(minMs, targetMs, maxMs)
+	}
+}
+
+// TargetBufferMs 返回当前的目标缓冲深度（毫秒），启用抖动缓冲时随到达抖动变化
+func (e *AudioPacerSinkElement) TargetBufferMs() int {
+	if e.pacer == nil {
+		return 0
+	}
+	return e.pacer.TargetBufferMs()
+}
+
+// BufferedMs 返回当前播放缓冲的深度（毫秒）
+func (e *AudioPacerSinkElement) BufferedMs() int {
+	if e.pacer == nil {
+		return 0
+	}
+	return e.pacer.BufferedMs()
+}
+
+// Underruns 返回播放中途缓冲耗尽的次数
+func (e *AudioPacerSinkElement) Underruns() int64 {
+	if e.pacer == nil {
+		return 0
+	}
+	return e.pacer.Underruns()
+}
+
+// publishPlayback 发布播放开始/结束事件
+func (e *AudioPacerSinkElement) publishPlayback(active bool) {
+	if e.Bus() == nil {
+		return
+	}
+	eventType := pipeline.EventPlaybackEnd
+	if active {
+		eventType = pipeline.EventPlaybackStart
+	}
+	e.Bus().Publish(pipeline.Event{
+		Type:      eventType,
+		Timestamp: e.clock.Now(),
+	})
+}
+
+// listenEvent 监听打断、暂停、恢复、压低音量事件
 func (e *AudioPacerSinkElement) listenEvent(ctx context.Context) {
 	defer e.wg.Done()
 
 	interruptCh := make(chan pipeline.Event, 5)
 	pauseCh := make(chan pipeline.Event, 5)
 	resumeCh := make(chan pipeline.Event, 5)
+	duckCh := make(chan pipeline.Event, 5)
+	unduckCh := make(chan pipeline.Event, 5)
 
 	// 订阅事件
 	e.Bus().Subscribe(pipeline.EventInterrupted, interruptCh)
 	e.Bus().Subscribe(pipeline.EventAudioPause, pauseCh)
 	e.Bus().Subscribe(pipeline.EventAudioResume, resumeCh)
+	e.Bus().Subscribe(pipeline.EventAudioDuck, duckCh)
+	e.Bus().Subscribe(pipeline.EventAudioUnduck, unduckCh)
 
 	// 退出时取消订阅
 	defer func() {
 		e.Bus().Unsubscribe(pipeline.EventInterrupted, interruptCh)
 		e.Bus().Unsubscribe(pipeline.EventAudioPause, pauseCh)
 		e.Bus().Unsubscribe(pipeline.EventAudioResume, resumeCh)
+		e.Bus().Unsubscribe(pipeline.EventAudioDuck, duckCh)
+		e.Bus().Unsubscribe(pipeline.EventAudioUnduck, unduckCh)
 	}()
 
 	for {
@@ -234,6 +357,13 @@ func (e *AudioPacerSinkElement) listenEvent(ctx context.Context) {
 
 		case event := <-resumeCh:
 			e.handleResume(event)
+
+		case event := <-duckCh:
+			e.handleDuck(event)
+
+		case <-unduckCh:
+			log.Printf("[AudioPacerSink] Restoring volume")
+			e.pacer.SetGain(1)
 		}
 	}
 }
@@ -242,8 +372,12 @@ func (e *AudioPacerSinkElement) listenEvent(ctx context.Context) {
 func (e *AudioPacerSinkElement) handleInterrupt(event pipeline.Event) {
 	log.Printf("[AudioPacerSink] Received interrupt event, clearing buffer with %dms fade-out", e.fadeOutMs)
 
-	// 清空音频缓冲区（带淡出效果）
-	if e.fadeOutMs > 0 {
+	// 当前短语即将播完时先播完，否则清空音频缓冲区（带淡出效果）
+	if e.graceMs > 0 {
+		if e.pacer.ClearAfterPhrase(e.graceMs, e.fadeOutMs) {
+			log.Printf("[AudioPacerSink] Finishing current phrase before stopping")
+		}
+	} else if e.fadeOutMs > 0 {
 		e.pacer.ClearWithFadeOut(e.fadeOutMs)
 	} else {
 		e.pacer.Clear()
@@ -274,3 +408,13 @@ func (e *AudioPacerSinkElement) handleResume(event pipeline.Event) {
 	log.Printf("[AudioPacerSink] Received resume event")
 	e.pacer.Resume()
 }
+
+// handleDuck 处理压低音量事件（双讲时用户在说话）
+func (e *AudioPacerSinkElement) handleDuck(event pipeline.Event) {
+	payload, ok := event.Payload.(*pipeline.AudioDuckPayload)
+	if !ok {
+		return
+	}
+	log.Printf("[AudioPacerSink] Ducking volume to gain %.2f", payload.Gain)
+	e.pacer.SetGain(payload.Gain)
+}
@@ -0,0 +1,125 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startPacerSink 启动使用 ManualClock 的 AudioPacerSinkElement，返回时输出协程已创建 ticker
+func startPacerSink(t *testing.T, clock *pipeline.ManualClock) *AudioPacerSinkElement {
+	t.Helper()
+
+	elem := NewAudioPacerSinkElementWithConfig(AudioPacerSinkConfig{
+		SampleRate: 16000,
+		Channels:   1,
+		Clock:      clock,
+	})
+	elem.SetBus(pipeline.NewEventBus())
+	require.NoError(t, elem.Start(context.Background()))
+	t.Cleanup(func() { elem.Stop() })
+
+	clock.BlockUntil(1)
+	return elem
+}
+
+func receiveFrame(t *testing.T, elem *AudioPacerSinkElement) *pipeline.AudioData {
+	t.Helper()
+	select {
+	case msg := <-elem.Out():
+		return msg.AudioData
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for audio frame")
+		return nil
+	}
+}
+
+func TestAudioPacerSinkElement_Pacing(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := pipeline.NewManualClock(start)
+	elem := startPacerSink(t, clock)
+
+	// 每 20ms 输出一帧，没有数据时为静音
+	for i := 1; i <= 5; i++ {
+		clock.Advance(20 * time.Millisecond)
+		frame := receiveFrame(t, elem)
+		assert.Equal(t, start.Add(time.Duration(i)*20*time.Millisecond), frame.Timestamp)
+		assert.Len(t, frame.Data, 640)
+	}
+
+	// 写入一帧音频，下一个 20ms 输出该帧
+	data := make([]byte, 640)
+	for i := range data {
+		data[i] = 0x11
+	}
+	elem.In() <- &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{Data: data, SampleRate: 16000, Channels: 1, MediaType: pipeline.AudioMediaTypeRaw},
+	}
+	require.Eventually(t, func() bool { return elem.BufferedMs() == 20 }, time.Second, time.Millisecond)
+
+	clock.Advance(20 * time.Millisecond)
+	assert.Equal(t, data, receiveFrame(t, elem).Data)
+	assert.Equal(t, 0, elem.BufferedMs())
+}
+
+func TestAudioPacerSinkElement_LagDoesNotBurst(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := pipeline.NewManualClock(start)
+	elem := startPacerSink(t, clock)
+
+	// 输出协程落后 200ms：只输出一帧，并从当前时间重新计时
+	clock.Advance(200 * time.Millisecond)
+	assert.Equal(t, start.Add(200*time.Millisecond), receiveFrame(t, elem).Timestamp)
+
+	clock.Advance(5 * time.Millisecond)
+	clock.Advance(15 * time.Millisecond)
+	assert.Equal(t, start.Add(220*time.Millisecond), receiveFrame(t, elem).Timestamp)
+}
+
+func TestAudioPacerSinkElement_JitterBuffer(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := pipeline.NewManualClock(start)
+	elem := startPacerSink(t, clock)
+	elem.SetJitterBufferMs(40, 60, 200)
+
+	data := make([]byte, 640)
+	for i := range data {
+		data[i] = 0x11
+	}
+
+	// 每批 5 帧突发到达，第二批晚到 30ms
+	bursts := map[int]bool{0: true, 130: true, 200: true}
+	var played []time.Time
+	for now := 0; now < 400; now += 10 {
+		if bursts[now] {
+			buffered := elem.BufferedMs()
+			for i := 0; i < 5; i++ {
+				elem.In() <- &pipeline.PipelineMessage{
+					Type:      pipeline.MsgTypeAudio,
+					AudioData: &pipeline.AudioData{Data: data, SampleRate: 16000, Channels: 1, MediaType: pipeline.AudioMediaTypeRaw},
+				}
+			}
+			require.Eventually(t, func() bool { return elem.BufferedMs() == buffered+100 }, time.Second, time.Millisecond)
+		}
+
+		clock.Advance(10 * time.Millisecond)
+		if (now+10)%20 == 0 {
+			frame := receiveFrame(t, elem)
+			if frame.Data[0] == 0x11 {
+				played = append(played, frame.Timestamp)
+			}
+		}
+	}
+
+	// 缓冲 60ms 后开始播放，之后每 20ms 输出一帧，批次之间没有断口
+	require.Len(t, played, 15)
+	for i, ts := range played {
+		assert.Equal(t, start.Add(time.Duration(60+20*i)*time.Millisecond), ts, "frame %d", i)
+	}
+	assert.Equal(t, int64(0), elem.Underruns())
+}
@@ -0,0 +1,69 @@
+// Package elements provides pipeline processing elements.
+//
+// audioRechunker implements OutputChunkMs for the realtime model elements
+// (OpenAI Realtime, Gemini Live). Their audio arrives in bursts of uneven
+// size; cutting it into fixed frames (e.g. 20ms) before it leaves the element
+// keeps the output pacer and jitter buffers from being flooded.
+package elements
+
+// audioRechunker splits provider audio, which arrives in chunks of whatever
+// size the provider sends (often hundreds of milliseconds at once), into
+// fixed-duration frames so that the downstream pacer and WebRTC track get an
+// even flow. Audio that does not fill a whole frame is held until the next
+// Write or returned by Flush.
+//
+// A nil audioRechunker passes audio through unchanged. It is not safe for
+// concurrent use; the element's receive loop owns it.
+type audioRechunker struct {
+	frameBytes int
+	pending    []byte
+}
+
+// newAudioRechunker returns a rechunker producing frameMs frames of 16-bit
+// PCM, or nil (pass-through) if frameMs <= 0.
+func newAudioRechunker(frameMs, sampleRate, channels int) *audioRechunker {
+	if frameMs <= 0 {
+		return nil
+	}
+	return &audioRechunker{frameBytes: sampleRate * frameMs / 1000 * channels * 2}
+}
+
+// Write appends data and returns the complete frames now available.
+func (r *audioRechunker) Write(data []byte) [][]byte {
+	if r == nil {
+		if len(data) == 0 {
+			return nil
+		}
+		return [][]byte{data}
+	}
+
+	r.pending = append(r.pending, data...)
+	var frames [][]byte
+	for len(r.pending) >= r.frameBytes {
+		frame := make([]byte, r.frameBytes)
+		copy(frame, r.pending)
+		frames = append(frames, frame)
+		r.pending = r.pending[r.frameBytes:]
+	}
+	// Move the remainder to the front so pending does not grow without bound
+	r.pending = append(r.pending[:0:0], r.pending...)
+	return frames
+}
+
+// Flush returns the held audio that does not fill a frame, at the end of a
+// response, and clears it.
+func (r *audioRechunker) Flush() []byte {
+	if r == nil || len(r.pending) == 0 {
+		return nil
+	}
+	rest := r.pending
+	r.pending = nil
+	return rest
+}
+
+// Reset drops the held audio, e.g. when the response is interrupted.
+func (r *audioRechunker) Reset() {
+	if r != nil {
+		r.pending = nil
+	}
+}
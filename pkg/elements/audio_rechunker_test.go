@@ -0,0 +1,64 @@
+package elements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudioRechunker_EvenFrames(t *testing.T) {
+	// 20ms at 24kHz mono PCM16 is 960 bytes
+	r := newAudioRechunker(20, 24000, 1)
+
+	var frames [][]byte
+	for _, size := range []int{24000, 100, 5000, 3} {
+		chunk := make([]byte, size)
+		for i := range chunk {
+			chunk[i] = byte(len(frames) + i)
+		}
+		frames = append(frames, r.Write(chunk)...)
+	}
+
+	// 29103 bytes make 30 full frames, the remaining 303 bytes are held
+	assert.Len(t, frames, 30)
+	for i, f := range frames {
+		assert.Len(t, f, 960, "frame %d", i)
+	}
+	assert.Len(t, r.Flush(), 303)
+	assert.Nil(t, r.Flush())
+
+	// Reset drops a partial frame
+	assert.Empty(t, r.Write(make([]byte, 500)))
+	r.Reset()
+	assert.Nil(t, r.Flush())
+	assert.Len(t, r.Write(make([]byte, 960)), 1)
+}
+
+func TestAudioRechunker_PreservesAudio(t *testing.T) {
+	r := newAudioRechunker(10, 16000, 1)
+
+	var in, out []byte
+	for i := 0; i < 7; i++ {
+		chunk := make([]byte, 777*(i+1))
+		for j := range chunk {
+			chunk[j] = byte(len(in) + j)
+		}
+		in = append(in, chunk...)
+		for _, f := range r.Write(chunk) {
+			out = append(out, f...)
+		}
+	}
+	out = append(out, r.Flush()...)
+	assert.Equal(t, in, out)
+}
+
+func TestAudioRechunker_Disabled(t *testing.T) {
+	var r *audioRechunker = newAudioRechunker(0, 24000, 1)
+	assert.Nil(t, r)
+
+	chunk := make([]byte, 12345)
+	assert.Equal(t, [][]byte{chunk}, r.Write(chunk))
+	assert.Nil(t, r.Write(nil))
+	assert.Nil(t, r.Flush())
+	r.Reset()
+}
@@ -1,7 +1,26 @@
+// Package elements provides pipeline processing elements.
+//
+// AudioResampleElement 实现音频采样率和通道数转换。
+//
+// 主要功能:
+//   - 输入/输出采样率配置
+//   - 单声道/立体声支持
+//   - 按输入消息的实际通道数自动重建重采样器（例如立体声输入自动下混为单声道），
+//     避免把交织的立体声样本误当成单声道处理
+//   - 支持 s16/f32/s8 采样格式，输出保持输入格式（float32 输入不经过 int16 转换）
+//   - 输入采样率自动检测: 构造时 inRate 为 0（或使用 NewAutoResampleElement）时，
+//     按每条消息的 AudioData.SampleRate 重建重采样器；消息未声明采样率时，
+//     使用 Link 时上游 OutputFormat 声明的格式
+//
+// 使用示例:
+//
+//	resample := NewAudioResampleElement(48000, 16000, 1, 1)
+//	auto := NewAutoResampleElement(16000, 1) // 输入 48k/24k/22.05k 混合也能统一输出 16k
 package elements
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -18,6 +37,14 @@ type AudioResampleElement struct {
 	outRate     int
 	inChannels  int
 	outChannels int
+	format      pipeline.SampleFormat
+
+	// autoRate 为 true 时输入采样率取自每条消息
+	autoRate bool
+
+	// linked 为 Link 时上游声明的输出格式
+	linkedMu sync.Mutex
+	linked   pipeline.AudioFormat
 
 	resample *audio.Resample
 
@@ -25,34 +52,47 @@ type AudioResampleElement struct {
 	wg     sync.WaitGroup
 }
 
+// NewAudioResampleElement 创建重采样元素
+// inRate 为 0 时自动检测输入采样率（见 NewAutoResampleElement），否则输入固定按 inRate 处理
 func NewAudioResampleElement(inRate, outRate int, inChannels, outChannels int) *AudioResampleElement {
-	inLayout := astiav.ChannelLayoutMono
-	outLayout := astiav.ChannelLayoutMono
-	if inChannels == 1 {
-		inLayout = astiav.ChannelLayoutMono
-	} else if inChannels == 2 {
-		inLayout = astiav.ChannelLayoutStereo
-	}
-
-	if outChannels == 1 {
-		outLayout = astiav.ChannelLayoutMono
-	} else if outChannels == 2 {
-		outLayout = astiav.ChannelLayoutStereo
-	}
-
-	resample, err := audio.NewResample(inRate, outRate, inLayout, outLayout)
-	if err != nil {
-		log.Fatalf("failed to create resample: %v", err)
-	}
-
-	return &AudioResampleElement{
+	e := &AudioResampleElement{
 		BaseElement: pipeline.NewBaseElement("audio-resample-element", 100),
 		inRate:      inRate,
 		outRate:     outRate,
 		inChannels:  inChannels,
 		outChannels: outChannels,
-		resample:    resample,
+		format:      pipeline.SampleFormatS16,
+		autoRate:    inRate <= 0,
+	}
+
+	// 自动检测时第一条消息到达后再创建重采样器
+	if !e.autoRate {
+		resample, err := audio.NewResample(inRate, outRate, channelLayout(inChannels), channelLayout(outChannels))
+		if err != nil {
+			log.Fatalf("failed to create resample: %v", err)
+		}
+		e.resample = resample
 	}
+	return e
+}
+
+// NewAutoResampleElement 创建按输入消息的实际采样率和通道数重采样的元素
+// 输出固定为 targetRate/targetChannels，适合上游采样率不确定或会变化的场景（如不同 TTS 服务商）
+func NewAutoResampleElement(targetRate, targetChannels int) *AudioResampleElement {
+	return NewAudioResampleElement(0, targetRate, 0, targetChannels)
+}
+
+// SetInputFormat 记录上游声明的输出格式，Link 时由 Pipeline 调用
+// 仅在消息未声明采样率/通道数时使用
+func (e *AudioResampleElement) SetInputFormat(format pipeline.AudioFormat) {
+	e.linkedMu.Lock()
+	e.linked = format
+	e.linkedMu.Unlock()
+}
+
+// OutputFormat 返回输出的采样率和通道数
+func (e *AudioResampleElement) OutputFormat() pipeline.AudioFormat {
+	return pipeline.AudioFormat{SampleRate: e.outRate, Channels: e.outChannels}
 }
 
 func (e *AudioResampleElement) Start(ctx context.Context) error {
@@ -86,6 +126,12 @@ func (e *AudioResampleElement) Start(ctx context.Context) error {
 					continue
 				}
 
+				// 输入采样率、通道数或采样格式与当前不一致时重建重采样器
+				if err := e.ensureInput(msg.AudioData.SampleRate, msg.AudioData.Channels, msg.AudioData.Format()); err != nil {
+					log.Printf("[RESAMPLE] 输入格式切换失败: %v", err)
+					continue
+				}
+
 				// 重采样
 				outData, err := e.resample.Resample(msg.AudioData.Data)
 				if err != nil {
@@ -95,15 +141,18 @@ func (e *AudioResampleElement) Start(ctx context.Context) error {
 
 				// 创建输出消息
 				outMsg := &pipeline.PipelineMessage{
-					Type:      pipeline.MsgTypeAudio,
-					SessionID: msg.SessionID,
-					Timestamp: time.Now(),
+					Type:       pipeline.MsgTypeAudio,
+					SessionID:  msg.SessionID,
+					Attributes: msg.Attributes,
+					Timestamp:  time.Now(),
 					AudioData: &pipeline.AudioData{
-						Data:       outData,
-						SampleRate: e.outRate,
-						Channels:   e.outChannels,
-						MediaType:  pipeline.AudioMediaTypeRaw,
-						Timestamp:  time.Now(),
+						Data:          outData,
+						SampleRate:    e.outRate,
+						Channels:      e.outChannels,
+						MediaType:     pipeline.AudioMediaTypeRaw,
+						SampleFormat:  e.format,
+						Timestamp:     time.Now(),
+						EndOfSentence: msg.AudioData.EndOfSentence,
 					},
 				}
 
@@ -119,6 +168,81 @@ func (e *AudioResampleElement) Start(ctx context.Context) error {
 	return nil
 }
 
+// Flush 丢弃尚未重采样的输入和尚未被下游取走的输出，打断时由 Pipeline 调用
+func (e *AudioResampleElement) Flush() {
+	if n := e.DropQueued(); n > 0 {
+		log.Printf("[RESAMPLE] 打断，丢弃 %d 条排队消息", n)
+	}
+}
+
+// ensureInput 确保重采样器的输入采样率、布局和采样格式与实际输入一致
+// rate/channels 为 0 表示消息未声明，自动检测时先取 Link 时上游声明的格式，再沿用当前值；
+// 固定输入采样率时忽略消息的 rate
+func (e *AudioResampleElement) ensureInput(rate, channels int, format pipeline.SampleFormat) error {
+	var linked pipeline.AudioFormat
+	if e.autoRate {
+		e.linkedMu.Lock()
+		linked = e.linked
+		e.linkedMu.Unlock()
+	} else {
+		rate = e.inRate
+	}
+
+	if rate <= 0 {
+		rate = linked.SampleRate
+	}
+	if rate <= 0 {
+		rate = e.inRate
+	}
+	if rate <= 0 {
+		return fmt.Errorf("unknown input sample rate")
+	}
+	if channels <= 0 {
+		channels = linked.Channels
+	}
+	if channels <= 0 {
+		channels = e.inChannels
+	}
+	if channels <= 0 {
+		channels = 1
+	}
+	if e.resample != nil && rate == e.inRate && channels == e.inChannels && format == e.format {
+		return nil
+	}
+	if channels > 2 {
+		return fmt.Errorf("unsupported input channels: %d", channels)
+	}
+
+	resample, err := audio.NewResampleWithFormat(rate, e.outRate, channelLayout(channels), channelLayout(e.outChannels), format)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[RESAMPLE] 输入 %dHz %d 声道 %s -> %dHz %d 声道 %s，重建重采样器",
+		e.inRate, e.inChannels, e.format, rate, channels, format)
+	if e.resample != nil {
+		e.resample.Free()
+	}
+	e.resample = resample
+	e.inRate = rate
+	e.inChannels = channels
+	e.format = format
+	return nil
+}
+
+// channelLayout 将通道数转换为 FFmpeg 通道布局（仅支持单声道/立体声）
+func channelLayout(channels int) astiav.ChannelLayout {
+	if channels == 2 {
+		return astiav.ChannelLayoutStereo
+	}
+	return astiav.ChannelLayoutMono
+}
+
+// newF32Resampler 创建 float32 交织 PCM 的重采样器
+func newF32Resampler(inRate, outRate, inChannels, outChannels int) (*audio.Resample, error) {
+	return audio.NewResampleWithFormat(inRate, outRate, channelLayout(inChannels), channelLayout(outChannels), pipeline.SampleFormatF32)
+}
+
 func (e *AudioResampleElement) Stop() error {
 	if e.cancel != nil {
 		e.cancel()
@@ -132,3 +256,8 @@ func (e *AudioResampleElement) Stop() error {
 	}
 	return nil
 }
+
+// isPCMMediaType 判断是否为未编码的 PCM 音频
+func isPCMMediaType(mediaType pipeline.AudioMediaType) bool {
+	return mediaType == pipeline.AudioMediaTypeRaw || mediaType == pipeline.AudioMediaTypePCM
+}
@@ -0,0 +1,161 @@
+package elements
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateStereoTone 生成左右声道相同的交织立体声正弦波
+func generateStereoTone(numFrames int, frequency float64, sampleRate int) []byte {
+	data := make([]byte, numFrames*4)
+	for i := 0; i < numFrames; i++ {
+		sample := int16(10000 * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+		binary.LittleEndian.PutUint16(data[i*4:], uint16(sample))
+		binary.LittleEndian.PutUint16(data[i*4+2:], uint16(sample))
+	}
+	return data
+}
+
+func TestAudioResampleElement_StereoInputDownmix(t *testing.T) {
+	elem := NewAudioResampleElement(48000, 16000, 1, 1)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	// 100ms 48kHz 立体声: 4800 帧 => 16kHz 单声道约 1600 个采样 (3200 字节)
+	// 若误当作单声道处理，会得到约 6400 字节（时长翻倍、音调错误）
+	const numFrames = 4800
+	elem.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       generateStereoTone(numFrames, 440, 48000),
+			SampleRate: 48000,
+			Channels:   2,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+
+	select {
+	case out := <-elem.Out():
+		require.NotNil(t, out.AudioData)
+		assert.Equal(t, 16000, out.AudioData.SampleRate)
+		assert.Equal(t, 1, out.AudioData.Channels)
+
+		expectedBytes := numFrames / 3 * 2
+		assert.InDelta(t, expectedBytes, len(out.AudioData.Data), float64(expectedBytes)/10,
+			"stereo input should be downmixed, not treated as mono")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for resampled audio")
+	}
+}
+
+func TestAudioResampleElement_Float32Input(t *testing.T) {
+	elem := NewAudioResampleElement(48000, 16000, 1, 1)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	// 100ms 48kHz float32 单声道 => 16kHz 约 1600 个 float32 采样
+	const numFrames = 4800
+	samples := make([]float32, numFrames)
+	for i := range samples {
+		samples[i] = float32(0.3 * math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+	elem.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:         audio.Float32ToBytes(samples, pipeline.SampleFormatF32),
+			SampleRate:   48000,
+			Channels:     1,
+			MediaType:    pipeline.AudioMediaTypeRaw,
+			SampleFormat: pipeline.SampleFormatF32,
+		},
+	}
+
+	select {
+	case out := <-elem.Out():
+		require.NotNil(t, out.AudioData)
+		assert.Equal(t, pipeline.SampleFormatF32, out.AudioData.SampleFormat, "float input should stay float")
+
+		expectedBytes := numFrames / 3 * 4
+		assert.InDelta(t, expectedBytes, len(out.AudioData.Data), float64(expectedBytes)/10)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for resampled audio")
+	}
+}
+
+// generateMonoTone 生成单声道 16-bit 正弦波
+func generateMonoTone(numFrames int, frequency float64, sampleRate int) []byte {
+	data := make([]byte, numFrames*2)
+	for i := 0; i < numFrames; i++ {
+		sample := int16(10000 * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+	return data
+}
+
+func TestAutoResampleElement_MixedRates(t *testing.T) {
+	elem := NewAutoResampleElement(16000, 1)
+	assert.Equal(t, pipeline.AudioFormat{SampleRate: 16000, Channels: 1}, elem.OutputFormat())
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	// 每帧 100ms，采样率各不相同 => 16kHz 单声道均约 1600 个采样 (3200 字节)
+	for _, rate := range []int{48000, 24000, 22050, 16000, 48000} {
+		numFrames := rate / 10
+		elem.In() <- &pipeline.PipelineMessage{
+			Type: pipeline.MsgTypeAudio,
+			AudioData: &pipeline.AudioData{
+				Data:       generateMonoTone(numFrames, 440, rate),
+				SampleRate: rate,
+				Channels:   1,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+			},
+		}
+
+		select {
+		case out := <-elem.Out():
+			require.NotNil(t, out.AudioData)
+			assert.Equal(t, 16000, out.AudioData.SampleRate)
+			assert.Equal(t, 1, out.AudioData.Channels)
+			assert.InDelta(t, 3200, len(out.AudioData.Data), 320, "input at %dHz", rate)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for resampled %dHz audio", rate)
+		}
+	}
+}
+
+func TestAutoResampleElement_LinkedInputFormat(t *testing.T) {
+	decoder := NewG711DecodeElement(G711MuLaw)
+	elem := NewAutoResampleElement(16000, 1)
+
+	// Link 时上游声明 8kHz 单声道输出，消息本身未声明采样率和通道数
+	p := pipeline.NewPipeline("test")
+	p.AddElements([]pipeline.Element{decoder, elem})
+	p.Link(decoder, elem)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:      generateMonoTone(800, 440, 8000),
+			MediaType: pipeline.AudioMediaTypeRaw,
+		},
+	}
+
+	select {
+	case out := <-elem.Out():
+		require.NotNil(t, out.AudioData)
+		assert.Equal(t, 16000, out.AudioData.SampleRate)
+		assert.InDelta(t, 3200, len(out.AudioData.Data), 320, "8kHz input should be upsampled 2x")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for resampled audio")
+	}
+}
@@ -0,0 +1,263 @@
+// Package elements provides pipeline processing elements.
+//
+// AudioResyncElement 让实时音频流与墙上时钟保持同步。
+// 长时间通话中，重采样的取整误差和两端时钟的细微差异会不断累积，
+// 一小时的同传会话可能偏差数百毫秒，表现为音画不同步、字幕与语音错位。
+//
+// 工作原理:
+//   - 记录自对齐起经过的墙上时间与已输出的采样时长，二者之差即为漂移
+//   - 每个 CheckInterval 取窗口内漂移的最小值，滤掉网络抖动造成的迟到
+//   - 第一个窗口的漂移作为基线（包含固定的链路延迟），之后只校正相对基线的变化
+//   - 漂移超过 Threshold 时，把后续每条消息线性重采样、增减几个采样，
+//     每条最多拉伸 MaxStretch（默认 0.5%），听不出变调或卡顿
+//   - 每次开始校正时发布 EventAudioResync
+//   - 输入中断超过 GapReset、或采样率/通道数变化时重新对齐
+//   - 只处理原始 PCM，非音频消息和编码后的音频原样透传
+//
+// 只适用于按实时速率持续产生的音频（麦克风、同传输出等），
+// 不适合比实时快得多的突发音频（如未经节拍器的 TTS 输出）。
+//
+// 使用示例:
+//
+//	resync := NewAudioResyncElement(DefaultAudioResyncConfig())
+//	p.Link(resample, resync)
+//	p.Link(resync, opusEncode)
+package elements
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure AudioResyncElement implements pipeline.Element
+var _ pipeline.Element = (*AudioResyncElement)(nil)
+
+// AudioResyncConfig 漂移校正配置
+type AudioResyncConfig struct {
+	CheckInterval time.Duration  // 多久检查一次漂移（默认 10s）
+	Threshold     time.Duration  // 漂移超过该值才校正（默认 20ms）
+	MaxStretch    float64        // 每条消息最多拉伸或压缩的比例（默认 0.005）
+	GapReset      time.Duration  // 输入中断超过该值视为新的音频段，重新对齐（默认 1s）
+	Clock         pipeline.Clock // 时间源，默认 SystemClock
+}
+
+// DefaultAudioResyncConfig 返回默认配置
+func DefaultAudioResyncConfig() AudioResyncConfig {
+	return AudioResyncConfig{
+		CheckInterval: 10 * time.Second,
+		Threshold:     20 * time.Millisecond,
+		MaxStretch:    0.005,
+		GapReset:      time.Second,
+	}
+}
+
+// AudioResyncElement 音频漂移校正元素
+type AudioResyncElement struct {
+	*pipeline.BaseElement
+
+	config AudioResyncConfig
+
+	// 以下状态只在处理 goroutine 中访问
+	sampleRate  int
+	channels    int
+	anchor      time.Time     // 对齐时刻
+	lastArrival time.Time     // 上一条音频的到达时间
+	frames      int64         // 对齐后输出的帧数
+	nextCheck   time.Time     // 下一次检查漂移的时刻
+	windowMin   time.Duration // 当前窗口内的最小漂移
+	windowSet   bool
+	baseline    time.Duration // 第一个窗口的漂移，包含固定的链路延迟
+	hasBaseline bool
+	pending     int // 尚待插入（正）或丢弃（负）的帧数
+
+	drift     atomic.Int64 // 最近一次检查的漂移（纳秒）
+	corrected atomic.Int64 // 累计插入（正）或丢弃（负）的音频时长（纳秒）
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewAudioResyncElement 创建漂移校正元素
+func NewAudioResyncElement(cfg AudioResyncConfig) *AudioResyncElement {
+	defaults := DefaultAudioResyncConfig()
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaults.CheckInterval
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaults.Threshold
+	}
+	if cfg.MaxStretch <= 0 {
+		cfg.MaxStretch = defaults.MaxStretch
+	}
+	if cfg.GapReset <= 0 {
+		cfg.GapReset = defaults.GapReset
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = pipeline.SystemClock
+	}
+
+	return &AudioResyncElement{
+		BaseElement: pipeline.NewBaseElement("audio-resync-element", 100),
+		config:      cfg,
+	}
+}
+
+// Drift 返回最近一次检查时相对基线的漂移，正值表示音频比墙上时钟慢
+func (e *AudioResyncElement) Drift() time.Duration {
+	return time.Duration(e.drift.Load())
+}
+
+// Corrected 返回累计插入（正）或丢弃（负）的音频时长
+func (e *AudioResyncElement) Corrected() time.Duration {
+	return time.Duration(e.corrected.Load())
+}
+
+func (e *AudioResyncElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio {
+					e.process(msg.AudioData)
+				}
+
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *AudioResyncElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+// process 记录一条原始 PCM 音频的到达，必要时就地增减几个采样
+func (e *AudioResyncElement) process(data *pipeline.AudioData) {
+	if data == nil || len(data.Data) == 0 || data.SampleRate <= 0 || !isPCMMediaType(data.MediaType) {
+		return
+	}
+
+	now := e.config.Clock.Now()
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+
+	// 格式变化或输入中断后重新对齐，这条音频的结束即为对齐时刻
+	if data.SampleRate != e.sampleRate || channels != e.channels || now.Sub(e.lastArrival) > e.config.GapReset {
+		e.realign(now, data.SampleRate, channels)
+		return
+	}
+	e.lastArrival = now
+
+	format := data.Format()
+	if e.pending != 0 {
+		samples := audio.BytesToFloat32(data.Data, format)
+		if inFrames := len(samples) / channels; inFrames >= 2 {
+			limit := int(float64(inFrames) * e.config.MaxStretch)
+			if limit < 1 {
+				limit = 1
+			}
+			step := e.pending
+			if step > limit {
+				step = limit
+			} else if step < -limit {
+				step = -limit
+			}
+			data.Data = audio.Float32ToBytes(audio.ResizeFrames(samples, channels, inFrames+step), format)
+			e.pending -= step
+			e.corrected.Add(int64(e.framesDuration(int64(step))))
+		}
+	}
+
+	e.frames += int64(len(data.Data) / (format.BytesPerSample() * channels))
+	drift := now.Sub(e.anchor) - e.framesDuration(e.frames)
+	if !e.windowSet || drift < e.windowMin {
+		e.windowMin = drift
+		e.windowSet = true
+	}
+
+	if !now.Before(e.nextCheck) {
+		e.check()
+		e.nextCheck = now.Add(e.config.CheckInterval)
+		e.windowSet = false
+	}
+}
+
+// realign 重新开始测量漂移
+func (e *AudioResyncElement) realign(now time.Time, sampleRate, channels int) {
+	if e.sampleRate != 0 {
+		log.Printf("[AudioResync] Realigning at %dHz/%dch", sampleRate, channels)
+	}
+	e.sampleRate = sampleRate
+	e.channels = channels
+	e.anchor = now
+	e.lastArrival = now
+	e.frames = 0
+	e.nextCheck = now.Add(e.config.CheckInterval)
+	e.windowSet = false
+	e.hasBaseline = false
+	e.pending = 0
+}
+
+// check 在窗口结束时比较漂移与基线，超过阈值时安排校正
+func (e *AudioResyncElement) check() {
+	if !e.hasBaseline {
+		e.baseline = e.windowMin
+		e.hasBaseline = true
+		return
+	}
+
+	drift := e.windowMin - e.baseline
+	e.drift.Store(int64(drift))
+	if drift.Abs() < e.config.Threshold {
+		return
+	}
+
+	// 已输出的校正已计入 drift，这里按当前测量值重新安排
+	e.pending = int(math.Round(drift.Seconds() * float64(e.sampleRate)))
+	log.Printf("[AudioResync] Drift %v, correcting", drift)
+
+	if bus := e.Bus(); bus != nil {
+		bus.Publish(pipeline.Event{
+			Type:      pipeline.EventAudioResync,
+			Timestamp: e.config.Clock.Now(),
+			Payload: pipeline.AudioResyncPayload{
+				Source:    e.GetName(),
+				Drift:     drift,
+				Corrected: e.Corrected(),
+			},
+		})
+	}
+}
+
+// framesDuration 返回 frames 帧在当前采样率下的时长
+func (e *AudioResyncElement) framesDuration(frames int64) time.Duration {
+	return time.Duration(frames) * time.Second / time.Duration(e.sampleRate)
+}
@@ -0,0 +1,95 @@
+package elements
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runResync feeds 20ms chunks of a 16kHz sine whose source clock runs at
+// the given ratio of real time, for the given wall duration, and returns the
+// drift between wall time and output audio at the end
+func runResync(t *testing.T, elem *AudioResyncElement, clock *pipeline.ManualClock, ratio float64, wall time.Duration) time.Duration {
+	t.Helper()
+	const sampleRate = 16000
+	const chunk = sampleRate / 50
+	period := time.Duration(float64(20*time.Millisecond) / ratio)
+
+	start := clock.Now()
+	var outFrames int
+	for n := 0; clock.Now().Sub(start) < wall; n++ {
+		samples := make([]float32, chunk)
+		for i := range samples {
+			samples[i] = float32(0.3 * math.Sin(2*math.Pi*300*float64(n*chunk+i)/sampleRate))
+		}
+		data := &pipeline.AudioData{
+			Data:       audio.Float32ToBytes(samples, pipeline.SampleFormatS16),
+			SampleRate: sampleRate,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		}
+		elem.process(data)
+		outFrames += len(data.Data) / 2
+
+		clock.Advance(period)
+	}
+
+	// The first chunk marks the alignment point
+	audioTime := time.Duration(outFrames-chunk) * time.Second / sampleRate
+	return clock.Now().Sub(start) - period - audioTime
+}
+
+func newTestResync(threshold time.Duration) (*AudioResyncElement, *pipeline.ManualClock) {
+	clock := pipeline.NewManualClock(time.Unix(0, 0))
+	cfg := DefaultAudioResyncConfig()
+	cfg.Threshold = threshold
+	cfg.Clock = clock
+	return NewAudioResyncElement(cfg), clock
+}
+
+func TestAudioResyncElement_CorrectsDrift(t *testing.T) {
+	threshold := DefaultAudioResyncConfig().Threshold
+
+	// A source clock 0.1% slow drifts by 120ms over two minutes when uncorrected
+	elem, clock := newTestResync(time.Hour)
+	uncorrected := runResync(t, elem, clock, 0.999, 2*time.Minute)
+	assert.Greater(t, uncorrected, 100*time.Millisecond)
+	assert.Zero(t, elem.Corrected())
+
+	elem, clock = newTestResync(threshold)
+	bus := pipeline.NewEventBus()
+	events := make(chan pipeline.Event, 100)
+	bus.Subscribe(pipeline.EventAudioResync, events)
+	elem.SetBus(bus)
+
+	drift := runResync(t, elem, clock, 0.999, 2*time.Minute)
+	assert.Less(t, drift.Abs(), threshold+10*time.Millisecond, "drift should stay near the threshold")
+	assert.Greater(t, elem.Corrected(), 80*time.Millisecond, "missing audio should have been inserted")
+
+	require.NotEmpty(t, events)
+	payload := (<-events).Payload.(pipeline.AudioResyncPayload)
+	assert.Equal(t, elem.GetName(), payload.Source)
+	assert.GreaterOrEqual(t, payload.Drift, threshold)
+
+	// A fast source clock is corrected by dropping audio
+	elem, clock = newTestResync(threshold)
+	drift = runResync(t, elem, clock, 1.001, 2*time.Minute)
+	assert.Less(t, drift.Abs(), threshold+10*time.Millisecond)
+	assert.Less(t, elem.Corrected(), -80*time.Millisecond)
+}
+
+func TestAudioResyncElement_RealignsAfterGap(t *testing.T) {
+	elem, clock := newTestResync(DefaultAudioResyncConfig().Threshold)
+	runResync(t, elem, clock, 0.999, 15*time.Second)
+
+	// A pause in the input is not drift
+	clock.Advance(5 * time.Second)
+	drift := runResync(t, elem, clock, 1, 30*time.Second)
+	assert.Less(t, drift.Abs(), 5*time.Millisecond)
+	assert.Zero(t, elem.Corrected())
+}
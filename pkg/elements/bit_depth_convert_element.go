@@ -0,0 +1,152 @@
+// Package elements provides pipeline processing elements.
+//
+// BitDepthConvertElement 实现 PCM 音频位深转换。
+// Pipeline 中的元素默认处理 16-bit PCM，部分音源（电话网关的 8-bit、
+// 专业声卡或文件的 24/32-bit）需要在接入处先转换位深。
+//
+// 主要功能:
+//   - 支持 8/16/24/32-bit 有符号整数 PCM 之间的任意转换（24-bit 为 3 字节紧凑排列）
+//   - 提高位深无损，降低位深默认截断低位
+//   - dither=true 时降低位深前加入 TPDF 抖动并四舍五入，减少低电平信号的量化失真；
+//     抖动后超出范围的样本截断到最大/最小值，不会回绕
+//   - 输入格式以构造参数为准（音源通常不标注 SampleFormat），输出标注为目标格式
+//   - 非音频消息和编码音频原样透传
+//
+// 使用示例:
+//
+//	convert := NewBitDepthConvertElement(24, 16)
+//	convert.SetProperty("dither", true)
+//	p.AddElements([]pipeline.Element{source, convert, resample, vad})
+package elements
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure BitDepthConvertElement implements pipeline.Element
+var _ pipeline.Element = (*BitDepthConvertElement)(nil)
+
+// BitDepthConvertElement 音频位深转换元素
+type BitDepthConvertElement struct {
+	*pipeline.BaseElement
+
+	from   pipeline.SampleFormat
+	to     pipeline.SampleFormat
+	dither bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBitDepthConvertElement 创建位深转换元素，fromBits/toBits 取 8、16、24 或 32
+// 不支持的位深在 Start 时返回错误
+func NewBitDepthConvertElement(fromBits, toBits int) *BitDepthConvertElement {
+	elem := &BitDepthConvertElement{
+		BaseElement: pipeline.NewBaseElement("bit-depth-convert-element", 100),
+		from:        bitDepthFormat(fromBits),
+		to:          bitDepthFormat(toBits),
+	}
+
+	elem.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "dither",
+		Type:     reflect.TypeOf(false),
+		Writable: true,
+		Readable: true,
+		Default:  false,
+	})
+
+	return elem
+}
+
+// bitDepthFormat 返回位深对应的整数采样格式，不支持的位深返回空
+func bitDepthFormat(bits int) pipeline.SampleFormat {
+	switch bits {
+	case 8:
+		return pipeline.SampleFormatS8
+	case 16:
+		return pipeline.SampleFormatS16
+	case 24:
+		return pipeline.SampleFormatS24
+	case 32:
+		return pipeline.SampleFormatS32
+	}
+	return ""
+}
+
+// SetProperty 设置属性，dither=true 时降低位深前加入抖动
+func (e *BitDepthConvertElement) SetProperty(name string, value interface{}) error {
+	if err := e.BaseElement.SetProperty(name, value); err != nil {
+		return err
+	}
+	if name == "dither" {
+		e.dither = value.(bool)
+	}
+	return nil
+}
+
+func (e *BitDepthConvertElement) Start(ctx context.Context) error {
+	if e.from == "" || e.to == "" {
+		return fmt.Errorf("unsupported bit depth conversion: only 8, 16, 24 and 32 bits are supported")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil && isPCMMediaType(msg.AudioData.MediaType) {
+					if err := e.convert(msg.AudioData); err != nil {
+						log.Printf("[BitDepthConvert] 转换失败: %v", err)
+						continue
+					}
+				}
+
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *BitDepthConvertElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+// convert 原地转换一条音频数据的位深并标注输出格式
+func (e *BitDepthConvertElement) convert(data *pipeline.AudioData) error {
+	convert := audio.ConvertSampleFormat
+	if e.dither {
+		convert = audio.ConvertSampleFormatDither
+	}
+	out, err := convert(data.Data, e.from, e.to)
+	if err != nil {
+		return err
+	}
+	data.Data = out
+	data.SampleFormat = e.to
+	return nil
+}
@@ -0,0 +1,98 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runBitDepthConvert 把一条 PCM 消息送入转换元素，返回输出消息
+func runBitDepthConvert(t *testing.T, elem *BitDepthConvertElement, data []byte) *pipeline.PipelineMessage {
+	t.Helper()
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       data,
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+	select {
+	case out := <-elem.Out():
+		return out
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for converted audio")
+	}
+	return nil
+}
+
+func TestBitDepthConvertElement_RoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 0.123, -1, 0.999}
+	s16 := audio.Float32ToBytes(samples, pipeline.SampleFormatS16)
+
+	// 16 -> 24/32 -> 16 无损，输出标注目标格式
+	for _, bits := range []int{24, 32} {
+		wide := runBitDepthConvert(t, NewBitDepthConvertElement(16, bits), s16)
+		assert.Equal(t, bitDepthFormat(bits), wide.AudioData.SampleFormat)
+		assert.Len(t, wide.AudioData.Data, len(samples)*bits/8)
+		assert.Equal(t, 16000, wide.AudioData.SampleRate)
+
+		narrow := runBitDepthConvert(t, NewBitDepthConvertElement(bits, 16), wide.AudioData.Data)
+		assert.Equal(t, pipeline.SampleFormatS16, narrow.AudioData.SampleFormat)
+		assert.Equal(t, s16, narrow.AudioData.Data, "16 -> %d -> 16", bits)
+	}
+
+	// 8-bit 输入扩展到 16-bit 后保持刻度
+	s8 := audio.Float32ToBytes(samples, pipeline.SampleFormatS8)
+	out := runBitDepthConvert(t, NewBitDepthConvertElement(8, 16), s8)
+	got := audio.BytesToFloat32(out.AudioData.Data, pipeline.SampleFormatS16)
+	for i := range samples {
+		assert.InDelta(t, samples[i], got[i], 0.01, "sample %d", i)
+	}
+}
+
+func TestBitDepthConvertElement_DitherClipsOnDownscale(t *testing.T) {
+	// 满幅 24-bit 样本抖动并四舍五入后会超出 16-bit 范围，应截断而不是回绕到相反符号
+	full := audio.Float32ToBytes([]float32{1, -1, 1, -1, 1, -1, 1, -1}, pipeline.SampleFormatS24)
+
+	elem := NewBitDepthConvertElement(24, 16)
+	require.NoError(t, elem.SetProperty("dither", true))
+	for i := 0; i < 20; i++ {
+		out := runBitDepthConvert(t, elem, full)
+		for j, s := range audio.BytesToFloat32(out.AudioData.Data, pipeline.SampleFormatS16) {
+			if j%2 == 0 {
+				assert.Greater(t, s, float32(0.99), "sample %d", j)
+			} else {
+				assert.Less(t, s, float32(-0.99), "sample %d", j)
+			}
+		}
+	}
+}
+
+func TestBitDepthConvertElement_Passthrough(t *testing.T) {
+	elem := NewBitDepthConvertElement(24, 16)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeData, TextData: &pipeline.TextData{Data: []byte("hi")}}
+	select {
+	case out := <-elem.Out():
+		assert.Equal(t, "hi", string(out.TextData.Data))
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for passthrough message")
+	}
+}
+
+func TestBitDepthConvertElement_UnsupportedBits(t *testing.T) {
+	assert.Error(t, NewBitDepthConvertElement(12, 16).Start(context.Background()))
+	assert.Error(t, NewBitDepthConvertElement(16, 20).Start(context.Background()))
+}
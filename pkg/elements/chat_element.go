@@ -6,7 +6,15 @@
 // Main features:
 //   - OpenAI Chat Completion API integration (gpt-4o-mini, gpt-4o, etc.)
 //   - Conversation history management with configurable limit
+//   - Thread-safe history access (AppendMessage/GetHistory) for RAG and tool results
 //   - Streaming response for reduced time-to-first-token
+//   - Optional response length limit, truncated at a sentence boundary with a wrap-up
+//   - Optional response timeout: a stalled request is cancelled and a fallback phrase spoken
+//   - Interrupt recovery: with InterruptRecoveryPreserveContext the next request notes
+//     where the previous answer was cut off, so the model can resume it
+//   - Optional raw token stream (EventLLMTokenDelta) for UIs that show the text as it arrives
+//   - Function calling: requested tool calls are published as EventToolCall and the
+//     conversation continues once every result is returned via SubmitToolResult
 //   - Integration with pipeline event system
 //
 // Usage:
@@ -21,11 +29,15 @@ package elements
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -38,6 +50,9 @@ import (
 // Make sure ChatElement implements pipeline.Element
 var _ pipeline.Element = (*ChatElement)(nil)
 
+// Make sure ChatElement implements pipeline.ToolResultSender
+var _ pipeline.ToolResultSender = (*ChatElement)(nil)
+
 // ChatConfig holds configuration for the chat element
 type ChatConfig struct {
 	APIKey       string // OpenAI API key
@@ -47,6 +62,71 @@ type ChatConfig struct {
 	Streaming    bool   // Enable streaming responses
 	MaxHistory   int    // Maximum number of history messages to retain (0 = unlimited)
 	Temperature  float64 // Temperature for response generation (0.0-2.0)
+
+	// MaxResponseChars limits the spoken response length in characters (0 = unlimited).
+	// Longer responses are cut at the last sentence boundary and followed by WrapUpText.
+	MaxResponseChars int
+	WrapUpText       string // Wrap-up spoken after a truncated response (default: defaultWrapUpText)
+
+	// ResponseTimeout cancels a stalled request (0 = disabled): the model must
+	// start answering, and keep streaming new text, within this time. FallbackText
+	// is spoken instead so the user does not hear dead air, and
+	// EventResponseTimeout is published.
+	ResponseTimeout time.Duration
+	FallbackText    string // Spoken when ResponseTimeout fires (default: defaultFallbackText)
+
+	// CacheSystemPrompt marks the static system prompt for server-side prompt caching.
+	// Claude models get an Anthropic cache_control marker on the system prompt;
+	// other models get a prompt_cache_key so OpenAI routes them to the same cache.
+	CacheSystemPrompt bool
+	PromptCacheKey    string // OpenAI prompt cache key (default: derived from the system prompt)
+
+	// PublishTokenDeltas publishes the model's raw text as EventLLMTokenDelta as
+	// it streams in, before sentence segmentation and length limits, so a UI can
+	// show the text appearing alongside the spoken audio. TokenDeltaInterval
+	// coalesces deltas into at most one event per interval (0 = every delta).
+	PublishTokenDeltas bool
+	TokenDeltaInterval time.Duration
+
+	// Headers are extra headers sent on every API request (API gateway keys,
+	// org IDs, tracing headers)
+	Headers map[string]string
+
+	// Tools are the functions the model may call. Calls are published as
+	// EventToolCall; the model answers once all results are submitted.
+	Tools []ToolDefinition
+}
+
+// ToolDefinition describes a function the model may call
+type ToolDefinition struct {
+	Name        string         // Function name
+	Description string         // What the function does and when to call it
+	Parameters  map[string]any // JSON Schema of the arguments (nil = no arguments)
+}
+
+// toolCancelledResult is recorded for tool calls still pending when the user speaks again
+const toolCancelledResult = "Cancelled: the user spoke before the result arrived."
+
+// defaultWrapUpText is spoken after a response is cut short by MaxResponseChars
+const defaultWrapUpText = "I'll stop there. Let me know if you'd like more detail."
+
+// defaultFallbackText is spoken when the model does not respond within ResponseTimeout
+const defaultFallbackText = "Sorry, that's taking longer than expected. Could you say that again?"
+
+// Chat message roles accepted by AppendMessage
+const (
+	ChatRoleSystem    = "system"
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// ChatRoleTool is the role of tool results in GetHistory; they are added with SubmitToolResult
+const ChatRoleTool = "tool"
+
+// ChatMessage is a plain-text conversation history entry
+type ChatMessage struct {
+	Role    string // "system", "user", "assistant" or "tool"
+	Content string
 }
 
 // ChatElement processes text input through OpenAI Chat Completion API
@@ -57,6 +137,27 @@ type ChatElement struct {
 	client  *openai.Client
 	history []openai.ChatCompletionMessageParamUnion
 
+	// summary 是早期对话的摘要（由 SummarizerElement 写入），作为额外的 system 消息
+	summary string
+	// trimmed 记录已从 history 头部移除的消息总数，用于定位快照中的消息
+	trimmed int
+
+	// attrs holds the Attributes of the user message being answered,
+	// copied onto outgoing text and response events (processLoop goroutine only)
+	attrs pipeline.Attributes
+
+	// interruptNote is added to history before the next user message when the
+	// previous answer was interrupted under InterruptRecoveryPreserveContext
+	interruptNote string
+
+	// pendingTools holds the IDs of tool calls awaiting a result. awaitingTools
+	// is set while a follow-up request is due once they are all answered;
+	// toolResults wakes processLoop when the last result arrives.
+	pendingTools  map[string]bool
+	awaitingTools bool
+	toolSession   string
+	toolResults   chan struct{}
+
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 	mu     sync.RWMutex
@@ -79,11 +180,23 @@ func NewChatElement(config ChatConfig) (*ChatElement, error) {
 	if config.Temperature == 0 {
 		config.Temperature = 0.7
 	}
+	if config.MaxResponseChars > 0 && config.WrapUpText == "" {
+		config.WrapUpText = defaultWrapUpText
+	}
+	if config.ResponseTimeout > 0 && config.FallbackText == "" {
+		config.FallbackText = defaultFallbackText
+	}
+	if config.CacheSystemPrompt && config.PromptCacheKey == "" {
+		sum := sha256.Sum256([]byte(config.SystemPrompt))
+		config.PromptCacheKey = "system-" + hex.EncodeToString(sum[:8])
+	}
 
 	return &ChatElement{
-		BaseElement: pipeline.NewBaseElement("chat-element", 100),
-		config:      config,
-		history:     make([]openai.ChatCompletionMessageParamUnion, 0),
+		BaseElement:  pipeline.NewBaseElement("chat-element", 100),
+		config:       config,
+		history:      make([]openai.ChatCompletionMessageParamUnion, 0),
+		pendingTools: make(map[string]bool),
+		toolResults:  make(chan struct{}, 1),
 	}, nil
 }
 
@@ -99,6 +212,9 @@ func (e *ChatElement) Start(ctx context.Context) error {
 	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
 		opts = append(opts, option.WithBaseURL(baseURL))
 	}
+	for key, value := range e.config.Headers {
+		opts = append(opts, option.WithHeader(key, value))
+	}
 	client := openai.NewClient(opts...)
 	e.client = &client
 
@@ -109,8 +225,29 @@ func (e *ChatElement) Start(ctx context.Context) error {
 		e.processLoop(ctx)
 	}()
 
-	log.Printf("[ChatElement] Started (model: %s, streaming: %v, max_history: %d)",
-		e.config.Model, e.config.Streaming, e.config.MaxHistory)
+	// Follow the interrupt recovery policy
+	if bus := e.BaseElement.Bus(); bus != nil {
+		recoveryCh := make(chan pipeline.Event, 10)
+		bus.Subscribe(pipeline.EventInterruptRecovery, recoveryCh)
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer bus.Unsubscribe(pipeline.EventInterruptRecovery, recoveryCh)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case evt := <-recoveryCh:
+					if payload, ok := evt.Payload.(*pipeline.InterruptRecoveryPayload); ok {
+						e.handleInterruptRecovery(payload)
+					}
+				}
+			}
+		}()
+	}
+
+	log.Printf("[ChatElement] Started (model: %s, streaming: %v, max_history: %d, tools: %d)",
+		e.config.Model, e.config.Streaming, e.config.MaxHistory, len(e.config.Tools))
 	return nil
 }
 
@@ -126,14 +263,100 @@ func (e *ChatElement) Stop() error {
 	return nil
 }
 
+// Prewarm opens the connection to the API before the first turn by looking
+// up the configured model, so the first response does not pay for the TLS
+// handshake. An API error still leaves a warm connection and is not reported.
+// It implements pipeline.Prewarmer and must be called after Start.
+func (e *ChatElement) Prewarm(ctx context.Context) error {
+	if e.client == nil {
+		return fmt.Errorf("chat element not started")
+	}
+
+	_, err := e.client.Models.Get(ctx, e.config.Model)
+	var apiErr *openai.Error
+	if err != nil && !errors.As(err, &apiErr) {
+		return err
+	}
+	return nil
+}
+
 // ClearHistory clears the conversation history
 func (e *ChatElement) ClearHistory() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.history = make([]openai.ChatCompletionMessageParamUnion, 0)
+	e.summary = ""
+	e.trimmed = 0
+	e.pendingTools = make(map[string]bool)
+	e.awaitingTools = false
 	log.Println("[ChatElement] History cleared")
 }
 
+// AppendMessage appends a message to the conversation history.
+// It is safe to call while the element is running, e.g. to inject a tool result
+// or a retrieved document; the message is used from the next request on.
+func (e *ChatElement) AppendMessage(role, content string) error {
+	var msg openai.ChatCompletionMessageParamUnion
+	switch role {
+	case ChatRoleSystem:
+		msg = openai.SystemMessage(content)
+	case ChatRoleUser:
+		msg = openai.UserMessage(content)
+	case ChatRoleAssistant:
+		msg = openai.AssistantMessage(content)
+	default:
+		return fmt.Errorf("unsupported role: %q", role)
+	}
+
+	e.addToHistory(msg)
+	return nil
+}
+
+// SubmitToolResult returns the result of a tool call published as EventToolCall.
+// Once every call of the turn has a result, the model is asked to continue
+// the answer. It is safe to call from any goroutine.
+func (e *ChatElement) SubmitToolResult(callID string, result string) error {
+	e.mu.Lock()
+	if !e.pendingTools[callID] {
+		e.mu.Unlock()
+		return fmt.Errorf("unknown tool call: %q", callID)
+	}
+	delete(e.pendingTools, callID)
+	e.appendHistoryLocked(openai.ToolMessage(result, callID))
+	done := len(e.pendingTools) == 0
+	e.mu.Unlock()
+
+	if done {
+		select {
+		case e.toolResults <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// SendToolResult implements pipeline.ToolResultSender. Non-string results are JSON-encoded.
+func (e *ChatElement) SendToolResult(callID string, result any) error {
+	output, err := toolResultString(result)
+	if err != nil {
+		return err
+	}
+	return e.SubmitToolResult(callID, output)
+}
+
+// GetHistory returns a copy of the conversation history
+func (e *ChatElement) GetHistory() []ChatMessage {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	history := make([]ChatMessage, 0, len(e.history))
+	for _, msg := range e.history {
+		role, text := messageText(msg)
+		history = append(history, ChatMessage{Role: role, Content: text})
+	}
+	return history
+}
+
 // GetHistoryLength returns the current number of messages in history
 func (e *ChatElement) GetHistoryLength() int {
 	e.mu.RLock()
@@ -141,12 +364,75 @@ func (e *ChatElement) GetHistoryLength() int {
 	return len(e.history)
 }
 
+// SnapshotHistory returns a copy of the current history together with its offset,
+// i.e. the number of messages already trimmed from the front of the history.
+func (e *ChatElement) SnapshotHistory() ([]openai.ChatCompletionMessageParamUnion, int) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	snapshot := make([]openai.ChatCompletionMessageParamUnion, len(e.history))
+	copy(snapshot, e.history)
+	return snapshot, e.trimmed
+}
+
+// ApplySummary replaces the first count messages of a snapshot taken at offset
+// with summary. Messages that were already trimmed in the meantime are skipped.
+func (e *ChatElement) ApplySummary(summary string, offset, count int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	drop := offset + count - e.trimmed
+	if drop > len(e.history) {
+		drop = len(e.history)
+	}
+	// Tool results must follow the assistant message that called the tool
+	for drop > 0 && drop < len(e.history) && e.history[drop].OfTool != nil {
+		drop++
+	}
+	if drop > 0 {
+		e.history = e.history[drop:]
+		e.trimmed += drop
+	}
+	e.summary = summary
+}
+
+// hasPendingToolCalls reports whether tool calls are awaiting results or the
+// follow-up request that uses them
+func (e *ChatElement) hasPendingToolCalls() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.pendingTools) > 0 || e.awaitingTools
+}
+
+// GetSummary returns the current conversation summary
+func (e *ChatElement) GetSummary() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.summary
+}
+
 // processLoop handles incoming messages
 func (e *ChatElement) processLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-e.toolResults:
+			e.mu.Lock()
+			ready := e.awaitingTools && len(e.pendingTools) == 0
+			if ready {
+				e.awaitingTools = false
+			}
+			sessionID := e.toolSession
+			e.mu.Unlock()
+			if !ready {
+				continue
+			}
+
+			// Continue the answer with the tool results
+			if err := e.respond(ctx, "(tool results)", sessionID); err != nil {
+				log.Printf("[ChatElement] Error processing tool results: %v", err)
+				e.publishError(err)
+			}
 		case msg, ok := <-e.BaseElement.InChan:
 			if !ok {
 				return
@@ -158,13 +444,10 @@ func (e *ChatElement) processLoop(ctx context.Context) {
 				}
 
 				// Process the message
+				e.attrs = msg.Attributes
 				if err := e.processMessage(ctx, text, msg.SessionID); err != nil {
 					log.Printf("[ChatElement] Error processing message: %v", err)
-					e.BaseElement.Bus().Publish(pipeline.Event{
-						Type:      pipeline.EventError,
-						Timestamp: time.Now(),
-						Payload:   fmt.Sprintf("Chat error: %v", err),
-					})
+					e.publishError(err)
 				}
 			} else {
 				// Pass through non-text messages
@@ -174,55 +457,261 @@ func (e *ChatElement) processLoop(ctx context.Context) {
 	}
 }
 
+// publishError publishes a chat error on the bus
+func (e *ChatElement) publishError(err error) {
+	e.BaseElement.Bus().Publish(pipeline.Event{
+		Type:       pipeline.EventError,
+		Timestamp:  time.Now(),
+		Payload:    fmt.Sprintf("Chat error: %v", err),
+		Attributes: e.attrs,
+	})
+}
+
+// handleInterruptRecovery records where the answer was interrupted so the
+// next request can resume it; under InterruptRecoveryDiscard the next
+// response starts fresh
+func (e *ChatElement) handleInterruptRecovery(payload *pipeline.InterruptRecoveryPayload) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.interruptNote = ""
+	if payload.Policy != pipeline.InterruptRecoveryPreserveContext || payload.InterruptedText == "" {
+		return
+	}
+	e.interruptNote = fmt.Sprintf("The user interrupted your previous answer. They only heard: %q. "+
+		"If their next message does not change the topic, briefly acknowledge it and resume from where you were cut off "+
+		"(e.g. \"As I was saying...\") instead of repeating what they already heard.", payload.InterruptedText)
+	log.Printf("[ChatElement] Preserving interrupted answer for resumption")
+}
+
 // processMessage handles a single user message
 func (e *ChatElement) processMessage(ctx context.Context, userText string, sessionID string) error {
 	log.Printf("[ChatElement] User: %s", userText)
 
+	// Tool calls still pending are answered as cancelled, so the history stays valid.
+	// The tool replies must directly follow the assistant's tool calls, before any note
+	e.cancelPendingTools()
+
+	// Note where the previous answer was interrupted
+	e.mu.Lock()
+	note := e.interruptNote
+	e.interruptNote = ""
+	e.mu.Unlock()
+	if note != "" {
+		e.addToHistory(openai.SystemMessage(note))
+	}
+
 	// Add user message to history
 	e.addToHistory(openai.UserMessage(userText))
 
+	return e.respond(ctx, userText, sessionID)
+}
+
+// respond requests the next assistant answer for the current history.
+// requestText is only used for provider logging.
+func (e *ChatElement) respond(ctx context.Context, requestText string, sessionID string) error {
 	// Publish response start event
 	e.BaseElement.Bus().Publish(pipeline.Event{
-		Type:      pipeline.EventResponseStart,
-		Timestamp: time.Now(),
-		Payload:   sessionID,
+		Type:       pipeline.EventResponseStart,
+		Timestamp:  time.Now(),
+		Payload:    sessionID,
+		Attributes: e.attrs,
 	})
 
 	var response string
+	var calls []pipeline.ToolCallPayload
 	var err error
 
+	providerLog := e.ProviderLogger()
+	providerLog.Log(pipeline.ProviderRecord{
+		Kind:     pipeline.ProviderLLM,
+		Provider: "openai",
+		Element:  e.GetName(),
+		Model:    e.config.Model,
+		Text:     requestText,
+	})
+	started := time.Now()
+
+	reqCtx, guard := newResponseGuard(ctx, e.config.ResponseTimeout)
 	if e.config.Streaming {
-		response, err = e.chatStreaming(ctx, sessionID)
+		response, calls, err = e.chatStreaming(reqCtx, sessionID, guard)
 	} else {
-		response, err = e.chatNonStreaming(ctx, sessionID)
+		response, calls, err = e.chatNonStreaming(reqCtx, sessionID)
+	}
+	guard.Stop()
+
+	providerLog.Log(pipeline.ProviderRecord{
+		Kind:     pipeline.ProviderLLM,
+		Provider: "openai",
+		Element:  e.GetName(),
+		Response: true,
+		Model:    e.config.Model,
+		Text:     response,
+		Duration: time.Since(started),
+		Err:      err,
+	})
+
+	if err != nil && guard.TimedOut() {
+		response = e.fallback(response, sessionID)
+		err = nil
 	}
 
 	if err != nil {
 		// Publish response end with error
 		e.BaseElement.Bus().Publish(pipeline.Event{
-			Type:      pipeline.EventResponseEnd,
-			Timestamp: time.Now(),
-			Payload:   map[string]interface{}{"error": err.Error()},
+			Type:       pipeline.EventResponseEnd,
+			Timestamp:  time.Now(),
+			Payload:    map[string]interface{}{"error": err.Error()},
+			Attributes: e.attrs,
 		})
 		return err
 	}
 
 	// Add assistant response to history
-	e.addToHistory(openai.AssistantMessage(response))
+	if len(calls) > 0 {
+		e.requestTools(response, calls, sessionID)
+	} else {
+		e.addToHistory(openai.AssistantMessage(response))
+	}
 
 	// Publish response end event
 	e.BaseElement.Bus().Publish(pipeline.Event{
-		Type:      pipeline.EventResponseEnd,
-		Timestamp: time.Now(),
-		Payload:   map[string]interface{}{"text": response},
+		Type:       pipeline.EventResponseEnd,
+		Timestamp:  time.Now(),
+		Payload:    map[string]interface{}{"text": response},
+		Attributes: e.attrs,
 	})
 
 	log.Printf("[ChatElement] Assistant: %s", truncateForLog(response, 100))
 	return nil
 }
 
+// requestTools records the assistant's tool calls in history and publishes
+// them as EventToolCall. The answer continues once all results are submitted.
+func (e *ChatElement) requestTools(response string, calls []pipeline.ToolCallPayload, sessionID string) {
+	msg := openai.ChatCompletionAssistantMessageParam{}
+	if response != "" {
+		msg.Content.OfString = openai.String(response)
+	}
+	for _, call := range calls {
+		msg.ToolCalls = append(msg.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+			ID: call.CallID,
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{
+				Name:      call.Name,
+				Arguments: call.Arguments,
+			},
+		})
+	}
+
+	e.mu.Lock()
+	e.appendHistoryLocked(openai.ChatCompletionMessageParamUnion{OfAssistant: &msg})
+	for _, call := range calls {
+		e.pendingTools[call.CallID] = true
+	}
+	e.awaitingTools = true
+	e.toolSession = sessionID
+	e.mu.Unlock()
+
+	for _, call := range calls {
+		log.Printf("[ChatElement] Tool call: %s(%s)", call.Name, truncateForLog(call.Arguments, 100))
+		e.BaseElement.Bus().Publish(pipeline.Event{
+			Type:       pipeline.EventToolCall,
+			Timestamp:  time.Now(),
+			Payload:    &pipeline.ToolCallPayload{CallID: call.CallID, Name: call.Name, Arguments: call.Arguments},
+			Attributes: e.attrs,
+		})
+	}
+}
+
+// cancelPendingTools answers tool calls that have no result yet as cancelled
+// and publishes EventToolCallCancelled
+func (e *ChatElement) cancelPendingTools() {
+	e.mu.Lock()
+	var ids []string
+	for id := range e.pendingTools {
+		ids = append(ids, id)
+		e.appendHistoryLocked(openai.ToolMessage(toolCancelledResult, id))
+	}
+	e.pendingTools = make(map[string]bool)
+	e.awaitingTools = false
+	e.mu.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+	log.Printf("[ChatElement] Cancelled %d pending tool call(s)", len(ids))
+	e.BaseElement.Bus().Publish(pipeline.Event{
+		Type:       pipeline.EventToolCallCancelled,
+		Timestamp:  time.Now(),
+		Payload:    &pipeline.ToolCallCancelledPayload{CallIDs: ids},
+		Attributes: e.attrs,
+	})
+}
+
+// toolParams converts the configured tools to API parameters
+func (e *ChatElement) toolParams() []openai.ChatCompletionToolParam {
+	if len(e.config.Tools) == 0 {
+		return nil
+	}
+	tools := make([]openai.ChatCompletionToolParam, 0, len(e.config.Tools))
+	for _, tool := range e.config.Tools {
+		fn := shared.FunctionDefinitionParam{
+			Name:       tool.Name,
+			Parameters: shared.FunctionParameters(tool.Parameters),
+		}
+		if tool.Description != "" {
+			fn.Description = openai.String(tool.Description)
+		}
+		tools = append(tools, openai.ChatCompletionToolParam{Function: fn})
+	}
+	return tools
+}
+
+// toolCallBuffer collects streamed tool call deltas until each call is complete.
+// The API sends the ID and name in the first delta of a call and the
+// arguments in fragments, all tagged with the call's index.
+type toolCallBuffer struct {
+	calls []pipeline.ToolCallPayload
+}
+
+// add merges a delta into the call at its index
+func (b *toolCallBuffer) add(delta openai.ChatCompletionChunkChoiceDeltaToolCall) {
+	i := int(delta.Index)
+	if i < 0 {
+		return
+	}
+	for len(b.calls) <= i {
+		b.calls = append(b.calls, pipeline.ToolCallPayload{})
+	}
+	call := &b.calls[i]
+	if delta.ID != "" {
+		call.CallID = delta.ID
+	}
+	if delta.Function.Name != "" {
+		call.Name = delta.Function.Name
+	}
+	call.Arguments += delta.Function.Arguments
+}
+
+// complete returns the buffered calls, skipping any that never got an ID or name
+func (b *toolCallBuffer) complete() []pipeline.ToolCallPayload {
+	var calls []pipeline.ToolCallPayload
+	for _, call := range b.calls {
+		if call.CallID == "" || call.Name == "" {
+			continue
+		}
+		if call.Arguments == "" {
+			call.Arguments = "{}"
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
 // chatStreaming performs streaming chat completion
-func (e *ChatElement) chatStreaming(ctx context.Context, sessionID string) (string, error) {
+// The returned text is what was sent to TTS, also when an error occurs.
+// Tool calls are returned once the stream has completed.
+func (e *ChatElement) chatStreaming(ctx context.Context, sessionID string, guard *responseGuard) (string, []pipeline.ToolCallPayload, error) {
 	messages := e.buildMessages()
 
 	params := openai.ChatCompletionNewParams{
@@ -232,11 +721,21 @@ func (e *ChatElement) chatStreaming(ctx context.Context, sessionID string) (stri
 	if e.config.MaxTokens > 0 {
 		params.MaxTokens = openai.Int(int64(e.config.MaxTokens))
 	}
+	if e.config.CacheSystemPrompt && !e.usesAnthropicCache() {
+		params.PromptCacheKey = openai.String(e.config.PromptCacheKey)
+	}
+	params.Tools = e.toolParams()
 
 	stream := e.client.Chat.Completions.NewStreaming(ctx, params)
 
 	var builder strings.Builder
 	var sentenceBuffer strings.Builder
+	sentChars := 0
+	truncated := false
+	var toolCalls toolCallBuffer
+
+	tokens := e.newTokenDeltaPublisher()
+	defer tokens.flush()
 
 	for stream.Next() {
 		chunk := stream.Current()
@@ -244,18 +743,35 @@ func (e *ChatElement) chatStreaming(ctx context.Context, sessionID string) (stri
 			continue
 		}
 
+		if deltas := chunk.Choices[0].Delta.ToolCalls; len(deltas) > 0 {
+			guard.Touch()
+			for _, d := range deltas {
+				toolCalls.add(d)
+			}
+		}
+
 		delta := chunk.Choices[0].Delta.Content
 		if delta == "" {
 			continue
 		}
+		guard.Touch()
+		tokens.add(delta)
 
-		builder.WriteString(delta)
 		sentenceBuffer.WriteString(delta)
 
 		// Check if we have a complete sentence to send to TTS
 		// Send on sentence boundaries for natural speech
 		sentence := sentenceBuffer.String()
 		if shouldFlushSentence(sentence) {
+			// Stop at the sentence boundary once the length limit would be exceeded
+			chars := utf8.RuneCountInString(sentence)
+			if e.exceedsResponseLimit(sentChars, chars) {
+				truncated = true
+				break
+			}
+			sentChars += chars
+
+			builder.WriteString(sentence)
 			e.sendToTTS(sentence, sessionID, false)
 			sentenceBuffer.Reset()
 
@@ -268,21 +784,155 @@ func (e *ChatElement) chatStreaming(ctx context.Context, sessionID string) (stri
 		}
 	}
 
+	if truncated {
+		stream.Close()
+		return e.wrapUp(builder.String(), sessionID), nil, nil
+	}
+
 	if err := stream.Err(); err != nil {
-		return "", fmt.Errorf("streaming error: %w", err)
+		return builder.String(), nil, fmt.Errorf("streaming error: %w", err)
 	}
 
 	// Send remaining text
 	remaining := sentenceBuffer.String()
+	if e.exceedsResponseLimit(sentChars, utf8.RuneCountInString(remaining)) {
+		return e.wrapUp(builder.String(), sessionID), nil, nil
+	}
+	builder.WriteString(remaining)
 	if remaining != "" {
 		e.sendToTTS(remaining, sessionID, true)
 	}
 
-	return builder.String(), nil
+	return builder.String(), toolCalls.complete(), nil
+}
+
+// tokenDeltaPublisher publishes raw model text as EventLLMTokenDelta,
+// coalescing deltas that arrive within TokenDeltaInterval of the last event.
+// A nil publisher does nothing.
+type tokenDeltaPublisher struct {
+	e        *ChatElement
+	interval time.Duration
+	pending  strings.Builder
+	lastAt   time.Time
+	seq      int
+}
+
+// newTokenDeltaPublisher returns nil unless PublishTokenDeltas is set
+func (e *ChatElement) newTokenDeltaPublisher() *tokenDeltaPublisher {
+	if !e.config.PublishTokenDeltas {
+		return nil
+	}
+	return &tokenDeltaPublisher{e: e, interval: e.config.TokenDeltaInterval}
+}
+
+// add queues delta and publishes it unless the last event was too recent
+func (p *tokenDeltaPublisher) add(delta string) {
+	if p == nil {
+		return
+	}
+	p.pending.WriteString(delta)
+	if p.interval <= 0 || time.Since(p.lastAt) >= p.interval {
+		p.flush()
+	}
+}
+
+// flush publishes any queued text
+func (p *tokenDeltaPublisher) flush() {
+	if p == nil || p.pending.Len() == 0 {
+		return
+	}
+	p.lastAt = time.Now()
+	p.e.BaseElement.Bus().Publish(pipeline.Event{
+		Type:      pipeline.EventLLMTokenDelta,
+		Timestamp: p.lastAt,
+		Payload: pipeline.LLMTokenDeltaPayload{
+			Source: p.e.GetName(),
+			Text:   p.pending.String(),
+			Seq:    p.seq,
+		},
+		Attributes: p.e.attrs,
+	})
+	p.pending.Reset()
+	p.seq++
+}
+
+// exceedsResponseLimit reports whether adding chars to the sent characters would
+// exceed MaxResponseChars. The first sentence is always allowed so the response is never empty.
+func (e *ChatElement) exceedsResponseLimit(sentChars, chars int) bool {
+	return e.config.MaxResponseChars > 0 && sentChars > 0 && sentChars+chars > e.config.MaxResponseChars
+}
+
+// wrapUp finishes a truncated response with the wrap-up text and returns the full spoken response
+func (e *ChatElement) wrapUp(response string, sessionID string) string {
+	log.Printf("[ChatElement] Response truncated at %d characters", utf8.RuneCountInString(response))
+	e.sendToTTS(e.config.WrapUpText, sessionID, true)
+	return strings.TrimSpace(response) + " " + e.config.WrapUpText
+}
+
+// fallback finishes a timed-out response with the fallback text and returns the full spoken response
+func (e *ChatElement) fallback(response string, sessionID string) string {
+	log.Printf("[ChatElement] No response within %v, speaking fallback", e.config.ResponseTimeout)
+	e.sendToTTS(e.config.FallbackText, sessionID, true)
+
+	e.BaseElement.Bus().Publish(pipeline.Event{
+		Type:      pipeline.EventResponseTimeout,
+		Timestamp: time.Now(),
+		Payload: pipeline.ResponseTimeoutPayload{
+			Source:   e.GetName(),
+			Timeout:  e.config.ResponseTimeout,
+			Fallback: e.config.FallbackText,
+		},
+		Attributes: e.attrs,
+	})
+
+	return strings.TrimSpace(strings.TrimSpace(response) + " " + e.config.FallbackText)
+}
+
+// responseGuard cancels a request that makes no progress within its timeout.
+// A nil guard is valid and does nothing.
+type responseGuard struct {
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// newResponseGuard returns a context that is cancelled when the guard times
+// out. It returns ctx and a nil guard when timeout <= 0.
+func newResponseGuard(ctx context.Context, timeout time.Duration) (context.Context, *responseGuard) {
+	if timeout <= 0 {
+		return ctx, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	g := &responseGuard{timeout: timeout}
+	g.timer = time.AfterFunc(timeout, func() {
+		g.timedOut.Store(true)
+		cancel()
+	})
+	return ctx, g
+}
+
+// Touch restarts the timeout after the model made progress.
+func (g *responseGuard) Touch() {
+	if g == nil || g.timedOut.Load() {
+		return
+	}
+	g.timer.Reset(g.timeout)
+}
+
+// Stop ends the guard once the request has finished.
+func (g *responseGuard) Stop() {
+	if g != nil {
+		g.timer.Stop()
+	}
+}
+
+// TimedOut reports whether the guard cancelled the request.
+func (g *responseGuard) TimedOut() bool {
+	return g != nil && g.timedOut.Load()
 }
 
 // chatNonStreaming performs non-streaming chat completion
-func (e *ChatElement) chatNonStreaming(ctx context.Context, sessionID string) (string, error) {
+func (e *ChatElement) chatNonStreaming(ctx context.Context, sessionID string) (string, []pipeline.ToolCallPayload, error) {
 	messages := e.buildMessages()
 
 	params := openai.ChatCompletionNewParams{
@@ -292,22 +942,46 @@ func (e *ChatElement) chatNonStreaming(ctx context.Context, sessionID string) (s
 	if e.config.MaxTokens > 0 {
 		params.MaxTokens = openai.Int(int64(e.config.MaxTokens))
 	}
+	if e.config.CacheSystemPrompt && !e.usesAnthropicCache() {
+		params.PromptCacheKey = openai.String(e.config.PromptCacheKey)
+	}
+	params.Tools = e.toolParams()
 
 	completion, err := e.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("completion error: %w", err)
+		return "", nil, fmt.Errorf("completion error: %w", err)
 	}
 
 	if len(completion.Choices) == 0 {
-		return "", fmt.Errorf("no response from model")
+		return "", nil, fmt.Errorf("no response from model")
+	}
+
+	var toolCalls toolCallBuffer
+	for i, call := range completion.Choices[0].Message.ToolCalls {
+		toolCalls.add(openai.ChatCompletionChunkChoiceDeltaToolCall{
+			Index:    int64(i),
+			ID:       call.ID,
+			Function: openai.ChatCompletionChunkChoiceDeltaToolCallFunction{Name: call.Function.Name, Arguments: call.Function.Arguments},
+		})
 	}
 
 	response := completion.Choices[0].Message.Content
+	if tokens := e.newTokenDeltaPublisher(); tokens != nil {
+		tokens.add(response)
+		tokens.flush()
+	}
+
+	if e.config.MaxResponseChars > 0 {
+		if cut, truncated := truncateAtSentence(response, e.config.MaxResponseChars); truncated {
+			e.sendToTTS(cut, sessionID, false)
+			return e.wrapUp(cut, sessionID), nil, nil
+		}
+	}
 
 	// Send complete response to TTS
 	e.sendToTTS(response, sessionID, true)
 
-	return response, nil
+	return response, toolCalls.complete(), nil
 }
 
 // buildMessages builds the message array for API call
@@ -315,10 +989,15 @@ func (e *ChatElement) buildMessages() []openai.ChatCompletionMessageParamUnion {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(e.history)+1)
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(e.history)+2)
 
 	// Add system message
-	messages = append(messages, openai.SystemMessage(e.config.SystemPrompt))
+	messages = append(messages, e.systemMessage())
+
+	// Add summary of earlier conversation
+	if e.summary != "" {
+		messages = append(messages, openai.SystemMessage("Summary of the earlier conversation: "+e.summary))
+	}
 
 	// Add history
 	messages = append(messages, e.history...)
@@ -326,11 +1005,34 @@ func (e *ChatElement) buildMessages() []openai.ChatCompletionMessageParamUnion {
 	return messages
 }
 
+// systemMessage returns the system prompt, with an Anthropic cache_control
+// marker when CacheSystemPrompt is set for a Claude model
+func (e *ChatElement) systemMessage() openai.ChatCompletionMessageParamUnion {
+	if !e.config.CacheSystemPrompt || !e.usesAnthropicCache() {
+		return openai.SystemMessage(e.config.SystemPrompt)
+	}
+
+	part := openai.ChatCompletionContentPartTextParam{Text: e.config.SystemPrompt}
+	part.SetExtraFields(map[string]any{
+		"cache_control": map[string]string{"type": "ephemeral"},
+	})
+	return openai.SystemMessage([]openai.ChatCompletionContentPartTextParam{part})
+}
+
+// usesAnthropicCache reports whether the model uses Anthropic-style cache_control markers
+func (e *ChatElement) usesAnthropicCache() bool {
+	return strings.Contains(strings.ToLower(e.config.Model), "claude")
+}
+
 // addToHistory adds a message to history with limit enforcement
 func (e *ChatElement) addToHistory(msg openai.ChatCompletionMessageParamUnion) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	e.appendHistoryLocked(msg)
+}
 
+// appendHistoryLocked adds a message to history with limit enforcement; e.mu must be held
+func (e *ChatElement) appendHistoryLocked(msg openai.ChatCompletionMessageParamUnion) {
 	e.history = append(e.history, msg)
 
 	// Enforce history limit (keep pairs of user/assistant messages)
@@ -340,8 +1042,33 @@ func (e *ChatElement) addToHistory(msg openai.ChatCompletionMessageParamUnion) {
 		if excess%2 != 0 {
 			excess++ // Keep pairs
 		}
+		// Tool results must follow the assistant message that called the tool
+		for excess < len(e.history) && e.history[excess].OfTool != nil {
+			excess++
+		}
 		e.history = e.history[excess:]
+		e.trimmed += excess
+	}
+}
+
+// messageText extracts the role and plain text content of a history message
+func messageText(msg openai.ChatCompletionMessageParamUnion) (string, string) {
+	role := ""
+	switch {
+	case msg.OfUser != nil:
+		role = ChatRoleUser
+	case msg.OfAssistant != nil:
+		role = ChatRoleAssistant
+	case msg.OfSystem != nil:
+		role = ChatRoleSystem
+	case msg.OfTool != nil:
+		role = ChatRoleTool
 	}
+	text := ""
+	if s, ok := msg.GetContent().AsAny().(*string); ok && s != nil {
+		text = *s
+	}
+	return role, text
 }
 
 // sendToTTS sends text to the TTS element
@@ -356,9 +1083,10 @@ func (e *ChatElement) sendToTTS(text string, sessionID string, isFinal bool) {
 	}
 
 	msg := &pipeline.PipelineMessage{
-		Type:      pipeline.MsgTypeData,
-		SessionID: sessionID,
-		Timestamp: time.Now(),
+		Type:       pipeline.MsgTypeData,
+		SessionID:  sessionID,
+		Timestamp:  time.Now(),
+		Attributes: e.attrs,
 		TextData: &pipeline.TextData{
 			Data:      []byte(text),
 			TextType:  textType,
@@ -368,6 +1096,9 @@ func (e *ChatElement) sendToTTS(text string, sessionID string, isFinal bool) {
 	e.BaseElement.OutChan <- msg
 }
 
+// sentenceEnders are the punctuation marks that end a sentence
+const sentenceEnders = ".!?;:。！？；："
+
 // shouldFlushSentence checks if the buffer contains a complete sentence
 func shouldFlushSentence(text string) bool {
 	// Flush on sentence-ending punctuation
@@ -383,11 +1114,37 @@ func shouldFlushSentence(text string) bool {
 		return false
 	}
 
-	sentenceEnders := ".!?;:。！？；："
-
 	return strings.ContainsRune(sentenceEnders, lastRune)
 }
 
+// truncateAtSentence cuts text to at most maxChars characters, ending at the last
+// sentence boundary. If there is no boundary, the first sentence is kept whole.
+func truncateAtSentence(text string, maxChars int) (string, bool) {
+	if utf8.RuneCountInString(text) <= maxChars {
+		return text, false
+	}
+
+	cut := -1
+	chars := 0
+	for i, r := range text {
+		chars++
+		if strings.ContainsRune(sentenceEnders, r) {
+			end := i + utf8.RuneLen(r)
+			if chars > maxChars && cut >= 0 {
+				break
+			}
+			cut = end
+			if chars >= maxChars {
+				break
+			}
+		}
+	}
+	if cut < 0 || cut >= len(text) {
+		return text, false
+	}
+	return text[:cut], true
+}
+
 // truncateForLog truncates text for logging
 func truncateForLog(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -2,12 +2,18 @@ package elements
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/openai/openai-go"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -83,6 +89,88 @@ func TestChatElementHistory(t *testing.T) {
 	assert.Equal(t, 0, elem.GetHistoryLength())
 }
 
+// TestChatElementAppendMessage tests thread-safe history injection
+func TestChatElementAppendMessage(t *testing.T) {
+	elem, err := NewChatElement(ChatConfig{
+		APIKey:     "test-key",
+		MaxHistory: 100,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, elem.AppendMessage(ChatRoleSystem, "Retrieved document: the store opens at 9am."))
+	require.NoError(t, elem.AppendMessage(ChatRoleUser, "When do you open?"))
+	require.NoError(t, elem.AppendMessage(ChatRoleAssistant, "At 9am."))
+	assert.Error(t, elem.AppendMessage("tool", "result"))
+
+	history := elem.GetHistory()
+	require.Len(t, history, 3)
+	assert.Equal(t, ChatMessage{Role: ChatRoleSystem, Content: "Retrieved document: the store opens at 9am."}, history[0])
+	assert.Equal(t, ChatMessage{Role: ChatRoleUser, Content: "When do you open?"}, history[1])
+	assert.Equal(t, ChatMessage{Role: ChatRoleAssistant, Content: "At 9am."}, history[2])
+
+	// Concurrent writers and readers must not race
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			elem.AppendMessage(ChatRoleUser, "hello")
+		}()
+		go func() {
+			defer wg.Done()
+			elem.GetHistory()
+			elem.buildMessages()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 13, elem.GetHistoryLength())
+
+	elem.ClearHistory()
+	assert.Empty(t, elem.GetHistory())
+}
+
+// TestChatElementApplySummary tests replacing older history with a summary
+func TestChatElementApplySummary(t *testing.T) {
+	elem, err := NewChatElement(ChatConfig{
+		APIKey:     "test-key",
+		MaxHistory: 4,
+	})
+	require.NoError(t, err)
+
+	elem.addToHistory(openai.UserMessage("u1"))
+	elem.addToHistory(openai.AssistantMessage("a1"))
+	elem.addToHistory(openai.UserMessage("u2"))
+	elem.addToHistory(openai.AssistantMessage("a2"))
+
+	history, offset := elem.SnapshotHistory()
+	require.Len(t, history, 4)
+	assert.Equal(t, 0, offset)
+
+	role, text := messageText(history[0])
+	assert.Equal(t, "user", role)
+	assert.Equal(t, "u1", text)
+
+	// A new turn trims the first pair before the summary is applied
+	elem.addToHistory(openai.UserMessage("u3"))
+	elem.addToHistory(openai.AssistantMessage("a3"))
+
+	// Summarize the first two turns of the snapshot
+	elem.ApplySummary("user said u1 and u2", offset, 4)
+
+	assert.Equal(t, "user said u1 and u2", elem.GetSummary())
+	assert.Equal(t, 2, elem.GetHistoryLength())
+
+	remaining, _ := elem.SnapshotHistory()
+	_, text = messageText(remaining[0])
+	assert.Equal(t, "u3", text)
+
+	// Summary is sent as an extra system message
+	assert.Len(t, elem.buildMessages(), 4)
+
+	elem.ClearHistory()
+	assert.Empty(t, elem.GetSummary())
+}
+
 // TestChatElementShouldFlushSentence tests sentence detection
 func TestChatElementShouldFlushSentence(t *testing.T) {
 	tests := []struct {
@@ -109,6 +197,527 @@ func TestChatElementShouldFlushSentence(t *testing.T) {
 	}
 }
 
+// TestTruncateAtSentence tests response truncation at sentence boundaries
+func TestTruncateAtSentence(t *testing.T) {
+	tests := []struct {
+		text      string
+		maxChars  int
+		expected  string
+		truncated bool
+	}{
+		{"Short.", 20, "Short.", false},
+		{"One. Two. Three.", 10, "One. Two.", true},
+		{"One. Two. Three.", 4, "One.", true},
+		{"A very long first sentence. Two.", 5, "A very long first sentence.", true},
+		{"No boundary at all", 5, "No boundary at all", false},
+		{"你好。今天天气很好。", 4, "你好。", true},
+	}
+
+	for _, tt := range tests {
+		result, truncated := truncateAtSentence(tt.text, tt.maxChars)
+		assert.Equal(t, tt.expected, result, "text: %q", tt.text)
+		assert.Equal(t, tt.truncated, truncated, "text: %q", tt.text)
+	}
+}
+
+// captureChatRequest runs one non-streaming turn against a fake OpenAI server
+// and returns the decoded request body
+func captureChatRequest(t *testing.T, config ChatConfig) map[string]any {
+	t.Helper()
+
+	requests := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"m",`+
+			`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"OK"}}]}`)
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	config.APIKey = "test-key"
+	chat, err := NewChatElement(config)
+	require.NoError(t, err)
+
+	p := pipeline.NewPipeline("test-chat-cache")
+	p.AddElement(chat)
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	require.NoError(t, p.PushText("test-session", "Hello"))
+
+	select {
+	case body := <-requests:
+		return body
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for chat request")
+		return nil
+	}
+}
+
+// TestChatElementCacheSystemPrompt tests the prompt caching markers sent to the API
+func TestChatElementCacheSystemPrompt(t *testing.T) {
+	const prompt = "You are a helpful assistant with a very long static prompt."
+
+	t.Run("anthropic cache_control marker", func(t *testing.T) {
+		body := captureChatRequest(t, ChatConfig{
+			Model:             "claude-sonnet-4",
+			SystemPrompt:      prompt,
+			CacheSystemPrompt: true,
+		})
+
+		system := body["messages"].([]any)[0].(map[string]any)
+		assert.Equal(t, "system", system["role"])
+		part := system["content"].([]any)[0].(map[string]any)
+		assert.Equal(t, prompt, part["text"])
+		assert.Equal(t, map[string]any{"type": "ephemeral"}, part["cache_control"])
+		assert.NotContains(t, body, "prompt_cache_key")
+	})
+
+	t.Run("openai prompt_cache_key", func(t *testing.T) {
+		body := captureChatRequest(t, ChatConfig{
+			Model:             "gpt-4o-mini",
+			SystemPrompt:      prompt,
+			CacheSystemPrompt: true,
+		})
+
+		key, ok := body["prompt_cache_key"].(string)
+		require.True(t, ok, "prompt_cache_key not sent")
+		assert.True(t, strings.HasPrefix(key, "system-"))
+
+		// The key is stable for the same system prompt
+		chat, err := NewChatElement(ChatConfig{APIKey: "k", SystemPrompt: prompt, CacheSystemPrompt: true})
+		require.NoError(t, err)
+		assert.Equal(t, key, chat.config.PromptCacheKey)
+
+		system := body["messages"].([]any)[0].(map[string]any)
+		assert.Equal(t, prompt, system["content"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		body := captureChatRequest(t, ChatConfig{
+			Model:        "claude-sonnet-4",
+			SystemPrompt: prompt,
+		})
+
+		assert.NotContains(t, body, "prompt_cache_key")
+		system := body["messages"].([]any)[0].(map[string]any)
+		assert.Equal(t, prompt, system["content"])
+	})
+}
+
+// TestChatElementInterruptRecovery tests that a preserved interruption is noted before the next user turn
+func TestChatElementInterruptRecovery(t *testing.T) {
+	requests := make(chan map[string]any, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"m",`+
+			`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"OK"}}]}`)
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	chat, err := NewChatElement(ChatConfig{APIKey: "test-key"})
+	require.NoError(t, err)
+	p := pipeline.NewPipeline("test-chat-recovery")
+	p.AddElement(chat)
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	pendingNote := func() string {
+		chat.mu.RLock()
+		defer chat.mu.RUnlock()
+		return chat.interruptNote
+	}
+	lastMessages := func() []any {
+		select {
+		case body := <-requests:
+			messages := body["messages"].([]any)
+			return messages[len(messages)-2:]
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout waiting for chat request")
+			return nil
+		}
+	}
+
+	p.Bus().Publish(pipeline.Event{
+		Type: pipeline.EventInterruptRecovery,
+		Payload: &pipeline.InterruptRecoveryPayload{
+			Policy:          pipeline.InterruptRecoveryPreserveContext,
+			InterruptedText: "The three steps are",
+		},
+	})
+	require.Eventually(t, func() bool { return pendingNote() != "" }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, p.PushText("test-session", "Sorry, go on"))
+	messages := lastMessages()
+	note := messages[0].(map[string]any)
+	assert.Equal(t, "system", note["role"])
+	assert.Contains(t, note["content"], `"The three steps are"`)
+	assert.Equal(t, "Sorry, go on", messages[1].(map[string]any)["content"])
+
+	// Discard starts fresh: no note before the next turn
+	p.Bus().Publish(pipeline.Event{
+		Type: pipeline.EventInterruptRecovery,
+		Payload: &pipeline.InterruptRecoveryPayload{
+			Policy:          pipeline.InterruptRecoveryDiscard,
+			InterruptedText: "OK",
+		},
+	})
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, p.PushText("test-session", "Something else"))
+	messages = lastMessages()
+	assert.Equal(t, "assistant", messages[0].(map[string]any)["role"])
+	assert.Equal(t, "Something else", messages[1].(map[string]any)["content"])
+}
+
+// TestChatElementTokenDeltas tests that the raw stream is published before segmentation
+func TestChatElementTokenDeltas(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"Hel", "lo there", ". Bye"} {
+			io.WriteString(w, `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"m",`+
+				`"choices":[{"index":0,"delta":{"content":"`+delta+`"}}]}`+"\n\n")
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	collect := func(interval time.Duration) []pipeline.LLMTokenDeltaPayload {
+		chat, err := NewChatElement(ChatConfig{
+			APIKey:             "test-key",
+			Streaming:          true,
+			PublishTokenDeltas: true,
+			TokenDeltaInterval: interval,
+		})
+		require.NoError(t, err)
+
+		p := pipeline.NewPipeline("test-chat-tokens")
+		p.AddElement(chat)
+		deltas := make(chan pipeline.Event, 10)
+		p.Bus().Subscribe(pipeline.EventLLMTokenDelta, deltas)
+		ends := make(chan pipeline.Event, 1)
+		p.Bus().Subscribe(pipeline.EventResponseEnd, ends)
+		require.NoError(t, p.Start(context.Background()))
+		defer p.Stop()
+
+		require.NoError(t, p.PushText("test-session", "Hi"))
+		select {
+		case <-ends:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout waiting for response end")
+		}
+
+		var payloads []pipeline.LLMTokenDeltaPayload
+		for {
+			select {
+			case evt := <-deltas:
+				payloads = append(payloads, evt.Payload.(pipeline.LLMTokenDeltaPayload))
+			default:
+				return payloads
+			}
+		}
+	}
+
+	// Every delta as received
+	payloads := collect(0)
+	require.Len(t, payloads, 3)
+	for i, want := range []string{"Hel", "lo there", ". Bye"} {
+		assert.Equal(t, want, payloads[i].Text)
+		assert.Equal(t, i, payloads[i].Seq)
+		assert.Equal(t, "chat-element", payloads[i].Source)
+	}
+
+	// Throttled: the first delta goes out at once, the rest is coalesced
+	payloads = collect(time.Hour)
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "Hel", payloads[0].Text)
+	assert.Equal(t, "lo there. Bye", payloads[1].Text)
+	assert.Equal(t, 1, payloads[1].Seq)
+}
+
+// TestChatElementToolCalls tests that a streamed tool call is published and
+// that the follow-up request carries the submitted result
+func TestChatElementToolCalls(t *testing.T) {
+	requests := make(chan map[string]any, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+
+		chunk := func(delta string) {
+			io.WriteString(w, `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"m",`+
+				`"choices":[{"index":0,"delta":`+delta+`}]}`+"\n\n")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		// Answer with the tool result once there is one
+		messages := body["messages"].([]any)
+		last := messages[len(messages)-1].(map[string]any)
+		if last["role"] == "tool" {
+			result, _ := json.Marshal("The weather in Paris is " + last["content"].(string) + ".")
+			chunk(`{"content":` + string(result) + `}`)
+		} else {
+			chunk(`{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}`)
+			chunk(`{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}`)
+			chunk(`{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}`)
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	chat, err := NewChatElement(ChatConfig{
+		APIKey:    "test-key",
+		Streaming: true,
+		Tools: []ToolDefinition{{
+			Name:        "get_weather",
+			Description: "Get the current weather for a city",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				"required":   []string{"city"},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	p := pipeline.NewPipeline("test-chat-tools")
+	p.AddElement(chat)
+	calls := make(chan pipeline.Event, 1)
+	p.Bus().Subscribe(pipeline.EventToolCall, calls)
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	require.NoError(t, p.PushText("test-session", "What's the weather in Paris?"))
+
+	body := <-requests
+	tools := body["tools"].([]any)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "get_weather", tools[0].(map[string]any)["function"].(map[string]any)["name"])
+
+	var call *pipeline.ToolCallPayload
+	select {
+	case evt := <-calls:
+		call = evt.Payload.(*pipeline.ToolCallPayload)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for tool call")
+	}
+	assert.Equal(t, "call_1", call.CallID)
+	assert.Equal(t, "get_weather", call.Name)
+	assert.JSONEq(t, `{"city":"Paris"}`, call.Arguments)
+
+	assert.Error(t, chat.SubmitToolResult("call_unknown", "sunny"))
+	require.NoError(t, chat.SubmitToolResult(call.CallID, "sunny and 22°C"))
+
+	// The follow-up request carries the tool call and its result
+	select {
+	case body = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for follow-up request")
+	}
+	messages := body["messages"].([]any)
+	assistant := messages[len(messages)-2].(map[string]any)
+	assert.Equal(t, "call_1", assistant["tool_calls"].([]any)[0].(map[string]any)["id"])
+	result := messages[len(messages)-1].(map[string]any)
+	assert.Equal(t, "call_1", result["tool_call_id"])
+	assert.Equal(t, "sunny and 22°C", result["content"])
+
+	// The answer uses the result
+	msg, err := p.PullTimeout(5 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "The weather in Paris is sunny and 22°C.", string(msg.TextData.Data))
+	assert.Equal(t, ChatRoleTool, chat.GetHistory()[2].Role)
+}
+
+// TestChatElementToolCallCancelled tests that a new user turn cancels pending tool calls
+func TestChatElementToolCallCancelled(t *testing.T) {
+	requests := make(chan map[string]any, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"m",`+
+			`"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":null,`+
+			`"tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup_order","arguments":"{}"}}]}}]}`)
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	chat, err := NewChatElement(ChatConfig{
+		APIKey: "test-key",
+		Tools:  []ToolDefinition{{Name: "lookup_order"}},
+	})
+	require.NoError(t, err)
+
+	p := pipeline.NewPipeline("test-chat-tools-cancel")
+	p.AddElement(chat)
+	cancelled := make(chan pipeline.Event, 1)
+	p.Bus().Subscribe(pipeline.EventToolCallCancelled, cancelled)
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	require.NoError(t, p.PushText("test-session", "Where is my order?"))
+	<-requests
+	require.NoError(t, p.PushText("test-session", "Never mind"))
+
+	select {
+	case evt := <-cancelled:
+		assert.Equal(t, []string{"call_1"}, evt.Payload.(*pipeline.ToolCallCancelledPayload).CallIDs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for tool call cancellation")
+	}
+
+	body := <-requests
+	messages := body["messages"].([]any)
+	result := messages[len(messages)-2].(map[string]any)
+	assert.Equal(t, "tool", result["role"])
+	assert.Equal(t, toolCancelledResult, result["content"])
+	assert.Error(t, chat.SubmitToolResult("call_1", "shipped"))
+}
+
+// TestChatElementToolCallInterrupted tests that an interrupt during a pending
+// tool call keeps the tool reply right after the assistant's tool call
+func TestChatElementToolCallInterrupted(t *testing.T) {
+	requests := make(chan map[string]any, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"m",`+
+			`"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":null,`+
+			`"tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup_order","arguments":"{}"}}]}}]}`)
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	chat, err := NewChatElement(ChatConfig{
+		APIKey: "test-key",
+		Tools:  []ToolDefinition{{Name: "lookup_order"}},
+	})
+	require.NoError(t, err)
+
+	p := pipeline.NewPipeline("test-chat-tools-interrupt")
+	p.AddElement(chat)
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	require.NoError(t, p.PushText("test-session", "Where is my order?"))
+	<-requests
+
+	// The user barges in while the tool call is pending
+	p.Bus().Publish(pipeline.Event{
+		Type: pipeline.EventInterruptRecovery,
+		Payload: &pipeline.InterruptRecoveryPayload{
+			Policy:          pipeline.InterruptRecoveryPreserveContext,
+			InterruptedText: "Let me check",
+		},
+	})
+	require.Eventually(t, func() bool {
+		chat.mu.RLock()
+		defer chat.mu.RUnlock()
+		return chat.interruptNote != ""
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, p.PushText("test-session", "Actually, cancel it"))
+
+	var body map[string]any
+	select {
+	case body = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for chat request")
+	}
+	messages := body["messages"].([]any)
+	require.GreaterOrEqual(t, len(messages), 4)
+	roles := make([]any, 0, 4)
+	for _, m := range messages[len(messages)-4:] {
+		roles = append(roles, m.(map[string]any)["role"])
+	}
+	assert.Equal(t, []any{"assistant", "tool", "system", "user"}, roles)
+	assert.Equal(t, "call_1", messages[len(messages)-3].(map[string]any)["tool_call_id"])
+}
+
+// TestChatElementResponseTimeout tests the fallback spoken when the stream stalls
+func TestChatElementResponseTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"m",`+
+			`"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello there. "}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		// Stall until the client gives up
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+
+	chat, err := NewChatElement(ChatConfig{
+		APIKey:          "test-key",
+		Streaming:       true,
+		ResponseTimeout: 200 * time.Millisecond,
+		FallbackText:    "One moment.",
+	})
+	require.NoError(t, err)
+
+	p := pipeline.NewPipeline("test-chat-timeout")
+	p.AddElement(chat)
+
+	events := make(chan pipeline.Event, 1)
+	p.Bus().Subscribe(pipeline.EventResponseTimeout, events)
+	errs := make(chan pipeline.Event, 1)
+	p.Bus().Subscribe(pipeline.EventError, errs)
+
+	require.NoError(t, p.Start(context.Background()))
+	defer p.Stop()
+
+	msg := &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeData,
+		SessionID: "test-session",
+		TextData:  &pipeline.TextData{Data: []byte("Hello"), TextType: "text/plain"},
+	}
+	msg.SetAttribute(pipeline.AttrRequestID, "req-1")
+	p.Push(msg)
+
+	first := p.Pull()
+	assert.Equal(t, "Hello there.", strings.TrimSpace(string(first.TextData.Data)))
+
+	fallback := p.Pull()
+	assert.Equal(t, "One moment.", string(fallback.TextData.Data))
+	assert.Equal(t, "final", fallback.TextData.TextType)
+
+	select {
+	case evt := <-events:
+		payload, ok := evt.Payload.(pipeline.ResponseTimeoutPayload)
+		require.True(t, ok)
+		assert.Equal(t, 200*time.Millisecond, payload.Timeout)
+		assert.Equal(t, "One moment.", payload.Fallback)
+		assert.Equal(t, "req-1", evt.Attributes.String(pipeline.AttrRequestID))
+	case <-time.After(5 * time.Second):
+		t.Fatal("EventResponseTimeout not published")
+	}
+
+	select {
+	case evt := <-errs:
+		t.Fatalf("unexpected error event: %v", evt.Payload)
+	default:
+	}
+
+	// The spoken text, including the fallback, is kept as the assistant turn
+	require.Eventually(t, func() bool { return chat.GetHistoryLength() == 2 }, time.Second, 10*time.Millisecond)
+	history := chat.GetHistory()
+	assert.Equal(t, ChatMessage{Role: "assistant", Content: "Hello there. One moment."}, history[1])
+}
+
 // TestChatElementWithRealAPI tests with real OpenAI API (skipped if no key)
 func TestChatElementWithRealAPI(t *testing.T) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
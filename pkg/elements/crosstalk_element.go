@@ -0,0 +1,432 @@
+// Package elements provides pipeline processing elements.
+//
+// CrosstalkElement 抑制助手自身语音回灌到麦克风造成的自我打断。
+// 免提通话时 TTS 声音会从扬声器漏进麦克风，即使有回声消除，残留回声仍可能被
+// VAD 当成用户说话而打断助手。
+//
+// 工作原理:
+//   - 输出链路上的参考分支（ReferenceTap）记录助手实际播放的音频
+//   - 麦克风音频与参考音频按 10ms 能量包络做相关，搜索 0..MaxDelayMs 的回声延迟
+//   - 相关系数超过阈值时认为麦克风里主要是我们自己的声音，该帧替换为静音，VAD 不会触发
+//   - 用户与助手同时说话（双讲）时相关性下降，音频原样透传，仍可正常打断
+//
+// 使用示例:
+//
+//	crosstalk := NewCrosstalkElement()
+//	p.Link(input, crosstalk)
+//	p.Link(crosstalk, vad)
+//	// 输出链路：TTS -> ReferenceTap -> sink
+//	tap := crosstalk.ReferenceTap()
+//	p.Link(tts, tap)
+//	p.Link(tap, sink)
+package elements
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+// Make sure CrosstalkElement implements pipeline.Element
+var _ pipeline.Element = (*CrosstalkElement)(nil)
+
+const (
+	crosstalkSlot     = 10 * time.Millisecond // 能量包络的时间粒度
+	crosstalkFloorDB  = -90.0                 // 无参考音频时的能量
+	crosstalkMinRange = 6.0                   // 麦克风包络起伏小于该值（dB）时不做判断
+	crosstalkHorizon  = 60 * time.Second      // 参考音频最多提前排队的时长
+)
+
+// CrosstalkConfig 串音检测配置
+type CrosstalkConfig struct {
+	Threshold  float64 // 包络相关系数阈值，超过即视为回声（默认 0.8）
+	WindowMs   int     // 相关窗口长度（默认 500ms）
+	MaxDelayMs int     // 搜索的最大回声延迟，包含播放和采集延迟（默认 300ms）
+}
+
+// DefaultCrosstalkConfig 返回默认配置
+func DefaultCrosstalkConfig() CrosstalkConfig {
+	return CrosstalkConfig{
+		Threshold:  0.8,
+		WindowMs:   500,
+		MaxDelayMs: 300,
+	}
+}
+
+// CrosstalkElement 检测并抑制与助手输出高度相关的麦克风音频
+type CrosstalkElement struct {
+	*pipeline.BaseElement
+
+	config CrosstalkConfig
+
+	mu        sync.Mutex
+	refEnv    envelopeRing // 参考音频（按播放时间）的能量包络
+	micEnv    envelopeRing // 麦克风音频（按到达时间）的能量包络
+	refCursor time.Time    // 已排队参考音频的播放结束时间
+	micCursor time.Time    // 上一帧麦克风音频的结束时间
+
+	suppressed atomic.Int64 // 被抑制的麦克风帧数
+
+	now func() time.Time // 便于测试替换
+
+	interruptCh chan pipeline.Event
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewCrosstalkElement 使用默认配置创建 CrosstalkElement
+func NewCrosstalkElement() *CrosstalkElement {
+	return NewCrosstalkElementWithConfig(DefaultCrosstalkConfig())
+}
+
+// NewCrosstalkElementWithConfig 使用自定义配置创建 CrosstalkElement
+func NewCrosstalkElementWithConfig(cfg CrosstalkConfig) *CrosstalkElement {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 0.8
+	}
+	if cfg.WindowMs <= 0 {
+		cfg.WindowMs = 500
+	}
+	if cfg.MaxDelayMs < 0 {
+		cfg.MaxDelayMs = 0
+	}
+
+	// 麦克风包络只需覆盖一个窗口；参考包络还要容纳提前排队、尚未播放的部分
+	slots := (cfg.WindowMs+cfg.MaxDelayMs)/int(crosstalkSlot/time.Millisecond) + 1
+	return &CrosstalkElement{
+		BaseElement: pipeline.NewBaseElement("crosstalk-element", 100),
+		config:      cfg,
+		refEnv:      newEnvelopeRing(slots + int(crosstalkHorizon/crosstalkSlot)),
+		micEnv:      newEnvelopeRing(slots),
+		now:         time.Now,
+	}
+}
+
+// ReferenceTap 返回放在输出链路上的透传元素，记录助手播放的音频作为参考信号
+// 应放在 sink 之前、尽量靠近实际播放的位置
+func (e *CrosstalkElement) ReferenceTap() pipeline.Element {
+	return &crosstalkReferenceTap{
+		BaseElement: pipeline.NewBaseElement("crosstalk-reference-tap", 100),
+		detector:    e,
+	}
+}
+
+// SuppressedFrames 返回因串音被替换为静音的麦克风帧数
+func (e *CrosstalkElement) SuppressedFrames() int64 {
+	return e.suppressed.Load()
+}
+
+func (e *CrosstalkElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	if e.Bus() != nil {
+		e.interruptCh = make(chan pipeline.Event, 10)
+		e.Bus().Subscribe(pipeline.EventInterrupted, e.interruptCh)
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.process(ctx)
+	}()
+
+	return nil
+}
+
+func (e *CrosstalkElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	if e.interruptCh != nil {
+		e.Bus().Unsubscribe(pipeline.EventInterrupted, e.interruptCh)
+		e.interruptCh = nil
+	}
+	return nil
+}
+
+func (e *CrosstalkElement) process(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.interruptCh:
+			// 打断后尚未播放的参考音频不会再播放
+			e.clearPendingReference()
+		case msg := <-e.BaseElement.InChan:
+			if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil && isPCMMediaType(msg.AudioData.MediaType) {
+				if e.pushMic(msg.AudioData) {
+					e.suppressed.Add(1)
+					msg = silencedMessage(msg)
+				}
+			}
+
+			select {
+			case e.BaseElement.OutChan <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// pushReference 记录一段即将播放的参考音频
+// 音频可能快于实时下发，按顺序排在已排队音频之后播放
+func (e *CrosstalkElement) pushReference(data *pipeline.AudioData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := e.now()
+	if e.refCursor.After(start) {
+		start = e.refCursor
+	}
+	e.refCursor = appendEnvelope(&e.refEnv, data, start)
+}
+
+// pushMic 记录一帧麦克风音频，返回该帧是否为串音
+func (e *CrosstalkElement) pushMic(data *pipeline.AudioData) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	start := now.Add(-audioDuration(data))
+	if e.micCursor.After(start) {
+		start = e.micCursor
+	}
+	e.micCursor = appendEnvelope(&e.micEnv, data, start)
+
+	return e.isCrosstalkLocked(slotOf(e.micCursor) - 1)
+}
+
+// isCrosstalkLocked 判断以 end 结束的麦克风窗口是否与参考音频高度相关
+func (e *CrosstalkElement) isCrosstalkLocked(end int64) bool {
+	window := e.config.WindowMs / int(crosstalkSlot/time.Millisecond)
+	maxLag := e.config.MaxDelayMs / int(crosstalkSlot/time.Millisecond)
+	if window < 2 {
+		return false
+	}
+
+	mic := make([]float64, window)
+	for i := range mic {
+		v, ok := e.micEnv.get(end - int64(window-1-i))
+		if !ok {
+			return false // 麦克风音频还不够一个窗口
+		}
+		mic[i] = v
+	}
+	if spread(mic) < crosstalkMinRange {
+		return false
+	}
+
+	ref := make([]float64, window)
+	for lag := 0; lag <= maxLag; lag++ {
+		active := false
+		for i := range ref {
+			v, ok := e.refEnv.get(end - int64(lag) - int64(window-1-i))
+			if !ok {
+				v = crosstalkFloorDB
+			} else {
+				active = true
+			}
+			ref[i] = v
+		}
+		if active && pearson(mic, ref) >= e.config.Threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// clearPendingReference 丢弃尚未播放的参考音频
+func (e *CrosstalkElement) clearPendingReference() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	for slot := slotOf(now); slot < slotOf(e.refCursor); slot++ {
+		e.refEnv.clear(slot)
+	}
+	if e.refCursor.After(now) {
+		e.refCursor = now
+	}
+}
+
+// crosstalkReferenceTap 透传输出音频，并把 PCM 音频记录为参考信号
+type crosstalkReferenceTap struct {
+	*pipeline.BaseElement
+
+	detector *CrosstalkElement
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (t *crosstalkReferenceTap) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-t.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil && isPCMMediaType(msg.AudioData.MediaType) {
+					t.detector.pushReference(msg.AudioData)
+				}
+
+				select {
+				case t.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *crosstalkReferenceTap) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+		t.wg.Wait()
+		t.cancel = nil
+	}
+	return nil
+}
+
+// envelopeRing 按时间槽保存最近的能量包络（dBFS）
+type envelopeRing struct {
+	values []float64
+	slots  []int64
+}
+
+func newEnvelopeRing(size int) envelopeRing {
+	slots := make([]int64, size)
+	for i := range slots {
+		slots[i] = -1
+	}
+	return envelopeRing{values: make([]float64, size), slots: slots}
+}
+
+func (r *envelopeRing) set(slot int64, v float64) {
+	i := slot % int64(len(r.values))
+	r.values[i] = v
+	r.slots[i] = slot
+}
+
+func (r *envelopeRing) get(slot int64) (float64, bool) {
+	if slot < 0 {
+		return 0, false
+	}
+	i := slot % int64(len(r.values))
+	if r.slots[i] != slot {
+		return 0, false
+	}
+	return r.values[i], true
+}
+
+func (r *envelopeRing) clear(slot int64) {
+	if i := slot % int64(len(r.values)); r.slots[i] == slot {
+		r.slots[i] = -1
+	}
+}
+
+// appendEnvelope 把从 start 开始的音频按 10ms 槽计算能量写入包络，返回音频结束时间
+func appendEnvelope(ring *envelopeRing, data *pipeline.AudioData, start time.Time) time.Time {
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	samples := utils.ByteSliceToInt16Slice(data.Data)
+	perSlot := data.SampleRate * int(crosstalkSlot/time.Millisecond) / 1000 * channels
+	if perSlot <= 0 {
+		return start
+	}
+
+	slot := slotOf(start)
+	for i := 0; i+perSlot <= len(samples); i += perSlot {
+		var sum float64
+		for _, s := range samples[i : i+perSlot] {
+			v := float64(s) / 32768
+			sum += v * v
+		}
+		db := crosstalkFloorDB
+		if rms := math.Sqrt(sum / float64(perSlot)); rms > 0 {
+			db = math.Max(20*math.Log10(rms), crosstalkFloorDB)
+		}
+		ring.set(slot, db)
+		slot++
+	}
+	return start.Add(audioDuration(data))
+}
+
+// audioDuration 返回 PCM 音频的时长
+func audioDuration(data *pipeline.AudioData) time.Duration {
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	if data.SampleRate <= 0 {
+		return 0
+	}
+	frames := len(data.Data) / 2 / channels
+	return time.Duration(frames) * time.Second / time.Duration(data.SampleRate)
+}
+
+// slotOf 返回时间所在的 10ms 槽
+func slotOf(t time.Time) int64 {
+	return t.UnixNano() / int64(crosstalkSlot)
+}
+
+// silencedMessage 返回音频替换为静音的消息副本
+func silencedMessage(msg *pipeline.PipelineMessage) *pipeline.PipelineMessage {
+	audioData := *msg.AudioData
+	audioData.Data = make([]byte, len(msg.AudioData.Data))
+	out := *msg
+	out.AudioData = &audioData
+	return &out
+}
+
+// spread 返回最大值与最小值之差
+func spread(values []float64) float64 {
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return hi - lo
+}
+
+// pearson 返回两个等长序列的皮尔逊相关系数
+func pearson(a, b []float64) float64 {
+	n := float64(len(a))
+	var sumA, sumB float64
+	for i := range a {
+		sumA += a[i]
+		sumB += b[i]
+	}
+	meanA, meanB := sumA/n, sumB/n
+
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
@@ -0,0 +1,159 @@
+package elements
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const crosstalkTestRate = 16000
+
+// speechLike 生成带音节式能量起伏的测试音频
+func speechLike(seed int64, d time.Duration, gain float64) []int16 {
+	rng := rand.New(rand.NewSource(seed))
+	n := int(d.Seconds() * crosstalkTestRate)
+	out := make([]int16, n)
+	syllable := crosstalkTestRate / 20 // 50ms
+	amp := 0.0
+	for i := range out {
+		if i%syllable == 0 {
+			amp = rng.Float64() * rng.Float64()
+		}
+		v := amp * gain * math.Sin(2*math.Pi*220*float64(i)/crosstalkTestRate)
+		out[i] = int16(v * 32767)
+	}
+	return out
+}
+
+func pcmData(samples []int16) *pipeline.AudioData {
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		data[2*i] = byte(s)
+		data[2*i+1] = byte(uint16(s) >> 8)
+	}
+	return &pipeline.AudioData{
+		Data:       data,
+		SampleRate: crosstalkTestRate,
+		Channels:   1,
+		MediaType:  pipeline.AudioMediaTypeRaw,
+	}
+}
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+// feedMic 按 20ms 一帧模拟麦克风实时到达，返回被判定为串音的帧数
+func feedMic(e *CrosstalkElement, clock *fakeClock, samples []int16) (suppressed, total int) {
+	frame := crosstalkTestRate / 50
+	for i := 0; i+frame <= len(samples); i += frame {
+		clock.t = clock.t.Add(20 * time.Millisecond)
+		if e.pushMic(pcmData(samples[i : i+frame])) {
+			suppressed++
+		}
+		total++
+	}
+	return suppressed, total
+}
+
+func TestCrosstalk_SuppressesEcho(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	e := NewCrosstalkElement()
+	e.now = clock.now
+
+	ref := speechLike(1, 2*time.Second, 0.8)
+	e.pushReference(pcmData(ref))
+
+	// 回声延迟 120ms，衰减并叠加少量噪声
+	clock.t = clock.t.Add(120 * time.Millisecond)
+	rng := rand.New(rand.NewSource(7))
+	mic := make([]int16, len(ref))
+	for i, s := range ref {
+		mic[i] = int16(float64(s)*0.2 + rng.NormFloat64()*30)
+	}
+
+	suppressed, total := feedMic(e, clock, mic)
+	// 第一个窗口积累完成后，绝大多数帧应被抑制
+	assert.Greater(t, suppressed, total*3/4, "suppressed %d of %d", suppressed, total)
+}
+
+func TestCrosstalk_PassesIndependentSpeech(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	e := NewCrosstalkElement()
+	e.now = clock.now
+
+	e.pushReference(pcmData(speechLike(1, 2*time.Second, 0.8)))
+	clock.t = clock.t.Add(120 * time.Millisecond)
+
+	suppressed, total := feedMic(e, clock, speechLike(42, 2*time.Second, 0.5))
+	assert.Less(t, suppressed, total/10, "suppressed %d of %d", suppressed, total)
+}
+
+func TestCrosstalk_NoReferencePassesThrough(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	e := NewCrosstalkElement()
+	e.now = clock.now
+
+	suppressed, _ := feedMic(e, clock, speechLike(1, time.Second, 0.8))
+	assert.Equal(t, 0, suppressed)
+}
+
+func TestCrosstalk_InterruptDropsPendingReference(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	e := NewCrosstalkElement()
+	e.now = clock.now
+
+	ref := speechLike(1, 2*time.Second, 0.8)
+	e.pushReference(pcmData(ref))
+	e.clearPendingReference()
+
+	suppressed, _ := feedMic(e, clock, ref)
+	assert.Equal(t, 0, suppressed)
+	assert.Equal(t, clock.t, e.refCursor.Add(2*time.Second))
+}
+
+func TestCrosstalkElement_SilencesEchoFrames(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	e := NewCrosstalkElement()
+	e.now = clock.now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tap := e.ReferenceTap()
+	require.NoError(t, tap.Start(ctx))
+	defer tap.Stop()
+	require.NoError(t, e.Start(ctx))
+	defer e.Stop()
+
+	ref := speechLike(1, time.Second, 0.8)
+	tap.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeAudio, AudioData: pcmData(ref)}
+	select {
+	case <-tap.Out():
+	case <-time.After(time.Second):
+		t.Fatal("reference tap did not pass audio through")
+	}
+
+	frame := crosstalkTestRate / 50
+	var last *pipeline.PipelineMessage
+	for i := 0; i+frame <= len(ref); i += frame {
+		// 时钟只在两帧之间推进，element 协程读取时不会与写入竞争
+		clock.t = clock.t.Add(20 * time.Millisecond)
+		e.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeAudio, AudioData: pcmData(ref[i : i+frame])}
+		select {
+		case last = <-e.Out():
+		case <-time.After(time.Second):
+			t.Fatal("crosstalk element did not forward audio")
+		}
+	}
+
+	require.NotNil(t, last)
+	assert.Greater(t, e.SuppressedFrames(), int64(0))
+	assert.Equal(t, make([]byte, frame*2), last.AudioData.Data)
+}
@@ -0,0 +1,160 @@
+// Package elements provides pipeline processing elements.
+//
+// DeepgramRealtimeSTTElement wraps asr.DeepgramProvider as a realtime STT
+// element. It shares the realtime STT base with the ElevenLabs element, so
+// partial throttling, early commit, the result timeout and transcript events
+// work the same way.
+//
+// Usage:
+//
+//	stt, err := elements.NewDeepgramRealtimeSTTElement(elements.DeepgramRealtimeSTTConfig{
+//		Language:             "en",
+//		EnablePartialResults: true,
+//		VADEnabled:           true,
+//	})
+package elements
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Ensure DeepgramRealtimeSTTElement implements pipeline.Element
+var _ pipeline.Element = (*DeepgramRealtimeSTTElement)(nil)
+var _ pipeline.InputResetter = (*DeepgramRealtimeSTTElement)(nil)
+
+// DeepgramRealtimeSTTElement implements speech-to-text using Deepgram's streaming API (Nova-3).
+// It provides low-latency streaming ASR via WebSocket, well suited to English phone audio.
+// Supports interim and final transcripts; with VAD the speech end commits the utterance,
+// otherwise Deepgram's endpointing decides when it is final.
+type DeepgramRealtimeSTTElement struct {
+	*realtimeSTTBase
+}
+
+// DeepgramRealtimeSTTConfig holds configuration for DeepgramRealtimeSTTElement.
+type DeepgramRealtimeSTTConfig struct {
+	// APIKey is the Deepgram API key (if empty, will use DEEPGRAM_API_KEY env var)
+	APIKey string
+
+	// Language code (e.g., "en", "zh", "auto" for auto-detection)
+	// Leave empty to use the pipeline LanguageContext source language,
+	// or auto-detection if none is set
+	Language string
+
+	// Model to use (default: "nova-3")
+	Model string
+
+	// EnablePartialResults enables interim results during recognition
+	EnablePartialResults bool
+
+	// VADEnabled determines if element should listen to VAD events
+	// When true, audio is sent while the user speaks and the speech end
+	// commits the utterance; Deepgram's endpointing is disabled unless
+	// EndpointingMs is set
+	// When false, audio is sent continuously and Deepgram's endpointing
+	// decides when an utterance is final
+	VADEnabled bool
+
+	// EndpointingMs is the trailing silence Deepgram waits for before
+	// finalizing an utterance (default: 0, Deepgram's default; negative
+	// disables endpointing, so finals only follow Commit)
+	EndpointingMs int
+
+	// SampleRate in Hz (default: 16000; 8000 for telephone audio)
+	SampleRate int
+
+	// Channels (default: 1 for mono)
+	Channels int
+
+	// BitsPerSample (default: 16)
+	BitsPerSample int
+
+	// ResultTimeout is how long to wait for a final transcript after a commit
+	// before publishing EventNoResult (default: DefaultSTTResultTimeout, negative disables)
+	ResultTimeout time.Duration
+
+	// PartialIntervalMs coalesces partial results: at most one partial, the
+	// latest, is emitted per interval. Reduces downstream churn when the
+	// provider sends partials very frequently (default: 0, emit every partial)
+	PartialIntervalMs int
+
+	// EarlyCommitStableMs commits a partial as the final transcript once it
+	// has stayed unchanged for this long, instead of waiting for the
+	// provider's final. This shaves the provider's finalization latency off
+	// every turn. Later partials of the utterance are dropped; the provider's
+	// final is dropped if it matches the committed text, and otherwise sent as
+	// a "text/final" carrying STTCorrection metadata (default: 0, disabled)
+	EarlyCommitStableMs int
+
+	// EarlyCommitMinConfidence is the minimum partial confidence for an early
+	// commit. Results without a confidence score only qualify when this is 0
+	EarlyCommitMinConfidence float32
+
+	// Headers are extra headers sent on every provider request (API gateway
+	// keys, org IDs, tracing headers)
+	Headers map[string]string
+}
+
+// NewDeepgramRealtimeSTTElement creates a new Deepgram Realtime STT element.
+func NewDeepgramRealtimeSTTElement(config DeepgramRealtimeSTTConfig) (*DeepgramRealtimeSTTElement, error) {
+	// Get API key from config or environment
+	apiKey := config.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("DEEPGRAM_API_KEY")
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("Deepgram API key is required (set APIKey or DEEPGRAM_API_KEY env var)")
+	}
+
+	// The VAD decides when the user is done, so Deepgram should not finalize on its own
+	endpointing := config.EndpointingMs
+	if config.VADEnabled && endpointing == 0 {
+		endpointing = -1
+	}
+
+	// Create Deepgram provider
+	provider, err := asr.NewDeepgramProvider(asr.DeepgramConfig{
+		APIKey:        apiKey,
+		Model:         config.Model,
+		EndpointingMs: endpointing,
+		Headers:       config.Headers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Deepgram provider: %w", err)
+	}
+
+	// Set defaults and validate
+	if config.SampleRate == 0 {
+		config.SampleRate = 16000
+	}
+	if config.Channels == 0 {
+		config.Channels = 1
+	}
+	if config.BitsPerSample == 0 {
+		config.BitsPerSample = 16
+	}
+	if config.BitsPerSample != 16 {
+		return nil, fmt.Errorf("Deepgram streaming expects 16-bit PCM, got %d bits", config.BitsPerSample)
+	}
+
+	base := newRealtimeSTTBase("deepgram-realtime-stt", "DeepgramSTT", provider, realtimeSTTConfig{
+		Language:                 config.Language,
+		Model:                    config.Model,
+		EnablePartialResults:     config.EnablePartialResults,
+		VADEnabled:               config.VADEnabled,
+		SampleRate:               config.SampleRate,
+		Channels:                 config.Channels,
+		BitsPerSample:            config.BitsPerSample,
+		ResultTimeout:            config.ResultTimeout,
+		PartialIntervalMs:        config.PartialIntervalMs,
+		EarlyCommitStableMs:      config.EarlyCommitStableMs,
+		EarlyCommitMinConfidence: config.EarlyCommitMinConfidence,
+	})
+
+	return &DeepgramRealtimeSTTElement{realtimeSTTBase: base}, nil
+}
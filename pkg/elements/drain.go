@@ -0,0 +1,36 @@
+// Package elements provides pipeline processing elements.
+//
+// 元素实现 pipeline.Drainer 的公共辅助函数。
+// Pipeline.StopGraceful 调用各元素的 Drain，等待其内部缓冲和处理中的请求
+// （TTS 合成、音频播放队列）清空后再停止；waitUntil 负责轮询等待。
+package elements
+
+import (
+	"context"
+	"time"
+)
+
+// drainPollInterval Drain 检查缓冲是否清空的间隔
+const drainPollInterval = 10 * time.Millisecond
+
+// waitUntil 轮询直到 done 连续两次返回 true 或 ctx 到期，供各元素实现 pipeline.Drainer
+// 连续两次是为了跳过元素刚从 InChan 取出消息、尚未计入处理中的瞬间
+func waitUntil(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	idle := 0
+	for {
+		if !done() {
+			idle = 0
+		} else if idle++; idle >= 2 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
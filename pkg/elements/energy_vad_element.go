@@ -0,0 +1,403 @@
+// Package elements provides pipeline processing elements.
+//
+// EnergyVADElement is a pure-Go voice activity detector based on short-term
+// energy and zero-crossing rate. It needs no ONNX model or build tags, so it
+// can stand in for SileroVADElement to provide turn detection and interrupt
+// support out of the box. It is less accurate than Silero on noisy input.
+//
+// Features:
+//   - 20ms frames: speech when energy is above EnergyThresholdDB and the
+//     zero-crossing rate is below MaxZeroCrossingRate (hiss and broadband
+//     noise cross zero much more often than voiced speech); frames 15dB above
+//     the threshold count as speech regardless of ZCR
+//   - Speech starts after MinSpeechMs of speech frames and ends after
+//     MinSilenceMs of non-speech, optionally held back by HangoverMs
+//   - Emits EventVADSpeechStart (with pre-roll audio) and EventVADSpeechEnd
+//     with the same VADPayload as SileroVADElement
+//   - Passthrough and filter modes, 16kHz s16/f32 PCM
+//
+// Example:
+//
+//	vadElem := NewEnergyVADElement(DefaultEnergyVADConfig())
+//	p.Link(resample, vadElem)
+//	p.Link(vadElem, stt)
+package elements
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure EnergyVADElement implements pipeline.Element and pipeline.InputResetter
+var (
+	_ pipeline.Element       = (*EnergyVADElement)(nil)
+	_ pipeline.InputResetter = (*EnergyVADElement)(nil)
+)
+
+const (
+	energyVADSampleRate = 16000
+	energyVADFrameMs    = 20
+	// energyVADLoudMargin is how far above the threshold a frame counts as
+	// speech regardless of its zero-crossing rate
+	energyVADLoudMargin = 15.0
+)
+
+// EnergyVADConfig holds configuration for the energy-based VAD
+type EnergyVADConfig struct {
+	// EnergyThresholdDB is the frame RMS level (dBFS) above which a frame may
+	// be speech (default -40)
+	EnergyThresholdDB float64
+	// MaxZeroCrossingRate is the highest fraction of sign changes per sample
+	// a quiet speech frame may have (default 0.3)
+	MaxZeroCrossingRate float64
+	// MinSpeechMs is how long speech frames must last before speech start is
+	// emitted (default 100ms)
+	MinSpeechMs int
+	// MinSilenceMs is how long non-speech must last before speech end is
+	// emitted (default 400ms)
+	MinSilenceMs int
+	// HangoverMs holds back speech end for this long after MinSilenceMs, as
+	// in SileroVADConfig. 0 disables aggregation.
+	HangoverMs int
+	// PreRollMs is the audio kept before speech start (default 300ms)
+	PreRollMs int
+	Mode      VADMode
+}
+
+// DefaultEnergyVADConfig returns the default configuration
+func DefaultEnergyVADConfig() EnergyVADConfig {
+	return EnergyVADConfig{
+		EnergyThresholdDB:   -40,
+		MaxZeroCrossingRate: 0.3,
+		MinSpeechMs:         100,
+		MinSilenceMs:        400,
+		PreRollMs:           300,
+		Mode:                VADModePassthrough,
+	}
+}
+
+// EnergyVADElement implements voice activity detection from frame energy and
+// zero-crossing rate
+type EnergyVADElement struct {
+	*pipeline.BaseElement
+
+	stateLock sync.Mutex
+	config    EnergyVADConfig
+
+	isSpeaking  atomic.Bool
+	audioBuffer []float32
+
+	// Pre-roll buffer for capturing audio before speech detection
+	preRollBuffer *audio.RingBuffer
+
+	// Detection state, in samples
+	currSample    int
+	speechRun     int // consecutive speech samples while not speaking
+	silenceRun    int // consecutive non-speech samples while speaking
+	speechStarted int // sample where the current speech run began
+	pendingEnd    int // speech end waiting out the hangover window (0 = none)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEnergyVADElement creates an energy-based VAD element. Zero fields take
+// their defaults.
+func NewEnergyVADElement(config EnergyVADConfig) *EnergyVADElement {
+	defaults := DefaultEnergyVADConfig()
+	if config.EnergyThresholdDB == 0 {
+		config.EnergyThresholdDB = defaults.EnergyThresholdDB
+	}
+	if config.MaxZeroCrossingRate <= 0 {
+		config.MaxZeroCrossingRate = defaults.MaxZeroCrossingRate
+	}
+	if config.MinSpeechMs <= 0 {
+		config.MinSpeechMs = defaults.MinSpeechMs
+	}
+	if config.MinSilenceMs <= 0 {
+		config.MinSilenceMs = defaults.MinSilenceMs
+	}
+	if config.HangoverMs < 0 {
+		config.HangoverMs = 0
+	}
+	if config.PreRollMs <= 0 {
+		config.PreRollMs = defaults.PreRollMs
+	}
+
+	elem := &EnergyVADElement{
+		BaseElement:   pipeline.NewBaseElement("energy-vad-element", 100),
+		config:        config,
+		audioBuffer:   make([]float32, 0, 1024),
+		preRollBuffer: audio.NewRingBuffer(energyVADSampleRate, config.PreRollMs),
+	}
+
+	for _, prop := range []pipeline.PropertyDesc{
+		{Name: "energy-threshold-db", Type: reflect.TypeOf(float64(0)), Writable: true, Readable: true, Default: config.EnergyThresholdDB},
+		{Name: "min-speech-ms", Type: reflect.TypeOf(int(0)), Writable: true, Readable: true, Default: config.MinSpeechMs},
+		{Name: "min-silence-ms", Type: reflect.TypeOf(int(0)), Writable: true, Readable: true, Default: config.MinSilenceMs},
+		{Name: "hangover-ms", Type: reflect.TypeOf(int(0)), Writable: true, Readable: true, Default: config.HangoverMs},
+	} {
+		elem.RegisterProperty(prop)
+	}
+
+	return elem
+}
+
+// SetProperty updates a property; changes apply from the next frame
+func (e *EnergyVADElement) SetProperty(name string, value interface{}) error {
+	e.stateLock.Lock()
+	defer e.stateLock.Unlock()
+
+	if err := e.BaseElement.SetProperty(name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "energy-threshold-db":
+		e.config.EnergyThresholdDB = value.(float64)
+	case "min-speech-ms":
+		e.config.MinSpeechMs = value.(int)
+	case "min-silence-ms":
+		e.config.MinSilenceMs = value.(int)
+	case "hangover-ms":
+		e.config.HangoverMs = value.(int)
+	}
+	return nil
+}
+
+// GetProperty returns a property value
+func (e *EnergyVADElement) GetProperty(name string) (interface{}, error) {
+	e.stateLock.Lock()
+	defer e.stateLock.Unlock()
+	return e.BaseElement.GetProperty(name)
+}
+
+// Start starts the VAD element processing
+func (e *EnergyVADElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.processAudio(ctx)
+	}()
+
+	return nil
+}
+
+// Stop stops the VAD element
+func (e *EnergyVADElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+// processAudio is the main audio processing loop
+func (e *EnergyVADElement) processAudio(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.BaseElement.InChan:
+			if msg.Type != pipeline.MsgTypeAudio || msg.AudioData == nil || len(msg.AudioData.Data) == 0 {
+				continue
+			}
+
+			if msg.AudioData.MediaType != pipeline.AudioMediaTypeRaw {
+				log.Printf("[EnergyVAD] Skipping non-raw audio: %s", msg.AudioData.MediaType)
+				continue
+			}
+
+			if msg.AudioData.Format().BytesPerSample() == 0 {
+				log.Printf("[EnergyVAD] Skipping unsupported sample format: %s", msg.AudioData.SampleFormat)
+				continue
+			}
+
+			if msg.AudioData.SampleRate != energyVADSampleRate {
+				log.Printf("[EnergyVAD] Warning: Expected 16kHz audio, got %dHz. Please add AudioResampleElement before VAD.",
+					msg.AudioData.SampleRate)
+				continue
+			}
+
+			e.handleAudioData(ctx, msg)
+		}
+	}
+}
+
+// handleAudioData classifies the frames of one audio message and forwards it
+func (e *EnergyVADElement) handleAudioData(ctx context.Context, msg *pipeline.PipelineMessage) {
+	format := msg.AudioData.Format()
+	samples := audio.BytesToFloat32(msg.AudioData.Data, format)
+
+	// Pre-roll audio is always 16-bit PCM for the STT elements
+	if format == pipeline.SampleFormatS16 {
+		e.preRollBuffer.Write(msg.AudioData.Data)
+	} else {
+		e.preRollBuffer.Write(audio.Float32ToBytes(samples, pipeline.SampleFormatS16))
+	}
+
+	const frameSize = energyVADSampleRate * energyVADFrameMs / 1000
+
+	e.stateLock.Lock()
+	e.audioBuffer = append(e.audioBuffer, samples...)
+	for len(e.audioBuffer) >= frameSize {
+		frame := e.audioBuffer[:frameSize]
+		e.detect(msg.SessionID, frame)
+		e.audioBuffer = e.audioBuffer[frameSize:]
+	}
+	e.audioBuffer = append(e.audioBuffer[:0:0], e.audioBuffer...)
+	e.stateLock.Unlock()
+
+	if e.config.Mode == VADModeFilter && !e.isSpeaking.Load() {
+		return
+	}
+	select {
+	case e.BaseElement.OutChan <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// detect runs the speech state machine on one frame; must hold stateLock
+func (e *EnergyVADElement) detect(sessionID string, frame []float32) {
+	const sampleRate = energyVADSampleRate
+	minSpeech := e.config.MinSpeechMs * sampleRate / 1000
+	minSilence := e.config.MinSilenceMs * sampleRate / 1000
+	hangover := e.config.HangoverMs * sampleRate / 1000
+
+	level := windowDBFS(frame)
+	speech := isSpeechFrame(level, zeroCrossingRate(frame), e.config.EnergyThresholdDB, e.config.MaxZeroCrossingRate)
+	frameStart := e.currSample
+	e.currSample += len(frame)
+
+	if !e.isSpeaking.Load() {
+		if !speech {
+			e.speechRun = 0
+			if e.pendingEnd != 0 && e.currSample-e.pendingEnd >= minSilence+hangover {
+				endSample := e.pendingEnd
+				e.pendingEnd = 0
+				e.emitEvent(pipeline.EventVADSpeechEnd, sessionID, endSample)
+				log.Printf("[EnergyVAD] Speech ended (endMs=%d)", endSample*1000/sampleRate)
+			}
+			return
+		}
+		if e.speechRun == 0 {
+			e.speechStarted = frameStart
+		}
+		e.speechRun += len(frame)
+		if e.speechRun < minSpeech {
+			return
+		}
+
+		e.isSpeaking.Store(true)
+		e.silenceRun = 0
+		if e.pendingEnd != 0 {
+			// Speech resumed within the hangover window: same utterance
+			log.Printf("[EnergyVAD] Pause bridged (%dms)", (e.speechStarted-e.pendingEnd)*1000/sampleRate)
+			e.pendingEnd = 0
+			return
+		}
+		e.emitEvent(pipeline.EventVADSpeechStart, sessionID, e.speechStarted)
+		log.Printf("[EnergyVAD] Speech started (startMs=%d, level=%.1fdBFS)", e.speechStarted*1000/sampleRate, level)
+		return
+	}
+
+	if speech {
+		e.silenceRun = 0
+		return
+	}
+	e.silenceRun += len(frame)
+	if e.silenceRun < minSilence {
+		return
+	}
+
+	e.isSpeaking.Store(false)
+	e.speechRun = 0
+	endSample := e.currSample - e.silenceRun
+	if hangover > 0 {
+		// Hold speech end until the hangover window runs out
+		e.pendingEnd = endSample
+		return
+	}
+	e.emitEvent(pipeline.EventVADSpeechEnd, sessionID, endSample)
+	log.Printf("[EnergyVAD] Speech ended (endMs=%d)", endSample*1000/sampleRate)
+}
+
+// isSpeechFrame classifies a frame from its level (dBFS) and zero-crossing rate
+func isSpeechFrame(levelDB, zcr, thresholdDB, maxZCR float64) bool {
+	if levelDB < thresholdDB {
+		return false
+	}
+	return zcr <= maxZCR || levelDB >= thresholdDB+energyVADLoudMargin
+}
+
+// zeroCrossingRate returns the fraction of adjacent sample pairs that change sign
+func zeroCrossingRate(frame []float32) float64 {
+	if len(frame) < 2 {
+		return 0
+	}
+	crossings := 0
+	for i := 1; i < len(frame); i++ {
+		if (frame[i-1] >= 0) != (frame[i] >= 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / float64(len(frame)-1)
+}
+
+// emitEvent publishes a VAD event at sample position pos. There is no speech
+// probability, so Confidence is 1 for speech start and 0 for speech end.
+func (e *EnergyVADElement) emitEvent(eventType pipeline.EventType, sessionID string, pos int) {
+	if e.Bus() == nil {
+		return
+	}
+
+	payload := pipeline.VADPayload{
+		AudioMs: pos * 1000 / energyVADSampleRate,
+		ItemID:  sessionID,
+	}
+
+	if eventType == pipeline.EventVADSpeechStart {
+		payload.Confidence = 1
+		payload.PreRollAudio = e.preRollBuffer.ReadAll()
+		payload.SampleRate = energyVADSampleRate
+		payload.Channels = 1
+		e.preRollBuffer.Clear()
+	}
+
+	e.Bus().Publish(pipeline.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Payload:   payload,
+	})
+}
+
+// ResetInput discards a partially captured utterance without emitting
+// speech end. Implements pipeline.InputResetter for Pipeline.MuteInput.
+func (e *EnergyVADElement) ResetInput() {
+	e.stateLock.Lock()
+	e.audioBuffer = e.audioBuffer[:0]
+	e.speechRun = 0
+	e.silenceRun = 0
+	e.pendingEnd = 0
+	e.stateLock.Unlock()
+
+	if e.isSpeaking.Swap(false) {
+		log.Printf("[EnergyVAD] Input reset, dropped utterance in progress")
+	}
+	e.preRollBuffer.Clear()
+}
+
+// GetIsSpeaking returns whether speech is currently detected
+func (e *EnergyVADElement) GetIsSpeaking() bool {
+	return e.isSpeaking.Load()
+}
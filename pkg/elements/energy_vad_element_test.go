@@ -0,0 +1,205 @@
+package elements
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateSyntheticSpeech creates 16kHz PCM with speech-like characteristics:
+// three formants amplitude-modulated at about three syllables per second.
+// Same generator as tests/vad/simple.
+func generateSyntheticSpeech(durationSec float64) []byte {
+	const sampleRate = 16000
+	numSamples := int(durationSec * sampleRate)
+	data := make([]byte, numSamples*2)
+
+	for i := 0; i < numSamples; i++ {
+		t := float64(i) / sampleRate
+
+		ampMod := 0.5 + 0.5*math.Sin(2*math.Pi*3*t)
+
+		sample := 0.0
+		sample += 0.5 * math.Sin(2*math.Pi*300*t)
+		sample += 0.3 * math.Sin(2*math.Pi*800*t)
+		sample += 0.2 * math.Sin(2*math.Pi*2500*t)
+		sample *= ampMod
+
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(sample*16000)))
+	}
+
+	return data
+}
+
+// generateWhiteNoise generates white noise at the given RMS level (dBFS)
+func generateWhiteNoise(numSamples int, levelDB float64) []byte {
+	rng := rand.New(rand.NewSource(1))
+	rms := math.Pow(10, levelDB/20) * 32768
+	data := make([]byte, numSamples*2)
+	for i := 0; i < numSamples; i++ {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(rng.NormFloat64()*rms)))
+	}
+	return data
+}
+
+// energyVADHarness feeds audio to an EnergyVADElement and collects its events
+type energyVADHarness struct {
+	elem   *EnergyVADElement
+	events chan pipeline.Event
+}
+
+func newEnergyVADHarness(t *testing.T, config EnergyVADConfig) *energyVADHarness {
+	h := &energyVADHarness{
+		elem:   NewEnergyVADElement(config),
+		events: make(chan pipeline.Event, 20),
+	}
+	bus := pipeline.NewEventBus()
+	bus.Subscribe(pipeline.EventVADSpeechStart, h.events)
+	bus.Subscribe(pipeline.EventVADSpeechEnd, h.events)
+	h.elem.SetBus(bus)
+	return h
+}
+
+// feed sends input in 20ms messages and drains the forwarded audio
+func (h *energyVADHarness) feed(input []byte) (forwarded int) {
+	for off := 0; off < len(input); off += 640 {
+		h.elem.handleAudioData(context.Background(), vadAudioMessage(input[off:off+640]))
+		for len(h.elem.Out()) > 0 {
+			<-h.elem.Out()
+			forwarded++
+		}
+	}
+	return forwarded
+}
+
+func (h *energyVADHarness) next(t *testing.T) (pipeline.EventType, pipeline.VADPayload) {
+	t.Helper()
+	select {
+	case evt := <-h.events:
+		return evt.Type, evt.Payload.(pipeline.VADPayload)
+	default:
+		t.Fatal("expected a VAD event")
+		return "", pipeline.VADPayload{}
+	}
+}
+
+func TestEnergyVADElement_SyntheticSpeech(t *testing.T) {
+	h := newEnergyVADHarness(t, DefaultEnergyVADConfig())
+
+	h.feed(generateSilence(16000))
+	assert.False(t, h.elem.GetIsSpeaking())
+	h.feed(generateSyntheticSpeech(2.0))
+	assert.True(t, h.elem.GetIsSpeaking())
+	h.feed(generateSilence(16000))
+	assert.False(t, h.elem.GetIsSpeaking())
+
+	require.Len(t, h.events, 2, "syllable dips must not split the utterance")
+	eventType, payload := h.next(t)
+	assert.Equal(t, pipeline.EventVADSpeechStart, eventType)
+	assert.InDelta(t, 1000, payload.AudioMs, 40)
+	assert.Equal(t, "test-session", payload.ItemID)
+	assert.Equal(t, 16000, payload.SampleRate)
+	// Pre-roll covers the audio before the start was confirmed
+	assert.Equal(t, 300*16*2, len(payload.PreRollAudio))
+
+	eventType, payload = h.next(t)
+	assert.Equal(t, pipeline.EventVADSpeechEnd, eventType)
+	assert.InDelta(t, 3000, payload.AudioMs, 60)
+}
+
+func TestEnergyVADElement_RejectsNoise(t *testing.T) {
+	h := newEnergyVADHarness(t, DefaultEnergyVADConfig())
+
+	// Hiss above the energy threshold is rejected by its zero-crossing rate
+	h.feed(generateWhiteNoise(32000, -35))
+	assert.Empty(t, h.events)
+
+	// Speech mixed into the same noise is still detected
+	speech := generateSyntheticSpeech(1.0)
+	noise := generateWhiteNoise(16000, -35)
+	for i := 0; i < len(speech); i += 2 {
+		s := int16(binary.LittleEndian.Uint16(speech[i:])) + int16(binary.LittleEndian.Uint16(noise[i:]))
+		binary.LittleEndian.PutUint16(speech[i:], uint16(s))
+	}
+	h.feed(speech)
+	eventType, _ := h.next(t)
+	assert.Equal(t, pipeline.EventVADSpeechStart, eventType)
+}
+
+func TestEnergyVADElement_HangoverAndFilter(t *testing.T) {
+	config := DefaultEnergyVADConfig()
+	config.MinSilenceMs = 100
+	config.HangoverMs = 300
+	config.Mode = VADModeFilter
+	h := newEnergyVADHarness(t, config)
+
+	forwarded := h.feed(generateSilence(8000))
+	assert.Zero(t, forwarded, "filter mode drops silence")
+
+	h.feed(generateTone(8000, 440, 16000))
+	h.feed(generateSilence(3200)) // 200ms pause, bridged by the hangover
+	h.feed(generateTone(8000, 440, 16000))
+	require.Len(t, h.events, 1)
+	h.feed(generateSilence(16000))
+
+	require.Len(t, h.events, 2)
+	eventType, _ := h.next(t)
+	assert.Equal(t, pipeline.EventVADSpeechStart, eventType)
+	eventType, payload := h.next(t)
+	assert.Equal(t, pipeline.EventVADSpeechEnd, eventType)
+	assert.InDelta(t, 1700, payload.AudioMs, 40)
+
+	// Runtime property changes take effect on the next frame
+	require.NoError(t, h.elem.SetProperty("energy-threshold-db", -10.0))
+	h.feed(generateTone(8000, 440, 16000)) // about -13dBFS
+	assert.Empty(t, h.events)
+}
+
+func TestEnergyVADElement_ResetInput(t *testing.T) {
+	h := newEnergyVADHarness(t, DefaultEnergyVADConfig())
+
+	h.feed(generateTone(8000, 440, 16000))
+	require.True(t, h.elem.GetIsSpeaking())
+	eventType, _ := h.next(t)
+	require.Equal(t, pipeline.EventVADSpeechStart, eventType)
+
+	h.elem.ResetInput()
+	assert.False(t, h.elem.GetIsSpeaking())
+	h.feed(generateSilence(16000))
+	assert.Empty(t, h.events, "reset utterance should not produce speech end")
+}
+
+func TestEnergyVADElement_Pipeline(t *testing.T) {
+	elem := NewEnergyVADElement(EnergyVADConfig{})
+	bus := pipeline.NewEventBus()
+	events := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventVADSpeechStart, events)
+	elem.SetBus(bus)
+
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	input := append(generateSilence(8000), generateSyntheticSpeech(0.5)...)
+	for off := 0; off < len(input); off += 640 {
+		elem.In() <- vadAudioMessage(input[off : off+640])
+		select {
+		case <-elem.Out():
+		case <-time.After(time.Second):
+			t.Fatal("passthrough mode should forward every message")
+		}
+	}
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, pipeline.EventVADSpeechStart, evt.Type)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for speech start")
+	}
+}
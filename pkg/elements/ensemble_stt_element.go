@@ -0,0 +1,428 @@
+// Package elements provides pipeline processing elements.
+//
+// EnsembleSTTElement transcribes each utterance with several ASR providers
+// and emits one reconciled transcript, trading cost and latency for accuracy.
+//
+// Features:
+//   - VAD-segmented utterances, recognized by all providers concurrently
+//   - Pluggable ReconcilePolicy: PreferHighestConfidence, MajorityVote or custom
+//   - Timeout bounds the wait for slow providers; late results are dropped
+//   - EventNoResult when no provider returns a transcript
+//
+// Usage:
+//
+//	stt := elements.NewEnsembleSTTElement(
+//		[]asr.Provider{whisper, deepgram, assemblyAI},
+//		elements.MajorityVote(),
+//	)
+//	p.Link(vad, stt)
+package elements
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Ensure EnsembleSTTElement implements pipeline.Element
+var _ pipeline.Element = (*EnsembleSTTElement)(nil)
+var _ pipeline.InputResetter = (*EnsembleSTTElement)(nil)
+
+// DefaultEnsembleTimeout is how long EnsembleSTTElement waits for all
+// providers to recognize an utterance before reconciling what it has.
+const DefaultEnsembleTimeout = 3 * time.Second
+
+// EnsembleResult is one provider's transcript of an utterance.
+type EnsembleResult struct {
+	// Provider is the provider name
+	Provider string
+
+	// Result is the provider's final result
+	Result *asr.RecognitionResult
+}
+
+// ReconcilePolicy picks the final transcript of an utterance from the results
+// of the providers that answered in time, in provider order. results is never
+// empty. Returning nil drops the utterance (EventNoResult is published).
+type ReconcilePolicy func(results []EnsembleResult) *asr.RecognitionResult
+
+// PreferHighestConfidence returns the result with the highest confidence.
+// Results without a confidence score (-1) rank lowest; ties go to the earlier
+// provider, so list the most trusted provider first.
+func PreferHighestConfidence() ReconcilePolicy {
+	return func(results []EnsembleResult) *asr.RecognitionResult {
+		best := results[0].Result
+		for _, r := range results[1:] {
+			if r.Result.Confidence > best.Confidence {
+				best = r.Result
+			}
+		}
+		return best
+	}
+}
+
+// MajorityVote returns the transcript most providers agree on, ignoring case,
+// whitespace and punctuation. Ties (e.g. two providers that
+// disagree) are broken by PreferHighestConfidence among the tied transcripts.
+func MajorityVote() ReconcilePolicy {
+	byConfidence := PreferHighestConfidence()
+	return func(results []EnsembleResult) *asr.RecognitionResult {
+		votes := make(map[string][]EnsembleResult)
+		most := 0
+		for _, r := range results {
+			key := normalizeTranscript(r.Result.Text)
+			votes[key] = append(votes[key], r)
+			most = max(most, len(votes[key]))
+		}
+
+		var tied []EnsembleResult
+		for _, r := range results {
+			if len(votes[normalizeTranscript(r.Result.Text)]) == most {
+				tied = append(tied, r)
+			}
+		}
+		return byConfidence(tied)
+	}
+}
+
+// EnsembleSTTConfig holds configuration for EnsembleSTTElement.
+type EnsembleSTTConfig struct {
+	// Timeout is how long to wait for all providers to recognize an utterance.
+	// When it expires the results received so far are reconciled and the
+	// remaining requests are canceled; if none arrived, EventNoResult is
+	// published (default: DefaultEnsembleTimeout)
+	Timeout time.Duration
+
+	// Recognition is passed to every provider. An empty Language uses the
+	// pipeline LanguageContext source language
+	Recognition asr.RecognitionConfig
+
+	// SampleRate in Hz (default: 16000)
+	SampleRate int
+
+	// Channels (default: 1 for mono)
+	Channels int
+}
+
+// EnsembleSTTElement runs several ASR providers on the same audio and emits a
+// single reconciled transcript, for use cases where accuracy matters more than
+// cost (e.g. medical dictation).
+//
+// Utterances are segmented by VAD events: audio between EventVADSpeechStart
+// (including its pre-roll) and EventVADSpeechEnd is sent to every provider's
+// Recognize concurrently. Once all providers answer, or Timeout expires, the
+// non-empty results are reconciled by the ReconcilePolicy and emitted as a
+// text/final message and EventFinalResult. Latency is that of the slowest
+// provider, bounded by Timeout. Utterances are recognized one at a time, in order.
+type EnsembleSTTElement struct {
+	*pipeline.BaseElement
+
+	providers []asr.Provider
+	policy    ReconcilePolicy
+	config    EnsembleSTTConfig
+
+	// Audio of the utterance in progress
+	mu          sync.Mutex
+	speaking    bool
+	audioBuffer []byte
+
+	// Completed utterances waiting for recognition
+	utterances chan []byte
+
+	vadEventsSub chan pipeline.Event
+
+	// Attributes of the latest input audio, attached to recognition results
+	attrs pipeline.AttributeTracker
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEnsembleSTTElement creates an ensemble STT element with default configuration.
+// A nil policy uses PreferHighestConfidence.
+func NewEnsembleSTTElement(providers []asr.Provider, policy ReconcilePolicy) *EnsembleSTTElement {
+	return NewEnsembleSTTElementWithConfig(providers, policy, EnsembleSTTConfig{})
+}
+
+// NewEnsembleSTTElementWithConfig creates an ensemble STT element.
+// A nil policy uses PreferHighestConfidence.
+func NewEnsembleSTTElementWithConfig(providers []asr.Provider, policy ReconcilePolicy, config EnsembleSTTConfig) *EnsembleSTTElement {
+	if policy == nil {
+		policy = PreferHighestConfidence()
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultEnsembleTimeout
+	}
+	if config.SampleRate == 0 {
+		config.SampleRate = 16000
+	}
+	if config.Channels == 0 {
+		config.Channels = 1
+	}
+
+	return &EnsembleSTTElement{
+		BaseElement: pipeline.NewBaseElement("ensemble-stt", 100),
+		providers:   providers,
+		policy:      policy,
+		config:      config,
+		utterances:  make(chan []byte, 4),
+	}
+}
+
+// Start starts the ensemble STT element.
+func (e *EnsembleSTTElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	names := make([]string, len(e.providers))
+	for i, p := range e.providers {
+		names[i] = p.Name()
+	}
+	log.Printf("[EnsembleSTT] Starting element (providers: %s, timeout: %v)", strings.Join(names, ", "), e.config.Timeout)
+
+	if e.BaseElement.Bus() != nil {
+		e.vadEventsSub = make(chan pipeline.Event, 10)
+		e.BaseElement.Bus().Subscribe(pipeline.EventVADSpeechStart, e.vadEventsSub)
+		e.BaseElement.Bus().Subscribe(pipeline.EventVADSpeechEnd, e.vadEventsSub)
+
+		e.wg.Add(1)
+		go e.handleVADEvents(ctx)
+	}
+
+	e.wg.Add(2)
+	go e.processAudio(ctx)
+	go e.recognizeUtterances(ctx)
+
+	return nil
+}
+
+// Stop stops the ensemble STT element.
+func (e *EnsembleSTTElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+
+	if e.vadEventsSub != nil {
+		if e.BaseElement.Bus() != nil {
+			e.BaseElement.Bus().Unsubscribe(pipeline.EventVADSpeechStart, e.vadEventsSub)
+			e.BaseElement.Bus().Unsubscribe(pipeline.EventVADSpeechEnd, e.vadEventsSub)
+		}
+		e.vadEventsSub = nil
+	}
+
+	for _, p := range e.providers {
+		p.Close()
+	}
+	return nil
+}
+
+// ResetInput drops the utterance in progress without recognizing it.
+// Implements pipeline.InputResetter.
+func (e *EnsembleSTTElement) ResetInput() {
+	e.mu.Lock()
+	e.speaking = false
+	e.audioBuffer = e.audioBuffer[:0]
+	e.mu.Unlock()
+}
+
+// processAudio buffers incoming audio while the user is speaking.
+func (e *EnsembleSTTElement) processAudio(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.BaseElement.InChan:
+			if msg.Type != pipeline.MsgTypeAudio || msg.AudioData == nil {
+				continue
+			}
+			e.attrs.Track(msg)
+
+			if msg.AudioData.SampleRate != e.config.SampleRate {
+				log.Printf("[EnsembleSTT] Warning: Audio sample rate mismatch (expected %d, got %d)",
+					e.config.SampleRate, msg.AudioData.SampleRate)
+				continue
+			}
+
+			e.mu.Lock()
+			if e.speaking {
+				e.audioBuffer = append(e.audioBuffer, msg.AudioData.Data...)
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// handleVADEvents starts and ends utterances on VAD events.
+func (e *EnsembleSTTElement) handleVADEvents(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-e.vadEventsSub:
+			switch event.Type {
+			case pipeline.EventVADSpeechStart:
+				e.mu.Lock()
+				e.speaking = true
+				e.audioBuffer = e.audioBuffer[:0]
+				if payload, ok := event.Payload.(pipeline.VADPayload); ok {
+					e.audioBuffer = append(e.audioBuffer, payload.PreRollAudio...)
+				}
+				e.mu.Unlock()
+
+			case pipeline.EventVADSpeechEnd:
+				e.mu.Lock()
+				speaking := e.speaking
+				utterance := bytes.Clone(e.audioBuffer)
+				e.speaking = false
+				e.audioBuffer = e.audioBuffer[:0]
+				e.mu.Unlock()
+
+				if !speaking || len(utterance) == 0 {
+					continue
+				}
+				select {
+				case e.utterances <- utterance:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// recognizeUtterances recognizes completed utterances in order.
+func (e *EnsembleSTTElement) recognizeUtterances(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case utterance := <-e.utterances:
+			result := e.recognize(ctx, utterance)
+			if ctx.Err() != nil {
+				return
+			}
+			if result == nil || result.Text == "" {
+				reason := "empty"
+				if result == nil {
+					reason = "timeout"
+				}
+				publishNoResult(e.BaseElement.Bus(), e.GetName(), reason, e.config.Timeout)
+				continue
+			}
+			e.emit(ctx, result)
+		}
+	}
+}
+
+// recognize sends audio to all providers and reconciles the results that
+// arrive within the timeout. It returns nil if no provider answered.
+func (e *EnsembleSTTElement) recognize(ctx context.Context, audio []byte) *asr.RecognitionResult {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	audioConfig := asr.AudioConfig{
+		SampleRate:    e.config.SampleRate,
+		Channels:      e.config.Channels,
+		Encoding:      "pcm",
+		BitsPerSample: 16,
+	}
+	config := e.config.Recognition
+	if config.Language == "" {
+		config.Language = e.LanguageContext().Source()
+	}
+
+	results := make([]*asr.RecognitionResult, len(e.providers))
+	var wg sync.WaitGroup
+	for i, p := range e.providers {
+		wg.Add(1)
+		go func(i int, p asr.Provider) {
+			defer wg.Done()
+
+			start := time.Now()
+			result, err := p.Recognize(ctx, bytes.NewReader(audio), audioConfig, config)
+			if err != nil {
+				log.Printf("[EnsembleSTT] %s failed after %v: %v", p.Name(), time.Since(start), err)
+				return
+			}
+			results[i] = result
+		}(i, p)
+	}
+	wg.Wait()
+
+	var answered []EnsembleResult
+	empty := false
+	for i, r := range results {
+		if r == nil {
+			continue
+		}
+		log.Printf("[EnsembleSTT] %s: %q (confidence %.2f)", e.providers[i].Name(), r.Text, r.Confidence)
+		if r.Text == "" {
+			empty = true
+			continue
+		}
+		answered = append(answered, EnsembleResult{Provider: e.providers[i].Name(), Result: r})
+	}
+	if len(answered) == 0 {
+		if empty {
+			return &asr.RecognitionResult{IsFinal: true, Confidence: -1}
+		}
+		return nil
+	}
+
+	result := e.policy(answered)
+	if result == nil {
+		return &asr.RecognitionResult{IsFinal: true, Confidence: -1}
+	}
+	return result
+}
+
+// emit sends the reconciled transcript downstream and publishes EventFinalResult.
+func (e *EnsembleSTTElement) emit(ctx context.Context, result *asr.RecognitionResult) {
+	e.LanguageContext().SetDetected(result.Language)
+	log.Printf("[EnsembleSTT] Reconciled result: %s", result.Text)
+
+	attrs := e.attrs.Attributes()
+	timestamp := result.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	textMsg := &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeData,
+		Timestamp:  time.Now(),
+		Attributes: attrs,
+		TextData: &pipeline.TextData{
+			Data:      []byte(result.Text),
+			TextType:  "text/final",
+			Timestamp: timestamp,
+		},
+	}
+
+	select {
+	case e.BaseElement.OutChan <- textMsg:
+	case <-ctx.Done():
+		return
+	}
+
+	if e.BaseElement.Bus() != nil {
+		e.BaseElement.Bus().Publish(pipeline.Event{
+			Type:       pipeline.EventFinalResult,
+			Timestamp:  timestamp,
+			Payload:    result.Text,
+			Attributes: attrs,
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package elements
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockASRProvider answers every Recognize with a fixed result after a delay.
+type mockASRProvider struct {
+	name   string
+	text   string
+	conf   float32
+	delay  time.Duration
+	audios chan []byte
+}
+
+func newMockASRProvider(name, text string, conf float32, delay time.Duration) *mockASRProvider {
+	return &mockASRProvider{name: name, text: text, conf: conf, delay: delay, audios: make(chan []byte, 10)}
+}
+
+func (p *mockASRProvider) Name() string { return p.name }
+
+func (p *mockASRProvider) Recognize(ctx context.Context, audio io.Reader, _ asr.AudioConfig, _ asr.RecognitionConfig) (*asr.RecognitionResult, error) {
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	p.audios <- data
+
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &asr.RecognitionResult{Text: p.text, IsFinal: true, Confidence: p.conf, Timestamp: time.Now()}, nil
+}
+
+func (p *mockASRProvider) StreamingRecognize(context.Context, asr.AudioConfig, asr.RecognitionConfig) (asr.StreamingRecognizer, error) {
+	return nil, &asr.Error{Code: asr.ErrCodeUnsupportedFeature, Message: "streaming not supported"}
+}
+
+func (p *mockASRProvider) SupportsStreaming() bool      { return false }
+func (p *mockASRProvider) SupportedLanguages() []string { return nil }
+func (p *mockASRProvider) Close() error                 { return nil }
+
+func ensembleResults(results ...EnsembleResult) []EnsembleResult { return results }
+
+func ensembleResult(provider, text string, conf float32) EnsembleResult {
+	return EnsembleResult{Provider: provider, Result: &asr.RecognitionResult{Text: text, Confidence: conf}}
+}
+
+func TestReconcilePolicies(t *testing.T) {
+	highest := PreferHighestConfidence()
+	assert.Equal(t, "b", highest(ensembleResults(ensembleResult("1", "a", 0.7), ensembleResult("2", "b", 0.9))).Text)
+	// Unknown confidence ranks lowest, ties go to the first provider
+	assert.Equal(t, "a", highest(ensembleResults(ensembleResult("1", "a", 0.5), ensembleResult("2", "b", -1))).Text)
+	assert.Equal(t, "a", highest(ensembleResults(ensembleResult("1", "a", -1), ensembleResult("2", "b", -1))).Text)
+
+	vote := MajorityVote()
+	// Two providers agreeing (ignoring case and punctuation) outvote a more confident one
+	got := vote(ensembleResults(
+		ensembleResult("1", "Take 50 mg of aspirin.", 0.6),
+		ensembleResult("2", "Take 15 mg of aspirin", 0.95),
+		ensembleResult("3", "take 50 mg of aspirin", 0.7),
+	))
+	assert.Equal(t, "take 50 mg of aspirin", got.Text)
+	// Without a majority the most confident transcript wins
+	got = vote(ensembleResults(ensembleResult("1", "fifty", 0.6), ensembleResult("2", "fifteen", 0.8)))
+	assert.Equal(t, "fifteen", got.Text)
+}
+
+func TestEnsembleSTTElement_Reconciles(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	events := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventFinalResult, events)
+	bus.Subscribe(pipeline.EventNoResult, events)
+
+	accurate := newMockASRProvider("accurate", "take 50 mg of aspirin", 0.9, 30*time.Millisecond)
+	fast := newMockASRProvider("fast", "take 15 mg of aspirin", 0.6, 0)
+	elem := NewEnsembleSTTElementWithConfig([]asr.Provider{fast, accurate}, PreferHighestConfidence(), EnsembleSTTConfig{Timeout: time.Second})
+	elem.SetBus(bus)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	// Both providers get the pre-roll and the speech audio
+	bus.Publish(pipeline.Event{Type: pipeline.EventVADSpeechStart, Payload: pipeline.VADPayload{PreRollAudio: []byte{1, 2}}})
+	time.Sleep(10 * time.Millisecond)
+	elem.In() <- audioMessage(16000, 1, 4)
+	time.Sleep(10 * time.Millisecond)
+	bus.Publish(pipeline.Event{Type: pipeline.EventVADSpeechEnd})
+
+	for _, p := range []*mockASRProvider{fast, accurate} {
+		select {
+		case audio := <-p.audios:
+			assert.Equal(t, []byte{1, 2, 0, 0, 0, 0}, audio, p.name)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %s to receive audio", p.name)
+		}
+	}
+
+	// The element waits for the slower, more confident provider
+	select {
+	case out := <-elem.Out():
+		assert.Equal(t, "take 50 mg of aspirin", string(out.TextData.Data))
+		assert.Equal(t, "text/final", out.TextData.TextType)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reconciled result")
+	}
+	select {
+	case evt := <-events:
+		assert.Equal(t, pipeline.EventFinalResult, evt.Type)
+		assert.Equal(t, "take 50 mg of aspirin", evt.Payload)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for EventFinalResult")
+	}
+}
+
+func TestEnsembleSTTElement_Timeout(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	events := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventNoResult, events)
+
+	fast := newMockASRProvider("fast", "hello", 0.5, 0)
+	stalled := newMockASRProvider("stalled", "hello there", 0.99, time.Hour)
+	elem := NewEnsembleSTTElementWithConfig([]asr.Provider{fast, stalled}, nil, EnsembleSTTConfig{Timeout: 50 * time.Millisecond})
+	elem.SetBus(bus)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	utterance := func() {
+		bus.Publish(pipeline.Event{Type: pipeline.EventVADSpeechStart, Payload: pipeline.VADPayload{PreRollAudio: []byte{1, 2}}})
+		time.Sleep(10 * time.Millisecond)
+		bus.Publish(pipeline.Event{Type: pipeline.EventVADSpeechEnd})
+	}
+
+	// A stalled provider delays the result by at most the timeout
+	start := time.Now()
+	utterance()
+	select {
+	case out := <-elem.Out():
+		assert.Equal(t, "hello", string(out.TextData.Data))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the partial ensemble result")
+	}
+
+	// With no provider answering in time the utterance gets EventNoResult
+	fast.delay = time.Hour
+	utterance()
+	select {
+	case evt := <-events:
+		assert.Equal(t, "timeout", evt.Payload.(pipeline.NoResultPayload).Reason)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for EventNoResult")
+	}
+}
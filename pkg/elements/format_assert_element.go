@@ -0,0 +1,168 @@
+// Package elements provides pipeline processing elements.
+//
+// FormatAssertElement 校验流经的音频格式是否符合预期。
+// 配置错误的 Pipeline（例如把 24kHz 音频送给 48kHz 的消费者）不会报错，
+// 只会输出失真的音频；把该元素放在可疑的连接处，可以把这类问题变成明确的错误。
+//
+// 主要功能:
+//   - 校验采样率、通道数（期望值为 0 时不校验该项）
+//   - 校验原始 PCM 数据长度是否按帧对齐
+//   - 不匹配时发布 EventError；同一种错误格式只报告一次，避免刷屏
+//   - debug 模式下直接 panic，便于开发阶段尽早暴露问题
+//   - 所有消息原样透传
+//
+// 使用示例:
+//
+//	check := NewFormatAssertElement(48000, 1)
+//	check.SetProperty("debug", true)
+//	p.AddElements([]pipeline.Element{tts, check, sink})
+package elements
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure FormatAssertElement implements pipeline.Element
+var _ pipeline.Element = (*FormatAssertElement)(nil)
+
+// FormatAssertElement 音频格式断言元素
+type FormatAssertElement struct {
+	*pipeline.BaseElement
+
+	expectedRate     int
+	expectedChannels int
+	debug            bool
+
+	// 上一次报告的错误，相同的错误不重复报告
+	lastMismatch string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewFormatAssertElement 创建音频格式断言元素
+// expectedRate/expectedChannels 为 0 表示不校验该项
+func NewFormatAssertElement(expectedRate, expectedChannels int) *FormatAssertElement {
+	elem := &FormatAssertElement{
+		BaseElement:      pipeline.NewBaseElement("format-assert-element", 100),
+		expectedRate:     expectedRate,
+		expectedChannels: expectedChannels,
+	}
+
+	elem.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "debug",
+		Type:     reflect.TypeOf(false),
+		Writable: true,
+		Readable: true,
+		Default:  false,
+	})
+
+	return elem
+}
+
+// SetProperty 设置属性，debug=true 时格式不匹配直接 panic
+func (e *FormatAssertElement) SetProperty(name string, value interface{}) error {
+	if err := e.BaseElement.SetProperty(name, value); err != nil {
+		return err
+	}
+	if name == "debug" {
+		e.debug = value.(bool)
+	}
+	return nil
+}
+
+func (e *FormatAssertElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio {
+					e.check(msg.AudioData)
+				}
+
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *FormatAssertElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+// check 校验一条音频数据，不匹配时报告错误
+func (e *FormatAssertElement) check(data *pipeline.AudioData) {
+	mismatch := e.validate(data)
+	if mismatch == "" {
+		e.lastMismatch = ""
+		return
+	}
+
+	if e.debug {
+		panic(fmt.Sprintf("[FormatAssert] %s", mismatch))
+	}
+
+	if mismatch == e.lastMismatch {
+		return
+	}
+	e.lastMismatch = mismatch
+
+	log.Printf("[FormatAssert] %s", mismatch)
+	if e.Bus() != nil {
+		e.Bus().Publish(pipeline.Event{
+			Type:      pipeline.EventError,
+			Timestamp: time.Now(),
+			Payload:   fmt.Sprintf("audio format mismatch: %s", mismatch),
+		})
+	}
+}
+
+// validate 返回格式问题的描述，格式正确时返回空字符串
+func (e *FormatAssertElement) validate(data *pipeline.AudioData) string {
+	if data == nil {
+		return "audio message without audio data"
+	}
+
+	if e.expectedRate > 0 && data.SampleRate != e.expectedRate {
+		return fmt.Sprintf("expected %dHz, got %dHz", e.expectedRate, data.SampleRate)
+	}
+	if e.expectedChannels > 0 && data.Channels != e.expectedChannels {
+		return fmt.Sprintf("expected %d channel(s), got %d", e.expectedChannels, data.Channels)
+	}
+
+	// 原始 PCM 必须是完整的 16-bit 帧
+	if isPCMMediaType(data.MediaType) && data.Channels > 0 {
+		frameSize := 2 * data.Channels
+		if len(data.Data)%frameSize != 0 {
+			return fmt.Sprintf("PCM data length %d is not a multiple of frame size %d", len(data.Data), frameSize)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,78 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func audioMessage(sampleRate, channels, size int) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       make([]byte, size),
+			SampleRate: sampleRate,
+			Channels:   channels,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+}
+
+func TestFormatAssertElement_Validate(t *testing.T) {
+	elem := NewFormatAssertElement(48000, 1)
+
+	assert.Empty(t, elem.validate(audioMessage(48000, 1, 960).AudioData))
+	assert.Contains(t, elem.validate(audioMessage(24000, 1, 960).AudioData), "expected 48000Hz, got 24000Hz")
+	assert.Contains(t, elem.validate(audioMessage(48000, 2, 960).AudioData), "expected 1 channel(s), got 2")
+	assert.Contains(t, elem.validate(audioMessage(48000, 1, 961).AudioData), "not a multiple of frame size")
+	assert.NotEmpty(t, elem.validate(nil))
+
+	// 期望值为 0 时不校验
+	unchecked := NewFormatAssertElement(0, 0)
+	assert.Empty(t, unchecked.validate(audioMessage(8000, 2, 4).AudioData))
+}
+
+func TestFormatAssertElement_PublishesErrorOnce(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	errCh := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventError, errCh)
+
+	elem := NewFormatAssertElement(48000, 1)
+	elem.SetBus(bus)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	for i := 0; i < 3; i++ {
+		elem.In() <- audioMessage(24000, 1, 960)
+	}
+
+	// 消息原样透传
+	for i := 0; i < 3; i++ {
+		select {
+		case out := <-elem.Out():
+			assert.Equal(t, 24000, out.AudioData.SampleRate)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for passthrough message")
+		}
+	}
+
+	require.Len(t, errCh, 1)
+	evt := <-errCh
+	assert.Contains(t, evt.Payload, "expected 48000Hz, got 24000Hz")
+}
+
+func TestFormatAssertElement_DebugPanics(t *testing.T) {
+	elem := NewFormatAssertElement(48000, 1)
+	require.NoError(t, elem.SetProperty("debug", true))
+
+	assert.Panics(t, func() {
+		elem.check(audioMessage(24000, 1, 960).AudioData)
+	})
+	assert.NotPanics(t, func() {
+		elem.check(audioMessage(48000, 1, 960).AudioData)
+	})
+}
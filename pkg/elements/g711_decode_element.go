@@ -0,0 +1,149 @@
+// Package elements provides pipeline processing elements.
+//
+// G711DecodeElement 把电话网络的 G.711 音频（μ-law/A-law）解码为 16-bit PCM，
+// 用于接入 SIP 网关、Twilio 等以 8kHz G.711 传输音频的来源。
+//
+// 主要功能:
+//   - 按构造时指定的压扩律解码，输出 8kHz 单声道 PCM
+//   - 不做重采样，需要 16kHz 时在后面接 AudioResampleElement
+//   - 其他媒体类型和非音频消息原样转发
+//
+// 使用示例:
+//
+//	decoder := elements.NewG711DecodeElement(elements.G711ALaw)
+//	resample := elements.NewAudioResampleElement(8000, 16000, 1, 1)
+//	p.Link(decoder, resample)
+package elements
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// G711SampleRate G.711 固定采样率
+const G711SampleRate = 8000
+
+// G711Law G.711 压扩律
+type G711Law int
+
+const (
+	// G711MuLaw μ-law，北美/日本电话网络
+	G711MuLaw G711Law = iota
+	// G711ALaw A-law，欧洲及国际电话网络
+	G711ALaw
+)
+
+// MediaType 返回该压扩律对应的音频媒体类型
+func (l G711Law) MediaType() pipeline.AudioMediaType {
+	if l == G711ALaw {
+		return pipeline.AudioMediaTypeALaw
+	}
+	return pipeline.AudioMediaTypeMuLaw
+}
+
+func (l G711Law) String() string {
+	if l == G711ALaw {
+		return "alaw"
+	}
+	return "mulaw"
+}
+
+// G711DecodeElement 将 G.711 (μ-law/A-law) 音频解码为 16-bit PCM
+// 采样率保持 8kHz，需要 16kHz 时在后面接 AudioResampleElement
+// 其他媒体类型和非音频消息原样转发
+type G711DecodeElement struct {
+	*pipeline.BaseElement
+
+	law G711Law
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewG711DecodeElement 创建 G.711 解码 Element
+func NewG711DecodeElement(law G711Law) *G711DecodeElement {
+	return &G711DecodeElement{
+		BaseElement: pipeline.NewBaseElement("g711-decode-element", 100),
+		law:         law,
+	}
+}
+
+// OutputFormat 返回解码输出的格式，固定为 8kHz 单声道
+func (e *G711DecodeElement) OutputFormat() pipeline.AudioFormat {
+	return pipeline.AudioFormat{SampleRate: G711SampleRate, Channels: 1}
+}
+
+func (e *G711DecodeElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil &&
+					msg.AudioData.MediaType == e.law.MediaType() {
+					msg = e.decode(msg)
+				}
+
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// decode 返回解码后的 PCM 消息
+func (e *G711DecodeElement) decode(msg *pipeline.PipelineMessage) *pipeline.PipelineMessage {
+	var pcm []byte
+	if e.law == G711ALaw {
+		pcm = audio.ALawToPCM(msg.AudioData.Data)
+	} else {
+		pcm = audio.MuLawToPCM(msg.AudioData.Data)
+	}
+
+	sampleRate := msg.AudioData.SampleRate
+	if sampleRate == 0 {
+		sampleRate = G711SampleRate
+	}
+	channels := msg.AudioData.Channels
+	if channels == 0 {
+		channels = 1
+	}
+
+	return &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeAudio,
+		SessionID:  msg.SessionID,
+		Timestamp:  time.Now(),
+		Attributes: msg.Attributes,
+		AudioData: &pipeline.AudioData{
+			Data:       pcm,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+			SampleRate: sampleRate,
+			Channels:   channels,
+			Timestamp:  msg.AudioData.Timestamp,
+		},
+	}
+}
+
+func (e *G711DecodeElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package elements
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// g711Process sends msg through element and returns its output
+func g711Process(t *testing.T, element pipeline.Element, msg *pipeline.PipelineMessage) *pipeline.PipelineMessage {
+	t.Helper()
+	element.In() <- msg
+	select {
+	case out := <-element.Out():
+		return out
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for output")
+		return nil
+	}
+}
+
+func startG711(t *testing.T, law G711Law) (*G711EncodeElement, *G711DecodeElement) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	encoder := NewG711EncodeElement(law)
+	decoder := NewG711DecodeElement(law)
+	require.NoError(t, encoder.Start(ctx))
+	require.NoError(t, decoder.Start(ctx))
+	t.Cleanup(func() {
+		encoder.Stop()
+		decoder.Stop()
+	})
+	return encoder, decoder
+}
+
+func TestG711Element_RoundTrip(t *testing.T) {
+	for _, law := range []G711Law{G711MuLaw, G711ALaw} {
+		t.Run(law.String(), func(t *testing.T) {
+			encoder, decoder := startG711(t, law)
+
+			// 20ms of 8kHz PCM
+			pcm := generateTone(160, 440, G711SampleRate)
+			encoded := g711Process(t, encoder, &pipeline.PipelineMessage{
+				Type:      pipeline.MsgTypeAudio,
+				SessionID: "test-session",
+				AudioData: &pipeline.AudioData{
+					Data:       pcm,
+					SampleRate: G711SampleRate,
+					Channels:   1,
+					MediaType:  pipeline.AudioMediaTypeRaw,
+				},
+			})
+			require.Equal(t, pipeline.MsgTypeAudio, encoded.Type)
+			assert.Equal(t, law.MediaType(), encoded.AudioData.MediaType)
+			assert.Equal(t, G711SampleRate, encoded.AudioData.SampleRate)
+			assert.Equal(t, "test-session", encoded.SessionID)
+			require.Len(t, encoded.AudioData.Data, 160)
+
+			decoded := g711Process(t, decoder, encoded)
+			assert.Equal(t, pipeline.AudioMediaTypeRaw, decoded.AudioData.MediaType)
+			assert.Equal(t, G711SampleRate, decoded.AudioData.SampleRate)
+			assert.Equal(t, 1, decoded.AudioData.Channels)
+			require.Len(t, decoded.AudioData.Data, len(pcm))
+
+			// Quantization error grows with the segment, allow 1/16 of the level
+			for i := 0; i < len(pcm); i += 2 {
+				original := int(int16(binary.LittleEndian.Uint16(pcm[i:])))
+				got := int(int16(binary.LittleEndian.Uint16(decoded.AudioData.Data[i:])))
+				abs := original
+				if abs < 0 {
+					abs = -abs
+				}
+				assert.InDelta(t, original, got, float64(abs/16+16), "sample %d", i/2)
+			}
+		})
+	}
+}
+
+func TestG711DecodeElement_IgnoresOtherLaw(t *testing.T) {
+	mulawEncoder, _ := startG711(t, G711MuLaw)
+	_, alawDecoder := startG711(t, G711ALaw)
+
+	msg := &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       generateTone(160, 440, G711SampleRate),
+			SampleRate: G711SampleRate,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+	mulaw := g711Process(t, mulawEncoder, msg)
+
+	// An A-law decoder leaves μ-law audio untouched
+	out := g711Process(t, alawDecoder, mulaw)
+	assert.Same(t, mulaw, out)
+}
+
+func TestG711EncodeElement_RejectsWrongRate(t *testing.T) {
+	encoder, _ := startG711(t, G711MuLaw)
+
+	// 16kHz audio must be resampled first and is dropped
+	encoder.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       generateTone(320, 440, 16000),
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+
+	// Non-audio messages pass through
+	text := &pipeline.PipelineMessage{Type: pipeline.MsgTypeData, TextData: &pipeline.TextData{Data: []byte("hi")}}
+	assert.Same(t, text, g711Process(t, encoder, text))
+}
@@ -0,0 +1,132 @@
+// Package elements provides pipeline processing elements.
+//
+// G711EncodeElement 把 8kHz 单声道 PCM 编码为 G.711（μ-law/A-law），
+// 用于向电话网络（SIP 网关等）输出音频，与 G711DecodeElement 配对使用。
+//
+// 主要功能:
+//   - 按构造时指定的压扩律编码，输出消息标注对应的 AudioMediaType
+//   - 不做重采样，其他采样率的音频被丢弃（只提示一次），需要先接 AudioResampleElement 转换到 8kHz
+//   - 其他媒体类型和非音频消息原样转发
+//
+// 使用示例:
+//
+//	resample := elements.NewAudioResampleElement(24000, 8000, 1, 1)
+//	encoder := elements.NewG711EncodeElement(elements.G711MuLaw)
+//	p.Link(resample, encoder)
+package elements
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// G711EncodeElement 将 8kHz 单声道 PCM 编码为 G.711 (μ-law/A-law)
+// 不做重采样：其他采样率的音频会被丢弃，需要先接 AudioResampleElement 转换到 8kHz
+// 其他媒体类型和非音频消息原样转发
+type G711EncodeElement struct {
+	*pipeline.BaseElement
+
+	law G711Law
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewG711EncodeElement 创建 G.711 编码 Element
+func NewG711EncodeElement(law G711Law) *G711EncodeElement {
+	return &G711EncodeElement{
+		BaseElement: pipeline.NewBaseElement("g711-encode-element", 100),
+		law:         law,
+	}
+}
+
+func (e *G711EncodeElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		// 格式不匹配只提示一次，避免每帧刷日志
+		warned := false
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil && isPCMMediaType(msg.AudioData.MediaType) {
+					encoded, err := e.encode(msg)
+					if err != nil {
+						if !warned {
+							log.Printf("[G711Encode] Dropping audio: %v", err)
+							warned = true
+						}
+						continue
+					}
+					msg = encoded
+				}
+
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// encode 返回编码后的 G.711 消息
+func (e *G711EncodeElement) encode(msg *pipeline.PipelineMessage) (*pipeline.PipelineMessage, error) {
+	data := msg.AudioData
+	if data.SampleRate != 0 && data.SampleRate != G711SampleRate {
+		return nil, fmt.Errorf("G.711 needs %dHz audio, got %dHz (add an AudioResampleElement)", G711SampleRate, data.SampleRate)
+	}
+	if data.Channels > 1 {
+		return nil, fmt.Errorf("G.711 needs mono audio, got %d channels", data.Channels)
+	}
+
+	pcm, err := audio.ConvertSampleFormat(data.Data, data.Format(), pipeline.SampleFormatS16)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded []byte
+	if e.law == G711ALaw {
+		encoded = audio.PCMToALaw(pcm)
+	} else {
+		encoded = audio.PCMToMuLaw(pcm)
+	}
+
+	return &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeAudio,
+		SessionID:  msg.SessionID,
+		Timestamp:  time.Now(),
+		Attributes: msg.Attributes,
+		AudioData: &pipeline.AudioData{
+			Data:       encoded,
+			MediaType:  e.law.MediaType(),
+			SampleRate: G711SampleRate,
+			Channels:   1,
+			Timestamp:  data.Timestamp,
+		},
+	}, nil
+}
+
+func (e *G711EncodeElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
@@ -3,9 +3,14 @@ package elements
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/realtime-ai/realtime-ai/pkg/audio"
@@ -15,6 +20,8 @@ import (
 
 // Make sure GeminiLiveElement implements pipeline.Element
 var _ pipeline.Element = (*GeminiLiveElement)(nil)
+var _ pipeline.ToolResultSender = (*GeminiLiveElement)(nil)
+var _ pipeline.ConversationRecorder = (*GeminiLiveElement)(nil)
 
 // Deprecated: Use GeminiLiveElement, GeminiLiveConfig, etc. instead
 type GeminiElement = GeminiLiveElement
@@ -40,12 +47,25 @@ func DefaultGeminiConfig() GeminiLiveConfig {
 // DefaultGeminiLiveModel is the default model used by GeminiLiveElement
 const DefaultGeminiLiveModel = "gemini-2.5-flash-native-audio-preview-12-2025"
 
+// Gemini Live 返回 24kHz 单声道 PCM16
+const geminiLiveOutputSampleRate = 24000
+
 // GeminiLiveConfig holds configuration for GeminiLiveElement
 type GeminiLiveConfig struct {
 	// Model is the Gemini model to use (default: gemini-2.5-flash-native-audio-preview-12-2025)
 	Model string
 	// APIKey is the Google API key (default: from GOOGLE_API_KEY env)
 	APIKey string
+	// Tools are the functions the model may call. Calls are published as
+	// EventToolCall and must be answered with SendToolResult.
+	Tools []*genai.Tool
+	// GoogleSearch enables Google Search grounding: the model can search the
+	// web before answering factual questions. Searches are published as
+	// EventGrounding and need no answer.
+	GoogleSearch bool
+	// OutputChunkMs 把模型返回的音频切成固定时长的帧（如 20ms）再输出，
+	// 避免大块突发的音频冲击下游的 pacer；0 表示按服务商返回的块原样输出
+	OutputChunkMs int
 }
 
 // DefaultGeminiLiveConfig returns the default configuration
@@ -61,14 +81,29 @@ type GeminiLiveElement struct {
 
 	model     string
 	apiKey    string
+	tools     []*genai.Tool
 	session   *genai.Session
+	sendMu    sync.Mutex // genai.Session.Send 不支持并发写，同时保护 session 和 pendingHistory
 	sessionID string
 	dumper    *audio.Dumper
 
+	// 输出音频切帧，只在接收协程中使用
+	rechunker *audioRechunker
+
 	// Response tracking
 	inResponse        bool
 	currentResponseID string
 
+	// 接收协程是否仍在运行
+	alive atomic.Bool
+
+	// 等待结果的工具调用 callID -> 函数名
+	toolMu       sync.Mutex
+	pendingTools map[string]string
+
+	// 连接建立前通过 AppendMessage 记入的历史，连接后作为上下文发送
+	pendingHistory []*genai.Content
+
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
@@ -104,10 +139,17 @@ func NewGeminiLiveElementWithConfig(cfg GeminiLiveConfig) *GeminiLiveElement {
 		BaseElement: pipeline.NewBaseElement("gemini-live-element", 100),
 		model:       model,
 		apiKey:      apiKey,
+		tools:       liveTools(cfg),
 		dumper:      dumper,
+		rechunker:   newAudioRechunker(cfg.OutputChunkMs, geminiLiveOutputSampleRate, 1),
 	}
 }
 
+// OutputFormat 返回模型回复音频的格式（24kHz 单声道）
+func (e *GeminiLiveElement) OutputFormat() pipeline.AudioFormat {
+	return pipeline.AudioFormat{SampleRate: geminiLiveOutputSampleRate, Channels: 1}
+}
+
 // Implement Element interface
 
 func (e *GeminiLiveElement) Start(ctx context.Context) error {
@@ -123,13 +165,28 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 	log.Printf("[GEMINI] 正在连接模型: %s", e.model)
 	session, err := client.Live.Connect(e.model, &genai.LiveConnectConfig{
 		ResponseModalities: []string{"AUDIO"},
+		Tools:              e.tools,
 	})
 	if err != nil {
 		log.Printf("[GEMINI] connect to model error: %v", err)
 		return err
 	}
 
+	e.sendMu.Lock()
 	e.session = session
+	history := e.pendingHistory
+	e.pendingHistory = nil
+	if len(history) > 0 {
+		if err := e.session.Send(historyMessage(history)); err != nil {
+			log.Printf("[GEMINI] send conversation history error: %v", err)
+		} else {
+			log.Printf("[GEMINI] 已发送 %d 条历史消息作为上下文", len(history))
+		}
+	}
+	e.sendMu.Unlock()
+	e.toolMu.Lock()
+	e.pendingTools = make(map[string]string)
+	e.toolMu.Unlock()
 	log.Printf("[GEMINI] 成功连接到 Gemini Live API (模型: %s)", e.model)
 
 	// 启动输入处理协程（音频、图像、文本）
@@ -156,7 +213,7 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 							},
 						}
 
-						if err := e.session.Send(&liveMsg); err != nil {
+						if err := e.send(&liveMsg); err != nil {
 							log.Println("[GEMINI] AI session send error:", err)
 							continue
 						}
@@ -192,7 +249,7 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 							},
 						}
 
-						if err := e.session.Send(&liveMsg); err != nil {
+						if err := e.send(&liveMsg); err != nil {
 							log.Println("[GEMINI] AI session send image error:", err)
 							continue
 						}
@@ -209,7 +266,7 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 					}
 
 					if liveMsg.ClientContent != nil || liveMsg.RealtimeInput != nil {
-						if err := e.session.Send(&liveMsg); err != nil {
+						if err := e.send(&liveMsg); err != nil {
 							log.Println("AI session send error:", err)
 							continue
 						}
@@ -223,7 +280,9 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 	}()
 
 	if e.session != nil {
+		e.alive.Store(true)
 		go func() {
+			defer e.alive.Store(false)
 			log.Println("[GEMINI] 开始监听 Gemini 响应...")
 			for {
 				select {
@@ -245,10 +304,18 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 						return
 					}
 
+					if msg.ToolCall != nil {
+						e.handleToolCall(msg.ToolCall)
+					}
+					if msg.ToolCallCancellation != nil {
+						e.handleToolCallCancellation(msg.ToolCallCancellation)
+					}
+
 					// Handle interruption first
 					if msg.ServerContent != nil && msg.ServerContent.Interrupted {
 						log.Println("AI session interrupted")
 						// End current response if any
+						e.rechunker.Reset()
 						if e.inResponse {
 							e.endCurrentResponse("interrupted")
 						}
@@ -267,6 +334,7 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 					// 假设返回的 PCM 在 msg.ServerContent.ModelTurn.Parts 里
 					if msg.ServerContent != nil && msg.ServerContent.ModelTurn != nil {
 						for _, part := range msg.ServerContent.ModelTurn.Parts {
+							e.handleGrounding(part)
 							if part.InlineData != nil && len(part.InlineData.Data) > 0 {
 								log.Printf("[GEMINI] 收到 Gemini 音频响应: %d bytes", len(part.InlineData.Data))
 								// Start response if not already started
@@ -287,17 +355,8 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 								// })
 
 								// 将 AI 返回的 PCM 数据投递给下一环节
-								e.BaseElement.OutChan <- &pipeline.PipelineMessage{
-									Type:      pipeline.MsgTypeAudio,
-									SessionID: e.sessionID,
-									Timestamp: time.Now(),
-									AudioData: &pipeline.AudioData{
-										Data:       part.InlineData.Data,
-										MediaType:  pipeline.AudioMediaTypeRaw,
-										SampleRate: 24000, // AI 返回的采样率
-										Channels:   1,     // AI 返回的通道数
-										Timestamp:  time.Now(),
-									},
+								for _, frame := range e.rechunker.Write(part.InlineData.Data) {
+									e.sendAudio(frame)
 								}
 							}
 						}
@@ -305,6 +364,9 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 
 					// Check if turn is complete
 					if msg.ServerContent != nil && msg.ServerContent.TurnComplete {
+						if rest := e.rechunker.Flush(); len(rest) > 0 {
+							e.sendAudio(rest)
+						}
 						if e.inResponse {
 							e.endCurrentResponse("completed")
 						}
@@ -317,6 +379,195 @@ func (e *GeminiLiveElement) Start(ctx context.Context) error {
 	return nil
 }
 
+// sendAudio 将 AI 返回的 PCM 音频投递给下一环节
+func (e *GeminiLiveElement) sendAudio(data []byte) {
+	e.BaseElement.OutChan <- &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		SessionID: e.sessionID,
+		Timestamp: time.Now(),
+		AudioData: &pipeline.AudioData{
+			Data:       data,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+			SampleRate: geminiLiveOutputSampleRate, // AI 返回的采样率
+			Channels:   1,                          // AI 返回的通道数
+			Timestamp:  time.Now(),
+		},
+	}
+}
+
+// Healthy 报告 Gemini Live session 的接收循环是否仍在运行，实现 pipeline.HealthChecker
+func (e *GeminiLiveElement) Healthy() bool {
+	return e.alive.Load()
+}
+
+// SendToolResult 把工具执行结果交还模型，实现 pipeline.ToolResultSender
+// result 为 map 或 JSON 对象字符串时直接作为响应，其他值包装为 {"output": result}
+func (e *GeminiLiveElement) SendToolResult(callID string, result any) error {
+	e.toolMu.Lock()
+	name, ok := e.pendingTools[callID]
+	delete(e.pendingTools, callID)
+	e.toolMu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown tool call id: %s", callID)
+	}
+
+	response, err := toolResultMap(result)
+	if err != nil {
+		return err
+	}
+
+	return e.send(&genai.LiveClientMessage{
+		ToolResponse: &genai.LiveClientToolResponse{
+			FunctionResponses: []*genai.FunctionResponse{
+				{ID: callID, Name: name, Response: response},
+			},
+		},
+	})
+}
+
+// AppendMessage 把一条历史消息作为上下文发给模型，实现 pipeline.ConversationRecorder
+// 连接建立前调用时先缓存，Start 连接成功后按顺序发送；历史不会触发模型回复
+func (e *GeminiLiveElement) AppendMessage(role, content string) error {
+	turn, err := historyTurn(role, content)
+	if err != nil {
+		return err
+	}
+
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+	if e.session == nil {
+		e.pendingHistory = append(e.pendingHistory, turn)
+		return nil
+	}
+	return e.session.Send(historyMessage([]*genai.Content{turn}))
+}
+
+// historyTurn 把一条历史消息转换为 Live API 的对话轮次
+// Live API 只有 user 和 model 两种角色，system 消息按用户消息发送
+func historyTurn(role, content string) (*genai.Content, error) {
+	var liveRole string
+	switch role {
+	case ChatRoleUser, ChatRoleSystem:
+		liveRole = "user"
+	case ChatRoleAssistant:
+		liveRole = "model"
+	default:
+		return nil, fmt.Errorf("unsupported role: %q", role)
+	}
+	return &genai.Content{Role: liveRole, Parts: []*genai.Part{{Text: content}}}, nil
+}
+
+// historyMessage 把历史轮次封装为不结束当前轮次的 ClientContent，模型只把它作为上下文
+func historyMessage(turns []*genai.Content) *genai.LiveClientMessage {
+	return &genai.LiveClientMessage{
+		ClientContent: &genai.LiveClientContent{Turns: turns, TurnComplete: false},
+	}
+}
+
+// send 串行化对 session 的写入
+func (e *GeminiLiveElement) send(msg *genai.LiveClientMessage) error {
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+	if e.session == nil {
+		return fmt.Errorf("gemini session not connected")
+	}
+	return e.session.Send(msg)
+}
+
+// handleToolCall 记录待回复的工具调用并发布 EventToolCall
+func (e *GeminiLiveElement) handleToolCall(toolCall *genai.LiveServerToolCall) {
+	for _, fc := range toolCall.FunctionCalls {
+		if fc == nil {
+			continue
+		}
+		log.Printf("[GEMINI] 收到工具调用: %s (id=%s)", fc.Name, fc.ID)
+
+		e.toolMu.Lock()
+		e.pendingTools[fc.ID] = fc.Name
+		e.toolMu.Unlock()
+
+		e.BaseElement.Bus().Publish(pipeline.Event{
+			Type:      pipeline.EventToolCall,
+			Timestamp: time.Now(),
+			Payload: &pipeline.ToolCallPayload{
+				CallID:    fc.ID,
+				Name:      fc.Name,
+				Arguments: toolCallArguments(fc.Args),
+			},
+		})
+	}
+}
+
+// handleToolCallCancellation 丢弃被取消的工具调用并发布 EventToolCallCancelled
+func (e *GeminiLiveElement) handleToolCallCancellation(cancellation *genai.LiveServerToolCallCancellation) {
+	log.Printf("[GEMINI] 工具调用被取消: %v", cancellation.IDs)
+
+	callIDs := make([]string, 0, len(cancellation.IDs))
+	e.toolMu.Lock()
+	for _, id := range cancellation.IDs {
+		callID := strconv.FormatInt(id, 10)
+		delete(e.pendingTools, callID)
+		callIDs = append(callIDs, callID)
+	}
+	e.toolMu.Unlock()
+
+	e.BaseElement.Bus().Publish(pipeline.Event{
+		Type:      pipeline.EventToolCallCancelled,
+		Timestamp: time.Now(),
+		Payload:   &pipeline.ToolCallCancelledPayload{CallIDs: callIDs},
+	})
+}
+
+// handleGrounding 发布模型执行的 Google Search 搜索
+// Live API 把搜索作为模型生成的代码（google_search.search(queries=[...])）及其执行结果返回
+func (e *GeminiLiveElement) handleGrounding(part *genai.Part) {
+	var payload *pipeline.GroundingPayload
+	switch {
+	case part.ExecutableCode != nil && strings.Contains(part.ExecutableCode.Code, "google_search"):
+		payload = &pipeline.GroundingPayload{Queries: searchQueries(part.ExecutableCode.Code)}
+		log.Printf("[GEMINI] Google Search: %v", payload.Queries)
+	case part.CodeExecutionResult != nil:
+		payload = &pipeline.GroundingPayload{Result: part.CodeExecutionResult.Output}
+	default:
+		return
+	}
+	payload.Source = e.GetName()
+
+	e.BaseElement.Bus().Publish(pipeline.Event{
+		Type:      pipeline.EventGrounding,
+		Timestamp: time.Now(),
+		Payload:   payload,
+	})
+}
+
+// searchQueryPattern 匹配搜索代码中带引号的查询
+var searchQueryPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'`)
+
+// searchQueries 从 google_search.search(queries=[...]) 代码中提取查询
+func searchQueries(code string) []string {
+	var queries []string
+	for _, m := range searchQueryPattern.FindAllStringSubmatch(code, -1) {
+		q := m[1]
+		if q == "" {
+			q = m[2]
+		}
+		if q != "" {
+			queries = append(queries, q)
+		}
+	}
+	return queries
+}
+
+// liveTools 返回会话使用的工具，启用 GoogleSearch 时追加搜索工具
+func liveTools(cfg GeminiLiveConfig) []*genai.Tool {
+	if !cfg.GoogleSearch {
+		return cfg.Tools
+	}
+	tools := make([]*genai.Tool, 0, len(cfg.Tools)+1)
+	tools = append(tools, cfg.Tools...)
+	return append(tools, &genai.Tool{GoogleSearch: &genai.GoogleSearch{}})
+}
+
 func (e *GeminiLiveElement) Stop() error {
 	if e.cancel != nil {
 		e.cancel()
@@ -330,7 +581,9 @@ func (e *GeminiLiveElement) Stop() error {
 	}
 
 	// 清理 session
+	e.sendMu.Lock()
 	e.session = nil
+	e.sendMu.Unlock()
 	e.sessionID = ""
 	return nil
 }
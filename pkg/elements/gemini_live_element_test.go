@@ -0,0 +1,118 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+func TestSearchQueries(t *testing.T) {
+	assert.Equal(t, []string{"weather in London", "London forecast"},
+		searchQueries(`print(google_search.search(queries=["weather in London", "London forecast"]))`))
+	assert.Equal(t, []string{`who is "Bob"`},
+		searchQueries(`print(google_search.search(queries=['who is "Bob"']))`))
+	assert.Empty(t, searchQueries(`print(google_search.search(queries=[]))`))
+}
+
+func TestLiveTools(t *testing.T) {
+	fn := &genai.Tool{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "get_time"}}}
+
+	assert.Equal(t, []*genai.Tool{fn}, liveTools(GeminiLiveConfig{Tools: []*genai.Tool{fn}}))
+
+	tools := liveTools(GeminiLiveConfig{Tools: []*genai.Tool{fn}, GoogleSearch: true})
+	require.Len(t, tools, 2)
+	assert.Same(t, fn, tools[0])
+	assert.NotNil(t, tools[1].GoogleSearch)
+}
+
+// startGeminiBus 为未连接的 GeminiLiveElement 设置事件总线并订阅 eventType
+func startGeminiBus(t *testing.T, e *GeminiLiveElement, eventType pipeline.EventType) chan pipeline.Event {
+	t.Helper()
+
+	bus := pipeline.NewEventBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, bus.Start(ctx))
+	t.Cleanup(bus.Stop)
+	e.SetBus(bus)
+
+	ch := make(chan pipeline.Event, 2)
+	bus.Subscribe(eventType, ch)
+	return ch
+}
+
+func receiveEvent(t *testing.T, ch chan pipeline.Event) pipeline.Event {
+	t.Helper()
+	select {
+	case evt := <-ch:
+		return evt
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+		return pipeline.Event{}
+	}
+}
+
+func TestGeminiLiveElement_Grounding(t *testing.T) {
+	e := NewGeminiLiveElementWithConfig(GeminiLiveConfig{APIKey: "test-key", GoogleSearch: true})
+	ch := startGeminiBus(t, e, pipeline.EventGrounding)
+
+	e.handleGrounding(&genai.Part{ExecutableCode: &genai.ExecutableCode{
+		Code: `print(google_search.search(queries=["weather in London"]))`,
+	}})
+	e.handleGrounding(&genai.Part{CodeExecutionResult: &genai.CodeExecutionResult{
+		Output: "Looking up information on Google Search.",
+	}})
+	e.handleGrounding(&genai.Part{Text: "It is sunny."})
+
+	query := receiveEvent(t, ch).Payload.(*pipeline.GroundingPayload)
+	assert.Equal(t, "gemini-live-element", query.Source)
+	assert.Equal(t, []string{"weather in London"}, query.Queries)
+
+	result := receiveEvent(t, ch).Payload.(*pipeline.GroundingPayload)
+	assert.Equal(t, "Looking up information on Google Search.", result.Result)
+
+	select {
+	case evt := <-ch:
+		t.Fatalf("unexpected grounding event: %+v", evt.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGeminiLiveElement_ToolCallCancellation(t *testing.T) {
+	e := NewGeminiLiveElementWithConfig(GeminiLiveConfig{APIKey: "test-key"})
+	e.pendingTools = map[string]string{"7": "get_time", "8": "get_weather"}
+	ch := startGeminiBus(t, e, pipeline.EventToolCallCancelled)
+
+	e.handleToolCallCancellation(&genai.LiveServerToolCallCancellation{IDs: []int64{7}})
+
+	payload := receiveEvent(t, ch).Payload.(*pipeline.ToolCallCancelledPayload)
+	assert.Equal(t, []string{"7"}, payload.CallIDs)
+	assert.Equal(t, map[string]string{"8": "get_weather"}, e.pendingTools)
+
+	// 被取消的调用不再接受结果
+	assert.Error(t, e.SendToolResult("7", "12:00"))
+}
+
+func TestGeminiLiveElement_AppendMessageBeforeConnect(t *testing.T) {
+	e := NewGeminiLiveElementWithConfig(GeminiLiveConfig{APIKey: "test"})
+
+	require.NoError(t, e.AppendMessage(ChatRoleUser, "My name is Ann"))
+	require.NoError(t, e.AppendMessage(ChatRoleAssistant, "Nice to meet you, Ann"))
+	assert.Error(t, e.AppendMessage("tool", "x"))
+
+	// 未连接时缓存，连接后作为不结束轮次的上下文发送
+	require.Len(t, e.pendingHistory, 2)
+	assert.Equal(t, "user", e.pendingHistory[0].Role)
+	assert.Equal(t, "My name is Ann", e.pendingHistory[0].Parts[0].Text)
+	assert.Equal(t, "model", e.pendingHistory[1].Role)
+
+	msg := historyMessage(e.pendingHistory)
+	require.NotNil(t, msg.ClientContent)
+	assert.False(t, msg.ClientContent.TurnComplete)
+	assert.Len(t, msg.ClientContent.Turns, 2)
+}
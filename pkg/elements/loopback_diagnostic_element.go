@@ -0,0 +1,488 @@
+// Package elements provides pipeline processing elements.
+//
+// LoopbackDiagnosticElement 用于排查输出音频断续（"破音"）问题。
+// 它按实时节奏生成已知的测试信号（单音或扫频），送入真实的输出链路
+// （重采样 → Opus → WebRTC），并在链路各处检查音频是否连续，给出可复现、可上报的数据。
+//
+// 工作原理:
+//   - 元素本身是信号源，按 FrameDuration 节奏输出测试信号
+//   - Probe 返回放在输出链路末端（发送给连接之前）的透传元素，检查到达间隔、静音断点、
+//     波形跳变（丢样本/拼接造成的爆音）和帧长是否规整
+//   - 客户端把收到的音频回传时（回环），元素的输入端用同样的方法检查回传音频
+//   - RecordSend 记录连接发送每帧的耗时和错误
+//   - 发现问题时立即打日志，信号结束后输出汇总报告，Report 随时可取
+//
+// 使用示例:
+//
+//	diag := NewLoopbackDiagnosticElement()
+//	resample := NewAudioResampleElement(24000, 48000, 1, 1)
+//	probe := diag.Probe()
+//	p.AddElements([]pipeline.Element{diag, resample, probe})
+//	p.Link(diag, resample)
+//	p.Link(resample, probe)
+//	// ...
+//	report := diag.Report()
+package elements
+
+import (
+	"context"
+	"encoding/binary"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure LoopbackDiagnosticElement implements pipeline.Element
+var _ pipeline.Element = (*LoopbackDiagnosticElement)(nil)
+
+// 测试信号类型
+const (
+	LoopbackSignalTone  = "tone"  // 固定频率正弦波
+	LoopbackSignalSweep = "sweep" // 在 SweepFrom..SweepTo 之间往复的线性扫频
+)
+
+// LoopbackDiagnosticConfig 诊断配置
+type LoopbackDiagnosticConfig struct {
+	Signal     string  // LoopbackSignalTone 或 LoopbackSignalSweep，默认 tone
+	SampleRate int     // 生成信号的采样率，默认 24000（与常见 TTS 输出一致）
+	Amplitude  float64 // 幅度 0..1，默认 0.5
+
+	Frequency   float64       // 单音频率，默认 440Hz
+	SweepFrom   float64       // 扫频起始频率，默认 200Hz
+	SweepTo     float64       // 扫频结束频率，默认 4000Hz
+	SweepPeriod time.Duration // 一次扫频的时长，默认 2s
+
+	Duration      time.Duration // 生成信号的总时长，默认 10s
+	FrameDuration time.Duration // 每帧时长，默认 20ms
+	GapThreshold  time.Duration // 帧到达间隔超过该值记为卡顿，默认 3 帧
+}
+
+// DefaultLoopbackDiagnosticConfig 返回默认配置
+func DefaultLoopbackDiagnosticConfig() LoopbackDiagnosticConfig {
+	return LoopbackDiagnosticConfig{
+		Signal:        LoopbackSignalTone,
+		SampleRate:    24000,
+		Amplitude:     0.5,
+		Frequency:     440,
+		SweepFrom:     200,
+		SweepTo:       4000,
+		SweepPeriod:   2 * time.Second,
+		Duration:      10 * time.Second,
+		FrameDuration: 20 * time.Millisecond,
+		GapThreshold:  60 * time.Millisecond,
+	}
+}
+
+// LoopbackStageReport 链路中一个检查点的统计
+type LoopbackStageReport struct {
+	Frames        int           `json:"frames"`             // 收到的音频帧数
+	Audio         time.Duration `json:"audio_ns"`           // 收到的音频总时长
+	Elapsed       time.Duration `json:"elapsed_ns"`         // 首帧到末帧的时间
+	MaxGap        time.Duration `json:"max_gap_ns"`         // 最大帧到达间隔
+	Gaps          int           `json:"gaps"`               // 到达间隔超过 GapThreshold 的次数
+	DropoutFrames int           `json:"dropout_frames"`     // 信号中间出现的静音帧数
+	Clicks        int           `json:"clicks"`             // 波形跳变（丢样本/拼接）次数
+	OddFrames     int           `json:"odd_frames"`         // 时长不是 FrameDuration 的帧数
+	OddFrameAudio time.Duration `json:"odd_frame_audio_ns"` // 这些帧与 FrameDuration 的偏差总和
+}
+
+// LoopbackSendReport 连接发送的统计
+type LoopbackSendReport struct {
+	Calls   int           `json:"calls"`
+	Errors  int           `json:"errors"`
+	MaxTime time.Duration `json:"max_time_ns"` // 单次发送的最长耗时
+}
+
+// LoopbackReport 诊断报告
+type LoopbackReport struct {
+	Signal    string              `json:"signal"`
+	Generated time.Duration       `json:"generated_ns"` // 已生成的信号时长
+	Done      bool                `json:"done"`         // 信号是否已全部生成
+	Output    LoopbackStageReport `json:"output"`       // 输出链路末端（Probe）
+	Loopback  LoopbackStageReport `json:"loopback"`     // 客户端回传的音频（未回传时为空）
+	Send      LoopbackSendReport  `json:"send"`
+	Problems  int                 `json:"problems"` // 卡顿、静音、跳变的总次数
+}
+
+// LoopbackDiagnosticElement 生成测试信号并检查输出链路的音频连续性
+type LoopbackDiagnosticElement struct {
+	*pipeline.BaseElement
+
+	config  LoopbackDiagnosticConfig
+	maxStep float64 // 测试信号相邻样本的最大差值（归一化）
+
+	mu        sync.Mutex
+	generated time.Duration
+	finished  bool
+	output    *loopbackStage
+	loopback  *loopbackStage
+	send      LoopbackSendReport
+
+	done   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLoopbackDiagnosticElement 使用默认配置创建诊断元素
+func NewLoopbackDiagnosticElement() *LoopbackDiagnosticElement {
+	return NewLoopbackDiagnosticElementWithConfig(DefaultLoopbackDiagnosticConfig())
+}
+
+// NewLoopbackDiagnosticElementWithConfig 使用自定义配置创建诊断元素，未设置的字段使用默认值
+func NewLoopbackDiagnosticElementWithConfig(cfg LoopbackDiagnosticConfig) *LoopbackDiagnosticElement {
+	def := DefaultLoopbackDiagnosticConfig()
+	if cfg.Signal != LoopbackSignalSweep {
+		cfg.Signal = LoopbackSignalTone
+	}
+	if cfg.SampleRate <= 0 {
+		cfg.SampleRate = def.SampleRate
+	}
+	if cfg.Amplitude <= 0 || cfg.Amplitude > 1 {
+		cfg.Amplitude = def.Amplitude
+	}
+	if cfg.Frequency <= 0 {
+		cfg.Frequency = def.Frequency
+	}
+	if cfg.SweepFrom <= 0 {
+		cfg.SweepFrom = def.SweepFrom
+	}
+	if cfg.SweepTo <= 0 {
+		cfg.SweepTo = def.SweepTo
+	}
+	if cfg.SweepPeriod <= 0 {
+		cfg.SweepPeriod = def.SweepPeriod
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = def.Duration
+	}
+	if cfg.FrameDuration <= 0 {
+		cfg.FrameDuration = def.FrameDuration
+	}
+	if cfg.GapThreshold <= 0 {
+		cfg.GapThreshold = 3 * cfg.FrameDuration
+	}
+
+	maxFreq := cfg.Frequency
+	if cfg.Signal == LoopbackSignalSweep {
+		maxFreq = math.Max(cfg.SweepFrom, cfg.SweepTo)
+	}
+
+	e := &LoopbackDiagnosticElement{
+		BaseElement: pipeline.NewBaseElement("loopback-diagnostic-element", 100),
+		config:      cfg,
+		maxStep:     cfg.Amplitude * 2 * math.Pi * maxFreq / float64(cfg.SampleRate),
+		done:        make(chan struct{}),
+	}
+	e.output = newLoopbackStage("output", e)
+	e.loopback = newLoopbackStage("loopback", e)
+	return e
+}
+
+// Probe 返回放在输出链路末端的透传元素，检查到达该处的音频
+func (e *LoopbackDiagnosticElement) Probe() pipeline.Element {
+	return &loopbackProbe{
+		BaseElement: pipeline.NewBaseElement("loopback-diagnostic-probe", 100),
+		diag:        e,
+	}
+}
+
+// RecordSend 记录连接发送一段音频的耗时和结果
+func (e *LoopbackDiagnosticElement) RecordSend(elapsed time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.send.Calls++
+	if err != nil {
+		e.send.Errors++
+		log.Printf("[LoopbackDiag] send error: %v", err)
+	}
+	if elapsed > e.send.MaxTime {
+		e.send.MaxTime = elapsed
+	}
+}
+
+// Done 在信号全部生成并留出排空时间后关闭
+func (e *LoopbackDiagnosticElement) Done() <-chan struct{} {
+	return e.done
+}
+
+// Report 返回当前的诊断报告
+func (e *LoopbackDiagnosticElement) Report() LoopbackReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := LoopbackReport{
+		Signal:    e.config.Signal,
+		Generated: e.generated,
+		Done:      e.finished,
+		Output:    e.output.report,
+		Loopback:  e.loopback.report,
+		Send:      e.send,
+	}
+	for _, s := range []LoopbackStageReport{r.Output, r.Loopback} {
+		r.Problems += s.Gaps + s.DropoutFrames + s.Clicks
+	}
+	r.Problems += r.Send.Errors
+	return r
+}
+
+func (e *LoopbackDiagnosticElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		e.generate(ctx)
+	}()
+	go func() {
+		defer e.wg.Done()
+		// 输入端是客户端回传的音频
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil {
+					e.loopback.observe(msg.AudioData, time.Now())
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *LoopbackDiagnosticElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+// generate 按实时节奏输出测试信号
+func (e *LoopbackDiagnosticElement) generate(ctx context.Context) {
+	frameSamples := int(e.config.FrameDuration * time.Duration(e.config.SampleRate) / time.Second)
+	frames := int(e.config.Duration / e.config.FrameDuration)
+	var phase float64
+	var n int
+
+	log.Printf("[LoopbackDiag] generating %s for %v at %dHz", e.config.Signal, e.config.Duration, e.config.SampleRate)
+
+	ticker := time.NewTicker(e.config.FrameDuration)
+	defer ticker.Stop()
+
+	for i := 0; i < frames; i++ {
+		data := make([]byte, frameSamples*2)
+		for j := 0; j < frameSamples; j++ {
+			v := e.config.Amplitude * math.Sin(phase)
+			binary.LittleEndian.PutUint16(data[j*2:], uint16(int16(v*32767)))
+			phase += 2 * math.Pi * e.frequencyAt(n) / float64(e.config.SampleRate)
+			phase = math.Mod(phase, 2*math.Pi)
+			n++
+		}
+
+		msg := &pipeline.PipelineMessage{
+			Type:      pipeline.MsgTypeAudio,
+			Timestamp: time.Now(),
+			AudioData: &pipeline.AudioData{
+				Data:       data,
+				SampleRate: e.config.SampleRate,
+				Channels:   1,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+				Timestamp:  time.Now(),
+			},
+		}
+		select {
+		case e.BaseElement.OutChan <- msg:
+		case <-ctx.Done():
+			return
+		}
+
+		e.mu.Lock()
+		e.generated += e.config.FrameDuration
+		e.mu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	// 留出时间让链路中的音频排空
+	select {
+	case <-time.After(25 * e.config.FrameDuration):
+	case <-ctx.Done():
+		return
+	}
+
+	e.mu.Lock()
+	e.finished = true
+	e.mu.Unlock()
+
+	r := e.Report()
+	log.Printf("[LoopbackDiag] done: generated %v, output %v in %d frames (max gap %v, %d gaps, %d dropout frames, %d clicks, %d odd frames), send %d calls / %d errors (max %v), loopback %v, %d problems",
+		r.Generated, r.Output.Audio, r.Output.Frames, r.Output.MaxGap, r.Output.Gaps, r.Output.DropoutFrames,
+		r.Output.Clicks, r.Output.OddFrames, r.Send.Calls, r.Send.Errors, r.Send.MaxTime, r.Loopback.Audio, r.Problems)
+	close(e.done)
+}
+
+// frequencyAt 返回第 n 个样本的瞬时频率
+func (e *LoopbackDiagnosticElement) frequencyAt(n int) float64 {
+	if e.config.Signal != LoopbackSignalSweep {
+		return e.config.Frequency
+	}
+	period := int(e.config.SweepPeriod * time.Duration(e.config.SampleRate) / time.Second)
+	pos := float64(n%period) / float64(period)
+	return e.config.SweepFrom + (e.config.SweepTo-e.config.SweepFrom)*pos
+}
+
+// loopbackStage 检查一个检查点收到的音频
+type loopbackStage struct {
+	name string
+	diag *LoopbackDiagnosticElement
+
+	report   LoopbackStageReport
+	first    time.Time
+	last     time.Time
+	prev     float64 // 上一帧最后一个样本（归一化）
+	hasPrev  bool
+	started  bool // 已收到过有信号的帧
+	silences int  // 最近一段连续静音帧数，后面再出现信号时计入 DropoutFrames
+}
+
+func newLoopbackStage(name string, diag *LoopbackDiagnosticElement) *loopbackStage {
+	return &loopbackStage{name: name, diag: diag}
+}
+
+// observe 检查一帧音频，只分析第一个声道
+func (s *loopbackStage) observe(data *pipeline.AudioData, now time.Time) {
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	if data.SampleRate <= 0 || data.Format() != pipeline.SampleFormatS16 || len(data.Data) < 2*channels {
+		return
+	}
+	samples := len(data.Data) / 2 / channels
+	frameDur := time.Duration(samples) * time.Second / time.Duration(data.SampleRate)
+	cfg := s.diag.config
+
+	s.diag.mu.Lock()
+	defer s.diag.mu.Unlock()
+
+	r := &s.report
+	r.Frames++
+	r.Audio += frameDur
+
+	if s.first.IsZero() {
+		s.first = now
+	} else {
+		gap := now.Sub(s.last)
+		if gap > r.MaxGap {
+			r.MaxGap = gap
+		}
+		if gap > cfg.GapThreshold && s.started {
+			r.Gaps++
+			log.Printf("[LoopbackDiag] %s: %v gap between frames at %v", s.name, gap, now.Sub(s.first))
+		}
+	}
+	s.last = now
+	r.Elapsed = now.Sub(s.first)
+
+	if d := frameDur - cfg.FrameDuration; d != 0 {
+		r.OddFrames++
+		if d < 0 {
+			d = -d
+		}
+		r.OddFrameAudio += d
+	}
+
+	// 能量远低于测试信号时视为静音
+	var sum float64
+	jump := 0.0
+	jumpAt := -1
+	prev, hasPrev := s.prev, s.hasPrev
+	for i := 0; i < samples; i++ {
+		v := float64(int16(binary.LittleEndian.Uint16(data.Data[i*2*channels:]))) / 32768
+		sum += v * v
+		if hasPrev && math.Abs(v-prev) > jump {
+			jump = math.Abs(v - prev)
+			jumpAt = i
+		}
+		prev, hasPrev = v, true
+	}
+	s.prev, s.hasPrev = prev, hasPrev
+
+	rms := math.Sqrt(sum / float64(samples))
+	if rms < cfg.Amplitude/math.Sqrt2*0.1 {
+		s.silences++
+		return
+	}
+	if s.started && s.silences > 0 {
+		r.DropoutFrames += s.silences
+		log.Printf("[LoopbackDiag] %s: %d silent frames inside the signal at %v", s.name, s.silences, now.Sub(s.first))
+	}
+	s.started = true
+	s.silences = 0
+
+	// 相邻样本差值远超测试信号的最大斜率，说明丢了样本或拼接错位
+	maxStep := s.diag.maxStep * float64(cfg.SampleRate) / float64(data.SampleRate)
+	if jump > 3*maxStep+0.02 {
+		r.Clicks++
+		log.Printf("[LoopbackDiag] %s: waveform jump %.3f (expected <= %.3f) at sample %d, %v", s.name, jump, maxStep, jumpAt, now.Sub(s.first))
+	}
+}
+
+// loopbackProbe 透传音频并检查到达输出链路末端的音频
+type loopbackProbe struct {
+	*pipeline.BaseElement
+
+	diag *LoopbackDiagnosticElement
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (p *loopbackProbe) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-p.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil {
+					p.diag.output.observe(msg.AudioData, time.Now())
+				}
+
+				select {
+				case p.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *loopbackProbe) Stop() error {
+	if p.cancel != nil {
+		p.cancel()
+		p.wg.Wait()
+		p.cancel = nil
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shortDiagnosticConfig(signal string) LoopbackDiagnosticConfig {
+	cfg := DefaultLoopbackDiagnosticConfig()
+	cfg.Signal = signal
+	cfg.Duration = 200 * time.Millisecond
+	cfg.SweepPeriod = 100 * time.Millisecond
+	return cfg
+}
+
+// collectFrames 运行信号源并收集生成的全部音频帧
+func collectFrames(t *testing.T, diag *LoopbackDiagnosticElement) []*pipeline.AudioData {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, diag.Start(ctx))
+	defer diag.Stop()
+
+	var frames []*pipeline.AudioData
+	for len(frames) < 10 {
+		select {
+		case msg := <-diag.Out():
+			frames = append(frames, msg.AudioData)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for test signal")
+		}
+	}
+	return frames
+}
+
+func TestLoopbackDiagnostic_CleanSignal(t *testing.T) {
+	for _, signal := range []string{LoopbackSignalTone, LoopbackSignalSweep} {
+		t.Run(signal, func(t *testing.T) {
+			diag := NewLoopbackDiagnosticElementWithConfig(shortDiagnosticConfig(signal))
+			probe := diag.Probe()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			require.NoError(t, diag.Start(ctx))
+			defer diag.Stop()
+			require.NoError(t, probe.Start(ctx))
+			defer probe.Stop()
+
+			go func() {
+				for {
+					select {
+					case msg := <-diag.Out():
+						probe.In() <- msg
+					case <-probe.Out():
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+
+			select {
+			case <-diag.Done():
+			case <-time.After(2 * time.Second):
+				t.Fatal("diagnostic did not finish")
+			}
+
+			r := diag.Report()
+			assert.True(t, r.Done)
+			assert.Equal(t, signal, r.Signal)
+			assert.Equal(t, 200*time.Millisecond, r.Generated)
+			assert.Equal(t, 10, r.Output.Frames)
+			assert.Equal(t, 200*time.Millisecond, r.Output.Audio)
+			assert.Equal(t, 0, r.Output.OddFrames)
+			assert.Equal(t, 0, r.Problems, "%+v", r.Output)
+		})
+	}
+}
+
+func TestLoopbackDiagnostic_DetectsProblems(t *testing.T) {
+	frames := collectFrames(t, NewLoopbackDiagnosticElementWithConfig(shortDiagnosticConfig(LoopbackSignalTone)))
+
+	diag := NewLoopbackDiagnosticElementWithConfig(shortDiagnosticConfig(LoopbackSignalTone))
+	now := time.Now()
+	for i, frame := range frames {
+		data := *frame
+		switch i {
+		case 3:
+			// 丢掉帧尾一部分样本，与下一帧拼接处出现跳变
+			data.Data = data.Data[:len(data.Data)/2+2]
+		case 5:
+			// 静音
+			data.Data = make([]byte, len(frame.Data))
+		case 7:
+			// 晚到 100ms
+			now = now.Add(100 * time.Millisecond)
+		}
+		now = now.Add(20 * time.Millisecond)
+		diag.output.observe(&data, now)
+	}
+
+	r := diag.Report().Output
+	assert.Equal(t, 10, r.Frames)
+	assert.Equal(t, 1, r.OddFrames)
+	assert.Equal(t, 1, r.DropoutFrames)
+	assert.Equal(t, 1, r.Gaps)
+	assert.Equal(t, 120*time.Millisecond, r.MaxGap)
+	assert.GreaterOrEqual(t, r.Clicks, 1)
+	assert.Equal(t, r.Gaps+r.DropoutFrames+r.Clicks, diag.Report().Problems)
+}
+
+func TestLoopbackDiagnostic_LoopbackInputAndSends(t *testing.T) {
+	diag := NewLoopbackDiagnosticElementWithConfig(shortDiagnosticConfig(LoopbackSignalTone))
+	frames := collectFrames(t, NewLoopbackDiagnosticElementWithConfig(shortDiagnosticConfig(LoopbackSignalTone)))
+
+	// 客户端回传前的静音不算断点
+	now := time.Now()
+	silence := *frames[0]
+	silence.Data = make([]byte, len(frames[0].Data))
+	for i := 0; i < 3; i++ {
+		now = now.Add(20 * time.Millisecond)
+		diag.loopback.observe(&silence, now)
+	}
+	for _, frame := range frames {
+		now = now.Add(20 * time.Millisecond)
+		diag.loopback.observe(frame, now)
+	}
+
+	diag.RecordSend(time.Millisecond, nil)
+	diag.RecordSend(5*time.Millisecond, errors.New("write failed"))
+
+	r := diag.Report()
+	assert.Equal(t, 13, r.Loopback.Frames)
+	assert.Equal(t, 0, r.Loopback.DropoutFrames)
+	assert.Equal(t, 0, r.Loopback.Clicks)
+	assert.Equal(t, 2, r.Send.Calls)
+	assert.Equal(t, 1, r.Send.Errors)
+	assert.Equal(t, 5*time.Millisecond, r.Send.MaxTime)
+	assert.Equal(t, 1, r.Problems)
+}
@@ -0,0 +1,146 @@
+// Package elements provides pipeline processing elements.
+//
+// LoudnessNormalizeElement 把输出音频的响度归一化到目标 LUFS。
+// 不同的 TTS 声音和服务商输出响度差异很大，归一化后助手的音量在不同声音、
+// 不同回复之间保持一致。
+//
+// 主要功能:
+//   - 按 ITU-R BS.1770 测量最近 3 秒的 K 加权响度，平滑地调整增益
+//   - 回复之间的静音不参与测量，不会被放大
+//   - 增益限制在 ±MaxGainDB 内，输出峰值不超过约 -0.1 dBFS
+//   - 支持 s16/f32 原始 PCM，采样率或通道数变化时重新开始测量
+//   - 非音频消息和编码后的音频原样透传
+//
+// 使用示例（放在输出编码器之前）:
+//
+//	loudness := NewLoudnessNormalizeElement(DefaultLoudnessNormalizeConfig())
+//	p.AddElements([]pipeline.Element{tts, resample, loudness, opusEncode})
+//	p.Link(resample, loudness)
+//	p.Link(loudness, opusEncode)
+package elements
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure LoudnessNormalizeElement implements pipeline.Element
+var _ pipeline.Element = (*LoudnessNormalizeElement)(nil)
+
+// LoudnessNormalizeConfig 配置
+type LoudnessNormalizeConfig struct {
+	TargetLUFS float64 // 目标响度（LUFS），默认 -16，语音类流媒体的常用值
+	MaxGainDB  float64 // 最大增益/衰减（dB），默认 20
+}
+
+// DefaultLoudnessNormalizeConfig 返回默认配置
+func DefaultLoudnessNormalizeConfig() LoudnessNormalizeConfig {
+	return LoudnessNormalizeConfig{
+		TargetLUFS: -16,
+		MaxGainDB:  20,
+	}
+}
+
+// LoudnessNormalizeElement 响度归一化元素
+type LoudnessNormalizeElement struct {
+	*pipeline.BaseElement
+
+	targetLUFS float64
+	maxGainDB  float64
+
+	// 当前格式对应的归一化器，格式变化时重建
+	normalizer *audio.LoudnessNormalizer
+	sampleRate int
+	channels   int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLoudnessNormalizeElement 创建响度归一化元素
+func NewLoudnessNormalizeElement(cfg LoudnessNormalizeConfig) *LoudnessNormalizeElement {
+	defaults := DefaultLoudnessNormalizeConfig()
+	if cfg.TargetLUFS == 0 {
+		cfg.TargetLUFS = defaults.TargetLUFS
+	}
+	if cfg.MaxGainDB <= 0 {
+		cfg.MaxGainDB = defaults.MaxGainDB
+	}
+
+	return &LoudnessNormalizeElement{
+		BaseElement: pipeline.NewBaseElement("loudness-normalize-element", 100),
+		targetLUFS:  cfg.TargetLUFS,
+		maxGainDB:   cfg.MaxGainDB,
+	}
+}
+
+func (e *LoudnessNormalizeElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio {
+					e.normalize(msg.AudioData)
+				}
+
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *LoudnessNormalizeElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+// normalize 对一条原始 PCM 音频就地应用增益
+func (e *LoudnessNormalizeElement) normalize(data *pipeline.AudioData) {
+	if data == nil || len(data.Data) == 0 || !isPCMMediaType(data.MediaType) {
+		return
+	}
+
+	format := data.Format()
+	samples := audio.BytesToFloat32(data.Data, format)
+	if samples == nil {
+		return
+	}
+
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	if e.normalizer == nil || data.SampleRate != e.sampleRate || channels != e.channels {
+		if e.normalizer != nil {
+			log.Printf("[LoudnessNormalize] Format changed to %dHz/%dch, restarting measurement", data.SampleRate, channels)
+		}
+		e.normalizer = audio.NewLoudnessNormalizer(data.SampleRate, channels, e.targetLUFS, e.maxGainDB)
+		e.sampleRate = data.SampleRate
+		e.channels = channels
+	}
+
+	e.normalizer.Process(samples)
+	data.Data = audio.Float32ToBytes(samples, format)
+}
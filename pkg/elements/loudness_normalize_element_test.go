@@ -0,0 +1,93 @@
+package elements
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runLoudnessNormalize feeds 6s of a 48kHz sine at amplitude through the
+// element in 20ms chunks and returns the input and output samples
+func runLoudnessNormalize(t *testing.T, amplitude float64) (in, out []float32) {
+	const sampleRate = 48000
+	elem := NewLoudnessNormalizeElement(DefaultLoudnessNormalizeConfig())
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	in = make([]float32, sampleRate*6)
+	for i := range in {
+		in[i] = float32(amplitude * math.Sin(2*math.Pi*300*float64(i)/sampleRate))
+	}
+
+	const chunk = sampleRate / 50
+	for off := 0; off < len(in); off += chunk {
+		elem.In() <- &pipeline.PipelineMessage{
+			Type: pipeline.MsgTypeAudio,
+			AudioData: &pipeline.AudioData{
+				Data:       audio.Float32ToBytes(in[off:off+chunk], pipeline.SampleFormatS16),
+				SampleRate: sampleRate,
+				Channels:   1,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+			},
+		}
+		select {
+		case msg := <-elem.Out():
+			out = append(out, audio.BytesToFloat32(msg.AudioData.Data, pipeline.SampleFormatS16)...)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for normalized audio")
+		}
+	}
+	return in, out
+}
+
+func TestLoudnessNormalizeElement_Converges(t *testing.T) {
+	const sampleRate = 48000
+	target := DefaultLoudnessNormalizeConfig().TargetLUFS
+
+	quietIn, quietOut := runLoudnessNormalize(t, 0.03)
+	loudIn, loudOut := runLoudnessNormalize(t, 0.6)
+
+	quietBefore := audio.IntegratedLoudness(quietIn, 1, sampleRate)
+	loudBefore := audio.IntegratedLoudness(loudIn, 1, sampleRate)
+	require.Greater(t, loudBefore-quietBefore, 20.0)
+
+	// After the gain has settled both inputs are close to the target and to each other
+	quietAfter := audio.IntegratedLoudness(quietOut[sampleRate*3:], 1, sampleRate)
+	loudAfter := audio.IntegratedLoudness(loudOut[sampleRate*3:], 1, sampleRate)
+	assert.InDelta(t, target, quietAfter, 1.0)
+	assert.InDelta(t, target, loudAfter, 1.0)
+	assert.InDelta(t, quietAfter, loudAfter, 1.0)
+}
+
+func TestLoudnessNormalizeElement_Passthrough(t *testing.T) {
+	elem := NewLoudnessNormalizeElement(LoudnessNormalizeConfig{})
+	assert.Equal(t, -16.0, elem.targetLUFS)
+	assert.Equal(t, 20.0, elem.maxGainDB)
+
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	opus := []byte{1, 2, 3}
+	elem.In() <- &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{Data: opus, MediaType: pipeline.AudioMediaTypeOpus},
+	}
+	elem.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeData, TextData: &pipeline.TextData{Data: []byte("hi")}}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-elem.Out():
+			if msg.AudioData != nil {
+				assert.Equal(t, opus, msg.AudioData.Data)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for passthrough message")
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package elements
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noisePaddedSpeech returns 1s of white noise, 1s of a voiced-like tone
+// complex over the same noise, then 1.5s of noise again, at 16kHz
+func noisePaddedSpeech() []float32 {
+	const sampleRate = 16000
+	rng := rand.New(rand.NewSource(7))
+	samples := make([]float32, sampleRate*7/2)
+	for i := range samples {
+		v := rng.NormFloat64() * 0.03
+		if i >= sampleRate && i < 2*sampleRate {
+			t := float64(i) / sampleRate
+			for h := 1; h <= 4; h++ {
+				v += 0.15 / float64(h) * math.Sin(2*math.Pi*180*float64(h)*t)
+			}
+		}
+		samples[i] = float32(v)
+	}
+	return samples
+}
+
+func rmsOf(samples []float32) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// runNoiseSuppress feeds samples through elem as 20ms s16 frames and returns
+// the output samples
+func runNoiseSuppress(t *testing.T, elem *NoiseSuppressElement, samples []float32) []float32 {
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	var out []float32
+	const chunk = 320
+	for off := 0; off+chunk <= len(samples); off += chunk {
+		elem.In() <- &pipeline.PipelineMessage{
+			Type: pipeline.MsgTypeAudio,
+			AudioData: &pipeline.AudioData{
+				Data:       audio.Float32ToBytes(samples[off:off+chunk], pipeline.SampleFormatS16),
+				SampleRate: 16000,
+				Channels:   1,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+			},
+		}
+		select {
+		case msg := <-elem.Out():
+			require.Equal(t, 16000, msg.AudioData.SampleRate)
+			require.Equal(t, 1, msg.AudioData.Channels)
+			require.Len(t, msg.AudioData.Data, chunk*2)
+			out = append(out, audio.BytesToFloat32(msg.AudioData.Data, pipeline.SampleFormatS16)...)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for denoised audio")
+		}
+	}
+	return out
+}
+
+func TestNoiseSuppressElement_ReducesNoise(t *testing.T) {
+	const sampleRate = 16000
+	in := noisePaddedSpeech()
+	out := runNoiseSuppress(t, NewNoiseSuppressElement(DefaultNoiseSuppressConfig()), in)
+
+	// Noise-only regions, skipping the first 0.5s while the noise estimate settles
+	leadIn := rmsOf(in[sampleRate/2 : sampleRate-sampleRate/10])
+	leadOut := rmsOf(out[sampleRate/2 : sampleRate-sampleRate/10])
+	tailIn := rmsOf(in[2*sampleRate+sampleRate/2:])
+	tailOut := rmsOf(out[2*sampleRate+sampleRate/2:])
+	assert.Less(t, leadOut, leadIn/4, "leading noise should drop by more than 12dB")
+	assert.Less(t, tailOut, tailIn/4, "trailing noise should drop by more than 12dB")
+
+	// Speech keeps most of its level
+	speechIn := rmsOf(in[sampleRate+sampleRate/5 : 2*sampleRate-sampleRate/5])
+	speechOut := rmsOf(out[sampleRate+sampleRate/5 : 2*sampleRate-sampleRate/5])
+	assert.InDelta(t, 1.0, speechOut/speechIn, 0.2)
+}
+
+func TestNoiseSuppressElement_Bypass(t *testing.T) {
+	in := noisePaddedSpeech()[:16000]
+	elem := NewNoiseSuppressElement(NoiseSuppressConfig{Bypass: true})
+	assert.Equal(t, 1.0, elem.strength)
+
+	out := runNoiseSuppress(t, elem, in)
+	want := audio.BytesToFloat32(audio.Float32ToBytes(in, pipeline.SampleFormatS16), pipeline.SampleFormatS16)
+	assert.Equal(t, want, out)
+
+	// Turning bypass off at runtime starts suppressing
+	require.NoError(t, elem.SetProperty("bypass", false))
+	out = runNoiseSuppress(t, elem, in)
+	assert.Less(t, rmsOf(out[8000:]), rmsOf(in[8000:])/4)
+
+	assert.Error(t, elem.SetProperty("strength", 1))
+	require.NoError(t, elem.SetProperty("strength", 0.5))
+	v, err := elem.GetProperty("strength")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, v)
+}
+
+func TestNoiseSuppressElement_Passthrough(t *testing.T) {
+	elem := NewNoiseSuppressElement(DefaultNoiseSuppressConfig())
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	pcm48k := audio.Float32ToBytes(noisePaddedSpeech()[:960], pipeline.SampleFormatS16)
+	for _, data := range []*pipeline.AudioData{
+		{Data: []byte{1, 2, 3}, MediaType: pipeline.AudioMediaTypeOpus},
+		{Data: append([]byte(nil), pcm48k...), SampleRate: 48000, Channels: 1, MediaType: pipeline.AudioMediaTypeRaw},
+	} {
+		original := append([]byte(nil), data.Data...)
+		elem.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeAudio, AudioData: data}
+		select {
+		case msg := <-elem.Out():
+			assert.Equal(t, original, msg.AudioData.Data)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for passthrough audio")
+		}
+	}
+}
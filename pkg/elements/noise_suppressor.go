@@ -0,0 +1,18 @@
+//go:build !rnnoise
+// +build !rnnoise
+
+package elements
+
+import "github.com/realtime-ai/realtime-ai/pkg/audio"
+
+// spectralSuppressor 纯 Go 的谱减法降噪后端
+type spectralSuppressor struct {
+	*audio.SpectralDenoiser
+}
+
+func (spectralSuppressor) Close() {}
+
+// newNoiseSuppressor 创建默认的谱减法降噪后端
+func newNoiseSuppressor(sampleRate int, strength float64) (noiseSuppressor, error) {
+	return spectralSuppressor{audio.NewSpectralDenoiser(sampleRate, strength)}, nil
+}
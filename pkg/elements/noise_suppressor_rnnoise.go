@@ -0,0 +1,144 @@
+//go:build rnnoise
+// +build rnnoise
+
+package elements
+
+/*
+#cgo pkg-config: rnnoise
+#include <rnnoise.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+const (
+	// rnnoiseSampleRate RNNoise 只支持 48kHz
+	rnnoiseSampleRate = 48000
+	// rnnoiseLatencyMs 预先填充的输出（重采样和 10ms 分帧带来的延迟）
+	rnnoiseLatencyMs = 20
+)
+
+// rnnoiseSuppressor 基于 RNNoise 的降噪后端，输入先重采样到 48kHz，
+// 降噪后再重采样回原采样率
+type rnnoiseSuppressor struct {
+	state      *C.DenoiseState
+	frameSize  int
+	sampleRate int
+	strength   float64
+
+	up   *audio.Resample
+	down *audio.Resample
+
+	pending []float32 // 不足一帧的 48kHz 输入
+	dry     []float32 // 上一帧输入，与 RNNoise 一帧延迟后的输出对齐
+	in      []float32 // RNNoise 输入帧（int16 幅度）
+	wet     []float32 // RNNoise 输出帧
+	out     []float32 // 尚未返回的输出
+}
+
+// newNoiseSuppressor 创建 RNNoise 降噪后端
+func newNoiseSuppressor(sampleRate int, strength float64) (noiseSuppressor, error) {
+	up, err := audio.NewResampleWithFormat(sampleRate, rnnoiseSampleRate, channelLayout(1), channelLayout(1), pipeline.SampleFormatF32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upsampler: %w", err)
+	}
+	down, err := audio.NewResampleWithFormat(rnnoiseSampleRate, sampleRate, channelLayout(1), channelLayout(1), pipeline.SampleFormatF32)
+	if err != nil {
+		up.Free()
+		return nil, fmt.Errorf("failed to create downsampler: %w", err)
+	}
+
+	state := C.rnnoise_create(nil)
+	if state == nil {
+		up.Free()
+		down.Free()
+		return nil, fmt.Errorf("failed to create RNNoise state")
+	}
+
+	frameSize := int(C.rnnoise_get_frame_size())
+	s := &rnnoiseSuppressor{
+		state:      state,
+		frameSize:  frameSize,
+		sampleRate: sampleRate,
+		up:         up,
+		down:       down,
+		dry:        make([]float32, frameSize),
+		in:         make([]float32, frameSize),
+		wet:        make([]float32, frameSize),
+	}
+	s.SetStrength(strength)
+	s.Reset()
+	return s, nil
+}
+
+// SetStrength 设置降噪强度，RNNoise 没有强度参数，按强度混合降噪前后的音频
+func (s *rnnoiseSuppressor) SetStrength(strength float64) {
+	s.strength = min(max(strength, 0), 1)
+}
+
+// Reset 清空缓冲的音频；RNNoise 的噪声估计保留，不需要重新学习
+func (s *rnnoiseSuppressor) Reset() {
+	s.pending = s.pending[:0]
+	clear(s.dry)
+	s.out = append(s.out[:0], make([]float32, s.sampleRate*rnnoiseLatencyMs/1000)...)
+}
+
+// Process 降噪并返回与输入相同数量的样本
+func (s *rnnoiseSuppressor) Process(samples []float32) []float32 {
+	if len(samples) > 0 {
+		if upsampled, err := s.up.Resample(audio.Float32ToBytes(samples, pipeline.SampleFormatF32)); err == nil {
+			s.pending = append(s.pending, audio.BytesToFloat32(upsampled, pipeline.SampleFormatF32)...)
+		}
+	}
+
+	var denoised []float32
+	for len(s.pending) >= s.frameSize {
+		frame := s.pending[:s.frameSize]
+		for i, v := range frame {
+			s.in[i] = v * 32768
+		}
+		C.rnnoise_process_frame(s.state, (*C.float)(unsafe.Pointer(&s.wet[0])), (*C.float)(unsafe.Pointer(&s.in[0])))
+
+		for i, v := range s.wet {
+			wet := float64(v) / 32768
+			denoised = append(denoised, float32(s.strength*wet+(1-s.strength)*float64(s.dry[i])))
+		}
+		copy(s.dry, frame)
+		s.pending = s.pending[s.frameSize:]
+	}
+	s.pending = append([]float32(nil), s.pending...)
+
+	if len(denoised) > 0 {
+		if downsampled, err := s.down.Resample(audio.Float32ToBytes(denoised, pipeline.SampleFormatF32)); err == nil {
+			s.out = append(s.out, audio.BytesToFloat32(downsampled, pipeline.SampleFormatF32)...)
+		}
+	}
+
+	// 输出不足时在前面补零，保持输出长度与输入一致
+	n := len(samples)
+	result := make([]float32, n)
+	if len(s.out) >= n {
+		copy(result, s.out[:n])
+		s.out = s.out[n:]
+	} else {
+		copy(result[n-len(s.out):], s.out)
+		s.out = s.out[:0]
+	}
+	return result
+}
+
+// Close 释放 RNNoise 和重采样器
+func (s *rnnoiseSuppressor) Close() {
+	if s.state != nil {
+		C.rnnoise_destroy(s.state)
+		s.state = nil
+	}
+	s.up.Free()
+	s.down.Free()
+}
@@ -1,3 +1,25 @@
+// Package elements provides pipeline processing elements.
+//
+// OpenAIRealtimeAPIElement 通过服务端 WebSocket 连接 OpenAI Realtime API，
+// 音频以 base64 PCM16 在 WebSocket 上双向传输，不依赖 WebRTC。
+// 既可用于 WebRTC 混合链路，也可直接接入 Twilio 等纯 WebSocket 传输，
+// 替代独立的 STT + LLM + TTS 链路。
+//
+// 主要功能:
+//   - 任意采样率的 PCM 输入自动重采样为 24kHz 后发送
+//   - 服务端 VAD，用户开口时发布 EventInterrupted
+//   - 可选流式输出音频增量（电话场景降低首包延迟），并可切成固定时长的帧（OutputChunkMs）
+//   - MsgTypeData 中的 JSON 客户端事件直接透传给 OpenAI
+//   - 函数调用：模型请求调用工具时发布 EventToolCall，结果通过 SendToolResult 交还
+//   - 对话历史：AppendMessage 记入的消息作为 conversation item 发送，连接前调用时先缓存
+//
+// 使用示例:
+//
+//	elem := NewOpenAIRealtimeAPIElementWithConfig(OpenAIRealtimeConfig{
+//	    APIKey:      os.Getenv("OPENAI_API_KEY"),
+//	    StreamAudio: true,
+//	})
+//	p.AddElement(elem)
 package elements
 
 import (
@@ -8,9 +30,11 @@ import (
 	"log"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	openairt "github.com/WqyJh/go-openai-realtime"
+	"github.com/asticode/go-astiav"
 	"github.com/realtime-ai/realtime-ai/pkg/audio"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 	"github.com/sashabaranov/go-openai"
@@ -18,25 +42,76 @@ import (
 
 // Make sure OpenAIRealtimeAPIElement implements pipeline.Element
 var _ pipeline.Element = (*OpenAIRealtimeAPIElement)(nil)
+var _ pipeline.ToolResultSender = (*OpenAIRealtimeAPIElement)(nil)
+var _ pipeline.ConversationRecorder = (*OpenAIRealtimeAPIElement)(nil)
+
+// OpenAI Realtime API 只接受并返回 24kHz 单声道 PCM16
+const openAIRealtimeSampleRate = 24000
+
+// OpenAIRealtimeConfig OpenAI Realtime 元素配置
+type OpenAIRealtimeConfig struct {
+	APIKey       string          // OpenAI API key（为空时读取 OPENAI_API_KEY）
+	Model        string          // Realtime 模型（为空时使用库默认模型）
+	BaseURL      string          // Realtime WebSocket 地址（为空时使用 OpenAI 官方地址，可指向代理或兼容服务）
+	Voice        openairt.Voice  // 输出音色（默认 shimmer）
+	Instructions string          // 系统指令
+	StreamAudio  bool            // 逐个输出音频增量，而不是在响应结束时整段输出
+	Tools        []openairt.Tool // 模型可调用的函数，调用通过 EventToolCall 发布
+
+	// OutputChunkMs 把模型返回的音频切成固定时长的帧（如 20ms）再输出，
+	// 避免大块突发的音频冲击下游的 pacer；0 表示按服务商返回的块原样输出
+	OutputChunkMs int
+}
+
+// DefaultOpenAIRealtimeConfig 返回默认配置
+func DefaultOpenAIRealtimeConfig() OpenAIRealtimeConfig {
+	return OpenAIRealtimeConfig{
+		Voice: openairt.VoiceShimmer,
+	}
+}
 
 type OpenAIRealtimeAPIElement struct {
 	*pipeline.BaseElement
 
+	config    OpenAIRealtimeConfig
 	conn      *openairt.Conn
 	sessionID string
 	dumper    *audio.Dumper
 
+	// 输入重采样（输入采样率不是 24kHz 时按需创建）
+	resampler       *audio.Resample
+	resamplerInRate int
+
+	// 连接读循环是否仍在运行
+	alive atomic.Bool
+
+	// 连接建立前通过 AppendMessage 记入的历史，连接后按顺序发送
+	historyMu      sync.Mutex
+	pendingHistory []openairt.MessageItem
+
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
 func NewOpenAIRealtimeAPIElement() *OpenAIRealtimeAPIElement {
+	return NewOpenAIRealtimeAPIElementWithConfig(DefaultOpenAIRealtimeConfig())
+}
+
+// NewOpenAIRealtimeAPIElementWithConfig 使用指定配置创建 OpenAI Realtime 元素
+func NewOpenAIRealtimeAPIElementWithConfig(config OpenAIRealtimeConfig) *OpenAIRealtimeAPIElement {
 	var dumper *audio.Dumper
 	var err error
 
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if config.Voice == "" {
+		config.Voice = openairt.VoiceShimmer
+	}
+
 	// 如果环境变量设置了，就创建dumper
 	if os.Getenv("DUMP_OPENAI_AUDIO") == "true" {
-		dumper, err = audio.NewDumper("openai_response", 24000, 1)
+		dumper, err = audio.NewDumper("openai_response", openAIRealtimeSampleRate, 1)
 		if err != nil {
 			log.Printf("create audio dumper error: %v", err)
 		} else {
@@ -46,24 +121,46 @@ func NewOpenAIRealtimeAPIElement() *OpenAIRealtimeAPIElement {
 
 	return &OpenAIRealtimeAPIElement{
 		BaseElement: pipeline.NewBaseElement("openai-realtime-element", 100),
+		config:      config,
 		dumper:      dumper,
 	}
 }
 
-func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
+// OutputFormat 返回模型回复音频的格式（24kHz 单声道）
+func (e *OpenAIRealtimeAPIElement) OutputFormat() pipeline.AudioFormat {
+	return pipeline.AudioFormat{SampleRate: openAIRealtimeSampleRate, Channels: 1}
+}
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	// baseURL := os.Getenv("OPENAI_BASE_URL")
+func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
+	clientConfig := openairt.DefaultConfig(e.config.APIKey)
+	if e.config.BaseURL != "" {
+		clientConfig.BaseURL = e.config.BaseURL
+	}
+	client := openairt.NewClientWithConfig(clientConfig)
 
-	client := openairt.NewClient(apiKey)
-	// if baseURL != "" {
-	// 	client.BaseURL = baseURL
-	// }
-	conn, err := client.Connect(context.Background())
+	var opts []openairt.ConnectOption
+	if e.config.Model != "" {
+		opts = append(opts, openairt.WithModel(e.config.Model))
+	}
+	conn, err := client.Connect(context.Background(), opts...)
 	if err != nil {
 		return err
 	}
+
+	e.historyMu.Lock()
 	e.conn = conn
+	history := e.pendingHistory
+	e.pendingHistory = nil
+	for _, item := range history {
+		if err := conn.SendMessage(ctx, openairt.ConversationItemCreateEvent{Item: item}); err != nil {
+			log.Printf("[OpenAIRealtime] send conversation history error: %v", err)
+			break
+		}
+	}
+	e.historyMu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
 
 	responseDeltaHandler := func(ctx context.Context, event openairt.ServerEvent) {
 		switch event.ServerEventType() {
@@ -85,6 +182,24 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 		}
 	}
 
+	// Function calls: the result must be sent back with SendToolResult
+	toolCallHandler := func(ctx context.Context, event openairt.ServerEvent) {
+		if event.ServerEventType() != openairt.ServerEventTypeResponseFunctionCallArgumentsDone {
+			return
+		}
+		call := event.(openairt.ResponseFunctionCallArgumentsDoneEvent)
+		log.Printf("[OpenAIRealtime] tool call: %s (call_id=%s)", call.Name, call.CallID)
+		e.Bus().Publish(pipeline.Event{
+			Type:      pipeline.EventToolCall,
+			Timestamp: time.Now(),
+			Payload: &pipeline.ToolCallPayload{
+				CallID:    call.CallID,
+				Name:      call.Name,
+				Arguments: call.Arguments,
+			},
+		})
+	}
+
 	// Log handler
 	logHandler := func(ctx context.Context, event openairt.ServerEvent) {
 
@@ -100,6 +215,7 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 	}
 
 	audiobuffer := make([]byte, 0)
+	rechunker := newAudioRechunker(e.config.OutputChunkMs, openAIRealtimeSampleRate, 1)
 
 	audioResponseHandler := func(ctx context.Context, event openairt.ServerEvent) {
 		switch event.ServerEventType() {
@@ -110,7 +226,6 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 			if err != nil {
 				log.Fatal(err)
 			}
-			audiobuffer = append(audiobuffer, data...)
 
 			// dump 音频数据
 			if e.dumper != nil {
@@ -119,28 +234,31 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 				}
 			}
 
+			if e.config.StreamAudio {
+				for _, frame := range rechunker.Write(data) {
+					e.sendAudio(ctx, frame)
+				}
+			} else {
+				audiobuffer = append(audiobuffer, data...)
+			}
+
 		case openairt.ServerEventTypeResponseAudioDone:
 
 			data := audiobuffer[:]
 			audiobuffer = make([]byte, 0)
 
-			e.BaseElement.OutChan <- &pipeline.PipelineMessage{
-				Type:      pipeline.MsgTypeAudio,
-				SessionID: e.sessionID,
-				Timestamp: time.Now(),
-				AudioData: &pipeline.AudioData{
-					Data:       data,
-					MediaType:  pipeline.AudioMediaTypeRaw,
-					SampleRate: 24000, // AI 返回的采样率
-					Channels:   1,     // AI 返回的通道数
-					Timestamp:  time.Now(),
-				},
+			for _, frame := range rechunker.Write(data) {
+				e.sendAudio(ctx, frame)
+			}
+			if rest := rechunker.Flush(); len(rest) > 0 {
+				e.sendAudio(ctx, rest)
 			}
 
 		case openairt.ServerEventTypeInputAudioBufferSpeechStarted:
 			// Interrupt the current response
 			msg := event.(openairt.InputAudioBufferSpeechStartedEvent)
 			log.Println("AI session interrupted")
+			rechunker.Reset()
 			e.Bus().Publish(pipeline.Event{
 				Type:      pipeline.EventInterrupted,
 				Timestamp: time.Now(),
@@ -151,13 +269,25 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 		}
 	}
 
-	connHandler := openairt.NewConnHandler(ctx, conn, logHandler, responseHandler, responseDeltaHandler, audioResponseHandler)
+	connHandler := openairt.NewConnHandler(ctx, conn, logHandler, responseHandler, responseDeltaHandler, audioResponseHandler, toolCallHandler)
 	connHandler.Start()
+	e.alive.Store(true)
+
+	// 读循环退出（连接出错或关闭）后标记为不健康
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if err, ok := <-connHandler.Err(); ok && err != nil {
+			log.Printf("[OpenAIRealtime] connection error: %v", err)
+		}
+		e.alive.Store(false)
+	}()
 
 	conn.SendMessage(ctx, openairt.SessionUpdateEvent{
 		Session: openairt.ClientSession{
 			Modalities:        []openairt.Modality{openairt.ModalityText, openairt.ModalityAudio},
-			Voice:             openairt.VoiceShimmer,
+			Instructions:      e.config.Instructions,
+			Voice:             e.config.Voice,
 			OutputAudioFormat: openairt.AudioFormatPcm16,
 			InputAudioTranscription: &openairt.InputAudioTranscription{
 				Model: openai.Whisper1,
@@ -169,6 +299,7 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 					SilenceDurationMs: 800,
 				},
 			},
+			Tools:           e.config.Tools,
 			MaxOutputTokens: 4000,
 		},
 	})
@@ -185,7 +316,7 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 
 				if msg.Type == pipeline.MsgTypeAudio {
 
-					if msg.AudioData.MediaType != pipeline.AudioMediaTypeRaw {
+					if msg.AudioData == nil || !isPCMMediaType(msg.AudioData.MediaType) {
 						continue
 					}
 
@@ -197,9 +328,15 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 					// 保存会话ID
 					e.sessionID = msg.SessionID
 
-					// 将 PCM data 发送给 AI
+					data, err := e.resampleInput(msg.AudioData)
+					if err != nil {
+						log.Println("AI session resample error:", err)
+						continue
+					}
+
+					// 将 PCM data 以 base64 发送给 AI
 					if e.conn != nil {
-						base64Audio := base64.StdEncoding.EncodeToString(msg.AudioData.Data)
+						base64Audio := base64.StdEncoding.EncodeToString(data)
 						conn.SendMessage(ctx, openairt.InputAudioBufferAppendEvent{
 							Audio: base64Audio,
 						})
@@ -228,6 +365,83 @@ func (e *OpenAIRealtimeAPIElement) Start(ctx context.Context) error {
 	return nil
 }
 
+// Healthy 报告与 OpenAI Realtime API 的连接是否仍然存活，实现 pipeline.HealthChecker
+func (e *OpenAIRealtimeAPIElement) Healthy() bool {
+	return e.alive.Load()
+}
+
+// SendToolResult 把工具执行结果交还模型并触发新的响应，实现 pipeline.ToolResultSender
+// result 为 string 时原样作为输出，其他值编码为 JSON
+func (e *OpenAIRealtimeAPIElement) SendToolResult(callID string, result any) error {
+	conn := e.conn
+	if conn == nil {
+		return fmt.Errorf("openai realtime not connected")
+	}
+
+	output, err := toolResultString(result)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := conn.SendMessage(ctx, openairt.ConversationItemCreateEvent{
+		Item: openairt.MessageItem{
+			Type:   openairt.MessageItemTypeFunctionCallOutput,
+			CallID: callID,
+			Output: output,
+		},
+	}); err != nil {
+		return fmt.Errorf("send tool result: %w", err)
+	}
+	if err := conn.SendMessage(ctx, openairt.ResponseCreateEvent{}); err != nil {
+		return fmt.Errorf("request response after tool result: %w", err)
+	}
+	return nil
+}
+
+// AppendMessage 把一条历史消息作为 conversation item 发给模型，实现 pipeline.ConversationRecorder
+// 连接建立前调用时先缓存，Start 连接成功后按顺序发送；历史不会触发模型回复
+func (e *OpenAIRealtimeAPIElement) AppendMessage(role, content string) error {
+	item, err := historyItem(role, content)
+	if err != nil {
+		return err
+	}
+
+	e.historyMu.Lock()
+	defer e.historyMu.Unlock()
+	if e.conn == nil {
+		e.pendingHistory = append(e.pendingHistory, item)
+		return nil
+	}
+	if err := e.conn.SendMessage(context.Background(), openairt.ConversationItemCreateEvent{Item: item}); err != nil {
+		return fmt.Errorf("send conversation history: %w", err)
+	}
+	return nil
+}
+
+// historyItem 把一条历史消息转换为 Realtime API 的消息 item
+// 助手消息使用 text 内容，用户和系统消息使用 input_text
+func historyItem(role, content string) (openairt.MessageItem, error) {
+	var itemRole openairt.MessageRole
+	contentType := openairt.MessageContentTypeInputText
+	switch role {
+	case ChatRoleUser:
+		itemRole = openairt.MessageRoleUser
+	case ChatRoleSystem:
+		itemRole = openairt.MessageRoleSystem
+	case ChatRoleAssistant:
+		itemRole = openairt.MessageRoleAssistant
+		contentType = openairt.MessageContentTypeText
+	default:
+		return openairt.MessageItem{}, fmt.Errorf("unsupported role: %q", role)
+	}
+	return openairt.MessageItem{
+		Type:    openairt.MessageItemTypeMessage,
+		Role:    itemRole,
+		Content: []openairt.MessageContentPart{{Type: contentType, Text: content}},
+	}, nil
+}
+
 func (e *OpenAIRealtimeAPIElement) Stop() error {
 	if e.cancel != nil {
 		e.cancel()
@@ -235,6 +449,19 @@ func (e *OpenAIRealtimeAPIElement) Stop() error {
 		e.cancel = nil
 	}
 
+	e.historyMu.Lock()
+	if e.conn != nil {
+		e.conn.Close()
+		e.conn = nil
+	}
+	e.historyMu.Unlock()
+
+	if e.resampler != nil {
+		e.resampler.Free()
+		e.resampler = nil
+		e.resamplerInRate = 0
+	}
+
 	// 关闭 dumper
 	if e.dumper != nil {
 		e.dumper.Close()
@@ -244,6 +471,51 @@ func (e *OpenAIRealtimeAPIElement) Stop() error {
 	return nil
 }
 
+// sendAudio 将 AI 返回的 24kHz PCM 音频投递给下一环节
+func (e *OpenAIRealtimeAPIElement) sendAudio(ctx context.Context, data []byte) {
+	msg := &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		SessionID: e.sessionID,
+		Timestamp: time.Now(),
+		AudioData: &pipeline.AudioData{
+			Data:       data,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+			SampleRate: openAIRealtimeSampleRate, // AI 返回的采样率
+			Channels:   1,                        // AI 返回的通道数
+			Timestamp:  time.Now(),
+		},
+	}
+
+	select {
+	case e.BaseElement.OutChan <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// resampleInput 将输入 PCM 转换为 OpenAI 要求的 24kHz 单声道
+// 未标注采样率的音频视为已经是 24kHz
+func (e *OpenAIRealtimeAPIElement) resampleInput(data *pipeline.AudioData) ([]byte, error) {
+	if data.SampleRate == 0 || data.SampleRate == openAIRealtimeSampleRate {
+		return data.Data, nil
+	}
+
+	if e.resampler == nil || e.resamplerInRate != data.SampleRate {
+		if e.resampler != nil {
+			e.resampler.Free()
+		}
+		resampler, err := audio.NewResample(data.SampleRate, openAIRealtimeSampleRate,
+			astiav.ChannelLayoutMono, astiav.ChannelLayoutMono)
+		if err != nil {
+			e.resampler = nil
+			return nil, fmt.Errorf("create resampler %dHz -> %dHz: %w", data.SampleRate, openAIRealtimeSampleRate, err)
+		}
+		e.resampler = resampler
+		e.resamplerInRate = data.SampleRate
+	}
+
+	return e.resampler.Resample(data.Data)
+}
+
 // UnmarshalClientEvent unmarshals the client event from the given JSON data.
 func UnmarshalClientEvent(data []byte) (openairt.ClientEvent, error) {
 	var eventType struct {
@@ -0,0 +1,167 @@
+package elements
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openAIRealtimeTestServer is a mock OpenAI Realtime WebSocket endpoint
+type openAIRealtimeTestServer struct {
+	url     string
+	auth    chan string // Authorization header of each connection
+	appends chan string // base64 audio of each input_audio_buffer.append
+	events  chan []byte // server events to send to the client
+}
+
+func startOpenAIRealtimeTestServer(t *testing.T) *openAIRealtimeTestServer {
+	t.Helper()
+	s := &openAIRealtimeTestServer{
+		auth:    make(chan string, 1),
+		appends: make(chan string, 10),
+		events:  make(chan []byte, 10),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.auth <- r.Header.Get("Authorization")
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				select {
+				case event := <-s.events:
+					conn.WriteMessage(websocket.TextMessage, event)
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var event struct {
+				Type  string `json:"type"`
+				Audio string `json:"audio"`
+			}
+			if json.Unmarshal(msg, &event) == nil && event.Type == "input_audio_buffer.append" {
+				s.appends <- event.Audio
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	s.url = "ws" + strings.TrimPrefix(server.URL, "http")
+	return s
+}
+
+// send queues a server event for the connected client
+func (s *openAIRealtimeTestServer) send(event map[string]interface{}) {
+	data, _ := json.Marshal(event)
+	s.events <- data
+}
+
+// rampPCM returns 16-bit PCM filled with a byte ramp, so reordering or truncation shows
+func rampPCM(samples int) []byte {
+	data := make([]byte, samples*2)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	return data
+}
+
+func TestOpenAIRealtimeAPIElement_Base64Audio(t *testing.T) {
+	server := startOpenAIRealtimeTestServer(t)
+
+	elem := NewOpenAIRealtimeAPIElementWithConfig(OpenAIRealtimeConfig{
+		APIKey:      "test-api-key",
+		BaseURL:     server.url,
+		StreamAudio: true,
+	})
+	bus := pipeline.NewEventBus()
+	interrupted := make(chan pipeline.Event, 1)
+	bus.Subscribe(pipeline.EventInterrupted, interrupted)
+	elem.SetBus(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	require.NoError(t, elem.Start(ctx))
+	defer elem.Stop()
+
+	select {
+	case auth := <-server.auth:
+		assert.Equal(t, "Bearer test-api-key", auth)
+	case <-time.After(2 * time.Second):
+		t.Fatal("element did not connect to the realtime endpoint")
+	}
+
+	// Caller audio is sent as base64 input_audio_buffer.append events
+	input := rampPCM(480)
+	elem.In() <- &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		SessionID: "call-1",
+		AudioData: &pipeline.AudioData{
+			Data:       input,
+			SampleRate: openAIRealtimeSampleRate,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+	select {
+	case appended := <-server.appends:
+		assert.Equal(t, base64.StdEncoding.EncodeToString(input), appended)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no input_audio_buffer.append received")
+	}
+
+	// Model audio arrives as base64 response.audio.delta events and is output as PCM
+	reply := rampPCM(240)
+	server.send(map[string]interface{}{
+		"type":          "response.audio.delta",
+		"event_id":      "event_1",
+		"response_id":   "resp_1",
+		"item_id":       "item_1",
+		"output_index":  0,
+		"content_index": 0,
+		"delta":         base64.StdEncoding.EncodeToString(reply),
+	})
+	select {
+	case msg := <-elem.Out():
+		require.Equal(t, pipeline.MsgTypeAudio, msg.Type)
+		assert.Equal(t, reply, msg.AudioData.Data)
+		assert.Equal(t, openAIRealtimeSampleRate, msg.AudioData.SampleRate)
+		assert.Equal(t, "call-1", msg.SessionID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no audio output for response.audio.delta")
+	}
+
+	// Server VAD detecting the caller interrupts the response
+	server.send(map[string]interface{}{
+		"type":           "input_audio_buffer.speech_started",
+		"event_id":       "event_2",
+		"audio_start_ms": 100,
+		"item_id":        "item_2",
+	})
+	select {
+	case <-interrupted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("speech_started did not publish EventInterrupted")
+	}
+}
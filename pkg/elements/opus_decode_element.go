@@ -13,41 +13,95 @@ import (
 	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
+const (
+	// opusCNGFrame 舒适噪声帧时长
+	opusCNGFrame = 20 * time.Millisecond
+
+	// opusCNGSlackFrames 开始填充前容忍的帧数：上一个包本身的时长加一帧网络抖动，
+	// 避免正常但稍晚到达的包被当成 DTX 空隙
+	opusCNGSlackFrames = 2
+
+	// opusDTXMaxGap DTX 期间编码器至少每 400ms 发送一次舒适噪声更新，
+	// 留出连续丢失一个更新包加网络抖动的余量，超过 1s 没有收到包视为发送端已停止，不再填充
+	opusDTXMaxGap = time.Second
+
+	// opusPLCMaxFrames 一次丢包最多隐藏的帧数，更长的中断（如发送端暂停）不再插值
+	opusPLCMaxFrames = 5
+)
+
+// OpusDecodeConfig Opus 解码配置
+type OpusDecodeConfig struct {
+	SampleRate int
+	Channels   int
+
+	// ComfortNoise 在发送端启用 DTX 时，用 Opus 内置的舒适噪声（PLC/CNG）
+	// 实时填补包之间的空隙，下游 VAD/STT 仍能收到连续的音频。
+	// 包到达时间不规则由这里吸收：晚到 opusCNGSlackFrames 帧以内不填充，之后按经过的时间补齐，
+	// 下游不需要额外的抖动缓冲（AudioPacer 的抖动缓冲在编码之前，不会看到 DTX 空隙）
+	ComfortNoise bool
+
+	// PacketLossConcealment 根据 RTP 序列号（AudioData.RTPSequence）检测丢包，
+	// 用 Opus PLC 插值补齐丢失的帧，最后一帧优先用下一个包携带的 FEC 数据恢复，
+	// 避免丢包处出现空隙和爆音。迟到的乱序包会被丢弃
+	PacketLossConcealment bool
+}
+
 type OpusDecodeElement struct {
 	*pipeline.BaseElement
 
-	decoder    *opus.Decoder
-	sampleRate int
-	channels   int
-	dumper     *audio.Dumper
+	decoder      *opus.Decoder
+	sampleRate   int
+	channels     int
+	comfortNoise bool
+	plc          bool
+	dumper       *audio.Dumper
+
+	// 丢包检测状态
+	lastSeq uint16
+	haveSeq bool
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
 func NewOpusDecodeElement(sampleRate int, channels int) *OpusDecodeElement {
-	decoder, err := opus.NewDecoder(sampleRate, channels)
+	return NewOpusDecodeElementWithConfig(OpusDecodeConfig{
+		SampleRate: sampleRate,
+		Channels:   channels,
+	})
+}
+
+// NewOpusDecodeElementWithConfig 使用自定义配置创建 OpusDecodeElement
+func NewOpusDecodeElementWithConfig(cfg OpusDecodeConfig) *OpusDecodeElement {
+	decoder, err := opus.NewDecoder(cfg.SampleRate, cfg.Channels)
 	if err != nil {
 		log.Fatalf("failed to create opus decoder: %v", err)
 	}
 
 	var dumper *audio.Dumper
 	if os.Getenv("DUMP_OPUS_DECODED") == "true" {
-		dumper, err = audio.NewDumper("opus_decoded", sampleRate, channels)
+		dumper, err = audio.NewDumper("opus_decoded", cfg.SampleRate, cfg.Channels)
 		if err != nil {
 			log.Printf("create audio dumper error: %v", err)
 		}
 	}
 
 	return &OpusDecodeElement{
-		BaseElement: pipeline.NewBaseElement("opus-decode-element", 100),
-		decoder:     decoder,
-		sampleRate:  sampleRate,
-		channels:    channels,
-		dumper:      dumper,
+		BaseElement:  pipeline.NewBaseElement("opus-decode-element", 100),
+		decoder:      decoder,
+		sampleRate:   cfg.SampleRate,
+		channels:     cfg.Channels,
+		comfortNoise: cfg.ComfortNoise,
+		plc:          cfg.PacketLossConcealment,
+		dumper:       dumper,
 	}
 }
 
+// OutputFormat 返回解码输出的采样率和通道数
+func (e *OpusDecodeElement) OutputFormat() pipeline.AudioFormat {
+	return pipeline.AudioFormat{SampleRate: e.sampleRate, Channels: e.channels}
+}
+
 func (e *OpusDecodeElement) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	e.cancel = cancel
@@ -57,10 +111,34 @@ func (e *OpusDecodeElement) Start(ctx context.Context) error {
 		defer e.wg.Done()
 
 		pcmBuf := make([]int16, 1920) // stereo * 960
+
+		// 舒适噪声：包到达的间隔可能不规则，按实际经过的时间补齐缺少的帧
+		var ticker <-chan time.Time
+		if e.comfortNoise {
+			t := time.NewTicker(opusCNGFrame)
+			defer t.Stop()
+			ticker = t.C
+		}
+		var lastPacket time.Time          // 最近一次收到包的时间
+		var lastSession string            // 最近一个包的 SessionID
+		var lastAttrs pipeline.Attributes // 最近一个包的 Attributes
+		var filled int                    // 此后已填充的舒适噪声帧数
+
 		for {
 			select {
 			case <-ctx.Done():
 				return
+			case <-ticker:
+				if lastPacket.IsZero() {
+					continue
+				}
+				due := comfortNoiseFrames(time.Since(lastPacket), filled)
+				for i := 0; i < due; i++ {
+					if !e.emitComfortNoise(ctx, lastSession, lastAttrs, pcmBuf) {
+						return
+					}
+					filled++
+				}
 			case msg := <-e.BaseElement.InChan:
 				if msg.Type != pipeline.MsgTypeAudio {
 					continue
@@ -74,40 +152,31 @@ func (e *OpusDecodeElement) Start(ctx context.Context) error {
 					continue
 				}
 
+				// 丢包隐藏：舒适噪声已按时间填充过的帧不再重复补
+				if e.plc && msg.AudioData.HasRTPSequence {
+					lost, ok := e.checkSequence(msg.AudioData.RTPSequence)
+					if !ok {
+						continue
+					}
+					if lost -= filled; lost > 0 {
+						if !e.conceal(ctx, msg, lost, pcmBuf) {
+							return
+						}
+					}
+				}
+
 				// 解码
 				n, err := e.decoder.Decode(msg.AudioData.Data, pcmBuf)
 				if err != nil {
 					log.Println("Opus decode error:", err)
 					continue
 				}
+				lastPacket = time.Now()
+				lastSession = msg.SessionID
+				lastAttrs = msg.Attributes
+				filled = 0
 
-				audioData := utils.Int16SliceToByteSlice(pcmBuf[:n])
-
-				// dump 音频数据
-				if e.dumper != nil {
-					if err := e.dumper.Write(audioData); err != nil {
-						log.Printf("Failed to dump audio: %v", err)
-					}
-				}
-
-				// 创建输出消息
-				outMsg := &pipeline.PipelineMessage{
-					Type:      pipeline.MsgTypeAudio,
-					SessionID: msg.SessionID,
-					Timestamp: time.Now(),
-					AudioData: &pipeline.AudioData{
-						Data:       audioData,
-						MediaType:  pipeline.AudioMediaTypeRaw,
-						SampleRate: e.sampleRate,
-						Channels:   e.channels,
-						Timestamp:  time.Now(),
-					},
-				}
-
-				// 输出
-				select {
-				case e.BaseElement.OutChan <- outMsg:
-				case <-ctx.Done():
+				if !e.emit(ctx, msg.SessionID, msg.Attributes, pcmBuf[:n]) {
 					return
 				}
 			}
@@ -116,6 +185,130 @@ func (e *OpusDecodeElement) Start(ctx context.Context) error {
 	return nil
 }
 
+// comfortNoiseFrames 返回距上一个包 gap 时长内还需填充的舒适噪声帧数
+// 超过 opusDTXMaxGap 后不再填充
+func comfortNoiseFrames(gap time.Duration, filled int) int {
+	if gap > opusDTXMaxGap {
+		return 0
+	}
+	due := int(gap/opusCNGFrame) - opusCNGSlackFrames - filled
+	if due < 0 {
+		return 0
+	}
+	return due
+}
+
+// packetsLost 返回 RTP 序列号 prev 与 seq 之间丢失的包数，序列号按 16 位回绕
+// seq 不比 prev 新（重复或迟到的乱序包）时返回 -1
+func packetsLost(prev, seq uint16) int {
+	delta := seq - prev
+	if delta == 0 || delta >= 1<<15 {
+		return -1
+	}
+	return int(delta) - 1
+}
+
+// checkSequence 记录收到的序列号并返回丢失的包数，ok 为 false 表示该包迟到，应丢弃
+func (e *OpusDecodeElement) checkSequence(seq uint16) (lost int, ok bool) {
+	if !e.haveSeq {
+		e.lastSeq = seq
+		e.haveSeq = true
+		return 0, true
+	}
+
+	lost = packetsLost(e.lastSeq, seq)
+	if lost < 0 {
+		log.Printf("[OpusDecode] Dropping late packet seq=%d (last %d)", seq, e.lastSeq)
+		return 0, false
+	}
+	e.lastSeq = seq
+	return lost, true
+}
+
+// conceal 在解码 msg 之前补齐 lost 个丢失的帧：前面的帧用 PLC 插值，
+// 紧邻 msg 的一帧用 msg 中的 FEC 数据恢复（没有 FEC 时 libopus 自动退化为 PLC）
+func (e *OpusDecodeElement) conceal(ctx context.Context, msg *pipeline.PipelineMessage, lost int, pcmBuf []int16) bool {
+	useFEC := lost <= opusPLCMaxFrames
+	if !useFEC {
+		log.Printf("[OpusDecode] %d packets lost, concealing %d", lost, opusPLCMaxFrames)
+		lost = opusPLCMaxFrames
+	}
+
+	// 丢失的帧按上一个包的时长补齐
+	frameSamples, err := e.decoder.LastPacketDuration()
+	if err != nil || frameSamples <= 0 {
+		frameSamples = e.sampleRate * int(opusCNGFrame/time.Millisecond) / 1000
+	}
+	size := frameSamples * e.channels
+	if size > len(pcmBuf) {
+		size = len(pcmBuf) - len(pcmBuf)%e.channels
+	}
+
+	for i := 0; i < lost; i++ {
+		frame := pcmBuf[:size:size]
+		if useFEC && i == lost-1 {
+			err = e.decoder.DecodeFEC(msg.AudioData.Data, frame)
+		} else {
+			err = e.decoder.DecodePLC(frame)
+		}
+		if err != nil {
+			log.Println("Opus packet loss concealment error:", err)
+			return true
+		}
+		if !e.emit(ctx, msg.SessionID, msg.Attributes, frame) {
+			return false
+		}
+	}
+	return true
+}
+
+// emitComfortNoise 用 Opus PLC 生成一帧舒适噪声并输出
+func (e *OpusDecodeElement) emitComfortNoise(ctx context.Context, sessionID string, attrs pipeline.Attributes, pcmBuf []int16) bool {
+	// DecodePLC 按容量决定生成的时长
+	size := e.sampleRate * int(opusCNGFrame/time.Millisecond) / 1000 * e.channels
+	frame := pcmBuf[:size:size]
+	if err := e.decoder.DecodePLC(frame); err != nil {
+		log.Println("Opus comfort noise error:", err)
+		return true
+	}
+	return e.emit(ctx, sessionID, attrs, frame)
+}
+
+// emit 输出一帧 PCM，ctx 结束时返回 false
+func (e *OpusDecodeElement) emit(ctx context.Context, sessionID string, attrs pipeline.Attributes, pcm []int16) bool {
+	audioData := utils.Int16SliceToByteSlice(pcm)
+
+	// dump 音频数据
+	if e.dumper != nil {
+		if err := e.dumper.Write(audioData); err != nil {
+			log.Printf("Failed to dump audio: %v", err)
+		}
+	}
+
+	// 创建输出消息
+	outMsg := &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeAudio,
+		SessionID:  sessionID,
+		Timestamp:  time.Now(),
+		Attributes: attrs,
+		AudioData: &pipeline.AudioData{
+			Data:       audioData,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+			SampleRate: e.sampleRate,
+			Channels:   e.channels,
+			Timestamp:  time.Now(),
+		},
+	}
+
+	// 输出
+	select {
+	case e.BaseElement.OutChan <- outMsg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (e *OpusDecodeElement) Stop() error {
 	if e.cancel != nil {
 		e.cancel()
@@ -0,0 +1,66 @@
+package elements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComfortNoiseFrames(t *testing.T) {
+	tests := []struct {
+		name   string
+		gap    time.Duration
+		filled int
+		want   int
+	}{
+		{name: "packet just arrived", gap: 0, want: 0},
+		{name: "late packet within slack", gap: 59 * time.Millisecond, want: 0},
+		{name: "first frame after slack", gap: 60 * time.Millisecond, want: 1},
+		{name: "partial frame rounds down", gap: 119 * time.Millisecond, want: 3},
+		{name: "already filled", gap: 100 * time.Millisecond, filled: 3, want: 0},
+		{name: "catch up after a slow tick", gap: 200 * time.Millisecond, filled: 5, want: 3},
+		{name: "filled ahead of the gap", gap: 100 * time.Millisecond, filled: 10, want: 0},
+		{name: "DTX update interval", gap: 400 * time.Millisecond, want: 18},
+		{name: "longest gap", gap: opusDTXMaxGap, want: 48},
+		{name: "sender stopped", gap: opusDTXMaxGap + time.Millisecond, filled: 48, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, comfortNoiseFrames(tt.gap, tt.filled))
+		})
+	}
+}
+
+func TestPacketsLost(t *testing.T) {
+	assert.Equal(t, 0, packetsLost(100, 101))
+	assert.Equal(t, 3, packetsLost(100, 104))
+
+	// 序列号回绕
+	assert.Equal(t, 0, packetsLost(65535, 0))
+	assert.Equal(t, 2, packetsLost(65534, 1))
+
+	// 重复和迟到的包
+	assert.Equal(t, -1, packetsLost(100, 100))
+	assert.Equal(t, -1, packetsLost(100, 99))
+	assert.Equal(t, -1, packetsLost(1, 65535))
+}
+
+func TestCheckSequence(t *testing.T) {
+	e := &OpusDecodeElement{}
+
+	lost, ok := e.checkSequence(10)
+	assert.True(t, ok)
+	assert.Equal(t, 0, lost)
+
+	lost, ok = e.checkSequence(13)
+	assert.True(t, ok)
+	assert.Equal(t, 2, lost)
+
+	// 迟到的包被丢弃，不影响后续检测
+	_, ok = e.checkSequence(12)
+	assert.False(t, ok)
+	lost, ok = e.checkSequence(14)
+	assert.True(t, ok)
+	assert.Equal(t, 0, lost)
+}
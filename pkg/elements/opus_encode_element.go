@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hraban/opus"
@@ -11,19 +12,47 @@ import (
 	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
+// opusDTXMaxBytes DTX 模式下编码器返回不超过该长度的包表示静音帧，无需发送
+const opusDTXMaxBytes = 2
+
+// OpusEncodeConfig Opus 编码配置
+type OpusEncodeConfig struct {
+	BufferSize int
+	SampleRate int
+	Channels   int
+
+	// DTX 启用不连续传输：静音期间编码器只偶尔输出舒适噪声更新包，
+	// 其余静音帧不再输出，适合大部分时间在听的助手节省带宽。
+	// 解码端应启用 OpusDecodeConfig.ComfortNoise 填补空隙；WebRTC 对端的抖动缓冲本身支持 DTX。
+	DTX bool
+}
+
 type OpusEncodeElement struct {
 	*pipeline.BaseElement
 
 	encoder    *opus.Encoder
 	sampleRate int
 	channels   int
+	dtx        bool
+
+	// DTX 模式下未发送的静音帧数
+	droppedFrames atomic.Int64
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
 func NewOpusEncodeElement(bufferSize int, sampleRate int, channels int) *OpusEncodeElement {
-	encoder, err := opus.NewEncoder(sampleRate, channels, opus.AppVoIP)
+	return NewOpusEncodeElementWithConfig(OpusEncodeConfig{
+		BufferSize: bufferSize,
+		SampleRate: sampleRate,
+		Channels:   channels,
+	})
+}
+
+// NewOpusEncodeElementWithConfig 使用自定义配置创建 OpusEncodeElement
+func NewOpusEncodeElementWithConfig(cfg OpusEncodeConfig) *OpusEncodeElement {
+	encoder, err := opus.NewEncoder(cfg.SampleRate, cfg.Channels, opus.AppVoIP)
 	if err != nil {
 		log.Fatalf("failed to create opus encoder: %v", err)
 	}
@@ -32,14 +61,27 @@ func NewOpusEncodeElement(bufferSize int, sampleRate int, channels int) *OpusEnc
 	encoder.SetBitrate(64000) // 64 kbps
 	encoder.SetComplexity(10) // 最高质量
 
+	if cfg.DTX {
+		if err := encoder.SetDTX(true); err != nil {
+			log.Printf("failed to enable opus DTX: %v", err)
+			cfg.DTX = false
+		}
+	}
+
 	return &OpusEncodeElement{
-		BaseElement: pipeline.NewBaseElement("opus-encode-element", bufferSize),
+		BaseElement: pipeline.NewBaseElement("opus-encode-element", cfg.BufferSize),
 		encoder:     encoder,
-		sampleRate:  sampleRate,
-		channels:    channels,
+		sampleRate:  cfg.SampleRate,
+		channels:    cfg.Channels,
+		dtx:         cfg.DTX,
 	}
 }
 
+// DroppedFrames 返回 DTX 模式下因静音而未发送的帧数
+func (e *OpusEncodeElement) DroppedFrames() int64 {
+	return e.droppedFrames.Load()
+}
+
 func (e *OpusEncodeElement) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	e.cancel = cancel
@@ -77,13 +119,20 @@ func (e *OpusEncodeElement) Start(ctx context.Context) error {
 					continue
 				}
 
+				// DTX 静音帧不需要发送
+				if e.dtx && n <= opusDTXMaxBytes {
+					e.droppedFrames.Add(1)
+					continue
+				}
+
 				log.Printf("Opus encode success, n: %d", n)
 
 				// 创建输出消息
 				outMsg := &pipeline.PipelineMessage{
-					Type:      pipeline.MsgTypeAudio,
-					SessionID: msg.SessionID,
-					Timestamp: time.Now(),
+					Type:       pipeline.MsgTypeAudio,
+					SessionID:  msg.SessionID,
+					Attributes: msg.Attributes,
+					Timestamp:  time.Now(),
 					AudioData: &pipeline.AudioData{
 						Data:       opusBuf[:n],
 						MediaType:  pipeline.AudioMediaTypeOpus,
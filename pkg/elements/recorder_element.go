@@ -0,0 +1,342 @@
+// Package elements provides pipeline processing elements.
+//
+// RecorderElement 把会话音频和转写文本流式上传到对象存储（S3/GCS 等）。
+// 音频按分片（multipart upload）边录边传，长时间通话也不会占用本地磁盘，
+// 适用于呼叫中心对话的合规归档。
+//
+// 主要功能:
+//   - 原始 PCM 音频按 PartSize 分片上传（默认 5MB，S3 要求的最小分片）
+//   - 最终（final）文本写入 JSON Lines 转写文件
+//   - 结束时写入会话元数据（采样率、通道数、起止时间等）
+//   - 所有消息原样透传，上传在后台进行
+//   - 上传失败时发布 EventError，不影响 Pipeline 运行
+//
+// 对象布局（Track 用于区分同一会话中的多路录音，如用户输入和 AI 输出）:
+//
+//	<Prefix>/<SessionID>/<Track>.pcm
+//	<Prefix>/<SessionID>/<Track>.transcript.jsonl
+//	<Prefix>/<SessionID>/<Track>.json
+//
+// 使用示例:
+//
+//	store, _ := storage.NewS3Store(storage.S3Config{Bucket: "call-recordings"})
+//	recorder, _ := NewRecorderElement(RecorderConfig{
+//	    Store:     store,
+//	    Prefix:    "call-center",
+//	    SessionID: callSid,
+//	    Track:     "caller",
+//	})
+//	p.Link(resample, recorder)
+//	p.Link(recorder, stt)
+package elements
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/storage"
+)
+
+// Make sure RecorderElement implements pipeline.Element
+var _ pipeline.Element = (*RecorderElement)(nil)
+
+// recorderFinalizeTimeout 停止时完成上传的最长时间
+const recorderFinalizeTimeout = 30 * time.Second
+
+// RecorderConfig 录音上传配置
+type RecorderConfig struct {
+	Store     storage.ObjectStore // 对象存储（必填）
+	Prefix    string              // 对象 key 前缀
+	SessionID string              // 会话 ID（默认按开始时间生成）
+	Track     string              // 录音轨道名（默认 "audio"）
+	PartSize  int                 // 音频分片大小（默认 storage.MinPartSize）
+}
+
+// recorderTranscriptEntry 转写文件中的一行
+type recorderTranscriptEntry struct {
+	Time     time.Time `json:"time"`
+	TextType string    `json:"text_type"`
+	Text     string    `json:"text"`
+}
+
+// recorderSessionInfo 会话元数据
+type recorderSessionInfo struct {
+	SessionID     string    `json:"session_id"`
+	Track         string    `json:"track"`
+	Encoding      string    `json:"encoding"`
+	SampleRate    int       `json:"sample_rate"`
+	Channels      int       `json:"channels"`
+	AudioBytes    int64     `json:"audio_bytes"`
+	AudioKey      string    `json:"audio_key,omitempty"`
+	TranscriptKey string    `json:"transcript_key,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	EndedAt       time.Time `json:"ended_at"`
+}
+
+// RecorderElement 会话录音上传元素
+type RecorderElement struct {
+	*pipeline.BaseElement
+
+	config RecorderConfig
+
+	// 音频分片缓冲，满 PartSize 后交给上传协程
+	buffer     []byte
+	partCh     chan []byte
+	upload     storage.Upload
+	partNumber int
+	failed     bool
+
+	// 会话信息
+	mu         sync.Mutex
+	sampleRate int
+	channels   int
+	audioBytes int64
+	transcript []recorderTranscriptEntry
+	startedAt  time.Time
+	formatWarn bool
+
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	uploaderWg sync.WaitGroup
+}
+
+// NewRecorderElement 创建录音上传元素
+func NewRecorderElement(config RecorderConfig) (*RecorderElement, error) {
+	if config.Store == nil {
+		return nil, fmt.Errorf("object store is required")
+	}
+	if config.SessionID == "" {
+		config.SessionID = time.Now().UTC().Format("20060102T150405.000Z")
+	}
+	if config.Track == "" {
+		config.Track = "audio"
+	}
+	if config.PartSize <= 0 {
+		config.PartSize = storage.MinPartSize
+	}
+
+	return &RecorderElement{
+		BaseElement: pipeline.NewBaseElement("recorder-element", 100),
+		config:      config,
+	}, nil
+}
+
+func (e *RecorderElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.startedAt = time.Now()
+	e.partCh = make(chan []byte, 4)
+
+	e.uploaderWg.Add(1)
+	go func() {
+		defer e.uploaderWg.Done()
+		e.uploadLoop()
+	}()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+				e.record(msg)
+			}
+		}
+	}()
+
+	log.Printf("[Recorder] Recording session %s (track: %s) to %s", e.config.SessionID, e.config.Track, e.config.Store.Name())
+	return nil
+}
+
+// Stop 停止录音，上传剩余音频并写入转写和元数据
+func (e *RecorderElement) Stop() error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	e.wg.Wait()
+	e.cancel = nil
+
+	// 最后一个分片可以小于 PartSize
+	if len(e.buffer) > 0 {
+		e.partCh <- e.buffer
+		e.buffer = nil
+	}
+	close(e.partCh)
+	e.uploaderWg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), recorderFinalizeTimeout)
+	defer cancel()
+
+	if err := e.finalize(ctx); err != nil {
+		e.reportError(err)
+		return err
+	}
+
+	log.Printf("[Recorder] Session %s uploaded (%d audio bytes)", e.config.SessionID, e.audioBytes)
+	return nil
+}
+
+// record 记录一条消息
+func (e *RecorderElement) record(msg *pipeline.PipelineMessage) {
+	switch {
+	case msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil:
+		e.recordAudio(msg.AudioData)
+	case msg.Type == pipeline.MsgTypeData && msg.TextData != nil:
+		// 只记录最终文本（"final" 或 "text/final"）
+		if strings.HasSuffix(msg.TextData.TextType, "final") && len(msg.TextData.Data) > 0 {
+			e.mu.Lock()
+			e.transcript = append(e.transcript, recorderTranscriptEntry{
+				Time:     time.Now(),
+				TextType: msg.TextData.TextType,
+				Text:     string(msg.TextData.Data),
+			})
+			e.mu.Unlock()
+		}
+	}
+}
+
+// recordAudio 缓冲音频，满一个分片后交给上传协程
+func (e *RecorderElement) recordAudio(data *pipeline.AudioData) {
+	if !isPCMMediaType(data.MediaType) || len(data.Data) == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	if e.sampleRate == 0 {
+		e.sampleRate = data.SampleRate
+		e.channels = data.Channels
+	}
+	// 只录制第一次出现的格式，格式变化的数据会破坏原始 PCM 文件
+	if data.SampleRate != e.sampleRate || data.Channels != e.channels {
+		if !e.formatWarn {
+			e.formatWarn = true
+			log.Printf("[Recorder] Skipping audio with format %dHz/%dch (recording %dHz/%dch)",
+				data.SampleRate, data.Channels, e.sampleRate, e.channels)
+		}
+		e.mu.Unlock()
+		return
+	}
+	e.audioBytes += int64(len(data.Data))
+	e.mu.Unlock()
+
+	e.buffer = append(e.buffer, data.Data...)
+	if len(e.buffer) >= e.config.PartSize {
+		e.partCh <- e.buffer
+		e.buffer = nil
+	}
+}
+
+// uploadLoop 按顺序上传音频分片
+func (e *RecorderElement) uploadLoop() {
+	ctx := context.Background()
+
+	for part := range e.partCh {
+		if e.failed {
+			continue
+		}
+
+		if e.upload == nil {
+			upload, err := e.config.Store.CreateUpload(ctx, e.key(".pcm"), "application/octet-stream")
+			if err != nil {
+				e.failed = true
+				e.reportError(fmt.Errorf("failed to start audio upload: %w", err))
+				continue
+			}
+			e.upload = upload
+		}
+
+		e.partNumber++
+		if err := e.upload.UploadPart(ctx, e.partNumber, part); err != nil {
+			e.failed = true
+			e.reportError(fmt.Errorf("failed to upload audio part %d: %w", e.partNumber, err))
+		}
+	}
+}
+
+// finalize 完成音频上传，写入转写和会话元数据
+func (e *RecorderElement) finalize(ctx context.Context) error {
+	info := recorderSessionInfo{
+		SessionID: e.config.SessionID,
+		Track:     e.config.Track,
+		Encoding:  "pcm_s16le",
+		StartedAt: e.startedAt,
+		EndedAt:   time.Now(),
+	}
+
+	if e.upload != nil {
+		if e.failed {
+			e.upload.Abort(ctx)
+			return fmt.Errorf("audio upload of session %s failed", e.config.SessionID)
+		}
+		if err := e.upload.Complete(ctx); err != nil {
+			return fmt.Errorf("failed to complete audio upload: %w", err)
+		}
+		info.AudioKey = e.upload.Key()
+	}
+
+	e.mu.Lock()
+	info.SampleRate = e.sampleRate
+	info.Channels = e.channels
+	info.AudioBytes = e.audioBytes
+	transcript := e.transcript
+	e.mu.Unlock()
+
+	if len(transcript) > 0 {
+		var lines strings.Builder
+		for _, entry := range transcript {
+			line, _ := json.Marshal(entry)
+			lines.Write(line)
+			lines.WriteByte('\n')
+		}
+		info.TranscriptKey = e.key(".transcript.jsonl")
+		if err := e.config.Store.Put(ctx, info.TranscriptKey, []byte(lines.String()), "application/x-ndjson"); err != nil {
+			return fmt.Errorf("failed to upload transcript: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := e.config.Store.Put(ctx, e.key(".json"), data, "application/json"); err != nil {
+		return fmt.Errorf("failed to upload session info: %w", err)
+	}
+	return nil
+}
+
+// key 返回本轨道对象的 key
+func (e *RecorderElement) key(suffix string) string {
+	parts := []string{e.config.SessionID, e.config.Track + suffix}
+	if prefix := strings.Trim(e.config.Prefix, "/"); prefix != "" {
+		parts = append([]string{prefix}, parts...)
+	}
+	return strings.Join(parts, "/")
+}
+
+// reportError 记录并发布上传错误
+func (e *RecorderElement) reportError(err error) {
+	log.Printf("[Recorder] %v", err)
+	if e.Bus() != nil {
+		e.Bus().Publish(pipeline.Event{
+			Type:      pipeline.EventError,
+			Timestamp: time.Now(),
+			Payload:   fmt.Sprintf("recorder: %v", err),
+		})
+	}
+}
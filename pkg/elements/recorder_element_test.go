@@ -0,0 +1,159 @@
+package elements
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory storage.ObjectStore
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	parts   map[string][]int // part sizes per key
+	failAt  int              // fail this part number (0 = never)
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}, parts: map[string][]int{}}
+}
+
+func (s *memoryStore) Name() string { return "memory" }
+
+func (s *memoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memoryStore) CreateUpload(ctx context.Context, key string, contentType string) (storage.Upload, error) {
+	return &memoryUpload{store: s, key: key}, nil
+}
+
+type memoryUpload struct {
+	store *memoryStore
+	key   string
+	data  []byte
+}
+
+func (u *memoryUpload) Key() string { return u.key }
+
+func (u *memoryUpload) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+	if partNumber == u.store.failAt {
+		return fmt.Errorf("part %d rejected", partNumber)
+	}
+	u.data = append(u.data, data...)
+	u.store.parts[u.key] = append(u.store.parts[u.key], len(data))
+	return nil
+}
+
+func (u *memoryUpload) Complete(ctx context.Context) error {
+	return u.store.Put(ctx, u.key, u.data, "")
+}
+
+func (u *memoryUpload) Abort(ctx context.Context) error { return nil }
+
+func drainOutput(t *testing.T, elem pipeline.Element, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-elem.Out():
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for passthrough message")
+		}
+	}
+}
+
+func TestRecorderElement_UploadsSession(t *testing.T) {
+	store := newMemoryStore()
+	elem, err := NewRecorderElement(RecorderConfig{
+		Store:     store,
+		Prefix:    "/calls/",
+		SessionID: "CA123",
+		Track:     "caller",
+		PartSize:  1000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, elem.Start(context.Background()))
+
+	for i := 0; i < 5; i++ {
+		elem.In() <- audioMessage(16000, 1, 640)
+	}
+	elem.In() <- textMessage("hello", "text/partial")
+	elem.In() <- textMessage("hello there", "text/final")
+	drainOutput(t, elem, 7)
+
+	require.NoError(t, elem.Stop())
+
+	// 3200 bytes in parts of at least 1000 bytes; the last one is smaller
+	assert.Len(t, store.objects["calls/CA123/caller.pcm"], 3200)
+	assert.Equal(t, []int{1280, 1280, 640}, store.parts["calls/CA123/caller.pcm"])
+
+	transcript := string(store.objects["calls/CA123/caller.transcript.jsonl"])
+	assert.Contains(t, transcript, `"text":"hello there"`)
+	assert.NotContains(t, transcript, `"text":"hello"`)
+
+	var info recorderSessionInfo
+	require.NoError(t, json.Unmarshal(store.objects["calls/CA123/caller.json"], &info))
+	assert.Equal(t, "CA123", info.SessionID)
+	assert.Equal(t, 16000, info.SampleRate)
+	assert.Equal(t, 1, info.Channels)
+	assert.Equal(t, int64(3200), info.AudioBytes)
+	assert.Equal(t, "calls/CA123/caller.pcm", info.AudioKey)
+}
+
+func TestRecorderElement_SkipsFormatChanges(t *testing.T) {
+	store := newMemoryStore()
+	elem, err := NewRecorderElement(RecorderConfig{Store: store, SessionID: "s1"})
+	require.NoError(t, err)
+	require.NoError(t, elem.Start(context.Background()))
+
+	elem.In() <- audioMessage(16000, 1, 320)
+	elem.In() <- audioMessage(48000, 1, 960)
+	drainOutput(t, elem, 2)
+	require.NoError(t, elem.Stop())
+
+	assert.Len(t, store.objects["s1/audio.pcm"], 320)
+	_, hasTranscript := store.objects["s1/audio.transcript.jsonl"]
+	assert.False(t, hasTranscript)
+}
+
+func TestRecorderElement_UploadFailure(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	errCh := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventError, errCh)
+
+	store := newMemoryStore()
+	store.failAt = 1
+	elem, err := NewRecorderElement(RecorderConfig{Store: store, SessionID: "s1", PartSize: 100})
+	require.NoError(t, err)
+	elem.SetBus(bus)
+	require.NoError(t, elem.Start(context.Background()))
+
+	elem.In() <- audioMessage(16000, 1, 320)
+	drainOutput(t, elem, 1)
+	assert.Error(t, elem.Stop())
+
+	require.NotEmpty(t, errCh)
+	evt := <-errCh
+	assert.True(t, strings.HasPrefix(evt.Payload.(string), "recorder: "))
+	_, uploaded := store.objects["s1/audio.pcm"]
+	assert.False(t, uploaded)
+}
+
+func TestNewRecorderElement_RequiresStore(t *testing.T) {
+	_, err := NewRecorderElement(RecorderConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,390 @@
+// Package elements provides pipeline processing elements.
+//
+// RepeatIntentElement 识别 "what?"、"can you repeat that?" 这类重复/澄清请求，
+// 直接重播上一轮助手的 TTS 音频，不再调用 LLM 重新生成。
+// 这类请求在语音对话中很常见，重播既更快也节省调用成本。
+//
+// 工作原理:
+//   - 放在 STT 和 LLM 之间，检查每条最终识别结果是否为触发短语
+//   - 输出链路上的缓存分支（OutputTap）记录上一轮助手播放的音频
+//   - 命中触发短语且有缓存时，识别结果不再下发给 LLM，由 OutputTap 重新输出缓存音频
+//   - 没有缓存（如对话刚开始）时识别结果照常下发
+//
+// 触发短语按语言配置，语言未显式配置时跟随 Pipeline LanguageContext 的源语言，
+// 仍未知时匹配所有语言的短语。匹配前去掉语气词等填充词，"sorry, what?" 与
+// "what" 等价，而 "what is it" 这样的正常提问不会命中。
+//
+// 使用示例:
+//
+//	repeat := NewRepeatIntentElement()
+//	p.Link(stt, repeat)
+//	p.Link(repeat, llm)
+//	// 输出链路：TTS -> OutputTap -> sink
+//	tap := repeat.OutputTap()
+//	p.Link(tts, tap)
+//	p.Link(tap, sink)
+package elements
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure RepeatIntentElement implements pipeline.Element
+var _ pipeline.Element = (*RepeatIntentElement)(nil)
+
+// RepeatIntentConfig 重复意图识别配置
+type RepeatIntentConfig struct {
+	// Phrases 按语言配置的触发短语，key 为主语言代码（"en"、"zh"）
+	Phrases map[string][]string
+
+	// Fillers 按语言配置的填充词，匹配前从识别结果和短语中去掉
+	Fillers map[string][]string
+
+	Language string        // 用户语言，"" 时跟随 LanguageContext 的源语言，仍为空匹配所有语言
+	MaxCache time.Duration // 缓存的最长音频时长，超出部分不再缓存，默认 60s
+}
+
+// DefaultRepeatPhrases 返回内置的触发短语
+func DefaultRepeatPhrases() map[string][]string {
+	return map[string][]string{
+		"en": {
+			"what", "what was that", "what did you say", "pardon", "pardon me", "excuse me",
+			"sorry", "come again", "say again", "say that again", "repeat", "repeat that",
+			"can you repeat that", "could you repeat that", "can you say that again",
+			"could you say that again", "i didn't catch that", "i did not catch that",
+			"i didn't hear you", "one more time",
+		},
+		"zh": {
+			"什么", "啥", "你说什么", "刚才说什么", "你刚才说什么", "再说一遍", "再说一次",
+			"重复一遍", "重复一下", "没听清", "没听清楚", "我没听清", "我没听清楚",
+		},
+	}
+}
+
+// DefaultRepeatFillers 返回内置的填充词
+func DefaultRepeatFillers() map[string][]string {
+	return map[string][]string{
+		"en": {"sorry", "i'm", "um", "uh", "hmm", "oh", "wait", "please", "excuse", "me", "hey"},
+		"zh": {"啊", "呀", "吧", "呢", "嗯", "哦", "请"},
+	}
+}
+
+// DefaultRepeatIntentConfig 返回默认配置
+func DefaultRepeatIntentConfig() RepeatIntentConfig {
+	return RepeatIntentConfig{
+		Phrases:  DefaultRepeatPhrases(),
+		Fillers:  DefaultRepeatFillers(),
+		MaxCache: 60 * time.Second,
+	}
+}
+
+// RepeatIntentElement 识别重复请求并重播上一轮 TTS 输出
+type RepeatIntentElement struct {
+	*pipeline.BaseElement
+
+	config  RepeatIntentConfig
+	phrases map[string][]string            // 按语言去掉填充词后的短语
+	fillers map[string]map[string]struct{} // 按语言的填充词
+
+	mu       sync.Mutex
+	current  []*pipeline.PipelineMessage // 本轮已输出的音频
+	cacheDur time.Duration               // current 的总时长
+	last     []*pipeline.PipelineMessage // 可重播的音频（上一轮完整回复）
+	replayCh chan []*pipeline.PipelineMessage
+
+	replays atomic.Int64 // 重播次数
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRepeatIntentElement 使用默认配置创建 RepeatIntentElement
+func NewRepeatIntentElement() *RepeatIntentElement {
+	return NewRepeatIntentElementWithConfig(DefaultRepeatIntentConfig())
+}
+
+// NewRepeatIntentElementWithConfig 使用自定义配置创建 RepeatIntentElement
+func NewRepeatIntentElementWithConfig(cfg RepeatIntentConfig) *RepeatIntentElement {
+	if cfg.Phrases == nil {
+		cfg.Phrases = DefaultRepeatPhrases()
+	}
+	if cfg.MaxCache <= 0 {
+		cfg.MaxCache = 60 * time.Second
+	}
+
+	e := &RepeatIntentElement{
+		BaseElement: pipeline.NewBaseElement("repeat-intent-element", 100),
+		config:      cfg,
+		phrases:     make(map[string][]string),
+		fillers:     make(map[string]map[string]struct{}),
+		replayCh:    make(chan []*pipeline.PipelineMessage, 1),
+	}
+	for lang, list := range cfg.Fillers {
+		lang = baseLanguage(lang)
+		if e.fillers[lang] == nil {
+			e.fillers[lang] = make(map[string]struct{})
+		}
+		for _, filler := range list {
+			for _, w := range intentWords(filler) {
+				e.fillers[lang][w] = struct{}{}
+			}
+		}
+	}
+	for lang, list := range cfg.Phrases {
+		lang = baseLanguage(lang)
+		for _, phrase := range list {
+			if key := e.intentKey(lang, phrase); key != "" {
+				e.phrases[lang] = append(e.phrases[lang], key)
+			}
+		}
+	}
+	return e
+}
+
+// OutputTap 返回放在输出链路上的透传元素，缓存助手的音频并负责重播
+// 应放在 TTS 之后、sink 之前
+func (e *RepeatIntentElement) OutputTap() pipeline.Element {
+	return &repeatOutputTap{
+		BaseElement: pipeline.NewBaseElement("repeat-output-tap", 100),
+		intent:      e,
+	}
+}
+
+// Replays 返回因重复请求而重播的次数
+func (e *RepeatIntentElement) Replays() int64 {
+	return e.replays.Load()
+}
+
+// IsRepeatRequest 判断文本是否为重复/澄清请求
+func (e *RepeatIntentElement) IsRepeatRequest(text string) bool {
+	lang := e.config.Language
+	if lang == "" {
+		lang = e.LanguageContext().EffectiveSource()
+	}
+	if lang = baseLanguage(lang); lang != "" {
+		return e.matchLanguage(lang, text)
+	}
+	for lang := range e.phrases {
+		if e.matchLanguage(lang, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RepeatIntentElement) matchLanguage(lang, text string) bool {
+	key := e.intentKey(lang, text)
+	if key == "" {
+		return false
+	}
+	for _, phrase := range e.phrases[lang] {
+		if key == phrase {
+			return true
+		}
+	}
+	return false
+}
+
+// intentKey 返回去掉标点和填充词后的文本，用于与短语比较
+// 全部是填充词时保留原词（"sorry" 本身也是触发短语）
+func (e *RepeatIntentElement) intentKey(lang, text string) string {
+	words := intentWords(text)
+	kept := make([]string, 0, len(words))
+	for _, w := range words {
+		if _, filler := e.fillers[lang][w]; !filler {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		kept = words
+	}
+	return strings.Join(kept, " ")
+}
+
+func (e *RepeatIntentElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.process(ctx)
+	}()
+
+	return nil
+}
+
+func (e *RepeatIntentElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+func (e *RepeatIntentElement) process(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.BaseElement.InChan:
+			if msg.Type == pipeline.MsgTypeData && msg.TextData != nil && msg.TextData.TextType == "text/final" {
+				text := string(msg.TextData.Data)
+				if e.IsRepeatRequest(text) && e.replay() {
+					log.Printf("[RepeatIntent] Replaying last response for %q", text)
+					continue
+				}
+				// 新的用户轮次，之后输出的音频属于新回复
+				e.newTurn()
+			}
+
+			select {
+			case e.BaseElement.OutChan <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// newTurn 把本轮音频作为可重播的回复，开始缓存下一轮
+func (e *RepeatIntentElement) newTurn() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.current) > 0 {
+		e.last = e.current
+	}
+	e.current = nil
+	e.cacheDur = 0
+}
+
+// replay 请求 OutputTap 重播最近一轮回复，没有缓存时返回 false
+func (e *RepeatIntentElement) replay() bool {
+	e.mu.Lock()
+	msgs := e.current
+	if len(msgs) == 0 {
+		msgs = e.last
+	}
+	e.mu.Unlock()
+
+	if len(msgs) == 0 {
+		return false
+	}
+
+	// 只保留最新的一次重播请求
+	select {
+	case <-e.replayCh:
+	default:
+	}
+	e.replayCh <- msgs
+	e.replays.Add(1)
+	return true
+}
+
+// record 缓存一帧输出音频
+func (e *RepeatIntentElement) record(msg *pipeline.PipelineMessage) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	d := audioDuration(msg.AudioData)
+	if e.cacheDur+d > e.config.MaxCache {
+		return
+	}
+	e.current = append(e.current, msg)
+	e.cacheDur += d
+}
+
+// repeatOutputTap 透传输出音频并缓存，收到重播请求时重新输出缓存的音频
+type repeatOutputTap struct {
+	*pipeline.BaseElement
+
+	intent *RepeatIntentElement
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (t *repeatOutputTap) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msgs := <-t.intent.replayCh:
+				for _, cached := range msgs {
+					out := *cached
+					out.Timestamp = time.Now()
+					select {
+					case t.BaseElement.OutChan <- &out:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case msg := <-t.BaseElement.InChan:
+				if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil && len(msg.AudioData.Data) > 0 {
+					t.intent.record(msg)
+				}
+
+				select {
+				case t.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *repeatOutputTap) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+		t.wg.Wait()
+		t.cancel = nil
+	}
+	return nil
+}
+
+// intentWords 把文本切分为小写单词，去掉标点；中日韩文字每个字算一个词
+func intentWords(text string) []string {
+	var words []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			words = append(words, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’':
+			if r == '’' {
+				r = '\''
+			}
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
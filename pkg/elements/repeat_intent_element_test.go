@@ -0,0 +1,150 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepeatIntent_Phrasings(t *testing.T) {
+	e := NewRepeatIntentElement()
+
+	repeats := []string{
+		"What?",
+		"what",
+		"Sorry, what?",
+		"Pardon?",
+		"Excuse me?",
+		"Can you repeat that?",
+		"Could you say that again, please?",
+		"Um, sorry, I didn't catch that.",
+		"I’m sorry, what was that?",
+		"Come again?",
+		"你说什么？",
+		"再说一遍吧",
+		"啊？什么？",
+		"我没听清楚。",
+	}
+	for _, text := range repeats {
+		assert.True(t, e.IsRepeatRequest(text), "expected repeat request: %q", text)
+	}
+
+	others := []string{
+		"",
+		"What is the weather today?",
+		"what time is it",
+		"Sorry I'm late",
+		"Repeat after me: hello",
+		"Can you repeat the order number for the second package?",
+		"什么时候下雨",
+		"今天天气怎么样",
+	}
+	for _, text := range others {
+		assert.False(t, e.IsRepeatRequest(text), "unexpected repeat request: %q", text)
+	}
+}
+
+func TestRepeatIntent_LanguageSpecificPhrases(t *testing.T) {
+	cfg := DefaultRepeatIntentConfig()
+	cfg.Phrases["es"] = []string{"qué", "puedes repetir"}
+	cfg.Fillers["es"] = []string{"perdón"}
+
+	// 显式配置语言时只匹配该语言的短语
+	cfg.Language = "es-ES"
+	e := NewRepeatIntentElementWithConfig(cfg)
+	assert.True(t, e.IsRepeatRequest("¿Perdón, qué?"))
+	assert.True(t, e.IsRepeatRequest("¿Puedes repetir?"))
+	assert.False(t, e.IsRepeatRequest("What?"))
+
+	// 跟随 LanguageContext 检测到的语言
+	cfg.Language = ""
+	e = NewRepeatIntentElementWithConfig(cfg)
+	lc := pipeline.NewLanguageContext("auto", "")
+	e.SetLanguageContext(lc)
+	assert.True(t, e.IsRepeatRequest("What?"))
+	assert.True(t, e.IsRepeatRequest("¿Qué?"))
+	lc.SetDetected("en")
+	assert.True(t, e.IsRepeatRequest("What?"))
+	assert.False(t, e.IsRepeatRequest("¿Qué?"))
+}
+
+func finalText(text string) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type:     pipeline.MsgTypeData,
+		TextData: &pipeline.TextData{Data: []byte(text), TextType: "text/final"},
+	}
+}
+
+func audioMsg(b byte) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       []byte{b, b, b, b},
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+}
+
+func TestRepeatIntentElement_ReplaysLastResponse(t *testing.T) {
+	e := NewRepeatIntentElement()
+	tap := e.OutputTap()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, e.Start(ctx))
+	defer e.Stop()
+	require.NoError(t, tap.Start(ctx))
+	defer tap.Stop()
+
+	recv := func(ch <-chan *pipeline.PipelineMessage) *pipeline.PipelineMessage {
+		t.Helper()
+		select {
+		case msg := <-ch:
+			return msg
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+			return nil
+		}
+	}
+
+	// 没有缓存时重复请求照常下发
+	e.In() <- finalText("what?")
+	assert.Equal(t, "what?", string(recv(e.Out()).TextData.Data))
+
+	// 用户提问，助手回复两帧音频
+	e.In() <- finalText("tell me a joke")
+	assert.Equal(t, "tell me a joke", string(recv(e.Out()).TextData.Data))
+	for _, b := range []byte{1, 2} {
+		tap.In() <- audioMsg(b)
+		assert.Equal(t, b, recv(tap.Out()).AudioData.Data[0])
+	}
+
+	// 重复请求不下发给 LLM，由 tap 重播缓存的音频
+	e.In() <- finalText("Sorry, can you repeat that?")
+	for _, b := range []byte{1, 2} {
+		assert.Equal(t, b, recv(tap.Out()).AudioData.Data[0])
+	}
+	select {
+	case msg := <-e.Out():
+		t.Fatalf("repeat request was forwarded: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, int64(1), e.Replays())
+
+	// 新的提问开始新一轮，再次重复时重播新回复
+	e.In() <- finalText("another one")
+	recv(e.Out())
+	tap.In() <- audioMsg(3)
+	recv(tap.Out())
+
+	e.In() <- finalText("what?")
+	assert.Equal(t, byte(3), recv(tap.Out()).AudioData.Data[0])
+	assert.Equal(t, int64(2), e.Replays())
+}
@@ -8,6 +8,7 @@
 //   - 特殊情况处理：缩写词、小数、URL、省略号等
 //   - 长度控制：最小/最大句子长度限制
 //   - 超时机制：避免长时间等待
+//   - 部分刷新：FlushPartial 提前输出未完成的句子，分句状态保持不变
 //
 // 设计原则:
 //   - 宁可稍晚分句，不可错误分句（错误分句会导致语音不自然）
@@ -76,6 +77,10 @@ type SentenceSegmenter struct {
 	buffer   strings.Builder
 	callback SentenceCallback
 
+	// emitted 缓冲区中已由 FlushPartial 输出的字节数
+	// 句子完成时只输出其后的部分，分句判断仍基于完整句子
+	emitted int
+
 	lastFeedTime time.Time
 	timer        *time.Timer
 
@@ -196,6 +201,28 @@ func (s *SentenceSegmenter) Flush() {
 	s.flushBuffer(true)
 }
 
+// FlushPartial 把缓冲区中尚未输出的部分作为非最终片段立即输出（isFinal 为 false）
+// 与 Flush 不同，缓冲区不会被清空：句子继续累积，分句仍按完整句子判断，
+// 句子完成时只输出 FlushPartial 之后新增的内容。用于超低延迟场景，
+// 在短暂无输入后先送出半句，让首段音频更早播放
+// 返回是否输出了内容
+func (s *SentenceSegmenter) FlushPartial() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content := s.buffer.String()
+	partial := strings.TrimSpace(content[s.emitted:])
+	if partial == "" {
+		return false
+	}
+
+	s.emitted = len(content)
+	if s.callback != nil {
+		s.callback(partial, false)
+	}
+	return true
+}
+
 // Reset 重置分句器状态
 func (s *SentenceSegmenter) Reset() {
 	s.mu.Lock()
@@ -203,6 +230,7 @@ func (s *SentenceSegmenter) Reset() {
 
 	s.stopTimer()
 	s.buffer.Reset()
+	s.emitted = 0
 }
 
 // GetBuffer 获取当前缓冲区内容（用于调试）
@@ -221,15 +249,16 @@ func (s *SentenceSegmenter) tryFlush(isTimeout bool) bool {
 
 	// 超时情况：直接输出缓冲区内容（如果非空）
 	if isTimeout {
-		sentence := strings.TrimSpace(content)
-		if sentence != "" {
-			s.buffer.Reset()
-			if s.callback != nil {
-				s.callback(sentence, false)
-			}
-			return true
+		if strings.TrimSpace(content) == "" {
+			return false
 		}
-		return false
+		sentence := strings.TrimSpace(content[s.emitted:])
+		s.buffer.Reset()
+		s.emitted = 0
+		if s.callback != nil && sentence != "" {
+			s.callback(sentence, false)
+		}
+		return true
 	}
 
 	// 查找分句点
@@ -247,9 +276,20 @@ func (s *SentenceSegmenter) tryFlush(isTimeout bool) bool {
 		return false
 	}
 
+	// 已由 FlushPartial 输出的部分不再重复
+	if s.emitted > 0 {
+		if s.emitted >= breakPoint {
+			sentence = ""
+			remaining = content[s.emitted:]
+		} else {
+			sentence = strings.TrimSpace(content[s.emitted:breakPoint])
+		}
+	}
+
 	// 刷新句子
 	s.buffer.Reset()
 	s.buffer.WriteString(remaining)
+	s.emitted = 0
 
 	if s.callback != nil && sentence != "" {
 		s.callback(sentence, false)
@@ -280,6 +320,11 @@ func (s *SentenceSegmenter) findSentenceBreak(text string, isTimeout bool) int {
 
 		// 检查是否为句尾标点
 		if s.isSentenceEnder(r) {
+			// 不足最小长度的句子与下一句合并，继续查找后面的句尾
+			if utf8.RuneCountInString(strings.TrimSpace(string(runes[:i+1]))) < s.config.MinLength {
+				continue
+			}
+
 			// 智能检测：排除特殊情况
 			if s.config.EnableSmartPunctuation {
 				textBefore := string(runes[:i+1])
@@ -335,10 +380,10 @@ func (s *SentenceSegmenter) findForcedBreak(text string, runes []rune) int {
 	return len(string(runes[:maxRunes]))
 }
 
-// findSoftBreak 在软分隔符（逗号等）处分割
+// findSoftBreak 在软分隔符（逗号等）处分割，分出的句子不超过 MaxLength
 func (s *SentenceSegmenter) findSoftBreak(runes []rune) int {
 	// 从后向前查找软分隔符
-	for i := len(runes) - 1; i >= s.config.MinLength; i-- {
+	for i := min(len(runes), s.config.MaxLength) - 1; i >= s.config.MinLength; i-- {
 		if softBreakPunctuation[runes[i]] {
 			return len(string(runes[:i+1]))
 		}
@@ -346,10 +391,10 @@ func (s *SentenceSegmenter) findSoftBreak(runes []rune) int {
 	return 0
 }
 
-// findSpaceBreak 在空格处分割
+// findSpaceBreak 在空格处分割，分出的句子不超过 MaxLength
 func (s *SentenceSegmenter) findSpaceBreak(runes []rune) int {
 	// 从后向前查找空格
-	for i := len(runes) - 1; i >= s.config.MinLength; i-- {
+	for i := min(len(runes), s.config.MaxLength) - 1; i >= s.config.MinLength; i-- {
 		if unicode.IsSpace(runes[i]) {
 			return len(string(runes[:i+1]))
 		}
@@ -522,8 +567,9 @@ func (s *SentenceSegmenter) stopTimer() {
 
 // flushBuffer 刷新缓冲区
 func (s *SentenceSegmenter) flushBuffer(isFinal bool) {
-	content := strings.TrimSpace(s.buffer.String())
+	content := strings.TrimSpace(s.buffer.String()[s.emitted:])
 	s.buffer.Reset()
+	s.emitted = 0
 
 	if content != "" && s.callback != nil {
 		s.callback(content, isFinal)
@@ -472,6 +472,52 @@ func TestSentenceSegmenter_Timeout(t *testing.T) {
 	})
 }
 
+func TestSentenceSegmenter_FlushPartial(t *testing.T) {
+	type segment struct {
+		text    string
+		isFinal bool
+	}
+	var segments []segment
+
+	segmenter := NewSentenceSegmenter(SentenceSegmenterConfig{
+		MinLength:              10,
+		FlushTimeout:           time.Minute,
+		EnableSmartPunctuation: true,
+	})
+	segmenter.OnSentence(func(sentence string, isFinal bool) {
+		segments = append(segments, segment{sentence, isFinal})
+	})
+
+	// 半句先行输出，缓冲区保留
+	segmenter.Feed("Please ask Dr")
+	require.True(t, segmenter.FlushPartial())
+	assert.Equal(t, []segment{{"Please ask Dr", false}}, segments)
+	assert.Equal(t, "Please ask Dr", segmenter.GetBuffer(), "partial flush should keep accumulating")
+
+	// 没有新内容时不重复输出
+	assert.False(t, segmenter.FlushPartial())
+
+	// 分句仍按完整句子判断："Dr." 不被当作句尾，只输出剩余部分
+	segmenter.Feed(". Smith about it. Then")
+	assert.Equal(t, []segment{
+		{"Please ask Dr", false},
+		{". Smith about it.", false},
+	}, segments)
+	assert.Equal(t, " Then", segmenter.GetBuffer())
+
+	// 再次部分输出后，Flush 只输出其后新增的内容
+	require.True(t, segmenter.FlushPartial())
+	segmenter.Feed(" call me back")
+	segmenter.Flush()
+	assert.Equal(t, []segment{
+		{"Please ask Dr", false},
+		{". Smith about it.", false},
+		{"Then", false},
+		{"call me back", true},
+	}, segments)
+	assert.Empty(t, segmenter.GetBuffer())
+}
+
 // ============================================================
 // 多语言测试
 // ============================================================
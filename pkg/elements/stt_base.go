@@ -2,7 +2,13 @@ package elements
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
 
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 )
 
@@ -20,3 +26,696 @@ func (e *STTBaseElement) Start(ctx context.Context) error {
 func (e *STTBaseElement) Stop() error {
 	return nil
 }
+
+// sttCommitter is implemented by streaming recognizers that can be told the
+// utterance is over, so they return its final transcript without waiting
+// for their own endpointing.
+type sttCommitter interface {
+	Commit(ctx context.Context) error
+}
+
+// realtimeSTTConfig holds the settings shared by the streaming STT elements,
+// with defaults already applied by the element constructor.
+type realtimeSTTConfig struct {
+	Language             string
+	Model                string
+	EnablePartialResults bool
+	VADEnabled           bool
+
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+
+	ResultTimeout            time.Duration
+	PartialIntervalMs        int
+	EarlyCommitStableMs      int
+	EarlyCommitMinConfidence float32
+
+	// Provider-specific recognition options (PrebufferMs, Timestamps,
+	// Prompt, ...). Language, Model and EnablePartialResults are filled in
+	// from the element each time a recognizer is started
+	Recognition asr.RecognitionConfig
+}
+
+// realtimeSTTBase is the machinery shared by the WebSocket streaming STT
+// elements: VAD gating, commit on speech end, partial throttling and early
+// commit, the result watchdog, connection status and recognizer restarts.
+// The provider elements embed it and only set up their provider.
+type realtimeSTTBase struct {
+	*pipeline.BaseElement
+
+	// Log prefix, e.g. "ElevenLabsSTT"
+	logTag string
+
+	// ASR provider
+	provider asr.Provider
+
+	// ASR configuration
+	language             string
+	model                string
+	enablePartialResults bool
+	recognition          asr.RecognitionConfig
+
+	// Audio configuration
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+
+	// VAD integration
+	vadEnabled   bool
+	vadEventsSub chan pipeline.Event
+	isSpeaking   bool
+	speakingMu   sync.Mutex
+
+	// Streaming recognizer
+	recognizer     asr.StreamingRecognizer
+	recognizerLock sync.Mutex
+
+	// How often the recognizer connection is checked to update the element status
+	statusInterval time.Duration
+
+	// Attributes of the latest input audio, attached to recognition results
+	attrs pipeline.AttributeTracker
+
+	// Fires EventNoResult when a commit gets no final transcript
+	resultWatchdog *resultWatchdog
+
+	// Minimum time between emitted partial results (0 = no throttling)
+	partialInterval time.Duration
+
+	// Commit stable, confident partials before the provider's final (0 = disabled)
+	earlyCommitStable        time.Duration
+	earlyCommitMinConfidence float32
+
+	// Optional provider hooks: onResult sees every result before it is sent
+	// downstream, onRestart runs when the recognizer session is replaced
+	onResult  func(result *asr.RecognitionResult, attrs pipeline.Attributes)
+	onRestart func()
+
+	// Lifecycle management
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newRealtimeSTTBase creates the shared part of a streaming STT element.
+func newRealtimeSTTBase(name, logTag string, provider asr.Provider, config realtimeSTTConfig) *realtimeSTTBase {
+	e := &realtimeSTTBase{
+		BaseElement:              pipeline.NewBaseElement(name, 100),
+		logTag:                   logTag,
+		provider:                 provider,
+		language:                 config.Language,
+		model:                    config.Model,
+		enablePartialResults:     config.EnablePartialResults,
+		recognition:              config.Recognition,
+		vadEnabled:               config.VADEnabled,
+		sampleRate:               config.SampleRate,
+		channels:                 config.Channels,
+		bitsPerSample:            config.BitsPerSample,
+		partialInterval:          time.Duration(config.PartialIntervalMs) * time.Millisecond,
+		earlyCommitStable:        time.Duration(config.EarlyCommitStableMs) * time.Millisecond,
+		earlyCommitMinConfidence: config.EarlyCommitMinConfidence,
+		statusInterval:           sttConnectionCheckInterval,
+	}
+	e.resultWatchdog = newResultWatchdog(sttResultTimeout(config.ResultTimeout), e.onResultTimeout)
+
+	// Register properties for runtime configuration
+	e.registerProperties()
+
+	return e
+}
+
+// registerProperties sets up the property system for runtime configuration.
+func (e *realtimeSTTBase) registerProperties() {
+	e.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "language",
+		Type:     reflect.TypeOf(""),
+		Writable: true,
+		Readable: true,
+		Default:  e.language,
+	})
+
+	e.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "model",
+		Type:     reflect.TypeOf(""),
+		Writable: true,
+		Readable: true,
+		Default:  e.model,
+	})
+
+	e.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "enable_partial_results",
+		Type:     reflect.TypeOf(false),
+		Writable: true,
+		Readable: true,
+		Default:  e.enablePartialResults,
+	})
+
+	e.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "vad_enabled",
+		Type:     reflect.TypeOf(false),
+		Writable: true,
+		Readable: true,
+		Default:  e.vadEnabled,
+	})
+}
+
+// logf logs with the element's prefix.
+func (e *realtimeSTTBase) logf(format string, args ...interface{}) {
+	log.Printf("["+e.logTag+"] "+format, args...)
+}
+
+// Start starts the element.
+func (e *realtimeSTTBase) Start(ctx context.Context) error {
+	e.ctx, e.cancel = context.WithCancel(ctx)
+
+	e.logf("Starting element (VAD: %v, Language: %s, Model: %s)",
+		e.vadEnabled, e.language, e.model)
+
+	// Subscribe to VAD events if VAD is enabled
+	if e.vadEnabled && e.BaseElement.Bus() != nil {
+		e.vadEventsSub = make(chan pipeline.Event, 10)
+		e.BaseElement.Bus().Subscribe(pipeline.EventVADSpeechStart, e.vadEventsSub)
+		e.BaseElement.Bus().Subscribe(pipeline.EventVADSpeechEnd, e.vadEventsSub)
+
+		e.logf("Subscribed to VAD events")
+	}
+
+	// Start streaming recognizer
+	if err := e.startRecognizer(e.ctx); err != nil {
+		e.cancel()
+		err = fmt.Errorf("failed to start recognizer: %w", err)
+		e.SetStatus(pipeline.ElementStateFailed, err)
+		return err
+	}
+
+	// Start audio processing goroutine
+	e.wg.Add(1)
+	go e.processAudio(e.ctx)
+
+	// Start VAD event handler if enabled
+	if e.vadEnabled {
+		e.wg.Add(1)
+		go e.handleVADEvents(e.ctx)
+	}
+
+	// Start result handler
+	e.wg.Add(1)
+	go e.handleResults(e.ctx)
+
+	// Report a dropped connection as a Degraded element status
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		watchRecognizerConnection(e.ctx, e.BaseElement, e.statusInterval, e.currentRecognizer)
+	}()
+
+	e.logf("Element started successfully")
+	return nil
+}
+
+// Stop stops the element.
+func (e *realtimeSTTBase) Stop() error {
+	e.logf("Stopping element")
+
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.resultWatchdog.Disarm()
+
+	// Close recognizer first
+	e.recognizerLock.Lock()
+	if e.recognizer != nil {
+		e.recognizer.Close()
+		e.recognizer = nil
+	}
+	e.recognizerLock.Unlock()
+
+	// Wait for goroutines
+	e.wg.Wait()
+
+	// Close provider
+	if e.provider != nil {
+		e.provider.Close()
+	}
+
+	// Unsubscribe from VAD events
+	if e.vadEventsSub != nil {
+		if e.BaseElement.Bus() != nil {
+			e.BaseElement.Bus().Unsubscribe(pipeline.EventVADSpeechStart, e.vadEventsSub)
+			e.BaseElement.Bus().Unsubscribe(pipeline.EventVADSpeechEnd, e.vadEventsSub)
+		}
+		close(e.vadEventsSub)
+		e.vadEventsSub = nil
+	}
+
+	e.logf("Stopped")
+	return nil
+}
+
+// startRecognizer creates and starts a streaming recognizer.
+func (e *realtimeSTTBase) startRecognizer(ctx context.Context) error {
+	e.recognizerLock.Lock()
+	defer e.recognizerLock.Unlock()
+
+	audioConfig := asr.AudioConfig{
+		SampleRate:    e.sampleRate,
+		Channels:      e.channels,
+		Encoding:      "pcm",
+		BitsPerSample: e.bitsPerSample,
+	}
+
+	recognitionConfig := e.recognition
+	recognitionConfig.Language = e.recognitionLanguage()
+	recognitionConfig.Model = e.model
+	recognitionConfig.EnablePartialResults = e.enablePartialResults
+
+	recognizer, err := e.provider.StreamingRecognize(ctx, audioConfig, recognitionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create streaming recognizer: %w", err)
+	}
+
+	e.recognizer = recognizer
+	e.logf("Streaming recognizer started")
+	return nil
+}
+
+// processAudio processes incoming audio messages.
+func (e *realtimeSTTBase) processAudio(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-e.BaseElement.InChan:
+			if !ok {
+				return
+			}
+
+			// Only process audio messages
+			if msg.Type != pipeline.MsgTypeAudio || msg.AudioData == nil {
+				continue
+			}
+			e.attrs.Track(msg)
+
+			// Validate audio format
+			if msg.AudioData.SampleRate != e.sampleRate {
+				e.logf("Warning: Audio sample rate mismatch (expected %d, got %d)",
+					e.sampleRate, msg.AudioData.SampleRate)
+				continue
+			}
+			if msg.AudioData.Channels > 0 && msg.AudioData.Channels != e.channels {
+				e.logf("Warning: Audio channel count mismatch (expected %d, got %d)",
+					e.channels, msg.AudioData.Channels)
+				continue
+			}
+
+			// With VAD, only send audio while speaking
+			if e.vadEnabled {
+				e.speakingMu.Lock()
+				isSpeaking := e.isSpeaking
+				e.speakingMu.Unlock()
+
+				if !isSpeaking {
+					continue
+				}
+			}
+
+			e.sendAudioToRecognizer(ctx, msg.AudioData.Data)
+		}
+	}
+}
+
+// handleVADEvents processes VAD speech start/end events.
+func (e *realtimeSTTBase) handleVADEvents(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-e.vadEventsSub:
+			if !ok {
+				return
+			}
+
+			switch event.Type {
+			case pipeline.EventVADSpeechStart:
+				// Extract pre-roll audio from VAD payload
+				if payload, ok := event.Payload.(pipeline.VADPayload); ok {
+					// Send pre-roll audio first (before setting isSpeaking)
+					if len(payload.PreRollAudio) > 0 {
+						e.logf("VAD speech started with %d bytes pre-roll audio",
+							len(payload.PreRollAudio))
+						e.sendAudioToRecognizer(ctx, payload.PreRollAudio)
+					} else {
+						e.logf("VAD speech started (no pre-roll)")
+					}
+				} else {
+					e.logf("VAD speech started (legacy payload)")
+				}
+
+				e.speakingMu.Lock()
+				e.isSpeaking = true
+				e.speakingMu.Unlock()
+
+			case pipeline.EventVADSpeechEnd:
+				e.logf("VAD speech ended")
+				e.speakingMu.Lock()
+				e.isSpeaking = false
+				e.speakingMu.Unlock()
+
+				// Commit to trigger final transcription
+				e.commitRecognizer(ctx)
+			}
+		}
+	}
+}
+
+// ResetInput drops the utterance in progress without committing it.
+// Audio already streamed to the recognizer can't be withdrawn, so the
+// recognizer is replaced in the background. Implements pipeline.InputResetter.
+func (e *realtimeSTTBase) ResetInput() {
+	e.speakingMu.Lock()
+	uncommitted := e.isSpeaking || !e.vadEnabled
+	e.isSpeaking = false
+	e.speakingMu.Unlock()
+
+	ctx := e.ctx
+	if !uncommitted || ctx == nil || ctx.Err() != nil {
+		return
+	}
+
+	e.logf("Input reset, restarting recognizer")
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.restartRecognizer(ctx)
+	}()
+}
+
+// restartRecognizer closes the current recognizer, discarding its pending
+// audio, and starts a fresh one with its own result handler.
+func (e *realtimeSTTBase) restartRecognizer(ctx context.Context) {
+	e.recognizerLock.Lock()
+	old := e.recognizer
+	e.recognizer = nil
+	e.recognizerLock.Unlock()
+	e.resultWatchdog.Disarm()
+
+	// The old handleResults exits once the closed recognizer's results channel closes
+	if old != nil {
+		old.Close()
+	}
+	if e.onRestart != nil {
+		e.onRestart()
+	}
+
+	if err := e.startRecognizer(ctx); err != nil {
+		e.logf("Failed to restart recognizer: %v", err)
+		e.SetStatus(pipeline.ElementStateDegraded, err)
+		return
+	}
+	if ctx.Err() != nil {
+		// Stopped while reconnecting
+		e.recognizerLock.Lock()
+		if e.recognizer != nil {
+			e.recognizer.Close()
+			e.recognizer = nil
+		}
+		e.recognizerLock.Unlock()
+		return
+	}
+
+	e.wg.Add(1)
+	go e.handleResults(ctx)
+}
+
+// currentRecognizer returns the active recognizer, nil while it is being replaced.
+func (e *realtimeSTTBase) currentRecognizer() asr.StreamingRecognizer {
+	e.recognizerLock.Lock()
+	defer e.recognizerLock.Unlock()
+	return e.recognizer
+}
+
+// sendAudioToRecognizer sends audio data to the streaming recognizer.
+func (e *realtimeSTTBase) sendAudioToRecognizer(ctx context.Context, audioData []byte) {
+	recognizer := e.currentRecognizer()
+	if recognizer == nil {
+		return
+	}
+
+	if err := recognizer.SendAudio(ctx, audioData); err != nil {
+		e.logf("Error sending audio to recognizer: %v", err)
+	}
+}
+
+// commitRecognizer ends the current utterance to trigger final transcription.
+func (e *realtimeSTTBase) commitRecognizer(ctx context.Context) {
+	committer, ok := e.currentRecognizer().(sttCommitter)
+	if !ok {
+		return
+	}
+
+	if err := committer.Commit(ctx); err != nil {
+		e.logf("Error committing audio: %v", err)
+		return
+	}
+
+	e.logf("Committed audio for final transcription")
+	e.resultWatchdog.Arm()
+	e.ProviderLogger().Log(pipeline.ProviderRecord{
+		Kind:     pipeline.ProviderSTT,
+		Provider: e.provider.Name(),
+		Element:  e.GetName(),
+		Model:    e.model,
+		Language: e.recognitionLanguage(),
+	})
+}
+
+// recognitionLanguage returns the configured language, falling back to the
+// pipeline LanguageContext source language.
+func (e *realtimeSTTBase) recognitionLanguage() string {
+	if e.language != "" {
+		return e.language
+	}
+	return e.LanguageContext().Source()
+}
+
+// handleResults processes recognition results from the streaming recognizer.
+func (e *realtimeSTTBase) handleResults(ctx context.Context) {
+	defer e.wg.Done()
+
+	recognizer := e.currentRecognizer()
+	if recognizer == nil {
+		e.logf("No recognizer available for results")
+		return
+	}
+
+	resultsChan := recognizer.Results()
+	throttle := newPartialThrottle(e.partialInterval, pipeline.SystemClock)
+	early := newEarlyCommit(e.earlyCommitStable, e.earlyCommitMinConfidence, pipeline.SystemClock)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-throttle.C():
+			if result := throttle.Flush(); result != nil && !e.emitResult(ctx, result, nil) {
+				return
+			}
+
+		case <-early.C():
+			if result := early.Commit(); result != nil {
+				e.logf("Committing stable partial early")
+				e.resultWatchdog.Disarm()
+				throttle.Reset()
+				if !e.emitResult(ctx, result, nil) {
+					return
+				}
+			}
+
+		case result, ok := <-resultsChan:
+			if !ok {
+				e.logf("Results channel closed")
+				return
+			}
+
+			if result == nil {
+				continue
+			}
+
+			var metadata interface{}
+			if result.IsFinal {
+				e.resultWatchdog.Disarm()
+				final, replaces := early.Reconcile(result)
+				if final == nil {
+					e.logf("Final transcript matches the early commit")
+					throttle.Reset()
+					continue
+				}
+				if replaces != "" {
+					e.logf("Final transcript corrects the early commit %q", replaces)
+					metadata = STTCorrection{Replaces: replaces}
+				}
+				if result.Text == "" {
+					e.logf("Empty final transcript")
+					publishNoResult(e.BaseElement.Bus(), e.GetName(), "empty", e.resultWatchdog.Timeout())
+					continue
+				}
+			}
+
+			// Skip empty results
+			if result.Text == "" {
+				continue
+			}
+
+			if result.IsFinal {
+				throttle.Reset()
+			} else if !early.Observe(result) {
+				continue
+			} else if result = throttle.Offer(result); result == nil {
+				continue
+			}
+
+			if !e.emitResult(ctx, result, metadata) {
+				return
+			}
+		}
+	}
+}
+
+// emitResult sends a recognition result downstream, with the given message
+// Metadata, and publishes it on the bus. It returns false if ctx was cancelled.
+func (e *realtimeSTTBase) emitResult(ctx context.Context, result *asr.RecognitionResult, metadata interface{}) bool {
+	// Let downstream elements follow the spoken language
+	e.LanguageContext().SetDetected(result.Language)
+
+	// Determine text type
+	textType := "text/partial"
+	eventType := pipeline.EventPartialResult
+	if result.IsFinal {
+		textType = "text/final"
+		eventType = pipeline.EventFinalResult
+		e.ProviderLogger().Log(pipeline.ProviderRecord{
+			Kind:     pipeline.ProviderSTT,
+			Provider: e.provider.Name(),
+			Element:  e.GetName(),
+			Response: true,
+			Model:    e.model,
+			Language: result.Language,
+			Text:     result.Text,
+		})
+	}
+
+	e.logf("Recognition result (%s): %s", textType, result.Text)
+
+	// Create text data message
+	attrs := e.attrs.Attributes()
+	if e.onResult != nil {
+		e.onResult(result, attrs)
+	}
+	textMsg := &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeData,
+		Timestamp:  time.Now(),
+		Attributes: attrs,
+		Metadata:   metadata,
+		TextData: &pipeline.TextData{
+			Data:      []byte(result.Text),
+			TextType:  textType,
+			Timestamp: result.Timestamp,
+		},
+	}
+
+	// Send to output channel
+	select {
+	case e.BaseElement.OutChan <- textMsg:
+	case <-ctx.Done():
+		return false
+	}
+
+	// Publish event to bus
+	if e.BaseElement.Bus() != nil {
+		e.BaseElement.Bus().Publish(pipeline.Event{
+			Type:       eventType,
+			Timestamp:  result.Timestamp,
+			Payload:    result.Text,
+			Attributes: attrs,
+		})
+		publishTranscriptResult(e.BaseElement.Bus(), e.GetName(), result, attrs)
+	}
+	return true
+}
+
+// onResultTimeout publishes EventNoResult when a commit got no final transcript.
+func (e *realtimeSTTBase) onResultTimeout() {
+	e.logf("No final transcript within %v of commit", e.resultWatchdog.Timeout())
+	publishNoResult(e.BaseElement.Bus(), e.GetName(), "timeout", e.resultWatchdog.Timeout())
+}
+
+// SetProperty sets a property value at runtime.
+func (e *realtimeSTTBase) SetProperty(name string, value interface{}) error {
+	switch name {
+	case "language":
+		if lang, ok := value.(string); ok {
+			e.language = lang
+			e.logf("Language set to: %s", lang)
+			return nil
+		}
+	case "model":
+		if model, ok := value.(string); ok {
+			e.model = model
+			e.logf("Model set to: %s", model)
+			return nil
+		}
+	case "enable_partial_results":
+		if enable, ok := value.(bool); ok {
+			e.enablePartialResults = enable
+			e.logf("Partial results: %v", enable)
+			return nil
+		}
+	case "vad_enabled":
+		if enable, ok := value.(bool); ok {
+			e.vadEnabled = enable
+			e.logf("VAD enabled: %v", enable)
+			return nil
+		}
+	}
+
+	return e.BaseElement.SetProperty(name, value)
+}
+
+// GetProperty gets a property value.
+func (e *realtimeSTTBase) GetProperty(name string) (interface{}, error) {
+	switch name {
+	case "language":
+		return e.language, nil
+	case "model":
+		return e.model, nil
+	case "enable_partial_results":
+		return e.enablePartialResults, nil
+	case "vad_enabled":
+		return e.vadEnabled, nil
+	}
+
+	return e.BaseElement.GetProperty(name)
+}
+
+// Commit commits the current audio buffer to trigger final transcription.
+// This is useful in non-VAD mode when you want to manually control when
+// final transcriptions are generated.
+func (e *realtimeSTTBase) Commit(ctx context.Context) error {
+	e.commitRecognizer(ctx)
+	return nil
+}
+
+// Healthy reports whether the streaming recognizer has a live connection.
+// It implements pipeline.HealthChecker.
+func (e *realtimeSTTBase) Healthy() bool {
+	hc, ok := e.currentRecognizer().(pipeline.HealthChecker)
+	return ok && hc.Healthy()
+}
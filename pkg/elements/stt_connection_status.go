@@ -0,0 +1,68 @@
+// Package elements provides pipeline processing elements.
+//
+// Connection status for the streaming STT elements. A recognizer whose
+// WebSocket has dropped stops producing transcripts without any error on the
+// audio path, so the elements poll its health and report it through the
+// element status: Degraded while the connection is down (EventElementError)
+// and Running again once it is back (EventElementRecovered).
+package elements
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// sttConnectionCheckInterval is how often streaming STT elements check their
+// recognizer connection.
+const sttConnectionCheckInterval = time.Second
+
+// errRecognizerConnectionLost is reported when a recognizer is unhealthy
+// without telling why.
+var errRecognizerConnectionLost = errors.New("streaming recognizer connection lost")
+
+// recognizerError returns why the recognizer's connection is down, or nil if
+// it is up or the recognizer does not report its health.
+func recognizerError(recognizer asr.StreamingRecognizer) error {
+	hc, ok := recognizer.(pipeline.HealthChecker)
+	if !ok || hc.Healthy() {
+		return nil
+	}
+	if r, ok := recognizer.(asr.ConnectionErrorReporter); ok {
+		if err := r.ConnectionError(); err != nil {
+			return err
+		}
+	}
+	return errRecognizerConnectionLost
+}
+
+// watchRecognizerConnection keeps the element status in sync with the
+// recognizer connection until ctx is done. A dropped connection marks the
+// element Degraded, which publishes EventElementError; a live connection
+// marks it Running again, which publishes EventElementRecovered. While the
+// recognizer is being replaced (current returns nil) the status is kept.
+func watchRecognizerConnection(ctx context.Context, base *pipeline.BaseElement, interval time.Duration, current func() asr.StreamingRecognizer) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		recognizer := current()
+		if recognizer == nil {
+			continue
+		}
+		if err := recognizerError(recognizer); err != nil {
+			base.SetStatus(pipeline.ElementStateDegraded, err)
+		} else {
+			base.SetStatus(pipeline.ElementStateRunning, nil)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRecognizer is a streaming recognizer whose connection can be dropped
+type flakyRecognizer struct {
+	healthy atomic.Bool
+	err     atomic.Pointer[error]
+	results chan *asr.RecognitionResult
+}
+
+func newFlakyRecognizer() *flakyRecognizer {
+	r := &flakyRecognizer{results: make(chan *asr.RecognitionResult)}
+	r.healthy.Store(true)
+	return r
+}
+
+func (r *flakyRecognizer) SendAudio(ctx context.Context, audioData []byte) error { return nil }
+func (r *flakyRecognizer) Results() <-chan *asr.RecognitionResult                { return r.results }
+func (r *flakyRecognizer) Close() error                                          { return nil }
+func (r *flakyRecognizer) Healthy() bool                                         { return r.healthy.Load() }
+
+func (r *flakyRecognizer) ConnectionError() error {
+	if err := r.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// drop simulates the provider closing the WebSocket
+func (r *flakyRecognizer) drop(err error) {
+	r.err.Store(&err)
+	r.healthy.Store(false)
+}
+
+func TestSTTConnectionStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func(t *testing.T, recognizer asr.StreamingRecognizer) (*pipeline.BaseElement, func() asr.StreamingRecognizer)
+	}{
+		{
+			name: "elevenlabs-realtime-stt",
+			create: func(t *testing.T, recognizer asr.StreamingRecognizer) (*pipeline.BaseElement, func() asr.StreamingRecognizer) {
+				elem, err := NewElevenLabsRealtimeSTTElement(ElevenLabsRealtimeSTTConfig{APIKey: "test-key"})
+				require.NoError(t, err)
+				elem.recognizer = recognizer
+				return elem.BaseElement, elem.currentRecognizer
+			},
+		},
+		{
+			name: "qwen-realtime-stt",
+			create: func(t *testing.T, recognizer asr.StreamingRecognizer) (*pipeline.BaseElement, func() asr.StreamingRecognizer) {
+				elem, err := NewQwenRealtimeSTTElement(QwenRealtimeSTTConfig{APIKey: "test-key"})
+				require.NoError(t, err)
+				elem.recognizer = recognizer
+				return elem.BaseElement, elem.currentRecognizer
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recognizer := newFlakyRecognizer()
+			base, current := tt.create(t, recognizer)
+
+			bus := pipeline.NewEventBus()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			bus.Start(ctx)
+			base.SetBus(bus)
+
+			events := make(chan pipeline.Event, 10)
+			bus.Subscribe(pipeline.EventElementError, events)
+			bus.Subscribe(pipeline.EventElementRecovered, events)
+
+			go watchRecognizerConnection(ctx, base, 10*time.Millisecond, current)
+			require.Eventually(t, func() bool { return base.Status() == pipeline.ElementStateRunning },
+				time.Second, 5*time.Millisecond)
+
+			// The provider drops the connection mid-call
+			dropped := errors.New("websocket: close 1006 (abnormal closure): unexpected EOF")
+			recognizer.drop(dropped)
+
+			select {
+			case evt := <-events:
+				require.Equal(t, pipeline.EventElementError, evt.Type)
+				payload := evt.Payload.(pipeline.ElementErrorPayload)
+				assert.Equal(t, tt.name, payload.Element)
+				assert.Equal(t, pipeline.ElementStateDegraded, payload.State)
+				assert.ErrorIs(t, payload.Err, dropped)
+			case <-time.After(time.Second):
+				t.Fatal("EventElementError not published")
+			}
+			assert.Equal(t, pipeline.ElementStateDegraded, base.Status())
+
+			// A live connection again recovers the element
+			recognizer.healthy.Store(true)
+			select {
+			case evt := <-events:
+				require.Equal(t, pipeline.EventElementRecovered, evt.Type)
+				assert.Equal(t, tt.name, evt.Payload.(pipeline.ElementRecoveredPayload).Element)
+			case <-time.After(time.Second):
+				t.Fatal("EventElementRecovered not published")
+			}
+			assert.Equal(t, pipeline.ElementStateRunning, base.Status())
+		})
+	}
+}
+
+func TestRecognizerError(t *testing.T) {
+	recognizer := newFlakyRecognizer()
+	assert.NoError(t, recognizerError(recognizer))
+
+	// Unhealthy without a reported error
+	recognizer.healthy.Store(false)
+	assert.ErrorIs(t, recognizerError(recognizer), errRecognizerConnectionLost)
+}
@@ -0,0 +1,141 @@
+// Package elements provides pipeline processing elements.
+//
+// Early commit for the realtime STT elements (EarlyCommitStableMs). Providers
+// often take a few hundred milliseconds after the speaker stops to send the
+// final transcript, although the last partial already has the same text.
+// Committing a partial once it has been stable and confident enough lets the
+// LLM start that much sooner on every turn.
+//
+// Features:
+//   - A partial becomes the final after EarlyCommitStableMs without changes,
+//     if its confidence is at least EarlyCommitMinConfidence
+//   - The provider's final is dropped when it matches the committed text
+//   - A different final is sent as "text/final" with STTCorrection metadata
+package elements
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// STTCorrection is the Metadata of a "text/final" message that replaces a
+// transcript committed early (see EarlyCommitStableMs) because the
+// provider's final turned out to be different. Consumers that already acted
+// on the early transcript should treat the new text as a replacement for
+// Replaces rather than as a new utterance.
+type STTCorrection struct {
+	Replaces string // the early-committed text
+}
+
+// earlyCommit commits a realtime transcript before the provider's final
+// arrives: once a partial has stayed unchanged for the stable duration with
+// enough confidence, it is emitted as a final. Further partials of the
+// utterance are dropped, and the provider's final is reconciled against the
+// committed text. A nil earlyCommit is valid and never commits early.
+//
+// It is not safe for concurrent use; the result loop owns it.
+type earlyCommit struct {
+	stable        time.Duration
+	minConfidence float32
+	clock         pipeline.Clock
+	candidate     *asr.RecognitionResult // latest partial, not yet committed
+	changed       time.Time              // when the candidate text last changed
+	due           <-chan time.Time       // fires when the candidate is stable
+	committed     *asr.RecognitionResult // committed early, awaiting the provider's final
+}
+
+// newEarlyCommit returns an earlyCommit for partials stable for the given
+// duration with at least minConfidence. It returns nil when stable <= 0.
+func newEarlyCommit(stable time.Duration, minConfidence float32, clock pipeline.Clock) *earlyCommit {
+	if stable <= 0 {
+		return nil
+	}
+	return &earlyCommit{stable: stable, minConfidence: minConfidence, clock: clock}
+}
+
+// Observe records a partial result. It returns false if the utterance was
+// already committed early and the partial should be dropped.
+func (c *earlyCommit) Observe(result *asr.RecognitionResult) bool {
+	if c == nil {
+		return true
+	}
+	if c.committed != nil {
+		return false
+	}
+
+	now := c.clock.Now()
+	if c.candidate == nil || c.candidate.Text != result.Text {
+		c.changed = now
+		c.due = nil
+	}
+	c.candidate = result
+
+	// Results without a confidence score (-1) only qualify when no minimum is set
+	if c.minConfidence > 0 && result.Confidence < c.minConfidence {
+		c.due = nil
+	} else if c.due == nil {
+		c.due = c.clock.After(c.changed.Add(c.stable).Sub(now))
+	}
+	return true
+}
+
+// C fires when the candidate partial has been stable long enough. It is nil
+// while there is nothing to commit.
+func (c *earlyCommit) C() <-chan time.Time {
+	if c == nil {
+		return nil
+	}
+	return c.due
+}
+
+// Commit returns the candidate as a final result after C fired.
+func (c *earlyCommit) Commit() *asr.RecognitionResult {
+	candidate := c.candidate
+	c.candidate = nil
+	c.due = nil
+	if candidate == nil {
+		return nil
+	}
+
+	result := *candidate
+	result.IsFinal = true
+	c.committed = &result
+	return &result
+}
+
+// Reconcile matches the provider's final against an early commit of the same
+// utterance. It returns the final to emit, or nil if the early commit already
+// covered it, and the early-committed text the final replaces, if any.
+func (c *earlyCommit) Reconcile(final *asr.RecognitionResult) (result *asr.RecognitionResult, replaces string) {
+	if c == nil {
+		return final, ""
+	}
+
+	committed := c.committed
+	c.candidate = nil
+	c.due = nil
+	c.committed = nil
+	if committed == nil {
+		return final, ""
+	}
+
+	if final.Text == "" || normalizeTranscript(final.Text) == normalizeTranscript(committed.Text) {
+		return nil, ""
+	}
+	return final, committed.Text
+}
+
+// normalizeTranscript drops case, whitespace and punctuation, which providers
+// commonly add or change between the last partial and the final.
+func normalizeTranscript(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, text)
+}
@@ -0,0 +1,118 @@
+package elements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scoredPartial(text string, confidence float32) *asr.RecognitionResult {
+	return &asr.RecognitionResult{Text: text, Confidence: confidence}
+}
+
+func assertNotDue(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case <-c:
+		t.Fatal("early commit due too early")
+	default:
+	}
+}
+
+func TestEarlyCommitDisabled(t *testing.T) {
+	early := newEarlyCommit(0, 0.9, pipeline.NewManualClock(time.Unix(0, 0)))
+	assert.Nil(t, early)
+
+	assert.True(t, early.Observe(scoredPartial("hello", 1)))
+	assert.Nil(t, early.C())
+	final := &asr.RecognitionResult{Text: "hello", IsFinal: true}
+	result, replaces := early.Reconcile(final)
+	assert.Same(t, final, result)
+	assert.Empty(t, replaces)
+}
+
+func TestEarlyCommitStablePartial(t *testing.T) {
+	clock := pipeline.NewManualClock(time.Unix(100, 0))
+	early := newEarlyCommit(300*time.Millisecond, 0.8, clock)
+
+	// A changing partial restarts the stability window
+	assert.True(t, early.Observe(scoredPartial("what is", 0.9)))
+	clock.Advance(200 * time.Millisecond)
+	assert.True(t, early.Observe(scoredPartial("what is the weather", 0.9)))
+	require.NotNil(t, early.C())
+	clock.Advance(200 * time.Millisecond)
+	assertNotDue(t, early.C())
+
+	// Repeating the same text does not restart it
+	assert.True(t, early.Observe(scoredPartial("what is the weather", 0.95)))
+	clock.Advance(100 * time.Millisecond)
+	<-early.C()
+
+	result := early.Commit()
+	require.NotNil(t, result)
+	assert.True(t, result.IsFinal)
+	assert.Equal(t, "what is the weather", result.Text)
+	assert.Nil(t, early.C())
+
+	// Later partials of the committed utterance are dropped
+	assert.False(t, early.Observe(scoredPartial("what is the weather", 0.95)))
+
+	// The provider's final matches apart from case and punctuation
+	result, replaces := early.Reconcile(&asr.RecognitionResult{Text: "What is the weather?", IsFinal: true})
+	assert.Nil(t, result)
+	assert.Empty(t, replaces)
+
+	// The next utterance starts fresh
+	assert.True(t, early.Observe(scoredPartial("thanks", 0.9)))
+	require.NotNil(t, early.C())
+}
+
+func TestEarlyCommitCorrection(t *testing.T) {
+	clock := pipeline.NewManualClock(time.Unix(100, 0))
+	early := newEarlyCommit(300*time.Millisecond, 0.8, clock)
+
+	early.Observe(scoredPartial("book a table", 0.9))
+	clock.Advance(300 * time.Millisecond)
+	<-early.C()
+	require.NotNil(t, early.Commit())
+
+	// The user kept talking after the pause: the final replaces the early commit
+	final := &asr.RecognitionResult{Text: "Book a table for two.", IsFinal: true}
+	result, replaces := early.Reconcile(final)
+	assert.Same(t, final, result)
+	assert.Equal(t, "book a table", replaces)
+
+	// Without an early commit the final passes through unchanged
+	result, replaces = early.Reconcile(final)
+	assert.Same(t, final, result)
+	assert.Empty(t, replaces)
+}
+
+func TestEarlyCommitConfidence(t *testing.T) {
+	clock := pipeline.NewManualClock(time.Unix(100, 0))
+	early := newEarlyCommit(300*time.Millisecond, 0.8, clock)
+
+	// A low-confidence partial never commits, however stable
+	early.Observe(scoredPartial("hello", 0.5))
+	assert.Nil(t, early.C())
+	clock.Advance(time.Second)
+	assert.Nil(t, early.C())
+
+	// Once the confidence rises the text already counts as stable
+	early.Observe(scoredPartial("hello", 0.9))
+	<-early.C()
+	assert.Equal(t, "hello", early.Commit().Text)
+	early.Reconcile(&asr.RecognitionResult{Text: "hello", IsFinal: true})
+
+	// Results without a confidence score do not qualify when a minimum is set
+	early.Observe(scoredPartial("bye", -1))
+	assert.Nil(t, early.C())
+
+	unscored := newEarlyCommit(300*time.Millisecond, 0, clock)
+	unscored.Observe(scoredPartial("bye", -1))
+	assert.NotNil(t, unscored.C())
+}
@@ -0,0 +1,78 @@
+// Package elements provides pipeline processing elements.
+//
+// partialThrottle implements PartialIntervalMs for the realtime STT elements.
+// Some providers send a partial transcript every few tens of milliseconds,
+// and every one of them costs downstream work (caption updates, early
+// translation). The throttle keeps only the latest partial per interval,
+// so the text shown is never stale, and a final result is never delayed.
+package elements
+
+import (
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// partialThrottle coalesces partial STT results so that at most one partial
+// is emitted per interval. Partials arriving within the interval replace each
+// other and only the latest is emitted once the interval has passed; a final
+// result drops the pending partial. A zero interval emits every partial.
+//
+// It is not safe for concurrent use; the result loop owns it.
+type partialThrottle struct {
+	interval time.Duration
+	clock    pipeline.Clock
+	last     time.Time              // when the last partial was emitted
+	pending  *asr.RecognitionResult // latest partial not yet emitted
+	due      <-chan time.Time       // fires when pending may be emitted
+}
+
+// newPartialThrottle returns a throttle emitting at most one partial per interval.
+func newPartialThrottle(interval time.Duration, clock pipeline.Clock) *partialThrottle {
+	return &partialThrottle{interval: interval, clock: clock}
+}
+
+// Offer returns result if it may be emitted now, or nil if it was held back
+// until C fires.
+func (t *partialThrottle) Offer(result *asr.RecognitionResult) *asr.RecognitionResult {
+	if t.interval <= 0 {
+		return result
+	}
+
+	now := t.clock.Now()
+	if t.pending == nil && now.Sub(t.last) >= t.interval {
+		t.last = now
+		return result
+	}
+
+	t.pending = result
+	if t.due == nil {
+		t.due = t.clock.After(t.last.Add(t.interval).Sub(now))
+	}
+	return nil
+}
+
+// C fires when a held-back partial is due. It is nil while nothing is pending.
+func (t *partialThrottle) C() <-chan time.Time {
+	return t.due
+}
+
+// Flush returns the held-back partial, if any, after C fired.
+func (t *partialThrottle) Flush() *asr.RecognitionResult {
+	result := t.pending
+	t.pending = nil
+	t.due = nil
+	if result != nil {
+		t.last = t.clock.Now()
+	}
+	return result
+}
+
+// Reset drops the held-back partial when a final result arrives, so the first
+// partial of the next utterance is emitted right away.
+func (t *partialThrottle) Reset() {
+	t.pending = nil
+	t.due = nil
+	t.last = time.Time{}
+}
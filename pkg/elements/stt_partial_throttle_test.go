@@ -0,0 +1,78 @@
+package elements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func partial(text string) *asr.RecognitionResult {
+	return &asr.RecognitionResult{Text: text}
+}
+
+func TestPartialThrottleDisabled(t *testing.T) {
+	throttle := newPartialThrottle(0, pipeline.NewManualClock(time.Unix(0, 0)))
+
+	for _, text := range []string{"h", "he", "hel"} {
+		assert.Equal(t, text, throttle.Offer(partial(text)).Text)
+	}
+	assert.Nil(t, throttle.C())
+}
+
+func TestPartialThrottleCoalesces(t *testing.T) {
+	clock := pipeline.NewManualClock(time.Unix(100, 0))
+	throttle := newPartialThrottle(200*time.Millisecond, clock)
+
+	// The first partial goes out immediately
+	assert.Equal(t, "h", throttle.Offer(partial("h")).Text)
+	assert.Nil(t, throttle.C())
+
+	// Partials within the interval are held back; only the latest survives
+	clock.Advance(50 * time.Millisecond)
+	assert.Nil(t, throttle.Offer(partial("he")))
+	clock.Advance(50 * time.Millisecond)
+	assert.Nil(t, throttle.Offer(partial("hel")))
+	require.NotNil(t, throttle.C())
+
+	select {
+	case <-throttle.C():
+		t.Fatal("pending partial due too early")
+	default:
+	}
+
+	clock.Advance(100 * time.Millisecond)
+	select {
+	case <-throttle.C():
+	default:
+		t.Fatal("pending partial not due after the interval")
+	}
+	assert.Equal(t, "hel", throttle.Flush().Text)
+	assert.Nil(t, throttle.C())
+
+	// The next window starts at the flush
+	clock.Advance(100 * time.Millisecond)
+	assert.Nil(t, throttle.Offer(partial("hell")))
+	clock.Advance(100 * time.Millisecond)
+	<-throttle.C()
+	assert.Equal(t, "hell", throttle.Flush().Text)
+}
+
+func TestPartialThrottleResetOnFinal(t *testing.T) {
+	clock := pipeline.NewManualClock(time.Unix(100, 0))
+	throttle := newPartialThrottle(200*time.Millisecond, clock)
+
+	throttle.Offer(partial("h"))
+	assert.Nil(t, throttle.Offer(partial("he")))
+
+	// A final result supersedes the pending partial
+	throttle.Reset()
+	assert.Nil(t, throttle.C())
+	assert.Nil(t, throttle.Flush())
+
+	// The next utterance's first partial is not delayed
+	assert.Equal(t, "w", throttle.Offer(partial("w")).Text)
+}
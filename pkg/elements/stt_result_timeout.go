@@ -0,0 +1,120 @@
+// Package elements provides pipeline processing elements.
+//
+// resultWatchdog guards the realtime STT elements against a dropped provider
+// response. The watchdog is armed when audio is committed (VAD speech end or
+// an explicit commit) and disarmed by the final transcript. If none arrives
+// within ResultTimeout the element publishes EventNoResult, so the turn ends
+// instead of waiting for a transcript that never comes.
+//
+// ResultTimeout of 0 selects DefaultSTTResultTimeout; a negative value
+// disables the watchdog.
+package elements
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// DefaultSTTResultTimeout is how long an STT element waits for a final
+// transcript after committing audio before it publishes EventNoResult.
+const DefaultSTTResultTimeout = 5 * time.Second
+
+// sttResultTimeout resolves a configured result timeout: zero selects the
+// default and a negative value disables the timeout.
+func sttResultTimeout(d time.Duration) time.Duration {
+	if d == 0 {
+		return DefaultSTTResultTimeout
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// resultWatchdog fires when committed audio gets no final transcript in time,
+// so a dropped STT response does not stall the turn indefinitely.
+// A nil watchdog is valid and does nothing.
+type resultWatchdog struct {
+	mu        sync.Mutex
+	timeout   time.Duration
+	timer     *time.Timer
+	gen       uint64 // bumped on every Arm/Disarm so stale timers are ignored
+	onTimeout func()
+}
+
+// newResultWatchdog returns a watchdog that calls onTimeout if Disarm is not
+// called within timeout of Arm. It returns nil when timeout <= 0.
+func newResultWatchdog(timeout time.Duration, onTimeout func()) *resultWatchdog {
+	if timeout <= 0 {
+		return nil
+	}
+	return &resultWatchdog{timeout: timeout, onTimeout: onTimeout}
+}
+
+// Arm starts (or restarts) the timeout after audio is committed.
+func (w *resultWatchdog) Arm() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopLocked()
+	gen := w.gen
+	w.timer = time.AfterFunc(w.timeout, func() {
+		w.mu.Lock()
+		fire := w.gen == gen
+		if fire {
+			w.timer = nil
+		}
+		w.mu.Unlock()
+
+		if fire {
+			w.onTimeout()
+		}
+	})
+}
+
+// Disarm cancels a pending timeout once a final transcript arrives.
+func (w *resultWatchdog) Disarm() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopLocked()
+}
+
+// Timeout returns the configured timeout, or 0 for a nil watchdog.
+func (w *resultWatchdog) Timeout() time.Duration {
+	if w == nil {
+		return 0
+	}
+	return w.timeout
+}
+
+func (w *resultWatchdog) stopLocked() {
+	w.gen++
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// publishNoResult publishes EventNoResult for an STT element.
+func publishNoResult(bus pipeline.Bus, source, reason string, timeout time.Duration) {
+	if bus == nil {
+		return
+	}
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventNoResult,
+		Timestamp: time.Now(),
+		Payload: pipeline.NoResultPayload{
+			Source:  source,
+			Reason:  reason,
+			Timeout: timeout,
+		},
+	})
+}
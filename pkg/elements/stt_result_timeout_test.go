@@ -0,0 +1,81 @@
+package elements
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSTTResultTimeoutDefaults(t *testing.T) {
+	assert.Equal(t, DefaultSTTResultTimeout, sttResultTimeout(0))
+	assert.Equal(t, 2*time.Second, sttResultTimeout(2*time.Second))
+	assert.Equal(t, time.Duration(0), sttResultTimeout(-1))
+	assert.Nil(t, newResultWatchdog(sttResultTimeout(-1), func() {}))
+}
+
+func TestResultWatchdogFires(t *testing.T) {
+	fired := make(chan struct{}, 1)
+	w := newResultWatchdog(20*time.Millisecond, func() { fired <- struct{}{} })
+
+	w.Arm()
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not fire")
+	}
+}
+
+func TestResultWatchdogDisarm(t *testing.T) {
+	var fired atomic.Int32
+	w := newResultWatchdog(30*time.Millisecond, func() { fired.Add(1) })
+
+	w.Arm()
+	w.Disarm()
+
+	// Re-arming restarts the window instead of firing twice
+	w.Arm()
+	time.Sleep(10 * time.Millisecond)
+	w.Arm()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), fired.Load())
+
+	w.Disarm()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), fired.Load())
+}
+
+func TestResultWatchdogNil(t *testing.T) {
+	var w *resultWatchdog
+	w.Arm()
+	w.Disarm()
+	assert.Equal(t, time.Duration(0), w.Timeout())
+}
+
+func TestPublishNoResult(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	defer bus.Stop()
+
+	ch := make(chan pipeline.Event, 1)
+	bus.Subscribe(pipeline.EventNoResult, ch)
+
+	publishNoResult(bus, "qwen-realtime-stt", "timeout", 5*time.Second)
+
+	select {
+	case evt := <-ch:
+		payload, ok := evt.Payload.(pipeline.NoResultPayload)
+		require.True(t, ok)
+		assert.Equal(t, "qwen-realtime-stt", payload.Source)
+		assert.Equal(t, "timeout", payload.Reason)
+		assert.Equal(t, 5*time.Second, payload.Timeout)
+	case <-time.After(time.Second):
+		t.Fatal("EventNoResult not published")
+	}
+}
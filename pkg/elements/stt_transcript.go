@@ -0,0 +1,55 @@
+// Package elements provides pipeline processing elements.
+//
+// Structured transcript events for the STT elements. EventPartialResult and
+// EventFinalResult only carry the text; EventTranscriptResult carries a
+// pipeline.TranscriptResult with the source element, whether it is final,
+// the utterance timing and word timings when the provider reports them, for
+// captions and transcript alignment.
+//
+// Usage:
+//
+//	ch := make(chan pipeline.Event, 10)
+//	p.Bus().Subscribe(pipeline.EventTranscriptResult, ch)
+//	for event := range ch {
+//		t := event.Payload.(*pipeline.TranscriptResult)
+//		log.Printf("[%s] %d-%dms %s", t.Source, t.StartMs, t.EndMs, t.Text)
+//	}
+package elements
+
+import (
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// newTranscriptResult converts a recognition result into the
+// EventTranscriptResult payload. Timings are left at zero when the provider
+// didn't report any.
+func newTranscriptResult(source string, result *asr.RecognitionResult) *pipeline.TranscriptResult {
+	transcript := &pipeline.TranscriptResult{
+		Source:  source,
+		Text:    result.Text,
+		IsFinal: result.IsFinal,
+		StartMs: result.StartTime.Milliseconds(),
+		EndMs:   result.EndTime.Milliseconds(),
+	}
+	for _, word := range result.Words {
+		transcript.Words = append(transcript.Words, pipeline.WordTiming{
+			Word:  word.Word,
+			Start: word.Start,
+			End:   word.End,
+		})
+	}
+	return transcript
+}
+
+// publishTranscriptResult publishes EventTranscriptResult for result. STT
+// elements call it right after publishing the plain-text EventPartialResult
+// or EventFinalResult, which existing subscribers keep receiving.
+func publishTranscriptResult(bus pipeline.Bus, source string, result *asr.RecognitionResult, attrs pipeline.Attributes) {
+	bus.Publish(pipeline.Event{
+		Type:       pipeline.EventTranscriptResult,
+		Timestamp:  result.Timestamp,
+		Payload:    newTranscriptResult(source, result),
+		Attributes: attrs,
+	})
+}
@@ -0,0 +1,274 @@
+// Package elements provides pipeline processing elements.
+//
+// SummarizerElement keeps long conversations within a small context window.
+// Every N assistant turns it folds the older part of a ChatElement's history
+// into a compact summary, which ChatElement then sends as an extra system note
+// in place of the removed turns.
+//
+// Main features:
+//   - Turn counting via EventResponseEnd on the pipeline bus
+//   - Incremental summaries (the previous summary is folded into the next one)
+//   - Keeps the most recent turns verbatim
+//   - EventSummaryUpdated for observability
+//
+// The element passes all messages through unchanged, so it only needs to be
+// added to the pipeline (for bus access); linking it is optional.
+//
+// Usage:
+//
+//	summarizer, err := NewSummarizerElement(SummarizerConfig{
+//	    Chat:        chat,
+//	    APIKey:      "sk-xxx",
+//	    Model:       "gpt-4o-mini",
+//	    EveryNTurns: 10,
+//	})
+package elements
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure SummarizerElement implements pipeline.Element
+var _ pipeline.Element = (*SummarizerElement)(nil)
+
+const defaultSummaryPrompt = "You maintain the long-term memory of a voice conversation. " +
+	"Merge the previous summary and the new conversation turns into one concise summary. " +
+	"Keep facts, names, decisions, user preferences and open questions. " +
+	"Write plain text, no more than 150 words."
+
+// SummarizerConfig holds configuration for the summarizer element
+type SummarizerConfig struct {
+	Chat        *ChatElement // Chat element whose history is summarized
+	APIKey      string       // OpenAI API key
+	Model       string       // Model used for summarization (default: gpt-4o-mini)
+	EveryNTurns int          // Summarize after every N assistant turns (default: 10)
+	KeepTurns   int          // Most recent turns kept verbatim (default: 2)
+	Prompt      string       // Custom summarization instruction
+
+	// Headers are extra headers sent on every API request (API gateway keys,
+	// org IDs, tracing headers)
+	Headers map[string]string
+}
+
+// SummarizerElement periodically summarizes older ChatElement history
+type SummarizerElement struct {
+	*pipeline.BaseElement
+
+	config SummarizerConfig
+	client *openai.Client
+
+	turns       int
+	summarizing bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// NewSummarizerElement creates a new summarizer element
+func NewSummarizerElement(config SummarizerConfig) (*SummarizerElement, error) {
+	if config.Chat == nil {
+		return nil, fmt.Errorf("chat element is required")
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if config.Model == "" {
+		config.Model = "gpt-4o-mini"
+	}
+	if config.EveryNTurns <= 0 {
+		config.EveryNTurns = 10
+	}
+	if config.KeepTurns <= 0 {
+		config.KeepTurns = 2
+	}
+	if config.Prompt == "" {
+		config.Prompt = defaultSummaryPrompt
+	}
+
+	return &SummarizerElement{
+		BaseElement: pipeline.NewBaseElement("summarizer-element", 100),
+		config:      config,
+	}, nil
+}
+
+// Start initializes the OpenAI client and begins listening for turns
+func (e *SummarizerElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	opts := []option.RequestOption{
+		option.WithAPIKey(e.config.APIKey),
+	}
+	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	for key, value := range e.config.Headers {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+	client := openai.NewClient(opts...)
+	e.client = &client
+
+	responseEndCh := make(chan pipeline.Event, 10)
+	e.Bus().Subscribe(pipeline.EventResponseEnd, responseEndCh)
+
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		defer e.Bus().Unsubscribe(pipeline.EventResponseEnd, responseEndCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-responseEndCh:
+				e.onTurnEnd(ctx)
+			}
+		}
+	}()
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.BaseElement.InChan:
+				select {
+				case e.BaseElement.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	log.Printf("[SummarizerElement] Started (model: %s, every %d turns, keep %d turns)",
+		e.config.Model, e.config.EveryNTurns, e.config.KeepTurns)
+	return nil
+}
+
+// Stop stops the summarizer element
+func (e *SummarizerElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	log.Println("[SummarizerElement] Stopped")
+	return nil
+}
+
+// onTurnEnd counts assistant turns and triggers summarization every N turns
+func (e *SummarizerElement) onTurnEnd(ctx context.Context) {
+	e.mu.Lock()
+	e.turns++
+	if e.turns < e.config.EveryNTurns || e.summarizing {
+		e.mu.Unlock()
+		return
+	}
+	e.turns = 0
+	e.summarizing = true
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer func() {
+			e.mu.Lock()
+			e.summarizing = false
+			e.mu.Unlock()
+		}()
+
+		if err := e.Summarize(ctx); err != nil {
+			log.Printf("[SummarizerElement] Summarization failed: %v", err)
+		}
+	}()
+}
+
+// summaryCut returns how many leading history messages to summarize so that
+// keepTurns turns are kept verbatim. Each turn is a user/assistant pair, but
+// the cut never separates tool results from the assistant message that
+// requested them: it moves back to that message, which is kept.
+func summaryCut(history []openai.ChatCompletionMessageParamUnion, keepTurns int) int {
+	count := len(history) - keepTurns*2
+	if count <= 0 {
+		return 0
+	}
+	for count > 0 && history[count].OfTool != nil {
+		count--
+	}
+	return count
+}
+
+// Summarize folds all but the most recent turns of the chat history into the summary
+func (e *SummarizerElement) Summarize(ctx context.Context) error {
+	// The tool call and its results must stay together in the history
+	if e.config.Chat.hasPendingToolCalls() {
+		log.Printf("[SummarizerElement] Tool call pending, skipping summarization")
+		return nil
+	}
+
+	history, offset := e.config.Chat.SnapshotHistory()
+	count := summaryCut(history, e.config.KeepTurns)
+	if count <= 0 {
+		return nil
+	}
+
+	var transcript strings.Builder
+	for _, msg := range history[:count] {
+		role, text := messageText(msg)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", role, text)
+	}
+
+	var input strings.Builder
+	if prev := e.config.Chat.GetSummary(); prev != "" {
+		fmt.Fprintf(&input, "Previous summary:\n%s\n\n", prev)
+	}
+	fmt.Fprintf(&input, "New conversation turns:\n%s", transcript.String())
+
+	completion, err := e.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(e.config.Prompt),
+			openai.UserMessage(input.String()),
+		},
+		Model: shared.ChatModel(e.config.Model),
+	})
+	if err != nil {
+		return fmt.Errorf("completion error: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return fmt.Errorf("no response from model")
+	}
+
+	summary := strings.TrimSpace(completion.Choices[0].Message.Content)
+	if summary == "" {
+		return fmt.Errorf("empty summary")
+	}
+
+	e.config.Chat.ApplySummary(summary, offset, count)
+
+	e.Bus().Publish(pipeline.Event{
+		Type:      pipeline.EventSummaryUpdated,
+		Timestamp: time.Now(),
+		Payload: &pipeline.SummaryPayload{
+			Summary:            summary,
+			SummarizedMessages: count,
+		},
+	})
+
+	log.Printf("[SummarizerElement] Summarized %d messages: %s", count, truncateForLog(summary, 100))
+	return nil
+}
@@ -0,0 +1,94 @@
+package elements
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolCallMessage is an assistant message calling the tool with the given ID
+func toolCallMessage(callID string) openai.ChatCompletionMessageParamUnion {
+	return openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+		ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+			ID:       callID,
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "lookup", Arguments: "{}"},
+		}},
+	}}
+}
+
+func TestSummaryCut(t *testing.T) {
+	user, assistant := openai.UserMessage("u"), openai.AssistantMessage("a")
+	call1, call2 := toolCallMessage("call_1"), toolCallMessage("call_2")
+	result1, result2 := openai.ToolMessage("r1", "call_1"), openai.ToolMessage("r2", "call_2")
+
+	tests := []struct {
+		name      string
+		history   []openai.ChatCompletionMessageParamUnion
+		keepTurns int
+		want      int
+	}{
+		{"plain turns", []openai.ChatCompletionMessageParamUnion{user, assistant, user, assistant, user, assistant}, 1, 4},
+		{"too short", []openai.ChatCompletionMessageParamUnion{user, assistant}, 2, 0},
+		{"cut before tool group", []openai.ChatCompletionMessageParamUnion{user, assistant, user, call1, result1, assistant}, 1, 3},
+		{"cut inside tool results", []openai.ChatCompletionMessageParamUnion{user, call1, result1, result2, assistant}, 1, 1},
+		{"two tool rounds", []openai.ChatCompletionMessageParamUnion{user, call1, result1, call2, result2, assistant}, 1, 3},
+		{"only tool group left", []openai.ChatCompletionMessageParamUnion{call1, result1, result2}, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, summaryCut(tt.history, tt.keepTurns))
+		})
+	}
+}
+
+func TestSummarizerElement_ToolCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"m",`+
+			`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"The user asked about an order."}}]}`)
+	}))
+	defer srv.Close()
+
+	chat, err := NewChatElement(ChatConfig{APIKey: "test-key"})
+	require.NoError(t, err)
+	summarizer, err := NewSummarizerElement(SummarizerConfig{Chat: chat, APIKey: "test-key", KeepTurns: 1})
+	require.NoError(t, err)
+	client := openai.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(srv.URL))
+	summarizer.client = &client
+	summarizer.SetBus(pipeline.NewEventBus())
+
+	chat.addToHistory(openai.UserMessage("Where is my order?"))
+	chat.addToHistory(toolCallMessage("call_1"))
+
+	// Not summarized while the tool call is waiting for its result
+	chat.mu.Lock()
+	chat.pendingTools["call_1"] = true
+	chat.mu.Unlock()
+	require.NoError(t, summarizer.Summarize(context.Background()))
+	assert.Empty(t, chat.GetSummary())
+	assert.Equal(t, 2, chat.GetHistoryLength())
+
+	chat.mu.Lock()
+	delete(chat.pendingTools, "call_1")
+	chat.mu.Unlock()
+	chat.addToHistory(openai.ToolMessage("shipped", "call_1"))
+	chat.addToHistory(openai.AssistantMessage("It has shipped."))
+
+	// The cut falls inside the tool group, so the tool call is kept with its result
+	require.NoError(t, summarizer.Summarize(context.Background()))
+	assert.Equal(t, "The user asked about an order.", chat.GetSummary())
+
+	history := chat.GetHistory()
+	require.Len(t, history, 3)
+	assert.Equal(t, ChatRoleAssistant, history[0].Role)
+	assert.Equal(t, ChatRoleTool, history[1].Role)
+	assert.Equal(t, "It has shipped.", history[2].Content)
+}
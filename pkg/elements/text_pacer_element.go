@@ -0,0 +1,327 @@
+// Package elements provides pipeline processing elements.
+//
+// TextPacerElement 按语音播放的节奏逐词输出文本，用于字幕/DataChannel 文本。
+// 整句一次性下发显得突兀，逐词输出可以实现“边说边打字”的效果。
+//
+// 主要功能:
+//   - 默认关闭，关闭时文本原样透传
+//   - 英文等按词输出（按 WordsPerMinute 计时），中日韩按字输出（按 CharsPerSecond 计时）
+//   - 文本消息的 Metadata 为 []pipeline.WordTiming 时按词时间戳输出
+//   - 非文本消息立即透传，不受文本节奏影响
+//   - 收到 EventInterrupted 时丢弃尚未输出的文本
+//
+// 使用示例:
+//
+//	pacer := NewTextPacerElementWithConfig(TextPacerConfig{
+//	    Enabled:        true,
+//	    WordsPerMinute: 160,
+//	})
+//	p.Link(translate, pacer)
+package elements
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure TextPacerElement implements pipeline.Element
+var _ pipeline.Element = (*TextPacerElement)(nil)
+
+// TextPacerConfig 文本节奏配置
+type TextPacerConfig struct {
+	Enabled        bool // 是否启用节奏控制（默认关闭，文本原样透传）
+	WordsPerMinute int  // 按词输出的语速（默认 150）
+	CharsPerSecond int  // 中日韩文字按字输出的语速（默认 4）
+
+	// Clock 节奏控制使用的时钟（默认 pipeline.SystemClock），测试时可注入 pipeline.ManualClock
+	Clock pipeline.Clock
+}
+
+// DefaultTextPacerConfig 返回默认配置
+func DefaultTextPacerConfig() TextPacerConfig {
+	return TextPacerConfig{
+		Enabled:        false,
+		WordsPerMinute: 150,
+		CharsPerSecond: 4,
+	}
+}
+
+// pacedToken 一个待输出的文本片段及其相对输出时间
+type pacedToken struct {
+	text   string
+	offset time.Duration
+}
+
+// TextPacerElement 按语音节奏输出文本
+type TextPacerElement struct {
+	*pipeline.BaseElement
+
+	config TextPacerConfig
+
+	textChan    chan *pipeline.PipelineMessage
+	interruptCh chan pipeline.Event
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTextPacerElement 创建 TextPacerElement（默认配置，需启用后才生效）
+func NewTextPacerElement() *TextPacerElement {
+	return NewTextPacerElementWithConfig(DefaultTextPacerConfig())
+}
+
+// NewTextPacerElementWithConfig 使用自定义配置创建 TextPacerElement
+func NewTextPacerElementWithConfig(cfg TextPacerConfig) *TextPacerElement {
+	if cfg.WordsPerMinute <= 0 {
+		cfg.WordsPerMinute = 150
+	}
+	if cfg.CharsPerSecond <= 0 {
+		cfg.CharsPerSecond = 4
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = pipeline.SystemClock
+	}
+
+	elem := &TextPacerElement{
+		BaseElement: pipeline.NewBaseElement("text-pacer-element", 100),
+		config:      cfg,
+		textChan:    make(chan *pipeline.PipelineMessage, 100),
+	}
+
+	elem.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "enabled",
+		Type:     reflect.TypeOf(false),
+		Writable: true,
+		Readable: true,
+		Default:  cfg.Enabled,
+	})
+
+	return elem
+}
+
+// SetProperty 设置属性，需在 Start 之前调用
+func (e *TextPacerElement) SetProperty(name string, value interface{}) error {
+	if err := e.BaseElement.SetProperty(name, value); err != nil {
+		return err
+	}
+	if name == "enabled" {
+		e.config.Enabled = value.(bool)
+	}
+	return nil
+}
+
+func (e *TextPacerElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	if e.Bus() != nil {
+		e.interruptCh = make(chan pipeline.Event, 10)
+		e.Bus().Subscribe(pipeline.EventInterrupted, e.interruptCh)
+	}
+
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		e.inputLoop(ctx)
+	}()
+	go func() {
+		defer e.wg.Done()
+		e.emitLoop(ctx)
+	}()
+
+	return nil
+}
+
+func (e *TextPacerElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	if e.interruptCh != nil {
+		e.Bus().Unsubscribe(pipeline.EventInterrupted, e.interruptCh)
+		e.interruptCh = nil
+	}
+	return nil
+}
+
+// inputLoop 把文本交给 emitLoop 按节奏输出，其他消息立即透传
+func (e *TextPacerElement) inputLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.BaseElement.InChan:
+			out := e.BaseElement.OutChan
+			if e.config.Enabled && msg.Type == pipeline.MsgTypeData && msg.TextData != nil {
+				out = e.textChan
+			}
+
+			select {
+			case out <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// emitLoop 逐条处理文本消息，按节奏输出其中的片段
+func (e *TextPacerElement) emitLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.interruptCh:
+			e.dropPending()
+		case msg := <-e.textChan:
+			if !e.emitPaced(ctx, msg) {
+				e.dropPending()
+			}
+		}
+	}
+}
+
+// emitPaced 按节奏输出一条文本消息，被打断或停止时返回 false
+func (e *TextPacerElement) emitPaced(ctx context.Context, msg *pipeline.PipelineMessage) bool {
+	clock := e.config.Clock
+	tokens := e.tokensFor(msg)
+	start := clock.Now()
+
+	for i, token := range tokens {
+		if wait := token.offset - clock.Now().Sub(start); wait > 0 {
+			select {
+			case <-clock.After(wait):
+			case <-e.interruptCh:
+				return false
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		textType := msg.TextData.TextType
+		if textType == "final" && i < len(tokens)-1 {
+			textType = "partial"
+		}
+
+		out := &pipeline.PipelineMessage{
+			Type:       pipeline.MsgTypeData,
+			SessionID:  msg.SessionID,
+			Attributes: msg.Attributes,
+			Timestamp:  clock.Now(),
+			TextData: &pipeline.TextData{
+				Data:      []byte(token.text),
+				TextType:  textType,
+				Timestamp: clock.Now(),
+			},
+		}
+
+		select {
+		case e.BaseElement.OutChan <- out:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// dropPending 丢弃所有尚未输出的文本
+func (e *TextPacerElement) dropPending() {
+	for {
+		select {
+		case <-e.textChan:
+		default:
+			return
+		}
+	}
+}
+
+// tokensFor 把文本消息拆分为带输出时间的片段
+func (e *TextPacerElement) tokensFor(msg *pipeline.PipelineMessage) []pacedToken {
+	// 优先使用词时间戳
+	if timings, ok := msg.Metadata.([]pipeline.WordTiming); ok && len(timings) > 0 {
+		tokens := make([]pacedToken, 0, len(timings))
+		for i, t := range timings {
+			text := t.Word
+			if i < len(timings)-1 && !containsCJK(text) && !strings.HasSuffix(text, " ") {
+				text += " "
+			}
+			tokens = append(tokens, pacedToken{text: text, offset: t.Start})
+		}
+		return tokens
+	}
+
+	wordDelay := time.Minute / time.Duration(e.config.WordsPerMinute)
+	charDelay := time.Second / time.Duration(e.config.CharsPerSecond)
+
+	var tokens []pacedToken
+	var offset time.Duration
+	for _, text := range splitPacedTokens(string(msg.TextData.Data)) {
+		tokens = append(tokens, pacedToken{text: text, offset: offset})
+		if containsCJK(text) {
+			offset += charDelay
+		} else {
+			offset += wordDelay
+		}
+	}
+	return tokens
+}
+
+// splitPacedTokens 把文本拆分为逐词（中日韩逐字）输出的片段
+// 空白和标点附着在前一个片段上，拼接所有片段即得到原文
+func splitPacedTokens(text string) []string {
+	var tokens []string
+	var cur strings.Builder
+	boundary := false   // 下一个文字是否开始新片段
+	afterSpace := false // 上一个字符是否为空白
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			cur.WriteRune(r)
+			boundary = true
+			afterSpace = true
+			continue
+		case unicode.IsPunct(r) && !afterSpace:
+			// 标点跟随前一个词
+		default:
+			if boundary || isCJKRune(r) {
+				flush()
+			}
+			boundary = isCJKRune(r)
+		}
+		cur.WriteRune(r)
+		afterSpace = false
+	}
+	flush()
+
+	return tokens
+}
+
+// isCJKRune 判断是否为中日韩文字
+func isCJKRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// containsCJK 判断文本是否包含中日韩文字
+func containsCJK(text string) bool {
+	for _, r := range text {
+		if isCJKRune(r) {
+			return true
+		}
+	}
+	return false
+}
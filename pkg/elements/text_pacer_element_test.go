@@ -0,0 +1,147 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textMessage(text, textType string) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type:     pipeline.MsgTypeData,
+		TextData: &pipeline.TextData{Data: []byte(text), TextType: textType},
+	}
+}
+
+func receiveText(t *testing.T, elem *TextPacerElement) *pipeline.PipelineMessage {
+	t.Helper()
+	select {
+	case msg := <-elem.Out():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for output")
+		return nil
+	}
+}
+
+func TestSplitPacedTokens(t *testing.T) {
+	assert.Equal(t, []string{"Hello, ", "world. ", "How ", "are ", "you?"}, splitPacedTokens("Hello, world. How are you?"))
+	assert.Equal(t, []string{"你", "好。", "今", "天"}, splitPacedTokens("你好。今天"))
+	assert.Equal(t, []string{"Say ", "(quietly) ", "hi"}, splitPacedTokens("Say (quietly) hi"))
+}
+
+func TestTextPacerElement_Disabled(t *testing.T) {
+	elem := NewTextPacerElement()
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage("one two three", "final")
+	out := receiveText(t, elem)
+	assert.Equal(t, "one two three", string(out.TextData.Data))
+}
+
+func TestTextPacerElement_PacesWords(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := pipeline.NewManualClock(start)
+	elem := NewTextPacerElementWithConfig(TextPacerConfig{
+		Enabled:        true,
+		WordsPerMinute: 600, // 100ms per word
+		Clock:          clock,
+	})
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage("one two three", "final")
+	out := receiveText(t, elem)
+	assert.Equal(t, "one ", string(out.TextData.Data))
+	assert.Equal(t, "partial", out.TextData.TextType)
+
+	// 音频不等待文本节奏
+	clock.BlockUntil(1)
+	elem.In() <- &pipeline.PipelineMessage{Type: pipeline.MsgTypeAudio, AudioData: &pipeline.AudioData{}}
+	assert.Equal(t, pipeline.MsgTypeAudio, receiveText(t, elem).Type)
+
+	// 时间未到不输出下一个词
+	clock.Advance(99 * time.Millisecond)
+	assert.Empty(t, elem.Out())
+
+	clock.Advance(time.Millisecond)
+	out = receiveText(t, elem)
+	assert.Equal(t, "two ", string(out.TextData.Data))
+	assert.Equal(t, "partial", out.TextData.TextType)
+	assert.Equal(t, start.Add(100*time.Millisecond), out.Timestamp)
+
+	clock.BlockUntil(1)
+	clock.Advance(100 * time.Millisecond)
+	out = receiveText(t, elem)
+	assert.Equal(t, "three", string(out.TextData.Data))
+	assert.Equal(t, "final", out.TextData.TextType)
+	assert.Equal(t, start.Add(200*time.Millisecond), out.Timestamp)
+}
+
+func TestTextPacerElement_PreservesAttributes(t *testing.T) {
+	elem := NewTextPacerElementWithConfig(TextPacerConfig{
+		Enabled:        true,
+		WordsPerMinute: 6000,
+	})
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	msg := textMessage("one two", "final")
+	msg.SetAttribute(pipeline.AttrRequestID, "req_1")
+	elem.In() <- msg
+
+	for i := 0; i < 2; i++ {
+		out := receiveText(t, elem)
+		assert.Equal(t, "req_1", out.Attributes.String(pipeline.AttrRequestID))
+	}
+}
+
+func TestTextPacerElement_WordTimings(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := pipeline.NewManualClock(start)
+	elem := NewTextPacerElementWithConfig(TextPacerConfig{Enabled: true, Clock: clock})
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	msg := textMessage("hello world", "partial")
+	msg.Metadata = []pipeline.WordTiming{
+		{Word: "hello", Start: 0},
+		{Word: "world", Start: 150 * time.Millisecond},
+	}
+
+	elem.In() <- msg
+	assert.Equal(t, "hello ", string(receiveText(t, elem).TextData.Data))
+
+	clock.BlockUntil(1)
+	clock.Advance(150 * time.Millisecond)
+	out := receiveText(t, elem)
+	assert.Equal(t, "world", string(out.TextData.Data))
+	assert.Equal(t, start.Add(150*time.Millisecond), out.Timestamp)
+}
+
+func TestTextPacerElement_Interrupt(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	elem := NewTextPacerElementWithConfig(TextPacerConfig{
+		Enabled:        true,
+		WordsPerMinute: 60, // 1s per word
+	})
+	elem.SetBus(bus)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage("one two three", "final")
+	assert.Equal(t, "one ", string(receiveText(t, elem).TextData.Data))
+
+	bus.Publish(pipeline.Event{Type: pipeline.EventInterrupted, Timestamp: time.Now()})
+
+	select {
+	case msg := <-elem.Out():
+		t.Fatalf("unexpected output after interrupt: %q", msg.TextData.Data)
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,61 @@
+// Package elements provides pipeline processing elements.
+//
+// 函数调用结果的编码辅助函数，供实现 pipeline.ToolResultSender 的元素共用。
+// 应用收到 EventToolCall 后执行工具，SendToolResult 传入的结果可以是任意值，
+// 这里按各服务商的要求转换：OpenAI（Chat、Realtime）接受字符串，
+// Gemini Live 只接受 JSON 对象。
+package elements
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// toolResultString 把工具结果编码为字符串：string 原样返回，其他值编码为 JSON
+func toolResultString(result any) (string, error) {
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("marshal tool result: %w", err)
+	}
+	return string(data), nil
+}
+
+// toolResultMap 把工具结果转换为 JSON 对象（Gemini 的 FunctionResponse 只接受对象）
+// map 和 JSON 对象字符串直接使用，其他值包装为 {"output": result}
+func toolResultMap(result any) (map[string]any, error) {
+	switch v := result.(type) {
+	case map[string]any:
+		return v, nil
+	case string:
+		var m map[string]any
+		if json.Unmarshal([]byte(v), &m) == nil && m != nil {
+			return m, nil
+		}
+		return map[string]any{"output": v}, nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal tool result: %w", err)
+	}
+	var m map[string]any
+	if json.Unmarshal(data, &m) == nil && m != nil {
+		return m, nil
+	}
+	return map[string]any{"output": result}, nil
+}
+
+// toolCallArguments 把函数参数编码为 JSON 字符串
+func toolCallArguments(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,50 @@
+package elements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolResultString(t *testing.T) {
+	s, err := toolResultString("sunny, 22C")
+	require.NoError(t, err)
+	assert.Equal(t, "sunny, 22C", s)
+
+	s, err = toolResultString(map[string]any{"temp": 22})
+	require.NoError(t, err)
+	assert.Equal(t, `{"temp":22}`, s)
+
+	_, err = toolResultString(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestToolResultMap(t *testing.T) {
+	m, err := toolResultMap(map[string]any{"temp": 22})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"temp": 22}, m)
+
+	m, err = toolResultMap(`{"temp": 22}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"temp": float64(22)}, m)
+
+	m, err = toolResultMap("sunny")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"output": "sunny"}, m)
+
+	m, err = toolResultMap(struct {
+		Temp int `json:"temp"`
+	}{22})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"temp": float64(22)}, m)
+
+	m, err = toolResultMap(42)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"output": 42}, m)
+}
+
+func TestToolCallArguments(t *testing.T) {
+	assert.Equal(t, "{}", toolCallArguments(nil))
+	assert.Equal(t, `{"city":"Paris"}`, toolCallArguments(map[string]any{"city": "Paris"}))
+}
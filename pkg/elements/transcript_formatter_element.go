@@ -0,0 +1,211 @@
+// Package elements provides pipeline processing elements.
+//
+// TranscriptFormatterElement 清理 STT 转写文本，用于字幕展示。
+// 部分 STT 提供商返回全小写、无标点的文本，直接显示效果较差。
+//
+// 主要功能:
+//   - 句首字母大写，单独的 "i"（含 i'm、i'll 等）改为大写
+//   - 最终结果末尾缺少标点时补全：疑问句补问号，其余补句号（中文使用全角标点）
+//   - 中间结果只做大写和空白整理，不补标点，避免字幕随识别过程闪烁
+//   - 只处理 text/partial 和 text/final 文本，其他消息原样透传
+//
+// 全部为轻量规则，不依赖外部服务，可用于实时的中间结果。
+//
+// 使用示例:
+//
+//	formatter := NewTranscriptFormatterElement(DefaultFormatterConfig())
+//	p.Link(stt, formatter)
+//	p.Link(formatter, sink)
+package elements
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure TranscriptFormatterElement implements pipeline.Element
+var _ pipeline.Element = (*TranscriptFormatterElement)(nil)
+
+// FormatterConfig 转写文本格式化配置
+type FormatterConfig struct {
+	Capitalize     bool // 句首和单独的 "i" 大写
+	AddPunctuation bool // 最终结果末尾补全标点
+}
+
+// DefaultFormatterConfig 返回默认配置（启用所有规则）
+func DefaultFormatterConfig() FormatterConfig {
+	return FormatterConfig{
+		Capitalize:     true,
+		AddPunctuation: true,
+	}
+}
+
+// TranscriptFormatterElement 格式化 STT 的中间和最终结果
+type TranscriptFormatterElement struct {
+	*pipeline.BaseElement
+
+	config FormatterConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTranscriptFormatterElement 创建 TranscriptFormatterElement
+func NewTranscriptFormatterElement(cfg FormatterConfig) *TranscriptFormatterElement {
+	return &TranscriptFormatterElement{
+		BaseElement: pipeline.NewBaseElement("transcript-formatter-element", 100),
+		config:      cfg,
+	}
+}
+
+func (e *TranscriptFormatterElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.process(ctx)
+	}()
+
+	return nil
+}
+
+func (e *TranscriptFormatterElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+func (e *TranscriptFormatterElement) process(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.BaseElement.InChan:
+			if msg.Type == pipeline.MsgTypeData && msg.TextData != nil && len(msg.TextData.Data) > 0 {
+				switch msg.TextData.TextType {
+				case "text/partial", "text/final":
+					final := msg.TextData.TextType == "text/final"
+					textData := *msg.TextData
+					textData.Data = []byte(formatTranscript(string(msg.TextData.Data), e.config, final))
+					out := *msg
+					out.TextData = &textData
+					msg = &out
+				}
+			}
+
+			select {
+			case e.BaseElement.OutChan <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+var (
+	transcriptSpaceRe    = regexp.MustCompile(`\s+`)
+	transcriptSpacePunct = regexp.MustCompile(`\s+([,.!?;:])`)
+	transcriptPronounRe  = regexp.MustCompile(`\bi('(?:m|ll|ve|d))?(\s|[,!?;:]|\.(?:\s|$)|$)`)
+)
+
+// 英文疑问句常见的开头词
+var questionStarters = map[string]bool{
+	"what": true, "why": true, "how": true, "who": true, "whom": true, "whose": true,
+	"where": true, "when": true, "which": true,
+	"is": true, "are": true, "am": true, "was": true, "were": true,
+	"do": true, "does": true, "did": true,
+	"can": true, "could": true, "would": true, "will": true, "should": true, "shall": true,
+	"may": true, "might": true, "have": true, "has": true, "had": true,
+	"isn't": true, "aren't": true, "don't": true, "doesn't": true, "didn't": true,
+	"can't": true, "won't": true, "wouldn't": true, "shouldn't": true,
+}
+
+// formatTranscript 按配置格式化一段转写文本，final 为 false 时不补标点
+func formatTranscript(text string, cfg FormatterConfig, final bool) string {
+	text = strings.TrimSpace(transcriptSpaceRe.ReplaceAllString(text, " "))
+	if text == "" {
+		return text
+	}
+	text = transcriptSpacePunct.ReplaceAllString(text, "$1")
+
+	if cfg.Capitalize {
+		text = capitalizeTranscript(text)
+	}
+	if cfg.AddPunctuation && final {
+		text = addEndPunctuation(text)
+	}
+	return text
+}
+
+// capitalizeTranscript 句首字母和单独的 "i" 大写
+func capitalizeTranscript(text string) string {
+	text = transcriptPronounRe.ReplaceAllString(text, "I$1$2")
+
+	var b strings.Builder
+	b.Grow(len(text))
+	sentenceStart := true
+	afterEnd := false // 刚遇到英文句末标点，后面跟空白才算新句子（避免 "3.5" 被当成两句）
+	wordStart := 0    // 当前词在 text 中的起始位置
+	for i, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			// "e.g." "i.e." 这类缩写中间带点，不算句末
+			if afterEnd && !strings.Contains(strings.TrimRight(text[wordStart:i], ".!?"), ".") {
+				sentenceStart = true
+			}
+			afterEnd = false
+			wordStart = i + utf8.RuneLen(r)
+		case r == '.' || r == '!' || r == '?':
+			afterEnd = true
+		case r == '。' || r == '！' || r == '？':
+			sentenceStart = true
+		case sentenceStart && unicode.IsLetter(r):
+			r = unicode.ToUpper(r)
+			sentenceStart = false
+		case unicode.IsDigit(r):
+			sentenceStart = false
+			afterEnd = false
+		default:
+			afterEnd = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// addEndPunctuation 末尾缺少标点时补全句号或问号
+func addEndPunctuation(text string) string {
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if unicode.IsPunct(last) {
+		return text
+	}
+
+	if unicode.Is(unicode.Han, last) {
+		if strings.HasSuffix(text, "吗") || strings.HasSuffix(text, "呢") {
+			return text + "？"
+		}
+		return text + "。"
+	}
+
+	// 只看最后一句的第一个词
+	sentence := text
+	if i := strings.LastIndexAny(text, ".!?"); i >= 0 {
+		sentence = text[i+1:]
+	}
+	fields := strings.Fields(sentence)
+	if len(fields) > 0 && questionStarters[strings.ToLower(strings.Trim(fields[0], ",;:\"'"))] {
+		return text + "?"
+	}
+	return text + "."
+}
@@ -0,0 +1,101 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptFormatter_Capitalization(t *testing.T) {
+	cfg := FormatterConfig{Capitalize: true}
+
+	cases := map[string]string{
+		"hello world":                     "Hello world",
+		"hello there. how are you":        "Hello there. How are you",
+		"  so   i think i'm ready ":       "So I think I'm ready",
+		"yes! great? ok":                  "Yes! Great? Ok",
+		"i.e. the file":                   "I.e. the file",
+		"it costs 3.50 now":               "It costs 3.50 now",
+		"use e.g. python":                 "Use e.g. python",
+		"what do i do , said i.":          "What do I do, said I.",
+		"42 is the answer. it really is.": "42 is the answer. It really is.",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, formatTranscript(in, cfg, true), in)
+	}
+}
+
+func TestTranscriptFormatter_EndPunctuation(t *testing.T) {
+	cfg := FormatterConfig{AddPunctuation: true}
+
+	cases := map[string]string{
+		"hello world":                "hello world.",
+		"what time is it":            "what time is it?",
+		"Can you help me":            "Can you help me?",
+		"that's fine. are you there": "that's fine. are you there?",
+		"already done.":              "already done.",
+		"really?":                    "really?",
+		"今天天气不错":                     "今天天气不错。",
+		"你吃饭了吗":                      "你吃饭了吗？",
+		"好的。":                        "好的。",
+		"":                           "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, formatTranscript(in, cfg, true), in)
+	}
+}
+
+func TestTranscriptFormatter_PartialsNotPunctuated(t *testing.T) {
+	cfg := DefaultFormatterConfig()
+
+	assert.Equal(t, "What time", formatTranscript("what time", cfg, false))
+	assert.Equal(t, "What time is it?", formatTranscript("what time is it", cfg, true))
+}
+
+func TestTranscriptFormatter_Disabled(t *testing.T) {
+	assert.Equal(t, "hello world", formatTranscript("hello  world", FormatterConfig{}, true))
+}
+
+func TestTranscriptFormatterElement(t *testing.T) {
+	e := NewTranscriptFormatterElement(DefaultFormatterConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, e.Start(ctx))
+	defer e.Stop()
+
+	send := func(text, textType string) *pipeline.PipelineMessage {
+		in := &pipeline.PipelineMessage{
+			Type:     pipeline.MsgTypeData,
+			TextData: &pipeline.TextData{Data: []byte(text), TextType: textType},
+		}
+		e.In() <- in
+		select {
+		case out := <-e.Out():
+			// The input message must not be modified in place
+			assert.Equal(t, text, string(in.TextData.Data))
+			return out
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for output")
+			return nil
+		}
+	}
+
+	assert.Equal(t, "How are", string(send("how are", "text/partial").TextData.Data))
+	assert.Equal(t, "How are you?", string(send("how are you", "text/final").TextData.Data))
+	// Non-transcript text passes through untouched
+	assert.Equal(t, "hello world", string(send("hello world", "text/plain").TextData.Data))
+
+	audio := &pipeline.PipelineMessage{Type: pipeline.MsgTypeAudio, AudioData: &pipeline.AudioData{Data: []byte{1, 2}}}
+	e.In() <- audio
+	select {
+	case out := <-e.Out():
+		assert.Same(t, audio, out)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for audio passthrough")
+	}
+}
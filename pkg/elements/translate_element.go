@@ -13,6 +13,7 @@ import (
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/shared"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 	"google.golang.org/genai"
 )
 
@@ -23,11 +24,15 @@ var _ pipeline.Element = (*TranslateElement)(nil)
 type TranslateConfig struct {
 	Provider     string // "openai" or "gemini"
 	APIKey       string
-	SourceLang   string // "auto", "zh", "en", "ja", etc.
-	TargetLang   string // "en", "zh", "ja", etc.
+	SourceLang   string // "auto", "zh", "en", "ja", etc. (default: pipeline LanguageContext, then "auto")
+	TargetLang   string // "en", "zh", "ja", etc. (default: pipeline LanguageContext)
 	Model        string // "gpt-4o-mini", "gemini-2.0-flash-exp"
 	SystemPrompt string // Custom translation prompt
 	Streaming    bool   // Enable streaming translation
+
+	// Headers are extra headers sent on every API request (API gateway keys,
+	// org IDs, tracing headers)
+	Headers map[string]string
 }
 
 // TranslateElement translates text from one language to another
@@ -35,6 +40,7 @@ type TranslateElement struct {
 	*pipeline.BaseElement
 
 	config        TranslateConfig
+	customPrompt  bool
 	openaiClient  *openai.Client
 	geminiClient  *genai.Client
 	geminiSession *genai.Session
@@ -58,19 +64,44 @@ func NewTranslateElement(config TranslateConfig) (*TranslateElement, error) {
 	if config.SourceLang == "" {
 		config.SourceLang = "auto"
 	}
-	if config.TargetLang == "" {
-		return nil, fmt.Errorf("target language is required")
-	}
-	if config.SystemPrompt == "" {
-		config.SystemPrompt = buildDefaultPrompt(config.SourceLang, config.TargetLang)
-	}
 
 	return &TranslateElement{
-		BaseElement: pipeline.NewBaseElement("translate-element", 100),
-		config:      config,
+		BaseElement:  pipeline.NewBaseElement("translate-element", 100),
+		config:       config,
+		customPrompt: config.SystemPrompt != "",
 	}, nil
 }
 
+// sourceLang returns the configured source language, falling back to the
+// pipeline LanguageContext (including the language detected by STT)
+func (e *TranslateElement) sourceLang() string {
+	if e.config.SourceLang != "auto" {
+		return e.config.SourceLang
+	}
+	if lang := e.LanguageContext().EffectiveSource(); lang != "" {
+		return lang
+	}
+	return "auto"
+}
+
+// targetLang returns the configured target language, falling back to the
+// pipeline LanguageContext
+func (e *TranslateElement) targetLang() string {
+	if e.config.TargetLang != "" {
+		return e.config.TargetLang
+	}
+	return e.LanguageContext().Target()
+}
+
+// systemPrompt returns the custom prompt, or the default prompt for the
+// languages currently in effect
+func (e *TranslateElement) systemPrompt() string {
+	if e.customPrompt {
+		return e.config.SystemPrompt
+	}
+	return buildDefaultPrompt(e.sourceLang(), e.targetLang())
+}
+
 // buildDefaultPrompt creates a default translation prompt
 func buildDefaultPrompt(sourceLang, targetLang string) string {
 	sourceLangName := getLanguageName(sourceLang)
@@ -108,6 +139,10 @@ func (e *TranslateElement) Start(ctx context.Context) error {
 		return fmt.Errorf("API key is required")
 	}
 
+	if e.targetLang() == "" {
+		return fmt.Errorf("target language is required")
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	e.cancel = cancel
 
@@ -120,12 +155,16 @@ func (e *TranslateElement) Start(ctx context.Context) error {
 		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
 			opts = append(opts, option.WithBaseURL(baseURL))
 		}
+		for key, value := range e.config.Headers {
+			opts = append(opts, option.WithHeader(key, value))
+		}
 		client := openai.NewClient(opts...)
 		e.openaiClient = &client
 	} else if e.config.Provider == "gemini" {
 		e.geminiClient, err = genai.NewClient(ctx, &genai.ClientConfig{
-			APIKey:  e.config.APIKey,
-			Backend: genai.BackendGoogleAI,
+			APIKey:     e.config.APIKey,
+			Backend:    genai.BackendGoogleAI,
+			HTTPClient: utils.NewHeaderClient(e.config.Headers),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create Gemini client: %v", err)
@@ -164,7 +203,9 @@ func (e *TranslateElement) Start(ctx context.Context) error {
 					if translated != "" {
 						// Send translated text to output
 						outMsg := &pipeline.PipelineMessage{
-							Type: pipeline.MsgTypeData,
+							Type:       pipeline.MsgTypeData,
+							SessionID:  msg.SessionID,
+							Attributes: msg.Attributes,
 							TextData: &pipeline.TextData{
 								Data:      []byte(translated),
 								TextType:  msg.TextData.TextType, // Preserve text type (partial/final)
@@ -175,9 +216,10 @@ func (e *TranslateElement) Start(ctx context.Context) error {
 
 						// Publish translation event
 						e.BaseElement.Bus().Publish(pipeline.Event{
-							Type:      pipeline.EventFinalResult,
-							Timestamp: time.Now(),
-							Payload:   translated,
+							Type:       pipeline.EventFinalResult,
+							Timestamp:  time.Now(),
+							Payload:    translated,
+							Attributes: msg.Attributes,
 						})
 					}
 				} else {
@@ -189,7 +231,7 @@ func (e *TranslateElement) Start(ctx context.Context) error {
 	}()
 
 	log.Printf("TranslateElement started (provider: %s, model: %s, %s -> %s)",
-		e.config.Provider, e.config.Model, e.config.SourceLang, e.config.TargetLang)
+		e.config.Provider, e.config.Model, e.sourceLang(), e.targetLang())
 	return nil
 }
 
@@ -211,7 +253,7 @@ func (e *TranslateElement) translateWithOpenAI(ctx context.Context, text string)
 
 	params := openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(e.config.SystemPrompt),
+			openai.SystemMessage(e.systemPrompt()),
 			openai.UserMessage(text),
 		},
 		Model: shared.ChatModel(e.config.Model),
@@ -233,7 +275,7 @@ func (e *TranslateElement) translateWithOpenAI(ctx context.Context, text string)
 func (e *TranslateElement) translateWithOpenAIStreaming(ctx context.Context, text string) (string, error) {
 	params := openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(e.config.SystemPrompt),
+			openai.SystemMessage(e.systemPrompt()),
 			openai.UserMessage(text),
 		},
 		Model: shared.ChatModel(e.config.Model),
@@ -331,13 +373,10 @@ func (e *TranslateElement) Stop() error {
 }
 
 func (e *TranslateElement) geminiRequestConfig() *genai.GenerateContentConfig {
-	if e.config.SystemPrompt == "" {
-		return nil
-	}
 	return &genai.GenerateContentConfig{
 		SystemInstruction: &genai.Content{
 			Parts: []*genai.Part{
-				{Text: e.config.SystemPrompt},
+				{Text: e.systemPrompt()},
 			},
 		},
 	}
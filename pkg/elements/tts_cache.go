@@ -0,0 +1,98 @@
+// Package elements provides pipeline processing elements.
+//
+// ttsCache is the phrase cache behind UniversalTTSElement.SetCacheSize.
+// Repeated phrases (greetings, "One moment, please.") are played from
+// memory instead of being synthesized again, which saves the provider round
+// trip and its cost. Entries are keyed by provider, text, voice, language and
+// speed, and the least recently used phrase is evicted when the cache is full.
+//
+// Usage:
+//
+//	ttsElem := elements.NewUniversalTTSElement(provider)
+//	ttsElem.SetCacheSize(100)
+package elements
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/realtime-ai/realtime-ai/pkg/tts"
+)
+
+// ttsCacheKey identifies a synthesis result. Requests that differ in any of
+// these fields produce different audio and are cached separately.
+type ttsCacheKey struct {
+	provider string
+	text     string
+	voice    string
+	language string
+	speed    float64
+}
+
+type ttsCacheEntry struct {
+	key  ttsCacheKey
+	resp tts.SynthesizeResponse
+}
+
+// ttsCache is an LRU cache of synthesized audio, safe for concurrent use
+type ttsCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front = most recently used
+	entries map[ttsCacheKey]*list.Element
+}
+
+func newTTSCache(size int) *ttsCache {
+	return &ttsCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[ttsCacheKey]*list.Element),
+	}
+}
+
+// get returns a copy of the cached response for key
+func (c *ttsCache) get(key ttsCacheKey) (*tts.SynthesizeResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return copyTTSResponse(&el.Value.(*ttsCacheEntry).resp), true
+}
+
+// put stores a copy of resp, evicting the least recently used entry when full
+func (c *ttsCache) put(key ttsCacheKey, resp *tts.SynthesizeResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*ttsCacheEntry).resp = *copyTTSResponse(resp)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&ttsCacheEntry{key: key, resp: *copyTTSResponse(resp)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*ttsCacheEntry).key)
+	}
+}
+
+// len returns the number of cached entries
+func (c *ttsCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// copyTTSResponse copies the audio, which is modified in place downstream
+// (crossfade, playback rate)
+func copyTTSResponse(resp *tts.SynthesizeResponse) *tts.SynthesizeResponse {
+	cp := *resp
+	cp.AudioData = append([]byte(nil), resp.AudioData...)
+	return &cp
+}
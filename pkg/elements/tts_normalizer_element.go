@@ -0,0 +1,521 @@
+// Package elements provides pipeline processing elements.
+//
+// TTSNormalizerElement 在文本送入 TTS 之前，把数字、货币、日期、缩写等
+// 展开为口语形式。LLM 输出的 "$9.99"、"2024-01-20" 直接交给 TTS 时读法很别扭。
+//
+// 主要功能:
+//   - 英文：数字、货币、百分比、序数词、ISO 日期、时间读成单词，常见缩写展开
+//   - 中文：货币、百分比、ISO 日期、时间改写为中文读法（数字本身交给 TTS 读）
+//   - 语言未显式配置时跟随 Pipeline LanguageContext 的输出语言，不支持的语言原样透传
+//   - 非文本消息原样透传
+//
+// 数字可能被流式输出拆开（如 "$9." 和 "99"），建议放在分句元素之后。
+//
+// 使用示例:
+//
+//	normalizer := NewTTSNormalizerElement()
+//	p.Link(segmenter, normalizer)
+//	p.Link(normalizer, tts)
+package elements
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Make sure TTSNormalizerElement implements pipeline.Element
+var _ pipeline.Element = (*TTSNormalizerElement)(nil)
+
+// TTSNormalizerConfig 文本规范化配置
+// 零值不做任何转换，通常从 DefaultTTSNormalizerConfig 开始修改
+type TTSNormalizerConfig struct {
+	Language      string // 文本语言，"" 时跟随 LanguageContext 的输出语言，仍为空按英文处理
+	Numbers       bool   // 数字、小数、序数词
+	Currency      bool   // 货币金额（$ € £ ¥）
+	Percentages   bool   // 百分比
+	Dates         bool   // ISO 日期（2024-01-20）和时间（14:30）
+	Abbreviations bool   // 常见缩写（Dr. e.g. etc.）
+
+	// ExtraAbbreviations 额外的缩写展开规则，覆盖内置规则（仅英文）
+	ExtraAbbreviations map[string]string
+}
+
+// DefaultTTSNormalizerConfig 返回默认配置（启用所有规则）
+func DefaultTTSNormalizerConfig() TTSNormalizerConfig {
+	return TTSNormalizerConfig{
+		Numbers:       true,
+		Currency:      true,
+		Percentages:   true,
+		Dates:         true,
+		Abbreviations: true,
+	}
+}
+
+// TTSNormalizerElement 把文本规范化为口语形式
+type TTSNormalizerElement struct {
+	*pipeline.BaseElement
+
+	config     TTSNormalizerConfig
+	normalizer *textNormalizer
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTTSNormalizerElement 使用默认配置创建 TTSNormalizerElement
+func NewTTSNormalizerElement() *TTSNormalizerElement {
+	return NewTTSNormalizerElementWithConfig(DefaultTTSNormalizerConfig())
+}
+
+// NewTTSNormalizerElementWithConfig 使用自定义配置创建 TTSNormalizerElement
+func NewTTSNormalizerElementWithConfig(cfg TTSNormalizerConfig) *TTSNormalizerElement {
+	elem := &TTSNormalizerElement{
+		BaseElement: pipeline.NewBaseElement("tts-normalizer-element", 100),
+		config:      cfg,
+		normalizer:  newTextNormalizer(cfg),
+	}
+
+	elem.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "language",
+		Type:     reflect.TypeOf(""),
+		Writable: true,
+		Readable: true,
+		Default:  cfg.Language,
+	})
+
+	return elem
+}
+
+// SetProperty 设置属性，需在 Start 之前调用
+func (e *TTSNormalizerElement) SetProperty(name string, value interface{}) error {
+	if err := e.BaseElement.SetProperty(name, value); err != nil {
+		return err
+	}
+	if name == "language" {
+		e.config.Language = value.(string)
+	}
+	return nil
+}
+
+func (e *TTSNormalizerElement) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.process(ctx)
+	}()
+
+	return nil
+}
+
+func (e *TTSNormalizerElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+func (e *TTSNormalizerElement) process(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.BaseElement.InChan:
+			if msg.Type == pipeline.MsgTypeData && msg.TextData != nil && len(msg.TextData.Data) > 0 {
+				text := e.normalizer.normalize(string(msg.TextData.Data), e.language())
+				textData := *msg.TextData
+				textData.Data = []byte(text)
+				out := *msg
+				out.TextData = &textData
+				msg = &out
+			}
+
+			select {
+			case e.BaseElement.OutChan <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// language 返回显式配置的语言，其次为 Pipeline 的输出语言，默认英文
+func (e *TTSNormalizerElement) language() string {
+	if e.config.Language != "" {
+		return e.config.Language
+	}
+	if lang := e.LanguageContext().OutputLanguage(); lang != "" {
+		return lang
+	}
+	return "en"
+}
+
+var (
+	isoDateRe    = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	clockTimeRe  = regexp.MustCompile(`\b([01]?\d|2[0-3]):([0-5]\d)\b`)
+	currencyRe   = regexp.MustCompile(`([$€£¥￥])\s?(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d+))?(?:\s(thousand|million|billion|trillion)\b)?`)
+	percentRe    = regexp.MustCompile(`(\d+(?:\.\d+)?)\s?%`)
+	ordinalRe    = regexp.MustCompile(`(?i)\b(\d+)(st|nd|rd|th)\b`)
+	numberSignRe = regexp.MustCompile(`\bNo\.\s?(\d)`)
+	numberRe     = regexp.MustCompile(`\b\d{1,3}(?:,\d{3})+(?:\.\d+)?\b|\b\d+(?:\.\d+)?\b`)
+)
+
+// 内置英文缩写
+var englishAbbreviations = map[string]string{
+	"Mr.":     "Mister",
+	"Mrs.":    "Missus",
+	"Ms.":     "Miz",
+	"Dr.":     "Doctor",
+	"Prof.":   "Professor",
+	"Jr.":     "Junior",
+	"Sr.":     "Senior",
+	"e.g.":    "for example",
+	"i.e.":    "that is",
+	"etc.":    "et cetera",
+	"vs.":     "versus",
+	"approx.": "approximately",
+}
+
+// textNormalizer 按配置预编译的规范化规则
+type textNormalizer struct {
+	config        TTSNormalizerConfig
+	abbreviations map[string]string
+	abbrevRe      *regexp.Regexp
+}
+
+func newTextNormalizer(cfg TTSNormalizerConfig) *textNormalizer {
+	n := &textNormalizer{
+		config:        cfg,
+		abbreviations: make(map[string]string, len(englishAbbreviations)+len(cfg.ExtraAbbreviations)),
+	}
+	for k, v := range englishAbbreviations {
+		n.abbreviations[k] = v
+	}
+	for k, v := range cfg.ExtraAbbreviations {
+		n.abbreviations[k] = v
+	}
+
+	// 长的缩写优先匹配
+	keys := make([]string, 0, len(n.abbreviations))
+	for k := range n.abbreviations {
+		keys = append(keys, regexp.QuoteMeta(k))
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	if len(keys) > 0 {
+		n.abbrevRe = regexp.MustCompile(`(^|[^\w.])(` + strings.Join(keys, "|") + `)`)
+	}
+	return n
+}
+
+// normalize 按语言规范化文本，不支持的语言原样返回
+func (n *textNormalizer) normalize(text, language string) string {
+	switch baseLanguage(language) {
+	case "en":
+		return n.normalizeEnglish(text)
+	case "zh":
+		return n.normalizeChinese(text)
+	default:
+		return text
+	}
+}
+
+func (n *textNormalizer) normalizeEnglish(text string) string {
+	cfg := n.config
+	if cfg.Dates {
+		text = isoDateRe.ReplaceAllStringFunc(text, func(s string) string {
+			m := isoDateRe.FindStringSubmatch(s)
+			year, _ := strconv.Atoi(m[1])
+			month, _ := strconv.Atoi(m[2])
+			day, _ := strconv.Atoi(m[3])
+			if month < 1 || month > 12 || day < 1 || day > 31 {
+				return s
+			}
+			return fmt.Sprintf("%s %s, %s", englishMonths[month-1], ordinalWords(int64(day)), yearWords(year))
+		})
+		text = clockTimeRe.ReplaceAllStringFunc(text, func(s string) string {
+			m := clockTimeRe.FindStringSubmatch(s)
+			hour, _ := strconv.Atoi(m[1])
+			minute, _ := strconv.Atoi(m[2])
+			switch {
+			case minute == 0:
+				return numberWords(int64(hour)) + " o'clock"
+			case minute < 10:
+				return numberWords(int64(hour)) + " oh " + numberWords(int64(minute))
+			default:
+				return numberWords(int64(hour)) + " " + numberWords(int64(minute))
+			}
+		})
+	}
+	if cfg.Currency {
+		text = currencyRe.ReplaceAllStringFunc(text, func(s string) string {
+			m := currencyRe.FindStringSubmatch(s)
+			return englishCurrency(m[1], m[2], m[3], m[4])
+		})
+	}
+	if cfg.Percentages {
+		text = percentRe.ReplaceAllStringFunc(text, func(s string) string {
+			return decimalWords(percentRe.FindStringSubmatch(s)[1]) + " percent"
+		})
+	}
+	if cfg.Abbreviations {
+		text = numberSignRe.ReplaceAllString(text, "number $1")
+		if n.abbrevRe != nil {
+			text = n.abbrevRe.ReplaceAllStringFunc(text, func(s string) string {
+				m := n.abbrevRe.FindStringSubmatch(s)
+				return m[1] + n.abbreviations[m[2]]
+			})
+		}
+	}
+	if cfg.Numbers {
+		text = ordinalRe.ReplaceAllStringFunc(text, func(s string) string {
+			value, err := strconv.ParseInt(ordinalRe.FindStringSubmatch(s)[1], 10, 64)
+			if err != nil {
+				return s
+			}
+			return ordinalWords(value)
+		})
+		text = numberRe.ReplaceAllStringFunc(text, func(s string) string {
+			// 不带千分位的四位整数按年份读（1999 -> nineteen ninety-nine）
+			if len(s) == 4 && !strings.ContainsAny(s, ".,") {
+				if year, _ := strconv.Atoi(s); year >= 1100 && year < 2100 {
+					return yearWords(year)
+				}
+			}
+			return decimalWords(s)
+		})
+	}
+	return text
+}
+
+func (n *textNormalizer) normalizeChinese(text string) string {
+	cfg := n.config
+	if cfg.Dates {
+		text = isoDateRe.ReplaceAllStringFunc(text, func(s string) string {
+			m := isoDateRe.FindStringSubmatch(s)
+			month, _ := strconv.Atoi(m[2])
+			day, _ := strconv.Atoi(m[3])
+			if month < 1 || month > 12 || day < 1 || day > 31 {
+				return s
+			}
+			return fmt.Sprintf("%s年%d月%d日", m[1], month, day)
+		})
+		text = clockTimeRe.ReplaceAllStringFunc(text, func(s string) string {
+			m := clockTimeRe.FindStringSubmatch(s)
+			hour, _ := strconv.Atoi(m[1])
+			minute, _ := strconv.Atoi(m[2])
+			if minute == 0 {
+				return fmt.Sprintf("%d点", hour)
+			}
+			return fmt.Sprintf("%d点%d分", hour, minute)
+		})
+	}
+	if cfg.Currency {
+		text = currencyRe.ReplaceAllStringFunc(text, func(s string) string {
+			m := currencyRe.FindStringSubmatch(s)
+			amount := strings.ReplaceAll(m[2], ",", "")
+			if m[3] != "" {
+				amount += "." + m[3]
+			}
+			return amount + chineseScales[m[4]] + chineseCurrencies[m[1]]
+		})
+	}
+	if cfg.Percentages {
+		text = percentRe.ReplaceAllString(text, "百分之$1")
+	}
+	return text
+}
+
+// baseLanguage 返回语言代码的主语言部分（"en-US" -> "en"）
+func baseLanguage(language string) string {
+	language = strings.ToLower(language)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return language
+}
+
+var (
+	englishMonths = []string{
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	}
+	englishOnes = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+		"seventeen", "eighteen", "nineteen",
+	}
+	englishTens = []string{
+		"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety",
+	}
+	englishScales = []string{"", "thousand", "million", "billion", "trillion"}
+
+	englishIrregularOrdinals = map[string]string{
+		"one": "first", "two": "second", "three": "third", "five": "fifth",
+		"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+	}
+
+	// 货币符号 -> 单数、复数、辅币单数、辅币复数
+	englishCurrencies = map[string][4]string{
+		"$": {"dollar", "dollars", "cent", "cents"},
+		"€": {"euro", "euros", "cent", "cents"},
+		"£": {"pound", "pounds", "penny", "pence"},
+		"¥": {"yen", "yen", "", ""},
+		"￥": {"yen", "yen", "", ""},
+	}
+	chineseCurrencies = map[string]string{"$": "美元", "€": "欧元", "£": "英镑", "¥": "元", "￥": "元"}
+	chineseScales     = map[string]string{"": "", "thousand": "千", "million": "百万", "billion": "十亿", "trillion": "万亿"}
+)
+
+// numberWords 把整数读成英文单词（123 -> one hundred twenty-three）
+func numberWords(n int64) string {
+	if n < 0 {
+		return "minus " + numberWords(-n)
+	}
+	if n < 1000 {
+		return hundredsWords(int(n))
+	}
+
+	if n >= 1e15 {
+		return strconv.FormatInt(n, 10)
+	}
+
+	var groups []string
+	for scale := 0; n > 0; scale++ {
+		if group := int(n % 1000); group > 0 {
+			words := hundredsWords(group)
+			if englishScales[scale] != "" {
+				words += " " + englishScales[scale]
+			}
+			groups = append([]string{words}, groups...)
+		}
+		n /= 1000
+	}
+	return strings.Join(groups, " ")
+}
+
+// hundredsWords 读 0-999
+func hundredsWords(n int) string {
+	switch {
+	case n < 20:
+		return englishOnes[n]
+	case n < 100:
+		if n%10 == 0 {
+			return englishTens[n/10]
+		}
+		return englishTens[n/10] + "-" + englishOnes[n%10]
+	default:
+		words := englishOnes[n/100] + " hundred"
+		if n%100 != 0 {
+			words += " " + hundredsWords(n%100)
+		}
+		return words
+	}
+}
+
+// ordinalWords 读序数词（22 -> twenty-second）
+func ordinalWords(n int64) string {
+	words := numberWords(n)
+	i := strings.LastIndexAny(words, " -") + 1
+	last := words[i:]
+
+	switch {
+	case englishIrregularOrdinals[last] != "":
+		last = englishIrregularOrdinals[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:i] + last
+}
+
+// yearWords 按年份读法（1999 -> nineteen ninety-nine，2005 -> two thousand five）
+func yearWords(year int) string {
+	high, low := year/100, year%100
+	switch {
+	case year >= 2000 && year < 2010:
+		return numberWords(int64(year))
+	case low == 0:
+		return hundredsWords(high) + " hundred"
+	case low < 10:
+		return hundredsWords(high) + " oh " + englishOnes[low]
+	default:
+		return hundredsWords(high) + " " + hundredsWords(low)
+	}
+}
+
+// decimalWords 读可能带千分位和小数的数字（1,234.5 -> one thousand two hundred thirty-four point five）
+func decimalWords(s string) string {
+	s = strings.ReplaceAll(s, ",", "")
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	value, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return s
+	}
+	words := numberWords(value)
+	if fracPart != "" {
+		digits := make([]string, 0, len(fracPart))
+		for _, d := range fracPart {
+			digits = append(digits, englishOnes[d-'0'])
+		}
+		words += " point " + strings.Join(digits, " ")
+	}
+	return words
+}
+
+// englishCurrency 读货币金额（$9.99 -> nine dollars and ninety-nine cents）
+func englishCurrency(symbol, whole, frac, scale string) string {
+	units := englishCurrencies[symbol]
+
+	// 带量级（$1.5 million）时按小数读
+	if scale != "" {
+		amount := whole
+		if frac != "" {
+			amount += "." + frac
+		}
+		return decimalWords(amount) + " " + scale + " " + units[1]
+	}
+
+	value, err := strconv.ParseInt(strings.ReplaceAll(whole, ",", ""), 10, 64)
+	if err != nil {
+		return symbol + whole
+	}
+
+	// 辅币按两位读（$0.5 -> fifty cents），没有辅币的货币按小数读
+	var cents int64
+	if frac != "" {
+		if units[2] == "" || len(frac) > 2 {
+			return decimalWords(whole+"."+frac) + " " + units[1]
+		}
+		cents, _ = strconv.ParseInt((frac + "0")[:2], 10, 64)
+	}
+
+	unit := func(n int64, singular, plural string) string {
+		if n == 1 {
+			return "one " + singular
+		}
+		return numberWords(n) + " " + plural
+	}
+
+	switch {
+	case cents == 0:
+		return unit(value, units[0], units[1])
+	case value == 0:
+		return unit(cents, units[2], units[3])
+	default:
+		return unit(value, units[0], units[1]) + " and " + unit(cents, units[2], units[3])
+	}
+}
@@ -0,0 +1,119 @@
+package elements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTSNormalizer_EnglishCurrency(t *testing.T) {
+	n := newTextNormalizer(DefaultTTSNormalizerConfig())
+
+	cases := map[string]string{
+		"It costs $9.99 today.":  "It costs nine dollars and ninety-nine cents today.",
+		"Only $1.":               "Only one dollar.",
+		"Add $0.50 for shipping": "Add fifty cents for shipping",
+		"A $1,299 laptop":        "A one thousand two hundred ninety-nine dollars laptop",
+		"Raised $2.5 million":    "Raised two point five million dollars",
+		"That's €20.01":          "That's twenty euros and one cent",
+		"£3.10 each":             "three pounds and ten pence each",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, n.normalize(in, "en"), in)
+	}
+}
+
+func TestTTSNormalizer_EnglishPercentages(t *testing.T) {
+	n := newTextNormalizer(DefaultTTSNormalizerConfig())
+
+	assert.Equal(t, "Up forty-five percent", n.normalize("Up 45%", "en"))
+	assert.Equal(t, "a three point five percent rate", n.normalize("a 3.5% rate", "en"))
+	assert.Equal(t, "one hundred percent sure", n.normalize("100 % sure", "en-US"))
+}
+
+func TestTTSNormalizer_EnglishDates(t *testing.T) {
+	n := newTextNormalizer(DefaultTTSNormalizerConfig())
+
+	assert.Equal(t, "Due on January twentieth, twenty twenty-four.", n.normalize("Due on 2024-01-20.", "en"))
+	assert.Equal(t, "March first, two thousand five", n.normalize("2005-03-01", "en"))
+	assert.Equal(t, "December thirty-first, nineteen ninety-nine", n.normalize("1999-12-31", "en"))
+
+	noNumbers := DefaultTTSNormalizerConfig()
+	noNumbers.Numbers = false
+	assert.Equal(t, "2024-13-40", newTextNormalizer(noNumbers).normalize("2024-13-40", "en"), "invalid dates are not read as dates")
+	assert.Equal(t, "at nine oh five or fourteen thirty or seven o'clock", n.normalize("at 9:05 or 14:30 or 7:00", "en"))
+}
+
+func TestTTSNormalizer_EnglishNumbersAndAbbreviations(t *testing.T) {
+	n := newTextNormalizer(DefaultTTSNormalizerConfig())
+
+	assert.Equal(t, "the twenty-second and first items", n.normalize("the 22nd and 1st items", "en"))
+	assert.Equal(t, "one thousand two hundred thirty-four point five users", n.normalize("1,234.5 users", "en"))
+	assert.Equal(t, "since nineteen eighty-four", n.normalize("since 1984", "en"))
+	assert.Equal(t, "Doctor Smith versus Mister Jones, for example", n.normalize("Dr. Smith vs. Mr. Jones, e.g.", "en"))
+	assert.Equal(t, "room number seven", n.normalize("room No. 7", "en"))
+	assert.Equal(t, "version v2 is out", n.normalize("version v2 is out", "en"))
+}
+
+func TestTTSNormalizer_Config(t *testing.T) {
+	cfg := DefaultTTSNormalizerConfig()
+	cfg.Numbers = false
+	cfg.ExtraAbbreviations = map[string]string{"approx.": "about"}
+	n := newTextNormalizer(cfg)
+
+	assert.Equal(t, "about 30 people paid five dollars", n.normalize("approx. 30 people paid $5", "en"))
+	assert.Equal(t, "$5", newTextNormalizer(TTSNormalizerConfig{}).normalize("$5", "en"))
+}
+
+func TestTTSNormalizer_Chinese(t *testing.T) {
+	n := newTextNormalizer(DefaultTTSNormalizerConfig())
+
+	assert.Equal(t, "价格是9.99美元，涨了百分之5", n.normalize("价格是$9.99，涨了5%", "zh"))
+	assert.Equal(t, "会议在2024年1月20日 14点30分", n.normalize("会议在2024-01-20 14:30", "zh-CN"))
+	assert.Equal(t, "El precio es $9.99", n.normalize("El precio es $9.99", "es"))
+}
+
+func TestTTSNormalizerElement(t *testing.T) {
+	elem := NewTTSNormalizerElement()
+	lc := pipeline.NewLanguageContext("auto", "zh")
+	elem.SetLanguageContext(lc)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage("总共$20", "final")
+	audio := &pipeline.PipelineMessage{Type: pipeline.MsgTypeAudio, AudioData: &pipeline.AudioData{}}
+	elem.In() <- audio
+
+	for _, want := range []*pipeline.PipelineMessage{nil, audio} {
+		select {
+		case msg := <-elem.Out():
+			if want == nil {
+				assert.Equal(t, "总共20美元", string(msg.TextData.Data))
+				assert.Equal(t, "final", msg.TextData.TextType)
+			} else {
+				assert.Same(t, want, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for output")
+		}
+	}
+
+	// 显式配置的语言优先于 LanguageContext
+	en := NewTTSNormalizerElement()
+	en.SetLanguageContext(lc)
+	require.NoError(t, en.SetProperty("language", "en"))
+	require.NoError(t, en.Start(context.Background()))
+	defer en.Stop()
+
+	en.In() <- textMessage("$20", "final")
+	select {
+	case msg := <-en.Out():
+		assert.Equal(t, "twenty dollars", string(msg.TextData.Data))
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for output")
+	}
+}
@@ -2,14 +2,29 @@ package elements
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"log"
+	"math"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 	"github.com/realtime-ai/realtime-ai/pkg/tts"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+// defaultCrossfadeMs is the default length of the join between TTS segments
+const defaultCrossfadeMs = 15
+
+// Playback backlog at which the rate adjustment is neutral (1.0) and at which
+// it reaches the maximum rate
+const (
+	paceTargetBacklog = time.Second
+	paceMaxBacklog    = 4 * time.Second
 )
 
 // UniversalTTSElement is a TTS element that can use any TTSProvider
@@ -19,9 +34,77 @@ type UniversalTTSElement struct {
 	*pipeline.BaseElement
 
 	provider tts.TTSProvider
-	voice    string
-	language string
-	options  map[string]interface{}
+
+	// voice, language and options may be changed while segments are being
+	// synthesized concurrently; settingsMu guards them and each request
+	// gets a snapshot (see newRequest)
+	settingsMu sync.Mutex
+	voice      string
+	language   string
+	options    map[string]interface{}
+
+	// languageSet is true once SetLanguage is called; otherwise the
+	// pipeline LanguageContext output language is used when available.
+	// Guarded by settingsMu.
+	languageSet bool
+
+	// Speaking-time limit per response (0 = unlimited).
+	// A response ends with a TextType "final" message.
+	maxSpeaking time.Duration
+	wrapUpText  string
+	spoken      time.Duration
+	limited     bool
+
+	// Crossfade between consecutive segments of a response (0 = disabled).
+	// lastFrame holds the last emitted PCM frame of the current response.
+	crossfade time.Duration
+	lastFrame []int16
+	lastRate  int
+
+	// Playback rate adjustment (1.0/1.0 = disabled). paceStart and paceAudio
+	// track how far the emitted audio runs ahead of real-time playback.
+	minRate   float64
+	maxRate   float64
+	paceStart time.Time
+	paceAudio time.Duration
+
+	// Number of segments synthesized in parallel (1 = one after another)
+	concurrency int
+
+	// Cache of synthesized phrases (nil = disabled). cacheBypass skips the
+	// cache without clearing it. Both are read by concurrent synthesize calls
+	// and may be changed while running.
+	cache       atomic.Pointer[ttsCache]
+	cacheBypass atomic.Bool
+
+	// attrs holds the Attributes of the segment being output and is
+	// copied onto wrap-up audio and error events (output goroutine only)
+	attrs pipeline.Attributes
+
+	// Sentence segmentation of incoming text (disabled by default).
+	// Sentences split off by segmenter wait in sentences until synthesized;
+	// sentenceReady is signalled when the flush timeout splits one off
+	// between messages. segmentAttrs are the Attributes of the last message.
+	segment       bool
+	segmentMin    int
+	segmentMax    int
+	segmenter     *SentenceSegmenter
+	sentenceMu    sync.Mutex
+	sentences     []*ttsSegment
+	segmentAttrs  pipeline.Attributes
+	sentenceReady chan struct{}
+
+	// Segments received but not yet emitted, waited on by Drain
+	inFlight atomic.Int64
+
+	// synthCtx is the context text is synthesized with. Flush cancels it to
+	// abort synthesis of the interrupted response and derives a new one
+	// from baseCtx. flushed tells the output path to start a new response.
+	synthMu     sync.Mutex
+	baseCtx     context.Context
+	synthCtx    context.Context
+	cancelSynth context.CancelFunc
+	flushed     atomic.Bool
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -35,6 +118,10 @@ func NewUniversalTTSElement(provider tts.TTSProvider) *UniversalTTSElement {
 		voice:       provider.GetDefaultVoice(),
 		language:    "en-US", // Default language
 		options:     make(map[string]interface{}),
+		crossfade:   defaultCrossfadeMs * time.Millisecond,
+		minRate:     1,
+		maxRate:     1,
+		concurrency: 1,
 	}
 
 	// Register properties
@@ -60,6 +147,43 @@ func (e *UniversalTTSElement) registerProperties() {
 		Readable: true,
 		Default:  "en-US",
 	})
+
+	e.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "speed",
+		Type:     reflect.TypeOf(float64(0)),
+		Writable: true,
+		Readable: true,
+		Default:  1.0,
+	})
+
+	e.RegisterProperty(pipeline.PropertyDesc{
+		Name:     "segment",
+		Type:     reflect.TypeOf(false),
+		Writable: true,
+		Readable: true,
+		Default:  false,
+	})
+}
+
+// SetProperty sets a property. "voice", "language" and "speed" apply from
+// the next synthesized segment; speed is passed to the provider as the
+// "speed" option. "segment" enables sentence segmentation (see
+// SetSegmentation) and must be set before Start.
+func (e *UniversalTTSElement) SetProperty(name string, value interface{}) error {
+	if err := e.BaseElement.SetProperty(name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "voice":
+		e.SetVoice(value.(string))
+	case "language":
+		e.SetLanguage(value.(string))
+	case "speed":
+		e.SetOption("speed", value.(float64))
+	case "segment":
+		e.SetSegmentation(value.(bool))
+	}
+	return nil
 }
 
 // Start starts the TTS element
@@ -72,6 +196,15 @@ func (e *UniversalTTSElement) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	e.cancel = cancel
 
+	e.synthMu.Lock()
+	e.baseCtx = ctx
+	e.synthCtx, e.cancelSynth = context.WithCancel(ctx)
+	e.synthMu.Unlock()
+
+	if e.segment {
+		e.startSegmenter()
+	}
+
 	// Start processing goroutine
 	e.wg.Add(1)
 	go func() {
@@ -79,7 +212,7 @@ func (e *UniversalTTSElement) Start(ctx context.Context) error {
 		e.processMessages(ctx)
 	}()
 
-	log.Printf("[%s] TTS element started with voice: %s", e.provider.Name(), e.voice)
+	log.Printf("[%s] TTS element started with voice: %s", e.provider.Name(), e.newRequest("").Voice)
 	return nil
 }
 
@@ -90,44 +223,537 @@ func (e *UniversalTTSElement) Stop() error {
 		e.wg.Wait()
 		e.cancel = nil
 	}
+	if e.segmenter != nil {
+		e.segmenter.Reset()
+	}
 	log.Printf("[%s] TTS element stopped", e.provider.Name())
 	return nil
 }
 
+// Prewarm opens the provider's connection ahead of the first synthesis when
+// the provider supports it. It implements pipeline.Prewarmer.
+func (e *UniversalTTSElement) Prewarm(ctx context.Context) error {
+	if p, ok := e.provider.(pipeline.Prewarmer); ok {
+		return p.Prewarm(ctx)
+	}
+	return nil
+}
+
+// Drain waits until every text segment already received has been
+// synthesized and emitted, so a graceful stop does not cut off the end of
+// a reply (see pipeline.Pipeline.StopGraceful)
+func (e *UniversalTTSElement) Drain(ctx context.Context) error {
+	idle := func() bool {
+		return len(e.BaseElement.InChan) == 0 && e.inFlight.Load() == 0
+	}
+	if e.segmenter == nil {
+		return waitUntil(ctx, idle)
+	}
+
+	// Text still waiting for the end of its sentence is spoken as the last one
+	if err := waitUntil(ctx, idle); err != nil {
+		return err
+	}
+	e.segmenter.Flush()
+	return waitUntil(ctx, idle)
+}
+
+// Flush discards the current response on interrupt: synthesis in progress
+// is cancelled, and queued text and synthesized audio not yet taken by the
+// next element are dropped. Text received afterwards starts a new response.
+func (e *UniversalTTSElement) Flush() {
+	e.synthMu.Lock()
+	if e.cancelSynth != nil {
+		e.cancelSynth()
+		e.synthCtx, e.cancelSynth = context.WithCancel(e.baseCtx)
+	}
+	e.synthMu.Unlock()
+
+	e.flushed.Store(true)
+	if n := e.DropQueued(); n > 0 {
+		log.Printf("[%s] Flushed %d queued messages", e.provider.Name(), n)
+	}
+
+	if e.segmenter != nil {
+		e.segmenter.Reset()
+		e.sentenceMu.Lock()
+		dropped := len(e.sentences)
+		e.sentences = nil
+		e.sentenceMu.Unlock()
+		e.inFlight.Add(int64(-dropped))
+	}
+}
+
+// synthesisContext returns the context for synthesizing newly received text
+func (e *UniversalTTSElement) synthesisContext() context.Context {
+	e.synthMu.Lock()
+	defer e.synthMu.Unlock()
+	return e.synthCtx
+}
+
 // processMessages processes incoming text messages and synthesizes speech
 func (e *UniversalTTSElement) processMessages(ctx context.Context) {
+	if e.concurrency > 1 {
+		e.processConcurrent(ctx)
+		return
+	}
+
 	for {
+		seg, ok := e.nextSegment(ctx)
+		if !ok {
+			return
+		}
+		e.attrs = seg.attrs
+		e.handleSegment(seg)
+		e.inFlight.Add(-1)
+	}
+}
+
+// nextSegment returns the next text segment to synthesize: a received text
+// message, or with segmentation enabled the next sentence split off from
+// received text. It returns false when ctx is done.
+func (e *UniversalTTSElement) nextSegment(ctx context.Context) (*ttsSegment, bool) {
+	for {
+		if seg := e.popSentence(); seg != nil {
+			return seg, true
+		}
+
 		select {
 		case <-ctx.Done():
-			return
+			return nil, false
+		case <-e.sentenceReady:
 		case msg := <-e.BaseElement.InChan:
-			if msg.Type == pipeline.MsgTypeData && msg.TextData != nil {
-				text := string(msg.TextData.Data)
-				if err := e.synthesizeAndOutput(ctx, text); err != nil {
-					log.Printf("[%s] Failed to synthesize speech: %v", e.provider.Name(), err)
-					e.publishError(fmt.Sprintf("Failed to synthesize speech: %v", err))
+			if msg.Type != pipeline.MsgTypeData || msg.TextData == nil {
+				continue
+			}
+			text := string(msg.TextData.Data)
+			isFinal := msg.TextData.TextType == "final"
+
+			if e.segmenter == nil {
+				e.inFlight.Add(1)
+				return &ttsSegment{
+					text:    text,
+					isFinal: isFinal,
+					attrs:   msg.Attributes,
+					ctx:     e.synthesisContext(),
+				}, true
+			}
+			e.inFlight.Add(1)
+			e.splitText(text, isFinal, msg.Attributes)
+			e.inFlight.Add(-1)
+		}
+	}
+}
+
+// startSegmenter creates the sentence segmenter for segmented synthesis
+func (e *UniversalTTSElement) startSegmenter() {
+	e.sentenceReady = make(chan struct{}, 1)
+	e.segmenter = NewSentenceSegmenter(SentenceSegmenterConfig{
+		MinLength:              e.segmentMin,
+		MaxLength:              e.segmentMax,
+		EnableSmartPunctuation: true,
+	})
+	e.segmenter.OnSentence(e.queueSentence)
+}
+
+// splitText feeds received text to the segmenter. At the end of a response
+// the rest of the text is split off as the final sentence.
+func (e *UniversalTTSElement) splitText(text string, isFinal bool, attrs pipeline.Attributes) {
+	e.sentenceMu.Lock()
+	e.segmentAttrs = attrs
+	e.sentenceMu.Unlock()
+
+	e.segmenter.Feed(text)
+	if !isFinal {
+		return
+	}
+	e.segmenter.Flush()
+
+	// The end of the response must reach the output path even when all of
+	// its text was already split off, so queue an empty final segment
+	e.sentenceMu.Lock()
+	ended := len(e.sentences) > 0 && e.sentences[len(e.sentences)-1].isFinal
+	e.sentenceMu.Unlock()
+	if !ended {
+		e.queueSentence("", true)
+	}
+}
+
+// queueSentence queues a sentence split off by the segmenter. It is called
+// from Feed and Flush, and from the segmenter's timer on a flush timeout.
+func (e *UniversalTTSElement) queueSentence(sentence string, isFinal bool) {
+	ctx := e.synthesisContext()
+
+	e.sentenceMu.Lock()
+	e.sentences = append(e.sentences, &ttsSegment{
+		text:    sentence,
+		isFinal: isFinal,
+		attrs:   e.segmentAttrs,
+		ctx:     ctx,
+	})
+	e.sentenceMu.Unlock()
+	e.inFlight.Add(1)
+
+	select {
+	case e.sentenceReady <- struct{}{}:
+	default:
+	}
+}
+
+// popSentence removes the oldest queued sentence, or returns nil
+func (e *UniversalTTSElement) popSentence() *ttsSegment {
+	e.sentenceMu.Lock()
+	defer e.sentenceMu.Unlock()
+	if len(e.sentences) == 0 {
+		return nil
+	}
+	seg := e.sentences[0]
+	e.sentences = e.sentences[1:]
+	return seg
+}
+
+// ttsSegment is one text chunk and its synthesized audio.
+// seq is the order in which the text was received; ctx is the synthesis
+// context at that time and is cancelled if the segment is flushed.
+type ttsSegment struct {
+	seq     uint64
+	text    string
+	isFinal bool
+	attrs   pipeline.Attributes
+	ctx     context.Context
+
+	msg *pipeline.PipelineMessage
+	err error
+}
+
+// processConcurrent synthesizes up to e.concurrency segments in parallel.
+// Synthesis may complete in any order; segments are buffered and emitted by
+// sequence number so speech is played in the order the text was received.
+func (e *UniversalTTSElement) processConcurrent(ctx context.Context) {
+	// A slot is held from receiving a segment until it is emitted, which also
+	// bounds how many finished segments wait behind a slow one
+	slots := make(chan struct{}, e.concurrency)
+	results := make(chan *ttsSegment, e.concurrency)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		e.emitInOrder(ctx, results, slots)
+	}()
+
+	var seq uint64
+	for {
+		seg, ok := e.nextSegment(ctx)
+		if !ok {
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		seg.seq = seq
+		seq++
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if seg.text != "" {
+				seg.msg, seg.err = e.synthesize(seg.ctx, seg.text, seg.attrs)
+			}
+			select {
+			case results <- seg:
+			case <-ctx.Done():
+			}
+		}()
+	}
+}
+
+// emitInOrder emits synthesized segments by sequence number, holding back
+// segments that complete before their predecessors
+func (e *UniversalTTSElement) emitInOrder(ctx context.Context, results <-chan *ttsSegment, slots <-chan struct{}) {
+	pending := make(map[uint64]*ttsSegment)
+	var next uint64
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case seg := <-results:
+			pending[seg.seq] = seg
+			for {
+				seg, ok := pending[next]
+				if !ok {
+					break
 				}
+				delete(pending, next)
+				next++
+
+				e.attrs = seg.attrs
+				e.emitSegment(seg.ctx, seg)
+				e.inFlight.Add(-1)
+				<-slots
+			}
+		}
+	}
+}
+
+// handleText synthesizes one text chunk, enforcing the speaking-time limit
+func (e *UniversalTTSElement) handleText(ctx context.Context, text string, isFinal bool) {
+	e.handleSegment(&ttsSegment{text: text, isFinal: isFinal, attrs: e.attrs, ctx: ctx})
+}
+
+// handleSegment synthesizes and outputs one segment. Sentences split off by
+// the segmenter are streamed when the provider supports it.
+func (e *UniversalTTSElement) handleSegment(seg *ttsSegment) {
+	if provider, format, ok := e.streamingProvider(); ok {
+		e.speakSegment(seg.ctx, seg, func() error {
+			return e.streamAndOutput(seg.ctx, provider, format, seg.text)
+		})
+		return
+	}
+
+	if !e.limited && seg.text != "" {
+		seg.msg, seg.err = e.synthesize(seg.ctx, seg.text, seg.attrs)
+	}
+	e.emitSegment(seg.ctx, seg)
+}
+
+// emitSegment outputs a synthesized segment, enforcing the speaking-time limit
+func (e *UniversalTTSElement) emitSegment(ctx context.Context, seg *ttsSegment) {
+	e.speakSegment(ctx, seg, func() error {
+		if seg.err != nil {
+			return seg.err
+		}
+		e.output(ctx, seg.msg)
+		return nil
+	})
+}
+
+// speakSegment applies the per-response rules around speak, which outputs
+// the audio of seg: flushed responses are dropped, the end of a response
+// resets its state and the speaking-time limit is enforced
+func (e *UniversalTTSElement) speakSegment(ctx context.Context, seg *ttsSegment, speak func() error) {
+	if e.flushed.Swap(false) {
+		e.resetResponse()
+	}
+	// Flushed while waiting or synthesizing
+	if ctx.Err() != nil {
+		return
+	}
+
+	if seg.isFinal {
+		defer e.resetSpeakingTime()
+		defer e.resetCrossfade()
+	}
+
+	// Limit reached: drop the rest of the response. An empty segment only
+	// marks the end of the response.
+	if e.limited || seg.text == "" {
+		return
+	}
+
+	if err := speak(); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[%s] Failed to synthesize speech: %v", e.provider.Name(), err)
+		e.publishError(fmt.Sprintf("Failed to synthesize speech: %v", err))
+		return
+	}
+
+	if e.maxSpeaking > 0 && e.spoken >= e.maxSpeaking && !seg.isFinal {
+		e.limited = true
+		log.Printf("[%s] Speaking time limit reached (%v), stopping response", e.provider.Name(), e.spoken)
+
+		if e.wrapUpText != "" {
+			if err := e.synthesizeAndOutput(ctx, e.wrapUpText); err != nil {
+				log.Printf("[%s] Failed to synthesize wrap-up: %v", e.provider.Name(), err)
 			}
 		}
 	}
 }
 
+// resetSpeakingTime starts a new response
+func (e *UniversalTTSElement) resetSpeakingTime() {
+	e.spoken = 0
+	e.limited = false
+}
+
+// resetResponse drops all per-response state after a flush; the final
+// segment that would have reset it was discarded
+func (e *UniversalTTSElement) resetResponse() {
+	e.resetSpeakingTime()
+	e.resetCrossfade()
+	e.paceStart = time.Time{}
+	e.paceAudio = 0
+}
+
 // synthesizeAndOutput synthesizes speech from text and outputs audio data
 func (e *UniversalTTSElement) synthesizeAndOutput(ctx context.Context, text string) error {
-	// Create synthesis request
-	req := &tts.SynthesizeRequest{
+	msg, err := e.synthesize(ctx, text, e.attrs)
+	if err != nil {
+		return err
+	}
+	e.output(ctx, msg)
+	return nil
+}
+
+// synthesize calls the provider and wraps the audio in a pipeline message.
+// It may run concurrently for several segments.
+func (e *UniversalTTSElement) synthesize(ctx context.Context, text string, attrs pipeline.Attributes) (*pipeline.PipelineMessage, error) {
+	req := e.newRequest(text)
+
+	// Exact repeats are served from the cache without calling the provider
+	cache := e.cache.Load()
+	if e.cacheBypass.Load() {
+		cache = nil
+	}
+	key := e.cacheKey(req)
+	if cache != nil {
+		if resp, ok := cache.get(key); ok {
+			log.Printf("[%s] Cache hit for %q (voice: %s)", e.provider.Name(), text, req.Voice)
+			return e.audioMessage(resp, attrs), nil
+		}
+	}
+
+	record := pipeline.ProviderRecord{
+		Kind:     pipeline.ProviderTTS,
+		Provider: e.provider.Name(),
+		Element:  e.GetName(),
+		Language: req.Language,
+		Voice:    req.Voice,
 		Text:     text,
-		Voice:    e.voice,
-		Language: e.language,
-		Options:  e.options,
 	}
+	e.ProviderLogger().Log(record)
+	started := time.Now()
 
 	// Call the provider's synthesize method
 	resp, err := e.provider.Synthesize(ctx, req)
+
+	record.Response = true
+	record.Text = ""
+	record.Duration = time.Since(started)
+	record.Err = err
+	if resp != nil {
+		record.Audio = resp.AudioData
+	}
+	e.ProviderLogger().Log(record)
+
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.put(key, resp)
+	}
+
+	log.Printf("[%s] Synthesized %d bytes of audio (voice: %s)",
+		e.provider.Name(), len(resp.AudioData), req.Voice)
+
+	return e.audioMessage(resp, attrs), nil
+}
+
+// streamingProvider returns the provider for streaming segmented sentences:
+// only with segmentation enabled, and only when the provider streams
+// audio in a format known ahead of the first chunk
+func (e *UniversalTTSElement) streamingProvider() (tts.StreamingTTSProvider, tts.AudioFormat, bool) {
+	if e.segmenter == nil {
+		return nil, tts.AudioFormat{}, false
+	}
+	provider, ok := e.provider.(tts.StreamingTTSProvider)
+	if !ok {
+		return nil, tts.AudioFormat{}, false
+	}
+	formatter, ok := e.provider.(tts.StreamFormatProvider)
+	if !ok {
+		return nil, tts.AudioFormat{}, false
+	}
+	return provider, formatter.StreamAudioFormat(), true
+}
+
+// streamAndOutput synthesizes one sentence with StreamSynthesize and outputs
+// each audio chunk as it arrives. PCM chunks are cut at whole frames.
+func (e *UniversalTTSElement) streamAndOutput(ctx context.Context, provider tts.StreamingTTSProvider, format tts.AudioFormat, text string) error {
+	req := e.newRequest(text)
+
+	record := pipeline.ProviderRecord{
+		Kind:     pipeline.ProviderTTS,
+		Provider: e.provider.Name(),
+		Element:  e.GetName(),
+		Language: req.Language,
+		Voice:    req.Voice,
+		Text:     text,
+	}
+	e.ProviderLogger().Log(record)
+	started := time.Now()
+
+	frameSize := 1
+	switch mediaType := format.MediaType.(type) {
+	case pipeline.AudioMediaType:
+		if isPCMMediaType(mediaType) {
+			frameSize = 2 * max(format.Channels, 1)
+		}
+	case string:
+		if isPCMMediaType(pipeline.AudioMediaType(mediaType)) {
+			frameSize = 2 * max(format.Channels, 1)
+		}
+	}
+
+	// The end of the sentence is only known once the stream ends, so the
+	// last 20ms are held back and sent with EndOfSentence set
+	hold := frameSize * max(format.SampleRate/50, 1)
+
+	audioChan, errChan := provider.StreamSynthesize(ctx, req)
+
+	var all, pending []byte
+	first := true
+	for chunk := range audioChan {
+		all = append(all, chunk...)
+		pending = append(pending, chunk...)
+		n := len(pending) - len(pending)%frameSize - hold
+		if n <= 0 {
+			continue
+		}
+		data := pending[:n:n]
+		pending = append([]byte(nil), pending[n:]...)
+
+		// Keep reading after a flush so the provider is not blocked
+		if ctx.Err() != nil {
+			continue
+		}
+		e.outputChunk(ctx, e.audioMessage(&tts.SynthesizeResponse{AudioData: data, AudioFormat: format}, e.attrs), first)
+		first = false
+	}
+	err := <-errChan
+
+	// A trailing partial frame is dropped
+	if n := len(pending) - len(pending)%frameSize; n > 0 && ctx.Err() == nil {
+		msg := e.audioMessage(&tts.SynthesizeResponse{AudioData: pending[:n], AudioFormat: format}, e.attrs)
+		msg.AudioData.EndOfSentence = true
+		e.outputChunk(ctx, msg, first)
+	}
+
+	record.Response = true
+	record.Text = ""
+	record.Duration = time.Since(started)
+	record.Err = err
+	record.Audio = all
+	e.ProviderLogger().Log(record)
+
 	if err != nil {
 		return err
 	}
+	log.Printf("[%s] Streamed %d bytes of audio (voice: %s)", e.provider.Name(), len(all), req.Voice)
+	return nil
+}
 
+// audioMessage wraps a synthesis response in a pipeline message
+func (e *UniversalTTSElement) audioMessage(resp *tts.SynthesizeResponse, attrs pipeline.Attributes) *pipeline.PipelineMessage {
 	// Create audio message for the pipeline
 	// Convert MediaType to AudioMediaType
 	var mediaType pipeline.AudioMediaType
@@ -139,8 +765,9 @@ func (e *UniversalTTSElement) synthesizeAndOutput(ctx context.Context, text stri
 		mediaType = pipeline.AudioMediaTypeRaw // default
 	}
 
-	msg := &pipeline.PipelineMessage{
-		Type: pipeline.MsgTypeAudio,
+	return &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeAudio,
+		Attributes: attrs,
 		AudioData: &pipeline.AudioData{
 			Data:       resp.AudioData,
 			SampleRate: resp.AudioFormat.SampleRate,
@@ -149,39 +776,353 @@ func (e *UniversalTTSElement) synthesizeAndOutput(ctx context.Context, text stri
 			Timestamp:  time.Now(),
 		},
 	}
+}
 
-	// Send to output channel
-	e.BaseElement.OutChan <- msg
+// cacheKey returns the cache key for a synthesis request
+func (e *UniversalTTSElement) cacheKey(req *tts.SynthesizeRequest) ttsCacheKey {
+	speed := 1.0
+	if s, ok := req.Options["speed"].(float64); ok {
+		speed = s
+	}
+	return ttsCacheKey{
+		provider: e.provider.Name(),
+		text:     req.Text,
+		voice:    req.Voice,
+		language: req.Language,
+		speed:    speed,
+	}
+}
 
-	log.Printf("[%s] Synthesized %d bytes of audio (voice: %s)",
-		e.provider.Name(), len(resp.AudioData), e.voice)
+// output post-processes a synthesized segment and sends it downstream.
+// Segments must be output in speech order. Each segment is a whole sentence,
+// so it is marked as the end of one for the audio pacer.
+func (e *UniversalTTSElement) output(ctx context.Context, msg *pipeline.PipelineMessage) {
+	msg.AudioData.EndOfSentence = true
 
-	return nil
+	// Speed up or slow down to keep pace, then smooth the join with the previous segment
+	e.applyPlaybackRate(msg.AudioData)
+	e.applyCrossfade(msg.AudioData)
+
+	e.send(ctx, msg)
+}
+
+// outputChunk sends one chunk of a streamed sentence downstream. The first
+// chunk is crossfaded with the previous sentence; later chunks continue the
+// same audio and are sent as is. Streamed audio is not time-stretched.
+func (e *UniversalTTSElement) outputChunk(ctx context.Context, msg *pipeline.PipelineMessage, first bool) {
+	if first {
+		e.applyCrossfade(msg.AudioData)
+	} else {
+		e.rememberLastFrame(msg.AudioData)
+	}
+	e.send(ctx, msg)
+}
+
+// send sends processed audio downstream and counts it as spoken
+func (e *UniversalTTSElement) send(ctx context.Context, msg *pipeline.PipelineMessage) {
+	// Flushed during synthesis
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Send to output channel
+	select {
+	case e.BaseElement.OutChan <- msg:
+	case <-ctx.Done():
+		return
+	}
+
+	e.spoken += pcmDuration(msg.AudioData)
+	e.paceAudio += pcmDuration(msg.AudioData)
 }
 
 // publishError publishes an error event to the pipeline bus
 func (e *UniversalTTSElement) publishError(message string) {
 	if e.BaseElement.Bus() != nil {
 		e.BaseElement.Bus().Publish(pipeline.Event{
-			Type:      pipeline.EventError,
-			Timestamp: time.Now(),
-			Payload:   message,
+			Type:       pipeline.EventError,
+			Timestamp:  time.Now(),
+			Payload:    message,
+			Attributes: e.attrs,
 		})
 	}
 }
 
 // SetVoice sets the voice to use for synthesis
 func (e *UniversalTTSElement) SetVoice(voice string) {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
 	e.voice = voice
 }
 
-// SetLanguage sets the language for synthesis
+// SetLanguage sets the language for synthesis, overriding the pipeline LanguageContext
 func (e *UniversalTTSElement) SetLanguage(language string) {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
 	e.language = language
+	e.languageSet = true
+}
+
+// newRequest creates a synthesis request for text with a snapshot of the
+// current voice, language and options
+func (e *UniversalTTSElement) newRequest(text string) *tts.SynthesizeRequest {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
+
+	options := make(map[string]interface{}, len(e.options))
+	for key, value := range e.options {
+		options[key] = value
+	}
+	return &tts.SynthesizeRequest{
+		Text:     text,
+		Voice:    e.voice,
+		Language: e.synthesisLanguageLocked(),
+		Options:  options,
+	}
+}
+
+// synthesisLanguageLocked returns the explicit language, then the pipeline output
+// language (target, or detected source when not translating), then the default.
+// settingsMu must be held.
+func (e *UniversalTTSElement) synthesisLanguageLocked() string {
+	if !e.languageSet {
+		if lang := e.LanguageContext().OutputLanguage(); lang != "" {
+			return lang
+		}
+	}
+	return e.language
+}
+
+// SetMaxSpeakingMs limits how long a single response may speak (0 = unlimited).
+// Once the limit is hit, the remaining text of the response is dropped and
+// wrapUp (if not empty) is spoken instead. Only raw PCM output is measured.
+func (e *UniversalTTSElement) SetMaxSpeakingMs(maxSpeakingMs int, wrapUp string) {
+	e.maxSpeaking = time.Duration(maxSpeakingMs) * time.Millisecond
+	e.wrapUpText = wrapUp
+}
+
+// SetCrossfadeMs sets the crossfade applied at the start of each TTS segment
+// that follows another segment of the same response (0 = disabled, default 15ms).
+// Independently synthesized sentences rarely start where the previous one
+// ended, which is heard as a click at the seam. The start of the new segment
+// is blended from the last sample of the previous one into its own signal,
+// so no audio has to be held back. Only raw PCM output is affected.
+func (e *UniversalTTSElement) SetCrossfadeMs(crossfadeMs int) {
+	e.crossfade = time.Duration(crossfadeMs) * time.Millisecond
+}
+
+// SetConcurrency sets how many text segments may be synthesized in parallel
+// (default 1 = one after another). Must be called before Start.
+//
+// Parallel synthesis lowers the gap between sentences when the provider is
+// slower than real time. Segments can finish out of order, so each one is
+// numbered on arrival and held back until all earlier segments have been
+// output. The provider must support concurrent Synthesize calls.
+func (e *UniversalTTSElement) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	e.concurrency = n
+}
+
+// SetSegmentation enables sentence segmentation of incoming text (disabled
+// by default). Must be called before Start.
+//
+// Text messages are fed to a SentenceSegmenter and each sentence is
+// synthesized on its own, so the audio of the first sentence of a long
+// paragraph starts flowing while the rest is still being synthesized or has
+// not arrived yet. A TextType "final" message ends the response and its
+// remaining text is spoken as the last sentence. When the provider implements
+// tts.StreamingTTSProvider and tts.StreamFormatProvider and concurrency is 1,
+// each sentence is synthesized with StreamSynthesize and its audio is output
+// chunk by chunk; streamed sentences are neither cached nor time-stretched.
+func (e *UniversalTTSElement) SetSegmentation(enabled bool) {
+	e.segment = enabled
+}
+
+// SetSegmentLength sets the minimum and maximum sentence length in characters
+// used by segmentation (0 = SentenceSegmenter default). Shorter sentences are
+// joined with the next one; longer ones are split at a comma or space.
+// Must be called before Start.
+func (e *UniversalTTSElement) SetSegmentLength(minLength, maxLength int) {
+	e.segmentMin = minLength
+	e.segmentMax = maxLength
+}
+
+// SetCacheSize enables an LRU cache of up to size synthesized phrases
+// (0 = disabled, the default). It may be called while running; the new
+// cache starts empty.
+//
+// Assistants often repeat phrases such as greetings or "One moment, please."
+// Exact repeats with the same provider, voice, language and speed option are
+// served from the cache without a synthesis call. Other provider options are
+// not part of the key, so the cache should be cleared by calling SetCacheSize
+// again after changing them.
+func (e *UniversalTTSElement) SetCacheSize(size int) {
+	if size <= 0 {
+		e.cache.Store(nil)
+		return
+	}
+	e.cache.Store(newTTSCache(size))
+}
+
+// SetCacheBypass skips the cache (both lookups and stores) while bypass is
+// true, e.g. for providers whose output is intentionally not deterministic.
+// Cached phrases are kept and used again once the bypass is lifted.
+// It may be called while running.
+func (e *UniversalTTSElement) SetCacheBypass(bypass bool) {
+	e.cacheBypass.Store(bypass)
+}
+
+// SetPlaybackRateRange enables time-stretching of TTS output within
+// [minRate, maxRate] without changing the pitch (both 1 = disabled, the default).
+// Rates are limited to ±20%.
+//
+// The rate follows the playback backlog, i.e. how much emitted audio has not
+// been played yet at real-time speed: up to 1s it moves from minRate to 1.0,
+// beyond that it rises to maxRate at 4s. In simultaneous interpretation this
+// keeps translated speech, which often runs longer than the source, from
+// drifting further and further behind.
+func (e *UniversalTTSElement) SetPlaybackRateRange(minRate, maxRate float64) {
+	if minRate > maxRate {
+		minRate, maxRate = maxRate, minRate
+	}
+	e.minRate = clampRate(minRate)
+	e.maxRate = clampRate(maxRate)
+}
+
+// clampRate limits a playback rate to the supported time-stretch range
+func clampRate(rate float64) float64 {
+	if rate < audio.TimeStretchMinRate {
+		return audio.TimeStretchMinRate
+	}
+	if rate > audio.TimeStretchMaxRate {
+		return audio.TimeStretchMaxRate
+	}
+	return rate
+}
+
+// playbackRate returns the rate for the next segment based on the playback backlog
+func (e *UniversalTTSElement) playbackRate() float64 {
+	now := time.Now()
+	backlog := e.paceStart.Add(e.paceAudio).Sub(now)
+	if backlog <= 0 {
+		// Everything emitted so far has been played
+		e.paceStart = now
+		e.paceAudio = 0
+		backlog = 0
+	}
+
+	if backlog < paceTargetBacklog {
+		return e.minRate + (1-e.minRate)*float64(backlog)/float64(paceTargetBacklog)
+	}
+	if backlog >= paceMaxBacklog {
+		return e.maxRate
+	}
+	return 1 + (e.maxRate-1)*float64(backlog-paceTargetBacklog)/float64(paceMaxBacklog-paceTargetBacklog)
+}
+
+// applyPlaybackRate time-stretches raw PCM output according to playbackRate
+func (e *UniversalTTSElement) applyPlaybackRate(data *pipeline.AudioData) {
+	if e.minRate == 1 && e.maxRate == 1 {
+		return
+	}
+
+	rate := e.playbackRate()
+	if data == nil || data.SampleRate <= 0 || !isPCMMediaType(data.MediaType) || len(data.Data)%2 != 0 ||
+		math.Abs(rate-1) < 0.01 {
+		return
+	}
+
+	samples := audio.TimeStretch(utils.ByteSliceToInt16Slice(data.Data), data.Channels, data.SampleRate, rate)
+	data.Data = utils.Int16SliceToByteSlice(samples)
+	log.Printf("[%s] Playback rate %.2f", e.provider.Name(), rate)
+}
+
+// applyCrossfade blends the start of data from the previous segment's last frame
+func (e *UniversalTTSElement) applyCrossfade(data *pipeline.AudioData) {
+	if e.crossfade <= 0 || data == nil || data.SampleRate <= 0 || !isPCMMediaType(data.MediaType) {
+		e.resetCrossfade()
+		return
+	}
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	frames := len(data.Data) / (2 * channels)
+	if frames == 0 {
+		return
+	}
+
+	// Only blend segments of the same format
+	if len(e.lastFrame) == channels && e.lastRate == data.SampleRate {
+		fadeFrames := int(e.crossfade * time.Duration(data.SampleRate) / time.Second)
+		if fadeFrames > frames {
+			fadeFrames = frames
+		}
+		for i := 0; i < fadeFrames; i++ {
+			w := float64(i+1) / float64(fadeFrames+1)
+			for ch := 0; ch < channels; ch++ {
+				off := (i*channels + ch) * 2
+				cur := float64(int16(binary.LittleEndian.Uint16(data.Data[off:])))
+				mixed := float64(e.lastFrame[ch])*(1-w) + cur*w
+				binary.LittleEndian.PutUint16(data.Data[off:], uint16(int16(mixed)))
+			}
+		}
+	}
+
+	e.rememberLastFrame(data)
+}
+
+// rememberLastFrame keeps the last frame of data for crossfading the next segment
+func (e *UniversalTTSElement) rememberLastFrame(data *pipeline.AudioData) {
+	if e.crossfade <= 0 || data == nil || data.SampleRate <= 0 || !isPCMMediaType(data.MediaType) {
+		e.resetCrossfade()
+		return
+	}
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	frames := len(data.Data) / (2 * channels)
+	if frames == 0 {
+		return
+	}
+
+	e.lastFrame = make([]int16, channels)
+	e.lastRate = data.SampleRate
+	last := (frames - 1) * channels * 2
+	for ch := 0; ch < channels; ch++ {
+		e.lastFrame[ch] = int16(binary.LittleEndian.Uint16(data.Data[last+ch*2:]))
+	}
+}
+
+// resetCrossfade forgets the previous segment, e.g. at the end of a response
+func (e *UniversalTTSElement) resetCrossfade() {
+	e.lastFrame = nil
+	e.lastRate = 0
+}
+
+// pcmDuration returns the playback duration of raw 16-bit PCM audio (0 for encoded audio)
+func pcmDuration(data *pipeline.AudioData) time.Duration {
+	if data == nil || data.SampleRate <= 0 {
+		return 0
+	}
+	if !isPCMMediaType(data.MediaType) {
+		return 0
+	}
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	samples := len(data.Data) / (2 * channels)
+	return time.Duration(samples) * time.Second / time.Duration(data.SampleRate)
 }
 
 // SetOption sets a provider-specific option
 func (e *UniversalTTSElement) SetOption(key string, value interface{}) {
+	e.settingsMu.Lock()
+	defer e.settingsMu.Unlock()
 	if e.options == nil {
 		e.options = make(map[string]interface{})
 	}
@@ -193,6 +1134,15 @@ func (e *UniversalTTSElement) GetProvider() tts.TTSProvider {
 	return e.provider
 }
 
+// Healthy reports whether the provider's connection is live.
+// Providers without a persistent connection are always healthy.
+func (e *UniversalTTSElement) Healthy() bool {
+	if hc, ok := e.provider.(pipeline.HealthChecker); ok {
+		return hc.Healthy()
+	}
+	return true
+}
+
 // GetSupportedVoices returns the list of supported voices
 func (e *UniversalTTSElement) GetSupportedVoices() []string {
 	return e.provider.GetSupportedVoices()
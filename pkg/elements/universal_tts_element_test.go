@@ -0,0 +1,686 @@
+package elements
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/tts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTTSProvider returns one second of 16kHz mono PCM for every request
+type fakeTTSProvider struct {
+	texts     []string
+	languages []string
+}
+
+func (p *fakeTTSProvider) Name() string { return "fake" }
+
+func (p *fakeTTSProvider) Synthesize(ctx context.Context, req *tts.SynthesizeRequest) (*tts.SynthesizeResponse, error) {
+	p.texts = append(p.texts, req.Text)
+	p.languages = append(p.languages, req.Language)
+	return &tts.SynthesizeResponse{
+		AudioData: make([]byte, 16000*2),
+		AudioFormat: tts.AudioFormat{
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}, nil
+}
+
+func (p *fakeTTSProvider) GetSupportedVoices() []string { return []string{"default"} }
+func (p *fakeTTSProvider) GetDefaultVoice() string      { return "default" }
+func (p *fakeTTSProvider) ValidateConfig() error        { return nil }
+
+func TestUniversalTTSElement_MaxSpeakingMs(t *testing.T) {
+	provider := &fakeTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetMaxSpeakingMs(1500, "wrap up")
+
+	ctx := context.Background()
+	elem.handleText(ctx, "one.", false)
+	elem.handleText(ctx, "two.", false) // 2s spoken: limit hit, wrap-up follows
+	elem.handleText(ctx, "three.", false)
+	elem.handleText(ctx, "four.", true) // end of response resets the limit
+	elem.handleText(ctx, "five.", false)
+
+	assert.Equal(t, []string{"one.", "two.", "wrap up", "five."}, provider.texts)
+	assert.Len(t, elem.Out(), 4)
+}
+
+func TestUniversalTTSElement_LanguageContext(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+
+	// No context: element default
+	elem.handleText(ctx, "one.", true)
+
+	// Target language from the pipeline context
+	lc := pipeline.NewLanguageContext("auto", "ja")
+	elem.SetLanguageContext(lc)
+	elem.handleText(ctx, "two.", true)
+
+	// Explicit language overrides the context
+	elem.SetLanguage("fr")
+	elem.handleText(ctx, "three.", true)
+
+	assert.Equal(t, []string{"en-US", "ja", "fr"}, provider.languages)
+}
+
+// levelTTSProvider returns 100ms of 16kHz mono PCM at a constant level per request
+type levelTTSProvider struct {
+	fakeTTSProvider
+	levels []int16
+}
+
+func (p *levelTTSProvider) Synthesize(ctx context.Context, req *tts.SynthesizeRequest) (*tts.SynthesizeResponse, error) {
+	level := p.levels[len(p.texts)%len(p.levels)]
+	p.texts = append(p.texts, req.Text)
+
+	data := make([]byte, 1600*2)
+	for i := 0; i < len(data); i += 2 {
+		binary.LittleEndian.PutUint16(data[i:], uint16(level))
+	}
+	return &tts.SynthesizeResponse{
+		AudioData: data,
+		AudioFormat: tts.AudioFormat{
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}, nil
+}
+
+// maxSampleJump returns the largest difference between consecutive samples
+func maxSampleJump(t *testing.T, elem *UniversalTTSElement) int {
+	t.Helper()
+	var samples []int16
+	for len(elem.Out()) > 0 {
+		msg := <-elem.Out()
+		for i := 0; i+1 < len(msg.AudioData.Data); i += 2 {
+			samples = append(samples, int16(binary.LittleEndian.Uint16(msg.AudioData.Data[i:])))
+		}
+	}
+
+	jump := 0
+	for i := 1; i < len(samples); i++ {
+		d := int(samples[i]) - int(samples[i-1])
+		if d < 0 {
+			d = -d
+		}
+		if d > jump {
+			jump = d
+		}
+	}
+	return jump
+}
+
+func TestUniversalTTSElement_Crossfade(t *testing.T) {
+	ctx := context.Background()
+
+	// Without crossfade the seam jumps from 10000 to -10000
+	elem := NewUniversalTTSElement(&levelTTSProvider{levels: []int16{10000, -10000}})
+	elem.SetCrossfadeMs(0)
+	elem.handleText(ctx, "one.", false)
+	elem.handleText(ctx, "two.", true)
+	assert.Equal(t, 20000, maxSampleJump(t, elem))
+
+	// 15ms at 16kHz spreads the jump over 240 samples
+	elem = NewUniversalTTSElement(&levelTTSProvider{levels: []int16{10000, -10000}})
+	elem.handleText(ctx, "one.", false)
+	elem.handleText(ctx, "two.", false)
+	elem.handleText(ctx, "three.", true)
+	assert.LessOrEqual(t, maxSampleJump(t, elem), 100)
+}
+
+func TestUniversalTTSElement_CrossfadeResetsPerResponse(t *testing.T) {
+	ctx := context.Background()
+	elem := NewUniversalTTSElement(&levelTTSProvider{levels: []int16{10000, -10000}})
+
+	elem.handleText(ctx, "one.", true)
+	first := <-elem.Out()
+	elem.handleText(ctx, "two.", false)
+	second := <-elem.Out()
+
+	// A new response starts untouched
+	assert.Equal(t, int16(10000), int16(binary.LittleEndian.Uint16(first.AudioData.Data)))
+	assert.Equal(t, int16(-10000), int16(binary.LittleEndian.Uint16(second.AudioData.Data)))
+}
+
+func TestUniversalTTSElement_PlaybackRate(t *testing.T) {
+	ctx := context.Background()
+	elem := NewUniversalTTSElement(&fakeTTSProvider{})
+	elem.SetPlaybackRateRange(1.0, 1.2)
+
+	// Nothing queued for playback: normal speed
+	elem.handleText(ctx, "one.", false)
+	msg := <-elem.Out()
+	assert.Len(t, msg.AudioData.Data, 16000*2)
+
+	// Far behind real time: maximum rate, same pitch but shorter
+	elem.paceAudio += 10 * time.Second
+	elem.handleText(ctx, "two.", false)
+	msg = <-elem.Out()
+	assert.Len(t, msg.AudioData.Data, 13333*2) // 1s / 1.2
+
+	// Rates are limited to ±20%
+	elem.SetPlaybackRateRange(1.5, 0.5)
+	assert.Equal(t, 0.8, elem.minRate)
+	assert.Equal(t, 1.2, elem.maxRate)
+}
+
+// delayTTSProvider takes a per-text delay to synthesize and returns the text
+// as audio, so tests can make segments finish out of order
+type delayTTSProvider struct {
+	fakeTTSProvider
+	delays map[string]time.Duration
+
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (p *delayTTSProvider) Synthesize(ctx context.Context, req *tts.SynthesizeRequest) (*tts.SynthesizeResponse, error) {
+	p.mu.Lock()
+	p.running++
+	if p.running > p.peak {
+		p.peak = p.running
+	}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(p.delays[req.Text]):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &tts.SynthesizeResponse{
+		AudioData:   []byte(req.Text),
+		AudioFormat: tts.AudioFormat{MediaType: pipeline.AudioMediaTypeOpus},
+	}, nil
+}
+
+func TestUniversalTTSElement_ConcurrentSynthesisKeepsOrder(t *testing.T) {
+	provider := &delayTTSProvider{delays: map[string]time.Duration{
+		"one.":   150 * time.Millisecond,
+		"two.":   10 * time.Millisecond,
+		"three.": 80 * time.Millisecond,
+		"four.":  0,
+	}}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetConcurrency(4)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	start := time.Now()
+	texts := []string{"one.", "two.", "three.", "four."}
+	for i, text := range texts {
+		textType := "partial"
+		if i == len(texts)-1 {
+			textType = "final"
+		}
+		elem.In() <- textMessage(text, textType)
+	}
+
+	var got []string
+	for range texts {
+		select {
+		case msg := <-elem.Out():
+			got = append(got, string(msg.AudioData.Data))
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for audio, got %v", got)
+		}
+	}
+
+	// Finished two, four, three, one; emitted in the order received
+	assert.Equal(t, texts, got)
+	provider.mu.Lock()
+	assert.Greater(t, provider.peak, 1)
+	provider.mu.Unlock()
+	// Synthesized in parallel: about as long as the slowest segment
+	assert.Less(t, time.Since(start), 230*time.Millisecond)
+}
+
+func TestUniversalTTSElement_ConcurrencyLimit(t *testing.T) {
+	provider := &delayTTSProvider{delays: map[string]time.Duration{}}
+	texts := []string{"a", "b", "c", "d", "e", "f"}
+	for _, text := range texts {
+		provider.delays[text] = 20 * time.Millisecond
+	}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetConcurrency(2)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	for _, text := range texts {
+		elem.In() <- textMessage(text, "partial")
+	}
+	var got []string
+	for range texts {
+		select {
+		case msg := <-elem.Out():
+			got = append(got, string(msg.AudioData.Data))
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for audio, got %v", got)
+		}
+	}
+
+	assert.Equal(t, texts, got)
+	provider.mu.Lock()
+	assert.LessOrEqual(t, provider.peak, 2)
+	provider.mu.Unlock()
+}
+
+func TestUniversalTTSElement_Drain(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		provider := &delayTTSProvider{delays: map[string]time.Duration{"a": 30 * time.Millisecond, "b": 10 * time.Millisecond, "c": 20 * time.Millisecond}}
+		elem := NewUniversalTTSElement(provider)
+		elem.SetConcurrency(concurrency)
+		require.NoError(t, elem.Start(context.Background()))
+
+		for _, text := range []string{"a", "b", "c"} {
+			elem.In() <- textMessage(text, "partial")
+		}
+
+		// Drain returns only after every segment received was emitted
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		require.NoError(t, elem.Drain(ctx))
+		cancel()
+		assert.Len(t, elem.Out(), 3, "concurrency %d", concurrency)
+		elem.Stop()
+	}
+}
+
+func TestUniversalTTSElement_Flush(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		provider := &delayTTSProvider{delays: map[string]time.Duration{
+			"a": 200 * time.Millisecond,
+			"b": 200 * time.Millisecond,
+			"c": 200 * time.Millisecond,
+			"d": 0,
+		}}
+		elem := NewUniversalTTSElement(provider)
+		elem.SetConcurrency(concurrency)
+		require.NoError(t, elem.Start(context.Background()))
+
+		for _, text := range []string{"a", "b", "c"} {
+			elem.In() <- textMessage(text, "partial")
+		}
+		time.Sleep(30 * time.Millisecond)
+
+		// Interrupted: queued text and synthesis in progress are discarded
+		start := time.Now()
+		elem.Flush()
+		elem.In() <- textMessage("d", "final")
+
+		select {
+		case msg := <-elem.Out():
+			assert.Equal(t, "d", string(msg.AudioData.Data), "concurrency %d", concurrency)
+			assert.Less(t, time.Since(start), 150*time.Millisecond, "concurrency %d", concurrency)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for audio after flush, concurrency %d", concurrency)
+		}
+		select {
+		case msg := <-elem.Out():
+			t.Errorf("unexpected audio after flush: %q, concurrency %d", msg.AudioData.Data, concurrency)
+		case <-time.After(250 * time.Millisecond):
+		}
+		elem.Stop()
+	}
+}
+
+func TestUniversalTTSElement_Cache(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetCacheSize(2)
+
+	elem.handleText(ctx, "How can I help you today?", true)
+	elem.handleText(ctx, "How can I help you today?", true)
+	assert.Equal(t, []string{"How can I help you today?"}, provider.texts, "repeat should hit the cache")
+	require.Len(t, elem.Out(), 2)
+	first, second := <-elem.Out(), <-elem.Out()
+	assert.Equal(t, first.AudioData.Data, second.AudioData.Data)
+
+	// A different voice or speed is a different phrase
+	elem.SetVoice("other")
+	elem.handleText(ctx, "How can I help you today?", true)
+	elem.SetOption("speed", 1.2)
+	elem.handleText(ctx, "How can I help you today?", true)
+	assert.Len(t, provider.texts, 3)
+
+	// Capacity 2: the first entry was evicted
+	elem.SetVoice("default")
+	elem.SetOption("speed", 1.0)
+	elem.handleText(ctx, "How can I help you today?", true)
+	assert.Len(t, provider.texts, 4)
+	assert.Equal(t, 2, elem.cache.Load().len())
+
+	// Bypass always calls the provider
+	elem.SetCacheBypass(true)
+	elem.handleText(ctx, "How can I help you today?", true)
+	assert.Len(t, provider.texts, 5)
+}
+
+func TestUniversalTTSElement_CacheConcurrent(t *testing.T) {
+	provider := &requestTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetConcurrency(3)
+	elem.SetCacheSize(4)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	// The cache is bypassed and replaced while segments are synthesized in parallel
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			elem.SetCacheBypass(i%2 == 0)
+			elem.SetCacheSize(4 + i%3)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		elem.In() <- textMessage(fmt.Sprintf("phrase %d", i%3), "partial")
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case <-elem.Out():
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for segment %d", i)
+		}
+	}
+	<-done
+}
+
+// optionsTTSProvider records the voice and speed of every request
+type optionsTTSProvider struct {
+	fakeTTSProvider
+	voices []string
+	speeds []interface{}
+}
+
+func (p *optionsTTSProvider) Synthesize(ctx context.Context, req *tts.SynthesizeRequest) (*tts.SynthesizeResponse, error) {
+	p.voices = append(p.voices, req.Voice)
+	p.speeds = append(p.speeds, req.Options["speed"])
+	return p.fakeTTSProvider.Synthesize(ctx, req)
+}
+
+func TestUniversalTTSElement_SetProperty(t *testing.T) {
+	ctx := context.Background()
+	provider := &optionsTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+
+	elem.handleText(ctx, "one.", true)
+	require.NoError(t, elem.SetProperty("voice", "narrator"))
+	require.NoError(t, elem.SetProperty("speed", 1.25))
+	require.NoError(t, elem.SetProperty("language", "de-DE"))
+	elem.handleText(ctx, "two.", true)
+
+	assert.Equal(t, []string{"default", "narrator"}, provider.voices)
+	assert.Equal(t, []interface{}{nil, 1.25}, provider.speeds)
+	assert.Equal(t, []string{"en-US", "de-DE"}, provider.languages)
+
+	v, err := elem.GetProperty("speed")
+	require.NoError(t, err)
+	assert.Equal(t, 1.25, v)
+	assert.Error(t, elem.SetProperty("speed", 1), "speed must be a float64")
+}
+
+// requestTTSProvider keeps every request and reads its options while
+// synthesizing, like providers that build the API call from them
+type requestTTSProvider struct {
+	fakeTTSProvider
+	mu       sync.Mutex
+	requests []*tts.SynthesizeRequest
+}
+
+func (p *requestTTSProvider) Synthesize(ctx context.Context, req *tts.SynthesizeRequest) (*tts.SynthesizeResponse, error) {
+	_ = req.Options["speed"]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+	return p.fakeTTSProvider.Synthesize(ctx, req)
+}
+
+func TestUniversalTTSElement_SetPropertyConcurrent(t *testing.T) {
+	provider := &requestTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetConcurrency(3)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	// Settings change while segments are synthesized in parallel
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			elem.SetProperty("speed", 1+float64(i)/100)
+			elem.SetProperty("voice", fmt.Sprintf("voice-%d", i))
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		elem.In() <- textMessage(fmt.Sprintf("segment %d", i), "partial")
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case <-elem.Out():
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for segment %d", i)
+		}
+	}
+	<-done
+
+	// Each request has its own copy of the options
+	elem.SetOption("speed", 2.0)
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	require.Len(t, provider.requests, 20)
+	for _, req := range provider.requests {
+		assert.NotEqual(t, 2.0, req.Options["speed"])
+	}
+}
+
+// sentenceTTSProvider records every request and holds requests containing
+// block until release is closed. It streams each text as chunks of odd sizes.
+type sentenceTTSProvider struct {
+	fakeTTSProvider
+	block   string
+	release chan struct{}
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (p *sentenceTTSProvider) wait(ctx context.Context, text string) error {
+	p.mu.Lock()
+	p.requests = append(p.requests, text)
+	p.mu.Unlock()
+	if p.block != "" && strings.Contains(text, p.block) {
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (p *sentenceTTSProvider) requested() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.requests...)
+}
+
+func (p *sentenceTTSProvider) Synthesize(ctx context.Context, req *tts.SynthesizeRequest) (*tts.SynthesizeResponse, error) {
+	if err := p.wait(ctx, req.Text); err != nil {
+		return nil, err
+	}
+	return &tts.SynthesizeResponse{AudioData: make([]byte, 200), AudioFormat: p.StreamAudioFormat()}, nil
+}
+
+// streamingSentenceTTSProvider adds streaming to sentenceTTSProvider
+type streamingSentenceTTSProvider struct {
+	sentenceTTSProvider
+	chunks []int // chunk sizes of each sentence, default 101, 50, 49
+}
+
+func (p *streamingSentenceTTSProvider) StreamSynthesize(ctx context.Context, req *tts.SynthesizeRequest) (<-chan []byte, <-chan error) {
+	audioChan := make(chan []byte, 3)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(audioChan)
+		defer close(errChan)
+		if err := p.wait(ctx, req.Text); err != nil {
+			errChan <- err
+			return
+		}
+		chunks := p.chunks
+		if chunks == nil {
+			chunks = []int{101, 50, 49}
+		}
+		for _, n := range chunks {
+			audioChan <- make([]byte, n)
+		}
+	}()
+	return audioChan, errChan
+}
+
+func (p *sentenceTTSProvider) StreamAudioFormat() tts.AudioFormat {
+	return tts.AudioFormat{SampleRate: 16000, Channels: 1, MediaType: pipeline.AudioMediaTypePCM}
+}
+
+const segmentParagraph = "The first sentence is here. The second one follows it. Third and last sentence"
+
+func TestUniversalTTSElement_Segmentation(t *testing.T) {
+	provider := &sentenceTTSProvider{block: "Third", release: make(chan struct{})}
+	elem := NewUniversalTTSElement(provider)
+	require.NoError(t, elem.SetProperty("segment", true))
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage(segmentParagraph, "final")
+
+	// The first sentence is spoken while the last one is still being synthesized
+	select {
+	case msg := <-elem.Out():
+		assert.Len(t, msg.AudioData.Data, 200)
+		assert.True(t, msg.AudioData.EndOfSentence, "each segment is a whole sentence")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for audio of the first sentence")
+	}
+	close(provider.release)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-elem.Out():
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for audio of sentence %d", i+2)
+		}
+	}
+	assert.Equal(t, []string{
+		"The first sentence is here.",
+		"The second one follows it.",
+		"Third and last sentence",
+	}, provider.requested())
+}
+
+func TestUniversalTTSElement_SegmentationStreaming(t *testing.T) {
+	provider := &streamingSentenceTTSProvider{sentenceTTSProvider: sentenceTTSProvider{block: "Third", release: make(chan struct{})}}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetSegmentation(true)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage(segmentParagraph, "final")
+
+	// Chunks are output as they arrive, cut at whole 16-bit samples
+	received := 0
+	for received < 400 {
+		select {
+		case msg := <-elem.Out():
+			assert.Zero(t, len(msg.AudioData.Data)%2, "chunk must hold whole samples")
+			assert.Equal(t, 16000, msg.AudioData.SampleRate)
+			received += len(msg.AudioData.Data)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for streamed audio, got %d bytes", received)
+		}
+	}
+	// The last sentence is requested but held back by the provider
+	assert.Eventually(t, func() bool { return len(provider.requested()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Empty(t, elem.Out(), "last sentence is still being synthesized")
+
+	close(provider.release)
+	for received < 600 {
+		select {
+		case msg := <-elem.Out():
+			received += len(msg.AudioData.Data)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for the last sentence, got %d bytes", received)
+		}
+	}
+	assert.Equal(t, 600, received)
+}
+
+func TestUniversalTTSElement_StreamingEndOfSentence(t *testing.T) {
+	provider := &streamingSentenceTTSProvider{chunks: []int{1000, 1000, 501}}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetSegmentation(true)
+	require.NoError(t, elem.Start(context.Background()))
+	defer elem.Stop()
+
+	elem.In() <- textMessage("Only one sentence here.", "final")
+
+	// Only the last chunk of the sentence, the held-back 20ms, ends it
+	var sizes []int
+	for {
+		select {
+		case msg := <-elem.Out():
+			sizes = append(sizes, len(msg.AudioData.Data))
+			if !msg.AudioData.EndOfSentence {
+				continue
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for the end of the sentence, got %v", sizes)
+		}
+		break
+	}
+	assert.Equal(t, []int{360, 1000, 500, 640}, sizes)
+}
+
+func TestUniversalTTSElement_SegmentLength(t *testing.T) {
+	provider := &sentenceTTSProvider{}
+	elem := NewUniversalTTSElement(provider)
+	elem.SetSegmentation(true)
+	elem.SetSegmentLength(5, 30)
+	require.NoError(t, elem.Start(context.Background()))
+
+	// "Hi." is shorter than the minimum and joins the next sentence; the long
+	// sentence is split at a comma
+	elem.In() <- textMessage("Hi. How are you? I looked it up for you, and the shop opens at nine tomorrow.", "final")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, elem.Drain(ctx))
+	elem.Stop()
+
+	requests := provider.requested()
+	require.NotEmpty(t, requests)
+	assert.Equal(t, "Hi. How are you?", requests[0])
+	for _, text := range requests {
+		assert.LessOrEqual(t, len([]rune(text)), 30, "sentence %q is too long", text)
+	}
+	assert.Equal(t, "Hi. How are you? I looked it up for you, and the shop opens at nine tomorrow.", strings.Join(requests, " "))
+}
@@ -2,9 +2,9 @@ package elements
 
 import (
 	"context"
-	"encoding/binary"
 	"fmt"
 	"log"
+	"math"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -43,8 +43,25 @@ type SileroVADConfig struct {
 	SpeechPadMs     int
 	PreRollMs       int // Pre-roll buffer duration in ms (default 300ms)
 	Mode            VADMode
+
+	// EnergyFloorDB enables a cheap RMS energy gate in front of Silero: windows
+	// below this level (dBFS, e.g. -50) skip ONNX inference once the audio has
+	// stayed below it for EnergyHoldMs. 0 disables the gate.
+	EnergyFloorDB float64
+	// EnergyHoldMs is how long energy must stay below the floor before the
+	// gate closes (default 500ms). The gate reopens on the first loud window.
+	EnergyHoldMs int
+
+	// HangoverMs holds back speech end for this long after MinSilenceDurMs has
+	// elapsed. Speech resuming within the window continues the same utterance
+	// without a new speech start, so short pauses between words don't split a
+	// sentence into several STT commits. 0 disables aggregation.
+	HangoverMs int
 }
 
+// defaultEnergyHoldMs is the default energy gate hysteresis
+const defaultEnergyHoldMs = 500
+
 // SileroVADElement implements voice activity detection using Silero VAD
 type SileroVADElement struct {
 	*pipeline.BaseElement
@@ -56,6 +73,9 @@ type SileroVADElement struct {
 	speechPadMs     int
 	preRollMs       int
 	mode            VADMode
+	energyFloorDB   float64
+	energyHoldMs    int
+	hangoverMs      int
 
 	// VAD detector (interface for testability)
 	detector vad.DetectorInterface
@@ -74,6 +94,13 @@ type SileroVADElement struct {
 	currSample int
 	triggered  bool
 	tempEnd    int
+	// pendingEnd is the speech end sample waiting out the hangover window (0 = none)
+	pendingEnd int
+
+	// Energy gate state
+	quietSamples   int  // consecutive samples below the energy floor
+	gated          bool // inference is currently skipped
+	skippedWindows int64
 
 	// Lifecycle management
 	cancel context.CancelFunc
@@ -102,6 +129,18 @@ func NewSileroVADElement(config SileroVADConfig) (*SileroVADElement, error) {
 		config.PreRollMs = 300 // Default 300ms pre-roll buffer
 	}
 
+	if config.EnergyFloorDB > 0 {
+		return nil, fmt.Errorf("energy floor must be in dBFS (<= 0), got %.1f", config.EnergyFloorDB)
+	}
+
+	if config.EnergyHoldMs == 0 {
+		config.EnergyHoldMs = defaultEnergyHoldMs
+	}
+
+	if config.HangoverMs < 0 {
+		return nil, fmt.Errorf("hangover must not be negative, got %dms", config.HangoverMs)
+	}
+
 	elem := &SileroVADElement{
 		BaseElement:      pipeline.NewBaseElement("silero-vad-element", 100),
 		modelPath:        config.ModelPath,
@@ -110,6 +149,9 @@ func NewSileroVADElement(config SileroVADConfig) (*SileroVADElement, error) {
 		speechPadMs:      config.SpeechPadMs,
 		preRollMs:        config.PreRollMs,
 		mode:             config.Mode,
+		energyFloorDB:    config.EnergyFloorDB,
+		energyHoldMs:     config.EnergyHoldMs,
+		hangoverMs:       config.HangoverMs,
 		audioBuffer:      make([]float32, 0, 1024),
 		processedSamples: 0,
 		preRollBuffer:    audio.NewRingBuffer(16000, config.PreRollMs), // 16kHz sample rate
@@ -155,6 +197,27 @@ func (e *SileroVADElement) registerProperties() error {
 			Readable: true,
 			Default:  e.speechPadMs,
 		},
+		{
+			Name:     "energy-floor-db",
+			Type:     reflect.TypeOf(float64(0)),
+			Writable: true,
+			Readable: true,
+			Default:  e.energyFloorDB,
+		},
+		{
+			Name:     "energy-hold-ms",
+			Type:     reflect.TypeOf(int(0)),
+			Writable: true,
+			Readable: true,
+			Default:  e.energyHoldMs,
+		},
+		{
+			Name:     "hangover-ms",
+			Type:     reflect.TypeOf(int(0)),
+			Writable: true,
+			Readable: true,
+			Default:  e.hangoverMs,
+		},
 	}
 
 	for _, prop := range props {
@@ -184,9 +247,12 @@ func (e *SileroVADElement) Init(ctx context.Context) error {
 	e.currSample = 0
 	e.triggered = false
 	e.tempEnd = 0
+	e.pendingEnd = 0
+	e.quietSamples = 0
+	e.gated = false
 
-	log.Printf("[SileroVAD] Initialized with threshold=%.2f, minSilence=%dms, speechPad=%dms, preRoll=%dms, mode=%d",
-		e.threshold, e.minSilenceDurMs, e.speechPadMs, e.preRollMs, e.mode)
+	log.Printf("[SileroVAD] Initialized with threshold=%.2f, minSilence=%dms, speechPad=%dms, preRoll=%dms, hangover=%dms, mode=%d",
+		e.threshold, e.minSilenceDurMs, e.speechPadMs, e.preRollMs, e.hangoverMs, e.mode)
 
 	return nil
 }
@@ -242,6 +308,11 @@ func (e *SileroVADElement) processAudio(ctx context.Context) {
 				continue
 			}
 
+			if msg.AudioData.Format().BytesPerSample() == 0 {
+				log.Printf("[SileroVAD] Skipping unsupported sample format: %s", msg.AudioData.SampleFormat)
+				continue
+			}
+
 			// Verify sample rate is 16kHz
 			if msg.AudioData.SampleRate != 16000 {
 				log.Printf("[SileroVAD] Warning: Expected 16kHz audio, got %dHz. Please add AudioResampleElement before VAD.",
@@ -257,11 +328,18 @@ func (e *SileroVADElement) processAudio(ctx context.Context) {
 
 // handleAudioData processes a single audio message
 func (e *SileroVADElement) handleAudioData(ctx context.Context, msg *pipeline.PipelineMessage) {
-	// Write raw audio to pre-roll buffer (before any processing)
-	e.preRollBuffer.Write(msg.AudioData.Data)
-
-	// Convert byte data to normalized float32 samples in [-1, 1]
-	samples := e.bytesToFloat32(msg.AudioData.Data)
+	// Convert byte data to normalized float32 samples in [-1, 1].
+	// Float32 input is decoded directly, without a round-trip through int16.
+	format := msg.AudioData.Format()
+	samples := audio.BytesToFloat32(msg.AudioData.Data, format)
+
+	// Write raw audio to pre-roll buffer (before any processing).
+	// Pre-roll audio is always 16-bit PCM for the STT elements.
+	if format == pipeline.SampleFormatS16 {
+		e.preRollBuffer.Write(msg.AudioData.Data)
+	} else {
+		e.preRollBuffer.Write(audio.Float32ToBytes(samples, pipeline.SampleFormatS16))
+	}
 
 	// Add samples to buffer
 	e.stateLock.Lock()
@@ -290,13 +368,25 @@ func (e *SileroVADElement) handleAudioData(ctx context.Context, msg *pipeline.Pi
 		e.processedSamples += int64(windowSize)
 		// Copy threshold under lock for consistent read
 		threshold := e.threshold
+		energyFloorDB := e.energyFloorDB
+		holdSamples := e.energyHoldMs * sampleRate / 1000
+		hangoverSamples := e.hangoverMs * sampleRate / 1000
 		e.stateLock.Unlock()
 
-		// Run inference to get speech probability
-		speechProb, err := e.detector.Infer(window)
-		if err != nil {
-			log.Printf("[SileroVAD] Infer error: %v", err)
-			continue
+		// Run inference to get speech probability, unless the energy gate
+		// says the window is silence
+		var speechProb float32
+		if e.energyGate(window, energyFloorDB, holdSamples) {
+			e.stateLock.Lock()
+			e.skippedWindows++
+			e.stateLock.Unlock()
+		} else {
+			prob, err := e.detector.Infer(window)
+			if err != nil {
+				log.Printf("[SileroVAD] Infer error: %v", err)
+				continue
+			}
+			speechProb = prob
 		}
 
 		e.currSample += windowSize
@@ -308,6 +398,11 @@ func (e *SileroVADElement) handleAudioData(ctx context.Context, msg *pipeline.Pi
 
 		if speechProb >= threshold && !e.triggered {
 			e.triggered = true
+			if e.pendingEnd != 0 {
+				// Speech resumed within the hangover window: same utterance
+				log.Printf("[SileroVAD] Pause bridged (%dms)", (e.currSample-windowSize-e.pendingEnd)*1000/sampleRate)
+				e.pendingEnd = 0
+			}
 			speechStartSample := e.currSample - windowSize - speechPadSamples
 			if speechStartSample < 0 {
 				speechStartSample = 0
@@ -328,18 +423,24 @@ func (e *SileroVADElement) handleAudioData(ctx context.Context, msg *pipeline.Pi
 
 			// Check if enough silence has passed
 			if e.currSample-e.tempEnd >= minSilenceSamples {
-				speechEndSample := e.tempEnd + speechPadSamples
-				speechEndMs := speechEndSample * 1000 / sampleRate
+				endSample := e.tempEnd
 				e.tempEnd = 0
 				e.triggered = false
 
-				if e.isSpeaking.Load() {
-					e.isSpeaking.Store(false)
-					e.emitEvent(pipeline.EventVADSpeechEnd, msg.SessionID, speechProb, speechEndMs)
-					log.Printf("[SileroVAD] Speech ended (endMs=%d, prob=%.3f)", speechEndMs, speechProb)
+				if hangoverSamples > 0 {
+					// Hold speech end until the hangover window runs out
+					e.pendingEnd = endSample
+				} else {
+					e.endSpeech(msg.SessionID, speechProb, endSample+speechPadSamples)
 				}
 			}
 		}
+
+		if e.pendingEnd != 0 && e.currSample-e.pendingEnd >= minSilenceSamples+hangoverSamples {
+			endSample := e.pendingEnd
+			e.pendingEnd = 0
+			e.endSpeech(msg.SessionID, speechProb, endSample+speechPadSamples)
+		}
 	}
 
 	// Handle output based on mode
@@ -364,6 +465,68 @@ func (e *SileroVADElement) handleAudioData(ctx context.Context, msg *pipeline.Pi
 	}
 }
 
+// endSpeech emits speech end at speechEndSample if speech is in progress
+func (e *SileroVADElement) endSpeech(sessionID string, prob float32, speechEndSample int) {
+	if !e.isSpeaking.Load() {
+		return
+	}
+	speechEndMs := speechEndSample * 1000 / 16000
+	e.isSpeaking.Store(false)
+	e.emitEvent(pipeline.EventVADSpeechEnd, sessionID, prob, speechEndMs)
+	log.Printf("[SileroVAD] Speech ended (endMs=%d, prob=%.3f)", speechEndMs, prob)
+}
+
+// energyGate reports whether inference can be skipped for window. The gate
+// closes once energy has stayed below the floor for holdSamples and never
+// while speech is in progress; it reopens as soon as a window exceeds the floor.
+func (e *SileroVADElement) energyGate(window []float32, floorDB float64, holdSamples int) bool {
+	if floorDB >= 0 {
+		e.quietSamples = 0
+		e.gated = false
+		return false
+	}
+
+	if windowDBFS(window) >= floorDB {
+		e.quietSamples = 0
+		if e.gated {
+			e.gated = false
+			log.Printf("[SileroVAD] Energy gate opened")
+		}
+		return false
+	}
+
+	e.quietSamples += len(window)
+	if e.triggered || e.quietSamples < holdSamples {
+		return false
+	}
+
+	if !e.gated {
+		e.gated = true
+		// Silero 是有状态模型，跳过推理后从干净的状态重新开始
+		if err := e.detector.Reset(); err != nil {
+			log.Printf("[SileroVAD] Reset error: %v", err)
+		}
+		log.Printf("[SileroVAD] Energy gate closed (below %.1f dBFS for %dms)", floorDB, e.quietSamples*1000/16000)
+	}
+	return true
+}
+
+// windowDBFS returns the RMS level of normalized samples in dBFS.
+func windowDBFS(window []float32) float64 {
+	if len(window) == 0 {
+		return math.Inf(-1)
+	}
+	var sum float64
+	for _, s := range window {
+		sum += float64(s) * float64(s)
+	}
+	rms := math.Sqrt(sum / float64(len(window)))
+	if rms == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(rms)
+}
+
 // emitEvent emits a VAD event to the bus
 func (e *SileroVADElement) emitEvent(eventType pipeline.EventType, sessionID string, confidence float32, audioMs int) {
 	if e.Bus() == nil {
@@ -396,13 +559,7 @@ func (e *SileroVADElement) emitEvent(eventType pipeline.EventType, sessionID str
 
 // bytesToFloat32 converts 16-bit PCM (little-endian) to normalized float32 in [-1, 1].
 func (e *SileroVADElement) bytesToFloat32(data []byte) []float32 {
-	n := len(data) / 2
-	samples := make([]float32, n)
-	for i := 0; i < n; i++ {
-		v := int16(binary.LittleEndian.Uint16(data[i*2 : i*2+2]))
-		samples[i] = float32(v) / 32768.0
-	}
-	return samples
+	return audio.BytesToFloat32(data, pipeline.SampleFormatS16)
 }
 
 // SetThreshold updates the VAD threshold
@@ -416,6 +573,7 @@ func (e *SileroVADElement) SetThreshold(threshold float32) error {
 	e.currSample = 0
 	e.triggered = false
 	e.tempEnd = 0
+	e.pendingEnd = 0
 	e.stateLock.Unlock()
 
 	if e.detector != nil {
@@ -424,6 +582,61 @@ func (e *SileroVADElement) SetThreshold(threshold float32) error {
 	return nil
 }
 
+// ResetInput discards a partially captured utterance without emitting
+// speech end, so downstream STT never commits it. Implements
+// pipeline.InputResetter for Pipeline.MuteInput.
+func (e *SileroVADElement) ResetInput() {
+	e.stateLock.Lock()
+	e.audioBuffer = e.audioBuffer[:0]
+	e.triggered = false
+	e.tempEnd = 0
+	e.pendingEnd = 0
+	e.quietSamples = 0
+	e.gated = false
+	e.stateLock.Unlock()
+
+	if e.isSpeaking.Swap(false) {
+		log.Printf("[SileroVAD] Input reset, dropped utterance in progress")
+	}
+	e.preRollBuffer.Clear()
+	if e.detector != nil {
+		e.detector.Reset()
+	}
+}
+
+// SetEnergyGate updates the energy gate floor (dBFS, 0 disables) and hold time.
+func (e *SileroVADElement) SetEnergyGate(floorDB float64, holdMs int) error {
+	if floorDB > 0 {
+		return fmt.Errorf("energy floor must be in dBFS (<= 0)")
+	}
+	if holdMs < 0 {
+		return fmt.Errorf("energy hold must not be negative")
+	}
+	e.stateLock.Lock()
+	e.energyFloorDB = floorDB
+	e.energyHoldMs = holdMs
+	e.stateLock.Unlock()
+	return nil
+}
+
+// SetHangover updates how long speech end is held back to bridge short pauses (0 disables).
+func (e *SileroVADElement) SetHangover(hangoverMs int) error {
+	if hangoverMs < 0 {
+		return fmt.Errorf("hangover must not be negative")
+	}
+	e.stateLock.Lock()
+	e.hangoverMs = hangoverMs
+	e.stateLock.Unlock()
+	return nil
+}
+
+// SkippedWindows returns how many windows the energy gate kept from inference.
+func (e *SileroVADElement) SkippedWindows() int64 {
+	e.stateLock.Lock()
+	defer e.stateLock.Unlock()
+	return e.skippedWindows
+}
+
 // GetIsSpeaking returns whether speech is currently detected
 func (e *SileroVADElement) GetIsSpeaking() bool {
 	return e.isSpeaking.Load()
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/binary"
 	"math"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 	"github.com/realtime-ai/realtime-ai/pkg/vad"
 	"github.com/stretchr/testify/assert"
@@ -293,6 +295,65 @@ func TestBytesToFloat32(t *testing.T) {
 	})
 }
 
+// windowRecorder records the windows passed to Infer
+type windowRecorder struct {
+	mu      sync.Mutex
+	windows [][]float32
+}
+
+func (r *windowRecorder) Infer(samples []float32) (float32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.windows = append(r.windows, append([]float32(nil), samples...))
+	return 0, nil
+}
+
+func (r *windowRecorder) Reset() error   { return nil }
+func (r *windowRecorder) Destroy() error { return nil }
+
+// TestVADElementFloat32Input tests that float32 audio reaches the detector without int16 quantization
+func TestVADElementFloat32Input(t *testing.T) {
+	elem, err := NewSileroVADElement(SileroVADConfig{ModelPath: "test_model.onnx"})
+	require.NoError(t, err)
+
+	recorder := &windowRecorder{}
+	elem.SetDetector(recorder)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, elem.Init(ctx))
+	require.NoError(t, elem.Start(ctx))
+	defer elem.Stop()
+
+	// Values that are not representable as int16/32768
+	samples := make([]float32, 512)
+	for i := range samples {
+		samples[i] = 0.123456789 * float32(i%7-3) / 3
+	}
+	elem.In() <- &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:         audio.Float32ToBytes(samples, pipeline.SampleFormatF32),
+			SampleRate:   16000,
+			Channels:     1,
+			MediaType:    pipeline.AudioMediaTypeRaw,
+			SampleFormat: pipeline.SampleFormatF32,
+		},
+	}
+
+	select {
+	case out := <-elem.Out():
+		assert.Equal(t, pipeline.SampleFormatF32, out.AudioData.SampleFormat)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected output message in passthrough mode")
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.windows, 1)
+	assert.Equal(t, samples, recorder.windows[0])
+}
+
 // TestSetThreshold tests threshold setting
 func TestSetThreshold(t *testing.T) {
 	config := SileroVADConfig{
@@ -506,3 +567,250 @@ func TestVADElementSpeechDetection(t *testing.T) {
 
 	assert.True(t, speechStartReceived, "Should receive speech start event")
 }
+
+// generateNoise generates low-level noise at roughly the given amplitude
+func generateNoise(numSamples int, amplitude int16) []byte {
+	data := make([]byte, numSamples*2)
+	for i := 0; i < numSamples; i++ {
+		sample := amplitude
+		if i%2 == 1 {
+			sample = -amplitude
+		}
+		binary.LittleEndian.PutUint16(data[i*2:i*2+2], uint16(sample))
+	}
+	return data
+}
+
+// vadAudioMessage wraps 16kHz mono PCM in a pipeline message
+func vadAudioMessage(data []byte) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type:      pipeline.MsgTypeAudio,
+		SessionID: "test-session",
+		AudioData: &pipeline.AudioData{
+			Data:       data,
+			SampleRate: 16000,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+}
+
+// TestVADElementEnergyGate tests that silence skips inference after the hold time
+func TestVADElementEnergyGate(t *testing.T) {
+	elem, err := NewSileroVADElement(SileroVADConfig{
+		ModelPath:     "test_model.onnx",
+		Mode:          VADModeFilter,
+		EnergyFloorDB: -50,
+		EnergyHoldMs:  200,
+	})
+	require.NoError(t, err)
+
+	mockDetector := vad.NewMockDetector()
+	elem.SetDetector(mockDetector)
+	ctx := context.Background()
+	require.NoError(t, elem.Init(ctx))
+
+	// 1s of silence: the first 6 windows (192ms) are below the 200ms hold,
+	// the remaining 25 are gated
+	elem.handleAudioData(ctx, vadAudioMessage(generateSilence(16000)))
+	assert.Equal(t, 6, mockDetector.GetInferCallCount())
+	assert.Equal(t, int64(25), elem.SkippedWindows())
+	assert.True(t, mockDetector.ResetCalled)
+
+	// Loud audio reopens the gate immediately
+	elem.handleAudioData(ctx, vadAudioMessage(generateTone(512*3, 440, 16000)))
+	assert.Equal(t, 9, mockDetector.GetInferCallCount())
+	assert.Equal(t, int64(25), elem.SkippedWindows())
+}
+
+// TestVADElementEnergyGateDuringSpeech tests that the gate never closes mid-speech
+func TestVADElementEnergyGateDuringSpeech(t *testing.T) {
+	elem, err := NewSileroVADElement(SileroVADConfig{
+		ModelPath:     "test_model.onnx",
+		Mode:          VADModeFilter,
+		EnergyFloorDB: -50,
+		EnergyHoldMs:  100,
+	})
+	require.NoError(t, err)
+
+	mockDetector := vad.NewMockDetectorWithProb(0.9)
+	elem.SetDetector(mockDetector)
+	ctx := context.Background()
+	require.NoError(t, elem.Init(ctx))
+
+	elem.handleAudioData(ctx, vadAudioMessage(generateTone(512, 440, 16000)))
+	require.True(t, elem.GetIsSpeaking())
+
+	elem.handleAudioData(ctx, vadAudioMessage(generateSilence(512*10)))
+	assert.Equal(t, 11, mockDetector.GetInferCallCount())
+	assert.Equal(t, int64(0), elem.SkippedWindows())
+}
+
+// TestVADElementEnergyGateDisabled tests that the gate is off by default
+func TestVADElementEnergyGateDisabled(t *testing.T) {
+	elem, err := NewSileroVADElement(SileroVADConfig{ModelPath: "test_model.onnx", Mode: VADModeFilter})
+	require.NoError(t, err)
+
+	mockDetector := vad.NewMockDetector()
+	elem.SetDetector(mockDetector)
+	ctx := context.Background()
+	require.NoError(t, elem.Init(ctx))
+
+	elem.handleAudioData(ctx, vadAudioMessage(generateSilence(512*20)))
+	assert.Equal(t, 20, mockDetector.GetInferCallCount())
+	assert.Equal(t, int64(0), elem.SkippedWindows())
+
+	_, err = NewSileroVADElement(SileroVADConfig{ModelPath: "test_model.onnx", EnergyFloorDB: 10})
+	assert.Error(t, err)
+	assert.Error(t, elem.SetEnergyGate(3, 100))
+}
+
+// BenchmarkVADElementEnergyGate compares inference calls on mostly silent
+// input (10s of background noise with 0.5s of speech) with and without the gate.
+func BenchmarkVADElementEnergyGate(b *testing.B) {
+	var input []byte
+	input = append(input, generateNoise(16000*5, 20)...) // ~-64 dBFS
+	input = append(input, generateTone(8000, 440, 16000)...)
+	input = append(input, generateNoise(16000*5, 20)...)
+
+	for _, bc := range []struct {
+		name    string
+		floorDB float64
+	}{
+		{"disabled", 0},
+		{"floor-50dB", -50},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var infers int
+			for i := 0; i < b.N; i++ {
+				elem, err := NewSileroVADElement(SileroVADConfig{
+					ModelPath:     "test_model.onnx",
+					Mode:          VADModeFilter,
+					EnergyFloorDB: bc.floorDB,
+				})
+				require.NoError(b, err)
+				mockDetector := vad.NewMockDetector()
+				elem.SetDetector(mockDetector)
+				require.NoError(b, elem.Init(context.Background()))
+
+				// Feed 20ms chunks as a transport would
+				for off := 0; off < len(input); off += 640 {
+					elem.handleAudioData(context.Background(), vadAudioMessage(input[off:off+640]))
+				}
+				infers += mockDetector.GetInferCallCount()
+			}
+			b.ReportMetric(float64(infers)/float64(b.N), "infers/op")
+		})
+	}
+}
+
+// energyDetector is a detector that reports speech for loud windows
+func energyDetector() *vad.MockDetector {
+	d := vad.NewMockDetector()
+	d.InferFunc = func(samples []float32) (float32, error) {
+		if windowDBFS(samples) > -40 {
+			return 0.9, nil
+		}
+		return 0.05, nil
+	}
+	return d
+}
+
+// countVADEvents feeds a sentence with a 200ms mid-pause followed by trailing
+// silence and returns the number of speech start and end events
+func countVADEvents(t *testing.T, hangoverMs int) (starts, ends int) {
+	elem, err := NewSileroVADElement(SileroVADConfig{
+		ModelPath:       "test_model.onnx",
+		MinSilenceDurMs: 100,
+		HangoverMs:      hangoverMs,
+		Mode:            VADModeFilter,
+	})
+	require.NoError(t, err)
+	elem.SetDetector(energyDetector())
+
+	bus := pipeline.NewEventBus()
+	events := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventVADSpeechStart, events)
+	bus.Subscribe(pipeline.EventVADSpeechEnd, events)
+	elem.SetBus(bus)
+
+	ctx := context.Background()
+	require.NoError(t, elem.Init(ctx))
+
+	var input []byte
+	input = append(input, generateTone(8000, 440, 16000)...) // 500ms
+	input = append(input, generateSilence(3200)...)          // 200ms pause
+	input = append(input, generateTone(8000, 440, 16000)...) // 500ms
+	input = append(input, generateSilence(16000)...)         // 1s
+	for off := 0; off < len(input); off += 640 {
+		elem.handleAudioData(ctx, vadAudioMessage(input[off:off+640]))
+	}
+
+	for len(events) > 0 {
+		switch (<-events).Type {
+		case pipeline.EventVADSpeechStart:
+			starts++
+		case pipeline.EventVADSpeechEnd:
+			ends++
+		}
+	}
+	return starts, ends
+}
+
+// TestVADElementHangover tests that a short mid-sentence pause stays one utterance
+func TestVADElementHangover(t *testing.T) {
+	starts, ends := countVADEvents(t, 0)
+	assert.Equal(t, 2, starts, "without hangover the pause splits the sentence")
+	assert.Equal(t, 2, ends)
+
+	starts, ends = countVADEvents(t, 300)
+	assert.Equal(t, 1, starts, "hangover should bridge the 200ms pause")
+	assert.Equal(t, 1, ends, "speech should still end after the trailing silence")
+
+	_, err := NewSileroVADElement(SileroVADConfig{ModelPath: "test_model.onnx", HangoverMs: -1})
+	assert.Error(t, err)
+}
+
+func TestVADElementResetInput(t *testing.T) {
+	elem, err := NewSileroVADElement(SileroVADConfig{
+		ModelPath:       "test_model.onnx",
+		MinSilenceDurMs: 100,
+		Mode:            VADModeFilter,
+	})
+	require.NoError(t, err)
+	elem.SetDetector(energyDetector())
+
+	bus := pipeline.NewEventBus()
+	events := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventVADSpeechStart, events)
+	bus.Subscribe(pipeline.EventVADSpeechEnd, events)
+	elem.SetBus(bus)
+
+	ctx := context.Background()
+	require.NoError(t, elem.Init(ctx))
+
+	feed := func(input []byte) {
+		for off := 0; off < len(input); off += 640 {
+			elem.handleAudioData(ctx, vadAudioMessage(input[off:off+640]))
+		}
+	}
+
+	// Half an utterance, then the input is muted
+	feed(generateTone(8000, 440, 16000))
+	require.True(t, elem.GetIsSpeaking())
+	require.Equal(t, pipeline.EventVADSpeechStart, (<-events).Type)
+
+	elem.ResetInput()
+	assert.False(t, elem.GetIsSpeaking())
+
+	// Silence after unmute must not end the dropped utterance
+	feed(generateSilence(16000))
+	assert.Empty(t, events, "reset utterance should not produce speech end")
+
+	// The next utterance is detected from a clean state
+	feed(generateTone(8000, 440, 16000))
+	feed(generateSilence(16000))
+	require.Len(t, events, 2)
+	assert.Equal(t, pipeline.EventVADSpeechStart, (<-events).Type)
+	assert.Equal(t, pipeline.EventVADSpeechEnd, (<-events).Type)
+}
@@ -15,9 +15,12 @@ import (
 
 // Ensure WhisperSTTElement implements pipeline.Element
 var _ pipeline.Element = (*WhisperSTTElement)(nil)
+var _ pipeline.InputResetter = (*WhisperSTTElement)(nil)
 
 // WhisperSTTElement implements speech-to-text using OpenAI Whisper API.
 // It can work standalone or integrate with VAD for optimized recognition.
+// Each result is also published as EventTranscriptResult, with segment and word
+// timings when WhisperSTTConfig.Timestamps is set.
 type WhisperSTTElement struct {
 	*pipeline.BaseElement
 
@@ -29,7 +32,10 @@ type WhisperSTTElement struct {
 	model               string
 	enablePartialResults bool
 	prompt              string
+	promptFunc          func() string
 	temperature         float32
+	task                string
+	timestamps          string
 
 	// Audio configuration
 	sampleRate    int
@@ -50,7 +56,11 @@ type WhisperSTTElement struct {
 	recognizer     asr.StreamingRecognizer
 	recognizerLock sync.Mutex
 
+	// Attributes of the latest input audio, attached to recognition results
+	attrs pipeline.AttributeTracker
+
 	// Lifecycle management
+	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
@@ -61,7 +71,8 @@ type WhisperSTTConfig struct {
 	APIKey string
 
 	// Language code (e.g., "en", "zh", "auto" for auto-detection)
-	// Leave empty for auto-detection
+	// Leave empty to use the pipeline LanguageContext source language,
+	// or auto-detection if none is set
 	Language string
 
 	// Model to use (default: "whisper-1")
@@ -73,9 +84,26 @@ type WhisperSTTConfig struct {
 	// Prompt provides context to guide the recognition
 	Prompt string
 
+	// PromptFunc, if set, is evaluated before each transcription and overrides
+	// Prompt, so the prompt can carry dynamic context such as the recent
+	// conversation or expected vocabulary
+	PromptFunc func() string
+
 	// Temperature for sampling (0.0-1.0, default: 0.0)
 	Temperature float32
 
+	// Task is "transcribe" (default) or "translate".
+	// "translate" uses OpenAI's audio/translations endpoint, which transcribes and
+	// translates to English in one call; Language then describes the source audio.
+	// Useful for English-target interpretation without a separate translate element.
+	Task string
+
+	// Timestamps requests timings from Whisper's verbose_json response:
+	// asr.TimestampsSegment for the start and end of each result,
+	// asr.TimestampsWord for word timings as well. They are published in the
+	// EventTranscriptResult payload (default: "", none; requires whisper-1)
+	Timestamps string
+
 	// VADEnabled determines if element should listen to VAD events
 	// When true, recognition is triggered by VAD speech start/end events
 	// When false, recognition runs continuously on buffered audio
@@ -89,6 +117,10 @@ type WhisperSTTConfig struct {
 
 	// BitsPerSample (default: 16)
 	BitsPerSample int
+
+	// Headers are extra headers sent on every provider request (API gateway
+	// keys, org IDs, tracing headers)
+	Headers map[string]string
 }
 
 // NewWhisperSTTElement creates a new Whisper STT element.
@@ -108,6 +140,9 @@ func NewWhisperSTTElement(config WhisperSTTConfig) (*WhisperSTTElement, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Whisper provider: %w", err)
 	}
+	if len(config.Headers) > 0 {
+		provider.SetHeaders(config.Headers)
+	}
 
 	// Set defaults
 	if config.Model == "" {
@@ -122,6 +157,13 @@ func NewWhisperSTTElement(config WhisperSTTConfig) (*WhisperSTTElement, error) {
 	if config.BitsPerSample == 0 {
 		config.BitsPerSample = 16
 	}
+	if config.Task == "" {
+		config.Task = asr.WhisperTaskTranscribe
+	}
+	if config.Task != asr.WhisperTaskTranscribe && config.Task != asr.WhisperTaskTranslate {
+		return nil, fmt.Errorf("unsupported task %q (expected %q or %q)",
+			config.Task, asr.WhisperTaskTranscribe, asr.WhisperTaskTranslate)
+	}
 
 	elem := &WhisperSTTElement{
 		BaseElement:          pipeline.NewBaseElement("whisper-stt", 100),
@@ -130,7 +172,10 @@ func NewWhisperSTTElement(config WhisperSTTConfig) (*WhisperSTTElement, error) {
 		model:                config.Model,
 		enablePartialResults: config.EnablePartialResults,
 		prompt:               config.Prompt,
+		promptFunc:           config.PromptFunc,
 		temperature:          config.Temperature,
+		task:                 config.Task,
+		timestamps:           config.Timestamps,
 		vadEnabled:           config.VADEnabled,
 		sampleRate:           config.SampleRate,
 		channels:             config.Channels,
@@ -182,10 +227,11 @@ func (e *WhisperSTTElement) registerProperties() {
 // Start starts the Whisper STT element.
 func (e *WhisperSTTElement) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
+	e.ctx = ctx
 	e.cancel = cancel
 
-	log.Printf("[WhisperSTT] Starting element (VAD: %v, Language: %s, Model: %s)",
-		e.vadEnabled, e.language, e.model)
+	log.Printf("[WhisperSTT] Starting element (VAD: %v, Language: %s, Model: %s, Task: %s)",
+		e.vadEnabled, e.language, e.model, e.task)
 
 	// Subscribe to VAD events if VAD is enabled
 	if e.vadEnabled && e.BaseElement.Bus() != nil {
@@ -270,11 +316,14 @@ func (e *WhisperSTTElement) startRecognizer(ctx context.Context) error {
 	}
 
 	recognitionConfig := asr.RecognitionConfig{
-		Language:             e.language,
+		Language:             e.recognitionLanguage(),
 		Model:                e.model,
 		EnablePartialResults: e.enablePartialResults,
 		Prompt:               e.prompt,
+		PromptFunc:           e.promptFunc,
 		Temperature:          e.temperature,
+		Task:                 e.task,
+		Timestamps:           e.timestamps,
 	}
 
 	recognizer, err := e.provider.StreamingRecognize(ctx, audioConfig, recognitionConfig)
@@ -305,6 +354,7 @@ func (e *WhisperSTTElement) processAudio(ctx context.Context) {
 			if msg.Type != pipeline.MsgTypeAudio || msg.AudioData == nil {
 				continue
 			}
+			e.attrs.Track(msg)
 
 			// Validate audio format
 			if msg.AudioData.SampleRate != e.sampleRate {
@@ -392,6 +442,64 @@ func (e *WhisperSTTElement) handleVADEvents(ctx context.Context) {
 	}
 }
 
+// ResetInput drops the utterance in progress without committing it.
+// Audio already streamed to the recognizer can't be withdrawn, so the
+// recognizer is replaced in the background. Implements pipeline.InputResetter.
+func (e *WhisperSTTElement) ResetInput() {
+	e.speakingMutex.Lock()
+	uncommitted := e.isSpeaking || !e.vadEnabled
+	e.isSpeaking = false
+	e.speakingMutex.Unlock()
+
+	e.audioBufferLock.Lock()
+	e.audioBuffer = e.audioBuffer[:0]
+	e.audioBufferLock.Unlock()
+
+	ctx := e.ctx
+	if !uncommitted || ctx == nil || ctx.Err() != nil {
+		return
+	}
+
+	log.Printf("[WhisperSTT] Input reset, restarting recognizer")
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.restartRecognizer(ctx)
+	}()
+}
+
+// restartRecognizer closes the current recognizer, discarding its pending
+// audio, and starts a fresh one with its own result handler.
+func (e *WhisperSTTElement) restartRecognizer(ctx context.Context) {
+	e.recognizerLock.Lock()
+	old := e.recognizer
+	e.recognizer = nil
+	e.recognizerLock.Unlock()
+
+	// The old handleResults exits once the closed recognizer's results channel closes
+	if old != nil {
+		old.Close()
+	}
+
+	if err := e.startRecognizer(ctx); err != nil {
+		log.Printf("[WhisperSTT] Failed to restart recognizer: %v", err)
+		return
+	}
+	if ctx.Err() != nil {
+		// Stopped while reconnecting
+		e.recognizerLock.Lock()
+		if e.recognizer != nil {
+			e.recognizer.Close()
+			e.recognizer = nil
+		}
+		e.recognizerLock.Unlock()
+		return
+	}
+
+	e.wg.Add(1)
+	go e.handleResults(ctx)
+}
+
 // sendAudioToRecognizer sends audio data to the streaming recognizer.
 func (e *WhisperSTTElement) sendAudioToRecognizer(ctx context.Context, audioData []byte) {
 	e.recognizerLock.Lock()
@@ -419,12 +527,30 @@ func (e *WhisperSTTElement) recognizeBufferedAudio(ctx context.Context) {
 	copy(audioData, e.audioBuffer)
 	e.audioBufferLock.Unlock()
 
+	e.ProviderLogger().Log(pipeline.ProviderRecord{
+		Kind:     pipeline.ProviderSTT,
+		Provider: e.provider.Name(),
+		Element:  e.GetName(),
+		Model:    e.model,
+		Language: e.recognitionLanguage(),
+		Audio:    audioData,
+	})
+
 	// Send to recognizer
 	e.sendAudioToRecognizer(ctx, audioData)
 
 	log.Printf("[WhisperSTT] Sent %d bytes of buffered audio for recognition", len(audioData))
 }
 
+// recognitionLanguage returns the configured language, falling back to the
+// pipeline LanguageContext source language.
+func (e *WhisperSTTElement) recognitionLanguage() string {
+	if e.language != "" {
+		return e.language
+	}
+	return e.LanguageContext().Source()
+}
+
 // handleResults processes recognition results from the streaming recognizer.
 func (e *WhisperSTTElement) handleResults(ctx context.Context) {
 	defer e.wg.Done()
@@ -460,20 +586,36 @@ func (e *WhisperSTTElement) handleResults(ctx context.Context) {
 				continue
 			}
 
+			// Let downstream elements follow the spoken language
+			if e.task != asr.WhisperTaskTranslate {
+				e.LanguageContext().SetDetected(result.Language)
+			}
+
 			// Determine text type
 			textType := "text/partial"
 			eventType := pipeline.EventPartialResult
 			if result.IsFinal {
 				textType = "text/final"
 				eventType = pipeline.EventFinalResult
+				e.ProviderLogger().Log(pipeline.ProviderRecord{
+					Kind:     pipeline.ProviderSTT,
+					Provider: e.provider.Name(),
+					Element:  e.GetName(),
+					Response: true,
+					Model:    e.model,
+					Language: result.Language,
+					Text:     result.Text,
+				})
 			}
 
 			log.Printf("[WhisperSTT] Recognition result (%s): %s", textType, result.Text)
 
 			// Create text data message
+			attrs := e.attrs.Attributes()
 			textMsg := &pipeline.PipelineMessage{
-				Type:      pipeline.MsgTypeData,
-				Timestamp: time.Now(),
+				Type:       pipeline.MsgTypeData,
+				Timestamp:  time.Now(),
+				Attributes: attrs,
 				TextData: &pipeline.TextData{
 					Data:      []byte(result.Text),
 					TextType:  textType,
@@ -491,10 +633,12 @@ func (e *WhisperSTTElement) handleResults(ctx context.Context) {
 			// Publish event to bus
 			if e.BaseElement.Bus() != nil {
 				e.BaseElement.Bus().Publish(pipeline.Event{
-					Type:      eventType,
-					Timestamp: result.Timestamp,
-					Payload:   result.Text,
+					Type:       eventType,
+					Timestamp:  result.Timestamp,
+					Payload:    result.Text,
+					Attributes: attrs,
 				})
+				publishTranscriptResult(e.BaseElement.Bus(), e.GetName(), result, attrs)
 			}
 		}
 	}
@@ -0,0 +1,85 @@
+package elements
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhisperSTTElement_TranscriptResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"Hello there.",
+			"segments":[{"start":0.5,"end":1.75,"text":"Hello there."}],
+			"words":[{"word":"Hello","start":0.5,"end":0.9},{"word":"there","start":1.0,"end":1.75}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	elem, err := NewWhisperSTTElement(WhisperSTTConfig{
+		APIKey:     "test-api-key",
+		Timestamps: asr.TimestampsWord,
+	})
+	require.NoError(t, err)
+
+	bus := pipeline.NewEventBus()
+	finals := make(chan pipeline.Event, 10)
+	transcripts := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventFinalResult, finals)
+	bus.Subscribe(pipeline.EventTranscriptResult, transcripts)
+	elem.SetBus(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	require.NoError(t, elem.Start(ctx))
+	defer elem.Stop()
+
+	// 10s of audio fills the recognizer buffer, so each chunk is recognized at once
+	for i := 0; i < 2; i++ {
+		elem.In() <- &pipeline.PipelineMessage{
+			Type: pipeline.MsgTypeAudio,
+			AudioData: &pipeline.AudioData{
+				Data:       make([]byte, 16000*2*10),
+				SampleRate: 16000,
+				Channels:   1,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+			},
+		}
+	}
+
+	// Timings of the second chunk are offset by the first chunk's 10s
+	for _, offset := range []time.Duration{0, 10 * time.Second} {
+		select {
+		case evt := <-finals:
+			assert.Equal(t, "Hello there.", evt.Payload)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for final result")
+		}
+
+		select {
+		case evt := <-transcripts:
+			result, ok := evt.Payload.(*pipeline.TranscriptResult)
+			require.True(t, ok, "Unexpected payload %T", evt.Payload)
+			assert.Equal(t, "whisper-stt", result.Source)
+			assert.Equal(t, "Hello there.", result.Text)
+			assert.True(t, result.IsFinal)
+			assert.True(t, result.HasTimings())
+			assert.Equal(t, (offset + 500*time.Millisecond).Milliseconds(), result.StartMs)
+			assert.Equal(t, (offset + 1750*time.Millisecond).Milliseconds(), result.EndMs)
+			assert.Equal(t, []pipeline.WordTiming{
+				{Word: "Hello", Start: offset + 500*time.Millisecond, End: offset + 900*time.Millisecond},
+				{Word: "there", Start: offset + time.Second, End: offset + 1750*time.Millisecond},
+			}, result.Words)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for transcript result")
+		}
+	}
+}
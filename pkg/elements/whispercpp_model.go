@@ -0,0 +1,105 @@
+//go:build whispercpp
+// +build whispercpp
+
+package elements
+
+/*
+#cgo pkg-config: whisper
+#include <stdlib.h>
+#include <whisper.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// whisperCppBuilt reports whether whisper.cpp is linked in
+const whisperCppBuilt = true
+
+// whisperCppBlankAudio is the segment whisper.cpp emits for silence
+const whisperCppBlankAudio = "[BLANK_AUDIO]"
+
+// cgoWhisperCppModel runs a ggml model through the whisper.cpp C API.
+// A whisper_context runs one inference at a time.
+type cgoWhisperCppModel struct {
+	mu  sync.Mutex
+	ctx *C.struct_whisper_context
+}
+
+// newWhisperCppModel loads a ggml model file
+func newWhisperCppModel(path string) (whisperCppModel, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ctx := C.whisper_init_from_file_with_params(cPath, C.whisper_context_default_params())
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load model %s", path)
+	}
+	return &cgoWhisperCppModel{ctx: ctx}, nil
+}
+
+// Transcribe runs greedy decoding over samples
+func (m *cgoWhisperCppModel) Transcribe(samples []float32, language string, threads int) (*whisperCppTranscript, error) {
+	if len(samples) == 0 {
+		return &whisperCppTranscript{Language: language}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx == nil {
+		return nil, fmt.Errorf("model is closed")
+	}
+
+	cLanguage := C.CString(language)
+	defer C.free(unsafe.Pointer(cLanguage))
+
+	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	params.n_threads = C.int(threads)
+	params.language = cLanguage
+	params.no_context = true // utterances are independent
+	params.suppress_blank = true
+	params.print_progress = false
+	params.print_realtime = false
+	params.print_timestamps = false
+	params.print_special = false
+
+	if ret := C.whisper_full(m.ctx, params, (*C.float)(unsafe.Pointer(&samples[0])), C.int(len(samples))); ret != 0 {
+		return nil, fmt.Errorf("whisper_full failed (%d)", int(ret))
+	}
+
+	transcript := &whisperCppTranscript{Language: language}
+	if id := C.whisper_full_lang_id(m.ctx); id >= 0 {
+		transcript.Language = C.GoString(C.whisper_lang_str(id))
+	}
+
+	n := int(C.whisper_full_n_segments(m.ctx))
+	for i := 0; i < n; i++ {
+		text := C.GoString(C.whisper_full_get_segment_text(m.ctx, C.int(i)))
+		if strings.TrimSpace(text) == whisperCppBlankAudio {
+			continue
+		}
+		// Segment times are in units of 10ms
+		transcript.Segments = append(transcript.Segments, whisperCppSegment{
+			Text:  text,
+			Start: time.Duration(C.whisper_full_get_segment_t0(m.ctx, C.int(i))) * 10 * time.Millisecond,
+			End:   time.Duration(C.whisper_full_get_segment_t1(m.ctx, C.int(i))) * 10 * time.Millisecond,
+		})
+	}
+	return transcript, nil
+}
+
+// Close frees the whisper.cpp context
+func (m *cgoWhisperCppModel) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ctx != nil {
+		C.whisper_free(m.ctx)
+		m.ctx = nil
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !whispercpp
+// +build !whispercpp
+
+package elements
+
+// whisperCppBuilt reports whether whisper.cpp is linked in
+const whisperCppBuilt = false
+
+// newWhisperCppModel is unavailable without the whispercpp build tag
+func newWhisperCppModel(path string) (whisperCppModel, error) {
+	return nil, ErrWhisperCppNotBuilt
+}
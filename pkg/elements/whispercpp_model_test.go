@@ -0,0 +1,114 @@
+//go:build whispercpp
+// +build whispercpp
+
+package elements
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readWhisperCppWAV returns the PCM data of a 16kHz mono 16-bit WAV file
+func readWhisperCppWAV(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, len(data) > 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE", "Not a WAV file")
+
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		body := data[pos+8 : min(pos+8+size, len(data))]
+		switch id {
+		case "fmt ":
+			require.Equal(t, uint16(1), binary.LittleEndian.Uint16(body[2:]), "Expected mono audio")
+			require.Equal(t, uint32(whisperCppSampleRate), binary.LittleEndian.Uint32(body[4:]), "Expected 16kHz audio")
+			require.Equal(t, uint16(16), binary.LittleEndian.Uint16(body[14:]), "Expected 16-bit audio")
+		case "data":
+			return body
+		}
+		pos += 8 + size + size%2
+	}
+	t.Fatal("WAV file has no data chunk")
+	return nil
+}
+
+// TestWhisperCppSTTElement_Model transcribes a short WAV with a real model,
+// e.g. whisper.cpp's models/ggml-tiny.en.bin and samples/jfk.wav:
+//
+//	WHISPER_CPP_MODEL=ggml-tiny.en.bin WHISPER_CPP_WAV=jfk.wav go test -tags whispercpp -run WhisperCpp ./pkg/elements/
+func TestWhisperCppSTTElement_Model(t *testing.T) {
+	modelPath, wavPath := os.Getenv("WHISPER_CPP_MODEL"), os.Getenv("WHISPER_CPP_WAV")
+	if modelPath == "" || wavPath == "" {
+		t.Skip("Skipping whisper.cpp test: WHISPER_CPP_MODEL and WHISPER_CPP_WAV not set")
+	}
+	pcm := readWhisperCppWAV(t, wavPath)
+
+	elem, err := NewWhisperCppSTTElement(WhisperCppSTTConfig{
+		ModelPath: modelPath,
+		Language:  "en",
+		Threads:   2,
+		ChunkMs:   30000,
+	})
+	require.NoError(t, err)
+
+	bus := pipeline.NewEventBus()
+	transcripts := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventTranscriptResult, transcripts)
+	elem.SetBus(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	require.NoError(t, elem.Start(ctx))
+	defer elem.Stop()
+
+	// Stream the file in 20ms frames, then end the utterance
+	frame := whisperCppSampleRate / 50 * 2
+	for reader := bytes.NewReader(pcm); reader.Len() > 0; {
+		chunk := make([]byte, min(frame, reader.Len()))
+		reader.Read(chunk)
+		elem.In() <- &pipeline.PipelineMessage{
+			Type: pipeline.MsgTypeAudio,
+			AudioData: &pipeline.AudioData{
+				Data:       chunk,
+				SampleRate: whisperCppSampleRate,
+				Channels:   1,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+			},
+		}
+	}
+	require.Eventually(t, func() bool {
+		elem.mu.Lock()
+		defer elem.mu.Unlock()
+		return elem.received == int64(len(pcm)/2)
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, elem.Commit(ctx))
+
+	select {
+	case msg := <-elem.Out():
+		assert.Equal(t, "text/final", msg.TextData.TextType)
+		assert.NotEmpty(t, string(msg.TextData.Data))
+		t.Logf("Transcript: %s", msg.TextData.Data)
+	case <-time.After(time.Minute):
+		t.Fatal("Timeout waiting for the transcript")
+	}
+
+	select {
+	case evt := <-transcripts:
+		result := evt.Payload.(*pipeline.TranscriptResult)
+		assert.True(t, result.HasTimings())
+		assert.Less(t, result.StartMs, result.EndMs)
+		assert.LessOrEqual(t, result.EndMs, whisperCppDuration(int64(len(pcm)/2)).Milliseconds()+100)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the transcript result")
+	}
+}
@@ -0,0 +1,623 @@
+// Package elements provides pipeline processing elements.
+//
+// WhisperCppSTTElement runs speech recognition locally with whisper.cpp, for
+// deployments where audio must not be sent to a cloud provider or that need
+// to work offline. It is a drop-in replacement for WhisperSTTElement.
+//
+// Features:
+//   - Any ggml Whisper model; freed on Stop and reloaded on restart
+//   - VAD-segmented utterances, or fixed ChunkMs chunks without VAD
+//   - Optional partial results by re-transcribing the utterance so far
+//   - Utterances longer than 30s are committed in pieces
+//
+// Requires the whispercpp build tag and libwhisper (cgo); see ErrWhisperCppNotBuilt.
+//
+// Usage:
+//
+//	stt, err := elements.NewWhisperCppSTTElement(elements.WhisperCppSTTConfig{
+//		ModelPath:  "models/ggml-base.en.bin",
+//		VADEnabled: true,
+//	})
+package elements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/asr"
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// Ensure WhisperCppSTTElement implements pipeline.Element
+var _ pipeline.Element = (*WhisperCppSTTElement)(nil)
+var _ pipeline.InputResetter = (*WhisperCppSTTElement)(nil)
+
+// ErrWhisperCppNotBuilt is returned by NewWhisperCppSTTElement when the binary
+// was built without the whispercpp build tag.
+var ErrWhisperCppNotBuilt = errors.New("whisper.cpp support not built in (build with -tags whispercpp)")
+
+const (
+	// whisper.cpp models take 16kHz mono audio
+	whisperCppSampleRate = 16000
+
+	// Whisper decodes at most 30s of audio at a time; longer utterances are
+	// committed in pieces
+	whisperCppMaxUtteranceMs = 30000
+
+	whisperCppDefaultChunkMs           = 5000
+	whisperCppDefaultPartialIntervalMs = 1000
+	whisperCppDefaultMaxThreads        = 4
+)
+
+// whisperCppModel is a loaded whisper.cpp model. The cgo backend lives in
+// whispercpp_model.go (whispercpp build tag).
+type whisperCppModel interface {
+	// Transcribe transcribes 16kHz mono samples in [-1, 1]. language is a
+	// Whisper language code or "auto". Segment times are relative to the
+	// first sample.
+	Transcribe(samples []float32, language string, threads int) (*whisperCppTranscript, error)
+
+	// Close frees the model.
+	Close()
+}
+
+// whisperCppTranscript is the output of one whisper.cpp run.
+type whisperCppTranscript struct {
+	Segments []whisperCppSegment
+	Language string // Detected (or requested) language code
+}
+
+// whisperCppSegment is one segment of a whisper.cpp transcript.
+type whisperCppSegment struct {
+	Text  string
+	Start time.Duration
+	End   time.Duration
+}
+
+// whisperCppUtterance is committed audio waiting to be transcribed.
+type whisperCppUtterance struct {
+	samples []float32
+	offset  time.Duration // position of samples[0] in the audio given to the model
+}
+
+// WhisperCppSTTElement implements speech-to-text with a local whisper.cpp
+// (ggml) model, so audio never leaves the machine. It takes the same 16kHz
+// mono PCM input and produces the same text/partial and text/final messages
+// and bus events as WhisperSTTElement, so the two can be swapped.
+//
+// whisper.cpp is linked through cgo and only built with the whispercpp build
+// tag (libwhisper must be installed where pkg-config finds it). Without the
+// tag NewWhisperCppSTTElement returns ErrWhisperCppNotBuilt.
+//
+// With VADEnabled, the audio between EventVADSpeechStart and EventVADSpeechEnd
+// is transcribed as one utterance; otherwise audio is transcribed in chunks
+// of ChunkMs, or when Commit is called. With EnablePartialResults the
+// utterance so far is re-transcribed every PartialIntervalMs. Each result is
+// also published as EventTranscriptResult with segment timings.
+type WhisperCppSTTElement struct {
+	*pipeline.BaseElement
+
+	// Loaded model, nil while stopped
+	model     whisperCppModel
+	modelPath string
+
+	// ASR configuration
+	language             string
+	threads              int
+	enablePartialResults bool
+	partialInterval      time.Duration
+	chunkSamples         int
+	clock                pipeline.Clock
+
+	// VAD integration
+	vadEnabled   bool
+	vadEventsSub chan pipeline.Event
+
+	// Utterance being collected
+	mu             sync.Mutex
+	speaking       bool
+	utterance      []float32
+	utteranceStart int64 // offset of utterance[0] in the audio given to the model, in samples
+	received       int64 // samples given to the model so far
+	partialLen     int   // utterance length at the last partial
+
+	// Committed utterances, transcribed in order
+	commits chan whisperCppUtterance
+
+	// Attributes of the latest input audio, attached to recognition results
+	attrs pipeline.AttributeTracker
+
+	// Lifecycle management
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// WhisperCppSTTConfig holds configuration for WhisperCppSTTElement.
+type WhisperCppSTTConfig struct {
+	// ModelPath is the ggml model file, e.g. "models/ggml-base.en.bin" (required)
+	ModelPath string
+
+	// Language code (e.g., "en", "zh", "auto" for auto-detection)
+	// Leave empty to use the pipeline LanguageContext source language,
+	// or auto-detection if none is set
+	Language string
+
+	// Threads is the number of CPU threads used for inference
+	// (default: the number of CPUs, at most 4)
+	Threads int
+
+	// EnablePartialResults enables interim results during recognition
+	EnablePartialResults bool
+
+	// PartialIntervalMs is how often the utterance in progress is
+	// transcribed for a partial result (default: 1000)
+	PartialIntervalMs int
+
+	// VADEnabled determines if element should listen to VAD events
+	// When true, recognition is triggered by VAD speech start/end events
+	// When false, audio is transcribed in chunks of ChunkMs
+	VADEnabled bool
+
+	// ChunkMs is the length of the chunks transcribed when VAD is disabled
+	// (default: 5000, at most 30000)
+	ChunkMs int
+
+	// Clock drives partial results (default pipeline.SystemClock); tests can
+	// inject a pipeline.ManualClock
+	Clock pipeline.Clock
+}
+
+// NewWhisperCppSTTElement creates a new whisper.cpp STT element and loads its model.
+func NewWhisperCppSTTElement(config WhisperCppSTTConfig) (*WhisperCppSTTElement, error) {
+	if !whisperCppBuilt {
+		return nil, ErrWhisperCppNotBuilt
+	}
+	if config.ModelPath == "" {
+		return nil, fmt.Errorf("whisper.cpp model path is required")
+	}
+	if _, err := os.Stat(config.ModelPath); err != nil {
+		return nil, fmt.Errorf("whisper.cpp model not found: %w", err)
+	}
+
+	model, err := newWhisperCppModel(config.ModelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load whisper.cpp model: %w", err)
+	}
+	return newWhisperCppSTTElement(config, model), nil
+}
+
+// newWhisperCppSTTElement creates the element around an already loaded model.
+func newWhisperCppSTTElement(config WhisperCppSTTConfig, model whisperCppModel) *WhisperCppSTTElement {
+	if config.Threads <= 0 {
+		config.Threads = min(runtime.NumCPU(), whisperCppDefaultMaxThreads)
+	}
+	if config.PartialIntervalMs <= 0 {
+		config.PartialIntervalMs = whisperCppDefaultPartialIntervalMs
+	}
+	if config.ChunkMs <= 0 {
+		config.ChunkMs = whisperCppDefaultChunkMs
+	}
+	if config.ChunkMs > whisperCppMaxUtteranceMs {
+		config.ChunkMs = whisperCppMaxUtteranceMs
+	}
+	if config.Clock == nil {
+		config.Clock = pipeline.SystemClock
+	}
+
+	return &WhisperCppSTTElement{
+		BaseElement:          pipeline.NewBaseElement("whispercpp-stt", 100),
+		model:                model,
+		modelPath:            config.ModelPath,
+		language:             config.Language,
+		threads:              config.Threads,
+		enablePartialResults: config.EnablePartialResults,
+		partialInterval:      time.Duration(config.PartialIntervalMs) * time.Millisecond,
+		chunkSamples:         config.ChunkMs * whisperCppSampleRate / 1000,
+		clock:                config.Clock,
+		vadEnabled:           config.VADEnabled,
+	}
+}
+
+// Start starts the whisper.cpp STT element.
+func (e *WhisperCppSTTElement) Start(ctx context.Context) error {
+	// The model is freed by Stop; reload it when restarted
+	if e.model == nil {
+		model, err := newWhisperCppModel(e.modelPath)
+		if err != nil {
+			return fmt.Errorf("failed to load whisper.cpp model: %w", err)
+		}
+		e.model = model
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.ctx = ctx
+	e.cancel = cancel
+	e.commits = make(chan whisperCppUtterance, 8)
+	e.ResetInput()
+
+	log.Printf("[WhisperCppSTT] Starting element (VAD: %v, Language: %s, Threads: %d)",
+		e.vadEnabled, e.language, e.threads)
+
+	// Subscribe to VAD events if VAD is enabled
+	if e.vadEnabled && e.BaseElement.Bus() != nil {
+		e.vadEventsSub = make(chan pipeline.Event, 10)
+		e.BaseElement.Bus().Subscribe(pipeline.EventVADSpeechStart, e.vadEventsSub)
+		e.BaseElement.Bus().Subscribe(pipeline.EventVADSpeechEnd, e.vadEventsSub)
+
+		e.wg.Add(1)
+		go e.handleVADEvents(ctx)
+	}
+
+	e.wg.Add(2)
+	go e.processAudio(ctx)
+	go e.transcribeLoop(ctx)
+
+	return nil
+}
+
+// Stop stops the element and frees the model.
+func (e *WhisperCppSTTElement) Stop() error {
+	log.Printf("[WhisperCppSTT] Stopping element")
+
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+
+	// Unsubscribe from VAD events
+	if e.vadEventsSub != nil {
+		if e.BaseElement.Bus() != nil {
+			e.BaseElement.Bus().Unsubscribe(pipeline.EventVADSpeechStart, e.vadEventsSub)
+			e.BaseElement.Bus().Unsubscribe(pipeline.EventVADSpeechEnd, e.vadEventsSub)
+		}
+		close(e.vadEventsSub)
+		e.vadEventsSub = nil
+	}
+
+	if e.model != nil {
+		e.model.Close()
+		e.model = nil
+	}
+
+	log.Printf("[WhisperCppSTT] Stopped")
+	return nil
+}
+
+// Commit transcribes the audio collected so far as a final result.
+// This is useful in non-VAD mode to end an utterance before ChunkMs.
+func (e *WhisperCppSTTElement) Commit(ctx context.Context) error {
+	e.mu.Lock()
+	u, ok := e.takeUtteranceLocked()
+	e.mu.Unlock()
+
+	if ok {
+		e.commit(ctx, u)
+	}
+	return nil
+}
+
+// ResetInput drops the utterance in progress without transcribing it.
+// Implements pipeline.InputResetter.
+func (e *WhisperCppSTTElement) ResetInput() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.speaking = false
+	e.utterance = nil
+	e.utteranceStart = e.received
+	e.partialLen = 0
+}
+
+// processAudio collects incoming audio into the current utterance.
+func (e *WhisperCppSTTElement) processAudio(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-e.BaseElement.InChan:
+			if !ok {
+				return
+			}
+
+			// Only process audio messages
+			if msg.Type != pipeline.MsgTypeAudio || msg.AudioData == nil {
+				continue
+			}
+			e.attrs.Track(msg)
+
+			// Validate audio format
+			if msg.AudioData.SampleRate != whisperCppSampleRate || msg.AudioData.Channels > 1 {
+				log.Printf("[WhisperCppSTT] Warning: Audio format mismatch (expected %dHz mono, got %dHz %d channels)",
+					whisperCppSampleRate, msg.AudioData.SampleRate, msg.AudioData.Channels)
+				continue
+			}
+
+			samples := audio.BytesToFloat32(msg.AudioData.Data, msg.AudioData.SampleFormat)
+
+			e.mu.Lock()
+			// With VAD, only audio while speaking is transcribed
+			if e.vadEnabled && !e.speaking {
+				e.mu.Unlock()
+				continue
+			}
+			e.appendLocked(samples)
+			limit := whisperCppMaxUtteranceMs * whisperCppSampleRate / 1000
+			if !e.vadEnabled {
+				limit = e.chunkSamples
+			}
+			var u whisperCppUtterance
+			full := len(e.utterance) >= limit
+			if full {
+				u, _ = e.takeUtteranceLocked()
+			}
+			e.mu.Unlock()
+
+			if full {
+				e.commit(ctx, u)
+			}
+		}
+	}
+}
+
+// handleVADEvents starts an utterance on speech start and commits it on speech end.
+func (e *WhisperCppSTTElement) handleVADEvents(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-e.vadEventsSub:
+			if !ok {
+				return
+			}
+
+			switch event.Type {
+			case pipeline.EventVADSpeechStart:
+				e.mu.Lock()
+				e.utterance = nil
+				e.utteranceStart = e.received
+				e.partialLen = 0
+				e.speaking = true
+				// Pre-roll audio captured before the speech start belongs to the utterance
+				if payload, ok := event.Payload.(pipeline.VADPayload); ok && len(payload.PreRollAudio) > 0 {
+					e.appendLocked(audio.BytesToFloat32(payload.PreRollAudio, pipeline.SampleFormatS16))
+				}
+				e.mu.Unlock()
+				log.Printf("[WhisperCppSTT] VAD speech started")
+
+			case pipeline.EventVADSpeechEnd:
+				e.mu.Lock()
+				e.speaking = false
+				u, ok := e.takeUtteranceLocked()
+				e.mu.Unlock()
+				log.Printf("[WhisperCppSTT] VAD speech ended")
+
+				if ok {
+					e.commit(ctx, u)
+				}
+			}
+		}
+	}
+}
+
+// appendLocked adds samples to the current utterance. The caller holds mu.
+func (e *WhisperCppSTTElement) appendLocked(samples []float32) {
+	e.utterance = append(e.utterance, samples...)
+	e.received += int64(len(samples))
+}
+
+// takeUtteranceLocked removes the current utterance for transcription and
+// starts a new one. It returns false if no audio was collected. The caller holds mu.
+func (e *WhisperCppSTTElement) takeUtteranceLocked() (whisperCppUtterance, bool) {
+	if len(e.utterance) == 0 {
+		return whisperCppUtterance{}, false
+	}
+	u := whisperCppUtterance{
+		samples: e.utterance,
+		offset:  whisperCppDuration(e.utteranceStart),
+	}
+	e.utterance = nil
+	e.utteranceStart = e.received
+	e.partialLen = 0
+	return u, true
+}
+
+// partialUtterance returns a copy of the utterance in progress if it has
+// grown since the last partial.
+func (e *WhisperCppSTTElement) partialUtterance() (whisperCppUtterance, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.utterance) == 0 || len(e.utterance) == e.partialLen {
+		return whisperCppUtterance{}, false
+	}
+	e.partialLen = len(e.utterance)
+	return whisperCppUtterance{
+		samples: append([]float32(nil), e.utterance...),
+		offset:  whisperCppDuration(e.utteranceStart),
+	}, true
+}
+
+// commit queues an utterance for a final transcription.
+func (e *WhisperCppSTTElement) commit(ctx context.Context, u whisperCppUtterance) {
+	select {
+	case e.commits <- u:
+	case <-ctx.Done():
+	}
+}
+
+// transcribeLoop runs the model on committed utterances and, with partial
+// results enabled, periodically on the utterance in progress. Inference runs
+// one at a time, so results are emitted in order.
+func (e *WhisperCppSTTElement) transcribeLoop(ctx context.Context) {
+	defer e.wg.Done()
+
+	var partialC <-chan time.Time
+	if e.enablePartialResults {
+		ticker := e.clock.NewTicker(e.partialInterval)
+		defer ticker.Stop()
+		partialC = ticker.C()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case u := <-e.commits:
+			if !e.transcribe(ctx, u, true) {
+				return
+			}
+
+		case <-partialC:
+			if u, ok := e.partialUtterance(); ok {
+				if !e.transcribe(ctx, u, false) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// transcribe runs the model on an utterance and emits the result. It returns
+// false if ctx was cancelled.
+func (e *WhisperCppSTTElement) transcribe(ctx context.Context, u whisperCppUtterance, final bool) bool {
+	language := e.recognitionLanguage()
+	if final {
+		e.ProviderLogger().Log(pipeline.ProviderRecord{
+			Kind:     pipeline.ProviderSTT,
+			Provider: "whisper.cpp",
+			Element:  e.GetName(),
+			Model:    e.modelPath,
+			Language: language,
+			Audio:    audio.Float32ToBytes(u.samples, pipeline.SampleFormatS16),
+		})
+	}
+
+	start := time.Now()
+	transcript, err := e.model.Transcribe(u.samples, language, e.threads)
+	if err != nil {
+		log.Printf("[WhisperCppSTT] Recognition error: %v", err)
+		return ctx.Err() == nil
+	}
+
+	texts := make([]string, 0, len(transcript.Segments))
+	for _, seg := range transcript.Segments {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) == 0 {
+		return ctx.Err() == nil
+	}
+
+	result := &asr.RecognitionResult{
+		Text:       strings.Join(texts, " "),
+		IsFinal:    final,
+		Confidence: -1, // whisper.cpp doesn't provide confidence scores
+		Language:   transcript.Language,
+		Duration:   time.Since(start),
+		Timestamp:  time.Now(),
+	}
+	if n := len(transcript.Segments); n > 0 {
+		result.StartTime = u.offset + transcript.Segments[0].Start
+		result.EndTime = u.offset + transcript.Segments[n-1].End
+	}
+	return e.emitResult(ctx, result)
+}
+
+// emitResult sends a recognition result downstream and publishes it on the
+// bus. It returns false if ctx was cancelled.
+func (e *WhisperCppSTTElement) emitResult(ctx context.Context, result *asr.RecognitionResult) bool {
+	// Let downstream elements follow the spoken language
+	e.LanguageContext().SetDetected(result.Language)
+
+	// Determine text type
+	textType := "text/partial"
+	eventType := pipeline.EventPartialResult
+	if result.IsFinal {
+		textType = "text/final"
+		eventType = pipeline.EventFinalResult
+		e.ProviderLogger().Log(pipeline.ProviderRecord{
+			Kind:     pipeline.ProviderSTT,
+			Provider: "whisper.cpp",
+			Element:  e.GetName(),
+			Response: true,
+			Model:    e.modelPath,
+			Language: result.Language,
+			Text:     result.Text,
+		})
+	}
+
+	log.Printf("[WhisperCppSTT] Recognition result (%s): %s", textType, result.Text)
+
+	// Create text data message
+	attrs := e.attrs.Attributes()
+	textMsg := &pipeline.PipelineMessage{
+		Type:       pipeline.MsgTypeData,
+		Timestamp:  time.Now(),
+		Attributes: attrs,
+		TextData: &pipeline.TextData{
+			Data:      []byte(result.Text),
+			TextType:  textType,
+			Timestamp: result.Timestamp,
+		},
+	}
+
+	// Send to output channel
+	select {
+	case e.BaseElement.OutChan <- textMsg:
+	case <-ctx.Done():
+		return false
+	}
+
+	// Publish event to bus
+	if e.BaseElement.Bus() != nil {
+		e.BaseElement.Bus().Publish(pipeline.Event{
+			Type:       eventType,
+			Timestamp:  result.Timestamp,
+			Payload:    result.Text,
+			Attributes: attrs,
+		})
+		publishTranscriptResult(e.BaseElement.Bus(), e.GetName(), result, attrs)
+	}
+	return true
+}
+
+// recognitionLanguage returns the Whisper language code to transcribe with:
+// the configured language, the pipeline LanguageContext source language, or
+// "auto" to detect it.
+func (e *WhisperCppSTTElement) recognitionLanguage() string {
+	language := e.language
+	if language == "" {
+		language = e.LanguageContext().Source()
+	}
+	if language == "" {
+		return "auto"
+	}
+	// whisper.cpp takes bare language codes ("en" rather than "en-US")
+	if i := strings.IndexByte(language, '-'); i > 0 {
+		language = language[:i]
+	}
+	return strings.ToLower(language)
+}
+
+// whisperCppDuration converts a number of 16kHz samples to a duration.
+func whisperCppDuration(samples int64) time.Duration {
+	return time.Duration(samples) * time.Second / whisperCppSampleRate
+}
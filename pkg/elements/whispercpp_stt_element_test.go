@@ -0,0 +1,180 @@
+package elements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWhisperCppModel transcribes n samples as "<n> samples", spoken from
+// 100ms after the start to 100ms before the end
+type fakeWhisperCppModel struct {
+	mu        sync.Mutex
+	languages []string
+	closed    bool
+}
+
+func (m *fakeWhisperCppModel) Transcribe(samples []float32, language string, threads int) (*whisperCppTranscript, error) {
+	m.mu.Lock()
+	m.languages = append(m.languages, language)
+	m.mu.Unlock()
+
+	return &whisperCppTranscript{
+		Language: "en",
+		Segments: []whisperCppSegment{{
+			Text:  fmt.Sprintf(" %d samples", len(samples)),
+			Start: 100 * time.Millisecond,
+			End:   whisperCppDuration(int64(len(samples))) - 100*time.Millisecond,
+		}},
+	}, nil
+}
+
+func (m *fakeWhisperCppModel) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+}
+
+func whisperCppAudio(ms int) *pipeline.PipelineMessage {
+	return &pipeline.PipelineMessage{
+		Type: pipeline.MsgTypeAudio,
+		AudioData: &pipeline.AudioData{
+			Data:       make([]byte, ms*whisperCppSampleRate/1000*2),
+			SampleRate: whisperCppSampleRate,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypeRaw,
+		},
+	}
+}
+
+func receiveWhisperCppText(t *testing.T, elem pipeline.Element) (string, string) {
+	t.Helper()
+	select {
+	case msg := <-elem.Out():
+		return string(msg.TextData.Data), msg.TextData.TextType
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for a result")
+		return "", ""
+	}
+}
+
+func TestNewWhisperCppSTTElement_Unavailable(t *testing.T) {
+	_, err := NewWhisperCppSTTElement(WhisperCppSTTConfig{ModelPath: "/nonexistent/ggml-tiny.bin"})
+	require.Error(t, err)
+	if !whisperCppBuilt {
+		assert.True(t, errors.Is(err, ErrWhisperCppNotBuilt), "Expected ErrWhisperCppNotBuilt, got %v", err)
+	}
+}
+
+func TestWhisperCppSTTElement_Chunks(t *testing.T) {
+	clock := pipeline.NewManualClock(time.Unix(0, 0))
+	model := &fakeWhisperCppModel{}
+	elem := newWhisperCppSTTElement(WhisperCppSTTConfig{
+		Language:             "en-US",
+		EnablePartialResults: true,
+		ChunkMs:              1000,
+		Clock:                clock,
+	}, model)
+
+	bus := pipeline.NewEventBus()
+	transcripts := make(chan pipeline.Event, 10)
+	bus.Subscribe(pipeline.EventTranscriptResult, transcripts)
+	elem.SetBus(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	require.NoError(t, elem.Start(ctx))
+
+	// Half a chunk: the utterance so far becomes a partial result
+	elem.In() <- whisperCppAudio(500)
+	clock.BlockUntil(1)
+	require.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		return len(elem.Out()) > 0
+	}, time.Second, 10*time.Millisecond)
+	text, textType := receiveWhisperCppText(t, elem)
+	assert.Equal(t, "8000 samples", text)
+	assert.Equal(t, "text/partial", textType)
+
+	// A full chunk is committed; the next chunk's timings follow on from it
+	elem.In() <- whisperCppAudio(500)
+	elem.In() <- whisperCppAudio(1000)
+	for _, want := range []pipeline.TranscriptResult{
+		{Source: "whispercpp-stt", Text: "8000 samples", StartMs: 100, EndMs: 400},
+		{Source: "whispercpp-stt", Text: "16000 samples", IsFinal: true, StartMs: 100, EndMs: 900},
+		{Source: "whispercpp-stt", Text: "16000 samples", IsFinal: true, StartMs: 1100, EndMs: 1900},
+	} {
+		select {
+		case evt := <-transcripts:
+			assert.Equal(t, &want, evt.Payload)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for %q", want.Text)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		text, textType := receiveWhisperCppText(t, elem)
+		assert.Equal(t, "16000 samples", text)
+		assert.Equal(t, "text/final", textType)
+	}
+
+	require.NoError(t, elem.Stop())
+	assert.True(t, model.closed)
+	assert.Equal(t, []string{"en", "en", "en"}, model.languages)
+}
+
+func TestWhisperCppSTTElement_VAD(t *testing.T) {
+	model := &fakeWhisperCppModel{}
+	elem := newWhisperCppSTTElement(WhisperCppSTTConfig{VADEnabled: true}, model)
+
+	bus := pipeline.NewEventBus()
+	elem.SetBus(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bus.Start(ctx))
+	require.NoError(t, elem.Start(ctx))
+	defer elem.Stop()
+
+	// Audio outside speech is ignored. Input is handled in order, so once the
+	// mismatched audio after it was seen, the first message was dropped
+	elem.In() <- whisperCppAudio(200)
+	mismatched := whisperCppAudio(200)
+	mismatched.AudioData.SampleRate = 48000
+	mismatched.Attributes = pipeline.Attributes{"marker": true}
+	elem.In() <- mismatched
+	require.Eventually(t, func() bool {
+		return elem.attrs.Attributes()["marker"] == true
+	}, time.Second, 5*time.Millisecond)
+
+	bus.Publish(pipeline.Event{
+		Type:    pipeline.EventVADSpeechStart,
+		Payload: pipeline.VADPayload{PreRollAudio: make([]byte, 300*whisperCppSampleRate/1000*2)},
+	})
+	require.Eventually(t, func() bool {
+		elem.mu.Lock()
+		defer elem.mu.Unlock()
+		return elem.speaking
+	}, time.Second, 5*time.Millisecond)
+
+	elem.In() <- whisperCppAudio(700)
+	require.Eventually(t, func() bool {
+		elem.mu.Lock()
+		defer elem.mu.Unlock()
+		return len(elem.utterance) == whisperCppSampleRate
+	}, time.Second, 5*time.Millisecond)
+	bus.Publish(pipeline.Event{Type: pipeline.EventVADSpeechEnd})
+
+	// Pre-roll plus the speech, without partials or a language hint
+	text, textType := receiveWhisperCppText(t, elem)
+	assert.Equal(t, "16000 samples", text)
+	assert.Equal(t, "text/final", textType)
+	assert.Equal(t, []string{"auto"}, model.languages)
+}
@@ -0,0 +1,91 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// Attributes 是应用附加在 PipelineMessage 上的结构化元数据（用户 ID、请求 ID、轮次等），
+// 在下游元素和 Bus 事件中都能读到，便于关联同一请求的消息、按用户路由，不需要额外的旁路状态。
+//
+// 与 Metadata（元素之间约定的单条消息附加数据，如 []WordTiming）不同，
+// Attributes 由应用写入，元素必须原样传递:
+//   - 由输入消息生成输出消息的元素（重采样、编解码、翻译、LLM、TTS 等）把输入的 Attributes 带到输出上
+//   - 异步产生输出的元素（如 STT）用 AttributeTracker 附加最近一条输入的 Attributes
+//   - 由某条消息触发的 Bus 事件在 Event.Attributes 中携带同一份 Attributes
+//
+// 使用示例:
+//
+//	msg.SetAttribute(pipeline.AttrUserID, "u_123")
+//	p.Push(msg)
+//	// 下游
+//	userID := msg.Attributes.String(pipeline.AttrUserID)
+package pipeline
+
+import "sync"
+
+// 常用的 Attributes 键
+const (
+	AttrUserID    = "user_id"    // 用户 ID
+	AttrRequestID = "request_id" // 请求 ID
+	AttrTurn      = "turn"       // 对话轮次
+
+	// AttrAudioTrack 输入音频所属的音轨 ID（一个连接有多条音轨时设置），见 Pipeline.SetAudioTrackInput
+	AttrAudioTrack = "audio_track"
+)
+
+// Attributes 应用附加在消息上的结构化元数据
+// 消息发送后视为只读，多条消息可能共享同一个 map；需要修改时先 Clone
+type Attributes map[string]any
+
+// Clone 返回 Attributes 的浅拷贝，nil 时返回 nil
+func (a Attributes) Clone() Attributes {
+	if a == nil {
+		return nil
+	}
+	c := make(Attributes, len(a))
+	for k, v := range a {
+		c[k] = v
+	}
+	return c
+}
+
+// String 返回 key 对应的字符串值，不存在或不是字符串时返回 ""
+func (a Attributes) String(key string) string {
+	s, _ := a[key].(string)
+	return s
+}
+
+// SetAttribute 设置一个属性，Attributes 为 nil 时自动创建
+// 只应在消息发送前调用
+func (p *PipelineMessage) SetAttribute(key string, value any) {
+	if p.Attributes == nil {
+		p.Attributes = make(Attributes)
+	}
+	p.Attributes[key] = value
+}
+
+// Attribute 返回 key 对应的属性
+func (p *PipelineMessage) Attribute(key string) (any, bool) {
+	v, ok := p.Attributes[key]
+	return v, ok
+}
+
+// AttributeTracker 记录最近一条输入消息的 Attributes
+// 供输入和输出不一一对应、在其他 goroutine 产生输出的元素使用
+type AttributeTracker struct {
+	mu    sync.Mutex
+	attrs Attributes
+}
+
+// Track 记录 msg 的 Attributes，msg 没有 Attributes 时保留之前的值
+func (t *AttributeTracker) Track(msg *PipelineMessage) {
+	if msg == nil || msg.Attributes == nil {
+		return
+	}
+	t.mu.Lock()
+	t.attrs = msg.Attributes
+	t.mu.Unlock()
+}
+
+// Attributes 返回最近记录的 Attributes
+func (t *AttributeTracker) Attributes() Attributes {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.attrs
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAttributes_SetAndGet(t *testing.T) {
+	msg := &PipelineMessage{Type: MsgTypeData}
+	if _, ok := msg.Attribute(AttrUserID); ok {
+		t.Error("Attribute should be missing on a new message")
+	}
+
+	msg.SetAttribute(AttrUserID, "u_1")
+	msg.SetAttribute(AttrTurn, 3)
+
+	if got := msg.Attributes.String(AttrUserID); got != "u_1" {
+		t.Errorf("Expected user ID u_1, got %q", got)
+	}
+	if v, ok := msg.Attribute(AttrTurn); !ok || v != 3 {
+		t.Errorf("Expected turn 3, got %v", v)
+	}
+	if got := msg.Attributes.String(AttrTurn); got != "" {
+		t.Errorf("String should return empty for non-string values, got %q", got)
+	}
+}
+
+func TestAttributes_Clone(t *testing.T) {
+	var nilAttrs Attributes
+	if nilAttrs.Clone() != nil {
+		t.Error("Clone of nil Attributes should be nil")
+	}
+
+	a := Attributes{AttrRequestID: "req_1"}
+	c := a.Clone()
+	c[AttrRequestID] = "req_2"
+	if a.String(AttrRequestID) != "req_1" {
+		t.Error("Modifying a clone should not change the original")
+	}
+}
+
+func TestAttributeTracker(t *testing.T) {
+	var tracker AttributeTracker
+	if tracker.Attributes() != nil {
+		t.Error("Empty tracker should return nil")
+	}
+
+	first := &PipelineMessage{Attributes: Attributes{AttrUserID: "u_1"}}
+	tracker.Track(first)
+	// 没有 Attributes 的消息不覆盖之前的值
+	tracker.Track(&PipelineMessage{})
+	if got := tracker.Attributes().String(AttrUserID); got != "u_1" {
+		t.Errorf("Expected u_1, got %q", got)
+	}
+
+	tracker.Track(&PipelineMessage{Attributes: Attributes{AttrUserID: "u_2"}})
+	if got := tracker.Attributes().String(AttrUserID); got != "u_2" {
+		t.Errorf("Expected u_2, got %q", got)
+	}
+
+	// 并发读写安全
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tracker.Track(first)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = tracker.Attributes()
+		}()
+	}
+	wg.Wait()
+}
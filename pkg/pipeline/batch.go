@@ -0,0 +1,330 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// RunBatch 以离线批处理模式驱动整条 Pipeline。
+// 不做实时节奏控制，尽可能快地把输入源推入 Pipeline 并聚合输出，
+// 适用于录音文件的转写/翻译等离线任务。
+//
+// 主要功能:
+//   - BatchSource 抽象输入源（内置 PCM 音频源）
+//   - 阻塞式推送，由 Element 通道提供背压，不丢数据
+//   - 聚合最终识别结果（FinalResult 事件）、输出文本和输出音频
+//   - 输入结束后，空闲超时内没有新输出即视为处理完成
+//
+// 注意: 批处理模式下不要使用 AudioPacerSinkElement 等按固定节奏输出的
+// Element 作为末端，否则 Pipeline 永远不会空闲。
+//
+// 使用示例:
+//
+//	src := NewPCMBatchSource(pcm, 16000, 1, 100*time.Millisecond)
+//	result, err := p.RunBatch(ctx, src, nil)
+//	fmt.Println(result.FinalResults, len(result.Audio))
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// DefaultBatchIdleTimeout 输入结束后等待 Pipeline 空闲的默认时长
+const DefaultBatchIdleTimeout = 3 * time.Second
+
+// BatchSource 批处理输入源
+type BatchSource interface {
+	// Next 返回下一条输入消息，输入结束时返回 io.EOF
+	Next(ctx context.Context) (*PipelineMessage, error)
+}
+
+// BatchSourceFunc 将普通函数适配为 BatchSource
+type BatchSourceFunc func(ctx context.Context) (*PipelineMessage, error)
+
+// Next 实现 BatchSource
+func (f BatchSourceFunc) Next(ctx context.Context) (*PipelineMessage, error) {
+	return f(ctx)
+}
+
+// BatchSink 接收 Pipeline 的每一条输出消息
+type BatchSink func(msg *PipelineMessage)
+
+// BatchConfig 批处理配置
+type BatchConfig struct {
+	// IdleTimeout 输入结束后，超过该时长没有新的输出/结果即认为处理完成
+	IdleTimeout time.Duration
+}
+
+// BatchResult 批处理聚合结果
+type BatchResult struct {
+	FinalResults []string      // 总线上 EventFinalResult 的文本（STT、翻译等，按到达顺序）
+	Texts        []string      // Pipeline 输出的文本消息，不含 STT 的中间结果（text/partial）
+	Audio        []byte        // Pipeline 输出的音频（按顺序拼接）
+	SampleRate   int           // 输出音频采样率
+	Channels     int           // 输出音频通道数
+	Elapsed      time.Duration // 批处理总耗时
+}
+
+// Text 返回拼接后的输出文本
+func (r *BatchResult) Text() string {
+	return strings.Join(r.Texts, "")
+}
+
+// collect 聚合一条输出消息
+func (r *BatchResult) collect(msg *PipelineMessage) {
+	switch msg.Type {
+	case MsgTypeAudio:
+		if msg.AudioData == nil || len(msg.AudioData.Data) == 0 {
+			return
+		}
+		if r.SampleRate == 0 {
+			r.SampleRate = msg.AudioData.SampleRate
+			r.Channels = msg.AudioData.Channels
+		}
+		r.Audio = append(r.Audio, msg.AudioData.Data...)
+	case MsgTypeData:
+		if msg.TextData == nil || len(msg.TextData.Data) == 0 {
+			return
+		}
+		// STT 的中间结果会被随后的最终结果取代，只保留最终结果
+		if msg.TextData.TextType == "text/partial" {
+			return
+		}
+		r.Texts = append(r.Texts, string(msg.TextData.Data))
+	}
+}
+
+// RunBatch 使用默认配置以批处理模式运行 Pipeline，见 RunBatchWithConfig
+func (p *Pipeline) RunBatch(ctx context.Context, source BatchSource, sink BatchSink) (*BatchResult, error) {
+	return p.RunBatchWithConfig(ctx, source, sink, BatchConfig{})
+}
+
+// RunBatchWithConfig 以批处理模式运行 Pipeline
+// 会启动 Pipeline，把 source 的全部消息无节奏地推入输入端 Element，
+// 收集输出端 Element 的输出（同时回调 sink，可为 nil），结束后停止 Pipeline。
+// 输入端和输出端的确定见 batchEndpointsLocked。
+// 调用前不要自行 Start Pipeline。ctx 取消时返回已聚合的部分结果和 ctx.Err()。
+func (p *Pipeline) RunBatchWithConfig(ctx context.Context, source BatchSource, sink BatchSink, cfg BatchConfig) (*BatchResult, error) {
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultBatchIdleTimeout
+	}
+
+	p.Lock()
+	first, last, err := p.batchEndpointsLocked()
+	p.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// 在启动前订阅，避免丢失早期结果
+	finalCh := make(chan Event, 100)
+	p.bus.Subscribe(EventFinalResult, finalCh)
+	defer p.bus.Unsubscribe(EventFinalResult, finalCh)
+
+	// Start 失败时会自行停止已启动的部分
+	if err := p.Start(ctx); err != nil {
+		return nil, err
+	}
+	defer p.Stop()
+
+	result := &BatchResult{}
+	startedAt := time.Now()
+
+	sourceDone := make(chan error, 1)
+	go func() {
+		sourceDone <- feedBatch(ctx, first, source)
+	}()
+
+	idle := time.NewTimer(cfg.IdleTimeout)
+	idle.Stop()
+	defer idle.Stop()
+	inputDone := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			result.Elapsed = time.Since(startedAt)
+			return result, ctx.Err()
+
+		case err := <-sourceDone:
+			if err != nil {
+				result.Elapsed = time.Since(startedAt)
+				return result, fmt.Errorf("batch source: %w", err)
+			}
+			inputDone = true
+			sourceDone = nil
+			idle.Reset(cfg.IdleTimeout)
+
+		case evt := <-finalCh:
+			if text, ok := evt.Payload.(string); ok && strings.TrimSpace(text) != "" {
+				result.FinalResults = append(result.FinalResults, text)
+			}
+			if inputDone {
+				idle.Reset(cfg.IdleTimeout)
+			}
+
+		case msg, ok := <-last.Out():
+			if !ok {
+				result.Elapsed = time.Since(startedAt)
+				return result, nil
+			}
+			if msg == nil {
+				continue
+			}
+			if sink != nil {
+				sink(msg)
+			}
+			result.collect(msg)
+			if inputDone {
+				idle.Reset(cfg.IdleTimeout)
+			}
+
+		case <-idle.C:
+			result.Elapsed = time.Since(startedAt)
+			return result, nil
+		}
+	}
+}
+
+// batchEndpointsLocked 返回批处理的输入端和输出端，调用方需持有锁
+// 优先使用 SetSource/SetSink 标记；未标记时按连接拓扑推断：没有上游的元素为输入端，
+// 没有下游的元素为输出端，没有任何连接的元素（如只通过 Bus 工作的元素）不参与推断。
+// 只有一个元素时它既是输入端也是输出端；推断结果不唯一时返回错误，需要显式标记
+func (p *Pipeline) batchEndpointsLocked() (source, sink Element, err error) {
+	if len(p.elements) == 0 {
+		return nil, nil, fmt.Errorf("pipeline %s has no elements", p.name)
+	}
+	if err := p.checkEndpointsLocked(); err != nil {
+		return nil, nil, err
+	}
+	for _, e := range p.elements {
+		if e.IsSource() {
+			source = e
+		}
+		if e.IsSink() {
+			sink = e
+		}
+	}
+	if len(p.elements) == 1 {
+		return p.elements[0], p.elements[0], nil
+	}
+
+	links := p.linksLocked()
+	inbound := make(map[Element]bool)
+	for _, dsts := range links {
+		for _, dst := range dsts {
+			inbound[dst] = true
+		}
+	}
+	var sources, sinks []Element
+	for _, e := range p.elements {
+		outbound := len(links[e]) > 0
+		if !outbound && !inbound[e] {
+			continue
+		}
+		if !inbound[e] {
+			sources = append(sources, e)
+		}
+		if !outbound {
+			sinks = append(sinks, e)
+		}
+	}
+
+	if source == nil {
+		if len(sources) != 1 {
+			return nil, nil, fmt.Errorf("pipeline %s: cannot infer the batch input from links (candidates: %s), mark it with SetSource",
+				p.name, joinNames(sources, ", "))
+		}
+		source = sources[0]
+	}
+	if sink == nil {
+		if len(sinks) != 1 {
+			return nil, nil, fmt.Errorf("pipeline %s: cannot infer the batch output from links (candidates: %s), mark it with SetSink",
+				p.name, joinNames(sinks, ", "))
+		}
+		sink = sinks[0]
+	}
+	return source, sink, nil
+}
+
+// feedBatch 把输入源的消息阻塞式推入 Element，直到输入结束
+func feedBatch(ctx context.Context, e Element, source BatchSource) error {
+	for {
+		msg, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			continue
+		}
+		select {
+		case e.In() <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pcmBatchSource 将一段原始 PCM 音频切分为固定时长的音频消息
+type pcmBatchSource struct {
+	data       []byte
+	sampleRate int
+	channels   int
+	chunkSize  int
+	offset     int
+}
+
+// NewPCMBatchSource 创建 PCM 音频批处理输入源
+// pcm 为 16-bit 小端交织数据，chunkDuration 为每条消息的音频时长（<=0 时默认 100ms）
+func NewPCMBatchSource(pcm []byte, sampleRate, channels int, chunkDuration time.Duration) BatchSource {
+	if channels <= 0 {
+		channels = 1
+	}
+	if chunkDuration <= 0 {
+		chunkDuration = 100 * time.Millisecond
+	}
+
+	frameSize := channels * 2
+	chunkSize := int(int64(sampleRate) * int64(chunkDuration) / int64(time.Second) * int64(frameSize))
+	if chunkSize < frameSize {
+		chunkSize = frameSize
+	}
+
+	return &pcmBatchSource{
+		data:       pcm,
+		sampleRate: sampleRate,
+		channels:   channels,
+		chunkSize:  chunkSize,
+	}
+}
+
+// Next 实现 BatchSource
+func (s *pcmBatchSource) Next(ctx context.Context) (*PipelineMessage, error) {
+	if s.offset >= len(s.data) {
+		return nil, io.EOF
+	}
+
+	end := s.offset + s.chunkSize
+	if end > len(s.data) {
+		end = len(s.data)
+	}
+	chunk := make([]byte, end-s.offset)
+	copy(chunk, s.data[s.offset:end])
+	s.offset = end
+
+	now := time.Now()
+	return &PipelineMessage{
+		Type:      MsgTypeAudio,
+		Timestamp: now,
+		AudioData: &AudioData{
+			Data:       chunk,
+			SampleRate: s.sampleRate,
+			Channels:   s.channels,
+			MediaType:  AudioMediaTypeRaw,
+			Timestamp:  now,
+		},
+	}, nil
+}
@@ -0,0 +1,245 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// echoElement 将音频原样输出，并为每条音频发布一条 FinalResult
+type echoElement struct {
+	*BaseElement
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newEchoElement() *echoElement {
+	return &echoElement{BaseElement: NewBaseElement("echo-element", 1)}
+}
+
+func (e *echoElement) Start(ctx context.Context) error {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.InChan:
+				e.Bus().Publish(Event{Type: EventFinalResult, Timestamp: time.Now(), Payload: "chunk"})
+				select {
+				case e.OutChan <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *echoElement) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+		e.wg.Wait()
+		e.cancel = nil
+	}
+	return nil
+}
+
+func TestPipelineRunBatch(t *testing.T) {
+	p := NewPipeline("batch")
+	p.AddElement(newEchoElement())
+
+	// 1 秒 16kHz 单声道音频，100ms 一块 => 10 条消息
+	pcm := make([]byte, 16000*2)
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+
+	var sinkCount int
+	result, err := p.RunBatchWithConfig(context.Background(),
+		NewPCMBatchSource(pcm, 16000, 1, 100*time.Millisecond),
+		func(msg *PipelineMessage) { sinkCount++ },
+		BatchConfig{IdleTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("RunBatch failed: %v", err)
+	}
+
+	if sinkCount != 10 {
+		t.Errorf("Expected 10 sink messages, got %d", sinkCount)
+	}
+	if len(result.Audio) != len(pcm) {
+		t.Fatalf("Expected %d audio bytes, got %d", len(pcm), len(result.Audio))
+	}
+	for i := range pcm {
+		if result.Audio[i] != pcm[i] {
+			t.Fatalf("Audio mismatch at byte %d", i)
+		}
+	}
+	if result.SampleRate != 16000 || result.Channels != 1 {
+		t.Errorf("Expected 16000Hz mono, got %dHz %dch", result.SampleRate, result.Channels)
+	}
+	if len(result.FinalResults) != 10 {
+		t.Errorf("Expected 10 final results, got %d", len(result.FinalResults))
+	}
+}
+
+func TestPipelineRunBatchSourceError(t *testing.T) {
+	p := NewPipeline("batch")
+	p.AddElement(newEchoElement())
+
+	sourceErr := errors.New("read failed")
+	_, err := p.RunBatch(context.Background(), BatchSourceFunc(func(ctx context.Context) (*PipelineMessage, error) {
+		return nil, sourceErr
+	}), nil)
+	if !errors.Is(err, sourceErr) {
+		t.Errorf("Expected source error, got %v", err)
+	}
+}
+
+func TestPipelineRunBatchNoElements(t *testing.T) {
+	p := NewPipeline("batch")
+	_, err := p.RunBatch(context.Background(), BatchSourceFunc(func(ctx context.Context) (*PipelineMessage, error) {
+		return nil, io.EOF
+	}), nil)
+	if err == nil {
+		t.Error("Expected error for empty pipeline")
+	}
+}
+
+func TestPipelineRunBatchTopology(t *testing.T) {
+	p := NewPipeline("batch")
+	sink := newEchoElement()
+	source := newEchoElement()
+	// 输出端先于输入端添加，另有一个只通过 Bus 工作、没有连接的元素
+	p.AddElement(sink)
+	p.AddElement(source)
+	p.AddElement(newEchoElement())
+	p.Link(source, sink)
+
+	pcm := make([]byte, 16000*2)
+	result, err := p.RunBatchWithConfig(context.Background(),
+		NewPCMBatchSource(pcm, 16000, 1, 100*time.Millisecond), nil,
+		BatchConfig{IdleTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("RunBatch failed: %v", err)
+	}
+	if len(result.Audio) != len(pcm) {
+		t.Errorf("Expected %d audio bytes, got %d", len(pcm), len(result.Audio))
+	}
+	// 两个连接的元素各发布 10 条结果
+	if len(result.FinalResults) != 20 {
+		t.Errorf("Expected 20 final results, got %d", len(result.FinalResults))
+	}
+}
+
+func TestPipelineRunBatchMarkedEndpoints(t *testing.T) {
+	newPipeline := func() (*Pipeline, *echoElement, *echoElement) {
+		p := NewPipeline("batch")
+		a, b, c, d := newEchoElement(), newEchoElement(), newEchoElement(), newEchoElement()
+		p.AddElement(a)
+		p.AddElement(b)
+		p.AddElement(c)
+		p.AddElement(d)
+		p.Link(a, b)
+		p.Link(c, d)
+		return p, c, d
+	}
+	pcm := make([]byte, 16000*2)
+
+	// 两条独立的链路无法推断输入端和输出端
+	p, _, _ := newPipeline()
+	_, err := p.RunBatch(context.Background(), NewPCMBatchSource(pcm, 16000, 1, 0), nil)
+	if err == nil {
+		t.Fatal("Expected error for ambiguous endpoints")
+	}
+
+	// 标记后使用标记的元素
+	p, c, d := newPipeline()
+	c.SetSource(true)
+	d.SetSink(true)
+	result, err := p.RunBatchWithConfig(context.Background(),
+		NewPCMBatchSource(pcm, 16000, 1, 100*time.Millisecond), nil,
+		BatchConfig{IdleTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("RunBatch failed: %v", err)
+	}
+	if len(result.Audio) != len(pcm) {
+		t.Errorf("Expected %d audio bytes, got %d", len(pcm), len(result.Audio))
+	}
+	if len(result.FinalResults) != 20 {
+		t.Errorf("Expected 20 final results, got %d", len(result.FinalResults))
+	}
+}
+
+// transcriptElement 为每条音频输出一条中间结果和一条最终结果
+type transcriptElement struct {
+	*echoElement
+}
+
+func (e *transcriptElement) Start(ctx context.Context) error {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.InChan:
+				for _, text := range []*TextData{
+					{Data: []byte("hel"), TextType: "text/partial"},
+					{Data: []byte("hello "), TextType: "text/final"},
+				} {
+					select {
+					case e.OutChan <- &PipelineMessage{Type: MsgTypeData, TextData: text}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func TestPipelineRunBatchFinalTexts(t *testing.T) {
+	p := NewPipeline("batch")
+	p.AddElement(&transcriptElement{echoElement: newEchoElement()})
+
+	pcm := make([]byte, 16000*2)
+	result, err := p.RunBatchWithConfig(context.Background(),
+		NewPCMBatchSource(pcm, 16000, 1, 500*time.Millisecond), nil,
+		BatchConfig{IdleTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("RunBatch failed: %v", err)
+	}
+	// 中间结果不计入输出文本
+	if got := result.Text(); got != "hello hello " {
+		t.Errorf("Expected only final texts, got %q", got)
+	}
+}
+
+func TestPipelineRunBatchStartError(t *testing.T) {
+	p := NewPipeline("batch")
+	first := &startElement{BaseElement: NewBaseElement("first", 10)}
+	broken := &startElement{BaseElement: NewBaseElement("broken", 10), err: errors.New("handshake failed")}
+	p.AddElements([]Element{first, broken})
+	p.Link(first, broken)
+
+	_, err := p.RunBatch(context.Background(), BatchSourceFunc(func(ctx context.Context) (*PipelineMessage, error) {
+		return nil, io.EOF
+	}), nil)
+	if err == nil {
+		t.Fatal("Expected start error")
+	}
+	// 启动失败时已启动的元素被停止
+	if !first.stopped {
+		t.Error("Already started element should be stopped")
+	}
+}
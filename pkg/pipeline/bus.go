@@ -15,6 +15,7 @@ const (
 	EventWarning       EventType = "Warning"
 	EventPartialResult EventType = "PartialResult"
 	EventFinalResult   EventType = "FinalResult"
+	EventNoResult      EventType = "NoResult" // STT committed audio but produced no transcript
 	EventBargeIn       EventType = "BargeIn"
 	EventInterrupted   EventType = "Interrupted"
 	EventStarted       EventType = "Started"
@@ -32,6 +33,71 @@ const (
 	EventInterruptAcknowledged EventType = "InterruptAcknowledged" // Component acknowledges interrupt
 	EventAudioPause            EventType = "AudioPause"            // Pause audio output (hybrid mode)
 	EventAudioResume           EventType = "AudioResume"           // Resume audio output (hybrid mode)
+	EventInterruptRecovery     EventType = "InterruptRecovery"     // How the conversation continues after an interrupt (InterruptConfig.RecoveryPolicy)
+	EventAudioDuck             EventType = "AudioDuck"             // Lower assistant audio while the user talks over it (DoubleTalkDuck)
+	EventAudioUnduck           EventType = "AudioUnduck"           // Restore assistant audio volume after ducking
+
+	// Playback events, published by the audio output (e.g. AudioPacerSinkElement)
+	EventPlaybackStart EventType = "PlaybackStart" // Assistant audio started playing
+	EventPlaybackEnd   EventType = "PlaybackEnd"   // Assistant audio finished playing or was cleared
+
+	// Speaking state events, published by SpeakingTracker from the audio sent to the client
+	EventAssistantSpeakingStart EventType = "AssistantSpeakingStart" // Assistant audio started flowing to the client
+	EventAssistantSpeakingEnd   EventType = "AssistantSpeakingEnd"   // Assistant audio finished (after the hangover) or was interrupted
+
+	// Conversation memory events
+	EventSummaryUpdated EventType = "SummaryUpdated" // Older history was summarized
+
+	// Language events
+	EventLanguageChanged EventType = "LanguageChanged" // STT detected a different source language
+
+	// Text input events
+	EventTextInput EventType = "TextInput" // User typed a message instead of speaking
+
+	// Tool calling events
+	EventToolCall          EventType = "ToolCall"          // Model requested a function call; answer with ToolResultSender.SendToolResult
+	EventToolCallCancelled EventType = "ToolCallCancelled" // Model cancelled pending tool calls (usually after an interruption); their results are no longer expected
+	EventGrounding         EventType = "Grounding"         // Model searched the web (Google Search grounding) before answering
+
+	// Input control events, published by Pipeline.MuteInput / UnmuteInput
+	EventInputMuted   EventType = "InputMuted"   // User audio input is being discarded
+	EventInputUnmuted EventType = "InputUnmuted" // User audio input is processed again
+
+	// LLM events
+	EventResponseTimeout EventType = "ResponseTimeout" // LLM stalled; the request was cancelled and a fallback spoken
+	EventLLMTokenDelta   EventType = "LLMTokenDelta"   // Raw LLM text as streamed, before sentence segmentation or truncation
+
+	// Watchdog events, published by Watchdog (see Pipeline.EnableWatchdog)
+	EventPipelineStalled   EventType = "PipelineStalled"   // No messages moved through the pipeline for the configured timeout
+	EventPipelineRecovered EventType = "PipelineRecovered" // Messages are moving again after a stall
+
+	// Silence timeout events, published by SilenceTimeout (see Pipeline.EnableSilenceTimeout)
+	EventSilencePrompt EventType = "SilencePrompt" // User stayed silent after the assistant finished; a prompt was spoken
+	EventSilenceHangup EventType = "SilenceHangup" // User stayed silent through every prompt; the call is being ended
+
+	// Latency budget events, published by LatencyBudget (see Pipeline.EnableLatencyBudget)
+	EventLatencyDegraded EventType = "LatencyDegraded" // End-to-end latency exceeded the budget; a lower-latency step was applied
+	EventLatencyRestored EventType = "LatencyRestored" // Latency is well within the budget again; the last step was undone
+
+	// Prewarm events, published by Pipeline.Prewarm
+	EventPrewarmed EventType = "Prewarmed" // An element finished warming up its provider connection
+
+	// Audio sync events
+	EventAudioResync EventType = "AudioResync" // An audio stream drifted from the wall clock and is being corrected
+
+	// Inbound audio track events, published by transports that receive several audio tracks per connection
+	EventAudioTrackAdded   EventType = "AudioTrackAdded"   // A client audio track (e.g. microphone, system audio) started
+	EventAudioTrackRemoved EventType = "AudioTrackRemoved" // A client audio track ended
+
+	// Element status events, published by BaseElement.SetStatus
+	EventElementError     EventType = "ElementError"     // An element became Degraded or Failed (e.g. its provider connection dropped)
+	EventElementRecovered EventType = "ElementRecovered" // A Degraded or Failed element is Running again
+
+	// Diarization events, published by STT elements with speaker labels enabled
+	EventSpeakerTurn EventType = "SpeakerTurn" // A different speaker started talking; published before the transcript it belongs to
+
+	// Structured STT results, published by STT elements right after EventPartialResult / EventFinalResult
+	EventTranscriptResult EventType = "TranscriptResult" // Transcript with segment and word timings, e.g. for subtitle alignment
 )
 
 // Event 代表一条通用事件
@@ -39,6 +105,9 @@ type Event struct {
 	Type      EventType
 	Timestamp time.Time
 	Payload   interface{} // 任意附加数据
+
+	// Attributes 触发该事件的消息的 Attributes（可能为 nil）
+	Attributes Attributes
 }
 
 // ResponseStartPayload is the payload for EventResponseStart
@@ -68,6 +137,24 @@ type TextDeltaPayload struct {
 	IsFinal    bool
 }
 
+// TextInputPayload is the payload for EventTextInput
+type TextInputPayload struct {
+	SessionID string
+	Text      string
+}
+
+// SummaryPayload is the payload for EventSummaryUpdated
+type SummaryPayload struct {
+	Summary            string // Latest summary of the earlier conversation
+	SummarizedMessages int    // Number of history messages folded into the summary this round
+}
+
+// AudioTrackPayload is the payload for EventAudioTrackAdded and EventAudioTrackRemoved
+type AudioTrackPayload struct {
+	TrackID  string // Track ID, also set as AttrAudioTrack on the track's audio messages
+	StreamID string // ID of the stream the client grouped the track in
+}
+
 // VADPayload is the payload for VAD events
 type VADPayload struct {
 	AudioMs      int     // Audio position in milliseconds
@@ -78,6 +165,84 @@ type VADPayload struct {
 	Channels     int     // Number of channels in PreRollAudio
 }
 
+// NoResultPayload is the payload for EventNoResult
+type NoResultPayload struct {
+	Source  string        // Name of the STT element
+	Reason  string        // "timeout" if no final transcript arrived in time, "empty" if it was blank
+	Timeout time.Duration // Configured result timeout
+}
+
+// TranscriptResult is the payload for EventTranscriptResult.
+// Times are offsets from the start of the audio the STT element sent to its
+// provider; StartMs and EndMs are both 0 when the provider reports no timings.
+type TranscriptResult struct {
+	Source  string       // Name of the STT element
+	Text    string       // Transcript, same as the EventPartialResult / EventFinalResult payload
+	IsFinal bool         // true for a final result
+	StartMs int64        // Start of the recognized speech in milliseconds
+	EndMs   int64        // End of the recognized speech in milliseconds
+	Words   []WordTiming // Word-level timings, if the provider supplies them
+}
+
+// HasTimings reports whether the provider supplied timings for the result
+func (r *TranscriptResult) HasTimings() bool {
+	return r.EndMs > 0
+}
+
+// SpeakerTurnPayload is the payload for EventSpeakerTurn
+type SpeakerTurnPayload struct {
+	Source  string // Name of the STT element
+	Speaker int    // 0-based speaker index; render as "Speaker <Speaker+1>"
+	Label   string // Provider's speaker label (e.g. "A")
+}
+
+// ElementErrorPayload is the payload for EventElementError
+type ElementErrorPayload struct {
+	Element string       // Element name
+	State   ElementState // ElementStateDegraded or ElementStateFailed
+	Err     error        // Underlying error
+}
+
+// ElementRecoveredPayload is the payload for EventElementRecovered
+type ElementRecoveredPayload struct {
+	Element  string        // Element name
+	Downtime time.Duration // How long the element was Degraded or Failed
+}
+
+// ResponseTimeoutPayload is the payload for EventResponseTimeout
+type ResponseTimeoutPayload struct {
+	Source   string        // Name of the LLM element
+	Timeout  time.Duration // Configured response timeout
+	Fallback string        // Text spoken instead of the response
+}
+
+// LLMTokenDeltaPayload is the payload for EventLLMTokenDelta
+type LLMTokenDeltaPayload struct {
+	Source string // Name of the LLM element
+	Text   string // Raw text received since the previous delta
+	Seq    int    // Position of this delta within the response, starting at 0
+}
+
+// ToolCallPayload is the payload for EventToolCall
+type ToolCallPayload struct {
+	CallID    string // ID to pass back to SendToolResult
+	Name      string // Function name
+	Arguments string // JSON-encoded function arguments
+}
+
+// ToolCallCancelledPayload is the payload for EventToolCallCancelled
+type ToolCallCancelledPayload struct {
+	CallIDs []string // IDs of the cancelled calls
+}
+
+// GroundingPayload is the payload for EventGrounding.
+// A search is published twice: once with the queries, once with the results.
+type GroundingPayload struct {
+	Source  string   // Name of the model element
+	Queries []string // Search queries issued by the model
+	Result  string   // Search results returned to the model
+}
+
 // InterruptSource defines the source of interrupt signal
 type InterruptSource int
 
@@ -96,6 +261,32 @@ type InterruptPayload struct {
 	Reason        string          // Reason for interrupt
 }
 
+// InterruptRecoveryPayload is the payload for EventInterruptRecovery
+type InterruptRecoveryPayload struct {
+	Policy          InterruptRecoveryPolicy // Recovery path chosen for this interrupt
+	ResponseID      string                  // ID of the interrupted response
+	InterruptedText string                  // Response text sent for playback before the interrupt
+}
+
+// AudioDuckPayload is the payload for EventAudioDuck
+type AudioDuckPayload struct {
+	Gain float64 // Linear gain applied to assistant audio while ducked (0-1)
+}
+
+// PrewarmPayload is the payload for EventPrewarmed
+type PrewarmPayload struct {
+	Element  string        // Name of the prewarmed element
+	Duration time.Duration // Time spent warming up, no longer paid by the first turn
+	Err      error         // Non-nil if warming up failed; the first turn then connects as usual
+}
+
+// AudioResyncPayload is the payload for EventAudioResync
+type AudioResyncPayload struct {
+	Source    string        // Name of the resync element
+	Drift     time.Duration // Wall clock minus audio time; positive when the audio runs slow
+	Corrected time.Duration // Total audio inserted (positive) or dropped (negative) so far
+}
+
 // Bus 定义了事件总线的接口
 type Bus interface {
 	// Subscribe 订阅某一类型的事件，事件将被投递到 ch 通道
@@ -0,0 +1,208 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// Clock 抽象了按节奏输出的元素（AudioPacerSink、TextPacer 等）所用的时间源。
+// 默认使用系统时钟；测试中注入 ManualClock，由测试代码推进时间，
+// 不需要 time.Sleep 就能确定性地验证节奏控制逻辑。
+//
+// 工作原理:
+//   - SystemClock 直接调用 time 包
+//   - ManualClock 的时间只在 Advance 时前进，到期的 After 和 Ticker 按到期顺序触发
+//   - BlockUntil 等待元素进入等待状态，避免测试在元素开始等待之前推进时间
+//
+// 使用示例:
+//
+//	clock := pipeline.NewManualClock(time.Unix(0, 0))
+//	pacer := elements.NewTextPacerElementWithConfig(elements.TextPacerConfig{
+//	    Enabled: true,
+//	    Clock:   clock,
+//	})
+//	// ...
+//	clock.BlockUntil(1)
+//	clock.Advance(100 * time.Millisecond)
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间源
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+	// After 在 d 之后向返回的通道发送当时的时间
+	After(d time.Duration) <-chan time.Time
+	// NewTicker 创建周期为 d 的 Ticker，d 必须大于 0
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker 周期性触发的定时器，与 time.Ticker 一样，接收方跟不上时丢弃多余的触发
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock 使用系统时间的 Clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	t *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+
+func (t systemTicker) Stop() { t.t.Stop() }
+
+// ManualClock 手动推进的 Clock，用于测试
+type ManualClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*manualWaiter
+}
+
+// manualWaiter 一个尚未触发的 After 或 Ticker
+type manualWaiter struct {
+	deadline time.Time
+	period   time.Duration // 大于 0 时为 Ticker
+	ch       chan time.Time
+}
+
+// NewManualClock 创建从 start 开始的 ManualClock
+func NewManualClock(start time.Time) *ManualClock {
+	c := &ManualClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now 返回当前时间
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After 在时间推进 d 之后触发，d <= 0 时立即触发
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.addWaiter(&manualWaiter{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// NewTicker 创建周期为 d 的 Ticker
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("pipeline: non-positive interval for ManualClock.NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &manualWaiter{deadline: c.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	c.addWaiter(w)
+	return &manualTicker{clock: c, w: w}
+}
+
+// Advance 把时间推进 d，期间到期的 After 和 Ticker 按到期顺序触发
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		w := c.nextWaiter(target)
+		if w == nil {
+			break
+		}
+		c.now = w.deadline
+
+		// 与 time.Ticker 一样，接收方没有取走上一次触发时丢弃本次
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.removeWaiter(w)
+		}
+	}
+	c.now = target
+}
+
+// Waiters 返回尚未触发的 After 和未停止的 Ticker 数量
+func (c *ManualClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil 阻塞直到尚未触发的 After 和未停止的 Ticker 数量至少为 n
+func (c *ManualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// addWaiter 需持有锁
+func (c *ManualClock) addWaiter(w *manualWaiter) {
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+}
+
+// removeWaiter 需持有锁
+func (c *ManualClock) removeWaiter(w *manualWaiter) {
+	for i, x := range c.waiters {
+		if x == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// nextWaiter 返回不晚于 target 且最早到期的等待者，需持有锁
+func (c *ManualClock) nextWaiter(target time.Time) *manualWaiter {
+	var next *manualWaiter
+	for _, w := range c.waiters {
+		if w.deadline.After(target) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+type manualTicker struct {
+	clock *ManualClock
+	w     *manualWaiter
+}
+
+func (t *manualTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *manualTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.removeWaiter(t.w)
+}
@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock_After(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewManualClock(start)
+
+	ch := c.After(100 * time.Millisecond)
+	if c.Waiters() != 1 {
+		t.Fatalf("expected 1 waiter, got %d", c.Waiters())
+	}
+
+	c.Advance(99 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("After fired early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+	select {
+	case fired := <-ch:
+		if want := start.Add(100 * time.Millisecond); !fired.Equal(want) {
+			t.Errorf("fired at %v, want %v", fired, want)
+		}
+	default:
+		t.Fatal("After did not fire")
+	}
+	if c.Waiters() != 0 {
+		t.Errorf("expected no waiters, got %d", c.Waiters())
+	}
+
+	// 非正时长立即触发
+	select {
+	case <-c.After(0):
+	default:
+		t.Fatal("After(0) did not fire immediately")
+	}
+}
+
+func TestManualClock_Ticker(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewManualClock(start)
+
+	ticker := c.NewTicker(20 * time.Millisecond)
+	for i := 1; i <= 3; i++ {
+		c.Advance(20 * time.Millisecond)
+		select {
+		case fired := <-ticker.C():
+			if want := start.Add(time.Duration(i) * 20 * time.Millisecond); !fired.Equal(want) {
+				t.Errorf("tick %d at %v, want %v", i, fired, want)
+			}
+		default:
+			t.Fatalf("tick %d missing", i)
+		}
+	}
+
+	// 接收方跟不上时只保留一次触发
+	c.Advance(100 * time.Millisecond)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatal("expected dropped ticks")
+	default:
+	}
+	if got := c.Now().Sub(start); got != 160*time.Millisecond {
+		t.Errorf("Now = start+%v, want start+160ms", got)
+	}
+
+	ticker.Stop()
+	c.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
+
+func TestManualClock_BlockUntil(t *testing.T) {
+	c := NewManualClock(time.Unix(0, 0))
+
+	done := make(chan struct{})
+	go func() {
+		<-c.After(time.Second)
+		close(done)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiter not released")
+	}
+}
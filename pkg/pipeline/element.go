@@ -25,6 +25,62 @@ type Element interface {
 	SetProperty(name string, value interface{}) error
 	GetProperty(name string) (interface{}, error)
 	GetName() string
+	// IsSource 报告元素是否为 Pipeline 的输入端，Push 发送到该元素
+	IsSource() bool
+	// IsSink 报告元素是否为 Pipeline 的输出端，Pull 从该元素读取
+	IsSink() bool
+	// Flush 丢弃元素中排队、缓冲和正在生成、尚未输出的数据，不阻塞
+	// 打断时由 Pipeline 对输出链路上的元素调用，见 Pipeline.SetSpeechInput
+	Flush()
+}
+
+// HealthChecker 由持有外部连接（如 WebSocket）的元素实现
+// Healthy 报告连接当前是否存活；Pipeline.Health 汇总所有实现该接口的元素
+type HealthChecker interface {
+	Healthy() bool
+}
+
+// ToolResultSender 由支持函数调用的实时模型元素实现
+// 收到 EventToolCall 后，调用方执行工具并通过 SendToolResult 把结果交还模型，否则模型会一直等待
+type ToolResultSender interface {
+	SendToolResult(callID string, result any) error
+}
+
+// ConversationRecorder 由维护对话历史的元素实现（如 ChatElement）
+// Pipeline 用它把开场白等不经过 LLM 的助手文本记入历史
+type ConversationRecorder interface {
+	AppendMessage(role, content string) error
+}
+
+// InputResetter 由保存用户语音中间状态的元素实现（如 VAD、STT）
+// Pipeline.MuteInput 调用 ResetInput 丢弃尚未提交的语音，且不产生语音结束事件或识别结果
+type InputResetter interface {
+	ResetInput()
+}
+
+// Prewarmer 由连接外部服务商的元素实现（如 LLM、TTS）
+// Prewarm 在元素 Start 之后、用户开口之前预先建立连接（TLS 握手、WebSocket）或发送极小的空请求，
+// 让第一轮对话不必承担冷启动延迟；见 Pipeline.SetPrewarmOnConnect
+type Prewarmer interface {
+	Prewarm(ctx context.Context) error
+}
+
+// Drainer 由在内部缓冲数据或有处理中请求的元素实现（如 TTS、音频播放）
+// Drain 阻塞到已收到的输入全部处理完并输出，或 ctx 到期；Pipeline.StopGraceful 在 Stop 之前调用
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// AudioFormatProvider 由输出原始音频的元素实现（如解码、混音、实时模型）
+// OutputFormat 声明输出的采样率和通道数，Link 时传给实现了 AudioFormatReceiver 的下游
+type AudioFormatProvider interface {
+	OutputFormat() AudioFormat
+}
+
+// AudioFormatReceiver 由需要知道输入格式的元素实现（如重采样）
+// Link 时上游实现了 AudioFormatProvider 则调用 SetInputFormat；消息自带的 SampleRate/Channels 优先
+type AudioFormatReceiver interface {
+	SetInputFormat(format AudioFormat)
 }
 
 type ElementWithProperties interface {
@@ -38,6 +94,12 @@ type BaseElement struct {
 	propertyDescs map[string]PropertyDesc // 保存此元素"可用属性"的描述信息
 	properties    map[string]interface{}  // 保存此元素"当前属性值"
 	bus           Bus
+	language      *LanguageContext
+	providerLog   *ProviderLogger
+	source        bool // 是否为 Pipeline 的输入端
+	sink          bool // 是否为 Pipeline 的输出端
+	status        elementStatus
+	stats         elementStats
 
 	InChan  chan *PipelineMessage
 	OutChan chan *PipelineMessage
@@ -77,6 +139,32 @@ func (b *BaseElement) Stop() error {
 	return nil
 }
 
+// Flush 默认不做任何事，缓冲输出数据的元素（如 TTS、重采样、音频播放）自行实现
+func (b *BaseElement) Flush() {}
+
+// DropQueued 丢弃 InChan 和 OutChan 中排队的消息，返回丢弃的条数，供元素实现 Flush
+func (b *BaseElement) DropQueued() int {
+	n := dropQueued(b.InChan) + dropQueued(b.OutChan)
+	b.resetPending()
+	return n
+}
+
+// dropQueued 不阻塞地取出 ch 中的全部消息
+func dropQueued(ch chan *PipelineMessage) int {
+	n := 0
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return n
+			}
+			n++
+		default:
+			return n
+		}
+	}
+}
+
 func (b *BaseElement) Bus() Bus {
 	return b.bus
 }
@@ -85,6 +173,48 @@ func (b *BaseElement) SetBus(bus Bus) {
 	b.bus = bus
 }
 
+// LanguageContext 返回 Pipeline 的语言上下文，未设置时为 nil
+func (b *BaseElement) LanguageContext() *LanguageContext {
+	return b.language
+}
+
+// SetLanguageContext 设置语言上下文，由 Pipeline 调用
+func (b *BaseElement) SetLanguageContext(lc *LanguageContext) {
+	b.language = lc
+}
+
+// ProviderLogger 返回服务商审计日志，未启用时为 nil（调用 Log 是安全的空操作）
+func (b *BaseElement) ProviderLogger() *ProviderLogger {
+	return b.providerLog
+}
+
+// SetProviderLogger 设置服务商审计日志，由 Pipeline 调用
+func (b *BaseElement) SetProviderLogger(l *ProviderLogger) {
+	b.providerLog = l
+}
+
+// IsSource 报告元素是否被标记为 Pipeline 的输入端
+func (b *BaseElement) IsSource() bool {
+	return b.source
+}
+
+// IsSink 报告元素是否被标记为 Pipeline 的输出端
+func (b *BaseElement) IsSink() bool {
+	return b.sink
+}
+
+// SetSource 把元素标记为 Pipeline 的输入端，需在 Pipeline.Start 之前调用
+// 未标记任何输入端时，Push 发送到第一个添加的元素
+func (b *BaseElement) SetSource(source bool) {
+	b.source = source
+}
+
+// SetSink 把元素标记为 Pipeline 的输出端，需在 Pipeline.Start 之前调用
+// 未标记任何输出端时，Pull 从最后一个添加的元素读取
+func (b *BaseElement) SetSink(sink bool) {
+	b.sink = sink
+}
+
 func (b *BaseElement) RegisterProperty(desc PropertyDesc) error {
 	if _, exists := b.propertyDescs[desc.Name]; exists {
 		return fmt.Errorf("property %s already registered", desc.Name)
@@ -0,0 +1,134 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// ElementState 让应用知道每个元素是否真的在工作，而不仅仅是 Start 成功过。
+// 服务商连接断开的元素仍在运行，但已经收不到结果，此前只能从日志里发现。
+//
+// 主要功能:
+//   - 状态: Initializing → Running，连接断开等可恢复的问题为 Degraded，不可恢复为 Failed
+//   - 状态变化时在总线上发布 EventElementError / EventElementRecovered
+//   - Pipeline.Statuses 汇总所有元素的状态，可用于健康检查接口
+//
+// 使用示例:
+//
+//	// 元素内部
+//	e.SetStatus(pipeline.ElementStateDegraded, err)
+//	// 应用
+//	for name, state := range p.Statuses() {
+//	    log.Printf("%s: %s", name, state)
+//	}
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// ElementState 元素的运行状态，见 BaseElement.Status
+type ElementState int
+
+const (
+	// ElementStateInitializing 元素尚未启动
+	ElementStateInitializing ElementState = iota
+	// ElementStateRunning 元素正常运行
+	ElementStateRunning
+	// ElementStateDegraded 元素仍在运行但无法正常工作（如服务商连接断开），可能自行恢复
+	ElementStateDegraded
+	// ElementStateFailed 元素已无法工作，需要重启或拆除会话
+	ElementStateFailed
+)
+
+func (s ElementState) String() string {
+	switch s {
+	case ElementStateInitializing:
+		return "initializing"
+	case ElementStateRunning:
+		return "running"
+	case ElementStateDegraded:
+		return "degraded"
+	case ElementStateFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// StatusReporter 由报告运行状态的元素实现，所有嵌入 BaseElement 的元素都满足
+// Pipeline.Statuses 汇总所有元素的状态
+type StatusReporter interface {
+	Status() ElementState
+}
+
+// elementStatus 元素的运行状态和导致降级/失败的错误
+type elementStatus struct {
+	mu    sync.Mutex
+	state ElementState
+	err   error
+	since time.Time // 进入当前状态的时间
+}
+
+// Status 返回元素当前的运行状态
+func (b *BaseElement) Status() ElementState {
+	b.status.mu.Lock()
+	defer b.status.mu.Unlock()
+	return b.status.state
+}
+
+// StatusError 返回导致元素进入 Degraded/Failed 的错误，其他状态下为 nil
+func (b *BaseElement) StatusError() error {
+	b.status.mu.Lock()
+	defer b.status.mu.Unlock()
+	return b.status.err
+}
+
+// SetStatus 更新元素状态，状态变化时在总线上发布事件:
+//   - 进入 Degraded 或 Failed 时发布 EventElementError
+//   - 从 Degraded 或 Failed 回到 Running 时发布 EventElementRecovered
+//
+// 状态不变时只更新错误，不重复发布事件，元素可以在每次检查时调用它
+func (b *BaseElement) SetStatus(state ElementState, err error) {
+	if state != ElementStateDegraded && state != ElementStateFailed {
+		err = nil
+	}
+
+	b.status.mu.Lock()
+	prev := b.status.state
+	b.status.err = err
+	if state == prev {
+		b.status.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	downtime := now.Sub(b.status.since)
+	b.status.state = state
+	b.status.since = now
+	b.status.mu.Unlock()
+
+	bus := b.Bus()
+	if bus == nil {
+		return
+	}
+	switch {
+	case state == ElementStateDegraded || state == ElementStateFailed:
+		bus.Publish(Event{
+			Type:      EventElementError,
+			Timestamp: now,
+			Payload:   ElementErrorPayload{Element: b.name, State: state, Err: err},
+		})
+	case state == ElementStateRunning && (prev == ElementStateDegraded || prev == ElementStateFailed):
+		bus.Publish(Event{
+			Type:      EventElementRecovered,
+			Timestamp: now,
+			Payload:   ElementRecoveredPayload{Element: b.name, Downtime: downtime},
+		})
+	}
+}
+
+// markStarted 由 Pipeline 在元素启动成功后调用：仍为 Initializing 的元素进入 Running
+// 启动过程中已报告 Degraded 等状态的元素保持原状态
+func (b *BaseElement) markStarted() {
+	b.status.mu.Lock()
+	defer b.status.mu.Unlock()
+	if b.status.state == ElementStateInitializing {
+		b.status.state = ElementStateRunning
+		b.status.since = time.Now()
+	}
+}
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitEvent 等待 ch 上的下一个事件
+func waitEvent(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case evt := <-ch:
+		return evt
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for event")
+		return Event{}
+	}
+}
+
+func TestElementStatusEvents(t *testing.T) {
+	elem := &MockElement{NewBaseElement("stt", 10)}
+	p := NewPipeline("test")
+	p.AddElement(elem)
+
+	errorsCh := make(chan Event, 10)
+	recovered := make(chan Event, 10)
+	p.Bus().Subscribe(EventElementError, errorsCh)
+	p.Bus().Subscribe(EventElementRecovered, recovered)
+
+	if got := elem.Status(); got != ElementStateInitializing {
+		t.Fatalf("Expected initializing before start, got %v", got)
+	}
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	if got := p.Statuses()["stt"]; got != ElementStateRunning {
+		t.Fatalf("Expected running after start, got %v", got)
+	}
+
+	// 连接断开：进入 Degraded 并发布错误
+	dropped := errors.New("websocket: close 1006 (abnormal closure)")
+	elem.SetStatus(ElementStateDegraded, dropped)
+	payload := waitEvent(t, errorsCh).Payload.(ElementErrorPayload)
+	if payload.Element != "stt" || payload.State != ElementStateDegraded || !errors.Is(payload.Err, dropped) {
+		t.Errorf("Unexpected error payload: %+v", payload)
+	}
+	if !errors.Is(elem.StatusError(), dropped) {
+		t.Errorf("Expected StatusError to return the drop, got %v", elem.StatusError())
+	}
+
+	// 状态不变时不重复发布
+	elem.SetStatus(ElementStateDegraded, dropped)
+	select {
+	case evt := <-errorsCh:
+		t.Fatalf("Unexpected repeated event: %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 恢复
+	elem.SetStatus(ElementStateRunning, nil)
+	if rec := waitEvent(t, recovered).Payload.(ElementRecoveredPayload); rec.Element != "stt" || rec.Downtime < 0 {
+		t.Errorf("Unexpected recovered payload: %+v", rec)
+	}
+	if elem.StatusError() != nil {
+		t.Errorf("Expected no error after recovery, got %v", elem.StatusError())
+	}
+
+	// Degraded -> Failed 再次发布
+	elem.SetStatus(ElementStateDegraded, dropped)
+	waitEvent(t, errorsCh)
+	elem.SetStatus(ElementStateFailed, dropped)
+	if payload := waitEvent(t, errorsCh).Payload.(ElementErrorPayload); payload.State != ElementStateFailed {
+		t.Errorf("Expected failed state, got %v", payload.State)
+	}
+}
+
+func TestElementStateString(t *testing.T) {
+	for state, want := range map[ElementState]string{
+		ElementStateInitializing: "initializing",
+		ElementStateRunning:      "running",
+		ElementStateDegraded:     "degraded",
+		ElementStateFailed:       "failed",
+		ElementState(99):         "unknown",
+	} {
+		if got := state.String(); got != want {
+			t.Errorf("%d: expected %q, got %q", state, want, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// flushOutput 在打断时清空输出链路（Element.Flush）。
+//
+// 用户打断时，已经合成的 TTS 音频还排在 TTS、重采样、播放元素的通道和缓冲里，
+// 只停止生成的话助手还会继续说一小段。打断管理器确认打断时调用 Pipeline.flushOutput，
+// 对输出链路上的每个元素调用 Element.Flush 丢弃这些数据。
+//
+// 输出链路是 SetSpeechInput 指定的元素及其经 Link 连接的所有下游；
+// 未设置时为维护对话历史的元素（ConversationRecorder，即 LLM 或实时模型）的所有下游，不含其本身；
+// 两者都没有时不清空任何元素。输入侧元素不在输出链路上，重采样等排队的用户音频不会被丢弃。
+//
+// Pipeline.Stop 持锁等待打断管理器的事件循环退出，事件循环里不能再获取 Pipeline 的锁，
+// 因此输出链路在拓扑变化时（持锁）预先算好，flushOutput 无锁读取。
+package pipeline
+
+// refreshFlushTargetsLocked 重新计算打断时需要清空的元素，调用方需持有锁
+func (p *Pipeline) refreshFlushTargetsLocked() {
+	targets := p.outputPathLocked()
+	p.flushTargets.Store(&targets)
+}
+
+// outputPathLocked 按链路顺序返回输出链路上的元素，上游在前，调用方需持有锁
+func (p *Pipeline) outputPathLocked() []Element {
+	if p.speechInput != nil {
+		return p.downstreamLocked([]Element{p.speechInput})
+	}
+
+	var models []Element
+	for _, e := range p.elements {
+		if _, ok := e.(ConversationRecorder); ok {
+			models = append(models, e)
+		}
+	}
+	// 模型本身的输入队列里是用户的输入，只清空它的下游
+	return p.downstreamLocked(models)[len(models):]
+}
+
+// downstreamLocked 返回 roots 及其经 Link 连接的所有下游，roots 在前，调用方需持有锁
+func (p *Pipeline) downstreamLocked(roots []Element) []Element {
+	path := append([]Element(nil), roots...)
+	seen := make(map[Element]bool, len(roots))
+	for _, e := range roots {
+		seen[e] = true
+	}
+	for i := 0; i < len(path); i++ {
+		f := p.fanouts[path[i]]
+		if f == nil {
+			continue
+		}
+		f.mu.Lock()
+		for _, t := range f.targets {
+			if !seen[t.dst] {
+				seen[t.dst] = true
+				path = append(path, t.dst)
+			}
+		}
+		f.mu.Unlock()
+	}
+	return path
+}
+
+// flushOutput 丢弃输出链路上排队和缓冲的数据，上游先清空，避免刚清空的下游又收到数据
+func (p *Pipeline) flushOutput() {
+	targets := p.flushTargets.Load()
+	if targets == nil {
+		return
+	}
+	for _, e := range *targets {
+		e.Flush()
+	}
+}
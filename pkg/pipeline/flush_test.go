@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stalledElement 不消费输入，模拟排满待播音频的 TTS/播放元素
+type stalledElement struct {
+	*BaseElement
+	flushes atomic.Int32
+}
+
+func newStalledElement(name string) *stalledElement {
+	return &stalledElement{BaseElement: NewBaseElement(name, 16)}
+}
+
+func (e *stalledElement) Start(ctx context.Context) error {
+	return nil
+}
+
+func (e *stalledElement) Stop() error {
+	return nil
+}
+
+func (e *stalledElement) Flush() {
+	e.flushes.Add(1)
+	e.DropQueued()
+}
+
+func queueAudio(e *stalledElement, n int) {
+	for i := 0; i < n; i++ {
+		e.InChan <- &PipelineMessage{Type: MsgTypeAudio, AudioData: &AudioData{Data: make([]byte, 640)}}
+	}
+}
+
+func TestPipelineInterruptFlushesOutputPath(t *testing.T) {
+	p := NewPipeline("test")
+	input := newStalledElement("input")
+	tts := newStalledElement("tts")
+	sink := newStalledElement("sink")
+	p.AddElements([]Element{input, tts, sink})
+	p.Link(tts, sink)
+	p.SetSpeechInput(tts)
+
+	config := DefaultInterruptConfig()
+	config.InterruptCooldownMs = 0
+	im := p.EnableInterruptManager(config)
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+
+	queueAudio(input, 2)
+	queueAudio(tts, 5)
+
+	// 打断管理器在事件循环中订阅，重发直到进入 AI 响应状态
+	deadline := time.Now().Add(time.Second)
+	for im.GetState() != InterruptStateAIResponding {
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for response to start")
+		}
+		p.Bus().Publish(Event{
+			Type:      EventResponseStart,
+			Timestamp: time.Now(),
+			Payload:   &ResponseStartPayload{ResponseID: "resp_001"},
+		})
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	im.TriggerManualInterrupt()
+
+	deadline = time.Now().Add(time.Second)
+	for tts.flushes.Load() == 0 || sink.flushes.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for output path to be flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if n := len(tts.InChan); n != 0 {
+		t.Errorf("Expected queued audio to be discarded, %d messages left", n)
+	}
+	if n := input.flushes.Load(); n != 0 {
+		t.Errorf("Input element should not be flushed, got %d flushes", n)
+	}
+	if n := len(input.InChan); n != 2 {
+		t.Errorf("Input queue should be untouched, got %d messages", n)
+	}
+}
+
+// stalledModel 维护对话历史的 stalledElement，模拟 LLM/实时模型元素
+type stalledModel struct {
+	*stalledElement
+}
+
+func (e *stalledModel) AppendMessage(role, content string) error {
+	return nil
+}
+
+func TestPipelineFlushWithoutSpeechInput(t *testing.T) {
+	// 没有 SetSpeechInput 也没有模型元素：不清空任何元素，输入侧排队的用户音频保留
+	p := NewPipeline("test")
+	resample := newStalledElement("resample")
+	sink := newStalledElement("sink")
+	p.AddElements([]Element{resample, sink})
+	p.Link(resample, sink)
+
+	queueAudio(resample, 3)
+	p.flushOutput()
+
+	if resample.flushes.Load() != 0 || sink.flushes.Load() != 0 {
+		t.Errorf("Expected no element to be flushed, got %d and %d", resample.flushes.Load(), sink.flushes.Load())
+	}
+	if n := len(resample.InChan); n != 3 {
+		t.Errorf("Input queue should be untouched, got %d messages", n)
+	}
+
+	// 有模型元素时清空它的下游，不清空模型本身和输入侧
+	p = NewPipeline("test")
+	input := newStalledElement("input")
+	model := &stalledModel{newStalledElement("model")}
+	output := newStalledElement("output")
+	p.AddElements([]Element{input, model, output})
+	p.Link(input, model)
+	p.Link(model, output)
+
+	queueAudio(input, 2)
+	queueAudio(model.stalledElement, 2)
+	queueAudio(output, 3)
+	p.flushOutput()
+
+	if output.flushes.Load() != 1 {
+		t.Errorf("Expected the model output to be flushed once, got %d", output.flushes.Load())
+	}
+	if n := len(output.InChan); n != 0 {
+		t.Errorf("Expected queued audio to be discarded, %d messages left", n)
+	}
+	if input.flushes.Load() != 0 || model.flushes.Load() != 0 {
+		t.Errorf("Input and model should not be flushed, got %d and %d", input.flushes.Load(), model.flushes.Load())
+	}
+	if len(input.InChan) != 2 || len(model.InChan) != 2 {
+		t.Errorf("Input queues should be untouched, got %d and %d messages", len(input.InChan), len(model.InChan))
+	}
+}
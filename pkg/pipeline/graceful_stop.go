@@ -0,0 +1,127 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// StopGraceful 在停止 Pipeline 之前先排空已有的数据，
+// 挂断前的告别语、TTS 正在合成的最后半句话都能完整播出，不会被 Stop 截断。
+//
+// 工作原理:
+//   - 拒绝新的输入，等待各元素通道中排队的消息流过 Pipeline
+//   - 按添加顺序调用各 Drainer 元素的 Drain（TTS、音频播放等），上游刷出的数据再经过下游
+//   - 排空或 ctx 到期后调用 Stop
+//
+// 使用示例:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	p.StopGraceful(ctx)
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrPipelineStopping StopGraceful 期间注入输入时返回
+var ErrPipelineStopping = errors.New("pipeline stopping")
+
+const (
+	// drainPollInterval 检查各通道是否排空的间隔
+	drainPollInterval = 10 * time.Millisecond
+	// drainIdlePolls 连续多少次检查都没有待处理消息才算排空，
+	// 覆盖元素已从 InChan 取出、尚未写入 OutChan 的短暂窗口
+	drainIdlePolls = 3
+)
+
+// StopGraceful 优雅停止 Pipeline，返回时 Pipeline 已经停止
+//
+// Stop 立即停止元素并取消 context，各元素通道中排队的消息（如 TTS 尚未送到音轨的最后半句话）会被丢弃。
+// StopGraceful 先排空再停止:
+//   - 不再接受新的输入：Push 丢弃消息，PushText 返回 ErrPipelineStopping
+//   - 等待各元素处理完 InChan 中已有的消息，输出端的消息需要调用方继续 Pull 取走
+//   - 按添加顺序调用实现 Drainer 的元素，输出缓冲中的数据，并等待下游处理完
+//   - 最后调用 Stop
+//
+// ctx 到期时不再等待，仍然调用 Stop，并返回 ctx.Err()
+func (p *Pipeline) StopGraceful(ctx context.Context) error {
+	p.draining.Store(true)
+	defer p.draining.Store(false)
+
+	err := p.drain(ctx)
+	if stopErr := p.Stop(); stopErr != nil {
+		return stopErr
+	}
+	return err
+}
+
+// drain 等待已有的消息流过 Pipeline，并依次刷出各元素的缓冲
+func (p *Pipeline) drain(ctx context.Context) error {
+	if err := p.waitIdle(ctx); err != nil {
+		return err
+	}
+
+	p.Lock()
+	elements := append([]Element(nil), p.elements...)
+	p.Unlock()
+
+	// 上游先刷出，刷出的数据再经过下游的缓冲
+	for _, e := range elements {
+		d, ok := e.(Drainer)
+		if !ok {
+			continue
+		}
+		if err := d.Drain(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("[Pipeline] %s: failed to drain element %s: %v", p.name, e.GetName(), err)
+		}
+		if err := p.waitIdle(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitIdle 等待所有元素的输入通道、连接中的输出通道和输出端都没有待处理的消息
+func (p *Pipeline) waitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	idle := 0
+	for {
+		if p.pendingMessages() {
+			idle = 0
+		} else if idle++; idle >= drainIdlePolls {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingMessages 报告是否还有消息在元素之间排队
+// 没有连接下游的非输出端元素的 OutChan 无人读取，不计入
+func (p *Pipeline) pendingMessages() bool {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, e := range p.elements {
+		if len(e.In()) > 0 {
+			return true
+		}
+	}
+	for src, f := range p.fanouts {
+		if len(src.Out()) > 0 || f.busy.Load() {
+			return true
+		}
+	}
+	if sink := p.sinkLocked(); sink != nil && len(sink.Out()) > 0 {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// relayElement 逐条转发消息，每条消息模拟 delay 的处理时间
+type relayElement struct {
+	*BaseElement
+	delay time.Duration
+}
+
+func newRelayElement(name string, delay time.Duration) *relayElement {
+	return &relayElement{BaseElement: NewBaseElement(name, 64), delay: delay}
+}
+
+func (e *relayElement) Start(ctx context.Context) error {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.InChan:
+				time.Sleep(e.delay)
+				e.OutChan <- msg
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *relayElement) Stop() error {
+	return nil
+}
+
+// bufferElement 缓冲收到的消息，直到 Drain 才输出（类似分句前的文本缓冲）
+type bufferElement struct {
+	*BaseElement
+	mu      sync.Mutex
+	held    []*PipelineMessage
+	onDrain func()
+	block   bool // Drain 一直阻塞到 ctx 到期
+}
+
+func newBufferElement(name string) *bufferElement {
+	return &bufferElement{BaseElement: NewBaseElement(name, 64)}
+}
+
+func (e *bufferElement) Start(ctx context.Context) error {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-e.InChan:
+				e.mu.Lock()
+				e.held = append(e.held, msg)
+				e.mu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *bufferElement) Stop() error {
+	return nil
+}
+
+func (e *bufferElement) Drain(ctx context.Context) error {
+	if e.onDrain != nil {
+		e.onDrain()
+	}
+	if e.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	e.mu.Lock()
+	held := e.held
+	e.held = nil
+	e.mu.Unlock()
+	for _, msg := range held {
+		e.OutChan <- msg
+	}
+	return nil
+}
+
+func TestPipelineStopGraceful(t *testing.T) {
+	const n = 40
+
+	p := NewPipeline("test")
+	first := newRelayElement("first", time.Millisecond)
+	buffer := newBufferElement("buffer")
+	last := newRelayElement("last", 2*time.Millisecond)
+	p.AddElements([]Element{first, buffer, last})
+	p.Link(first, buffer)
+	p.Link(buffer, last)
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	received := make(chan *PipelineMessage, n+1)
+	go func() {
+		for {
+			msg, err := p.PullTimeout(2 * time.Second)
+			if err != nil {
+				return
+			}
+			received <- msg
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		p.Push(&PipelineMessage{Type: MsgTypeData, SessionID: fmt.Sprintf("msg-%d", i)})
+	}
+
+	var pushErr error
+	buffer.onDrain = func() {
+		// 排空期间不再接受新输入
+		p.Push(&PipelineMessage{Type: MsgTypeData, SessionID: "late"})
+		pushErr = p.PushText("late", "hello")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.StopGraceful(ctx); err != nil {
+		t.Fatalf("StopGraceful failed: %v", err)
+	}
+	if !errors.Is(pushErr, ErrPipelineStopping) {
+		t.Errorf("Expected ErrPipelineStopping during drain, got %v", pushErr)
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case msg := <-received:
+			if want := fmt.Sprintf("msg-%d", i); msg.SessionID != want {
+				t.Fatalf("Expected %s, got %s", want, msg.SessionID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Only %d of %d messages reached the output", i, n)
+		}
+	}
+	select {
+	case msg := <-received:
+		t.Errorf("Unexpected message after drain: %s", msg.SessionID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPipelineStopGracefulDeadline(t *testing.T) {
+	p := NewPipeline("test")
+	buffer := newBufferElement("buffer")
+	buffer.block = true
+	p.AddElement(buffer)
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.StopGraceful(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("StopGraceful took %v after the deadline", elapsed)
+	}
+}
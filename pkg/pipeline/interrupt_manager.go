@@ -8,6 +8,10 @@
 //   - 判断是否应该触发打断（防抖、状态检查）
 //   - 广播打断事件到所有相关组件
 //   - 管理打断后的状态恢复
+//   - 跟踪 AI 音频是否正在播放，AI 静默时用户说话按普通轮次处理
+//   - AI 刚开口时不允许打断；很短的用户语音（"嗯"、"mm-hmm"）视为附和而非打断
+//   - 按 RecoveryPolicy 决定打断后重新回答还是接着被打断的内容说（EventInterruptRecovery）
+//   - 按 DoubleTalkPolicy 处理用户与 AI 同时说话：用户优先打断、AI 优先忽略用户、或压低 AI 音量
 //
 // 使用示例:
 //
@@ -19,6 +23,7 @@ package pipeline
 import (
 	"context"
 	"log"
+	"strings"
 	"sync"
 	"time"
 )
@@ -52,6 +57,38 @@ func (s InterruptState) String() string {
 	}
 }
 
+// InterruptRecoveryPolicy 打断后对话如何继续
+type InterruptRecoveryPolicy string
+
+const (
+	// InterruptRecoveryDiscard 丢弃被打断的回答，下一轮重新回答（默认）
+	InterruptRecoveryDiscard InterruptRecoveryPolicy = "discard"
+
+	// InterruptRecoveryPreserveContext 在对话历史中记录回答在哪里被打断，
+	// 下一轮模型可以自然地接着说（"刚才说到……"）
+	InterruptRecoveryPreserveContext InterruptRecoveryPolicy = "preserve_context"
+)
+
+// DoubleTalkPolicy 用户与 AI 同时说话（双讲）时的处理方式
+type DoubleTalkPolicy string
+
+const (
+	// DoubleTalkUserPriority 用户优先：按打断模式（VAD/API/混合）停止 AI（默认，适合 Web）
+	DoubleTalkUserPriority DoubleTalkPolicy = "user_priority"
+
+	// DoubleTalkAssistantPriority AI 优先：AI 说完之前忽略用户语音。
+	// LLM API 自身发出的打断（EventInterrupted）和客户端手动打断不受影响
+	DoubleTalkAssistantPriority DoubleTalkPolicy = "assistant_priority"
+
+	// DoubleTalkDuck 半双工压低：用户说话时把 AI 音量压低到 DuckGain，
+	// 用户停下后恢复（EventAudioDuck/EventAudioUnduck），不打断 AI。
+	// 适合电话等回声大、容易误打断的场景
+	DoubleTalkDuck DoubleTalkPolicy = "duck"
+)
+
+// defaultDuckGain 压低时的默认增益（约 -12dB）
+const defaultDuckGain = 0.25
+
 // InterruptConfig 打断机制配置
 type InterruptConfig struct {
 	// 打断检测模式
@@ -66,6 +103,30 @@ type InterruptConfig struct {
 	// 混合模式配置
 	APIConfirmTimeoutMs     int // API 确认超时时间（毫秒）
 	MinSpeechForConfirmMs   int // 无 API 确认时的最小语音时长（毫秒）
+
+	// 附和过滤：AI 至少说了 MinAssistantSpeechMs 后才允许 VAD 打断；
+	// 短于 BackchannelMaxMs 的用户语音视为附和，AI 继续说话。
+	// 纯 VAD 模式下打断会延迟到用户语音达到 BackchannelMaxMs 时才触发；
+	// 混合模式下与 MinSpeechForConfirmMs 取较大值作为确认阈值。0 表示不限制
+	MinAssistantSpeechMs int // AI 开始说话后多久才允许打断（毫秒）
+	BackchannelMaxMs     int // 短于该时长的用户语音视为附和（毫秒）
+
+	// 播放状态感知：以 AI 音频是否正在播放（EventPlaybackStart/End）判断 AI 是否在说话。
+	// AI 静默时用户说话只是普通轮次，不运行打断/确认逻辑；
+	// 响应结束后仍在播放的音频也可以被打断。
+	// 未收到过播放事件时（输出端不发布这些事件）退回按响应状态判断。
+	PlaybackAware bool
+	// 播放结束后再等待多久才认为 AI 说完（毫秒），吸收 TTS 分句之间输出缓冲短暂清空的空隙，
+	// 空隙内用户说话仍是打断。0 表示播放一结束即认为说完
+	PlaybackHangoverMs int
+
+	// 打断恢复策略，每次打断发布一次 EventInterruptRecovery，
+	// 由维护对话历史的元素（如 ChatElement）执行。空值等同于 InterruptRecoveryDiscard
+	RecoveryPolicy InterruptRecoveryPolicy
+
+	// 双讲策略：AI 说话时用户也开口如何处理。空值等同于 DoubleTalkUserPriority
+	DoubleTalkPolicy DoubleTalkPolicy
+	DuckGain         float64 // DoubleTalkDuck 时 AI 音频的线性增益（0-1），0 表示默认 0.25
 }
 
 // DefaultInterruptConfig 返回默认配置
@@ -78,9 +139,24 @@ func DefaultInterruptConfig() InterruptConfig {
 		InterruptCooldownMs:     500,   // 500ms 冷却时间
 		APIConfirmTimeoutMs:     500,   // API 确认超时 500ms
 		MinSpeechForConfirmMs:   300,   // 无确认时需要 300ms 语音
+		MinAssistantSpeechMs:    0,     // 默认 AI 一开口即可打断
+		BackchannelMaxMs:        0,     // 默认不过滤附和
+		PlaybackAware:           true,  // 默认根据播放状态区分普通轮次与打断
+		PlaybackHangoverMs:      300,   // 播放结束后 300ms 内仍视为 AI 在说话
+		RecoveryPolicy:          InterruptRecoveryDiscard,
+		DoubleTalkPolicy:        DoubleTalkUserPriority,
 	}
 }
 
+// PhoneInterruptConfig 返回电话场景的默认配置
+// 电话线路回声大，AI 的声音容易被 VAD 当作用户语音，因此双讲时压低 AI 音量而不是打断
+func PhoneInterruptConfig() InterruptConfig {
+	config := DefaultInterruptConfig()
+	config.DoubleTalkPolicy = DoubleTalkDuck
+	config.DuckGain = defaultDuckGain
+	return config
+}
+
 // InterruptManager 打断管理器
 type InterruptManager struct {
 	bus    Bus
@@ -96,6 +172,27 @@ type InterruptManager struct {
 	pendingInterruptAt time.Time
 	speechStartAt      time.Time
 
+	// 附和过滤状态
+	assistantStartAt   time.Time   // AI 本次开始说话的时间
+	pendingBargeIn     bool        // 纯 VAD 模式下等待语音达到 BackchannelMaxMs
+	pendingBargeInData interface{} // 延迟打断时使用的 VAD 载荷
+	ignoredSpeech      bool        // 当前用户语音未作为打断处理（附和或 AI 刚开口）
+
+	// 播放状态
+	playbackActive  bool      // AI 音频正在播放
+	playbackTracked bool      // 是否收到过播放事件
+	playbackEndAt   time.Time // 最近一次播放结束的时间
+
+	// 打断恢复状态
+	responseText strings.Builder // 当前回答已发送播放的文本
+	recovered    bool            // 当前回答已发布过 EventInterruptRecovery
+
+	// 双讲状态
+	ducked bool // 已发布 EventAudioDuck，尚未恢复
+
+	// flush 清空输出链路上排队的音频，由 Pipeline.EnableInterruptManager 设置
+	flush func()
+
 	// 同步
 	mu     sync.RWMutex
 	cancel context.CancelFunc
@@ -119,8 +216,8 @@ func (im *InterruptManager) Start(ctx context.Context) error {
 	im.wg.Add(1)
 	go im.eventLoop(ctx)
 
-	log.Printf("[InterruptManager] Started with config: VAD=%v, API=%v, Hybrid=%v",
-		im.config.EnableVADInterrupt, im.config.EnableAPIInterrupt, im.config.EnableHybridMode)
+	log.Printf("[InterruptManager] Started with config: VAD=%v, API=%v, Hybrid=%v, DoubleTalk=%s",
+		im.config.EnableVADInterrupt, im.config.EnableAPIInterrupt, im.config.EnableHybridMode, im.doubleTalkPolicy())
 
 	return nil
 }
@@ -146,12 +243,18 @@ func (im *InterruptManager) eventLoop(ctx context.Context) {
 	responseStartCh := make(chan Event, 10)
 	responseEndCh := make(chan Event, 10)
 	apiInterruptCh := make(chan Event, 10)
+	playbackStartCh := make(chan Event, 10)
+	playbackEndCh := make(chan Event, 10)
+	textDeltaCh := make(chan Event, 50)
 
 	im.bus.Subscribe(EventVADSpeechStart, vadStartCh)
 	im.bus.Subscribe(EventVADSpeechEnd, vadEndCh)
 	im.bus.Subscribe(EventResponseStart, responseStartCh)
 	im.bus.Subscribe(EventResponseEnd, responseEndCh)
 	im.bus.Subscribe(EventInterrupted, apiInterruptCh)
+	im.bus.Subscribe(EventPlaybackStart, playbackStartCh)
+	im.bus.Subscribe(EventPlaybackEnd, playbackEndCh)
+	im.bus.Subscribe(EventTextDelta, textDeltaCh)
 
 	defer func() {
 		im.bus.Unsubscribe(EventVADSpeechStart, vadStartCh)
@@ -159,6 +262,9 @@ func (im *InterruptManager) eventLoop(ctx context.Context) {
 		im.bus.Unsubscribe(EventResponseStart, responseStartCh)
 		im.bus.Unsubscribe(EventResponseEnd, responseEndCh)
 		im.bus.Unsubscribe(EventInterrupted, apiInterruptCh)
+		im.bus.Unsubscribe(EventPlaybackStart, playbackStartCh)
+		im.bus.Unsubscribe(EventPlaybackEnd, playbackEndCh)
+		im.bus.Unsubscribe(EventTextDelta, textDeltaCh)
 	}()
 
 	// 混合模式超时检查定时器
@@ -169,13 +275,21 @@ func (im *InterruptManager) eventLoop(ctx context.Context) {
 		defer hybridTimer.Stop()
 	}
 
+	// 附和过滤定时器：用户语音达到 BackchannelMaxMs 时触发延迟的打断
+	var bargeInTimer *time.Timer
+	if im.config.BackchannelMaxMs > 0 {
+		bargeInTimer = time.NewTimer(time.Hour)
+		bargeInTimer.Stop()
+		defer bargeInTimer.Stop()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 
 		case evt := <-vadStartCh:
-			im.handleVADStart(evt, hybridTimer)
+			im.handleVADStart(evt, hybridTimer, bargeInTimer)
 
 		case evt := <-vadEndCh:
 			im.handleVADEnd(evt)
@@ -189,6 +303,15 @@ func (im *InterruptManager) eventLoop(ctx context.Context) {
 		case evt := <-apiInterruptCh:
 			im.handleAPIInterrupt(evt)
 
+		case <-playbackStartCh:
+			im.handlePlayback(true)
+
+		case <-playbackEndCh:
+			im.handlePlayback(false)
+
+		case evt := <-textDeltaCh:
+			im.handleTextDelta(evt)
+
 		case <-func() <-chan time.Time {
 			if hybridTimer != nil {
 				return hybridTimer.C
@@ -196,23 +319,44 @@ func (im *InterruptManager) eventLoop(ctx context.Context) {
 			return nil
 		}():
 			im.handleHybridTimeout()
+
+		case <-func() <-chan time.Time {
+			if bargeInTimer != nil {
+				return bargeInTimer.C
+			}
+			return nil
+		}():
+			im.handleBargeInTimeout()
 		}
 	}
 }
 
 // handleVADStart 处理 VAD 语音开始事件
-func (im *InterruptManager) handleVADStart(evt Event, hybridTimer *time.Timer) {
+func (im *InterruptManager) handleVADStart(evt Event, hybridTimer, bargeInTimer *time.Timer) {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
 	im.speechStartAt = time.Now()
+	im.ignoredSpeech = false
 	prevState := im.state
 
 	log.Printf("[InterruptManager] VAD speech start, state: %s -> UserSpeaking", prevState)
 
-	// 检查是否在 AI 响应中
-	if prevState == InterruptStateAIResponding {
-		if im.shouldInterrupt(InterruptSourceVAD) {
+	// 只有 AI 正在说话时才是打断，否则是普通轮次
+	if im.assistantSpeakingLocked() {
+		if spoken := time.Since(im.assistantStartAt); spoken < time.Duration(im.config.MinAssistantSpeechMs)*time.Millisecond {
+			// AI 刚开口，不允许打断
+			log.Printf("[InterruptManager] Assistant spoke only %v (< %dms), ignoring speech",
+				spoken, im.config.MinAssistantSpeechMs)
+			im.ignoredSpeech = true
+		} else if policy := im.doubleTalkPolicy(); policy != DoubleTalkUserPriority {
+			// 双讲：AI 优先或压低音量，都不打断
+			log.Printf("[InterruptManager] Double-talk (%s), not interrupting", policy)
+			im.ignoredSpeech = true
+			if policy == DoubleTalkDuck {
+				im.duckLocked()
+			}
+		} else if im.shouldInterrupt(InterruptSourceVAD) {
 			if im.config.EnableHybridMode {
 				// 混合模式：先暂停输出，等待确认
 				im.pendingInterrupt = true
@@ -225,6 +369,11 @@ func (im *InterruptManager) handleVADStart(evt Event, hybridTimer *time.Timer) {
 				}
 
 				log.Printf("[InterruptManager] Hybrid mode: paused audio, waiting for API confirm or timeout")
+			} else if im.config.EnableVADInterrupt && bargeInTimer != nil {
+				// 纯 VAD 模式 + 附和过滤：语音持续到 BackchannelMaxMs 才打断
+				im.pendingBargeIn = true
+				im.pendingBargeInData = evt.Payload
+				bargeInTimer.Reset(time.Duration(im.config.BackchannelMaxMs) * time.Millisecond)
 			} else if im.config.EnableVADInterrupt {
 				// 纯 VAD 模式：直接打断
 				im.triggerInterruptLocked(InterruptSourceVAD, evt.Payload)
@@ -244,12 +393,24 @@ func (im *InterruptManager) handleVADEnd(evt Event) {
 	speechDuration := time.Since(im.speechStartAt)
 	log.Printf("[InterruptManager] VAD speech end, duration: %v, pending: %v", speechDuration, im.pendingInterrupt)
 
+	// 用户停下，恢复 AI 音量
+	im.unduckLocked()
+
+	// 纯 VAD 模式：语音在达到 BackchannelMaxMs 前结束，是附和
+	if im.pendingBargeIn {
+		log.Printf("[InterruptManager] Backchannel (%v < %dms), not interrupting",
+			speechDuration, im.config.BackchannelMaxMs)
+		im.pendingBargeIn = false
+		im.pendingBargeInData = nil
+		im.ignoredSpeech = true
+	}
+
 	// 混合模式：检查是否需要恢复或确认打断
 	if im.pendingInterrupt {
-		if speechDuration < time.Duration(im.config.MinSpeechForConfirmMs)*time.Millisecond {
-			// 语音太短，可能是误判，恢复输出
-			log.Printf("[InterruptManager] Short speech (%v < %dms), resuming audio",
-				speechDuration, im.config.MinSpeechForConfirmMs)
+		if speechDuration < im.minConfirmSpeechLocked() {
+			// 语音太短，可能是误判或附和，恢复输出
+			log.Printf("[InterruptManager] Short speech (%v < %v), resuming audio",
+				speechDuration, im.minConfirmSpeechLocked())
 			im.resumeAudioOutput()
 			im.pendingInterrupt = false
 			im.state = InterruptStateAIResponding
@@ -260,12 +421,50 @@ func (im *InterruptManager) handleVADEnd(evt Event) {
 		}
 	}
 
+	// 未作为打断处理的语音不开启新轮次，AI 继续说话
+	if im.ignoredSpeech && im.state == InterruptStateUserSpeaking {
+		im.ignoredSpeech = false
+		im.state = InterruptStateAIResponding
+	}
+
 	// 状态转换
 	if im.state == InterruptStateUserSpeaking {
 		im.state = InterruptStateProcessing
 	}
 }
 
+// minConfirmSpeechLocked 返回混合模式确认打断所需的最短语音时长（必须持有锁）
+func (im *InterruptManager) minConfirmSpeechLocked() time.Duration {
+	ms := im.config.MinSpeechForConfirmMs
+	if im.config.BackchannelMaxMs > ms {
+		ms = im.config.BackchannelMaxMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// handleBargeInTimeout 用户语音达到 BackchannelMaxMs 仍在继续，触发延迟的打断
+func (im *InterruptManager) handleBargeInTimeout() {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if !im.pendingBargeIn {
+		return
+	}
+	im.pendingBargeIn = false
+
+	payload := im.pendingBargeInData
+	im.pendingBargeInData = nil
+
+	// 播放已经结束，没有需要打断的内容
+	if im.config.PlaybackAware && im.playbackTracked && !im.playingLocked() {
+		return
+	}
+
+	log.Printf("[InterruptManager] Speech lasted %dms, interrupting", im.config.BackchannelMaxMs)
+	im.triggerInterruptLocked(InterruptSourceVAD, payload)
+	im.state = InterruptStateUserSpeaking // 用户仍在说话
+}
+
 // handleResponseStart 处理 AI 响应开始事件
 func (im *InterruptManager) handleResponseStart(evt Event) {
 	im.mu.Lock()
@@ -276,6 +475,11 @@ func (im *InterruptManager) handleResponseStart(evt Event) {
 	}
 
 	im.state = InterruptStateAIResponding
+	if !(im.config.PlaybackAware && im.playbackTracked) {
+		im.assistantStartAt = time.Now()
+	}
+	im.responseText.Reset()
+	im.recovered = false
 	log.Printf("[InterruptManager] AI response started, responseID: %s", im.currentResponseID)
 }
 
@@ -289,6 +493,11 @@ func (im *InterruptManager) handleResponseEnd(evt Event) {
 	im.state = InterruptStateIdle
 	im.currentResponseID = ""
 	im.pendingInterrupt = false
+	im.pendingBargeIn = false
+	im.pendingBargeInData = nil
+	if !(im.config.PlaybackAware && im.playbackTracked) {
+		im.unduckLocked()
+	}
 }
 
 // handleAPIInterrupt 处理来自 LLM API 的打断信号
@@ -301,15 +510,149 @@ func (im *InterruptManager) handleAPIInterrupt(evt Event) {
 	if im.pendingInterrupt {
 		// 混合模式：API 确认了打断
 		im.confirmInterruptLocked()
-	} else if im.config.EnableAPIInterrupt && im.state == InterruptStateAIResponding {
+	} else if im.config.EnableAPIInterrupt && im.assistantSpeakingLocked() {
 		// 纯 API 模式：触发打断
 		// 注意：不重复发布 EventInterrupted，因为它已经由 LLM Element 发布
+		im.unduckLocked()
+		im.flushOutputLocked()
 		im.state = InterruptStateInterrupted
 		im.lastInterruptAt = time.Now()
 		log.Printf("[InterruptManager] API interrupt confirmed, state -> Interrupted")
+		im.publishRecoveryLocked()
 	}
 }
 
+// handleTextDelta 记录当前回答已发送播放的文本，用于打断恢复
+// 被打断的回答后续生成的文本不再计入
+func (im *InterruptManager) handleTextDelta(evt Event) {
+	var text string
+	switch payload := evt.Payload.(type) {
+	case string:
+		text = payload
+	case *TextDeltaPayload:
+		text = payload.Text
+	case TextDeltaPayload:
+		text = payload.Text
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if !im.recovered {
+		im.responseText.WriteString(text)
+	}
+}
+
+// publishRecoveryLocked 按 RecoveryPolicy 发布 EventInterruptRecovery，每个回答只发布一次（必须持有锁）
+func (im *InterruptManager) publishRecoveryLocked() {
+	if im.recovered {
+		return
+	}
+	im.recovered = true
+
+	policy := im.config.RecoveryPolicy
+	if policy == "" {
+		policy = InterruptRecoveryDiscard
+	}
+	log.Printf("[InterruptManager] Interrupt recovery: %s", policy)
+
+	im.bus.Publish(Event{
+		Type:      EventInterruptRecovery,
+		Timestamp: time.Now(),
+		Payload: &InterruptRecoveryPayload{
+			Policy:          policy,
+			ResponseID:      im.currentResponseID,
+			InterruptedText: strings.TrimSpace(im.responseText.String()),
+		},
+	})
+}
+
+// handlePlayback 处理 AI 音频播放开始/结束事件
+func (im *InterruptManager) handlePlayback(active bool) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	im.playbackTracked = true
+	if im.playbackActive == active {
+		return
+	}
+	// 分句间隙后继续播放，仍是同一段说话
+	resumed := active && im.playingLocked()
+	im.playbackActive = active
+	if active && im.config.PlaybackAware && !resumed {
+		im.assistantStartAt = time.Now()
+	}
+	if !active {
+		im.playbackEndAt = time.Now()
+		im.unduckLocked()
+	}
+	log.Printf("[InterruptManager] Playback active: %v", active)
+}
+
+// playingLocked 判断 AI 音频是否在播放，播放结束后的 PlaybackHangoverMs 内仍算在播放（必须持有锁）
+func (im *InterruptManager) playingLocked() bool {
+	if im.playbackActive {
+		return true
+	}
+	hangover := time.Duration(im.config.PlaybackHangoverMs) * time.Millisecond
+	return !im.playbackEndAt.IsZero() && time.Since(im.playbackEndAt) < hangover
+}
+
+// doubleTalkPolicy 返回生效的双讲策略，只读取配置，不需要持有锁
+func (im *InterruptManager) doubleTalkPolicy() DoubleTalkPolicy {
+	if im.config.DoubleTalkPolicy == "" {
+		return DoubleTalkUserPriority
+	}
+	return im.config.DoubleTalkPolicy
+}
+
+// duckLocked 压低 AI 音频音量（必须持有锁）
+func (im *InterruptManager) duckLocked() {
+	if im.ducked {
+		return
+	}
+	im.ducked = true
+
+	gain := im.config.DuckGain
+	if gain <= 0 || gain > 1 {
+		gain = defaultDuckGain
+	}
+	log.Printf("[InterruptManager] Ducking assistant audio to gain %.2f", gain)
+	im.bus.Publish(Event{
+		Type:      EventAudioDuck,
+		Timestamp: time.Now(),
+		Payload:   &AudioDuckPayload{Gain: gain},
+	})
+}
+
+// unduckLocked 恢复被压低的 AI 音频音量（必须持有锁）
+func (im *InterruptManager) unduckLocked() {
+	if !im.ducked {
+		return
+	}
+	im.ducked = false
+
+	log.Printf("[InterruptManager] Restoring assistant audio volume")
+	im.bus.Publish(Event{
+		Type:      EventAudioUnduck,
+		Timestamp: time.Now(),
+	})
+}
+
+// assistantSpeakingLocked 判断 AI 当前是否在说话（必须持有锁）
+func (im *InterruptManager) assistantSpeakingLocked() bool {
+	if im.config.PlaybackAware && im.playbackTracked {
+		return im.playingLocked()
+	}
+	return im.state == InterruptStateAIResponding
+}
+
+// IsAssistantSpeaking 返回 AI 当前是否在说话（此时用户说话视为打断）
+func (im *InterruptManager) IsAssistantSpeaking() bool {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	return im.assistantSpeakingLocked()
+}
+
 // handleHybridTimeout 处理混合模式超时
 func (im *InterruptManager) handleHybridTimeout() {
 	im.mu.Lock()
@@ -366,6 +709,13 @@ func (im *InterruptManager) confirmInterruptLocked() {
 	im.triggerInterruptLocked(InterruptSourceVAD, nil)
 }
 
+// flushOutputLocked 丢弃已生成、尚未播放的音频（TTS、重采样、播放元素中排队的数据）
+func (im *InterruptManager) flushOutputLocked() {
+	if im.flush != nil {
+		im.flush()
+	}
+}
+
 // pauseAudioOutput 暂停音频输出
 func (im *InterruptManager) pauseAudioOutput() {
 	im.bus.Publish(Event{
@@ -392,8 +742,8 @@ func (im *InterruptManager) TriggerManualInterruptWithReason(reason string) {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
-	if im.state != InterruptStateAIResponding {
-		log.Printf("[InterruptManager] Manual interrupt ignored, not in AI responding state")
+	if !im.assistantSpeakingLocked() {
+		log.Printf("[InterruptManager] Manual interrupt ignored, assistant is not speaking")
 		return
 	}
 
@@ -405,6 +755,10 @@ func (im *InterruptManager) TriggerManualInterruptWithReason(reason string) {
 func (im *InterruptManager) triggerInterruptLockedWithReason(source InterruptSource, payload interface{}, reason string) {
 	log.Printf("[InterruptManager] Triggering interrupt from source: %v, reason: %s", source, reason)
 
+	// 被打断的音频会被清空，压低的音量随之恢复
+	im.unduckLocked()
+	im.flushOutputLocked()
+
 	im.state = InterruptStateInterrupted
 	im.lastInterruptAt = time.Now()
 	im.pendingInterrupt = false
@@ -428,6 +782,7 @@ func (im *InterruptManager) triggerInterruptLockedWithReason(source InterruptSou
 		Timestamp: time.Now(),
 		Payload:   interruptPayload,
 	})
+	im.publishRecoveryLocked()
 }
 
 // GetState 获取当前状态
@@ -381,3 +381,415 @@ func TestInterruptState_String(t *testing.T) {
 		}
 	}
 }
+
+func TestInterruptManager_PlaybackAware(t *testing.T) {
+	bus := newMockBus()
+	config := DefaultInterruptConfig()
+	config.EnableVADInterrupt = true
+	config.EnableAPIInterrupt = false
+	config.InterruptCooldownMs = 0
+
+	im := NewInterruptManager(bus, config)
+
+	ctx := context.Background()
+	_ = im.Start(ctx)
+	defer im.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// 输出端报告播放状态，此时响应已开始但音频尚未播放
+	bus.Publish(Event{Type: EventPlaybackEnd, Timestamp: time.Now()})
+	bus.Publish(Event{
+		Type:      EventResponseStart,
+		Timestamp: time.Now(),
+		Payload:   &ResponseStartPayload{ResponseID: "resp_001"},
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	if im.IsAssistantSpeaking() {
+		t.Error("Assistant should not be speaking before playback starts")
+	}
+
+	// AI 静默时说话是普通轮次，不应触发打断
+	bus.clearPublished()
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{}})
+	time.Sleep(10 * time.Millisecond)
+	if len(bus.getPublishedEvents(EventInterrupted)) > 0 {
+		t.Error("Speech while assistant is silent should not publish EventInterrupted")
+	}
+	bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now()})
+
+	// 响应结束后音频仍在播放，此时说话是打断
+	bus.Publish(Event{Type: EventResponseEnd, Timestamp: time.Now(), Payload: &ResponseEndPayload{}})
+	bus.Publish(Event{Type: EventPlaybackStart, Timestamp: time.Now()})
+	time.Sleep(10 * time.Millisecond)
+
+	if !im.IsAssistantSpeaking() {
+		t.Error("Assistant should be speaking while playback is active")
+	}
+
+	bus.clearPublished()
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{}})
+	time.Sleep(10 * time.Millisecond)
+	if len(bus.getPublishedEvents(EventInterrupted)) == 0 {
+		t.Error("Speech during playback should publish EventInterrupted")
+	}
+}
+
+func TestInterruptManager_PlaybackSentenceGap(t *testing.T) {
+	bus := newMockBus()
+	config := DefaultInterruptConfig()
+	config.EnableVADInterrupt = true
+	config.EnableAPIInterrupt = false
+	config.InterruptCooldownMs = 0
+	config.PlaybackHangoverMs = 200
+
+	im := NewInterruptManager(bus, config)
+	_ = im.Start(context.Background())
+	defer im.Stop()
+	waitForCondition(t, "event subscriptions", func() bool { return bus.subscribed(EventTextDelta) })
+
+	bus.Publish(Event{
+		Type:      EventResponseStart,
+		Timestamp: time.Now(),
+		Payload:   &ResponseStartPayload{ResponseID: "resp_001"},
+	})
+	bus.Publish(Event{Type: EventPlaybackStart, Timestamp: time.Now()})
+	waitForCondition(t, "playback start", im.IsAssistantSpeaking)
+
+	// 第一句播完，下一句的音频还没到，输出缓冲短暂清空
+	bus.Publish(Event{Type: EventPlaybackEnd, Timestamp: time.Now()})
+	time.Sleep(20 * time.Millisecond)
+	if !im.IsAssistantSpeaking() {
+		t.Error("Assistant should still be speaking in the gap between sentences")
+	}
+
+	// 空隙内用户开口仍是打断
+	bus.clearPublished()
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{}})
+	waitForCondition(t, "speech start", func() bool { return im.GetState() != InterruptStateAIResponding })
+	if len(bus.getPublishedEvents(EventInterrupted)) != 1 {
+		t.Error("Speech in a sentence gap should publish EventInterrupted")
+	}
+	bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now(), Payload: &VADPayload{}})
+
+	// 超过 hangover 后 AI 才算说完
+	waitForCondition(t, "playback hangover", func() bool { return !im.IsAssistantSpeaking() })
+}
+
+func TestInterruptManager_PlaybackAwareDisabled(t *testing.T) {
+	bus := newMockBus()
+	config := DefaultInterruptConfig()
+	config.PlaybackAware = false
+
+	im := NewInterruptManager(bus, config)
+
+	ctx := context.Background()
+	_ = im.Start(ctx)
+	defer im.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// 关闭后只看响应状态，忽略播放事件
+	bus.Publish(Event{Type: EventPlaybackStart, Timestamp: time.Now()})
+	time.Sleep(10 * time.Millisecond)
+	if im.IsAssistantSpeaking() {
+		t.Error("Playback events should be ignored when PlaybackAware is disabled")
+	}
+
+	bus.Publish(Event{
+		Type:      EventResponseStart,
+		Timestamp: time.Now(),
+		Payload:   &ResponseStartPayload{ResponseID: "resp_001"},
+	})
+	time.Sleep(10 * time.Millisecond)
+	if !im.IsAssistantSpeaking() {
+		t.Error("Assistant should be speaking while responding")
+	}
+}
+
+func TestInterruptManager_BackchannelVsInterrupt(t *testing.T) {
+	bus := newMockBus()
+	config := DefaultInterruptConfig()
+	config.EnableVADInterrupt = true
+	config.EnableAPIInterrupt = false
+	config.InterruptCooldownMs = 0
+	config.BackchannelMaxMs = 100
+
+	im := NewInterruptManager(bus, config)
+	_ = im.Start(context.Background())
+	defer im.Stop()
+	time.Sleep(10 * time.Millisecond)
+
+	bus.Publish(Event{Type: EventResponseStart, Timestamp: time.Now(), Payload: &ResponseStartPayload{ResponseID: "resp_001"}})
+	time.Sleep(10 * time.Millisecond)
+	bus.clearPublished()
+
+	// "mm-hmm"：短于 100ms 的语音是附和，不打断
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 0}})
+	time.Sleep(20 * time.Millisecond)
+	bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 20}})
+	time.Sleep(150 * time.Millisecond)
+
+	if len(bus.getPublishedEvents(EventInterrupted)) > 0 {
+		t.Error("Backchannel should not trigger EventInterrupted")
+	}
+	if im.GetState() != InterruptStateAIResponding {
+		t.Errorf("State should stay AIResponding after backchannel, got %v", im.GetState())
+	}
+
+	// 持续说话超过 100ms 是真正的打断
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 500}})
+	time.Sleep(20 * time.Millisecond)
+	if len(bus.getPublishedEvents(EventInterrupted)) > 0 {
+		t.Error("Interrupt should wait until speech exceeds BackchannelMaxMs")
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	interruptEvents := bus.getPublishedEvents(EventInterrupted)
+	if len(interruptEvents) != 1 {
+		t.Fatalf("Long speech should trigger one EventInterrupted, got %d", len(interruptEvents))
+	}
+	if payload, ok := interruptEvents[0].Payload.(*InterruptPayload); !ok || payload.AudioMs != 500 {
+		t.Errorf("Interrupt should carry the speech start VAD payload, got %+v", interruptEvents[0].Payload)
+	}
+	if im.GetState() != InterruptStateUserSpeaking {
+		t.Errorf("State should be UserSpeaking, got %v", im.GetState())
+	}
+}
+
+func TestInterruptManager_MinAssistantSpeech(t *testing.T) {
+	bus := newMockBus()
+	config := DefaultInterruptConfig()
+	config.EnableVADInterrupt = true
+	config.EnableAPIInterrupt = false
+	config.InterruptCooldownMs = 0
+	config.MinAssistantSpeechMs = 100
+
+	im := NewInterruptManager(bus, config)
+	_ = im.Start(context.Background())
+	defer im.Stop()
+	time.Sleep(10 * time.Millisecond)
+
+	bus.Publish(Event{Type: EventPlaybackStart, Timestamp: time.Now()})
+	time.Sleep(10 * time.Millisecond)
+	bus.clearPublished()
+
+	// AI 刚开口，用户语音不打断
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 0}})
+	time.Sleep(10 * time.Millisecond)
+	bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 10}})
+	time.Sleep(10 * time.Millisecond)
+
+	if len(bus.getPublishedEvents(EventInterrupted)) > 0 {
+		t.Error("Speech right after the assistant starts should not interrupt")
+	}
+
+	// AI 说了足够久之后可以打断
+	time.Sleep(100 * time.Millisecond)
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 200}})
+	time.Sleep(10 * time.Millisecond)
+
+	if len(bus.getPublishedEvents(EventInterrupted)) != 1 {
+		t.Error("Speech after MinAssistantSpeechMs should interrupt")
+	}
+}
+
+func TestInterruptManager_HybridMode_Backchannel(t *testing.T) {
+	bus := newMockBus()
+	config := DefaultInterruptConfig()
+	config.EnableHybridMode = true
+	config.EnableAPIInterrupt = false
+	config.MinSpeechForConfirmMs = 20
+	config.BackchannelMaxMs = 300
+	config.InterruptCooldownMs = 0
+
+	im := NewInterruptManager(bus, config)
+	_ = im.Start(context.Background())
+	defer im.Stop()
+	time.Sleep(10 * time.Millisecond)
+
+	bus.Publish(Event{Type: EventResponseStart, Timestamp: time.Now(), Payload: &ResponseStartPayload{ResponseID: "resp_001"}})
+	time.Sleep(10 * time.Millisecond)
+	bus.clearPublished()
+
+	// 50ms 语音超过 MinSpeechForConfirmMs，但仍短于 BackchannelMaxMs，按附和恢复输出
+	bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 0}})
+	time.Sleep(50 * time.Millisecond)
+	bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now(), Payload: &VADPayload{AudioMs: 50}})
+	time.Sleep(10 * time.Millisecond)
+
+	if len(bus.getPublishedEvents(EventAudioResume)) == 0 {
+		t.Error("Backchannel should resume audio in hybrid mode")
+	}
+	if len(bus.getPublishedEvents(EventInterrupted)) > 0 {
+		t.Error("Backchannel should not trigger EventInterrupted in hybrid mode")
+	}
+}
+
+func TestInterruptManager_RecoveryPolicy(t *testing.T) {
+	for _, policy := range []InterruptRecoveryPolicy{"", InterruptRecoveryPreserveContext} {
+		bus := newMockBus()
+		config := DefaultInterruptConfig()
+		config.EnableVADInterrupt = true
+		config.InterruptCooldownMs = 0
+		config.RecoveryPolicy = policy
+
+		im := NewInterruptManager(bus, config)
+		_ = im.Start(context.Background())
+		time.Sleep(10 * time.Millisecond)
+
+		bus.Publish(Event{Type: EventResponseStart, Payload: &ResponseStartPayload{ResponseID: "resp_001"}})
+		bus.Publish(Event{Type: EventTextDelta, Payload: "It will be sunny. "})
+		bus.Publish(Event{Type: EventTextDelta, Payload: &TextDeltaPayload{Text: "Tomorrow it"}})
+		time.Sleep(10 * time.Millisecond)
+
+		// 打断后 API 的打断信号和后续文本不再重复发布或计入
+		bus.Publish(Event{Type: EventVADSpeechStart, Payload: &VADPayload{}})
+		time.Sleep(10 * time.Millisecond)
+		bus.Publish(Event{Type: EventInterrupted, Payload: &InterruptPayload{Source: InterruptSourceLLMAPI}})
+		bus.Publish(Event{Type: EventTextDelta, Payload: " will rain."})
+		time.Sleep(10 * time.Millisecond)
+		im.Stop()
+
+		events := bus.getPublishedEvents(EventInterruptRecovery)
+		if len(events) != 1 {
+			t.Fatalf("Expected one EventInterruptRecovery for policy %q, got %d", policy, len(events))
+		}
+		payload := events[0].Payload.(*InterruptRecoveryPayload)
+
+		want := policy
+		if want == "" {
+			want = InterruptRecoveryDiscard
+		}
+		if payload.Policy != want {
+			t.Errorf("Expected policy %q, got %q", want, payload.Policy)
+		}
+		if payload.ResponseID != "resp_001" || payload.InterruptedText != "It will be sunny. Tomorrow it" {
+			t.Errorf("Unexpected recovery payload: %+v", payload)
+		}
+	}
+}
+
+// waitForCondition 轮询直到 cond 返回 true，超时则失败
+// 打断管理器每种事件使用独立的通道，先后发布的不同类型事件不保证按顺序处理，
+// 需要等前一个事件的效果可见后再发布下一个
+func waitForCondition(t *testing.T, desc string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for %s", desc)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// subscribed 报告是否有订阅者订阅了 eventType
+func (b *mockBus) subscribed(eventType EventType) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[eventType]) > 0
+}
+
+func TestInterruptManager_DoubleTalkPolicy(t *testing.T) {
+	// startSpeaking 让 AI 开始说话，然后用户开口
+	startSpeaking := func(t *testing.T, policy DoubleTalkPolicy) (*mockBus, *InterruptManager) {
+		bus := newMockBus()
+		config := DefaultInterruptConfig()
+		config.EnableVADInterrupt = true
+		config.EnableAPIInterrupt = false
+		config.InterruptCooldownMs = 0
+		config.DoubleTalkPolicy = policy
+
+		im := NewInterruptManager(bus, config)
+		_ = im.Start(context.Background())
+		waitForCondition(t, "event subscriptions", func() bool { return bus.subscribed(EventTextDelta) })
+
+		bus.Publish(Event{Type: EventPlaybackStart, Timestamp: time.Now()})
+		waitForCondition(t, "playback start", im.IsAssistantSpeaking)
+		bus.clearPublished()
+
+		bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{}})
+		waitForCondition(t, "speech start", func() bool { return im.GetState() == InterruptStateUserSpeaking })
+		return bus, im
+	}
+
+	t.Run("UserPriority", func(t *testing.T) {
+		bus, im := startSpeaking(t, DoubleTalkUserPriority)
+		defer im.Stop()
+
+		if len(bus.getPublishedEvents(EventInterrupted)) != 1 {
+			t.Error("User speech should interrupt the assistant")
+		}
+		if len(bus.getPublishedEvents(EventAudioDuck)) != 0 {
+			t.Error("User priority should not duck")
+		}
+	})
+
+	t.Run("AssistantPriority", func(t *testing.T) {
+		bus, im := startSpeaking(t, DoubleTalkAssistantPriority)
+		defer im.Stop()
+
+		bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now(), Payload: &VADPayload{}})
+		waitForCondition(t, "speech end", func() bool { return im.GetState() != InterruptStateUserSpeaking })
+
+		if len(bus.getPublishedEvents(EventInterrupted)) != 0 {
+			t.Error("User speech should not interrupt the assistant")
+		}
+		if len(bus.getPublishedEvents(EventAudioDuck)) != 0 {
+			t.Error("Assistant priority should not duck")
+		}
+		if im.GetState() != InterruptStateAIResponding {
+			t.Errorf("State should stay AIResponding, got %v", im.GetState())
+		}
+
+		// AI 说完后用户说话是普通轮次
+		bus.Publish(Event{Type: EventPlaybackEnd, Timestamp: time.Now()})
+		waitForCondition(t, "playback end", func() bool { return !im.IsAssistantSpeaking() })
+		bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{}})
+		waitForCondition(t, "speech start", func() bool { return im.GetState() == InterruptStateUserSpeaking })
+		if len(bus.getPublishedEvents(EventInterrupted)) != 0 {
+			t.Error("Speech after the assistant finished should not interrupt")
+		}
+	})
+
+	t.Run("Duck", func(t *testing.T) {
+		bus, im := startSpeaking(t, DoubleTalkDuck)
+		defer im.Stop()
+
+		if len(bus.getPublishedEvents(EventInterrupted)) != 0 {
+			t.Error("Ducking should not interrupt the assistant")
+		}
+		duckEvents := bus.getPublishedEvents(EventAudioDuck)
+		if len(duckEvents) != 1 {
+			t.Fatalf("Expected one EventAudioDuck, got %d", len(duckEvents))
+		}
+		if payload := duckEvents[0].Payload.(*AudioDuckPayload); payload.Gain != defaultDuckGain {
+			t.Errorf("Expected default duck gain %v, got %v", defaultDuckGain, payload.Gain)
+		}
+
+		// 用户停下后恢复音量，AI 继续说话
+		bus.Publish(Event{Type: EventVADSpeechEnd, Timestamp: time.Now(), Payload: &VADPayload{}})
+		waitForCondition(t, "unduck", func() bool { return len(bus.getPublishedEvents(EventAudioUnduck)) == 1 })
+		if im.GetState() != InterruptStateAIResponding {
+			t.Errorf("State should stay AIResponding, got %v", im.GetState())
+		}
+
+		// AI 在用户说话时说完，同样恢复音量
+		bus.Publish(Event{Type: EventVADSpeechStart, Timestamp: time.Now(), Payload: &VADPayload{}})
+		waitForCondition(t, "second duck", func() bool { return len(bus.getPublishedEvents(EventAudioDuck)) == 2 })
+		bus.Publish(Event{Type: EventPlaybackEnd, Timestamp: time.Now()})
+		waitForCondition(t, "unduck on playback end", func() bool { return len(bus.getPublishedEvents(EventAudioUnduck)) == 2 })
+		waitForCondition(t, "playback hangover", func() bool { return !im.IsAssistantSpeaking() })
+	})
+
+	t.Run("PhoneDefaults", func(t *testing.T) {
+		if config := PhoneInterruptConfig(); config.DoubleTalkPolicy != DoubleTalkDuck {
+			t.Errorf("Phone config should duck, got %q", config.DoubleTalkPolicy)
+		}
+		if config := DefaultInterruptConfig(); config.DoubleTalkPolicy != DoubleTalkUserPriority {
+			t.Errorf("Default config should be user priority, got %q", config.DoubleTalkPolicy)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// LanguageContext 保存 Pipeline 级别的语言配置，供 STT、翻译、TTS 等元素共享。
+// 以前源语言/目标语言需要分别写进每个元素的配置（SOURCE_LANG/TARGET_LANG），
+// 很容易不一致；现在只需在 Pipeline 上配置一次。
+//
+// 主要功能:
+//   - 统一的源语言（用户说的语言）和目标语言（输出的语言）
+//   - STT 检测到的语言会更新上下文，下游翻译/TTS 自动跟随
+//   - 元素自身显式配置的语言优先于上下文（按元素覆盖）
+//   - 检测到的语言变化时发布 EventLanguageChanged
+//
+// 使用示例:
+//
+//	p := NewPipeline("translation")
+//	p.SetLanguageContext(NewLanguageContext("auto", "en"))
+//	p.AddElements([]Element{stt, translate, tts}) // 元素读取 LanguageContext
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// LanguageChangedPayload 是 EventLanguageChanged 的附加数据
+type LanguageChangedPayload struct {
+	Previous string // 之前生效的源语言
+	Detected string // 新检测到的源语言
+}
+
+// LanguageContext Pipeline 级别的语言配置
+type LanguageContext struct {
+	mu       sync.RWMutex
+	source   string // 配置的源语言，"" 或 "auto" 表示自动检测
+	target   string // 目标语言，"" 表示与源语言相同（不翻译）
+	detected string // STT 最近检测到的源语言
+	bus      Bus
+}
+
+// NewLanguageContext 创建语言上下文
+func NewLanguageContext(source, target string) *LanguageContext {
+	return &LanguageContext{
+		source: source,
+		target: target,
+	}
+}
+
+// Source 返回配置的源语言（不含检测结果），STT 用它作为识别语言
+func (l *LanguageContext) Source() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.source
+}
+
+// EffectiveSource 返回当前生效的源语言：优先使用检测结果，其次为配置值
+// 自动检测且尚未检测到时返回 ""
+func (l *LanguageContext) EffectiveSource() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.effectiveSourceLocked()
+}
+
+func (l *LanguageContext) effectiveSourceLocked() string {
+	if l.detected != "" {
+		return l.detected
+	}
+	if l.source == "auto" {
+		return ""
+	}
+	return l.source
+}
+
+// Target 返回目标语言
+func (l *LanguageContext) Target() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.target
+}
+
+// OutputLanguage 返回输出语言：有目标语言时为目标语言，否则为生效的源语言
+func (l *LanguageContext) OutputLanguage() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.target != "" {
+		return l.target
+	}
+	return l.effectiveSourceLocked()
+}
+
+// SetSource 设置源语言，并清除之前的检测结果
+func (l *LanguageContext) SetSource(source string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.source = source
+	l.detected = ""
+}
+
+// SetTarget 设置目标语言
+func (l *LanguageContext) SetTarget(target string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.target = target
+}
+
+// SetDetected 记录 STT 检测到的源语言，变化时发布 EventLanguageChanged
+func (l *LanguageContext) SetDetected(language string) {
+	if l == nil || language == "" || language == "auto" {
+		return
+	}
+
+	l.mu.Lock()
+	previous := l.effectiveSourceLocked()
+	l.detected = language
+	bus := l.bus
+	l.mu.Unlock()
+
+	if previous == language || bus == nil {
+		return
+	}
+	bus.Publish(Event{
+		Type:      EventLanguageChanged,
+		Timestamp: time.Now(),
+		Payload: &LanguageChangedPayload{
+			Previous: previous,
+			Detected: language,
+		},
+	})
+}
+
+// Detected 返回 STT 最近检测到的源语言
+func (l *LanguageContext) Detected() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.detected
+}
+
+// setBus 设置用于发布语言变化事件的总线
+func (l *LanguageContext) setBus(bus Bus) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bus = bus
+}
+
+// LanguageAware 由需要读取 LanguageContext 的元素实现
+// BaseElement 已实现该接口，Pipeline 在添加元素时自动注入
+type LanguageAware interface {
+	SetLanguageContext(lc *LanguageContext)
+}
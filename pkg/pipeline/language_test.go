@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"testing"
+)
+
+func TestLanguageContextResolution(t *testing.T) {
+	lc := NewLanguageContext("auto", "")
+
+	if got := lc.EffectiveSource(); got != "" {
+		t.Errorf("EffectiveSource before detection = %q, want empty", got)
+	}
+	if got := lc.OutputLanguage(); got != "" {
+		t.Errorf("OutputLanguage before detection = %q, want empty", got)
+	}
+
+	lc.SetDetected("ja")
+	if got := lc.Source(); got != "auto" {
+		t.Errorf("Source = %q, want configured value %q", got, "auto")
+	}
+	if got := lc.EffectiveSource(); got != "ja" {
+		t.Errorf("EffectiveSource = %q, want %q", got, "ja")
+	}
+	if got := lc.OutputLanguage(); got != "ja" {
+		t.Errorf("OutputLanguage without target = %q, want %q", got, "ja")
+	}
+
+	lc.SetTarget("en")
+	if got := lc.OutputLanguage(); got != "en" {
+		t.Errorf("OutputLanguage = %q, want %q", got, "en")
+	}
+
+	// 重新设置源语言会清除检测结果
+	lc.SetSource("zh")
+	if got := lc.EffectiveSource(); got != "zh" {
+		t.Errorf("EffectiveSource after SetSource = %q, want %q", got, "zh")
+	}
+
+	// nil 上下文可以安全读取
+	var none *LanguageContext
+	if none.EffectiveSource() != "" || none.Target() != "" || none.OutputLanguage() != "" {
+		t.Error("nil LanguageContext should resolve to empty languages")
+	}
+	none.SetDetected("en")
+}
+
+func TestPipelineInjectsLanguageContext(t *testing.T) {
+	p := NewPipeline("test")
+	before := NewMockElement()
+	p.AddElement(before)
+
+	lc := NewLanguageContext("zh", "en")
+	p.SetLanguageContext(lc)
+
+	after := NewMockElement()
+	p.AddElements([]Element{after})
+
+	if before.LanguageContext() != lc {
+		t.Error("element added before SetLanguageContext did not receive the context")
+	}
+	if after.LanguageContext() != lc {
+		t.Error("element added after SetLanguageContext did not receive the context")
+	}
+	if p.LanguageContext() != lc {
+		t.Error("Pipeline.LanguageContext should return the configured context")
+	}
+}
+
+func TestLanguageContextPublishesChange(t *testing.T) {
+	p := NewPipeline("test")
+	lc := NewLanguageContext("auto", "en")
+	p.SetLanguageContext(lc)
+
+	ch := make(chan Event, 10)
+	p.Bus().Subscribe(EventLanguageChanged, ch)
+
+	lc.SetDetected("zh")
+	lc.SetDetected("zh") // 相同语言不重复发布
+	lc.SetDetected("ja")
+
+	if len(ch) != 2 {
+		t.Fatalf("got %d EventLanguageChanged events, want 2", len(ch))
+	}
+	first := (<-ch).Payload.(*LanguageChangedPayload)
+	if first.Previous != "" || first.Detected != "zh" {
+		t.Errorf("first change = %+v, want '' -> zh", first)
+	}
+	second := (<-ch).Payload.(*LanguageChangedPayload)
+	if second.Previous != "zh" || second.Detected != "ja" {
+		t.Errorf("second change = %+v, want zh -> ja", second)
+	}
+}
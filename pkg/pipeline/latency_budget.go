@@ -0,0 +1,291 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// LatencyBudget 监控端到端延迟（用户说完到助手第一段音频），超出预算时自动沿降级阶梯
+// 切换到更低延迟的配置（短语切分、更快的模型、流式 TTS 等），延迟恢复后再逐级还原。
+//
+// 工作原理:
+//   - 每一轮从 EventVADSpeechEnd 开始计时，到 EventAssistantSpeakingStart 或 EventPlaybackStart 结束，
+//     用户重新开口（EventVADSpeechStart）或 STT 判定为噪声（EventNoResult）时放弃本轮
+//   - 最近 Window 轮的平均延迟超过 Budget 时执行阶梯的下一级 Degrade，发布 EventLatencyDegraded
+//   - 平均延迟低于 Budget*RestoreRatio 时撤销最近一级（Restore），发布 EventLatencyRestored
+//   - 每次切换后清空样本，新配置下重新积累 Window 轮再判断，避免来回抖动
+//
+// 使用示例:
+//
+//	p.EnableLatencyBudget(pipeline.LatencyBudgetConfig{
+//		Budget: 1200 * time.Millisecond,
+//		Ladder: []pipeline.LatencyStep{
+//			pipeline.PropertyStep("short-endpointing", vad, "min-silence-ms", 300),
+//			pipeline.PropertyStep("no-text-pacing", pacer, "enabled", false),
+//			{Name: "fast-model", Degrade: useFastModel, Restore: useDefaultModel},
+//		},
+//	})
+package pipeline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultLatencyBudget 默认的端到端延迟预算
+	defaultLatencyBudget = 1500 * time.Millisecond
+	// defaultLatencyWindow 默认参与平均的轮数
+	defaultLatencyWindow = 3
+	// defaultLatencyRestoreRatio 默认的还原阈值（相对 Budget）
+	defaultLatencyRestoreRatio = 0.6
+)
+
+// LatencyStep 降级阶梯中的一级
+type LatencyStep struct {
+	// Name 名称，出现在事件和日志中
+	Name string
+
+	// Degrade 切换到更低延迟的配置
+	Degrade func() error
+
+	// Restore 还原 Degrade 前的配置（可选，为 nil 时该级不还原）
+	Restore func() error
+}
+
+// PropertyStep 返回把元素属性设为 value 的降级步骤，Restore 时还原为降级前的值
+func PropertyStep(name string, element Element, property string, value interface{}) LatencyStep {
+	var previous interface{}
+	return LatencyStep{
+		Name: name,
+		Degrade: func() error {
+			v, err := element.GetProperty(property)
+			if err != nil {
+				return err
+			}
+			previous = v
+			return element.SetProperty(property, value)
+		},
+		Restore: func() error {
+			return element.SetProperty(property, previous)
+		},
+	}
+}
+
+// LatencyBudgetConfig 延迟预算配置
+type LatencyBudgetConfig struct {
+	// Budget 用户说完到助手第一段音频的延迟预算，默认 1.5s
+	Budget time.Duration
+
+	// Ladder 降级阶梯，超出预算时从第一级开始依次执行，还原时倒序撤销
+	Ladder []LatencyStep
+
+	// Window 参与平均的最近轮数，默认 3
+	Window int
+
+	// RestoreRatio 平均延迟低于 Budget*RestoreRatio 时还原一级，默认 0.6
+	RestoreRatio float64
+
+	// Clock 时间源，事件没有时间戳时使用，默认 SystemClock
+	Clock Clock
+}
+
+// LatencyBudgetPayload EventLatencyDegraded 和 EventLatencyRestored 的 Payload
+type LatencyBudgetPayload struct {
+	Level   int           // 切换后已执行的降级级数，0 表示完全还原
+	Step    string        // 本次执行或撤销的步骤名称
+	Latency time.Duration // 触发切换的平均延迟
+	Budget  time.Duration // 延迟预算
+}
+
+// LatencyBudget 端到端延迟预算控制器
+type LatencyBudget struct {
+	bus    Bus
+	config LatencyBudgetConfig
+
+	mu          sync.Mutex
+	speechEnd   time.Time       // 本轮用户说完的时刻，零值表示没有进行中的一轮
+	samples     []time.Duration // 当前配置下最近几轮的延迟
+	level       int
+	lastLatency time.Duration
+
+	events chan Event
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// latencyEvents 标记一轮开始和结束的事件
+var latencyEvents = []EventType{
+	EventVADSpeechStart, EventVADSpeechEnd, EventNoResult, EventAssistantSpeakingStart, EventPlaybackStart,
+}
+
+// NewLatencyBudget 创建延迟预算控制器
+func NewLatencyBudget(bus Bus, config LatencyBudgetConfig) *LatencyBudget {
+	if config.Budget <= 0 {
+		config.Budget = defaultLatencyBudget
+	}
+	if config.Window <= 0 {
+		config.Window = defaultLatencyWindow
+	}
+	if config.RestoreRatio <= 0 {
+		config.RestoreRatio = defaultLatencyRestoreRatio
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &LatencyBudget{
+		bus:    bus,
+		config: config,
+		events: make(chan Event, 20),
+	}
+}
+
+// Start 开始监听
+func (l *LatencyBudget) Start(ctx context.Context) error {
+	ctx, l.cancel = context.WithCancel(ctx)
+
+	// 同一个 channel 订阅所有事件，保持事件的先后顺序
+	for _, t := range latencyEvents {
+		l.bus.Subscribe(t, l.events)
+	}
+
+	l.wg.Add(1)
+	go l.run(ctx)
+	return nil
+}
+
+// Stop 停止监听，已执行的降级保持不变
+func (l *LatencyBudget) Stop() error {
+	if l.cancel != nil {
+		l.cancel()
+		l.wg.Wait()
+		l.cancel = nil
+	}
+	for _, t := range latencyEvents {
+		l.bus.Unsubscribe(t, l.events)
+	}
+	return nil
+}
+
+// Level 返回当前已执行的降级级数
+func (l *LatencyBudget) Level() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// LastLatency 返回最近一轮的端到端延迟
+func (l *LatencyBudget) LastLatency() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastLatency
+}
+
+func (l *LatencyBudget) run(ctx context.Context) {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-l.events:
+			l.handle(evt)
+		}
+	}
+}
+
+// handle 根据对话事件计算每轮的延迟
+func (l *LatencyBudget) handle(evt Event) {
+	at := evt.Timestamp
+	if at.IsZero() {
+		at = l.config.Clock.Now()
+	}
+
+	l.mu.Lock()
+	switch evt.Type {
+	case EventVADSpeechEnd:
+		l.speechEnd = at
+	case EventVADSpeechStart, EventNoResult:
+		l.speechEnd = time.Time{}
+	default:
+		// 助手开始说话，同一轮的 EventAssistantSpeakingStart 和 EventPlaybackStart 只计一次
+		if l.speechEnd.IsZero() {
+			break
+		}
+		latency := at.Sub(l.speechEnd)
+		l.speechEnd = time.Time{}
+		l.lastLatency = latency
+		l.samples = append(l.samples, latency)
+		if len(l.samples) > l.config.Window {
+			l.samples = l.samples[1:]
+		}
+	}
+	average, ok := l.average()
+	l.mu.Unlock()
+
+	if ok {
+		l.adjust(average)
+	}
+}
+
+// average 返回最近 Window 轮的平均延迟，样本不足时返回 false；需持有锁
+func (l *LatencyBudget) average() (time.Duration, bool) {
+	if len(l.samples) < l.config.Window {
+		return 0, false
+	}
+	var total time.Duration
+	for _, s := range l.samples {
+		total += s
+	}
+	return total / time.Duration(len(l.samples)), true
+}
+
+// adjust 超出预算时降级一级，延迟足够低时还原一级
+func (l *LatencyBudget) adjust(average time.Duration) {
+	restoreBelow := time.Duration(float64(l.config.Budget) * l.config.RestoreRatio)
+
+	l.mu.Lock()
+	level := l.level
+	var step LatencyStep
+	eventType := EventLatencyDegraded
+	switch {
+	case average > l.config.Budget && level < len(l.config.Ladder):
+		step = l.config.Ladder[level]
+		level++
+	case average < restoreBelow && level > 0:
+		level--
+		step = l.config.Ladder[level]
+		eventType = EventLatencyRestored
+	default:
+		l.mu.Unlock()
+		return
+	}
+	l.level = level
+	l.samples = l.samples[:0]
+	l.mu.Unlock()
+
+	// 步骤失败时仍然计入级数，下次超预算时继续尝试下一级
+	if eventType == EventLatencyDegraded {
+		log.Printf("[LatencyBudget] Average latency %v exceeds budget %v, degrading: %s", average, l.config.Budget, step.Name)
+		if step.Degrade != nil {
+			if err := step.Degrade(); err != nil {
+				log.Printf("[LatencyBudget] Failed to apply %s: %v", step.Name, err)
+			}
+		}
+	} else {
+		log.Printf("[LatencyBudget] Average latency %v within budget %v, restoring: %s", average, l.config.Budget, step.Name)
+		if step.Restore != nil {
+			if err := step.Restore(); err != nil {
+				log.Printf("[LatencyBudget] Failed to restore %s: %v", step.Name, err)
+			}
+		}
+	}
+
+	l.bus.Publish(Event{
+		Type:      eventType,
+		Timestamp: l.config.Clock.Now(),
+		Payload: LatencyBudgetPayload{
+			Level:   level,
+			Step:    step.Name,
+			Latency: average,
+			Budget:  l.config.Budget,
+		},
+	})
+}
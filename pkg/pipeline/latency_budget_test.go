@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// latencyHarness 驱动 LatencyBudget，记录降级阶梯的执行顺序
+type latencyHarness struct {
+	t      *testing.T
+	bus    Bus
+	lb     *LatencyBudget
+	now    time.Time
+	events chan Event
+	calls  []string
+}
+
+func newLatencyHarness(t *testing.T, config LatencyBudgetConfig, steps ...string) *latencyHarness {
+	h := &latencyHarness{
+		t:      t,
+		bus:    NewEventBus(),
+		now:    time.Unix(0, 0),
+		events: make(chan Event, 10),
+	}
+	h.bus.Subscribe(EventLatencyDegraded, h.events)
+	h.bus.Subscribe(EventLatencyRestored, h.events)
+
+	for _, name := range steps {
+		name := name
+		config.Ladder = append(config.Ladder, LatencyStep{
+			Name:    name,
+			Degrade: func() error { h.calls = append(h.calls, "degrade "+name); return nil },
+			Restore: func() error { h.calls = append(h.calls, "restore "+name); return nil },
+		})
+	}
+	h.lb = NewLatencyBudget(h.bus, config)
+	if err := h.lb.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { h.lb.Stop() })
+	return h
+}
+
+// publish 发布带时间戳的事件，并等待控制器处理完毕
+func (h *latencyHarness) publish(eventType EventType, at time.Time) {
+	h.bus.Publish(Event{Type: eventType, Timestamp: at})
+	deadline := time.Now().Add(time.Second)
+	for len(h.lb.events) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+}
+
+// turn 模拟一轮对话：用户说完后经过 latency 助手开始说话
+func (h *latencyHarness) turn(latency time.Duration) {
+	h.now = h.now.Add(10 * time.Second)
+	h.publish(EventVADSpeechEnd, h.now)
+	h.publish(EventAssistantSpeakingStart, h.now.Add(latency))
+	h.publish(EventPlaybackStart, h.now.Add(latency+50*time.Millisecond))
+}
+
+func (h *latencyHarness) expectEvent(want EventType, level int, step string) {
+	h.t.Helper()
+	select {
+	case evt := <-h.events:
+		p := evt.Payload.(LatencyBudgetPayload)
+		if evt.Type != want || p.Level != level || p.Step != step {
+			h.t.Fatalf("Expected %s level %d %q, got %s %+v", want, level, step, evt.Type, p)
+		}
+	case <-time.After(time.Second):
+		h.t.Fatalf("Timeout waiting for %s", want)
+	}
+}
+
+func (h *latencyHarness) expectQuiet() {
+	h.t.Helper()
+	if len(h.events) != 0 {
+		h.t.Fatalf("Unexpected event %+v", <-h.events)
+	}
+}
+
+func TestLatencyBudgetDegradesAndRestores(t *testing.T) {
+	h := newLatencyHarness(t, LatencyBudgetConfig{Budget: time.Second, Window: 2}, "phrase-segmentation", "fast-model")
+
+	// 单轮超预算不足以降级
+	h.turn(2 * time.Second)
+	h.expectQuiet()
+	h.turn(1500 * time.Millisecond)
+	h.expectEvent(EventLatencyDegraded, 1, "phrase-segmentation")
+	if h.lb.LastLatency() != 1500*time.Millisecond {
+		t.Errorf("Expected last latency 1.5s, got %v", h.lb.LastLatency())
+	}
+
+	// 切换后重新积累样本
+	h.turn(1200 * time.Millisecond)
+	h.expectQuiet()
+	h.turn(1200 * time.Millisecond)
+	h.expectEvent(EventLatencyDegraded, 2, "fast-model")
+
+	// 阶梯用完后不再降级
+	h.turn(3 * time.Second)
+	h.turn(3 * time.Second)
+	h.expectQuiet()
+
+	// 在预算内但未低于还原阈值时保持不变
+	h.turn(800 * time.Millisecond)
+	h.turn(800 * time.Millisecond)
+	h.expectQuiet()
+
+	h.turn(400 * time.Millisecond)
+	h.turn(400 * time.Millisecond)
+	h.expectEvent(EventLatencyRestored, 1, "fast-model")
+	h.turn(400 * time.Millisecond)
+	h.turn(400 * time.Millisecond)
+	h.expectEvent(EventLatencyRestored, 0, "phrase-segmentation")
+
+	want := []string{"degrade phrase-segmentation", "degrade fast-model", "restore fast-model", "restore phrase-segmentation"}
+	if len(h.calls) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, h.calls)
+	}
+	for i := range want {
+		if h.calls[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, h.calls)
+		}
+	}
+	if h.lb.Level() != 0 {
+		t.Errorf("Expected level 0, got %d", h.lb.Level())
+	}
+}
+
+func TestLatencyBudgetIgnoresAbandonedTurns(t *testing.T) {
+	h := newLatencyHarness(t, LatencyBudgetConfig{Budget: time.Second, Window: 1}, "fast-model")
+
+	// 用户继续说话、噪声都不算一轮
+	h.publish(EventVADSpeechEnd, h.now)
+	h.publish(EventVADSpeechStart, h.now.Add(time.Second))
+	h.publish(EventAssistantSpeakingStart, h.now.Add(5*time.Second))
+	h.publish(EventVADSpeechEnd, h.now.Add(10*time.Second))
+	h.publish(EventNoResult, h.now.Add(11*time.Second))
+	h.publish(EventPlaybackStart, h.now.Add(15*time.Second))
+	h.expectQuiet()
+
+	h.turn(2 * time.Second)
+	h.expectEvent(EventLatencyDegraded, 1, "fast-model")
+}
+
+func TestPropertyStep(t *testing.T) {
+	elem := NewBaseElement("test", 10)
+	elem.RegisterProperty(PropertyDesc{Name: "min-silence-ms", Type: reflect.TypeOf(0), Writable: true, Readable: true, Default: 700})
+
+	step := PropertyStep("short-endpointing", elem, "min-silence-ms", 300)
+	if err := step.Degrade(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := elem.GetProperty("min-silence-ms"); v != 300 {
+		t.Errorf("Expected 300 after degrading, got %v", v)
+	}
+	if err := step.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := elem.GetProperty("min-silence-ms"); v != 700 {
+		t.Errorf("Expected 700 after restoring, got %v", v)
+	}
+}
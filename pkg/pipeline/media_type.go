@@ -18,6 +18,10 @@ const (
 	AudioMediaTypeSpeech AudioMediaType = "audio/speech"
 	// Opus with RFC header
 	AudioMediaTypeOpusStandard AudioMediaType = "audio/opus"
+	// G.711 μ-law audio (8-bit, telephony)
+	AudioMediaTypeMuLaw AudioMediaType = "audio/x-mulaw"
+	// G.711 A-law audio (8-bit, telephony)
+	AudioMediaTypeALaw AudioMediaType = "audio/x-alaw"
 )
 
 // String returns the string representation of AudioMediaType
@@ -25,6 +29,55 @@ func (amt AudioMediaType) String() string {
 	return string(amt)
 }
 
+// SampleFormat represents the sample encoding of raw PCM audio
+type SampleFormat string
+
+const (
+	// 16-bit signed little-endian (default when unset)
+	SampleFormatS16 SampleFormat = "s16le"
+	// 32-bit float little-endian, normalized to [-1, 1]
+	SampleFormatF32 SampleFormat = "f32le"
+	// 8-bit signed
+	SampleFormatS8 SampleFormat = "s8"
+	// 24-bit signed little-endian, packed in 3 bytes
+	SampleFormatS24 SampleFormat = "s24le"
+	// 32-bit signed little-endian
+	SampleFormatS32 SampleFormat = "s32le"
+)
+
+// BytesPerSample returns the size of one sample, or 0 for an unknown format.
+// An empty format is treated as SampleFormatS16.
+func (f SampleFormat) BytesPerSample() int {
+	switch f {
+	case SampleFormatS16, "":
+		return 2
+	case SampleFormatF32, SampleFormatS32:
+		return 4
+	case SampleFormatS8:
+		return 1
+	case SampleFormatS24:
+		return 3
+	}
+	return 0
+}
+
+// String returns the string representation of SampleFormat
+func (f SampleFormat) String() string {
+	return string(f)
+}
+
+// AudioFormat describes the raw audio an element produces.
+// A zero field means the value is unknown and is taken from each frame.
+type AudioFormat struct {
+	SampleRate int
+	Channels   int
+}
+
+// IsZero reports whether neither the sample rate nor the channel count is known
+func (f AudioFormat) IsZero() bool {
+	return f.SampleRate == 0 && f.Channels == 0
+}
+
 // VideoMediaType represents the media type for video data
 type VideoMediaType string
 
@@ -2,20 +2,43 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // https://chatgpt.com/c/678d0634-058c-8002-909d-d298453449e9
 
 type AudioData struct {
-	Data       []byte
-	SampleRate int
-	Channels   int
-	MediaType  AudioMediaType // pipeline.AudioMediaTypeRaw, pipeline.AudioMediaTypeOpus, etc.
-	Codec      string
-	Timestamp  time.Time
+	Data         []byte
+	SampleRate   int
+	Channels     int
+	MediaType    AudioMediaType // pipeline.AudioMediaTypeRaw, pipeline.AudioMediaTypeOpus, etc.
+	SampleFormat SampleFormat   // 原始 PCM 的采样格式，为空表示 SampleFormatS16
+	Codec        string
+	Timestamp    time.Time
+
+	// RTPSequence 编码音频包的 RTP 序列号，HasRTPSequence 为 true 时有效
+	// 解码端据此检测丢包（见 OpusDecodeConfig.PacketLossConcealment）
+	RTPSequence    uint16
+	HasRTPSequence bool
+
+	// EndOfSentence 该消息是一句话（短语）音频的最后一段，如 TTS 一句的结尾
+	// AudioPacerSinkElement 据此记录短语边界，打断时可先播完当前句（见 InterruptGraceMs）
+	EndOfSentence bool
+}
+
+// Format 返回采样格式，未设置时为 SampleFormatS16
+func (a *AudioData) Format() SampleFormat {
+	if a.SampleFormat == "" {
+		return SampleFormatS16
+	}
+	return a.SampleFormat
 }
 
 type VideoData struct {
@@ -36,6 +59,14 @@ type TextData struct {
 	Timestamp time.Time
 }
 
+// WordTiming 单词在对应音频中的时间位置（相对该段音频开始）
+// 可放在文本消息的 Metadata（[]WordTiming）中，供字幕等按语音节奏输出
+type WordTiming struct {
+	Word  string
+	Start time.Duration
+	End   time.Duration
+}
+
 // ImageData 图像数据结构
 // 用于在 Pipeline 中传输静态图像（区别于 VideoData 的视频帧流）
 type ImageData struct {
@@ -78,34 +109,117 @@ type PipelineMessage struct {
 
 	// Metadata 元数据
 	Metadata interface{}
+
+	// Attributes 应用附加的结构化元数据，元素须原样传递到输出消息和相关事件，见 Attributes
+	Attributes Attributes
 }
 
 func (p *PipelineMessage) String() string {
 	return fmt.Sprintf("PipelineMessage{Type: %d, SessionID: %s, Timestamp: %s}", p.Type, p.SessionID, p.Timestamp)
 }
 
+// fanoutCopy 返回分发给另一个下游的副本，音频数据各自一份（Attributes 只读，可以共享）
+func (p *PipelineMessage) fanoutCopy() *PipelineMessage {
+	c := *p
+	if p.AudioData != nil {
+		audio := *p.AudioData
+		audio.Data = slices.Clone(p.AudioData.Data)
+		c.AudioData = &audio
+	}
+	return &c
+}
+
 type Pipeline struct {
 	sync.Mutex
 	name             string
 	bus              Bus
 	elements         []Element
-	interruptManager *InterruptManager // 可选的打断管理器
+	interruptManager *InterruptManager  // 可选的打断管理器
+	speakingTracker  *SpeakingTracker   // 可选的说话状态跟踪器
+	silenceTimeout   *SilenceTimeout    // 可选的静默超时控制器
+	latencyBudget    *LatencyBudget     // 可选的延迟预算控制器
+	language         *LanguageContext   // 可选的语言上下文
+	providerLog      *ProviderLogger    // 可选的服务商审计日志
+	textInput        Element            // 键入文本的注入点（默认第一个元素）
+	speechInput      Element            // 直接播报文本的注入点（通常是 TTS 元素）
+	audioTrackInputs map[string]Element // 按音轨 ID 路由的音频注入点
+	greeting         string             // Start 后立即播报的开场白
+	inputMuted       bool               // 为 true 时 Push 丢弃音频输入
+	prewarmOnConnect bool               // Start 完成后在后台预热服务商连接
+	validateOnStart  bool               // Start 前完整校验拓扑，见 SetValidateOnStart
+
+	// StopGraceful 期间为 true，Push / PushText 不再接受输入
+	draining atomic.Bool
+
+	// 元素启动超时，见 SetStartTimeout / SetElementStartTimeout
+	startTimeout         time.Duration
+	elementStartTimeouts map[Element]time.Duration
+	cancel               context.CancelFunc // 取消传给元素的 context
+
+	// 可选的停滞检测器，每条消息都会访问，因此不经过锁
+	watchdog atomic.Pointer[Watchdog]
+
+	// Link 建立的连接，按源元素分组
+	fanouts map[Element]*fanout
+
+	// 打断时需要清空的输出链路，见 flush.go
+	flushTargets atomic.Pointer[[]Element]
 }
 
+// DefaultStartTimeout 单个元素 Start 的默认超时时间
+const DefaultStartTimeout = 30 * time.Second
+
+// TextInputType 键入文本消息的 TextType
+// 与 STT 最终结果相同，下游（如 ChatElement）按一轮用户输入处理
+const TextInputType = "text/final"
+
 func NewPipeline(name string) *Pipeline {
 	bus := NewEventBus()
 	return &Pipeline{
-		name:     name,
-		bus:      bus,
-		elements: []Element{},
+		name:         name,
+		bus:          bus,
+		elements:     []Element{},
+		startTimeout: DefaultStartTimeout,
 	}
 }
 
+// SetStartTimeout 设置每个元素 Start 的超时时间（默认 DefaultStartTimeout），0 表示不限制
+// 某个元素启动卡住（如服务商握手无响应）时，Start 返回指明该元素的错误，而不是一直挂起
+func (p *Pipeline) SetStartTimeout(timeout time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	p.startTimeout = timeout
+}
+
+// SetElementStartTimeout 为单个元素设置 Start 超时时间，覆盖 SetStartTimeout，0 表示不限制
+// 用于启动本来就慢的元素（如加载本地模型）
+func (p *Pipeline) SetElementStartTimeout(element Element, timeout time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	if p.elementStartTimeouts == nil {
+		p.elementStartTimeouts = make(map[Element]time.Duration)
+	}
+	p.elementStartTimeouts[element] = timeout
+}
+
+// startTimeoutFor 返回元素的启动超时时间
+func (p *Pipeline) startTimeoutFor(element Element) time.Duration {
+	p.Lock()
+	defer p.Unlock()
+	if timeout, ok := p.elementStartTimeouts[element]; ok {
+		return timeout
+	}
+	return p.startTimeout
+}
+
 func (p *Pipeline) AddElement(element Element) {
 	p.Lock()
 	defer p.Unlock()
 	element.SetBus(p.bus)
+	p.injectLanguage(element)
+	p.injectProviderLogger(element)
 	p.elements = append(p.elements, element)
+	p.refreshFlushTargetsLocked()
 }
 
 func (p *Pipeline) AddElements(elements []Element) {
@@ -113,8 +227,11 @@ func (p *Pipeline) AddElements(elements []Element) {
 	defer p.Unlock()
 	for _, element := range elements {
 		element.SetBus(p.bus)
+		p.injectLanguage(element)
+		p.injectProviderLogger(element)
 	}
 	p.elements = append(p.elements, elements...)
+	p.refreshFlushTargetsLocked()
 }
 
 // EnableInterruptManager 启用打断管理器
@@ -128,9 +245,76 @@ func (p *Pipeline) EnableInterruptManager(config InterruptConfig) *InterruptMana
 	}
 
 	p.interruptManager = NewInterruptManager(p.bus, config)
+	p.interruptManager.flush = p.flushOutput
 	return p.interruptManager
 }
 
+// EnableSpeakingTracker 启用助手说话状态跟踪
+// Pull 返回的每段音频都会计入，并据此发布 EventAssistantSpeakingStart/End
+func (p *Pipeline) EnableSpeakingTracker(config SpeakingConfig) *SpeakingTracker {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.speakingTracker != nil {
+		return p.speakingTracker
+	}
+
+	p.speakingTracker = NewSpeakingTracker(p.bus, config)
+	return p.speakingTracker
+}
+
+// EnableSilenceTimeout 启用静默超时处理
+// 助手说完后用户超过 config.Timeout 不说话时经 SetSpeechInput 指定的元素播报提示语，
+// 连续多次没有回应则发布 EventSilenceHangup 并调用 config.OnHangup
+func (p *Pipeline) EnableSilenceTimeout(config SilenceTimeoutConfig) *SilenceTimeout {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.silenceTimeout != nil {
+		return p.silenceTimeout
+	}
+
+	p.silenceTimeout = NewSilenceTimeout(p.bus, config, p.speak)
+	return p.silenceTimeout
+}
+
+// EnableLatencyBudget 启用端到端延迟预算
+// 最近几轮用户说完到助手开口的平均延迟超过 config.Budget 时依次执行 config.Ladder 的降级步骤，
+// 延迟恢复后逐级还原，并发布 EventLatencyDegraded / EventLatencyRestored
+func (p *Pipeline) EnableLatencyBudget(config LatencyBudgetConfig) *LatencyBudget {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.latencyBudget != nil {
+		return p.latencyBudget
+	}
+
+	p.latencyBudget = NewLatencyBudget(p.bus, config)
+	return p.latencyBudget
+}
+
+// EnableWatchdog 启用停滞检测
+// 会话中超过 config.Timeout 没有消息流动时发布 EventPipelineStalled，并调用 config.OnStall（如果设置）
+func (p *Pipeline) EnableWatchdog(config WatchdogConfig) *Watchdog {
+	p.Lock()
+	defer p.Unlock()
+
+	if w := p.watchdog.Load(); w != nil {
+		return w
+	}
+
+	w := NewWatchdog(p.bus, p.name, config)
+	p.watchdog.Store(w)
+	return w
+}
+
+// touch 向停滞检测器报告一次消息流动
+func (p *Pipeline) touch(step string) {
+	if w := p.watchdog.Load(); w != nil {
+		w.Touch(step)
+	}
+}
+
 // GetInterruptManager 获取打断管理器（如果已启用）
 func (p *Pipeline) GetInterruptManager() *InterruptManager {
 	p.Lock()
@@ -138,89 +322,812 @@ func (p *Pipeline) GetInterruptManager() *InterruptManager {
 	return p.interruptManager
 }
 
+// SetLanguageContext 设置 Pipeline 级别的语言上下文
+// 已添加和之后添加的元素都会收到该上下文
+func (p *Pipeline) SetLanguageContext(lc *LanguageContext) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.language = lc
+	if lc != nil {
+		lc.setBus(p.bus)
+	}
+	for _, element := range p.elements {
+		p.injectLanguage(element)
+	}
+}
+
+// LanguageContext 获取语言上下文（未设置时为 nil）
+func (p *Pipeline) LanguageContext() *LanguageContext {
+	p.Lock()
+	defer p.Unlock()
+	return p.language
+}
+
+// injectLanguage 把语言上下文注入支持的元素，调用方需持有锁
+func (p *Pipeline) injectLanguage(element Element) {
+	if p.language == nil {
+		return
+	}
+	if la, ok := element.(LanguageAware); ok {
+		la.SetLanguageContext(p.language)
+	}
+}
+
+// SetProviderLogger 启用服务商请求/响应审计日志，传入 nil 关闭
+// 已添加和之后添加的 STT、LLM、TTS 元素都会记录到该日志
+func (p *Pipeline) SetProviderLogger(l *ProviderLogger) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.providerLog = l
+	for _, element := range p.elements {
+		if pl, ok := element.(ProviderLogAware); ok {
+			pl.SetProviderLogger(l)
+		}
+	}
+}
+
+// injectProviderLogger 把服务商审计日志注入支持的元素，调用方需持有锁
+func (p *Pipeline) injectProviderLogger(element Element) {
+	if p.providerLog == nil {
+		return
+	}
+	if pl, ok := element.(ProviderLogAware); ok {
+		pl.SetProviderLogger(p.providerLog)
+	}
+}
+
 // Link 连接两个 Element，返回一个取消函数用于断开连接
-// 返回的函数调用后会停止数据传输并关闭目标 Element 的输入通道
+// 同一个 a 多次 Link 到不同元素时，a 的每条消息分发给所有下游（见 LinkMulti）
 func (p *Pipeline) Link(a, b Element) func() {
-	ctx, cancel := context.WithCancel(context.Background())
-	done := make(chan struct{})
+	return p.LinkMulti(a, b)
+}
 
-	go func() {
-		defer close(done)
-		for {
-			select {
-			case <-ctx.Done():
-				// 取消连接，退出
+// LinkMulti 把 src 的输出分发给 dsts 中的每个元素，返回一个取消函数断开这些连接
+//
+// 分发语义:
+//   - 每条消息按连接的先后顺序依次发送给所有下游，各下游收到的消息顺序与 src 输出一致
+//   - 除最后一个下游外，其余下游收到的是消息的副本，音频数据各自一份，原地修改音频不会互相影响
+//   - 不丢消息：某个下游处理慢时，分发在它的输入通道上等待，src 和其他下游随之被拖慢；
+//     不应阻塞主链路的旁路（如录音、转储）应自行缓冲或丢弃
+//   - 断开一个下游不影响其他下游；src 的输出通道关闭时关闭所有下游的输入通道
+func (p *Pipeline) LinkMulti(src Element, dsts ...Element) func() {
+	if len(dsts) == 0 {
+		return func() {}
+	}
+	targets := make([]*linkTarget, len(dsts))
+	for i, dst := range dsts {
+		targets[i] = &linkTarget{dst: dst, removed: make(chan struct{})}
+	}
+	negotiateFormat(src, dsts)
+
+	p.Lock()
+	if p.fanouts == nil {
+		p.fanouts = make(map[Element]*fanout)
+	}
+	f, running := p.fanouts[src]
+	if !running {
+		f = &fanout{src: src, done: make(chan struct{})}
+		p.fanouts[src] = f
+	}
+	f.mu.Lock()
+	f.targets = append(f.targets, targets...)
+	f.mu.Unlock()
+	p.refreshFlushTargetsLocked()
+	if !running {
+		// 下游就绪后再开始读取，第一条消息不会丢失
+		var ctx context.Context
+		ctx, f.cancel = context.WithCancel(context.Background())
+		go p.runFanout(ctx, f)
+	}
+	p.Unlock()
+
+	// 返回取消函数
+	return func() {
+		for _, t := range targets {
+			p.unlinkTarget(f, t)
+		}
+	}
+}
+
+// negotiateFormat 把 src 声明的输出音频格式告诉需要输入格式的下游
+func negotiateFormat(src Element, dsts []Element) {
+	provider, ok := src.(AudioFormatProvider)
+	if !ok {
+		return
+	}
+	format := provider.OutputFormat()
+	if format.IsZero() {
+		return
+	}
+	for _, dst := range dsts {
+		if receiver, ok := dst.(AudioFormatReceiver); ok {
+			receiver.SetInputFormat(format)
+		}
+	}
+}
+
+// fanout 把一个元素的输出分发给所有下游
+type fanout struct {
+	src    Element
+	cancel context.CancelFunc
+	done   chan struct{}
+	busy   atomic.Bool // 已从 src 取出、尚未交给所有下游
+
+	mu      sync.Mutex
+	targets []*linkTarget
+}
+
+// linkTarget 一个下游，removed 关闭表示连接已断开
+type linkTarget struct {
+	dst     Element
+	removed chan struct{}
+}
+
+func (p *Pipeline) runFanout(ctx context.Context, f *fanout) {
+	defer close(f.done)
+	for {
+		select {
+		case <-ctx.Done():
+			// 取消连接，退出
+			return
+		case msg, ok := <-f.src.Out():
+			if !ok {
+				// 源通道已关闭
+				p.closeFanout(f)
 				return
-			case msg, ok := <-a.Out():
-				if !ok {
-					// 源通道已关闭
-					close(b.In())
-					return
+			}
+			f.busy.Store(true)
+			recordOut(f.src)
+			f.mu.Lock()
+			targets := slices.Clone(f.targets)
+			f.mu.Unlock()
+
+			for i, t := range targets {
+				out := msg
+				if i < len(targets)-1 {
+					out = msg.fanoutCopy()
 				}
 				select {
 				case <-ctx.Done():
 					return
-				case b.In() <- msg:
+				case <-t.removed:
+				case t.dst.In() <- out:
+					recordIn(t.dst, out)
 				}
 			}
+			f.busy.Store(false)
+			p.touch(f.src.GetName())
 		}
-	}()
+	}
+}
 
-	// 返回取消函数
-	return func() {
-		cancel()
-		<-done // 等待 goroutine 退出
+// unlinkTarget 断开一个下游，最后一个下游断开后停止分发
+func (p *Pipeline) unlinkTarget(f *fanout, t *linkTarget) {
+	p.Lock()
+	f.mu.Lock()
+	i := slices.Index(f.targets, t)
+	if i < 0 {
+		f.mu.Unlock()
+		p.Unlock()
+		return
+	}
+	f.targets = slices.Delete(f.targets, i, i+1)
+	close(t.removed)
+	last := len(f.targets) == 0
+	f.mu.Unlock()
+	if last && p.fanouts[f.src] == f {
+		delete(p.fanouts, f.src)
+	}
+	p.refreshFlushTargetsLocked()
+	p.Unlock()
+
+	if last {
+		f.cancel()
+		<-f.done // 等待 goroutine 退出
+	}
+}
+
+// closeFanout 源通道关闭后关闭所有下游的输入通道
+func (p *Pipeline) closeFanout(f *fanout) {
+	p.Lock()
+	if p.fanouts[f.src] == f {
+		delete(p.fanouts, f.src)
 	}
+	p.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.targets {
+		close(t.dst.In())
+	}
+	f.targets = nil
 }
 
 func (p *Pipeline) Bus() Bus {
 	return p.bus
 }
 
+// Push 把消息发送到 Pipeline 的输入端
+// 输入端为标记了 IsSource 的元素，未标记时为第一个添加的元素
+// 输入静音（MuteInput）期间音频消息被直接丢弃，StopGraceful 期间所有消息被丢弃
 func (p *Pipeline) Push(msg *PipelineMessage) {
-	if len(p.elements) == 0 {
+	if p.draining.Load() {
+		return
+	}
+	if msg != nil && msg.Type == MsgTypeAudio && p.InputMuted() {
+		return
+	}
+	source := p.audioTrackInput(msg)
+	if source == nil {
+		source = p.Source()
+	}
+	if source == nil {
 		return
 	}
 	select {
-	case p.elements[0].In() <- msg:
+	case source.In() <- msg:
+		recordIn(source, msg)
+		p.touch("push")
 	default:
+		recordDropped(source)
 		fmt.Println("pipeline input channel is full")
 	}
 }
 
-// Pull 从 pipeline 的最后一个元素获取消息
-func (p *Pipeline) Pull() *PipelineMessage {
+// MuteInput 静音用户输入（如播放等待提示音期间），连接保持不断开
+// 静音期间 Push 丢弃音频；所有实现 InputResetter 的元素（VAD、STT）会丢弃说到一半的语音，
+// 因此取消静音后不会把静音前截断的半句话提交给下游
+func (p *Pipeline) MuteInput() {
+	p.Lock()
+	if p.inputMuted {
+		p.Unlock()
+		return
+	}
+	p.inputMuted = true
+	if w := p.watchdog.Load(); w != nil {
+		w.setSuspended(true)
+	}
+	var resetters []InputResetter
+	for _, e := range p.elements {
+		if r, ok := e.(InputResetter); ok {
+			resetters = append(resetters, r)
+		}
+	}
+	p.Unlock()
+
+	for _, r := range resetters {
+		r.ResetInput()
+	}
+	p.bus.Publish(Event{
+		Type:      EventInputMuted,
+		Timestamp: time.Now(),
+	})
+}
+
+// UnmuteInput 取消输入静音，之后的音频从干净的 VAD/STT 状态开始处理
+func (p *Pipeline) UnmuteInput() {
+	p.Lock()
+	if !p.inputMuted {
+		p.Unlock()
+		return
+	}
+	p.inputMuted = false
+	if w := p.watchdog.Load(); w != nil {
+		w.setSuspended(false)
+	}
+	p.Unlock()
+
+	p.bus.Publish(Event{
+		Type:      EventInputUnmuted,
+		Timestamp: time.Now(),
+	})
+}
+
+// InputMuted 报告用户输入当前是否被静音
+func (p *Pipeline) InputMuted() bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.inputMuted
+}
+
+// Source 返回 Pipeline 的输入端：标记了 IsSource 的元素，未标记时为第一个添加的元素
+func (p *Pipeline) Source() Element {
+	p.Lock()
+	defer p.Unlock()
+	return p.sourceLocked()
+}
+
+// Sink 返回 Pipeline 的输出端：标记了 IsSink 的元素，未标记时为最后一个添加的元素
+func (p *Pipeline) Sink() Element {
+	p.Lock()
+	defer p.Unlock()
+	return p.sinkLocked()
+}
+
+// sinkLocked 返回输出端，调用方需持有锁
+func (p *Pipeline) sinkLocked() Element {
+	for _, e := range p.elements {
+		if e.IsSink() {
+			return e
+		}
+	}
+	if len(p.elements) == 0 {
+		return nil
+	}
+	return p.elements[len(p.elements)-1]
+}
+
+// sourceLocked 返回输入端，调用方需持有锁
+func (p *Pipeline) sourceLocked() Element {
+	for _, e := range p.elements {
+		if e.IsSource() {
+			return e
+		}
+	}
 	if len(p.elements) == 0 {
 		return nil
 	}
-	return <-p.elements[len(p.elements)-1].Out()
+	return p.elements[0]
+}
+
+// checkEndpointsLocked 检查输入端和输出端的标记是否唯一，调用方需持有锁
+func (p *Pipeline) checkEndpointsLocked() error {
+	var source, sink Element
+	for _, e := range p.elements {
+		if e.IsSource() {
+			if source != nil {
+				return fmt.Errorf("pipeline %s has multiple source elements: %s, %s", p.name, source.GetName(), e.GetName())
+			}
+			source = e
+		}
+		if e.IsSink() {
+			if sink != nil {
+				return fmt.Errorf("pipeline %s has multiple sink elements: %s, %s", p.name, sink.GetName(), e.GetName())
+			}
+			sink = e
+		}
+	}
+	return nil
+}
+
+// SetAudioTrackInput 把某条音轨的音频（AttrAudioTrack 为 trackID 的消息）路由到指定元素，
+// 例如麦克风和系统音频各自接入一条 VAD/STT 分支，或都接入混音元素；
+// 未路由的音轨仍发送到输入端。element 为 nil 时取消路由
+func (p *Pipeline) SetAudioTrackInput(trackID string, element Element) {
+	p.Lock()
+	defer p.Unlock()
+	if element == nil {
+		delete(p.audioTrackInputs, trackID)
+		return
+	}
+	if p.audioTrackInputs == nil {
+		p.audioTrackInputs = make(map[string]Element)
+	}
+	p.audioTrackInputs[trackID] = element
+}
+
+// audioTrackInput 返回 msg 所属音轨的注入元素，未路由时返回 nil
+func (p *Pipeline) audioTrackInput(msg *PipelineMessage) Element {
+	if msg == nil || msg.Type != MsgTypeAudio {
+		return nil
+	}
+	trackID, ok := msg.Attributes[AttrAudioTrack].(string)
+	if !ok {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	return p.audioTrackInputs[trackID]
+}
+
+// SetTextInput 设置键入文本的注入元素（通常是 ChatElement）
+// 语音 Pipeline 的第一个元素一般是重采样/VAD/STT，不会处理文本
+func (p *Pipeline) SetTextInput(element Element) {
+	p.Lock()
+	defer p.Unlock()
+	p.textInput = element
+}
+
+// PushText 注入一轮键入的用户文本（打字代替说话）
+// 文本发送到 SetTextInput 指定的元素（未指定时为输入端），并发布 EventTextInput；
+// 如果启用了打断管理器且助手正在说话，会先打断当前回复
+func (p *Pipeline) PushText(sessionID, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("text input is empty")
+	}
+	if p.draining.Load() {
+		return ErrPipelineStopping
+	}
+
+	p.Lock()
+	target := p.textInput
+	if target == nil {
+		target = p.sourceLocked()
+	}
+	im := p.interruptManager
+	p.Unlock()
+
+	if target == nil {
+		return fmt.Errorf("pipeline has no element to receive text input")
+	}
+
+	if im != nil && im.IsAssistantSpeaking() {
+		im.TriggerManualInterruptWithReason("text_input")
+	}
+
+	p.bus.Publish(Event{
+		Type:      EventTextInput,
+		Timestamp: time.Now(),
+		Payload:   TextInputPayload{SessionID: sessionID, Text: text},
+	})
+
+	msg := &PipelineMessage{
+		Type:      MsgTypeData,
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		TextData: &TextData{
+			Data:      []byte(text),
+			TextType:  TextInputType,
+			Timestamp: time.Now(),
+		},
+	}
+
+	select {
+	case target.In() <- msg:
+		recordIn(target, msg)
+		return nil
+	default:
+		recordDropped(target)
+		return fmt.Errorf("text input channel of %s is full", target.GetName())
+	}
+}
+
+// SetSpeechInput 设置直接播报文本的注入元素（通常是 TTS 元素）
+// 开场白等不经过 LLM 的助手文本发送到该元素；打断时该元素及其下游排队的音频被清空（Element.Flush）
+func (p *Pipeline) SetSpeechInput(element Element) {
+	p.Lock()
+	defer p.Unlock()
+	p.speechInput = element
+	p.refreshFlushTargetsLocked()
+}
+
+// PlayOnStart 设置开场白，Start 完成后立即合成播放，不等待用户输入
+// 文本作为一轮完整回复发送到 SetSpeechInput 指定的元素；
+// 如果 SetTextInput 指定的元素维护对话历史（实现 ConversationRecorder），开场白同时记为助手消息
+// 没有设置 SetSpeechInput 时 Start 在启动任何元素之前返回错误
+func (p *Pipeline) PlayOnStart(text string) {
+	p.Lock()
+	defer p.Unlock()
+	p.greeting = strings.TrimSpace(text)
+}
+
+// playGreeting 将开场白发送给播报元素
+func (p *Pipeline) playGreeting() error {
+	p.Lock()
+	greeting := p.greeting
+	p.Unlock()
+
+	if greeting == "" {
+		return nil
+	}
+	return p.speak(greeting)
+}
+
+// speak 将一段不经过 LLM 的助手文本作为一轮完整回复发送给播报元素，并记入对话历史
+func (p *Pipeline) speak(text string) error {
+	p.Lock()
+	target := p.speechInput
+	recorder, _ := p.textInput.(ConversationRecorder)
+	p.Unlock()
+
+	if target == nil {
+		return fmt.Errorf("no speech input element, call SetSpeechInput")
+	}
+	if p.draining.Load() {
+		return ErrPipelineStopping
+	}
+
+	msg := &PipelineMessage{
+		Type:      MsgTypeData,
+		Timestamp: time.Now(),
+		TextData: &TextData{
+			Data:      []byte(text),
+			TextType:  "final",
+			Timestamp: time.Now(),
+		},
+	}
+
+	select {
+	case target.In() <- msg:
+		recordIn(target, msg)
+	default:
+		recordDropped(target)
+		return fmt.Errorf("speech input channel of %s is full", target.GetName())
+	}
+
+	if recorder != nil {
+		if err := recorder.AppendMessage("assistant", text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendHistory 把一条既有的对话消息（如上一通电话的历史）记入所有维护对话历史的元素
+// role 为 "system"、"user" 或 "assistant"；实时模型元素在连接建立前调用时会先缓存，连接后作为上下文发送
+// 没有元素实现 ConversationRecorder 时返回错误
+func (p *Pipeline) AppendHistory(role, content string) error {
+	p.Lock()
+	var recorders []ConversationRecorder
+	for _, e := range p.elements {
+		if r, ok := e.(ConversationRecorder); ok {
+			recorders = append(recorders, r)
+		}
+	}
+	p.Unlock()
+
+	if len(recorders) == 0 {
+		return fmt.Errorf("pipeline %s has no element that records conversation history", p.name)
+	}
+	for _, r := range recorders {
+		if err := r.AppendMessage(role, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Health 返回每个实现了 HealthChecker 的元素的连接状态，key 为元素名
+func (p *Pipeline) Health() map[string]bool {
+	p.Lock()
+	defer p.Unlock()
+
+	health := make(map[string]bool)
+	for _, e := range p.elements {
+		if hc, ok := e.(HealthChecker); ok {
+			health[e.GetName()] = hc.Healthy()
+		}
+	}
+	return health
+}
+
+// Statuses 返回每个元素的运行状态（见 BaseElement.Status），key 为元素名
+func (p *Pipeline) Statuses() map[string]ElementState {
+	p.Lock()
+	defer p.Unlock()
+
+	statuses := make(map[string]ElementState)
+	for _, e := range p.elements {
+		if sr, ok := e.(StatusReporter); ok {
+			statuses[e.GetName()] = sr.Status()
+		}
+	}
+	return statuses
+}
+
+// Healthy 当所有实现了 HealthChecker 的元素都健康时返回 true
+func (p *Pipeline) Healthy() bool {
+	for _, ok := range p.Health() {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrPipelineClosed PullContext 在没有输出端或输出端已关闭时返回
+var ErrPipelineClosed = errors.New("pipeline closed")
+
+// Pull 从 Pipeline 的输出端获取消息，阻塞直到有消息；输出端关闭时返回 nil
+// 输出端为标记了 IsSink 的元素，未标记时为最后一个添加的元素
+// 启用了 SpeakingTracker 时，取出的音频计入助手说话状态
+func (p *Pipeline) Pull() *PipelineMessage {
+	msg, _ := p.PullContext(context.Background())
+	return msg
+}
+
+// PullContext 与 Pull 相同，但在 ctx 取消或超时时返回 ctx.Err()，输出端关闭时返回 ErrPipelineClosed
+// 已经取出的消息总会返回，不会因为 ctx 同时取消而丢失
+func (p *Pipeline) PullContext(ctx context.Context) (*PipelineMessage, error) {
+	sink := p.Sink()
+	if sink == nil {
+		return nil, ErrPipelineClosed
+	}
+
+	select {
+	case msg, ok := <-sink.Out():
+		if !ok || msg == nil {
+			return nil, ErrPipelineClosed
+		}
+		recordOut(sink)
+		p.observePulled(msg)
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PullTimeout 最多等待 timeout 获取消息，超时返回 context.DeadlineExceeded
+func (p *Pipeline) PullTimeout(timeout time.Duration) (*PipelineMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return p.PullContext(ctx)
+}
+
+// observePulled 记录取出的消息：报告停滞检测器，音频计入助手说话状态
+func (p *Pipeline) observePulled(msg *PipelineMessage) {
+	p.touch("pull")
+	if msg.Type == MsgTypeAudio {
+		p.Lock()
+		tracker := p.speakingTracker
+		p.Unlock()
+		if tracker != nil {
+			tracker.Observe(msg.AudioData)
+		}
+	}
 }
 
 func (p *Pipeline) Start(ctx context.Context) error {
+	p.Lock()
+	err := p.validateLocked(p.validateOnStart)
+	p.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// 元素启动失败时取消该 context，让卡住的元素和已启动的元素退出
+	ctx, cancel := context.WithCancel(ctx)
+	p.Lock()
+	p.cancel = cancel
+	p.Unlock()
+
 	// 启动事件总线
 	p.bus.Start(ctx)
 
 	// 启动打断管理器（如果已启用）
 	if p.interruptManager != nil {
 		if err := p.interruptManager.Start(ctx); err != nil {
+			p.abortStart(nil)
+			return err
+		}
+	}
+
+	// 启动说话状态跟踪器（如果已启用）
+	if p.speakingTracker != nil {
+		if err := p.speakingTracker.Start(ctx); err != nil {
+			p.abortStart(nil)
+			return err
+		}
+	}
+
+	// 启动静默超时控制器（如果已启用）
+	if p.silenceTimeout != nil {
+		if err := p.silenceTimeout.Start(ctx); err != nil {
+			p.abortStart(nil)
+			return err
+		}
+	}
+
+	// 启动延迟预算控制器（如果已启用）
+	if p.latencyBudget != nil {
+		if err := p.latencyBudget.Start(ctx); err != nil {
+			p.abortStart(nil)
 			return err
 		}
 	}
 
 	// 启动所有 Elements
-	for _, e := range p.elements {
-		if err := e.Start(ctx); err != nil {
+	for i, e := range p.elements {
+		if err := p.startElement(ctx, e); err != nil {
+			p.abortStart(p.elements[:i])
 			return err
 		}
+		if s, ok := e.(interface{ markStarted() }); ok {
+			s.markStarted()
+		}
+	}
+
+	// 启动停滞检测（如果已启用），从所有元素就绪后开始计时
+	if w := p.watchdog.Load(); w != nil {
+		if err := w.Start(ctx); err != nil {
+			p.abortStart(p.elements)
+			return err
+		}
+	}
+
+	// 预热服务商连接（如果已启用），不阻塞 Start
+	p.Lock()
+	prewarm := p.prewarmOnConnect
+	p.Unlock()
+	if prewarm {
+		go p.Prewarm(ctx)
 	}
 
+	// 播报开场白（如果已设置），此时 Pipeline 已在运行，失败只记录日志
+	if err := p.playGreeting(); err != nil {
+		log.Printf("[Pipeline] %s: failed to play greeting: %v", p.name, err)
+	}
 	return nil
 }
 
+// startElement 启动一个元素，超过启动超时时间返回指明该元素的错误
+func (p *Pipeline) startElement(ctx context.Context, e Element) error {
+	timeout := p.startTimeoutFor(e)
+	if timeout <= 0 {
+		if err := e.Start(ctx); err != nil {
+			return fmt.Errorf("pipeline %s: element %s failed to start: %w", p.name, e.GetName(), err)
+		}
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Start(ctx)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("pipeline %s: element %s failed to start: %w", p.name, e.GetName(), err)
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("pipeline %s: element %s did not start within %v", p.name, e.GetName(), timeout)
+	}
+}
+
+// abortStart 元素启动失败后倒序停止已启动的元素和组件，避免泄漏
+// 启动失败（或卡住）的元素不调用 Stop，通过取消 context 通知它退出
+func (p *Pipeline) abortStart(started []Element) {
+	p.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.Unlock()
+	cancel()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := started[i].Stop(); err != nil {
+			log.Printf("[Pipeline] %s: failed to stop element %s after start failure: %v", p.name, started[i].GetName(), err)
+		}
+	}
+	if p.interruptManager != nil {
+		p.interruptManager.Stop()
+	}
+	if p.speakingTracker != nil {
+		p.speakingTracker.Stop()
+	}
+	if p.silenceTimeout != nil {
+		p.silenceTimeout.Stop()
+	}
+	if p.latencyBudget != nil {
+		p.latencyBudget.Stop()
+	}
+	p.bus.Stop()
+}
+
 func (p *Pipeline) Stop() error {
 	p.Lock()
 	defer p.Unlock()
 
+	// 最后取消传给元素的 context
+	if cancel := p.cancel; cancel != nil {
+		p.cancel = nil
+		defer cancel()
+	}
+
+	// 先停止停滞检测，停止过程中的空闲不是故障
+	if w := p.watchdog.Load(); w != nil {
+		if err := w.Stop(); err != nil {
+			return err
+		}
+	}
+
 	// 倒序停止 Elements
 	for i := len(p.elements) - 1; i >= 0; i-- {
 		if err := p.elements[i].Stop(); err != nil {
@@ -235,6 +1142,27 @@ func (p *Pipeline) Stop() error {
 		}
 	}
 
+	// 停止说话状态跟踪器
+	if p.speakingTracker != nil {
+		if err := p.speakingTracker.Stop(); err != nil {
+			return err
+		}
+	}
+
+	// 停止静默超时控制器
+	if p.silenceTimeout != nil {
+		if err := p.silenceTimeout.Stop(); err != nil {
+			return err
+		}
+	}
+
+	// 停止延迟预算控制器
+	if p.latencyBudget != nil {
+		if err := p.latencyBudget.Stop(); err != nil {
+			return err
+		}
+	}
+
 	// 停止事件总线
 	p.bus.Stop()
 	return nil
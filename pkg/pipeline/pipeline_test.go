@@ -2,6 +2,8 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,6 +72,64 @@ func TestPipelineLinkUnlink(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 }
 
+func TestPipelineLinkFanOut(t *testing.T) {
+	p := NewPipeline("test")
+
+	source := NewMockElement()
+	sinks := []*MockElement{NewMockElement(), NewMockElement(), NewMockElement()}
+	p.AddElements([]Element{source, sinks[0], sinks[1], sinks[2]})
+
+	// Link 和 LinkMulti 可以混用，同一个源的下游共享一路分发
+	unlink := p.Link(source, sinks[0])
+	unlinkRest := p.LinkMulti(source, sinks[1], sinks[2])
+	defer unlinkRest()
+
+	const count = 5
+	go func() {
+		for i := 0; i < count; i++ {
+			source.OutChan <- &PipelineMessage{
+				Type:      MsgTypeAudio,
+				SessionID: string(rune('a' + i)),
+				AudioData: &AudioData{Data: []byte{byte(i)}, SampleRate: 16000, Channels: 1},
+			}
+		}
+	}()
+
+	for i := 0; i < count; i++ {
+		for n, sink := range sinks {
+			select {
+			case msg := <-sink.InChan:
+				if msg.SessionID != string(rune('a'+i)) || msg.AudioData.Data[0] != byte(i) {
+					t.Fatalf("Sink %d: expected message %d in order, got %q %v", n, i, msg.SessionID, msg.AudioData.Data)
+				}
+				// 每个下游的音频数据各自一份
+				msg.AudioData.Data[0] = 0xff
+			case <-time.After(time.Second):
+				t.Fatalf("Sink %d: timeout waiting for message %d", n, i)
+			}
+		}
+	}
+
+	// 断开一个下游后其余下游继续收到消息
+	unlink()
+	source.OutChan <- &PipelineMessage{SessionID: "after-unlink"}
+	for n, sink := range sinks[1:] {
+		select {
+		case msg := <-sink.InChan:
+			if msg.SessionID != "after-unlink" {
+				t.Fatalf("Sink %d: unexpected message %q", n+1, msg.SessionID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Sink %d: timeout after unlinking another sink", n+1)
+		}
+	}
+	select {
+	case msg := <-sinks[0].InChan:
+		t.Fatalf("Unlinked sink received %q", msg.SessionID)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
 func TestPipelineStartStop(t *testing.T) {
 	p := NewPipeline("test")
 
@@ -131,3 +191,480 @@ func TestPipelinePushPull(t *testing.T) {
 		t.Errorf("Expected session ID 'test-session', got '%s'", received.SessionID)
 	}
 }
+
+func TestPipelinePullContext(t *testing.T) {
+	p := NewPipeline("test")
+	elem := NewMockElement()
+	p.AddElement(elem)
+
+	// 没有消息时按超时返回，不会一直阻塞
+	start := time.Now()
+	if _, err := p.PullTimeout(20 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("PullTimeout took %v", elapsed)
+	}
+
+	// 已取消的 ctx 不会取走排队的消息
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	elem.OutChan <- &PipelineMessage{SessionID: "queued"}
+	for i := 0; i < 10; i++ {
+		if msg, err := p.PullContext(ctx); err == nil {
+			if msg.SessionID != "queued" {
+				t.Fatalf("Unexpected message %q", msg.SessionID)
+			}
+			break
+		} else if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected Canceled, got %v", err)
+		}
+	}
+	if len(elem.OutChan) == 1 {
+		if msg, err := p.PullTimeout(time.Second); err != nil || msg.SessionID != "queued" {
+			t.Fatalf("Expected the queued message to remain, got %v %v", msg, err)
+		}
+	}
+
+	close(elem.OutChan)
+	if _, err := p.PullContext(context.Background()); !errors.Is(err, ErrPipelineClosed) {
+		t.Fatalf("Expected ErrPipelineClosed, got %v", err)
+	}
+	if p.Pull() != nil {
+		t.Error("Expected Pull to return nil once the sink is closed")
+	}
+}
+
+func TestPipelineSourceSink(t *testing.T) {
+	p := NewPipeline("test")
+
+	monitor := NewMockElement()
+	source := NewMockElement()
+	sink := NewMockElement()
+	tap := NewMockElement()
+	p.AddElements([]Element{monitor, source, sink, tap})
+
+	// 未标记时为第一个/最后一个元素
+	if p.Source() != monitor || p.Sink() != tap {
+		t.Fatal("expected first and last element as default source and sink")
+	}
+
+	source.SetSource(true)
+	sink.SetSink(true)
+	if p.Source() != source || p.Sink() != sink {
+		t.Fatal("expected marked elements as source and sink")
+	}
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+
+	p.Push(&PipelineMessage{SessionID: "in"})
+	select {
+	case msg := <-source.InChan:
+		if msg.SessionID != "in" {
+			t.Errorf("Expected session ID 'in', got '%s'", msg.SessionID)
+		}
+	default:
+		t.Fatal("Push did not reach the source element")
+	}
+
+	sink.OutChan <- &PipelineMessage{SessionID: "out"}
+	tap.OutChan <- &PipelineMessage{SessionID: "tap"}
+	if got := p.Pull().SessionID; got != "out" {
+		t.Errorf("Expected Pull from the sink element, got '%s'", got)
+	}
+}
+
+func TestPipelineMultipleSources(t *testing.T) {
+	p := NewPipeline("test")
+
+	a := NewMockElement()
+	b := NewMockElement()
+	a.SetSource(true)
+	b.SetSource(true)
+	p.AddElements([]Element{a, b})
+
+	if err := p.Start(context.Background()); err == nil {
+		t.Fatal("expected an error for multiple source elements")
+	}
+}
+
+// startElement 记录 Start/Stop 调用，block 为 true 时 Start 阻塞到 context 取消
+type startElement struct {
+	*BaseElement
+	block   bool
+	err     error
+	started bool
+	stopped bool
+}
+
+func (e *startElement) Start(ctx context.Context) error {
+	if e.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	e.started = true
+	return e.err
+}
+
+func (e *startElement) Stop() error {
+	e.stopped = true
+	return nil
+}
+
+func TestPipelineStartTimeout(t *testing.T) {
+	p := NewPipeline("test")
+	first := &startElement{BaseElement: NewBaseElement("first", 10)}
+	stuck := &startElement{BaseElement: NewBaseElement("stuck-provider", 10), block: true}
+	last := &startElement{BaseElement: NewBaseElement("last", 10)}
+	p.AddElements([]Element{first, stuck, last})
+	p.SetStartTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	err := p.Start(context.Background())
+	if err == nil {
+		t.Fatal("expected a start timeout error")
+	}
+	if !strings.Contains(err.Error(), "stuck-provider") {
+		t.Errorf("error should name the stuck element, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Start should give up after the timeout, took %v", elapsed)
+	}
+
+	// 已启动的元素被停止，之后的元素没有启动
+	if !first.stopped {
+		t.Error("already started element should be stopped")
+	}
+	if stuck.stopped || last.started {
+		t.Error("stuck and later elements should not be stopped or started")
+	}
+
+	// 单个元素的超时覆盖全局设置
+	p = NewPipeline("test")
+	slow := &startElement{BaseElement: NewBaseElement("slow", 10), block: true}
+	p.AddElement(slow)
+	p.SetStartTimeout(time.Hour)
+	p.SetElementStartTimeout(slow, 20*time.Millisecond)
+	if err := p.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "slow") {
+		t.Errorf("expected the per-element timeout to apply, got %v", err)
+	}
+}
+
+func TestPipelineStartError(t *testing.T) {
+	p := NewPipeline("test")
+	first := &startElement{BaseElement: NewBaseElement("first", 10)}
+	broken := &startElement{BaseElement: NewBaseElement("broken", 10), err: errors.New("handshake failed")}
+	p.AddElements([]Element{first, broken})
+
+	err := p.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broken") || !strings.Contains(err.Error(), "handshake failed") {
+		t.Fatalf("expected an error naming the element and its cause, got %v", err)
+	}
+	if !first.stopped {
+		t.Error("already started element should be stopped")
+	}
+}
+
+func TestPipelinePushText(t *testing.T) {
+	p := NewPipeline("test")
+
+	stt := NewMockElement()
+	chat := NewMockElement()
+	p.AddElements([]Element{stt, chat})
+
+	// 未指定注入点时发送到第一个元素
+	if err := p.PushText("s1", "  hello  "); err != nil {
+		t.Fatalf("PushText failed: %v", err)
+	}
+	msg := <-stt.InChan
+	if msg.Type != MsgTypeData || string(msg.TextData.Data) != "hello" || msg.TextData.TextType != TextInputType {
+		t.Errorf("Unexpected text message: %+v", msg.TextData)
+	}
+
+	events := make(chan Event, 1)
+	p.Bus().Subscribe(EventTextInput, events)
+
+	p.SetTextInput(chat)
+	if err := p.PushText("s1", "what's the weather"); err != nil {
+		t.Fatalf("PushText failed: %v", err)
+	}
+	msg = <-chat.InChan
+	if msg.SessionID != "s1" || string(msg.TextData.Data) != "what's the weather" {
+		t.Errorf("Expected text routed to chat element, got %+v", msg)
+	}
+	if len(stt.InChan) != 0 {
+		t.Error("Expected no text on the first element")
+	}
+
+	evt := <-events
+	if payload := evt.Payload.(TextInputPayload); payload.Text != "what's the weather" {
+		t.Errorf("Unexpected TextInput payload: %+v", payload)
+	}
+
+	if err := p.PushText("s1", "   "); err == nil {
+		t.Error("Expected error for empty text")
+	}
+	if err := NewPipeline("empty").PushText("s1", "hi"); err == nil {
+		t.Error("Expected error for pipeline without elements")
+	}
+}
+
+// historyElement 记录对话历史的测试元素
+type historyElement struct {
+	*MockElement
+	history []string
+}
+
+func (e *historyElement) AppendMessage(role, content string) error {
+	e.history = append(e.history, role+": "+content)
+	return nil
+}
+
+func TestPipelineAudioTrackInput(t *testing.T) {
+	p := NewPipeline("test")
+	mic := NewMockElement()
+	system := NewMockElement()
+	p.AddElements([]Element{mic, system})
+
+	trackAudio := func(trackID string) *PipelineMessage {
+		msg := &PipelineMessage{Type: MsgTypeAudio, AudioData: &AudioData{Data: []byte{1, 2}}}
+		if trackID != "" {
+			msg.SetAttribute(AttrAudioTrack, trackID)
+		}
+		return msg
+	}
+
+	// 路由的音轨发送到指定元素，未路由的音轨和不带音轨的音频发送到输入端
+	p.SetAudioTrackInput("system", system)
+	p.Push(trackAudio("system"))
+	p.Push(trackAudio("mic"))
+	p.Push(trackAudio(""))
+	if len(system.InChan) != 1 || len(mic.InChan) != 2 {
+		t.Fatalf("Expected 1 message on the system branch and 2 on the source, got %d and %d", len(system.InChan), len(mic.InChan))
+	}
+	if got := (<-system.InChan).Attributes.String(AttrAudioTrack); got != "system" {
+		t.Errorf("Expected the system track audio, got track %q", got)
+	}
+	<-mic.InChan
+	<-mic.InChan
+
+	p.SetAudioTrackInput("system", nil)
+	p.Push(trackAudio("system"))
+	if len(mic.InChan) != 1 {
+		t.Error("Expected audio of an unrouted track on the source")
+	}
+}
+
+func TestPipelinePlayOnStart(t *testing.T) {
+	p := NewPipeline("test")
+
+	chat := &historyElement{MockElement: NewMockElement()}
+	tts := NewMockElement()
+	p.AddElements([]Element{chat, tts})
+	p.SetTextInput(chat)
+	p.SetSpeechInput(tts)
+	p.PlayOnStart("  Hi, how can I help you today?  ")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	// 开场白在任何用户输入之前作为完整回复发送给 TTS
+	select {
+	case msg := <-tts.InChan:
+		if string(msg.TextData.Data) != "Hi, how can I help you today?" || msg.TextData.TextType != "final" {
+			t.Errorf("Unexpected greeting message: %+v", msg.TextData)
+		}
+	default:
+		t.Fatal("Expected greeting on the speech input after Start")
+	}
+	if len(chat.InChan) != 0 {
+		t.Error("Greeting must not be sent to the chat element as user input")
+	}
+	if len(chat.history) != 1 || chat.history[0] != "assistant: Hi, how can I help you today?" {
+		t.Errorf("Expected greeting recorded as assistant message, got %v", chat.history)
+	}
+}
+
+func TestPipelinePlayOnStartWithoutSpeechInput(t *testing.T) {
+	p := NewPipeline("test")
+	e := &startElement{BaseElement: NewBaseElement("tts", 10)}
+	p.AddElement(e)
+	p.PlayOnStart("Hello")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := p.Start(ctx); err == nil {
+		t.Error("Expected error when greeting has no speech input")
+	}
+	// 开场白没有播报元素时在启动任何元素之前失败
+	if e.started {
+		t.Error("Elements should not be started when the greeting has no speech input")
+	}
+	p.Stop()
+}
+
+func TestPipelineAppendHistory(t *testing.T) {
+	p := NewPipeline("test")
+	p.AddElement(NewMockElement())
+	if err := p.AppendHistory("user", "hi"); err == nil {
+		t.Error("Expected error without a conversation recorder")
+	}
+
+	chat := &historyElement{MockElement: NewMockElement()}
+	realtime := &historyElement{MockElement: NewMockElement()}
+	p.AddElements([]Element{chat, realtime})
+
+	if err := p.AppendHistory("user", "My name is Ann"); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+	if err := p.AppendHistory("assistant", "Nice to meet you, Ann"); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	want := []string{"user: My name is Ann", "assistant: Nice to meet you, Ann"}
+	for _, e := range []*historyElement{chat, realtime} {
+		if len(e.history) != 2 || e.history[0] != want[0] || e.history[1] != want[1] {
+			t.Errorf("Expected history %v, got %v", want, e.history)
+		}
+	}
+}
+
+// healthElement 带连接状态的测试元素
+type healthElement struct {
+	*MockElement
+	healthy bool
+}
+
+func (e *healthElement) Healthy() bool {
+	return e.healthy
+}
+
+func TestPipelineHealth(t *testing.T) {
+	p := NewPipeline("test")
+	if !p.Healthy() {
+		t.Error("Expected pipeline without health checkers to be healthy")
+	}
+
+	stt := &healthElement{MockElement: &MockElement{NewBaseElement("stt", 10)}, healthy: true}
+	tts := &healthElement{MockElement: &MockElement{NewBaseElement("tts", 10)}, healthy: true}
+	p.AddElements([]Element{stt, NewMockElement(), tts})
+
+	health := p.Health()
+	if len(health) != 2 || !health["stt"] || !health["tts"] {
+		t.Errorf("Unexpected health: %v", health)
+	}
+	if !p.Healthy() {
+		t.Error("Expected pipeline to be healthy")
+	}
+
+	tts.healthy = false
+	if health := p.Health(); health["tts"] || !health["stt"] {
+		t.Errorf("Expected tts to be reported unhealthy, got %v", health)
+	}
+	if p.Healthy() {
+		t.Error("Expected pipeline to be unhealthy when an element is unhealthy")
+	}
+}
+
+// resetElement 记录 ResetInput 调用次数的测试元素
+type resetElement struct {
+	*MockElement
+	resets int
+}
+
+func (e *resetElement) ResetInput() {
+	e.resets++
+}
+
+func TestPipelineMuteInput(t *testing.T) {
+	p := NewPipeline("test")
+	vad := &resetElement{MockElement: NewMockElement()}
+	p.AddElements([]Element{vad, NewMockElement()})
+
+	events := make(chan Event, 4)
+	p.Bus().Subscribe(EventInputMuted, events)
+	p.Bus().Subscribe(EventInputUnmuted, events)
+
+	p.MuteInput()
+	p.MuteInput() // 重复静音不再重置
+	if !p.InputMuted() {
+		t.Fatal("Expected input to be muted")
+	}
+	if vad.resets != 1 {
+		t.Errorf("Expected 1 ResetInput call, got %d", vad.resets)
+	}
+
+	// 静音期间音频被丢弃，文本等其他消息照常通过
+	p.Push(&PipelineMessage{Type: MsgTypeAudio})
+	p.Push(&PipelineMessage{Type: MsgTypeData})
+	if n := len(vad.InChan); n != 1 {
+		t.Fatalf("Expected only the data message to pass, got %d messages", n)
+	}
+	if msg := <-vad.InChan; msg.Type != MsgTypeData {
+		t.Errorf("Expected data message, got type %d", msg.Type)
+	}
+
+	p.UnmuteInput()
+	if p.InputMuted() {
+		t.Fatal("Expected input to be unmuted")
+	}
+	p.Push(&PipelineMessage{Type: MsgTypeAudio})
+	if n := len(vad.InChan); n != 1 {
+		t.Errorf("Expected audio to pass after unmute, got %d messages", n)
+	}
+
+	for _, want := range []EventType{EventInputMuted, EventInputUnmuted} {
+		select {
+		case evt := <-events:
+			if evt.Type != want {
+				t.Errorf("Expected %s, got %s", want, evt.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for %s", want)
+		}
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no further events, got %d", len(events))
+	}
+}
+
+// formatElement 声明输出格式并记录 Link 时收到的输入格式
+type formatElement struct {
+	*MockElement
+	output AudioFormat
+	input  AudioFormat
+}
+
+func (e *formatElement) OutputFormat() AudioFormat {
+	return e.output
+}
+
+func (e *formatElement) SetInputFormat(format AudioFormat) {
+	e.input = format
+}
+
+func TestPipelineLinkNegotiatesAudioFormat(t *testing.T) {
+	p := NewPipeline("test")
+	decoder := &formatElement{MockElement: NewMockElement(), output: AudioFormat{SampleRate: 48000, Channels: 2}}
+	resample := &formatElement{MockElement: NewMockElement()}
+	plain := NewMockElement()
+	p.AddElements([]Element{decoder, resample, plain})
+
+	p.Link(decoder, resample)
+	if resample.input != (AudioFormat{SampleRate: 48000, Channels: 2}) {
+		t.Errorf("Expected downstream to receive 48000Hz stereo, got %+v", resample.input)
+	}
+
+	// 未声明输出格式的上游不覆盖已知格式
+	p.Link(plain, resample)
+	if resample.input.SampleRate != 48000 {
+		t.Errorf("Expected input format to be kept, got %+v", resample.input)
+	}
+}
@@ -0,0 +1,76 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// 预热: 会话中第一次调用 LLM/TTS 通常最慢，因为要先完成 DNS、TCP、TLS 握手
+// 或 WebSocket 建连。预热在会话建立后、用户开口之前让实现 Prewarmer 的元素
+// 提前建立连接，把这部分延迟从第一轮对话中移走。
+//
+// 每个元素预热完成后发布 EventPrewarmed，Duration 即第一轮对话省下的建连耗时，
+// 可用于监控冷启动延迟。预热失败只记录日志，第一轮对话照常建连。
+//
+// 使用示例:
+//
+//	p.SetPrewarmOnConnect(true)
+//	p.Start(ctx) // Start 返回后在后台预热
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// SetPrewarmOnConnect 设置 Start 完成后是否在后台预热所有实现 Prewarmer 的元素
+func (p *Pipeline) SetPrewarmOnConnect(enabled bool) {
+	p.Lock()
+	defer p.Unlock()
+	p.prewarmOnConnect = enabled
+}
+
+// Prewarm 并发预热所有实现 Prewarmer 的元素，等待全部完成
+// 每个元素完成后发布 EventPrewarmed；返回所有失败元素的错误（errors.Join）
+// 需在 Start 之后调用，SetPrewarmOnConnect(true) 时由 Start 自动调用
+func (p *Pipeline) Prewarm(ctx context.Context) error {
+	p.Lock()
+	var prewarmers []Element
+	for _, e := range p.elements {
+		if _, ok := e.(Prewarmer); ok {
+			prewarmers = append(prewarmers, e)
+		}
+	}
+	p.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, e := range prewarmers {
+		wg.Add(1)
+		go func(e Element) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := e.(Prewarmer).Prewarm(ctx)
+			elapsed := time.Since(start)
+			if err != nil {
+				log.Printf("[Pipeline] %s: failed to prewarm %s: %v", p.name, e.GetName(), err)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			} else {
+				log.Printf("[Pipeline] %s: prewarmed %s in %v", p.name, e.GetName(), elapsed)
+			}
+
+			p.bus.Publish(Event{
+				Type:      EventPrewarmed,
+				Timestamp: time.Now(),
+				Payload:   PrewarmPayload{Element: e.GetName(), Duration: elapsed, Err: err},
+			})
+		}(e)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// coldElement 模拟服务商连接：未预热时第一次请求要先付出建连耗时
+type coldElement struct {
+	*MockElement
+	connectCost time.Duration
+	err         error
+
+	mu        sync.Mutex
+	connected bool
+}
+
+func (e *coldElement) connect() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.connected {
+		time.Sleep(e.connectCost)
+		e.connected = true
+	}
+}
+
+func (e *coldElement) Prewarm(ctx context.Context) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.connect()
+	return nil
+}
+
+// firstTurn 返回第一次请求的延迟
+func (e *coldElement) firstTurn() time.Duration {
+	start := time.Now()
+	e.connect()
+	return time.Since(start)
+}
+
+func TestPipelinePrewarmOnConnect(t *testing.T) {
+	const connectCost = 50 * time.Millisecond
+	newCold := func() *coldElement {
+		return &coldElement{MockElement: &MockElement{NewBaseElement("llm", 10)}, connectCost: connectCost}
+	}
+
+	// 未预热：第一轮承担建连耗时
+	cold := newCold()
+	p := NewPipeline("test")
+	p.AddElement(cold)
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if latency := cold.firstTurn(); latency < connectCost {
+		t.Errorf("Expected the cold first turn to pay the connect cost, took %v", latency)
+	}
+	p.Stop()
+
+	// 预热：Start 后在后台建连，发布 EventPrewarmed
+	warm := newCold()
+	p = NewPipeline("test")
+	p.AddElements([]Element{NewMockElement(), warm})
+	p.SetPrewarmOnConnect(true)
+	events := make(chan Event, 1)
+	p.Bus().Subscribe(EventPrewarmed, events)
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(PrewarmPayload)
+		if payload.Element != "llm" || payload.Err != nil || payload.Duration < connectCost {
+			t.Errorf("Unexpected prewarm payload %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for EventPrewarmed")
+	}
+	if latency := warm.firstTurn(); latency >= connectCost {
+		t.Errorf("Expected the prewarmed first turn to skip the connect cost, took %v", latency)
+	}
+}
+
+func TestPipelinePrewarmError(t *testing.T) {
+	errUnreachable := errors.New("unreachable")
+	p := NewPipeline("test")
+	p.AddElements([]Element{
+		&coldElement{MockElement: &MockElement{NewBaseElement("tts", 10)}, err: errUnreachable},
+		&coldElement{MockElement: &MockElement{NewBaseElement("llm", 10)}},
+	})
+
+	if err := p.Prewarm(context.Background()); !errors.Is(err, errUnreachable) {
+		t.Errorf("Expected the failing element's error, got %v", err)
+	}
+}
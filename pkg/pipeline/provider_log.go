@@ -0,0 +1,174 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// ProviderLogger 记录发给 STT、LLM、TTS 服务商的每个请求和收到的响应，
+// 便于确认实际发给服务商的参数（模型、语言、音色）和返回的内容。默认关闭。
+//
+// 主要功能:
+//   - 每条记录输出一行 "[provider]" 开头的 JSON，包含服务商、元素、模型、文本和耗时
+//   - 音频默认只记录字节数，IncludeAudio 时以 base64 记录内容
+//   - RedactText 只记录字符数，RedactPII 屏蔽邮箱、电话号码和长数字，MaxTextLen 截断长文本
+//   - 通过 Pipeline.SetProviderLogger 注入所有实现 ProviderLogAware 的元素
+//
+// 使用示例:
+//
+//	p.SetProviderLogger(pipeline.NewProviderLogger(pipeline.ProviderLogConfig{
+//	    RedactPII: true,
+//	}))
+package pipeline
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+// ProviderLogScope 服务商审计日志的作用域，每行日志以 "[provider]" 开头
+const ProviderLogScope = "provider"
+
+// ProviderKind 服务商类型
+type ProviderKind string
+
+const (
+	ProviderSTT ProviderKind = "stt"
+	ProviderLLM ProviderKind = "llm"
+	ProviderTTS ProviderKind = "tts"
+)
+
+// ProviderLogConfig 服务商请求/响应审计日志配置
+type ProviderLogConfig struct {
+	// Output 日志输出，默认为标准 log 包的输出
+	Output io.Writer
+
+	// IncludeAudio 为 true 时以 base64 记录音频内容，默认只记录字节数
+	IncludeAudio bool
+
+	// RedactText 为 true 时不记录文本内容，只记录字符数
+	RedactText bool
+
+	// RedactPII 为 true 时屏蔽文本中的邮箱、电话号码和长数字（如卡号、证件号）
+	RedactPII bool
+
+	// MaxTextLen 文本最多记录的字符数，超出部分截断，0 表示不限制
+	MaxTextLen int
+}
+
+// ProviderRecord 一次服务商请求或响应
+type ProviderRecord struct {
+	Kind     ProviderKind
+	Provider string // 服务商名称，如 "openai"、"elevenlabs"
+	Element  string // 发起请求的元素名
+	Response bool   // false 为请求，true 为响应
+
+	Model    string
+	Language string
+	Voice    string
+	Text     string
+	Audio    []byte
+
+	Duration time.Duration // 请求到响应的耗时，仅响应记录
+	Err      error
+}
+
+// providerLogEntry 一行审计日志的 JSON 结构
+type providerLogEntry struct {
+	Kind       ProviderKind `json:"kind"`
+	Provider   string       `json:"provider,omitempty"`
+	Element    string       `json:"element,omitempty"`
+	Direction  string       `json:"direction"`
+	Model      string       `json:"model,omitempty"`
+	Language   string       `json:"language,omitempty"`
+	Voice      string       `json:"voice,omitempty"`
+	Text       string       `json:"text,omitempty"`
+	TextChars  int          `json:"text_chars,omitempty"`
+	AudioBytes int          `json:"audio_bytes,omitempty"`
+	Audio      []byte       `json:"audio,omitempty"`
+	DurationMs int64        `json:"duration_ms,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	numberPattern = regexp.MustCompile(`\+?\d[\d\s\-().]{5,}\d`)
+)
+
+// ProviderLogger 把 STT/LLM/TTS 服务商的请求和响应记录为结构化日志（每行一个 JSON）
+// 用于排查语言识别错误、音色不对等服务商相关问题，音频默认只记录字节数
+// 通过 Pipeline.SetProviderLogger 启用；nil ProviderLogger 不记录任何内容
+type ProviderLogger struct {
+	config ProviderLogConfig
+	out    *log.Logger
+}
+
+// NewProviderLogger 创建服务商审计日志
+func NewProviderLogger(config ProviderLogConfig) *ProviderLogger {
+	if config.Output == nil {
+		config.Output = log.Writer()
+	}
+	return &ProviderLogger{
+		config: config,
+		out:    log.New(config.Output, "["+ProviderLogScope+"] ", log.LstdFlags|log.Lmicroseconds),
+	}
+}
+
+// Log 按配置脱敏后记录一条请求或响应
+func (l *ProviderLogger) Log(rec ProviderRecord) {
+	if l == nil {
+		return
+	}
+
+	entry := providerLogEntry{
+		Kind:       rec.Kind,
+		Provider:   rec.Provider,
+		Element:    rec.Element,
+		Direction:  "request",
+		Model:      rec.Model,
+		Language:   rec.Language,
+		Voice:      rec.Voice,
+		AudioBytes: len(rec.Audio),
+		DurationMs: rec.Duration.Milliseconds(),
+	}
+	if rec.Response {
+		entry.Direction = "response"
+	}
+	if rec.Text != "" {
+		entry.TextChars = utf8.RuneCountInString(rec.Text)
+		entry.Text = l.redactText(rec.Text)
+	}
+	if l.config.IncludeAudio {
+		entry.Audio = rec.Audio
+	}
+	if rec.Err != nil {
+		entry.Error = rec.Err.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		l.out.Printf("failed to encode record: %v", err)
+		return
+	}
+	l.out.Println(string(data))
+}
+
+// redactText 按配置屏蔽和截断文本，RedactText 时返回空串
+func (l *ProviderLogger) redactText(text string) string {
+	if l.config.RedactText {
+		return ""
+	}
+	if l.config.RedactPII {
+		text = emailPattern.ReplaceAllString(text, "[email]")
+		text = numberPattern.ReplaceAllString(text, "[number]")
+	}
+	if l.config.MaxTextLen > 0 && utf8.RuneCountInString(text) > l.config.MaxTextLen {
+		text = string([]rune(text)[:l.config.MaxTextLen]) + "…"
+	}
+	return text
+}
+
+// ProviderLogAware 由调用服务商的元素实现
+// BaseElement 已实现该接口，Pipeline 在添加元素时自动注入
+type ProviderLogAware interface {
+	SetProviderLogger(l *ProviderLogger)
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeProviderLog 解析审计日志输出的每一行
+func decodeProviderLog(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if !strings.HasPrefix(line, "["+ProviderLogScope+"] ") {
+			t.Fatalf("Expected provider scope prefix, got %q", line)
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line[strings.Index(line, "{"):]), &entry); err != nil {
+			t.Fatalf("Invalid JSON in %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestProviderLoggerRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewProviderLogger(ProviderLogConfig{Output: &buf, RedactPII: true, MaxTextLen: 60})
+
+	l.Log(ProviderRecord{
+		Kind:     ProviderTTS,
+		Provider: "elevenlabs",
+		Voice:    "rachel",
+		Text:     "Mail ann@example.com or call +1 (555) 010-9999",
+	})
+	l.Log(ProviderRecord{
+		Kind:     ProviderTTS,
+		Provider: "elevenlabs",
+		Response: true,
+		Audio:    []byte{1, 2, 3, 4},
+		Duration: 250 * time.Millisecond,
+		Err:      errors.New("quota exceeded"),
+	})
+
+	entries := decodeProviderLog(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	req := entries[0]
+	if req["direction"] != "request" || req["kind"] != "tts" || req["voice"] != "rachel" {
+		t.Errorf("Unexpected request entry: %v", req)
+	}
+	if req["text"] != "Mail [email] or call [number]" {
+		t.Errorf("Expected PII to be redacted, got %q", req["text"])
+	}
+
+	resp := entries[1]
+	if resp["direction"] != "response" || resp["audio_bytes"] != 4.0 || resp["duration_ms"] != 250.0 {
+		t.Errorf("Unexpected response entry: %v", resp)
+	}
+	if _, ok := resp["audio"]; ok {
+		t.Error("Expected audio bytes to be redacted by default")
+	}
+	if resp["error"] != "quota exceeded" {
+		t.Errorf("Expected error to be logged, got %v", resp["error"])
+	}
+
+	// 完全屏蔽文本时只保留字符数；截断超长文本
+	buf.Reset()
+	NewProviderLogger(ProviderLogConfig{Output: &buf, RedactText: true}).
+		Log(ProviderRecord{Kind: ProviderLLM, Text: "我的卡号是多少"})
+	NewProviderLogger(ProviderLogConfig{Output: &buf, MaxTextLen: 5}).
+		Log(ProviderRecord{Kind: ProviderLLM, Text: "hello world"})
+	entries = decodeProviderLog(t, buf.String())
+	if _, ok := entries[0]["text"]; ok || entries[0]["text_chars"] != 7.0 {
+		t.Errorf("Expected only the text length, got %v", entries[0])
+	}
+	if entries[1]["text"] != "hello…" {
+		t.Errorf("Expected truncated text, got %q", entries[1]["text"])
+	}
+
+	// nil 日志是空操作
+	var none *ProviderLogger
+	none.Log(ProviderRecord{Kind: ProviderSTT})
+}
+
+func TestPipelineSetProviderLogger(t *testing.T) {
+	p := NewPipeline("test")
+	before := NewMockElement()
+	p.AddElement(before)
+	if before.ProviderLogger() != nil {
+		t.Fatal("Expected provider logging to be off by default")
+	}
+
+	l := NewProviderLogger(ProviderLogConfig{})
+	p.SetProviderLogger(l)
+	after := NewMockElement()
+	p.AddElement(after)
+
+	if before.ProviderLogger() != l || after.ProviderLogger() != l {
+		t.Error("Expected existing and new elements to receive the provider logger")
+	}
+
+	p.SetProviderLogger(nil)
+	if before.ProviderLogger() != nil {
+		t.Error("Expected provider logger to be cleared")
+	}
+}
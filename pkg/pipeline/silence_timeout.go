@@ -0,0 +1,305 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// SilenceTimeout 实现呼叫中心常见的静默超时处理：助手说完后用户长时间不说话时
+// 自动播报提示（"Are you still there?"），连续多次没有回应则挂断。
+//
+// 工作原理:
+//   - 助手说完（EventAssistantSpeakingEnd 或 EventPlaybackEnd）后开始计时，
+//     STT 判定用户的声音不是语音（EventNoResult）时也重新计时
+//   - 助手开始说话、开始生成回复、用户开始说话（EventVADSpeechStart）时停止计时
+//   - 超过 Timeout 仍无回应时记一次 strike，发布 EventSilencePrompt 并播报提示语，
+//     提示语播完后重新计时
+//   - 用户说出一轮完整的话（EventFinalResult）或键入文本（EventTextInput）后 strike 清零
+//   - 连续提示 MaxPrompts 次后再次超时，发布 EventSilenceHangup，播报 HangupMessage（如果设置）
+//     并在其播完后调用 OnHangup
+//
+// 提示语和告别语经 SetSpeechInput 指定的元素播报，与开场白相同；
+// 计时依赖助手说话状态，需要同时启用 SpeakingTracker 或使用发布 EventPlaybackEnd 的输出元素。
+//
+// 使用示例:
+//
+//	p.SetSpeechInput(tts)
+//	p.EnableSpeakingTracker(pipeline.SpeakingConfig{})
+//	p.EnableSilenceTimeout(pipeline.SilenceTimeoutConfig{
+//		Timeout:       8 * time.Second,
+//		HangupMessage: "I'll end the call now. Goodbye!",
+//		OnHangup:      func(pipeline.SilenceTimeoutPayload) { session.Close() },
+//	})
+package pipeline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSilenceTimeout 默认的静默超时时长
+	defaultSilenceTimeout = 8 * time.Second
+	// defaultSilencePrompt 默认的提示语
+	defaultSilencePrompt = "Are you still there?"
+	// defaultSilenceMaxPrompts 默认挂断前的提示次数
+	defaultSilenceMaxPrompts = 2
+)
+
+// SilenceTimeoutConfig 静默超时配置
+type SilenceTimeoutConfig struct {
+	// Timeout 助手说完后等待用户回应的时长，默认 8s
+	Timeout time.Duration
+
+	// Prompts 依次播报的提示语，提示次数超过条数时重复最后一条，默认 "Are you still there?"
+	Prompts []string
+
+	// MaxPrompts 挂断前最多提示几次，默认 2；小于 0 表示不提示，第一次超时即挂断
+	MaxPrompts int
+
+	// HangupMessage 挂断前播报的告别语（可选）
+	HangupMessage string
+
+	// OnHangup 挂断回调（可选），在独立的 goroutine 中执行，通常关闭会话或调用 Pipeline.Stop
+	OnHangup func(SilenceTimeoutPayload)
+
+	// Clock 时间源，默认 SystemClock
+	Clock Clock
+}
+
+// SilenceTimeoutPayload EventSilencePrompt 和 EventSilenceHangup 的 Payload
+type SilenceTimeoutPayload struct {
+	Strike  int           // 连续第几次超时，从 1 开始
+	Silence time.Duration // 本次超时前用户已静默的时长
+	Text    string        // 播报的提示语或告别语，未播报时为空
+}
+
+// SilenceTimeout 静默超时控制器
+type SilenceTimeout struct {
+	bus    Bus
+	config SilenceTimeoutConfig
+	say    func(text string) error // 播报助手文本，由 Pipeline 设置
+
+	mu           sync.Mutex
+	armed        bool
+	since        time.Time // 开始计时的时刻
+	userSpeaking bool
+	strikes      int
+	hangingUp    bool // 正在播报告别语，播完后挂断
+	done         bool // 已挂断
+
+	events chan Event
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// silenceEvents 影响计时的对话事件
+var silenceEvents = []EventType{
+	EventAssistantSpeakingStart, EventAssistantSpeakingEnd, EventPlaybackStart, EventPlaybackEnd,
+	EventResponseStart, EventVADSpeechStart, EventVADSpeechEnd, EventFinalResult, EventNoResult, EventTextInput,
+}
+
+// NewSilenceTimeout 创建静默超时控制器，say 用于播报提示语和告别语（可为 nil，只发布事件）
+func NewSilenceTimeout(bus Bus, config SilenceTimeoutConfig, say func(text string) error) *SilenceTimeout {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultSilenceTimeout
+	}
+	if len(config.Prompts) == 0 {
+		config.Prompts = []string{defaultSilencePrompt}
+	}
+	if config.MaxPrompts == 0 {
+		config.MaxPrompts = defaultSilenceMaxPrompts
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &SilenceTimeout{
+		bus:    bus,
+		config: config,
+		say:    say,
+		events: make(chan Event, 20),
+	}
+}
+
+// Start 开始监听，助手第一次说完后才开始计时
+func (s *SilenceTimeout) Start(ctx context.Context) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	// 同一个 channel 订阅所有事件，保持事件的先后顺序
+	for _, t := range silenceEvents {
+		s.bus.Subscribe(t, s.events)
+	}
+
+	s.wg.Add(1)
+	go s.run(ctx)
+	return nil
+}
+
+// Stop 停止监听
+func (s *SilenceTimeout) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+		s.wg.Wait()
+		s.cancel = nil
+	}
+	for _, t := range silenceEvents {
+		s.bus.Unsubscribe(t, s.events)
+	}
+	return nil
+}
+
+// Strikes 返回当前连续超时的次数
+func (s *SilenceTimeout) Strikes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.strikes
+}
+
+func (s *SilenceTimeout) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	// 只在截止时刻变化时创建新的定时器
+	var timer <-chan time.Time
+	var timerDeadline time.Time
+	for {
+		s.mu.Lock()
+		if !s.armed {
+			timer = nil
+		} else if deadline := s.since.Add(s.config.Timeout); timer == nil || !deadline.Equal(timerDeadline) {
+			timer = s.config.Clock.After(deadline.Sub(s.config.Clock.Now()))
+			timerDeadline = deadline
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-s.events:
+			s.handle(evt)
+		case <-timer:
+			timer = nil
+			s.fire()
+		}
+	}
+}
+
+// handle 根据对话事件开始或停止计时
+func (s *SilenceTimeout) handle(evt Event) {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+
+	if s.hangingUp {
+		// 告别语期间只等它播完
+		finished := evt.Type == EventAssistantSpeakingEnd || evt.Type == EventPlaybackEnd
+		s.mu.Unlock()
+		if finished {
+			s.hangup()
+		}
+		return
+	}
+
+	switch evt.Type {
+	case EventAssistantSpeakingEnd:
+		// 被打断时用户正在说话或输入，由对应事件处理
+		if p, ok := evt.Payload.(SpeakingPayload); ok && p.Reason == "interrupted" {
+			break
+		}
+		s.arm()
+	case EventPlaybackEnd, EventNoResult:
+		s.arm()
+	case EventVADSpeechStart:
+		s.userSpeaking = true
+		s.armed = false
+	case EventVADSpeechEnd:
+		// 等待 STT 结果：EventFinalResult 表示用户已回应，EventNoResult 重新计时
+		s.userSpeaking = false
+	case EventFinalResult, EventTextInput:
+		s.strikes = 0
+		s.armed = false
+	default:
+		s.armed = false
+	}
+	s.mu.Unlock()
+}
+
+// arm 从现在开始计时，用户正在说话时不计时；需持有锁
+func (s *SilenceTimeout) arm() {
+	if s.userSpeaking {
+		return
+	}
+	s.armed = true
+	s.since = s.config.Clock.Now()
+}
+
+// fire 超时：播报提示语，或在提示次数用完后挂断
+func (s *SilenceTimeout) fire() {
+	now := s.config.Clock.Now()
+	s.mu.Lock()
+	if !s.armed || now.Before(s.since.Add(s.config.Timeout)) {
+		s.mu.Unlock()
+		return
+	}
+	if s.hangingUp {
+		// 告别语没有播完的通知，超时后直接挂断
+		s.mu.Unlock()
+		s.hangup()
+		return
+	}
+
+	s.strikes++
+	payload := SilenceTimeoutPayload{Strike: s.strikes, Silence: now.Sub(s.since)}
+	hangup := s.strikes > s.config.MaxPrompts
+	if hangup {
+		payload.Text = s.config.HangupMessage
+		s.hangingUp = payload.Text != ""
+	} else {
+		i := s.strikes - 1
+		if i >= len(s.config.Prompts) {
+			i = len(s.config.Prompts) - 1
+		}
+		payload.Text = s.config.Prompts[i]
+	}
+	// 播报后重新计时，收到说话开始/结束事件时会再次调整
+	s.since = now
+	s.armed = payload.Text != ""
+	s.mu.Unlock()
+
+	eventType := EventSilencePrompt
+	if hangup {
+		eventType = EventSilenceHangup
+		log.Printf("[SilenceTimeout] No response after %d prompts, hanging up", payload.Strike-1)
+	} else {
+		log.Printf("[SilenceTimeout] Silent for %v, prompting (strike %d)", payload.Silence, payload.Strike)
+	}
+	s.bus.Publish(Event{
+		Type:      eventType,
+		Timestamp: now,
+		Payload:   payload,
+	})
+
+	if payload.Text != "" && s.say != nil {
+		if err := s.say(payload.Text); err != nil {
+			log.Printf("[SilenceTimeout] Failed to speak %q: %v", payload.Text, err)
+		}
+	}
+	if hangup && payload.Text == "" {
+		s.hangup()
+	}
+}
+
+// hangup 调用 OnHangup，只执行一次
+func (s *SilenceTimeout) hangup() {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+	s.done = true
+	s.armed = false
+	payload := SilenceTimeoutPayload{Strike: s.strikes, Text: s.config.HangupMessage}
+	s.mu.Unlock()
+
+	if s.config.OnHangup != nil {
+		go s.config.OnHangup(payload)
+	}
+}
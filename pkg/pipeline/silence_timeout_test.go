@@ -0,0 +1,198 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// silenceHarness 用 ManualClock 驱动 SilenceTimeout，记录播报的文本
+type silenceHarness struct {
+	t      *testing.T
+	clock  *ManualClock
+	bus    Bus
+	st     *SilenceTimeout
+	events chan Event
+	said   chan string
+	hungUp chan SilenceTimeoutPayload
+}
+
+func newSilenceHarness(t *testing.T, config SilenceTimeoutConfig) *silenceHarness {
+	h := &silenceHarness{
+		t:      t,
+		clock:  NewManualClock(time.Unix(0, 0)),
+		bus:    NewEventBus(),
+		events: make(chan Event, 10),
+		said:   make(chan string, 10),
+		hungUp: make(chan SilenceTimeoutPayload, 1),
+	}
+	h.bus.Subscribe(EventSilencePrompt, h.events)
+	h.bus.Subscribe(EventSilenceHangup, h.events)
+
+	config.Clock = h.clock
+	config.OnHangup = func(p SilenceTimeoutPayload) { h.hungUp <- p }
+	h.st = NewSilenceTimeout(h.bus, config, func(text string) error {
+		h.said <- text
+		return nil
+	})
+	if err := h.st.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { h.st.Stop() })
+	return h
+}
+
+// publish 发布对话事件，并等待控制器处理完毕
+func (h *silenceHarness) publish(eventType EventType, payload interface{}) {
+	h.bus.Publish(Event{Type: eventType, Payload: payload})
+	deadline := time.Now().Add(time.Second)
+	for len(h.st.events) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+}
+
+// assistantFinished 模拟助手说完一轮
+func (h *silenceHarness) assistantFinished() {
+	h.publish(EventAssistantSpeakingEnd, SpeakingPayload{Reason: "finished"})
+}
+
+func (h *silenceHarness) expectEvent(want EventType, strike int, text string) {
+	h.t.Helper()
+	select {
+	case evt := <-h.events:
+		p := evt.Payload.(SilenceTimeoutPayload)
+		if evt.Type != want || p.Strike != strike || p.Text != text {
+			h.t.Fatalf("Expected %s strike %d %q, got %s %+v", want, strike, text, evt.Type, p)
+		}
+	case <-time.After(time.Second):
+		h.t.Fatalf("Timeout waiting for %s", want)
+	}
+	if text != "" {
+		if said := <-h.said; said != text {
+			h.t.Errorf("Expected %q to be spoken, got %q", text, said)
+		}
+	}
+}
+
+func (h *silenceHarness) expectQuiet() {
+	h.t.Helper()
+	time.Sleep(20 * time.Millisecond)
+	if len(h.events) != 0 {
+		h.t.Fatalf("Unexpected event %s", (<-h.events).Type)
+	}
+}
+
+func TestSilenceTimeoutPromptsThenHangsUp(t *testing.T) {
+	h := newSilenceHarness(t, SilenceTimeoutConfig{
+		Timeout:       5 * time.Second,
+		Prompts:       []string{"Are you still there?", "Hello?"},
+		MaxPrompts:    3,
+		HangupMessage: "Goodbye!",
+	})
+
+	// 助手说完前不计时
+	h.clock.Advance(time.Minute)
+	h.expectQuiet()
+
+	h.assistantFinished()
+	h.clock.BlockUntil(1)
+	h.clock.Advance(4 * time.Second)
+	h.expectQuiet()
+	h.clock.Advance(time.Second)
+	h.expectEvent(EventSilencePrompt, 1, "Are you still there?")
+
+	// 提示语播报期间不计时，播完后重新计时
+	h.publish(EventAssistantSpeakingStart, SpeakingPayload{})
+	h.clock.Advance(time.Minute)
+	h.expectQuiet()
+	h.assistantFinished()
+	h.clock.Advance(5 * time.Second)
+	h.expectEvent(EventSilencePrompt, 2, "Hello?")
+
+	// 没有说话状态事件时按播报时刻计时，提示语用完后重复最后一条
+	h.clock.Advance(5 * time.Second)
+	h.expectEvent(EventSilencePrompt, 3, "Hello?")
+
+	h.clock.Advance(5 * time.Second)
+	h.expectEvent(EventSilenceHangup, 4, "Goodbye!")
+	select {
+	case <-h.hungUp:
+		t.Fatal("Expected hangup to wait for the goodbye to finish")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	h.publish(EventAssistantSpeakingStart, SpeakingPayload{})
+	h.assistantFinished()
+	select {
+	case p := <-h.hungUp:
+		if p.Strike != 4 {
+			t.Errorf("Unexpected hangup payload %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for OnHangup")
+	}
+}
+
+func TestSilenceTimeoutUserActivity(t *testing.T) {
+	h := newSilenceHarness(t, SilenceTimeoutConfig{Timeout: 5 * time.Second, MaxPrompts: 1})
+
+	h.assistantFinished()
+	h.clock.BlockUntil(1)
+	h.clock.Advance(5 * time.Second)
+	h.expectEvent(EventSilencePrompt, 1, defaultSilencePrompt)
+
+	// 用户正在说话时不计时，说完一轮后 strike 清零
+	h.publish(EventVADSpeechStart, nil)
+	h.clock.Advance(time.Minute)
+	h.expectQuiet()
+	h.publish(EventVADSpeechEnd, nil)
+	h.publish(EventFinalResult, nil)
+	if h.st.Strikes() != 0 {
+		t.Fatalf("Expected strikes to reset after the user answered, got %d", h.st.Strikes())
+	}
+
+	// 打断结束的说话不计时，噪声（EventNoResult）后重新计时
+	h.publish(EventAssistantSpeakingEnd, SpeakingPayload{Reason: "interrupted"})
+	h.clock.Advance(time.Minute)
+	h.expectQuiet()
+	h.publish(EventNoResult, NoResultPayload{})
+	h.clock.BlockUntil(1)
+	h.clock.Advance(5 * time.Second)
+	h.expectEvent(EventSilencePrompt, 1, defaultSilencePrompt)
+
+	// 提示次数用完且没有告别语时直接挂断
+	h.clock.Advance(5 * time.Second)
+	h.expectEvent(EventSilenceHangup, 2, "")
+	select {
+	case <-h.hungUp:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for OnHangup")
+	}
+}
+
+func TestPipelineSilenceTimeoutSpeaks(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	tts := NewMockElement()
+	p := NewPipeline("test")
+	p.AddElement(tts)
+	p.SetSpeechInput(tts)
+	p.EnableSilenceTimeout(SilenceTimeoutConfig{Timeout: time.Second, Clock: clock})
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	p.Bus().Publish(Event{Type: EventPlaybackEnd})
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	select {
+	case msg := <-tts.InChan:
+		if string(msg.TextData.Data) != defaultSilencePrompt {
+			t.Errorf("Expected the prompt to be sent to the speech input, got %q", msg.TextData.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the prompt")
+	}
+}
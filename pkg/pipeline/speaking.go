@@ -0,0 +1,205 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// SpeakingTracker 根据实际输出的音频判断助手是否正在说话，发布
+// EventAssistantSpeakingStart/End，供前端驱动头像动画等 UI 状态。
+//
+// 工作原理:
+//   - 每段输出音频按时长累加到预计播放结束时刻（突发输出会排队，不会提前结束）
+//   - 播放结束后再等待 Hangover，吸收网络抖动和分句之间的短暂空隙
+//   - 收到 EventInterrupted 时客户端音频已被清空，立即结束
+//
+// 使用示例:
+//
+//	p.EnableSpeakingTracker(pipeline.SpeakingConfig{Hangover: 300 * time.Millisecond})
+//	// Pipeline.Pull 返回的音频会自动计入；EventBridge 开启 Speaking 后转发到客户端
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultSpeakingHangover 默认的说话结束等待时间
+const defaultSpeakingHangover = 300 * time.Millisecond
+
+// encodedFrameDuration 无法从字节数推算时长的编码音频（如 Opus）按一帧估算
+const encodedFrameDuration = 20 * time.Millisecond
+
+// SpeakingConfig 说话状态跟踪配置
+type SpeakingConfig struct {
+	// Hangover 音频播放完后再等待多久才认为说话结束，默认 300ms
+	Hangover time.Duration
+
+	// Clock 时间源，默认 SystemClock
+	Clock Clock
+}
+
+// SpeakingPayload EventAssistantSpeakingStart/End 的 Payload
+type SpeakingPayload struct {
+	// AudioMs 本次说话输出的音频时长（毫秒），说话开始时为 0
+	AudioMs int
+	// Reason 说话结束的原因："finished" 或 "interrupted"，说话开始时为空
+	Reason string
+}
+
+// SpeakingTracker 助手说话状态跟踪器
+type SpeakingTracker struct {
+	bus    Bus
+	config SpeakingConfig
+
+	mu       sync.Mutex
+	speaking bool
+	playEnd  time.Time     // 已输出音频预计播放结束的时刻
+	audio    time.Duration // 本次说话已输出的音频时长
+
+	wake        chan struct{}
+	interruptCh chan Event
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewSpeakingTracker 创建说话状态跟踪器
+func NewSpeakingTracker(bus Bus, config SpeakingConfig) *SpeakingTracker {
+	if config.Hangover <= 0 {
+		config.Hangover = defaultSpeakingHangover
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &SpeakingTracker{
+		bus:         bus,
+		config:      config,
+		wake:        make(chan struct{}, 1),
+		interruptCh: make(chan Event, 10),
+	}
+}
+
+// Start 开始跟踪
+func (t *SpeakingTracker) Start(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.bus.Subscribe(EventInterrupted, t.interruptCh)
+
+	t.wg.Add(1)
+	go t.run(ctx)
+	return nil
+}
+
+// Stop 停止跟踪，正在说话时不再发布结束事件
+func (t *SpeakingTracker) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+		t.wg.Wait()
+		t.cancel = nil
+	}
+	t.bus.Unsubscribe(EventInterrupted, t.interruptCh)
+	return nil
+}
+
+// Observe 记录一段已发往客户端的助手音频
+func (t *SpeakingTracker) Observe(data *AudioData) {
+	d := audioDuration(data)
+	if d <= 0 {
+		return
+	}
+
+	now := t.config.Clock.Now()
+	t.mu.Lock()
+	started := !t.speaking
+	if started || t.playEnd.Before(now) {
+		t.playEnd = now
+	}
+	t.playEnd = t.playEnd.Add(d)
+	if started {
+		t.speaking = true
+		t.audio = 0
+	}
+	t.audio += d
+	t.mu.Unlock()
+
+	if started {
+		t.publish(EventAssistantSpeakingStart, SpeakingPayload{})
+		select {
+		case t.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Speaking 报告助手当前是否在说话
+func (t *SpeakingTracker) Speaking() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.speaking
+}
+
+func (t *SpeakingTracker) run(ctx context.Context) {
+	defer t.wg.Done()
+
+	for {
+		t.mu.Lock()
+		var timeout <-chan time.Time
+		if t.speaking {
+			timeout = t.config.Clock.After(t.playEnd.Add(t.config.Hangover).Sub(t.config.Clock.Now()))
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.wake:
+		case <-t.interruptCh:
+			t.end("interrupted", false)
+		case <-timeout:
+			// 期间可能有新音频延长了播放时间，end 会重新检查
+			t.end("finished", true)
+		}
+	}
+}
+
+// end 结束说话并发布事件；onlyIfDone 时仅在播放和 Hangover 都已结束时生效
+func (t *SpeakingTracker) end(reason string, onlyIfDone bool) {
+	t.mu.Lock()
+	if !t.speaking || (onlyIfDone && t.config.Clock.Now().Before(t.playEnd.Add(t.config.Hangover))) {
+		t.mu.Unlock()
+		return
+	}
+	t.speaking = false
+	audioMs := int(t.audio.Milliseconds())
+	t.mu.Unlock()
+
+	t.publish(EventAssistantSpeakingEnd, SpeakingPayload{AudioMs: audioMs, Reason: reason})
+}
+
+func (t *SpeakingTracker) publish(eventType EventType, payload SpeakingPayload) {
+	t.bus.Publish(Event{
+		Type:      eventType,
+		Timestamp: t.config.Clock.Now(),
+		Payload:   payload,
+	})
+}
+
+// audioDuration 估算一段音频的播放时长
+func audioDuration(data *AudioData) time.Duration {
+	if data == nil || len(data.Data) == 0 {
+		return 0
+	}
+
+	var bytesPerSample int
+	switch data.MediaType {
+	case "", AudioMediaTypeRaw, AudioMediaTypePCM:
+		bytesPerSample = data.Format().BytesPerSample()
+	case AudioMediaTypeMuLaw, AudioMediaTypeALaw:
+		bytesPerSample = 1
+	}
+	if bytesPerSample == 0 || data.SampleRate <= 0 {
+		return encodedFrameDuration
+	}
+
+	channels := data.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	frames := len(data.Data) / (bytesPerSample * channels)
+	return time.Duration(frames) * time.Second / time.Duration(data.SampleRate)
+}
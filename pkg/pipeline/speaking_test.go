@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// pcmChunk 生成 16kHz 单声道 s16 的 ms 毫秒静音
+func pcmChunk(ms int) *AudioData {
+	return &AudioData{
+		Data:       make([]byte, 16000*2*ms/1000),
+		SampleRate: 16000,
+		Channels:   1,
+		MediaType:  AudioMediaTypeRaw,
+	}
+}
+
+func expectSpeakingEvent(t *testing.T, events chan Event, want EventType) SpeakingPayload {
+	t.Helper()
+	select {
+	case evt := <-events:
+		if evt.Type != want {
+			t.Fatalf("Expected %s, got %s", want, evt.Type)
+		}
+		return evt.Payload.(SpeakingPayload)
+	case <-time.After(time.Second):
+		t.Fatalf("Timeout waiting for %s", want)
+	}
+	return SpeakingPayload{}
+}
+
+func TestSpeakingTracker(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	bus := NewEventBus()
+	events := make(chan Event, 10)
+	bus.Subscribe(EventAssistantSpeakingStart, events)
+	bus.Subscribe(EventAssistantSpeakingEnd, events)
+
+	tracker := NewSpeakingTracker(bus, SpeakingConfig{Hangover: 300 * time.Millisecond, Clock: clock})
+	if err := tracker.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer tracker.Stop()
+
+	// 一次突发输出 400ms 音频，按播放时长而不是到达时间计算
+	tracker.Observe(pcmChunk(200))
+	tracker.Observe(pcmChunk(200))
+	expectSpeakingEvent(t, events, EventAssistantSpeakingStart)
+	if !tracker.Speaking() {
+		t.Fatal("Expected tracker to report speaking")
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(500 * time.Millisecond)
+	clock.BlockUntil(1)
+	if len(events) != 0 {
+		t.Fatalf("Expected speaking to continue until playback and hangover end, got %v", (<-events).Type)
+	}
+
+	clock.Advance(200 * time.Millisecond)
+	end := expectSpeakingEvent(t, events, EventAssistantSpeakingEnd)
+	if end.Reason != "finished" || end.AudioMs != 400 {
+		t.Errorf("Unexpected end payload: %+v", end)
+	}
+
+	// 打断时立即结束
+	tracker.Observe(pcmChunk(1000))
+	expectSpeakingEvent(t, events, EventAssistantSpeakingStart)
+	bus.Publish(Event{Type: EventInterrupted})
+	end = expectSpeakingEvent(t, events, EventAssistantSpeakingEnd)
+	if end.Reason != "interrupted" || end.AudioMs != 1000 {
+		t.Errorf("Unexpected end payload: %+v", end)
+	}
+	if tracker.Speaking() {
+		t.Error("Expected tracker to report idle after interrupt")
+	}
+}
+
+func TestPipelinePullObservesSpeaking(t *testing.T) {
+	p := NewPipeline("test")
+	elem := NewMockElement()
+	p.AddElement(elem)
+	tracker := p.EnableSpeakingTracker(SpeakingConfig{Clock: NewManualClock(time.Unix(0, 0))})
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+
+	elem.OutChan <- &PipelineMessage{Type: MsgTypeData}
+	p.Pull()
+	if tracker.Speaking() {
+		t.Fatal("Expected non-audio output not to count as speaking")
+	}
+
+	elem.OutChan <- &PipelineMessage{Type: MsgTypeAudio, AudioData: pcmChunk(20)}
+	p.Pull()
+	if !tracker.Speaking() {
+		t.Error("Expected pulled audio to start speaking")
+	}
+}
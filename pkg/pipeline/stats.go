@@ -0,0 +1,211 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// Pipeline.Stats 提供各元素的消息计数、排队深度、丢弃数和平均处理耗时，
+// 用于定位哪个元素成了瓶颈（队列持续增长、耗时变长）或在丢数据。
+//
+// 主要功能:
+//   - 计数由 Pipeline 在 Push、Link 转发、Pull 时自动更新，元素无需改动
+//   - 元素自行丢弃消息时调用 RecordDropped
+//   - 快照可直接编码为 JSON，或定期导出到 Prometheus 等监控系统
+//
+// 使用示例:
+//
+//	stats := p.Stats()
+//	if tts, ok := stats.Element("tts"); ok && tts.QueueDepth > 50 {
+//	    log.Printf("tts backlog: %d, avg %v", tts.QueueDepth, tts.AvgProcessing)
+//	}
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// statsPendingLimit 最多记录多少条尚未产生输出的输入到达时间
+	statsPendingLimit = 256
+	// statsAlpha 处理耗时指数移动平均的权重
+	statsAlpha = 0.1
+)
+
+// ElementStats 单个元素的运行统计，见 Pipeline.Stats
+type ElementStats struct {
+	Name        string `json:"name"`
+	MessagesIn  int64  `json:"messages_in"`  // 送入元素的消息数
+	MessagesOut int64  `json:"messages_out"` // 元素输出并被取走的消息数
+	BytesIn     int64  `json:"bytes_in"`     // 送入消息的数据字节数（音频、视频、文本、图像）
+	Dropped     int64  `json:"dropped"`      // 因通道已满等原因丢弃的消息数
+	QueueDepth  int    `json:"queue_depth"`  // 当前 InChan 中排队的消息数
+
+	// AvgProcessing 处理耗时的指数移动平均：按先后顺序把输入和输出配对，
+	// 取输入到达到对应输出被取走的时间。对每条输入产生一条输出的元素最准确，
+	// 聚合（如 STT）或拆分（如 TTS 分段）消息的元素只能作为参考
+	AvgProcessing time.Duration `json:"avg_processing"`
+}
+
+// PipelineStats Pipeline 的运行统计快照
+type PipelineStats struct {
+	Name      string         `json:"name"`
+	Timestamp time.Time      `json:"timestamp"`
+	Elements  []ElementStats `json:"elements"` // 按添加顺序
+}
+
+// Element 返回名为 name 的元素的统计
+func (s PipelineStats) Element(name string) (ElementStats, bool) {
+	for _, e := range s.Elements {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return ElementStats{}, false
+}
+
+// elementStats BaseElement 内的计数器，由 Pipeline 在消息进出元素时更新
+type elementStats struct {
+	mu          sync.Mutex
+	messagesIn  int64
+	messagesOut int64
+	bytesIn     int64
+	dropped     int64
+	avg         time.Duration
+	pending     []time.Time // 尚未产生输出的输入到达时间
+}
+
+// statsRecorder 由嵌入 BaseElement 的元素满足
+type statsRecorder interface {
+	recordIn(msg *PipelineMessage)
+	recordOut()
+	RecordDropped()
+	snapshotStats() ElementStats
+}
+
+// recordIn 记录一条送入元素的消息
+func (b *BaseElement) recordIn(msg *PipelineMessage) {
+	s := &b.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messagesIn++
+	s.bytesIn += int64(messageBytes(msg))
+	if len(s.pending) >= statsPendingLimit {
+		s.pending = s.pending[1:]
+	}
+	s.pending = append(s.pending, time.Now())
+}
+
+// recordOut 记录一条被取走的输出消息，与最早的未配对输入计算处理耗时
+func (b *BaseElement) recordOut() {
+	s := &b.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messagesOut++
+	if len(s.pending) == 0 {
+		return
+	}
+	d := time.Since(s.pending[0])
+	s.pending = s.pending[1:]
+	if s.avg == 0 {
+		s.avg = d
+	} else {
+		s.avg += time.Duration(statsAlpha * float64(d-s.avg))
+	}
+}
+
+// resetPending 丢弃未配对的输入，元素清空缓冲（Flush）后它们不会再产生输出
+func (b *BaseElement) resetPending() {
+	b.stats.mu.Lock()
+	b.stats.pending = nil
+	b.stats.mu.Unlock()
+}
+
+// RecordDropped 记录一条被丢弃的消息，元素在输出通道已满等情况下丢弃消息时调用
+func (b *BaseElement) RecordDropped() {
+	b.stats.mu.Lock()
+	b.stats.dropped++
+	b.stats.mu.Unlock()
+}
+
+// snapshotStats 返回当前统计，QueueDepth 由调用方填写
+func (b *BaseElement) snapshotStats() ElementStats {
+	s := &b.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ElementStats{
+		Name:          b.name,
+		MessagesIn:    s.messagesIn,
+		MessagesOut:   s.messagesOut,
+		BytesIn:       s.bytesIn,
+		Dropped:       s.dropped,
+		AvgProcessing: s.avg,
+	}
+}
+
+// messageBytes 返回消息携带的数据字节数
+func messageBytes(msg *PipelineMessage) int {
+	if msg == nil {
+		return 0
+	}
+	n := 0
+	if msg.AudioData != nil {
+		n += len(msg.AudioData.Data)
+	}
+	if msg.VideoData != nil {
+		n += len(msg.VideoData.Data)
+	}
+	if msg.TextData != nil {
+		n += len(msg.TextData.Data)
+	}
+	if msg.ImageData != nil {
+		n += len(msg.ImageData.Data)
+	}
+	return n
+}
+
+// recordIn 记录送入 e 的消息，e 未嵌入 BaseElement 时忽略
+func recordIn(e Element, msg *PipelineMessage) {
+	if r, ok := e.(statsRecorder); ok {
+		r.recordIn(msg)
+	}
+}
+
+// recordOut 记录从 e 取走的消息
+func recordOut(e Element) {
+	if r, ok := e.(statsRecorder); ok {
+		r.recordOut()
+	}
+}
+
+// recordDropped 记录 e 丢弃的消息
+func recordDropped(e Element) {
+	if r, ok := e.(statsRecorder); ok {
+		r.RecordDropped()
+	}
+}
+
+// Stats 返回各元素的运行统计快照，可定期采集（如导出到 Prometheus）
+//
+// 消息计数在 Pipeline 传递消息时自动更新：Push / PushText 送入输入端、Link 在元素之间转发、
+// Pull 从输出端取出。绕过 Pipeline 直接读写元素通道的消息不计入
+func (p *Pipeline) Stats() PipelineStats {
+	p.Lock()
+	elements := append([]Element(nil), p.elements...)
+	p.Unlock()
+
+	stats := PipelineStats{
+		Name:      p.name,
+		Timestamp: time.Now(),
+		Elements:  make([]ElementStats, 0, len(elements)),
+	}
+	for _, e := range elements {
+		var es ElementStats
+		if r, ok := e.(statsRecorder); ok {
+			es = r.snapshotStats()
+		} else {
+			es.Name = e.GetName()
+		}
+		es.QueueDepth = len(e.In())
+		stats.Elements = append(stats.Elements, es)
+	}
+	return stats
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPipelineStats(t *testing.T) {
+	const n = 20
+
+	p := NewPipeline("stats")
+	first := newRelayElement("first", 0)
+	middle := newRelayElement("middle", 2*time.Millisecond)
+	last := newRelayElement("last", 0)
+	p.AddElements([]Element{first, middle, last})
+	p.Link(first, middle)
+	p.Link(middle, last)
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+
+	for i := 0; i < n; i++ {
+		p.Push(&PipelineMessage{
+			Type:      MsgTypeAudio,
+			AudioData: &AudioData{Data: make([]byte, 320)},
+		})
+	}
+	for i := 0; i < n; i++ {
+		if _, err := p.PullTimeout(time.Second); err != nil {
+			t.Fatalf("Pull %d failed: %v", i, err)
+		}
+	}
+
+	stats := p.Stats()
+	if stats.Name != "stats" || len(stats.Elements) != 3 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	for _, name := range []string{"first", "middle", "last"} {
+		es, ok := stats.Element(name)
+		if !ok {
+			t.Fatalf("No stats for %s", name)
+		}
+		if es.MessagesIn != n || es.MessagesOut != n {
+			t.Errorf("%s: expected %d in/out, got %d/%d", name, n, es.MessagesIn, es.MessagesOut)
+		}
+		if es.BytesIn != n*320 {
+			t.Errorf("%s: expected %d bytes, got %d", name, n*320, es.BytesIn)
+		}
+		if es.QueueDepth != 0 || es.Dropped != 0 {
+			t.Errorf("%s: expected empty queue and no drops, got %d/%d", name, es.QueueDepth, es.Dropped)
+		}
+	}
+	if middle, _ := stats.Element("middle"); middle.AvgProcessing < 2*time.Millisecond {
+		t.Errorf("Expected middle processing time of at least 2ms, got %v", middle.AvgProcessing)
+	}
+}
+
+func TestPipelineStatsDropped(t *testing.T) {
+	p := NewPipeline("stats")
+	elem := NewMockElement() // 不读取输入，InChan 容量为 10
+	p.AddElement(elem)
+
+	for i := 0; i < 15; i++ {
+		p.Push(&PipelineMessage{Type: MsgTypeData, TextData: &TextData{Data: []byte("hi")}})
+	}
+
+	es := p.Stats().Elements[0]
+	if es.MessagesIn != 10 || es.Dropped != 5 || es.QueueDepth != 10 || es.BytesIn != 20 {
+		t.Errorf("Unexpected stats: %+v", es)
+	}
+}
@@ -0,0 +1,193 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// Validate 校验 Pipeline 的拓扑。
+//
+// 检查 AddElement / Link 建立的连接图，把会导致 Start 挂起或数据堵塞的拓扑
+// 提前报告为错误，而不是运行后才表现为没有输出:
+//   - 环: 元素的输出经 Link 又回到自身，消息在环中循环，通道写满后整条链路卡住
+//   - 未连接的元素: 多个元素的 Pipeline 中既没有上游也没有下游的元素，不会收到任何消息
+//   - 输出无人消费: 标记了输出端（SetSink）时，其余元素的输出只能经 Link 被消费，
+//     没有下游的元素写满输出通道后阻塞
+//
+// 只有一个元素的 Pipeline 不需要连接，总是有效的。
+//
+// Start 总是检查环；未连接的元素和输出无人消费可能是有意为之（如只通过 Bus 工作、
+// 不需要 Link 的 SummarizerElement），只在调用 Validate 或 SetValidateOnStart(true) 时检查。
+// 设置了开场白（PlayOnStart）时，Start 还检查是否有播报元素（SetSpeechInput）。
+//
+// 使用示例:
+//
+//	if err := p.Validate(); errors.Is(err, pipeline.ErrUnlinkedElement) {
+//	    log.Fatal(err)
+//	}
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPipelineCycle 连接图中存在环
+	ErrPipelineCycle = errors.New("pipeline has a cycle")
+
+	// ErrUnlinkedElement 元素没有任何连接
+	ErrUnlinkedElement = errors.New("pipeline has unlinked elements")
+
+	// ErrNoConsumer 元素的输出没有下游消费
+	ErrNoConsumer = errors.New("pipeline has elements with no consumer")
+)
+
+// Validate 检查 Pipeline 的拓扑，在 Start 前调用
+// 返回的错误指明出问题的元素，可用 errors.Is 判断 ErrPipelineCycle、ErrUnlinkedElement、
+// ErrNoConsumer；存在多个问题时全部返回（errors.Join）
+func (p *Pipeline) Validate() error {
+	p.Lock()
+	defer p.Unlock()
+	return p.validateLocked(true)
+}
+
+// SetValidateOnStart 为 true 时 Start 先调用 Validate，拓扑有问题时拒绝启动
+// 默认只检查环和输入端/输出端标记
+func (p *Pipeline) SetValidateOnStart(enabled bool) {
+	p.Lock()
+	defer p.Unlock()
+	p.validateOnStart = enabled
+}
+
+// validateLocked 检查拓扑，strict 为 false 时只检查输入端/输出端标记、开场白和环，调用方需持有锁
+func (p *Pipeline) validateLocked(strict bool) error {
+	if err := p.checkEndpointsLocked(); err != nil {
+		return err
+	}
+	if p.greeting != "" && p.speechInput == nil {
+		return fmt.Errorf("pipeline %s has a greeting but no speech input element, call SetSpeechInput", p.name)
+	}
+
+	links := p.linksLocked()
+	errs := []error{p.checkCyclesLocked(links)}
+	if strict && len(p.elements) > 1 {
+		errs = append(errs, p.checkLinkedLocked(links), p.checkConsumersLocked(links))
+	}
+	return errors.Join(errs...)
+}
+
+// linksLocked 返回每个元素的下游，调用方需持有锁
+func (p *Pipeline) linksLocked() map[Element][]Element {
+	links := make(map[Element][]Element, len(p.fanouts))
+	for src, f := range p.fanouts {
+		f.mu.Lock()
+		for _, t := range f.targets {
+			links[src] = append(links[src], t.dst)
+		}
+		f.mu.Unlock()
+	}
+	return links
+}
+
+// checkCyclesLocked 深度优先查找环，返回的错误列出环上的元素，如 "a -> b -> a"
+func (p *Pipeline) checkCyclesLocked(links map[Element][]Element) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[Element]int)
+	var path []Element
+
+	var visit func(e Element) []Element
+	visit = func(e Element) []Element {
+		state[e] = visiting
+		path = append(path, e)
+		for _, next := range links[e] {
+			switch state[next] {
+			case visiting:
+				// 环从 next 第一次出现的位置开始
+				for i, v := range path {
+					if v == next {
+						return append(append([]Element(nil), path[i:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[e] = visited
+		return nil
+	}
+
+	// 按添加顺序遍历，错误信息稳定；Link 了未添加的元素也会被检查
+	roots := append([]Element(nil), p.elements...)
+	for src := range links {
+		roots = append(roots, src)
+	}
+	for _, e := range roots {
+		if state[e] != unvisited {
+			continue
+		}
+		if cycle := visit(e); cycle != nil {
+			return fmt.Errorf("%w: %s", ErrPipelineCycle, joinNames(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// checkLinkedLocked 查找既没有上游也没有下游的元素
+func (p *Pipeline) checkLinkedLocked(links map[Element][]Element) error {
+	linked := make(map[Element]bool)
+	for src, dsts := range links {
+		linked[src] = true
+		for _, dst := range dsts {
+			linked[dst] = true
+		}
+	}
+
+	var unlinked []Element
+	for _, e := range p.elements {
+		if !linked[e] {
+			unlinked = append(unlinked, e)
+		}
+	}
+	if len(unlinked) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnlinkedElement, joinNames(unlinked, ", "))
+}
+
+// checkConsumersLocked 标记了输出端时，查找输出没有下游的其他元素
+// 未标记输出端时最后一个元素的输出由 Pull 消费，其余元素可能是只消费不输出的旁路，不做检查
+func (p *Pipeline) checkConsumersLocked(links map[Element][]Element) error {
+	var sink Element
+	for _, e := range p.elements {
+		if e.IsSink() {
+			sink = e
+		}
+	}
+	if sink == nil {
+		return nil
+	}
+
+	var dangling []Element
+	for _, e := range p.elements {
+		if e != sink && len(links[e]) == 0 {
+			dangling = append(dangling, e)
+		}
+	}
+	if len(dangling) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (sink is %s)", ErrNoConsumer, joinNames(dangling, ", "), sink.GetName())
+}
+
+// joinNames 用 sep 连接元素名称
+func joinNames(elements []Element, sep string) string {
+	names := make([]string, len(elements))
+	for i, e := range elements {
+		names[i] = e.GetName()
+	}
+	return strings.Join(names, sep)
+}
@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newNamedMockElement(name string) *MockElement {
+	return &MockElement{BaseElement: NewBaseElement(name, 10)}
+}
+
+func TestPipelineValidateLinear(t *testing.T) {
+	p := NewPipeline("test")
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Empty pipeline should be valid, got %v", err)
+	}
+
+	// 单个元素不需要连接
+	resample := newNamedMockElement("resample")
+	p.AddElement(resample)
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Single element pipeline should be valid, got %v", err)
+	}
+
+	stt := newNamedMockElement("stt")
+	chat := newNamedMockElement("chat")
+	tts := newNamedMockElement("tts")
+	p.AddElements([]Element{stt, chat, tts})
+	p.Link(resample, stt)
+	p.Link(stt, chat)
+	p.Link(chat, tts)
+	tts.SetSink(true)
+
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Linear pipeline should be valid, got %v", err)
+	}
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	p.Stop()
+}
+
+func TestPipelineValidateCycle(t *testing.T) {
+	p := NewPipeline("test")
+	input := newNamedMockElement("input")
+	a := newNamedMockElement("a")
+	b := newNamedMockElement("b")
+	c := newNamedMockElement("c")
+	p.AddElements([]Element{input, a, b, c})
+	p.Link(input, a)
+	p.Link(a, b)
+	p.Link(b, c)
+	p.Link(c, a)
+
+	err := p.Validate()
+	if !errors.Is(err, ErrPipelineCycle) {
+		t.Fatalf("Expected ErrPipelineCycle, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("Error should name the elements of the cycle, got %v", err)
+	}
+
+	// Start 拒绝启动，而不是挂起
+	if err := p.Start(context.Background()); !errors.Is(err, ErrPipelineCycle) {
+		t.Errorf("Expected Start to fail with ErrPipelineCycle, got %v", err)
+	}
+
+	// 元素连接到自身
+	p = NewPipeline("test")
+	loop := newNamedMockElement("loop")
+	p.AddElement(loop)
+	p.Link(loop, loop)
+	if err := p.Validate(); !errors.Is(err, ErrPipelineCycle) || !strings.Contains(err.Error(), "loop -> loop") {
+		t.Errorf("Expected a self-link cycle error, got %v", err)
+	}
+}
+
+func TestPipelineValidateDangling(t *testing.T) {
+	p := NewPipeline("test")
+	stt := newNamedMockElement("stt")
+	chat := newNamedMockElement("chat")
+	recorder := newNamedMockElement("recorder")
+	p.AddElements([]Element{stt, chat, recorder})
+	p.Link(stt, chat)
+
+	err := p.Validate()
+	if !errors.Is(err, ErrUnlinkedElement) {
+		t.Fatalf("Expected ErrUnlinkedElement, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "recorder") || strings.Contains(err.Error(), "chat") {
+		t.Errorf("Error should name only the unlinked element, got %v", err)
+	}
+
+	// 默认 Start 不检查未连接的元素（如只通过 Bus 工作的元素），开启后拒绝启动
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Expected Start without strict validation to succeed, got %v", err)
+	}
+	p.Stop()
+	p.SetValidateOnStart(true)
+	if err := p.Start(context.Background()); !errors.Is(err, ErrUnlinkedElement) {
+		t.Errorf("Expected Start to fail with ErrUnlinkedElement, got %v", err)
+	}
+
+	// 标记了输出端时，其他元素的输出必须有下游
+	p.Link(stt, recorder)
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected a valid pipeline without a marked sink, got %v", err)
+	}
+	chat.SetSink(true)
+	err = p.Validate()
+	if !errors.Is(err, ErrNoConsumer) {
+		t.Fatalf("Expected ErrNoConsumer, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "recorder") || !strings.Contains(err.Error(), "sink is chat") {
+		t.Errorf("Error should name the element without a consumer and the sink, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package pipeline provides the core pipeline processing framework.
+//
+// Watchdog 检测会话中途"连接还在、数据却不再流动"的静默故障
+// （如 STT 不再输出、LLM 挂起、某个元素卡死），发布 EventPipelineStalled 并可触发恢复。
+//
+// 工作原理:
+//   - Push、元素之间的每次消息传递以及 Pull 都记为一次活动
+//   - 超过 Timeout 没有任何活动时认为 Pipeline 停滞，每次停滞只报告一次
+//   - 之后再有活动时发布 EventPipelineRecovered 并重新开始计时
+//   - 输入静音（MuteInput）期间暂停检测，取消静音后重新计时
+//
+// 使用示例:
+//
+//	p.EnableWatchdog(pipeline.WatchdogConfig{
+//		Timeout: 30 * time.Second,
+//		OnStall: func(s pipeline.StalledPayload) { restartSession() },
+//	})
+package pipeline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultWatchdogTimeout 默认的停滞判定时长
+const defaultWatchdogTimeout = 30 * time.Second
+
+// WatchdogConfig 停滞检测配置
+type WatchdogConfig struct {
+	// Timeout 多久没有消息流动视为停滞，默认 30s
+	// 应大于正常对话中可能出现的最长静默（如 VAD 过滤模式下用户长时间不说话）
+	Timeout time.Duration
+
+	// OnStall 停滞时调用的恢复回调（可选），在独立的 goroutine 中执行，
+	// 可以直接重建会话或调用 Pipeline.Stop
+	OnStall func(StalledPayload)
+
+	// Clock 时间源，默认 SystemClock
+	Clock Clock
+}
+
+// StalledPayload EventPipelineStalled 和 EventPipelineRecovered 的 Payload
+type StalledPayload struct {
+	Pipeline string        // Pipeline 名称
+	Idle     time.Duration // 已经多久没有消息流动
+	LastStep string        // 最后一次活动的位置："push"、"pull" 或发出消息的元素名
+}
+
+// Watchdog Pipeline 停滞检测器
+type Watchdog struct {
+	bus    Bus
+	name   string
+	config WatchdogConfig
+
+	mu        sync.Mutex
+	last      time.Time // 最后一次活动的时刻
+	lastStep  string
+	stalled   bool
+	suspended bool
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWatchdog 创建停滞检测器，name 为被监视的 Pipeline 名称
+func NewWatchdog(bus Bus, name string, config WatchdogConfig) *Watchdog {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultWatchdogTimeout
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &Watchdog{
+		bus:    bus,
+		name:   name,
+		config: config,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start 开始检测，从此刻开始计时
+func (w *Watchdog) Start(ctx context.Context) error {
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	w.mu.Lock()
+	w.last = w.config.Clock.Now()
+	w.lastStep = ""
+	w.stalled = false
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run(ctx)
+	return nil
+}
+
+// Stop 停止检测
+func (w *Watchdog) Stop() error {
+	if w.cancel != nil {
+		w.cancel()
+		w.wg.Wait()
+		w.cancel = nil
+	}
+	return nil
+}
+
+// Touch 记录一次消息流动，step 为发生的位置
+func (w *Watchdog) Touch(step string) {
+	now := w.config.Clock.Now()
+	w.mu.Lock()
+	w.last = now
+	w.lastStep = step
+	recovered := w.stalled
+	w.stalled = false
+	w.mu.Unlock()
+
+	if recovered {
+		log.Printf("[Watchdog] Pipeline %s recovered at %s", w.name, step)
+		w.publish(EventPipelineRecovered, StalledPayload{Pipeline: w.name, LastStep: step})
+		w.signal()
+	}
+}
+
+// Stalled 报告 Pipeline 当前是否处于停滞状态
+func (w *Watchdog) Stalled() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stalled
+}
+
+// setSuspended 暂停或恢复检测，恢复时重新计时
+func (w *Watchdog) setSuspended(suspended bool) {
+	w.mu.Lock()
+	w.suspended = suspended
+	w.last = w.config.Clock.Now()
+	w.mu.Unlock()
+	w.signal()
+}
+
+func (w *Watchdog) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Watchdog) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		w.mu.Lock()
+		var timeout <-chan time.Time
+		if !w.stalled && !w.suspended {
+			timeout = w.config.Clock.After(w.last.Add(w.config.Timeout).Sub(w.config.Clock.Now()))
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.wake:
+		case <-timeout:
+			// 期间可能有新的活动，check 会重新判断
+			w.check()
+		}
+	}
+}
+
+// check 在超过 Timeout 没有活动时报告停滞
+func (w *Watchdog) check() {
+	now := w.config.Clock.Now()
+	w.mu.Lock()
+	idle := now.Sub(w.last)
+	if w.stalled || w.suspended || idle < w.config.Timeout {
+		w.mu.Unlock()
+		return
+	}
+	w.stalled = true
+	payload := StalledPayload{Pipeline: w.name, Idle: idle, LastStep: w.lastStep}
+	w.mu.Unlock()
+
+	log.Printf("[Watchdog] Pipeline %s stalled: no messages for %v (last at %q)", w.name, idle, payload.LastStep)
+	w.publish(EventPipelineStalled, payload)
+	if w.config.OnStall != nil {
+		go w.config.OnStall(payload)
+	}
+}
+
+func (w *Watchdog) publish(eventType EventType, payload StalledPayload) {
+	w.bus.Publish(Event{
+		Type:      eventType,
+		Timestamp: w.config.Clock.Now(),
+		Payload:   payload,
+	})
+}
@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func expectWatchdogEvent(t *testing.T, events chan Event, want EventType) StalledPayload {
+	t.Helper()
+	select {
+	case evt := <-events:
+		if evt.Type != want {
+			t.Fatalf("Expected %s, got %s", want, evt.Type)
+		}
+		return evt.Payload.(StalledPayload)
+	case <-time.After(time.Second):
+		t.Fatalf("Timeout waiting for %s", want)
+	}
+	return StalledPayload{}
+}
+
+func TestWatchdog(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	bus := NewEventBus()
+	events := make(chan Event, 10)
+	bus.Subscribe(EventPipelineStalled, events)
+	bus.Subscribe(EventPipelineRecovered, events)
+
+	stalls := make(chan StalledPayload, 1)
+	w := NewWatchdog(bus, "test", WatchdogConfig{
+		Timeout: 10 * time.Second,
+		Clock:   clock,
+		OnStall: func(s StalledPayload) { stalls <- s },
+	})
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	// 活动推迟停滞判定
+	clock.BlockUntil(1)
+	clock.Advance(8 * time.Second)
+	w.Touch("stt")
+	clock.BlockUntil(1)
+	clock.Advance(8 * time.Second)
+	clock.BlockUntil(1)
+	if len(events) != 0 {
+		t.Fatalf("Expected no stall while messages flow, got %v", (<-events).Type)
+	}
+
+	clock.Advance(2 * time.Second)
+	stalled := expectWatchdogEvent(t, events, EventPipelineStalled)
+	if stalled.Pipeline != "test" || stalled.Idle != 10*time.Second || stalled.LastStep != "stt" {
+		t.Errorf("Unexpected stall payload: %+v", stalled)
+	}
+	select {
+	case <-stalls:
+	case <-time.After(time.Second):
+		t.Fatal("Expected OnStall to be called")
+	}
+	if !w.Stalled() {
+		t.Fatal("Expected watchdog to report stalled")
+	}
+
+	// 每次停滞只报告一次
+	clock.Advance(time.Minute)
+	if len(events) != 0 {
+		t.Fatal("Expected a stall to be reported only once")
+	}
+
+	w.Touch("llm")
+	recovered := expectWatchdogEvent(t, events, EventPipelineRecovered)
+	if recovered.LastStep != "llm" || w.Stalled() {
+		t.Errorf("Expected recovery at llm, got %+v", recovered)
+	}
+
+	// 暂停期间不检测
+	clock.BlockUntil(1)
+	w.setSuspended(true)
+	clock.Advance(time.Minute)
+	if len(events) != 0 {
+		t.Fatalf("Expected no stall while suspended, got %v", (<-events).Type)
+	}
+	w.setSuspended(false)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectWatchdogEvent(t, events, EventPipelineStalled)
+}
+
+func TestPipelineWatchdogTracksFlow(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	p := NewPipeline("test")
+	a, b := NewMockElement(), NewMockElement()
+	p.AddElements([]Element{a, b})
+	w := p.EnableWatchdog(WatchdogConfig{Timeout: 10 * time.Second, Clock: clock})
+	if p.EnableWatchdog(WatchdogConfig{}) != w {
+		t.Fatal("Expected EnableWatchdog to return the existing watchdog")
+	}
+
+	events := make(chan Event, 10)
+	p.Bus().Subscribe(EventPipelineStalled, events)
+	p.Bus().Subscribe(EventPipelineRecovered, events)
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+	unlink := p.Link(a, b)
+	defer unlink()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectWatchdogEvent(t, events, EventPipelineStalled)
+
+	// 元素之间的消息传递记为活动
+	a.OutChan <- &PipelineMessage{Type: MsgTypeData}
+	if got := expectWatchdogEvent(t, events, EventPipelineRecovered); got.LastStep != a.GetName() {
+		t.Errorf("Expected recovery at %s, got %q", a.GetName(), got.LastStep)
+	}
+}
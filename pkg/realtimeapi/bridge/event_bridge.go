@@ -4,6 +4,7 @@ package bridge
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -29,12 +30,57 @@ type AudioSink interface {
 	SupportsRTPAudio() bool
 }
 
+// Config selects which optional pipeline events the EventBridge translates.
+// The zero value only bridges VAD, interruption and response events.
+type Config struct {
+	// InputTranscription sends conversation.item.input_audio_transcription.completed
+	// for each EventFinalResult transcript of the user's speech.
+	InputTranscription bool
+
+	// CommitOnSpeechEnd sends input_audio_buffer.committed and the user
+	// conversation.item.created after speech_stopped, as OpenAI server VAD does.
+	CommitOnSpeechEnd bool
+
+	// AudioTranscript sends the text of audio responses as
+	// response.audio_transcript.* instead of response.text.* events.
+	AudioTranscript bool
+
+	// ToolCalls sends a function_call response for each EventToolCall.
+	ToolCalls bool
+
+	// Errors sends an error event for each EventError.
+	Errors bool
+
+	// Speaking sends assistant.speaking.started/stopped for each
+	// EventAssistantSpeakingStart/End, so a UI can animate an avatar.
+	// Requires Pipeline.EnableSpeakingTracker.
+	Speaking bool
+}
+
+// OpenAIConfig enables every translation so that a client written against the
+// OpenAI Realtime event schema receives the event sequence it expects.
+func OpenAIConfig() Config {
+	return Config{
+		InputTranscription: true,
+		CommitOnSpeechEnd:  true,
+		AudioTranscript:    true,
+		ToolCalls:          true,
+		Errors:             true,
+		Speaking:           true,
+	}
+}
+
 // EventBridge bridges Pipeline Bus events to WebSocket server events.
 type EventBridge struct {
 	bus       pipeline.Bus
 	sender    EventSender
 	tracker   *state.ResponseTracker
 	sessionID string
+	config    Config
+
+	// Input item of the current user turn and the last committed item
+	inputItemID string
+	lastItemID  string
 
 	// AudioSink for RTP-based audio output (WebRTC mode)
 	audioSink AudioSink
@@ -47,6 +93,10 @@ type EventBridge struct {
 	responseEndCh   chan pipeline.Event
 	audioDeltaCh    chan pipeline.Event
 	textDeltaCh     chan pipeline.Event
+	finalResultCh   chan pipeline.Event
+	toolCallCh      chan pipeline.Event
+	errorCh         chan pipeline.Event
+	speakingCh      chan pipeline.Event
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -67,9 +117,20 @@ func NewEventBridge(bus pipeline.Bus, sender EventSender, sessionID string) *Eve
 		responseEndCh:   make(chan pipeline.Event, 10),
 		audioDeltaCh:    make(chan pipeline.Event, 100),
 		textDeltaCh:     make(chan pipeline.Event, 100),
+		finalResultCh:   make(chan pipeline.Event, 10),
+		toolCallCh:      make(chan pipeline.Event, 10),
+		errorCh:         make(chan pipeline.Event, 10),
+		speakingCh:      make(chan pipeline.Event, 10),
 	}
 }
 
+// NewEventBridgeWithConfig creates a new EventBridge with the given translation config.
+func NewEventBridgeWithConfig(bus pipeline.Bus, sender EventSender, sessionID string, config Config) *EventBridge {
+	eb := NewEventBridge(bus, sender, sessionID)
+	eb.config = config
+	return eb
+}
+
 // NewEventBridgeWithAudioSink creates a new EventBridge with an AudioSink for RTP audio output.
 // Use this for WebRTC mode where audio should be sent via RTP instead of base64-encoded events.
 func NewEventBridgeWithAudioSink(bus pipeline.Bus, sender EventSender, sessionID string, audioSink AudioSink) *EventBridge {
@@ -83,6 +144,11 @@ func (eb *EventBridge) SetAudioSink(sink AudioSink) {
 	eb.audioSink = sink
 }
 
+// SetConfig sets the translation config. It must be called before Start.
+func (eb *EventBridge) SetConfig(config Config) {
+	eb.config = config
+}
+
 // Start starts the event bridge.
 func (eb *EventBridge) Start(ctx context.Context) error {
 	eb.ctx, eb.cancel = context.WithCancel(ctx)
@@ -95,6 +161,19 @@ func (eb *EventBridge) Start(ctx context.Context) error {
 	eb.bus.Subscribe(pipeline.EventResponseEnd, eb.responseEndCh)
 	eb.bus.Subscribe(pipeline.EventAudioDelta, eb.audioDeltaCh)
 	eb.bus.Subscribe(pipeline.EventTextDelta, eb.textDeltaCh)
+	if eb.config.InputTranscription {
+		eb.bus.Subscribe(pipeline.EventFinalResult, eb.finalResultCh)
+	}
+	if eb.config.ToolCalls {
+		eb.bus.Subscribe(pipeline.EventToolCall, eb.toolCallCh)
+	}
+	if eb.config.Errors {
+		eb.bus.Subscribe(pipeline.EventError, eb.errorCh)
+	}
+	if eb.config.Speaking {
+		eb.bus.Subscribe(pipeline.EventAssistantSpeakingStart, eb.speakingCh)
+		eb.bus.Subscribe(pipeline.EventAssistantSpeakingEnd, eb.speakingCh)
+	}
 
 	// Start event handlers
 	eb.wg.Add(1)
@@ -117,6 +196,19 @@ func (eb *EventBridge) Stop() {
 	eb.bus.Unsubscribe(pipeline.EventResponseEnd, eb.responseEndCh)
 	eb.bus.Unsubscribe(pipeline.EventAudioDelta, eb.audioDeltaCh)
 	eb.bus.Unsubscribe(pipeline.EventTextDelta, eb.textDeltaCh)
+	if eb.config.InputTranscription {
+		eb.bus.Unsubscribe(pipeline.EventFinalResult, eb.finalResultCh)
+	}
+	if eb.config.ToolCalls {
+		eb.bus.Unsubscribe(pipeline.EventToolCall, eb.toolCallCh)
+	}
+	if eb.config.Errors {
+		eb.bus.Unsubscribe(pipeline.EventError, eb.errorCh)
+	}
+	if eb.config.Speaking {
+		eb.bus.Unsubscribe(pipeline.EventAssistantSpeakingStart, eb.speakingCh)
+		eb.bus.Unsubscribe(pipeline.EventAssistantSpeakingEnd, eb.speakingCh)
+	}
 
 	eb.wg.Wait()
 }
@@ -155,6 +247,18 @@ func (eb *EventBridge) handleEvents() {
 
 		case evt := <-eb.textDeltaCh:
 			eb.handleTextDelta(evt)
+
+		case evt := <-eb.finalResultCh:
+			eb.handleFinalResult(evt)
+
+		case evt := <-eb.toolCallCh:
+			eb.handleToolCall(evt)
+
+		case evt := <-eb.errorCh:
+			eb.handleError(evt)
+
+		case evt := <-eb.speakingCh:
+			eb.handleSpeaking(evt)
 		}
 	}
 }
@@ -171,6 +275,7 @@ func (eb *EventBridge) handleVADSpeechStart(evt pipeline.Event) {
 	if itemID == "" {
 		itemID = "item_" + uuid.New().String()[:8]
 	}
+	eb.inputItemID = itemID
 
 	eb.sender.SendEvent(events.NewInputAudioBufferSpeechStartedEvent(payload.AudioMs, itemID))
 }
@@ -183,12 +288,128 @@ func (eb *EventBridge) handleVADSpeechEnd(evt pipeline.Event) {
 		return
 	}
 
+	// Reuse the item ID from speech_started so clients can correlate the turn
 	itemID := payload.ItemID
+	if itemID == "" {
+		itemID = eb.inputItemID
+	}
 	if itemID == "" {
 		itemID = "item_" + uuid.New().String()[:8]
 	}
+	eb.inputItemID = itemID
 
 	eb.sender.SendEvent(events.NewInputAudioBufferSpeechStoppedEvent(payload.AudioMs, itemID))
+
+	if eb.config.CommitOnSpeechEnd {
+		eb.sender.SendEvent(events.NewInputAudioBufferCommittedEvent(itemID, eb.lastItemID))
+		eb.sender.SendEvent(events.NewConversationItemCreatedEvent(events.ConversationItem{
+			ID:      itemID,
+			Object:  "realtime.item",
+			Type:    events.ItemTypeMessage,
+			Status:  events.ItemStatusCompleted,
+			Role:    events.RoleUser,
+			Content: []events.Content{{Type: events.ContentTypeInputAudio}},
+		}, eb.lastItemID))
+		eb.lastItemID = itemID
+	}
+}
+
+// handleFinalResult handles final STT transcripts of the user's speech.
+func (eb *EventBridge) handleFinalResult(evt pipeline.Event) {
+	transcript, ok := evt.Payload.(string)
+	if !ok {
+		log.Printf("[EventBridge] invalid FinalResult payload")
+		return
+	}
+	if transcript == "" {
+		return
+	}
+
+	itemID := eb.inputItemID
+	if itemID == "" {
+		itemID = "item_" + uuid.New().String()[:8]
+	}
+	// The next utterance gets a new item
+	eb.inputItemID = ""
+
+	eb.sender.SendEvent(events.NewConversationItemInputAudioTranscriptionCompletedEvent(itemID, 0, transcript))
+}
+
+// handleToolCall sends a function call as its own response, like OpenAI does
+// when the model decides to call a tool.
+func (eb *EventBridge) handleToolCall(evt pipeline.Event) {
+	payload, ok := evt.Payload.(*pipeline.ToolCallPayload)
+	if !ok {
+		log.Printf("[EventBridge] invalid ToolCall payload")
+		return
+	}
+
+	if eb.tracker.HasActiveResponse() {
+		eb.completeCurrentResponse(events.ResponseStatusCompleted)
+	}
+
+	responseID := "resp_" + uuid.New().String()[:8]
+	item := events.ConversationItem{
+		ID:        "item_" + uuid.New().String()[:8],
+		Object:    "realtime.item",
+		Type:      events.ItemTypeFunctionCall,
+		Status:    events.ItemStatusInProgress,
+		CallID:    payload.CallID,
+		Name:      payload.Name,
+		Arguments: payload.Arguments,
+	}
+
+	eb.sender.SendEvent(events.NewResponseCreatedEvent(events.Response{
+		ID:     responseID,
+		Object: "realtime.response",
+		Status: events.ResponseStatusInProgress,
+		Output: []events.ConversationItem{},
+	}))
+	eb.sender.SendEvent(events.NewResponseOutputItemAddedEvent(responseID, 0, item))
+	eb.sender.SendEvent(events.NewResponseFunctionCallArgumentsDoneEvent(
+		responseID,
+		item.ID,
+		0,
+		payload.CallID,
+		payload.Arguments,
+	))
+
+	item.Status = events.ItemStatusCompleted
+	eb.sender.SendEvent(events.NewResponseOutputItemDoneEvent(responseID, 0, item))
+	eb.sender.SendEvent(events.NewResponseDoneEvent(events.Response{
+		ID:     responseID,
+		Object: "realtime.response",
+		Status: events.ResponseStatusCompleted,
+		Output: []events.ConversationItem{item},
+	}))
+}
+
+// handleError forwards pipeline errors to the client.
+func (eb *EventBridge) handleError(evt pipeline.Event) {
+	var message string
+	switch p := evt.Payload.(type) {
+	case string:
+		message = p
+	case error:
+		message = p.Error()
+	case map[string]interface{}:
+		message = fmt.Sprint(p["error"])
+	default:
+		message = fmt.Sprint(p)
+	}
+
+	eb.sender.SendEvent(events.NewErrorEvent(events.ErrorTypeServer, "pipeline_error", message, ""))
+}
+
+// handleSpeaking forwards the assistant speaking state to the client.
+func (eb *EventBridge) handleSpeaking(evt pipeline.Event) {
+	if evt.Type == pipeline.EventAssistantSpeakingStart {
+		eb.sender.SendEvent(events.NewAssistantSpeakingStartedEvent())
+		return
+	}
+
+	payload, _ := evt.Payload.(pipeline.SpeakingPayload)
+	eb.sender.SendEvent(events.NewAssistantSpeakingStoppedEvent(payload.AudioMs, payload.Reason))
 }
 
 // handleInterrupted handles interruption events.
@@ -240,6 +461,8 @@ func (eb *EventBridge) handleInterrupted(evt pipeline.Event) {
 		itemID = "item_" + uuid.New().String()[:8]
 	}
 
+	eb.inputItemID = itemID
+
 	// Send speech started event to indicate user is now speaking
 	eb.sender.SendEvent(events.NewInputAudioBufferSpeechStartedEvent(audioMs, itemID))
 
@@ -381,6 +604,19 @@ func (eb *EventBridge) handleTextDelta(evt pipeline.Event) {
 	// Track text data
 	eb.tracker.AddTextData(payload.Text)
 
+	// Text of an audio response is its transcript; audio_transcript.done is
+	// sent when the response completes
+	if eb.transcriptMode(ctx) {
+		eb.sender.SendEvent(events.NewResponseAudioTranscriptDeltaEvent(
+			ctx.ResponseID,
+			ctx.ItemID,
+			ctx.OutputIndex,
+			ctx.ContentIndex,
+			payload.Text,
+		))
+		return
+	}
+
 	// Send text delta event
 	eb.sender.SendEvent(events.NewResponseTextDeltaEvent(
 		ctx.ResponseID,
@@ -420,17 +656,30 @@ func (eb *EventBridge) completeCurrentResponse(status events.ResponseStatus) {
 		))
 	}
 
+	content := events.Content{
+		Type:  ctx.ContentType,
+		Audio: base64.StdEncoding.EncodeToString(ctx.AudioData),
+		Text:  ctx.TextData,
+	}
+	if eb.transcriptMode(ctx) {
+		eb.sender.SendEvent(events.NewResponseAudioTranscriptDoneEvent(
+			ctx.ResponseID,
+			ctx.ItemID,
+			ctx.OutputIndex,
+			ctx.ContentIndex,
+			ctx.TextData,
+		))
+		content.Text = ""
+		content.Transcript = ctx.TextData
+	}
+
 	// Send content_part.done
 	eb.sender.SendEvent(events.NewResponseContentPartDoneEvent(
 		ctx.ResponseID,
 		ctx.ItemID,
 		ctx.OutputIndex,
 		ctx.ContentIndex,
-		events.Content{
-			Type:  ctx.ContentType,
-			Audio: base64.StdEncoding.EncodeToString(ctx.AudioData),
-			Text:  ctx.TextData,
-		},
+		content,
 	))
 
 	// Send output_item.done
@@ -438,18 +687,12 @@ func (eb *EventBridge) completeCurrentResponse(status events.ResponseStatus) {
 		ctx.ResponseID,
 		ctx.OutputIndex,
 		events.ConversationItem{
-			ID:     ctx.ItemID,
-			Object: "realtime.item",
-			Type:   events.ItemTypeMessage,
-			Status: events.ItemStatusCompleted,
-			Role:   events.RoleAssistant,
-			Content: []events.Content{
-				{
-					Type:  ctx.ContentType,
-					Audio: base64.StdEncoding.EncodeToString(ctx.AudioData),
-					Text:  ctx.TextData,
-				},
-			},
+			ID:      ctx.ItemID,
+			Object:  "realtime.item",
+			Type:    events.ItemTypeMessage,
+			Status:  events.ItemStatusCompleted,
+			Role:    events.RoleAssistant,
+			Content: []events.Content{content},
 		},
 	))
 
@@ -473,6 +716,11 @@ func (eb *EventBridge) completeCurrentResponse(status events.ResponseStatus) {
 	eb.tracker.Reset()
 }
 
+// transcriptMode reports whether text of the response is sent as an audio transcript.
+func (eb *EventBridge) transcriptMode(ctx *state.ResponseContext) bool {
+	return eb.config.AudioTranscript && ctx.ContentType == events.ContentTypeAudio
+}
+
 // ForceCompleteResponse forces completion of any active response.
 // This is useful when the pipeline indicates completion via Pull() returning nil.
 func (eb *EventBridge) ForceCompleteResponse() {
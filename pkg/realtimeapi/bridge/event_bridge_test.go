@@ -417,3 +417,268 @@ func TestEventBridge_ForceCompleteResponse(t *testing.T) {
 	eb.Stop()
 	bus.Stop()
 }
+
+func TestEventBridge_InputTranscription(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	sender := newMockEventSender()
+
+	eb := NewEventBridgeWithConfig(bus, sender, "test-session", OpenAIConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus.Start(ctx)
+	eb.Start(ctx)
+
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventVADSpeechStart,
+		Timestamp: time.Now(),
+		Payload:   &pipeline.VADPayload{AudioMs: 100},
+	})
+	time.Sleep(20 * time.Millisecond)
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventVADSpeechEnd,
+		Timestamp: time.Now(),
+		Payload:   &pipeline.VADPayload{AudioMs: 900},
+	})
+	time.Sleep(20 * time.Millisecond)
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventFinalResult,
+		Timestamp: time.Now(),
+		Payload:   "hello there",
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	var startedID, committedID, createdID string
+	var transcription *events.ConversationItemInputAudioTranscriptionCompletedEvent
+	for _, e := range sender.getEvents() {
+		switch e := e.(type) {
+		case *events.InputAudioBufferSpeechStartedEvent:
+			startedID = e.ItemID
+		case *events.InputAudioBufferCommittedEvent:
+			committedID = e.ItemID
+		case *events.ConversationItemCreatedEvent:
+			createdID = e.Item.ID
+			if e.Item.Role != events.RoleUser {
+				t.Errorf("expected user item, got %s", e.Item.Role)
+			}
+		case *events.ConversationItemInputAudioTranscriptionCompletedEvent:
+			transcription = e
+		}
+	}
+
+	if transcription == nil {
+		t.Fatal("expected input audio transcription completed event")
+	}
+	if transcription.Transcript != "hello there" {
+		t.Errorf("unexpected transcript %q", transcription.Transcript)
+	}
+	// All events of the user turn refer to the same item
+	if startedID == "" || committedID != startedID || createdID != startedID || transcription.ItemID != startedID {
+		t.Errorf("item IDs differ: started=%s committed=%s created=%s transcription=%s",
+			startedID, committedID, createdID, transcription.ItemID)
+	}
+
+	eb.Stop()
+	bus.Stop()
+}
+
+func TestEventBridge_DefaultConfigIgnoresOptionalEvents(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	sender := newMockEventSender()
+
+	eb := NewEventBridge(bus, sender, "test-session")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus.Start(ctx)
+	eb.Start(ctx)
+
+	bus.Publish(pipeline.Event{Type: pipeline.EventFinalResult, Timestamp: time.Now(), Payload: "hello"})
+	bus.Publish(pipeline.Event{Type: pipeline.EventError, Timestamp: time.Now(), Payload: "boom"})
+	bus.Publish(pipeline.Event{Type: pipeline.EventAssistantSpeakingStart, Timestamp: time.Now()})
+
+	time.Sleep(100 * time.Millisecond)
+
+	if n := sender.getEventCount(); n != 0 {
+		t.Errorf("expected no events, got %d", n)
+	}
+
+	eb.Stop()
+	bus.Stop()
+}
+
+func TestEventBridge_AudioTranscript(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	sender := newMockEventSender()
+
+	eb := NewEventBridgeWithConfig(bus, sender, "test-session", Config{AudioTranscript: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus.Start(ctx)
+	eb.Start(ctx)
+
+	for _, text := range []string{"Hello", " world"} {
+		bus.Publish(pipeline.Event{
+			Type:      pipeline.EventTextDelta,
+			Timestamp: time.Now(),
+			Payload:   &pipeline.TextDeltaPayload{Text: text},
+		})
+		time.Sleep(20 * time.Millisecond)
+	}
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventResponseEnd,
+		Timestamp: time.Now(),
+		Payload:   &pipeline.ResponseEndPayload{Completed: true, Reason: "completed"},
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	if sender.hasEventType(events.ServerEventTypeResponseTextDelta) {
+		t.Error("text of an audio response should not be sent as response.text.delta")
+	}
+	if !sender.hasEventType(events.ServerEventTypeResponseAudioTranscriptDelta) {
+		t.Error("expected ResponseAudioTranscriptDelta event")
+	}
+
+	var done *events.ResponseAudioTranscriptDoneEvent
+	for _, e := range sender.getEvents() {
+		if e, ok := e.(*events.ResponseAudioTranscriptDoneEvent); ok {
+			done = e
+		}
+	}
+	if done == nil {
+		t.Fatal("expected ResponseAudioTranscriptDone event")
+	}
+	if done.Transcript != "Hello world" {
+		t.Errorf("unexpected transcript %q", done.Transcript)
+	}
+
+	eb.Stop()
+	bus.Stop()
+}
+
+func TestEventBridge_ToolCall(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	sender := newMockEventSender()
+
+	eb := NewEventBridgeWithConfig(bus, sender, "test-session", Config{ToolCalls: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus.Start(ctx)
+	eb.Start(ctx)
+
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventToolCall,
+		Timestamp: time.Now(),
+		Payload: &pipeline.ToolCallPayload{
+			CallID:    "call_1",
+			Name:      "get_weather",
+			Arguments: `{"city":"Paris"}`,
+		},
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	var args *events.ResponseFunctionCallArgumentsDoneEvent
+	for _, e := range sender.getEvents() {
+		if e, ok := e.(*events.ResponseFunctionCallArgumentsDoneEvent); ok {
+			args = e
+		}
+	}
+	if args == nil {
+		t.Fatal("expected ResponseFunctionCallArgumentsDone event")
+	}
+	if args.CallID != "call_1" || args.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected function call event: %+v", args)
+	}
+
+	done, ok := sender.getLastEvent().(*events.ResponseDoneEvent)
+	if !ok {
+		t.Fatal("expected ResponseDone as last event")
+	}
+	if len(done.Response.Output) != 1 || done.Response.Output[0].Name != "get_weather" {
+		t.Errorf("unexpected response output: %+v", done.Response.Output)
+	}
+
+	eb.Stop()
+	bus.Stop()
+}
+
+func TestEventBridge_Error(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	sender := newMockEventSender()
+
+	eb := NewEventBridgeWithConfig(bus, sender, "test-session", Config{Errors: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus.Start(ctx)
+	eb.Start(ctx)
+
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventError,
+		Timestamp: time.Now(),
+		Payload:   "Failed to synthesize speech",
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	e, ok := sender.getLastEvent().(*events.ErrorEvent)
+	if !ok {
+		t.Fatal("expected Error event")
+	}
+	if e.Error.Message != "Failed to synthesize speech" {
+		t.Errorf("unexpected error message %q", e.Error.Message)
+	}
+
+	eb.Stop()
+	bus.Stop()
+}
+
+func TestEventBridge_Speaking(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	sender := newMockEventSender()
+
+	eb := NewEventBridgeWithConfig(bus, sender, "test-session", Config{Speaking: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus.Start(ctx)
+	eb.Start(ctx)
+
+	bus.Publish(pipeline.Event{Type: pipeline.EventAssistantSpeakingStart, Timestamp: time.Now()})
+	bus.Publish(pipeline.Event{
+		Type:      pipeline.EventAssistantSpeakingEnd,
+		Timestamp: time.Now(),
+		Payload:   pipeline.SpeakingPayload{AudioMs: 1200, Reason: "interrupted"},
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	evts := sender.getEvents()
+	if len(evts) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evts))
+	}
+	if evts[0].ServerEventType() != events.ServerEventTypeAssistantSpeakingStarted {
+		t.Errorf("expected speaking started, got %s", evts[0].ServerEventType())
+	}
+	stopped, ok := evts[1].(*events.AssistantSpeakingStoppedEvent)
+	if !ok {
+		t.Fatalf("expected speaking stopped event, got %T", evts[1])
+	}
+	if stopped.AudioMs != 1200 || stopped.Reason != "interrupted" {
+		t.Errorf("unexpected speaking stopped event: %+v", stopped)
+	}
+
+	eb.Stop()
+	bus.Stop()
+}
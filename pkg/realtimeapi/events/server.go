@@ -42,6 +42,8 @@ const (
 
 	// Custom events (extensions to OpenAI Realtime API)
 	ServerEventTypeResponseInterrupted ServerEventType = "response.interrupted" // Response was interrupted by user speech
+	ServerEventTypeAssistantSpeakingStarted ServerEventType = "assistant.speaking.started" // Assistant audio started playing
+	ServerEventTypeAssistantSpeakingStopped ServerEventType = "assistant.speaking.stopped" // Assistant audio finished or was interrupted
 )
 
 // ServerEvent is the interface for all server events.
@@ -482,6 +484,17 @@ type ResponseFunctionCallArgumentsDoneEvent struct {
 	Arguments   string `json:"arguments"`
 }
 
+func NewResponseFunctionCallArgumentsDoneEvent(responseID, itemID string, outputIndex int, callID, arguments string) *ResponseFunctionCallArgumentsDoneEvent {
+	return &ResponseFunctionCallArgumentsDoneEvent{
+		BaseServerEvent: NewBaseServerEvent(ServerEventTypeResponseFunctionCallArgumentsDone),
+		ResponseID:      responseID,
+		ItemID:          itemID,
+		OutputIndex:     outputIndex,
+		CallID:          callID,
+		Arguments:       arguments,
+	}
+}
+
 // RateLimitsUpdatedEvent is sent when rate limits are updated.
 type RateLimitsUpdatedEvent struct {
 	BaseServerEvent
@@ -515,6 +528,34 @@ func NewResponseInterruptedEvent(responseID, itemID string, audioMs int, reason
 	}
 }
 
+// AssistantSpeakingStartedEvent is sent when assistant audio starts flowing to the client.
+// This is a custom extension to the OpenAI Realtime API.
+type AssistantSpeakingStartedEvent struct {
+	BaseServerEvent
+}
+
+func NewAssistantSpeakingStartedEvent() *AssistantSpeakingStartedEvent {
+	return &AssistantSpeakingStartedEvent{
+		BaseServerEvent: NewBaseServerEvent(ServerEventTypeAssistantSpeakingStarted),
+	}
+}
+
+// AssistantSpeakingStoppedEvent is sent when the assistant audio has played out or was interrupted.
+// This is a custom extension to the OpenAI Realtime API.
+type AssistantSpeakingStoppedEvent struct {
+	BaseServerEvent
+	AudioMs int    `json:"audio_ms"` // Audio sent while speaking (milliseconds)
+	Reason  string `json:"reason"`   // "finished" or "interrupted"
+}
+
+func NewAssistantSpeakingStoppedEvent(audioMs int, reason string) *AssistantSpeakingStoppedEvent {
+	return &AssistantSpeakingStoppedEvent{
+		BaseServerEvent: NewBaseServerEvent(ServerEventTypeAssistantSpeakingStopped),
+		AudioMs:         audioMs,
+		Reason:          reason,
+	}
+}
+
 // ParseServerEvent parses a JSON message into a ServerEvent.
 func ParseServerEvent(data []byte) (ServerEvent, error) {
 	var base BaseServerEvent
@@ -591,6 +632,31 @@ func ParseServerEvent(data []byte) (ServerEvent, error) {
 		err = json.Unmarshal(data, &e)
 		event = &e
 
+	case ServerEventTypeConversationItemCreated:
+		var e ConversationItemCreatedEvent
+		err = json.Unmarshal(data, &e)
+		event = &e
+
+	case ServerEventTypeConversationItemInputAudioTranscriptionCompleted:
+		var e ConversationItemInputAudioTranscriptionCompletedEvent
+		err = json.Unmarshal(data, &e)
+		event = &e
+
+	case ServerEventTypeResponseAudioTranscriptDelta:
+		var e ResponseAudioTranscriptDeltaEvent
+		err = json.Unmarshal(data, &e)
+		event = &e
+
+	case ServerEventTypeResponseAudioTranscriptDone:
+		var e ResponseAudioTranscriptDoneEvent
+		err = json.Unmarshal(data, &e)
+		event = &e
+
+	case ServerEventTypeResponseFunctionCallArgumentsDone:
+		var e ResponseFunctionCallArgumentsDoneEvent
+		err = json.Unmarshal(data, &e)
+		event = &e
+
 	default:
 		// For unhandled types, return the base event
 		return &base, nil
@@ -148,6 +148,12 @@ type ConversationItem struct {
 	Status  ItemStatus  `json:"status"`
 	Role    Role        `json:"role"`
 	Content []Content   `json:"content"`
+
+	// Function call fields (type "function_call" / "function_call_output")
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
 }
 
 // Content represents the content of a conversation item.
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -42,6 +43,13 @@ type Session struct {
 	// Audio mode: true if audio is transmitted via RTP (WebRTC mode)
 	audioViaRTP bool
 
+	// User text from conversation.item.create, sent to the pipeline on response.create
+	pendingText []string
+
+	// Items added by SeedConversation, and those not yet forwarded to the pipeline
+	seededCount   int
+	unsentHistory []events.ConversationItem
+
 	// Event channels
 	eventChan chan events.ServerEvent
 
@@ -338,10 +346,92 @@ func (s *Session) SetOnClose(fn func(session *Session)) {
 }
 
 // SetPipeline sets the pipeline for this session.
+// Conversation history seeded before the pipeline was set is forwarded to it.
 func (s *Session) SetPipeline(p *pipeline.Pipeline) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.Pipeline = p
+	s.mu.Unlock()
+
+	if p != nil {
+		if err := s.forwardHistory(); err != nil {
+			log.Printf("[session %s] failed to forward seeded conversation: %v", s.ID, err)
+		}
+	}
+}
+
+// SeedConversation pre-populates the conversation with items from a previous
+// interaction, e.g. the transcript of an earlier call. It must be called before
+// the session processes any live items; typically from the pipeline factory.
+//
+// Message items with text (or audio transcripts) are forwarded to the pipeline
+// as context via pipeline.Pipeline.AppendHistory, so the realtime provider or
+// chat model knows the history. Seeded items are not announced to the client.
+func (s *Session) SeedConversation(items []events.ConversationItem) error {
+	s.mu.Lock()
+	if s.Conversation.Count() != s.seededCount {
+		s.mu.Unlock()
+		return fmt.Errorf("conversation already has live items, seed it before the session starts processing")
+	}
+
+	for _, item := range items {
+		if item.ID == "" {
+			item.ID = "item_" + uuid.New().String()[:8]
+		}
+		if item.Object == "" {
+			item.Object = "realtime.item"
+		}
+		if item.Type == "" {
+			item.Type = events.ItemTypeMessage
+		}
+		if item.Status == "" {
+			item.Status = events.ItemStatusCompleted
+		}
+		s.Conversation.AddItem(item)
+		s.seededCount++
+		s.unsentHistory = append(s.unsentHistory, item)
+	}
+	hasPipeline := s.Pipeline != nil
+	s.mu.Unlock()
+
+	if !hasPipeline {
+		return nil
+	}
+	return s.forwardHistory()
+}
+
+// forwardHistory sends seeded items that have not reached the pipeline yet.
+func (s *Session) forwardHistory() error {
+	s.mu.Lock()
+	p := s.Pipeline
+	items := s.unsentHistory
+	s.unsentHistory = nil
+	s.mu.Unlock()
+
+	for _, item := range items {
+		text := itemText(item)
+		if item.Type != events.ItemTypeMessage || item.Role == "" || text == "" {
+			continue
+		}
+		if err := p.AppendHistory(string(item.Role), text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemText returns the text of a message item, using transcripts for audio content.
+func itemText(item events.ConversationItem) string {
+	var parts []string
+	for _, content := range item.Content {
+		text := content.Text
+		if text == "" {
+			text = content.Transcript
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
 }
 
 // GetPipeline returns the pipeline for this session.
@@ -388,6 +478,23 @@ func (s *Session) GetAudioTransport() AudioTransport {
 	return nil
 }
 
+// OutputSampleRate returns the sample rate pipeline audio output must have to
+// be sent over RTP: the clock rate negotiated by the transport, or
+// DefaultRTPSampleRate if it does not report one. Pipeline factories use it
+// as the target of the output resampler.
+func (s *Session) OutputSampleRate() int {
+	s.mu.RLock()
+	transport := s.transport
+	s.mu.RUnlock()
+
+	if st, ok := transport.(SampleRateTransport); ok {
+		if rate := st.SampleRate(); rate > 0 {
+			return rate
+		}
+	}
+	return DefaultRTPSampleRate
+}
+
 // PushAudio pushes PCM audio data directly to the pipeline.
 // This is used for WebRTC mode where audio comes via RTP, not base64-encoded events.
 func (s *Session) PushAudio(data []byte, sampleRate, channels int) {
@@ -407,6 +514,63 @@ func (s *Session) PushAudio(data []byte, sampleRate, channels int) {
 	}
 }
 
+// PushTrackAudio is PushAudio for one of several inbound audio tracks of the
+// connection. The message carries trackID in the pipeline.AttrAudioTrack
+// attribute, which pipeline.Pipeline.SetAudioTrackInput routes on.
+func (s *Session) PushTrackAudio(trackID string, data []byte, sampleRate, channels int) {
+	if p := s.GetPipeline(); p != nil {
+		msg := &pipeline.PipelineMessage{
+			Type:      pipeline.MsgTypeAudio,
+			SessionID: s.ID,
+			Timestamp: time.Now(),
+			AudioData: &pipeline.AudioData{
+				Data:       data,
+				SampleRate: sampleRate,
+				Channels:   channels,
+				MediaType:  pipeline.AudioMediaTypeRaw,
+				Timestamp:  time.Now(),
+			},
+		}
+		msg.SetAttribute(pipeline.AttrAudioTrack, trackID)
+		p.Push(msg)
+	}
+}
+
+// PushText injects a typed user turn into the running pipeline, as if the user
+// had spoken it. The text is added to the conversation and routed to the
+// pipeline's text input (see pipeline.Pipeline.SetTextInput), which produces
+// a spoken response.
+func (s *Session) PushText(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("text is empty")
+	}
+
+	p := s.GetPipeline()
+	if p == nil {
+		return fmt.Errorf("session has no pipeline configured")
+	}
+
+	item := events.ConversationItem{
+		ID:     "item_" + uuid.New().String()[:8],
+		Object: "realtime.item",
+		Type:   events.ItemTypeMessage,
+		Status: events.ItemStatusCompleted,
+		Role:   events.RoleUser,
+		Content: []events.Content{
+			{Type: events.ContentTypeInputText, Text: text},
+		},
+	}
+	previousItemID := s.Conversation.GetLastItemID()
+	s.Conversation.AddItem(item)
+
+	if err := s.SendEvent(events.NewConversationItemCreatedEvent(item, previousItemID)); err != nil {
+		return err
+	}
+
+	return p.PushText(s.ID, text)
+}
+
 // SendAudio sends PCM audio data directly via RTP track.
 // This is used for WebRTC mode where audio is sent via RTP, not base64-encoded events.
 // Returns error if the transport doesn't support RTP audio.
@@ -641,6 +805,17 @@ func (s *Session) handleConversationItemCreate(e *events.ConversationItemCreateE
 
 	s.Conversation.AddItem(item)
 
+	// User text is held until response.create, which runs it through the pipeline
+	if item.Role == events.RoleUser {
+		s.mu.Lock()
+		for _, content := range item.Content {
+			if content.Type == events.ContentTypeInputText && strings.TrimSpace(content.Text) != "" {
+				s.pendingText = append(s.pendingText, content.Text)
+			}
+		}
+		s.mu.Unlock()
+	}
+
 	return s.SendEvent(events.NewConversationItemCreatedEvent(item, e.PreviousItemID))
 }
 
@@ -694,11 +869,22 @@ func (s *Session) handleResponseCreate(_ *events.ResponseCreateEvent) error {
 		return err
 	}
 
-	// Trigger AI processing through the pipeline
-	// This will be handled by the event handler that listens to pipeline output
-	if p := s.GetPipeline(); p != nil {
-		// The pipeline will process and send response events
-		// through the session's event handler
+	// Trigger AI processing through the pipeline with the user text created
+	// since the last response. The pipeline sends the response events.
+	s.mu.Lock()
+	pending := s.pendingText
+	s.pendingText = nil
+	s.mu.Unlock()
+
+	if p := s.GetPipeline(); p != nil && len(pending) > 0 {
+		if err := p.PushText(s.ID, strings.Join(pending, "\n")); err != nil {
+			return s.SendEvent(events.NewErrorEvent(
+				events.ErrorTypeServer,
+				"text_input_failed",
+				err.Error(),
+				"",
+			))
+		}
 	}
 
 	return nil
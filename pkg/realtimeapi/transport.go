@@ -37,11 +37,23 @@ type AudioTransport interface {
 	SupportsRTPAudio() bool
 }
 
+// DefaultRTPSampleRate is the Opus RTP clock rate assumed when the transport
+// does not report a negotiated one.
+const DefaultRTPSampleRate = 48000
+
+// SampleRateTransport is implemented by audio transports whose output clock
+// rate is negotiated with the client, such as WebRTC.
+type SampleRateTransport interface {
+	// SampleRate returns the sample rate SendAudio expects.
+	SampleRate() int
+}
+
 // WebSocketTransport wraps a WebSocket connection for Realtime API events.
 type WebSocketTransport struct {
-	conn   *websocket.Conn
-	mu     sync.Mutex
-	closed bool
+	conn         *websocket.Conn
+	mu           sync.Mutex
+	closed       bool
+	writeTimeout time.Duration
 }
 
 // NewWebSocketTransport creates a new WebSocket transport.
@@ -51,6 +63,14 @@ func NewWebSocketTransport(conn *websocket.Conn) *WebSocketTransport {
 	}
 }
 
+// SetWriteTimeout bounds each write so a client that stops reading cannot
+// block the session forever. 0 means no deadline.
+func (t *WebSocketTransport) SetWriteTimeout(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.writeTimeout = d
+}
+
 // SendEvent sends a server event via WebSocket.
 func (t *WebSocketTransport) SendEvent(event events.ServerEvent) error {
 	t.mu.Lock()
@@ -65,6 +85,9 @@ func (t *WebSocketTransport) SendEvent(event events.ServerEvent) error {
 		return err
 	}
 
+	if t.writeTimeout > 0 {
+		t.conn.SetWriteDeadline(time.Now().Add(t.writeTimeout))
+	}
 	return t.conn.WriteMessage(websocket.TextMessage, data)
 }
 
@@ -0,0 +1,191 @@
+// Package server provides WebRTC server implementations for Realtime API.
+//
+// Pluggable authentication for the servers. An Authenticator runs before any
+// session, connection or pipeline is created, so unauthenticated clients cost
+// nothing but the rejected request. The accepted client's Identity is carried
+// by the session context for per-user pipelines, quotas or logging.
+//
+// Built in:
+//   - BearerTokenAuthenticator: static API tokens in the Authorization header
+//   - SignedURLAuthenticator: expiring HMAC-signed URLs created with SignURL,
+//     for clients that cannot set headers on the request
+//
+// Usage:
+//
+//	config := server.DefaultWebRTCRealtimeConfig()
+//	config.Authenticator = server.BearerTokenAuthenticator(map[string]string{
+//		"tenant-a": os.Getenv("TENANT_A_TOKEN"),
+//	})
+//	// in the PipelineFactory
+//	id, _ := server.IdentityFromContext(session.Context())
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned by authenticators that reject a request.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Identity is the authenticated client of a session.
+type Identity struct {
+	// Subject identifies the client (user, tenant or API key ID)
+	Subject string
+
+	// Claims holds extra attributes from the credential (e.g. org, plan, roles)
+	Claims map[string]string
+}
+
+// Authenticator validates a WebRTC negotiation request before any session or
+// pipeline is created. Implementations can check a bearer token, a signed URL,
+// a JWT or anything else carried by the request. A non-nil error rejects the
+// request with 401 Unauthorized.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(r *http.Request) (*Identity, error)
+
+// Authenticate calls f(r).
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (*Identity, error) {
+	return f(r)
+}
+
+// BearerTokenAuthenticator accepts requests whose Authorization header is
+// "Bearer <token>" for one of the given tokens. The identity subject is the
+// key of the matching token, so tokens can be mapped to clients.
+func BearerTokenAuthenticator(tokens map[string]string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Identity, error) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			return nil, ErrUnauthorized
+		}
+		for subject, want := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+				return &Identity{Subject: subject}, nil
+			}
+		}
+		return nil, ErrUnauthorized
+	})
+}
+
+// Query parameters of a signed URL, see SignURL.
+const (
+	signedURLSubjectParam   = "sub"
+	signedURLExpiresParam   = "expires"
+	signedURLSignatureParam = "signature"
+)
+
+// SignURL returns rawURL with a subject, an expiry and an HMAC-SHA256
+// signature added to its query, for clients that cannot set headers (e.g. a
+// link handed out by your backend). Verify it with SignedURLAuthenticator.
+func SignURL(rawURL string, secret []byte, subject string, expires time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(signedURLSubjectParam, subject)
+	q.Set(signedURLExpiresParam, strconv.FormatInt(expires.Unix(), 10))
+	q.Del(signedURLSignatureParam)
+	q.Set(signedURLSignatureParam, urlSignature(secret, u.Path, q))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// SignedURLAuthenticator accepts requests whose URL was signed by SignURL with
+// secret and has not expired. The identity subject is the signed subject.
+func SignedURLAuthenticator(secret []byte) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Identity, error) {
+		q := r.URL.Query()
+		signature := q.Get(signedURLSignatureParam)
+		if signature == "" {
+			return nil, ErrUnauthorized
+		}
+		q.Del(signedURLSignatureParam)
+		if !hmac.Equal([]byte(signature), []byte(urlSignature(secret, r.URL.Path, q))) {
+			return nil, ErrUnauthorized
+		}
+
+		expires, err := strconv.ParseInt(q.Get(signedURLExpiresParam), 10, 64)
+		if err != nil || time.Now().Unix() > expires {
+			return nil, ErrUnauthorized
+		}
+		return &Identity{Subject: q.Get(signedURLSubjectParam)}, nil
+	})
+}
+
+// urlSignature signs the path and the query without the signature.
+// url.Values.Encode sorts by key, so the parameter order does not matter.
+func urlSignature(secret []byte, path string, q url.Values) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(path + "?" + q.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validatorAuthenticator adapts the token validator of WebRTCRealtimeConfig.AuthValidator.
+func validatorAuthenticator(validate func(token string) bool) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Identity, error) {
+		if !validate(r.Header.Get("Authorization")) {
+			return nil, ErrUnauthorized
+		}
+		return &Identity{}, nil
+	})
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of ctx carrying id.
+func ContextWithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the authenticated client of a session. Session
+// and connection contexts created by the WebRTC servers carry it when an
+// Authenticator is configured, e.g. IdentityFromContext(session.Context())
+// in a PipelineFactory.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok && id != nil
+}
+
+// sameIdentity reports whether ctx and other carry the same authenticated
+// client, or both carry none.
+func sameIdentity(ctx, other context.Context) bool {
+	a, okA := IdentityFromContext(ctx)
+	b, okB := IdentityFromContext(other)
+	if !okA || !okB {
+		return okA == okB
+	}
+	return a.Subject == b.Subject
+}
+
+// authenticate runs auth on r. It returns the context to create the session
+// with, or writes 401 and returns false if the request is rejected.
+func authenticate(w http.ResponseWriter, r *http.Request, auth Authenticator) (context.Context, bool) {
+	ctx := context.Background()
+	if auth == nil {
+		return ctx, true
+	}
+
+	id, err := auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if id == nil {
+		id = &Identity{}
+	}
+	return ContextWithIdentity(ctx, id), true
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearerTokenAuthenticator(t *testing.T) {
+	auth := BearerTokenAuthenticator(map[string]string{"alice": "secret-a", "bob": "secret-b"})
+
+	r := httptest.NewRequest(http.MethodPost, "/session", nil)
+	r.Header.Set("Authorization", "Bearer secret-b")
+	id, err := auth.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", id.Subject)
+
+	for _, header := range []string{"", "secret-b", "Bearer ", "Bearer wrong"} {
+		r.Header.Set("Authorization", header)
+		_, err := auth.Authenticate(r)
+		assert.ErrorIs(t, err, ErrUnauthorized, "header %q", header)
+	}
+}
+
+func TestSignedURLAuthenticator(t *testing.T) {
+	secret := []byte("signing-key")
+	auth := SignedURLAuthenticator(secret)
+
+	signed, err := SignURL("https://example.com/session?model=gpt-4o-realtime-preview", secret, "alice", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	id, err := auth.Authenticate(httptest.NewRequest(http.MethodPost, signed, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.Subject)
+
+	// Tampered queries, wrong keys and expired links are rejected
+	tampered := httptest.NewRequest(http.MethodPost, signed+"&model=other", nil)
+	_, err = auth.Authenticate(tampered)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = SignedURLAuthenticator([]byte("other-key")).Authenticate(httptest.NewRequest(http.MethodPost, signed, nil))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	expired, err := SignURL("https://example.com/session", secret, "alice", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = auth.Authenticate(httptest.NewRequest(http.MethodPost, expired, nil))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth := BearerTokenAuthenticator(map[string]string{"alice": "secret"})
+
+	// Rejected requests get 401 before any session is created
+	w := httptest.NewRecorder()
+	_, ok := authenticate(w, httptest.NewRequest(http.MethodPost, "/session", nil), auth)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/session", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	ctx, ok := authenticate(httptest.NewRecorder(), r, auth)
+	require.True(t, ok)
+	id, ok := IdentityFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "alice", id.Subject)
+
+	// Without an Authenticator requests pass and carry no identity
+	ctx, ok = authenticate(httptest.NewRecorder(), r, nil)
+	require.True(t, ok)
+	_, ok = IdentityFromContext(ctx)
+	assert.False(t, ok)
+}
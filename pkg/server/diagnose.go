@@ -0,0 +1,164 @@
+// Package server provides WebRTC server implementations for Realtime API.
+//
+// The /diagnose endpoint of WebRTCRealtimeServer runs a loopback diagnostic
+// session for users reporting choppy output audio. Instead of a model, a
+// LoopbackDiagnosticElement plays a known test signal through the real output
+// path, and the collected report gives reproducible data to attach to a bug.
+//
+// Usage:
+//
+//	http.HandleFunc("/diagnose", srv.HandleDiagnose)
+//	// POST an SDP offer to start, then
+//	// GET /diagnose?session_id=... for the report
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/realtime-ai/realtime-ai/pkg/elements"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/realtimeapi"
+)
+
+// diagnosticRetention is how long a diagnostic report stays available after
+// its session has ended.
+const diagnosticRetention = 10 * time.Minute
+
+// HandleDiagnose serves the /diagnose endpoint for reproducing output audio
+// dropouts with a known test signal instead of a model's speech.
+//
+// POST takes the same SDP offer as HandleNegotiate and starts a session that
+// plays a test signal through the regular output path (resample to the
+// negotiated RTP clock rate, Opus, RTP). The signal can be chosen with query parameters:
+//
+//	signal=tone|sweep   test signal (default tone)
+//	duration=10s        how long to play (default 10s, at most 5m)
+//	sample_rate=24000   rate the signal is generated at (default 24000)
+//
+// The audio is checked for timing gaps, silent dropouts, waveform jumps and
+// slow or failing sends; problems are logged as they happen. If the client
+// sends the received audio back on its track (loopback), that audio is checked
+// as well.
+//
+// GET /diagnose?session_id=... returns the report as JSON. It is available
+// while the session runs and for 10 minutes after it ends.
+func (s *WebRTCRealtimeServer) HandleDiagnose(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ctx, ok := authenticate(w, r, s.authenticator())
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleDiagnoseReport(w, r)
+	case http.MethodPost:
+		cfg, err := diagnosticConfig(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req, ok := readNegotiateRequest(w, r)
+		if !ok {
+			return
+		}
+		diag := elements.NewLoopbackDiagnosticElementWithConfig(cfg)
+		s.negotiateSession(ctx, w, req.SessionDescription, s.config.DefaultModel, diagnosticPipeline(diag, cfg.SampleRate), diag)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleDiagnoseReport writes the report of a diagnostic session.
+func (s *WebRTCRealtimeServer) handleDiagnoseReport(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("session_id")
+
+	s.RLock()
+	diag := s.diagnostics[sessionID]
+	s.RUnlock()
+
+	if diag == nil {
+		http.Error(w, "Unknown diagnostic session", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		SessionID string `json:"session_id"`
+		elements.LoopbackReport
+	}{
+		SessionID:      sessionID,
+		LoopbackReport: diag.Report(),
+	})
+}
+
+// forgetDiagnostic drops a diagnostic report once the retention period has passed.
+func (s *WebRTCRealtimeServer) forgetDiagnostic(sessionID string) {
+	time.AfterFunc(diagnosticRetention, func() {
+		s.Lock()
+		delete(s.diagnostics, sessionID)
+		s.Unlock()
+	})
+}
+
+// diagnosticConfig reads the test signal options from the query string.
+func diagnosticConfig(r *http.Request) (elements.LoopbackDiagnosticConfig, error) {
+	cfg := elements.DefaultLoopbackDiagnosticConfig()
+	q := r.URL.Query()
+
+	switch signal := q.Get("signal"); signal {
+	case "":
+	case elements.LoopbackSignalTone, elements.LoopbackSignalSweep:
+		cfg.Signal = signal
+	default:
+		return cfg, fmt.Errorf("invalid signal %q", signal)
+	}
+
+	if v := q.Get("duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > 5*time.Minute {
+			return cfg, fmt.Errorf("invalid duration %q", v)
+		}
+		cfg.Duration = d
+	}
+
+	if v := q.Get("sample_rate"); v != "" {
+		rate, err := strconv.Atoi(v)
+		if err != nil || rate < 8000 || rate > realtimeapi.DefaultRTPSampleRate {
+			return cfg, fmt.Errorf("invalid sample_rate %q", v)
+		}
+		cfg.SampleRate = rate
+	}
+
+	return cfg, nil
+}
+
+// diagnosticPipeline builds test signal → resample to the session's output
+// rate → probe. The session sends the probe's output over RTP like any model
+// audio.
+func diagnosticPipeline(diag *elements.LoopbackDiagnosticElement, sampleRate int) PipelineFactory {
+	return func(ctx context.Context, session *realtimeapi.Session) (*pipeline.Pipeline, error) {
+		p := pipeline.NewPipeline("diagnose-" + session.ID)
+
+		resample := elements.NewAudioResampleElement(sampleRate, session.OutputSampleRate(), 1, 1)
+		probe := diag.Probe()
+
+		p.AddElements([]pipeline.Element{diag, resample, probe})
+		p.Link(diag, resample)
+		p.Link(resample, probe)
+		return p, nil
+	}
+}
@@ -0,0 +1,99 @@
+// Package server provides WebSocket server implementations for Realtime API.
+//
+// keepalive detects dead clients of WebSocketRealtimeServer. A client that
+// disappears without closing the socket (network drop, suspended laptop)
+// would otherwise keep its session and pipeline running forever; with
+// PingInterval set, its session is closed once it misses PongTimeout.
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// keepalive detects dead WebSocket clients.
+//
+// The server pings the client every pingInterval. Every message or pong from
+// the client pushes the read deadline out by pingInterval+pongTimeout, so a
+// client that goes silent makes the blocked read fail with a timeout and the
+// session is closed by the read loop.
+type keepalive struct {
+	conn         *websocket.Conn
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// newKeepalive returns nil when pingInterval is 0 (keepalive disabled).
+// A non-positive pongTimeout defaults to pingInterval.
+func newKeepalive(conn *websocket.Conn, pingInterval, pongTimeout, writeTimeout time.Duration) *keepalive {
+	if pingInterval <= 0 {
+		return nil
+	}
+	if pongTimeout <= 0 {
+		pongTimeout = pingInterval
+	}
+	return &keepalive{
+		conn:         conn,
+		pingInterval: pingInterval,
+		pongTimeout:  pongTimeout,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Start sets the initial read deadline, installs the pong handler and runs the
+// ping loop until ctx is done. A failed ping closes the connection, which
+// unblocks the read loop.
+func (k *keepalive) Start(ctx context.Context) {
+	if k == nil {
+		return
+	}
+
+	k.Extend()
+	k.conn.SetPongHandler(func(string) error {
+		k.Extend()
+		return nil
+	})
+
+	go func() {
+		ticker := time.NewTicker(k.pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// WriteControl is safe to call concurrently with the session writer
+				deadline := time.Now().Add(k.pongTimeout)
+				if k.writeTimeout > 0 {
+					deadline = time.Now().Add(k.writeTimeout)
+				}
+				if err := k.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+					log.Printf("[WebSocketRealtimeServer] ping failed, closing connection: %v", err)
+					k.conn.Close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Extend pushes the read deadline out after activity from the client.
+func (k *keepalive) Extend() {
+	if k == nil {
+		return
+	}
+	k.conn.SetReadDeadline(time.Now().Add(k.pingInterval + k.pongTimeout))
+}
+
+// isTimeout reports whether err is a read deadline expiry.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newKeepaliveServer runs a read loop like WebSocketRealtimeServer.handleSession
+// and reports why each connection was closed.
+func newKeepaliveServer(t *testing.T, pingInterval, pongTimeout time.Duration) (string, <-chan error) {
+	t.Helper()
+	closed := make(chan error, 1)
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ka := newKeepalive(conn, pingInterval, pongTimeout, time.Second)
+		ka.Start(ctx)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+			ka.Extend()
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), closed
+}
+
+func TestKeepalive_ReapsUnresponsiveClient(t *testing.T) {
+	url, closed := newKeepaliveServer(t, 50*time.Millisecond, 50*time.Millisecond)
+
+	// The client never reads, so pings are never answered with pongs
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	select {
+	case err := <-closed:
+		assert.True(t, isTimeout(err), "expected read timeout, got %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("unresponsive client was not reaped")
+	}
+}
+
+func TestKeepalive_KeepsResponsiveClient(t *testing.T) {
+	url, closed := newKeepaliveServer(t, 50*time.Millisecond, 50*time.Millisecond)
+
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Reading lets the default ping handler answer with pongs
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("responsive client was closed: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestKeepalive_Disabled(t *testing.T) {
+	assert.Nil(t, newKeepalive(nil, 0, time.Second, time.Second))
+
+	// A nil keepalive is a no-op
+	var ka *keepalive
+	ka.Start(context.Background())
+	ka.Extend()
+}
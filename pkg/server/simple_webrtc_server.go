@@ -19,10 +19,11 @@ import (
 type BasicWebRTCServer struct {
 	sync.RWMutex
 
-	config  *BasicWebRTCConfig
-	peers   map[string]connection.Connection
-	api     *webrtc.API
-	handler ServerEventHandler
+	config   *BasicWebRTCConfig
+	peers    map[string]connection.Connection
+	sessions map[string]connection.ResumableConnection // by resume token
+	api      *webrtc.API
+	handler  ServerEventHandler
 
 	onConnectionCreated func(ctx context.Context, conn connection.Connection)
 	onConnectionError   func(ctx context.Context, conn connection.Connection, err error)
@@ -35,6 +36,7 @@ func NewBasicWebRTCServer(cfg *BasicWebRTCConfig) *BasicWebRTCServer {
 		onConnectionCreated: func(ctx context.Context, conn connection.Connection) {},
 		onConnectionError:   func(ctx context.Context, conn connection.Connection, err error) {},
 		peers:               make(map[string]connection.Connection),
+		sessions:            make(map[string]connection.ResumableConnection),
 	}
 }
 
@@ -56,25 +58,21 @@ func (s *BasicWebRTCServer) OnConnectionError(f func(ctx context.Context, conn c
 }
 
 func (s *BasicWebRTCServer) Start() error {
+	if err := validateICEServers(s.config.ICEServers, s.config.ICETransportPolicy, s.config.ICELite); err != nil {
+		return fmt.Errorf("invalid ICE server configuration: %w", err)
+	}
 
 	settingEngine := webrtc.SettingEngine{}
 	if s.config.ICELite {
 		settingEngine.SetLite(true)
 	}
 
-	if len(s.config.Endpoint) > 0 {
-		settingEngine.SetNAT1To1IPs(s.config.Endpoint, webrtc.ICECandidateTypeHost)
-	}
+	applyICEConfig(&settingEngine, s.config.ICE, s.config.Endpoint)
 
 	settingEngine.SetFireOnTrackBeforeFirstRTP(true)
 
-	settingEngine.SetNetworkTypes([]webrtc.NetworkType{
-		webrtc.NetworkTypeUDP4,
-		webrtc.NetworkTypeTCP4,
-	})
-
 	udpListener, err := net.ListenUDP("udp", &net.UDPAddr{
-		IP:   net.ParseIP("0.0.0.0"),
+		IP:   s.config.ICE.listenIP(),
 		Port: s.config.RTCUDPPort,
 	})
 
@@ -94,11 +92,46 @@ func (s *BasicWebRTCServer) Start() error {
 
 }
 
+// peerConnectionConfig returns the configuration new PeerConnections are created with.
+func (s *BasicWebRTCServer) peerConnectionConfig() webrtc.Configuration {
+	return peerConnectionConfig(s.config.ICEServers, s.config.ICETransportPolicy)
+}
+
+// negotiateRequest is the body of a negotiation request. A client that lost
+// its connection sends the resume token it received earlier to resume the
+// same session instead of starting a new one.
+type negotiateRequest struct {
+	webrtc.SessionDescription
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// negotiateResponse is the SDP answer plus the session's resume token.
+type negotiateResponse struct {
+	webrtc.SessionDescription
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// ResumeToken returns the resume token of a connected peer, or "" if the peer
+// is unknown or resumption is disabled.
+func (s *BasicWebRTCServer) ResumeToken(peerID string) string {
+	s.RLock()
+	defer s.RUnlock()
+
+	if conn, ok := s.peers[peerID].(connection.ResumableConnection); ok {
+		return conn.ResumeToken()
+	}
+	return ""
+}
+
 // HandleNegotiate handles the /session WebRTC negotiation endpoint.
+//
+// When ResumeGracePeriod is set, the answer carries a "resume_token". Posting
+// an offer with that token within the grace period resumes the existing
+// connection, pipeline and conversation on a new PeerConnection.
 func (s *BasicWebRTCServer) HandleNegotiate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 	// Handle OPTIONS request for CORS preflight
 	if r.Method == http.MethodOptions {
@@ -110,24 +143,30 @@ func (s *BasicWebRTCServer) HandleNegotiate(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	ctx, ok := authenticate(w, r, s.config.Authenticator)
+	if !ok {
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Failed to read body", http.StatusBadRequest)
 		return
 	}
 
-	var offer webrtc.SessionDescription
-	if err := json.Unmarshal(body, &offer); err != nil {
+	var req negotiateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
 		http.Error(w, "Failed to parse offer", http.StatusBadRequest)
 		return
 	}
 
-	ctx := context.Background()
+	if req.ResumeToken != "" {
+		s.handleResume(ctx, w, req)
+		return
+	}
 
 	// Create PeerConnection
-	pc, err := s.api.NewPeerConnection(webrtc.Configuration{
-		ICEServers: []webrtc.ICEServer{},
-	})
+	pc, err := s.api.NewPeerConnection(s.peerConnectionConfig())
 
 	if err != nil {
 		s.onConnectionError(ctx, nil, err)
@@ -136,40 +175,103 @@ func (s *BasicWebRTCServer) HandleNegotiate(w http.ResponseWriter, r *http.Reque
 	}
 
 	peerID := uuid.New().String()
-	webrtcConn := connection.NewWebRTCConnection(peerID, pc)
+	cfg := connection.DefaultWebRTCConfig()
+	cfg.ResumeGracePeriod = s.config.ResumeGracePeriod
+	webrtcConn := connection.NewWebRTCConnectionWithConfig(peerID, pc, cfg)
 
 	s.Lock()
 	s.peers[peerID] = webrtcConn
 	s.Unlock()
 
+	resumeToken := ""
+	if resumable, ok := webrtcConn.(connection.ResumableConnection); ok {
+		resumeToken = resumable.ResumeToken()
+		if resumeToken != "" {
+			s.Lock()
+			s.sessions[resumeToken] = resumable
+			s.Unlock()
+		}
+
+		// Forget the connection once it is closed for good
+		go func() {
+			<-resumable.Done()
+			s.Lock()
+			delete(s.peers, peerID)
+			delete(s.sessions, resumeToken)
+			s.Unlock()
+		}()
+	}
+
 	// Notify handler: connection created
 	s.onConnectionCreated(ctx, webrtcConn)
 
-	// Start negotiation
-	if err := pc.SetRemoteDescription(offer); err != nil {
+	answer, err := negotiate(pc, req.SessionDescription, s.config.ICE)
+	if err != nil {
 		s.onConnectionError(ctx, webrtcConn, err)
-		http.Error(w, "Failed to set remote description", http.StatusInternalServerError)
+		http.Error(w, "Failed to negotiate", http.StatusInternalServerError)
 		return
 	}
 
-	answer, err := pc.CreateAnswer(nil)
+	// Return SDP answer to client
+	w.Header().Set("Content-Type", "application/sdp")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(negotiateResponse{SessionDescription: *answer, ResumeToken: resumeToken})
+}
+
+// handleResume moves an existing session onto a new PeerConnection.
+func (s *BasicWebRTCServer) handleResume(ctx context.Context, w http.ResponseWriter, req negotiateRequest) {
+	s.RLock()
+	conn, ok := s.sessions[req.ResumeToken]
+	s.RUnlock()
+
+	if !ok {
+		http.Error(w, "Unknown or expired resume token", http.StatusNotFound)
+		return
+	}
+
+	pc, err := s.api.NewPeerConnection(s.peerConnectionConfig())
 	if err != nil {
-		s.onConnectionError(ctx, webrtcConn, err)
-		http.Error(w, "Failed to create answer", http.StatusInternalServerError)
+		s.onConnectionError(ctx, conn, err)
+		http.Error(w, "Failed to create peer connection", http.StatusInternalServerError)
 		return
 	}
-	if err := pc.SetLocalDescription(answer); err != nil {
-		s.onConnectionError(ctx, webrtcConn, err)
-		http.Error(w, "Failed to set local description", http.StatusInternalServerError)
+
+	if err := conn.Resume(pc); err != nil {
+		pc.Close()
+		http.Error(w, "Unknown or expired resume token", http.StatusNotFound)
 		return
 	}
 
-	// Wait for ICE gathering to complete
-	gatherComplete := webrtc.GatheringCompletePromise(pc)
-	<-gatherComplete
+	answer, err := negotiate(pc, req.SessionDescription, s.config.ICE)
+	if err != nil {
+		s.onConnectionError(ctx, conn, err)
+		http.Error(w, "Failed to negotiate", http.StatusInternalServerError)
+		return
+	}
 
-	// Return SDP answer to client
 	w.Header().Set("Content-Type", "application/sdp")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(pc.LocalDescription())
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(negotiateResponse{SessionDescription: *answer, ResumeToken: req.ResumeToken})
+}
+
+// negotiate applies the offer to pc and returns the answer once ICE gathering
+// is done as configured by ice.
+func negotiate(pc *webrtc.PeerConnection, offer webrtc.SessionDescription, ice ICEConfig) (*webrtc.SessionDescription, error) {
+	if err := pc.SetRemoteDescription(offer); err != nil {
+		return nil, fmt.Errorf("failed to set remote description: %w", err)
+	}
+
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create answer: %w", err)
+	}
+	gatherDone := ice.gatheringDone(pc)
+	if err := pc.SetLocalDescription(answer); err != nil {
+		return nil, fmt.Errorf("failed to set local description: %w", err)
+	}
+
+	// Wait for ICE gathering
+	<-gatherDone
+
+	return pc.LocalDescription(), nil
 }
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResumeServer returns a BasicWebRTCServer with resumption enabled that
+// negotiates without binding the UDP mux.
+func newResumeServer(t *testing.T) *BasicWebRTCServer {
+	t.Helper()
+	s := NewBasicWebRTCServer(&BasicWebRTCConfig{ResumeGracePeriod: time.Second})
+	s.api = webrtc.NewAPI()
+	t.Cleanup(func() {
+		s.RLock()
+		defer s.RUnlock()
+		for _, conn := range s.peers {
+			go conn.Close()
+		}
+	})
+	return s
+}
+
+// newClientOffer returns the audio offer of a new client PeerConnection.
+func newClientOffer(t *testing.T) webrtc.SessionDescription {
+	t.Helper()
+
+	client, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio)
+	require.NoError(t, err)
+	offer, err := client.CreateOffer(nil)
+	require.NoError(t, err)
+	require.NoError(t, client.SetLocalDescription(offer))
+	return offer
+}
+
+// postOffer posts a client offer to HandleNegotiate with resumeToken.
+func postOffer(t *testing.T, s *BasicWebRTCServer, resumeToken string) (*httptest.ResponseRecorder, negotiateResponse) {
+	t.Helper()
+
+	body, err := json.Marshal(negotiateRequest{SessionDescription: newClientOffer(t), ResumeToken: resumeToken})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	s.HandleNegotiate(rec, httptest.NewRequest(http.MethodPost, "/session", bytes.NewReader(body)))
+
+	var resp negotiateResponse
+	if rec.Code < 300 {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	return rec, resp
+}
+
+func TestBasicWebRTCServerResume(t *testing.T) {
+	s := newResumeServer(t)
+
+	rec, first := postOffer(t, s, "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.NotEmpty(t, first.ResumeToken)
+	assert.Equal(t, webrtc.SDPTypeAnswer, first.Type)
+
+	// Resuming keeps the session: same token, no new peer
+	rec, resumed := postOffer(t, s, first.ResumeToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, first.ResumeToken, resumed.ResumeToken)
+	assert.Equal(t, webrtc.SDPTypeAnswer, resumed.Type)
+	s.RLock()
+	assert.Len(t, s.peers, 1)
+	s.RUnlock()
+}
+
+func TestBasicWebRTCServerResumeUnknownToken(t *testing.T) {
+	s := newResumeServer(t)
+
+	rec, _ := postOffer(t, s, "unknown-token")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	s.RLock()
+	assert.Empty(t, s.peers)
+	s.RUnlock()
+}
+
+func TestBasicWebRTCServerResumeDisabled(t *testing.T) {
+	s := newResumeServer(t)
+	s.config.ResumeGracePeriod = 0
+
+	rec, resp := postOffer(t, s, "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Empty(t, resp.ResumeToken)
+}
@@ -156,9 +156,14 @@ func (s *TwilioMediaServer) Stop() error {
 		s.cancel()
 	}
 
-	// Close all sessions
+	// Close all sessions. The lock is released first because closing a
+	// connection reports the state change back through removeSession.
 	s.sessionsMu.Lock()
-	for _, session := range s.sessions {
+	sessions := s.sessions
+	s.sessions = make(map[string]*TwilioSession)
+	s.sessionsMu.Unlock()
+
+	for _, session := range sessions {
 		if session.Pipeline != nil {
 			session.Pipeline.Stop()
 		}
@@ -166,8 +171,6 @@ func (s *TwilioMediaServer) Stop() error {
 			session.Connection.Close()
 		}
 	}
-	s.sessions = make(map[string]*TwilioSession)
-	s.sessionsMu.Unlock()
 
 	// Shutdown HTTP server
 	if s.server != nil {
@@ -283,13 +286,30 @@ createPipeline:
 }
 
 // forwardAudioToPipeline forwards audio from Twilio connection to pipeline.
+// When the pipeline reports an interruption (e.g. OpenAI Realtime server VAD
+// detects the caller speaking), audio already buffered by Twilio is cleared.
 func (s *TwilioMediaServer) forwardAudioToPipeline(conn *connection.TwilioConnection, p *pipeline.Pipeline) {
-	for msg := range conn.In() {
-		if msg.Type == pipeline.MsgTypeAudio {
-			p.Push(msg)
+	interruptCh := make(chan pipeline.Event, 10)
+	p.Bus().Subscribe(pipeline.EventInterrupted, interruptCh)
+	defer p.Bus().Unsubscribe(pipeline.EventInterrupted, interruptCh)
+
+	in := conn.In()
+	for {
+		select {
+		case msg, ok := <-in:
+			if !ok {
+				log.Printf("[TwilioServer] Audio forwarding stopped for call %s", conn.CallSid())
+				return
+			}
+			if msg.Type == pipeline.MsgTypeAudio {
+				p.Push(msg)
+			}
+		case <-interruptCh:
+			if err := conn.ClearAudio(); err != nil {
+				log.Printf("[TwilioServer] Failed to clear audio for call %s: %v", conn.CallSid(), err)
+			}
 		}
 	}
-	log.Printf("[TwilioServer] Audio forwarding stopped for call %s", conn.CallSid())
 }
 
 // handleTwiML serves TwiML for incoming calls.
@@ -368,6 +388,17 @@ func (s *TwilioMediaServer) GetSession(callSid string) *TwilioSession {
 	return s.sessions[callSid]
 }
 
+// Hangup ends the call with the given call SID from the server side.
+// Queued audio is played out first; the session is then cleaned up through
+// the normal connection-closed path.
+func (s *TwilioMediaServer) Hangup(callSid, reason string) error {
+	session := s.GetSession(callSid)
+	if session == nil {
+		return fmt.Errorf("no active session for call %s", callSid)
+	}
+	return session.Connection.Hangup(reason)
+}
+
 // GetActiveSessions returns all active sessions.
 func (s *TwilioMediaServer) GetActiveSessions() []*TwilioSession {
 	s.sessionsMu.RLock()
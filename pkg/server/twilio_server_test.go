@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/audio"
+	"github.com/realtime-ai/realtime-ai/pkg/connection"
+	"github.com/realtime-ai/realtime-ai/pkg/elements"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMockOpenAIRealtime serves a mock OpenAI Realtime WebSocket. The base64
+// audio of each input_audio_buffer.append is sent on appends, and events
+// written to the returned channel are sent to the client.
+func startMockOpenAIRealtime(t *testing.T) (url string, appends <-chan string, events chan<- []byte) {
+	t.Helper()
+	appendCh := make(chan string, 100)
+	eventCh := make(chan []byte, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				select {
+				case event := <-eventCh:
+					ws.WriteMessage(websocket.TextMessage, event)
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for {
+			_, msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			var event struct {
+				Type  string `json:"type"`
+				Audio string `json:"audio"`
+			}
+			if json.Unmarshal(msg, &event) == nil && event.Type == "input_audio_buffer.append" {
+				appendCh <- event.Audio
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), appendCh, eventCh
+}
+
+// realtimeTwilioFactory builds an OpenAI Realtime pipeline and forwards its
+// audio back to the call, like the twilio-voice-assistant example.
+type realtimeTwilioFactory struct {
+	url string
+}
+
+func (f *realtimeTwilioFactory) CreatePipeline(ctx context.Context, conn *connection.TwilioConnection) (*pipeline.Pipeline, error) {
+	p := pipeline.NewPipeline("twilio-realtime-" + conn.CallSid())
+	p.AddElement(elements.NewOpenAIRealtimeAPIElementWithConfig(elements.OpenAIRealtimeConfig{
+		APIKey:      "test-api-key",
+		BaseURL:     f.url,
+		StreamAudio: true,
+	}))
+	go func() {
+		for {
+			msg, err := p.PullContext(ctx)
+			if err != nil {
+				return
+			}
+			if msg.Type == pipeline.MsgTypeAudio && msg.AudioData != nil {
+				conn.SendMessage(msg)
+			}
+		}
+	}()
+	return p, nil
+}
+
+// readTwilioEvent reads messages from the Twilio side until one with the
+// given event arrives.
+func readTwilioEvent(t *testing.T, ws *websocket.Conn, event string) connection.TwilioMediaMessage {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg connection.TwilioMediaMessage
+		require.NoError(t, ws.ReadJSON(&msg), "waiting for %s event", event)
+		if msg.Event == event {
+			return msg
+		}
+	}
+}
+
+func TestTwilioMediaServer_OpenAIRealtimeBase64Audio(t *testing.T) {
+	realtimeURL, appends, events := startMockOpenAIRealtime(t)
+
+	s := NewTwilioMediaServer(TwilioServerConfig{}, &realtimeTwilioFactory{url: realtimeURL})
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx, s.cancel = ctx, cancel
+	t.Cleanup(func() { s.Stop() })
+	srv := httptest.NewServer(http.HandlerFunc(s.handleWebSocket))
+	t.Cleanup(srv.Close)
+
+	twilio, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { twilio.Close() })
+	require.NoError(t, twilio.WriteJSON(connection.TwilioMediaMessage{
+		Event:     "start",
+		StreamSid: "MZ123",
+		Start:     &connection.TwilioStartPayload{StreamSid: "MZ123", CallSid: "CA123"},
+	}))
+
+	// Caller audio: base64 μ-law from Twilio reaches OpenAI as base64 PCM
+	mulaw := base64.StdEncoding.EncodeToString(make([]byte, 160))
+	var appended string
+	require.Eventually(t, func() bool {
+		if s.GetSession("CA123") == nil {
+			return false
+		}
+		if err := twilio.WriteJSON(connection.TwilioMediaMessage{
+			Event:     "media",
+			StreamSid: "MZ123",
+			Media:     &connection.TwilioMediaPayload{Track: "inbound", Payload: mulaw},
+		}); err != nil {
+			return false
+		}
+		select {
+		case appended = <-appends:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond, "caller audio did not reach OpenAI Realtime")
+	pcm, err := base64.StdEncoding.DecodeString(appended)
+	require.NoError(t, err)
+	assert.NotEmpty(t, pcm)
+	assert.Zero(t, len(pcm)%2, "appended audio is 16-bit PCM")
+
+	// Model audio: base64 24kHz PCM from OpenAI reaches Twilio as base64 μ-law 8kHz
+	reply := make([]byte, 2*480) // 20ms at 24kHz
+	delta, _ := json.Marshal(map[string]interface{}{
+		"type":          "response.audio.delta",
+		"event_id":      "event_1",
+		"response_id":   "resp_1",
+		"item_id":       "item_1",
+		"output_index":  0,
+		"content_index": 0,
+		"delta":         base64.StdEncoding.EncodeToString(reply),
+	})
+	events <- delta
+	media := readTwilioEvent(t, twilio, "media")
+	assert.Equal(t, "MZ123", media.StreamSid)
+	require.NotNil(t, media.Media)
+	payload, err := base64.StdEncoding.DecodeString(media.Media.Payload)
+	require.NoError(t, err)
+	assert.NotEmpty(t, payload)
+	assert.Equal(t, audio.PCMToMuLaw(make([]byte, 2*len(payload))), payload, "silence in, μ-law silence out")
+
+	// The caller talking over the model clears audio buffered by Twilio
+	started, _ := json.Marshal(map[string]interface{}{
+		"type":           "input_audio_buffer.speech_started",
+		"event_id":       "event_2",
+		"audio_start_ms": 100,
+		"item_id":        "item_2",
+	})
+	events <- started
+	cleared := readTwilioEvent(t, twilio, "clear")
+	assert.Equal(t, "MZ123", cleared.StreamSid)
+}
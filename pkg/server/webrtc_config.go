@@ -1,5 +1,16 @@
 package server
 
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
 // BasicWebRTCConfig holds configuration for BasicWebRTCServer.
 // This is a simple WebRTC server without Realtime API protocol support.
 type BasicWebRTCConfig struct {
@@ -11,7 +22,253 @@ type BasicWebRTCConfig struct {
 
 	// Endpoint is the list of candidate addresses (default: []string{"0.0.0.0"})
 	Endpoint []string
+
+	// ICE controls which local interfaces/IPs candidates are gathered on
+	ICE ICEConfig
+
+	// ICEServers are the STUN/TURN servers used by every PeerConnection, e.g.
+	// a TURN relay for clients behind symmetric NAT. TURN URLs ("turn:" or
+	// "turns:") require Username and Credential (default: none)
+	ICEServers []webrtc.ICEServer
+
+	// ICETransportPolicy restricts the candidates used; set it to
+	// webrtc.ICETransportPolicyRelay to force traffic through TURN, e.g. to
+	// test a relay (default: webrtc.ICETransportPolicyAll)
+	ICETransportPolicy webrtc.ICETransportPolicy
+
+	// ResumeGracePeriod is how long a dropped connection is kept alive so the
+	// client can resume it with its resume token (default: 0, disabled)
+	ResumeGracePeriod time.Duration
+
+	// Authenticator validates every negotiation, including resumes, and
+	// rejects it with 401 before a connection is created (default: nil, no
+	// authentication). The identity is available to OnConnectionCreated
+	// through IdentityFromContext.
+	Authenticator Authenticator
 }
 
 // Deprecated: ServerConfig is deprecated. Use BasicWebRTCConfig instead.
 type ServerConfig = BasicWebRTCConfig
+
+// ICEConfig controls ICE candidate gathering.
+//
+// On multi-homed hosts Pion gathers candidates on every interface, including
+// Docker bridges and VPN tunnels the client can never reach. Restrict gathering
+// to the interfaces or IPs that are actually routable, and advertise the public
+// IP of cloud instances with a NAT 1:1 mapping.
+type ICEConfig struct {
+	// Interfaces limits gathering to these interface names (e.g. "eth0"). Empty means all.
+	Interfaces []string
+
+	// ExcludeInterfaces skips interfaces whose name starts with one of these
+	// prefixes (e.g. "docker", "br-", "veth")
+	ExcludeInterfaces []string
+
+	// IPs limits gathering to these local IPs. Empty means all. With a single IP
+	// the ICE UDP port is bound to that IP instead of 0.0.0.0.
+	IPs []string
+
+	// IPv6 also gathers IPv6 candidates (default: IPv4 only)
+	IPv6 bool
+
+	// NAT1To1IPs are the public IPs advertised for this host (1:1 NAT, e.g. an
+	// EC2 elastic IP). Takes precedence over Endpoint.
+	NAT1To1IPs []string
+
+	// NAT1To1Srflx advertises NAT1To1IPs as server reflexive candidates next to
+	// the host candidates, instead of replacing the host candidate IPs
+	NAT1To1Srflx bool
+
+	// GatheringTimeout bounds how long negotiation waits for ICE gathering; the
+	// answer is then sent with the candidates gathered so far (default: 0,
+	// wait until gathering completes)
+	GatheringTimeout time.Duration
+
+	// AnswerOnFirstCandidate sends the answer as soon as the first candidate is
+	// gathered instead of waiting for gathering to complete. Later candidates are
+	// not signaled, so only use it when the first (host or NAT 1:1) candidate is
+	// reachable by clients.
+	AnswerOnFirstCandidate bool
+}
+
+// validateICEServers checks that every TURN server has credentials and that a
+// relay-only policy has a TURN server to relay through. An ICE lite agent
+// gathers no relay candidates, so it cannot be relay-only.
+func validateICEServers(servers []webrtc.ICEServer, policy webrtc.ICETransportPolicy, iceLite bool) error {
+	hasTURN := false
+	for _, server := range servers {
+		if len(server.URLs) == 0 {
+			return fmt.Errorf("ICE server has no URLs")
+		}
+		for _, url := range server.URLs {
+			if !isTURNURL(url) {
+				continue
+			}
+			hasTURN = true
+			if server.Username == "" || server.Credential == nil || server.Credential == "" {
+				return fmt.Errorf("TURN server %s requires a username and credential", url)
+			}
+		}
+	}
+
+	if policy == webrtc.ICETransportPolicyRelay {
+		if !hasTURN {
+			return fmt.Errorf("relay-only ICE transport policy requires a TURN server")
+		}
+		if iceLite {
+			return fmt.Errorf("relay-only ICE transport policy is not supported with ICE lite")
+		}
+	}
+	return nil
+}
+
+// isTURNURL reports whether url is a TURN server URL
+func isTURNURL(url string) bool {
+	url = strings.ToLower(url)
+	return strings.HasPrefix(url, "turn:") || strings.HasPrefix(url, "turns:")
+}
+
+// peerConnectionConfig returns the configuration new PeerConnections are created with
+func peerConnectionConfig(servers []webrtc.ICEServer, policy webrtc.ICETransportPolicy) webrtc.Configuration {
+	return webrtc.Configuration{
+		ICEServers:         append([]webrtc.ICEServer{}, servers...),
+		ICETransportPolicy: policy,
+	}
+}
+
+// applyICEConfig configures candidate gathering on the setting engine.
+// endpoint is the legacy Endpoint option, used as NAT 1:1 IPs when
+// cfg.NAT1To1IPs is empty.
+func applyICEConfig(se *webrtc.SettingEngine, cfg ICEConfig, endpoint []string) {
+	natIPs := cfg.NAT1To1IPs
+	if len(natIPs) == 0 {
+		natIPs = endpoint
+	}
+	if len(natIPs) > 0 {
+		candidateType := webrtc.ICECandidateTypeHost
+		if cfg.NAT1To1Srflx {
+			candidateType = webrtc.ICECandidateTypeSrflx
+		}
+		se.SetNAT1To1IPs(natIPs, candidateType)
+		log.Printf("[WebRTC] NAT 1:1 IPs for ICE: %v (%s)", natIPs, candidateType)
+	}
+
+	se.SetNetworkTypes(cfg.networkTypes())
+
+	if filter := cfg.interfaceFilter(); filter != nil {
+		se.SetInterfaceFilter(filter)
+	}
+	if filter := cfg.ipFilter(); filter != nil {
+		se.SetIPFilter(filter)
+	}
+}
+
+// networkTypes returns the candidate network types to gather
+func (c ICEConfig) networkTypes() []webrtc.NetworkType {
+	types := []webrtc.NetworkType{webrtc.NetworkTypeUDP4, webrtc.NetworkTypeTCP4}
+	if c.IPv6 {
+		types = append(types, webrtc.NetworkTypeUDP6, webrtc.NetworkTypeTCP6)
+	}
+	return types
+}
+
+// interfaceFilter returns nil when no interface restriction is configured
+func (c ICEConfig) interfaceFilter() func(string) bool {
+	if len(c.Interfaces) == 0 && len(c.ExcludeInterfaces) == 0 {
+		return nil
+	}
+	return func(name string) bool {
+		for _, prefix := range c.ExcludeInterfaces {
+			if strings.HasPrefix(name, prefix) {
+				return false
+			}
+		}
+		if len(c.Interfaces) == 0 {
+			return true
+		}
+		for _, iface := range c.Interfaces {
+			if name == iface {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ipFilter returns nil when neither an IP list nor IPv4-only needs filtering
+func (c ICEConfig) ipFilter() func(net.IP) bool {
+	var allowed []net.IP
+	for _, s := range c.IPs {
+		if ip := net.ParseIP(s); ip != nil {
+			allowed = append(allowed, ip)
+		} else {
+			log.Printf("[WebRTC] ignoring invalid ICE IP: %q", s)
+		}
+	}
+	if len(allowed) == 0 && c.IPv6 {
+		return nil
+	}
+	return func(ip net.IP) bool {
+		if !c.IPv6 && ip.To4() == nil {
+			return false
+		}
+		if len(allowed) == 0 {
+			return true
+		}
+		for _, a := range allowed {
+			if a.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// listenIP returns the IP the ICE UDP port binds to: the configured IP when
+// exactly one is set, otherwise 0.0.0.0
+func (c ICEConfig) listenIP() net.IP {
+	if len(c.IPs) == 1 {
+		if ip := net.ParseIP(c.IPs[0]); ip != nil {
+			return ip
+		}
+	}
+	return net.IPv4zero
+}
+
+// gatheringDone returns a channel that is closed when the answer can be sent:
+// ICE gathering is complete, the first candidate was gathered
+// (AnswerOnFirstCandidate) or GatheringTimeout elapsed. It must be called
+// before SetLocalDescription, which starts gathering.
+func (c ICEConfig) gatheringDone(pc *webrtc.PeerConnection) <-chan struct{} {
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() { once.Do(func() { close(done) }) }
+
+	if c.AnswerOnFirstCandidate {
+		pc.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+			if candidate != nil {
+				finish()
+			}
+		})
+	}
+	complete := webrtc.GatheringCompletePromise(pc)
+
+	go func() {
+		var timeout <-chan time.Time
+		if c.GatheringTimeout > 0 {
+			timer := time.NewTimer(c.GatheringTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-complete:
+		case <-done:
+		case <-timeout:
+			log.Printf("[WebRTC] ICE gathering not complete after %v, answering with gathered candidates", c.GatheringTimeout)
+		}
+		finish()
+	}()
+
+	return done
+}
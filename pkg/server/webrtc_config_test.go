@@ -0,0 +1,202 @@
+package server
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTURNServer = webrtc.ICEServer{
+	URLs:       []string{"turn:turn.example.com:3478?transport=udp", "turns:turn.example.com:5349"},
+	Username:   "user",
+	Credential: "secret",
+}
+
+func TestPeerConnectionConfigWithTURN(t *testing.T) {
+	stun := webrtc.ICEServer{URLs: []string{"stun:stun.example.com:3478"}}
+
+	config := DefaultWebRTCRealtimeConfig()
+	config.ICELite = false
+	config.ICEServers = []webrtc.ICEServer{stun, testTURNServer}
+	config.ICETransportPolicy = webrtc.ICETransportPolicyRelay
+	realtime := NewWebRTCRealtimeServer(config)
+
+	require.NoError(t, validateICEServers(config.ICEServers, config.ICETransportPolicy, config.ICELite))
+	pcConfig := realtime.peerConnectionConfig()
+	assert.Equal(t, []webrtc.ICEServer{stun, testTURNServer}, pcConfig.ICEServers)
+	assert.Equal(t, webrtc.ICETransportPolicyRelay, pcConfig.ICETransportPolicy)
+
+	basic := NewBasicWebRTCServer(&ServerConfig{
+		ICEServers: []webrtc.ICEServer{testTURNServer},
+	})
+	pcConfig = basic.peerConnectionConfig()
+	assert.Equal(t, []webrtc.ICEServer{testTURNServer}, pcConfig.ICEServers)
+	assert.Equal(t, webrtc.ICETransportPolicyAll, pcConfig.ICETransportPolicy)
+}
+
+func TestValidateICEServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []webrtc.ICEServer
+		policy  webrtc.ICETransportPolicy
+		iceLite bool
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "stun without credentials", servers: []webrtc.ICEServer{{URLs: []string{"stun:stun.example.com"}}}},
+		{name: "turn with credentials", servers: []webrtc.ICEServer{testTURNServer}},
+		{name: "turn without username", servers: []webrtc.ICEServer{{URLs: []string{"turn:turn.example.com"}, Credential: "secret"}}, wantErr: true},
+		{name: "turns without credential", servers: []webrtc.ICEServer{{URLs: []string{"TURNS:turn.example.com"}, Username: "user"}}, wantErr: true},
+		{name: "no urls", servers: []webrtc.ICEServer{{}}, wantErr: true},
+		{name: "relay without turn", servers: []webrtc.ICEServer{{URLs: []string{"stun:stun.example.com"}}}, policy: webrtc.ICETransportPolicyRelay, wantErr: true},
+		{name: "relay with ice lite", servers: []webrtc.ICEServer{testTURNServer}, policy: webrtc.ICETransportPolicyRelay, iceLite: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateICEServers(tt.servers, tt.policy, tt.iceLite)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// silentSTUNServer returns a STUN server that never answers, so server
+// reflexive gathering only ends at pion's own timeout of several seconds.
+func silentSTUNServer(t *testing.T) webrtc.ICEServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return webrtc.ICEServer{URLs: []string{"stun:" + conn.LocalAddr().String()}}
+}
+
+// startAnswer answers a client offer on a new PeerConnection and returns it
+// with the gatheringDone channel of ice.
+func startAnswer(t *testing.T, ice ICEConfig, servers ...webrtc.ICEServer) (*webrtc.PeerConnection, <-chan struct{}) {
+	t.Helper()
+
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{ICEServers: servers})
+	require.NoError(t, err)
+	t.Cleanup(func() { pc.Close() })
+	require.NoError(t, pc.SetRemoteDescription(newClientOffer(t)))
+	answer, err := pc.CreateAnswer(nil)
+	require.NoError(t, err)
+
+	done := ice.gatheringDone(pc)
+	require.NoError(t, pc.SetLocalDescription(answer))
+	return pc, done
+}
+
+func waitGathering(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for gatheringDone")
+	}
+}
+
+func TestGatheringDone(t *testing.T) {
+	t.Run("waits for gathering to complete", func(t *testing.T) {
+		pc, done := startAnswer(t, ICEConfig{})
+		waitGathering(t, done)
+		assert.Equal(t, webrtc.ICEGatheringStateComplete, pc.ICEGatheringState())
+	})
+
+	t.Run("timeout answers with gathered candidates", func(t *testing.T) {
+		pc, done := startAnswer(t, ICEConfig{GatheringTimeout: 100 * time.Millisecond}, silentSTUNServer(t))
+		waitGathering(t, done)
+		assert.Equal(t, webrtc.ICEGatheringStateGathering, pc.ICEGatheringState())
+	})
+
+	t.Run("answer on first candidate", func(t *testing.T) {
+		pc, done := startAnswer(t, ICEConfig{AnswerOnFirstCandidate: true}, silentSTUNServer(t))
+		waitGathering(t, done)
+		assert.Equal(t, webrtc.ICEGatheringStateGathering, pc.ICEGatheringState())
+		assert.True(t, strings.Contains(pc.LocalDescription().SDP, "a=candidate:"), "answer should carry the first candidate")
+	})
+}
+
+// candidateAddresses answers a client offer on a PeerConnection whose setting
+// engine was configured by applyICEConfig and returns the address of every
+// candidate in the answer. Loopback candidates are included so the test does
+// not depend on the host's interfaces.
+func candidateAddresses(t *testing.T, ice ICEConfig, endpoint []string) []string {
+	t.Helper()
+
+	se := webrtc.SettingEngine{}
+	se.SetIncludeLoopbackCandidate(true)
+	applyICEConfig(&se, ice, endpoint)
+	api := webrtc.NewAPI(webrtc.WithSettingEngine(se))
+
+	pc, err := api.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	t.Cleanup(func() { pc.Close() })
+	require.NoError(t, pc.SetRemoteDescription(newClientOffer(t)))
+	answer, err := pc.CreateAnswer(nil)
+	require.NoError(t, err)
+	done := ice.gatheringDone(pc)
+	require.NoError(t, pc.SetLocalDescription(answer))
+	waitGathering(t, done)
+
+	var addresses []string
+	for _, line := range strings.Split(pc.LocalDescription().SDP, "\r\n") {
+		// a=candidate:<foundation> <component> <protocol> <priority> <address> <port> typ <type>
+		if fields := strings.Fields(line); len(fields) >= 8 && strings.HasPrefix(fields[0], "a=candidate:") {
+			addresses = append(addresses, fields[4])
+		}
+	}
+	return addresses
+}
+
+func TestApplyICEConfig(t *testing.T) {
+	t.Run("ip filter", func(t *testing.T) {
+		addresses := candidateAddresses(t, ICEConfig{IPs: []string{"127.0.0.1"}}, nil)
+		require.NotEmpty(t, addresses)
+		for _, address := range addresses {
+			assert.Equal(t, "127.0.0.1", address)
+		}
+	})
+
+	t.Run("interface filter", func(t *testing.T) {
+		addresses := candidateAddresses(t, ICEConfig{Interfaces: []string{"lo"}}, nil)
+		require.NotEmpty(t, addresses)
+		for _, address := range addresses {
+			assert.Equal(t, "127.0.0.1", address, "IPv4 candidates on lo only")
+		}
+
+		assert.Empty(t, candidateAddresses(t, ICEConfig{ExcludeInterfaces: []string{""}}, nil),
+			"excluding every interface gathers no candidates")
+	})
+
+	t.Run("nat 1:1", func(t *testing.T) {
+		addresses := candidateAddresses(t, ICEConfig{IPs: []string{"127.0.0.1"}, NAT1To1IPs: []string{"203.0.113.7"}}, nil)
+		require.NotEmpty(t, addresses)
+		for _, address := range addresses {
+			assert.Equal(t, "203.0.113.7", address, "host candidates advertise the public IP")
+		}
+	})
+
+	t.Run("endpoint as nat 1:1", func(t *testing.T) {
+		addresses := candidateAddresses(t, ICEConfig{IPs: []string{"127.0.0.1"}}, []string{"198.51.100.9"})
+		require.NotEmpty(t, addresses)
+		for _, address := range addresses {
+			assert.Equal(t, "198.51.100.9", address)
+		}
+	})
+
+	t.Run("nat 1:1 srflx", func(t *testing.T) {
+		addresses := candidateAddresses(t, ICEConfig{IPs: []string{"127.0.0.1"}, NAT1To1IPs: []string{"203.0.113.7"}, NAT1To1Srflx: true}, nil)
+		assert.Contains(t, addresses, "127.0.0.1", "host candidates are kept")
+		assert.Contains(t, addresses, "203.0.113.7", "public IP is advertised as server reflexive")
+	})
+}
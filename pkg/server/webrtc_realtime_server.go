@@ -9,18 +9,22 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/pion/webrtc/v4"
 	"github.com/realtime-ai/realtime-ai/pkg/connection"
+	"github.com/realtime-ai/realtime-ai/pkg/elements"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
 	"github.com/realtime-ai/realtime-ai/pkg/realtimeapi"
 	"github.com/realtime-ai/realtime-ai/pkg/realtimeapi/bridge"
 	"github.com/realtime-ai/realtime-ai/pkg/realtimeapi/events"
 )
 
-// PipelineFactory creates a pipeline for a session.
+// PipelineFactory creates a pipeline for a session. It runs once the WebRTC
+// connection is established, so session.OutputSampleRate reports the
+// negotiated clock rate that pipeline audio output must be resampled to.
 type PipelineFactory func(ctx context.Context, session *realtimeapi.Session) (*pipeline.Pipeline, error)
 
 // WebRTCRealtimeConfig holds configuration for WebRTCRealtimeServer.
@@ -28,14 +32,59 @@ type WebRTCRealtimeConfig struct {
 	// WebRTC configuration
 	RTCUDPPort int
 	ICELite    bool
-	Endpoint   []string
-
-	// Realtime API configuration
+	Endpoint   []string  // Public IPs advertised as host candidates (NAT 1:1)
+	ICE        ICEConfig // Candidate filtering (interfaces, IPs, IPv4-only)
+
+	// STUN/TURN servers for every PeerConnection, e.g. a TURN relay for
+	// clients behind symmetric NAT. TURN URLs require Username and Credential.
+	// ICETransportPolicy webrtc.ICETransportPolicyRelay forces relayed traffic.
+	ICEServers         []webrtc.ICEServer
+	ICETransportPolicy webrtc.ICETransportPolicy
+
+	// Realtime API configuration. Clients pick a model per connection with the
+	// "model" query parameter of the negotiate request; it must be one of
+	// AllowedModels (any model when empty) and defaults to DefaultModel.
+	// The pipeline factory reads the choice from session.Config.Model.
 	DefaultModel  string
 	AllowedModels []string
 
-	// Authentication (optional)
+	// Authentication (optional). Authenticator runs on every negotiation and
+	// rejects it with 401 before a session or pipeline is created; the
+	// identity it returns is available to the pipeline factory through
+	// IdentityFromContext(session.Context()). AuthValidator is a simpler
+	// check of the raw Authorization header, used when Authenticator is nil.
+	Authenticator Authenticator
 	AuthValidator func(token string) bool
+
+	// MaxConcurrentSessions limits the number of live sessions (and pipelines).
+	// New negotiations beyond the limit are rejected with 503.
+	// 0 means no limit.
+	MaxConcurrentSessions int
+
+	// EventBridge selects which pipeline events are sent to clients as
+	// OpenAI Realtime server events.
+	EventBridge bridge.Config
+
+	// PrewarmOnConnect opens LLM/TTS provider connections as soon as the
+	// session's pipeline starts, so the first response skips the handshake.
+	PrewarmOnConnect bool
+
+	// MultiTrackAudio accepts several inbound audio tracks per connection,
+	// e.g. a microphone and system audio. Audio from every track is pushed to
+	// the pipeline with the track ID in the pipeline.AttrAudioTrack attribute;
+	// route tracks to their own inputs (or a mixer) with
+	// Pipeline.SetAudioTrackInput. The pipeline factory can read the tracks
+	// offered at connect time with AudioTracks, and tracks added later (via a
+	// PATCH renegotiation) are published as pipeline.EventAudioTrackAdded.
+	// When false only the first audio track is used.
+	MultiTrackAudio bool
+
+	// ResumeGracePeriod keeps a session whose connection dropped (ICE
+	// disconnected or failed) alive, with its pipeline running, for this long.
+	// Within the window the client can resume it by posting a new offer with
+	// the session's resume_token, as with BasicWebRTCServer; the session is
+	// closed once it elapses. 0 closes the session as soon as the connection drops.
+	ResumeGracePeriod time.Duration
 }
 
 // DefaultWebRTCRealtimeConfig returns default configuration.
@@ -45,6 +94,7 @@ func DefaultWebRTCRealtimeConfig() *WebRTCRealtimeConfig {
 		ICELite:       true,
 		DefaultModel:  "gemini-2.5-flash-native-audio-preview-12-2025",
 		AllowedModels: []string{"gemini-2.0-flash", "gemini-2.5-flash-native-audio-preview-12-2025"},
+		EventBridge:   bridge.OpenAIConfig(),
 	}
 }
 
@@ -61,10 +111,22 @@ type WebRTCRealtimeServer struct {
 
 	// Session management
 	sessions map[string]*realtimeapi.Session
+	pending  int // Slots reserved by negotiations that have not registered a session yet
+
+	// Connections by session ID, for AudioTracks
+	connections map[string]connection.WebRTCRealtimeConnection
+
+	// Session IDs by resume token, for resumption and renegotiation
+	resumeTokens map[string]string
+
+	// Audio diagnostics by session ID, kept for a while after the session ends
+	diagnostics map[string]*elements.LoopbackDiagnosticElement
 
 	// Connection callbacks
 	onConnectionCreated func(ctx context.Context, conn connection.WebRTCRealtimeConnection, session *realtimeapi.Session)
 	onConnectionError   func(ctx context.Context, conn connection.WebRTCRealtimeConnection, err error)
+	onConnectionState   func(ctx context.Context, conn connection.WebRTCRealtimeConnection, state connection.ConnectionState)
+	onSessionLimit      func(active, limit int)
 }
 
 // NewWebRTCRealtimeServer creates a new WebRTC Realtime server.
@@ -74,11 +136,17 @@ func NewWebRTCRealtimeServer(config *WebRTCRealtimeConfig) *WebRTCRealtimeServer
 	}
 
 	return &WebRTCRealtimeServer{
-		config:   config,
-		sessions: make(map[string]*realtimeapi.Session),
+		config:       config,
+		sessions:     make(map[string]*realtimeapi.Session),
+		connections:  make(map[string]connection.WebRTCRealtimeConnection),
+		resumeTokens: make(map[string]string),
+		diagnostics:  make(map[string]*elements.LoopbackDiagnosticElement),
 		onConnectionCreated: func(ctx context.Context, conn connection.WebRTCRealtimeConnection, session *realtimeapi.Session) {
 		},
 		onConnectionError: func(ctx context.Context, conn connection.WebRTCRealtimeConnection, err error) {},
+		onConnectionState: func(ctx context.Context, conn connection.WebRTCRealtimeConnection, state connection.ConnectionState) {
+		},
+		onSessionLimit: func(active, limit int) {},
 	}
 }
 
@@ -97,28 +165,38 @@ func (s *WebRTCRealtimeServer) OnConnectionError(f func(ctx context.Context, con
 	s.onConnectionError = f
 }
 
+// OnConnectionStateChange sets the callback for connection state changes. A
+// dropped connection waiting for the client to resume is reported as
+// connection.ConnectionStateReconnecting (see ResumeGracePeriod).
+func (s *WebRTCRealtimeServer) OnConnectionStateChange(f func(ctx context.Context, conn connection.WebRTCRealtimeConnection, state connection.ConnectionState)) {
+	s.onConnectionState = f
+}
+
+// OnSessionLimit sets the callback invoked each time a negotiation is rejected
+// because MaxConcurrentSessions is reached, so operators can alert or scale out.
+func (s *WebRTCRealtimeServer) OnSessionLimit(f func(active, limit int)) {
+	s.onSessionLimit = f
+}
+
 // Start initializes the WebRTC API.
 func (s *WebRTCRealtimeServer) Start() error {
+	if err := validateICEServers(s.config.ICEServers, s.config.ICETransportPolicy, s.config.ICELite); err != nil {
+		return fmt.Errorf("invalid ICE server configuration: %w", err)
+	}
+
 	settingEngine := webrtc.SettingEngine{}
 
 	if s.config.ICELite {
 		settingEngine.SetLite(true)
 	}
 
-	// Set NAT1To1IPs for ICE candidates (only if explicitly configured)
-	if len(s.config.Endpoint) > 0 {
-		settingEngine.SetNAT1To1IPs(s.config.Endpoint, webrtc.ICECandidateTypeHost)
-		log.Printf("[WebRTCRealtimeServer] using IPs for ICE: %v", s.config.Endpoint)
-	}
+	// NAT 1:1 mapping, network types and interface/IP filters
+	applyICEConfig(&settingEngine, s.config.ICE, s.config.Endpoint)
 
 	settingEngine.SetFireOnTrackBeforeFirstRTP(true)
-	settingEngine.SetNetworkTypes([]webrtc.NetworkType{
-		webrtc.NetworkTypeUDP4,
-		webrtc.NetworkTypeTCP4,
-	})
 
 	udpListener, err := net.ListenUDP("udp", &net.UDPAddr{
-		IP:   net.ParseIP("0.0.0.0"),
+		IP:   s.config.ICE.listenIP(),
 		Port: s.config.RTCUDPPort,
 	})
 	if err != nil {
@@ -144,10 +222,17 @@ func (s *WebRTCRealtimeServer) Start() error {
 }
 
 // HandleNegotiate handles WebRTC signaling at /session endpoint.
+// POST creates a session from the client's SDP offer; the answer carries the
+// session's secret "resume_token". POST with that resume_token in the body
+// resumes the session on a new PeerConnection after the client lost its
+// connection (see WebRTCRealtimeConfig.ResumeGracePeriod). PATCH with the
+// resume_token renegotiates the session, e.g. to add an audio track (see
+// WebRTCRealtimeConfig.MultiTrackAudio). With authentication enabled, only
+// the client the session was created for can resume or renegotiate it.
 func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Request) {
 	// CORS headers
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, PATCH, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 	if r.Method == http.MethodOptions {
@@ -155,41 +240,207 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if r.Method != http.MethodPost {
+	if r.Method != http.MethodPost && r.Method != http.MethodPatch {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Optional authentication
-	if s.config.AuthValidator != nil {
-		token := r.Header.Get("Authorization")
-		if !s.config.AuthValidator(token) {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+	ctx, ok := authenticate(w, r, s.authenticator())
+	if !ok {
+		return
 	}
 
-	// Parse SDP offer
+	req, ok := readNegotiateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodPatch {
+		s.renegotiateSession(ctx, w, req)
+		return
+	}
+
+	if req.ResumeToken != "" {
+		s.resumeSession(ctx, w, req)
+		return
+	}
+
+	// Get model from query parameter
+	model := r.URL.Query().Get("model")
+	if model == "" {
+		model = s.config.DefaultModel
+	}
+
+	// Validate model
+	if !s.isModelAllowed(model) {
+		http.Error(w, fmt.Sprintf("Model not allowed: %s", model), http.StatusBadRequest)
+		return
+	}
+
+	s.negotiateSession(ctx, w, req.SessionDescription, model, s.pipelineFactory, nil)
+}
+
+// readNegotiateRequest parses the SDP offer and optional resume token in the
+// body of r, or writes 400 and returns false.
+func readNegotiateRequest(w http.ResponseWriter, r *http.Request) (negotiateRequest, bool) {
+	var req negotiateRequest
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return req, false
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Failed to parse offer", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// resumableConnection returns the connection of the session whose resume
+// token is token, or writes an error and returns nil if there is none or it
+// belongs to another client than the one authenticated in ctx.
+func (s *WebRTCRealtimeServer) resumableConnection(ctx context.Context, w http.ResponseWriter, token string) connection.WebRTCRealtimeConnection {
+	s.RLock()
+	sessionID := s.resumeTokens[token]
+	session := s.sessions[sessionID]
+	conn := s.connections[sessionID]
+	s.RUnlock()
+	if token == "" || session == nil || conn == nil {
+		http.Error(w, "Unknown or expired resume token", http.StatusNotFound)
+		return nil
+	}
+	if !sameIdentity(ctx, session.Context()) {
+		log.Printf("[WebRTCRealtimeServer] session %s: rejected resume token from another client", sessionID)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil
+	}
+	return conn
+}
+
+// renegotiateSession applies a new SDP offer to the session owning the
+// request's resume token and returns the answer.
+func (s *WebRTCRealtimeServer) renegotiateSession(ctx context.Context, w http.ResponseWriter, req negotiateRequest) {
+	conn := s.resumableConnection(ctx, w, req.ResumeToken)
+	if conn == nil {
 		return
 	}
+	sessionID := conn.SessionID()
 
-	var offer webrtc.SessionDescription
-	if err := json.Unmarshal(body, &offer); err != nil {
-		http.Error(w, "Failed to parse offer", http.StatusBadRequest)
+	answer, err := conn.Renegotiate(req.SessionDescription)
+	if err != nil {
+		log.Printf("[WebRTCRealtimeServer] session %s failed to renegotiate: %v", sessionID, err)
+		http.Error(w, "Failed to renegotiate", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(realtimeNegotiateResponse{
+		SessionID:   sessionID,
+		ResumeToken: req.ResumeToken,
+		SDP:         answer,
+	})
+	log.Printf("[WebRTCRealtimeServer] session %s renegotiated", sessionID)
+}
+
+// resumeSession moves the session owning the request's resume token onto a
+// new PeerConnection negotiated from the client's SDP offer. The session and
+// its pipeline are kept, so the conversation continues where the dropped
+// connection left it.
+func (s *WebRTCRealtimeServer) resumeSession(ctx context.Context, w http.ResponseWriter, req negotiateRequest) {
+	if s.config.ResumeGracePeriod <= 0 {
+		http.Error(w, "Resumption is disabled", http.StatusBadRequest)
 		return
 	}
 
-	ctx := context.Background()
+	conn := s.resumableConnection(ctx, w, req.ResumeToken)
+	if conn == nil {
+		return
+	}
+	sessionID := conn.SessionID()
 
-	// Create PeerConnection
-	pc, err := s.api.NewPeerConnection(webrtc.Configuration{
-		ICEServers: []webrtc.ICEServer{},
+	pc, err := s.api.NewPeerConnection(s.peerConnectionConfig())
+	if err != nil {
+		log.Printf("[WebRTCRealtimeServer] Failed to create peer connection: %v", err)
+		s.onConnectionError(ctx, conn, err)
+		http.Error(w, "Failed to create peer connection", http.StatusInternalServerError)
+		return
+	}
+
+	if err := conn.Resume(pc); err != nil {
+		pc.Close()
+		log.Printf("[WebRTCRealtimeServer] session %s failed to resume: %v", sessionID, err)
+		http.Error(w, "Unknown or expired resume token", http.StatusNotFound)
+		return
+	}
+
+	answer, err := negotiate(pc, req.SessionDescription, s.config.ICE)
+	if err != nil {
+		log.Printf("[WebRTCRealtimeServer] session %s failed to negotiate resume: %v", sessionID, err)
+		s.onConnectionError(ctx, conn, err)
+		http.Error(w, "Failed to negotiate", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(realtimeNegotiateResponse{
+		SessionID:   sessionID,
+		ResumeToken: req.ResumeToken,
+		SDP:         answer,
 	})
+	log.Printf("[WebRTCRealtimeServer] session %s resumed", sessionID)
+}
+
+// realtimeNegotiateResponse is the body of a successful negotiation.
+type realtimeNegotiateResponse struct {
+	SessionID   string                     `json:"session_id"`
+	ResumeToken string                     `json:"resume_token"`
+	SDP         *webrtc.SessionDescription `json:"sdp"`
+}
+
+// peerConnectionConfig returns the configuration new PeerConnections are created with.
+func (s *WebRTCRealtimeServer) peerConnectionConfig() webrtc.Configuration {
+	return peerConnectionConfig(s.config.ICEServers, s.config.ICETransportPolicy)
+}
+
+// authenticator returns the configured Authenticator, or one wrapping
+// AuthValidator, or nil if authentication is disabled.
+func (s *WebRTCRealtimeServer) authenticator() Authenticator {
+	if s.config.Authenticator != nil {
+		return s.config.Authenticator
+	}
+	if s.config.AuthValidator != nil {
+		return validatorAuthenticator(s.config.AuthValidator)
+	}
+	return nil
+}
+
+// isModelAllowed checks if a model is in the allowed list.
+func (s *WebRTCRealtimeServer) isModelAllowed(model string) bool {
+	if len(s.config.AllowedModels) == 0 {
+		return true
+	}
+	return slices.Contains(s.config.AllowedModels, model)
+}
+
+// negotiateSession answers the SDP offer and creates a session for model
+// whose pipeline is built by factory. The session context derives from ctx.
+// diag, if not nil, records pipeline output sends.
+func (s *WebRTCRealtimeServer) negotiateSession(ctx context.Context, w http.ResponseWriter, offer webrtc.SessionDescription, model string, factory PipelineFactory, diag *elements.LoopbackDiagnosticElement) {
+	// Reserve a session slot before allocating any resources
+	if active, ok := s.reserveSession(); !ok {
+		limit := s.config.MaxConcurrentSessions
+		log.Printf("[WebRTCRealtimeServer] session limit reached (%d/%d), rejecting negotiation", active, limit)
+		s.onSessionLimit(active, limit)
+		http.Error(w, fmt.Sprintf("Server at capacity (%d concurrent sessions), try again later", limit), http.StatusServiceUnavailable)
+		return
+	}
+
+	// Create PeerConnection
+	pc, err := s.api.NewPeerConnection(s.peerConnectionConfig())
 	if err != nil {
 		log.Printf("[WebRTCRealtimeServer] Failed to create peer connection: %v", err)
+		s.releaseReservation()
 		s.onConnectionError(ctx, nil, err)
 		http.Error(w, "Failed to create peer connection", http.StatusInternalServerError)
 		return
@@ -200,6 +451,7 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 	if err != nil {
 		log.Printf("[WebRTCRealtimeServer] Failed to create connection: %v", err)
 		pc.Close()
+		s.releaseReservation()
 		s.onConnectionError(ctx, nil, err)
 		http.Error(w, "Failed to create connection", http.StatusInternalServerError)
 		return
@@ -210,7 +462,7 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 
 	// Create session configuration
 	sessionConfig := realtimeapi.DefaultSessionConfig()
-	sessionConfig.Model = s.config.DefaultModel
+	sessionConfig.Model = model
 
 	// Create transport adapter that wraps the connection
 	transport := &webrtcConnectionTransport{conn: conn}
@@ -218,17 +470,29 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 	// Create session with transport
 	session := realtimeapi.NewSessionWithID(ctx, conn.SessionID(), transport, sessionConfig)
 
-	// Register session
+	// Register session, turning the reservation into a live session
 	s.Lock()
 	s.sessions[session.ID] = session
+	s.connections[session.ID] = conn
+	s.resumeTokens[conn.ResumeToken()] = session.ID
+	s.pending--
+	if diag != nil {
+		s.diagnostics[session.ID] = diag
+	}
 	s.Unlock()
 
 	// Set up cleanup on session close
 	session.SetOnClose(func(sess *realtimeapi.Session) {
 		s.Lock()
 		delete(s.sessions, sess.ID)
+		delete(s.connections, sess.ID)
+		delete(s.resumeTokens, conn.ResumeToken())
 		s.Unlock()
 		conn.Close()
+
+		if diag != nil {
+			s.forgetDiagnostic(sess.ID)
+		}
 	})
 
 	// Create event handler that bridges connection events to session
@@ -236,8 +500,14 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 		conn:    conn,
 		session: session,
 		server:  s,
+		factory: factory,
+		diag:    diag,
+	}
+	if s.config.MultiTrackAudio {
+		conn.RegisterEventHandler(&webrtcRealtimeTrackEventHandler{webrtcRealtimeEventHandler: handler})
+	} else {
+		conn.RegisterEventHandler(handler)
 	}
-	conn.RegisterEventHandler(handler)
 
 	// Start connection (sets up DataChannel and audio track handlers)
 	if err := conn.Start(ctx); err != nil {
@@ -268,6 +538,7 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	gatherDone := s.config.ICE.gatheringDone(pc)
 	if err := pc.SetLocalDescription(answer); err != nil {
 		log.Printf("[WebRTCRealtimeServer] Failed to set local description: %v", err)
 		s.onConnectionError(ctx, conn, err)
@@ -277,21 +548,17 @@ func (s *WebRTCRealtimeServer) HandleNegotiate(w http.ResponseWriter, r *http.Re
 	}
 
 	// Wait for ICE gathering
-	gatherComplete := webrtc.GatheringCompletePromise(pc)
-	<-gatherComplete
+	<-gatherDone
 
 	// Return answer
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
-	response := struct {
-		SessionID string                     `json:"session_id"`
-		SDP       *webrtc.SessionDescription `json:"sdp"`
-	}{
-		SessionID: session.ID,
-		SDP:       pc.LocalDescription(),
-	}
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(realtimeNegotiateResponse{
+		SessionID:   session.ID,
+		ResumeToken: conn.ResumeToken(),
+		SDP:         pc.LocalDescription(),
+	})
 
 	log.Printf("[WebRTCRealtimeServer] session %s created", session.ID)
 }
@@ -303,6 +570,48 @@ func (s *WebRTCRealtimeServer) GetSession(sessionID string) *realtimeapi.Session
 	return s.sessions[sessionID]
 }
 
+// AudioTracks returns the inbound audio tracks of a session, in the order they
+// were added, or nil if the session does not exist. Tracks in the client's
+// initial offer are known before the pipeline factory runs.
+func (s *WebRTCRealtimeServer) AudioTracks(sessionID string) []connection.AudioTrackInfo {
+	s.RLock()
+	conn := s.connections[sessionID]
+	s.RUnlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.AudioTracks()
+}
+
+// ActiveSessions returns the number of live sessions, including negotiations in progress.
+func (s *WebRTCRealtimeServer) ActiveSessions() int {
+	s.RLock()
+	defer s.RUnlock()
+	return len(s.sessions) + s.pending
+}
+
+// reserveSession reserves a slot for a new session. It returns the number of
+// sessions, including negotiations in progress, before the reservation and
+// false when MaxConcurrentSessions is reached.
+func (s *WebRTCRealtimeServer) reserveSession() (int, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	active := len(s.sessions) + s.pending
+	if limit := s.config.MaxConcurrentSessions; limit > 0 && active >= limit {
+		return active, false
+	}
+	s.pending++
+	return active, true
+}
+
+// releaseReservation gives back a slot whose negotiation failed before a session was registered.
+func (s *WebRTCRealtimeServer) releaseReservation() {
+	s.Lock()
+	s.pending--
+	s.Unlock()
+}
+
 // webrtcConnectionTransport adapts WebRTCRealtimeConnection to realtimeapi.AudioTransport.
 type webrtcConnectionTransport struct {
 	conn connection.WebRTCRealtimeConnection
@@ -320,6 +629,10 @@ func (t *webrtcConnectionTransport) SupportsRTPAudio() bool {
 	return t.conn.SupportsRTPAudio()
 }
 
+func (t *webrtcConnectionTransport) SampleRate() int {
+	return t.conn.SampleRate()
+}
+
 func (t *webrtcConnectionTransport) Close() error {
 	return t.conn.Close()
 }
@@ -329,27 +642,100 @@ type webrtcRealtimeEventHandler struct {
 	conn    connection.WebRTCRealtimeConnection
 	session *realtimeapi.Session
 	server  *WebRTCRealtimeServer
+	factory PipelineFactory
+
+	// diag is set for /diagnose sessions and times each audio send
+	diag *elements.LoopbackDiagnosticElement
 
 	pipelineCreated bool
+
+	// Resumption (see WebRTCRealtimeConfig.ResumeGracePeriod). reconnectGen
+	// identifies the current timer so a stale one does not close the session.
+	mu             sync.Mutex
+	connected      bool
+	reconnectTimer *time.Timer
+	reconnectGen   int
 }
 
 func (h *webrtcRealtimeEventHandler) OnConnectionStateChange(state webrtc.PeerConnectionState) {
 	log.Printf("[WebRTCRealtimeServer] session %s connection state: %s", h.session.ID, state.String())
+	connState := connection.PeerConnectionState(state)
 
 	switch state {
 	case webrtc.PeerConnectionStateConnected:
+		h.mu.Lock()
+		reconnected := h.connected
+		h.connected = true
+		if h.reconnectTimer != nil {
+			h.reconnectTimer.Stop()
+			h.reconnectTimer = nil
+		}
+		h.mu.Unlock()
+
+		// A resumed connection continues with the running session and pipeline
+		if reconnected {
+			log.Printf("[WebRTCRealtimeServer] session %s reconnected", h.session.ID)
+			break
+		}
+
 		// Start session and create pipeline
 		if err := h.session.Start(); err != nil {
 			log.Printf("[WebRTCRealtimeServer] session %s failed to start: %v", h.session.ID, err)
 		}
 
 		// Create pipeline if factory is set
-		if h.server.pipelineFactory != nil && !h.pipelineCreated {
+		if h.factory != nil && !h.pipelineCreated {
 			h.pipelineCreated = true
 			go h.setupPipeline()
 		}
 
-	case webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
+	case webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateFailed:
+		if h.waitForResume() {
+			connState = connection.ConnectionStateReconnecting
+			break
+		}
+		h.session.Close()
+
+	case webrtc.PeerConnectionStateClosed:
+		h.session.Close()
+	}
+
+	h.server.onConnectionState(h.session.Context(), h.conn, connState)
+}
+
+// waitForResume keeps the session and its pipeline alive for
+// ResumeGracePeriod after the connection dropped, so the client can resume it.
+// It returns false if resumption is disabled.
+func (h *webrtcRealtimeEventHandler) waitForResume() bool {
+	timeout := h.server.config.ResumeGracePeriod
+	if timeout <= 0 {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.reconnectTimer == nil {
+		log.Printf("[WebRTCRealtimeServer] session %s connection lost, waiting %v for resume", h.session.ID, timeout)
+		h.reconnectGen++
+		gen := h.reconnectGen
+		h.reconnectTimer = time.AfterFunc(timeout, func() {
+			h.reconnectExpired(gen)
+		})
+	}
+	return true
+}
+
+// reconnectExpired closes the session if the client did not resume it in time.
+func (h *webrtcRealtimeEventHandler) reconnectExpired(gen int) {
+	h.mu.Lock()
+	expired := h.reconnectTimer != nil && h.reconnectGen == gen
+	if expired {
+		h.reconnectTimer = nil
+	}
+	h.mu.Unlock()
+
+	if expired {
+		log.Printf("[WebRTCRealtimeServer] session %s resume grace period expired", h.session.ID)
 		h.session.Close()
 	}
 }
@@ -358,7 +744,7 @@ func (h *webrtcRealtimeEventHandler) setupPipeline() {
 	ctx := h.session.Context()
 
 	// Create pipeline
-	p, err := h.server.pipelineFactory(ctx, h.session)
+	p, err := h.factory(ctx, h.session)
 	if err != nil {
 		log.Printf("[WebRTCRealtimeServer] session %s failed to create pipeline: %v", h.session.ID, err)
 		return
@@ -376,9 +762,14 @@ func (h *webrtcRealtimeEventHandler) setupPipeline() {
 	} else {
 		eb = bridge.NewEventBridge(p.Bus(), h.session, h.session.ID)
 	}
+	eb.SetConfig(h.server.config.EventBridge)
 
 	h.session.SetEventBridge(eb)
 
+	if h.server.config.PrewarmOnConnect {
+		p.SetPrewarmOnConnect(true)
+	}
+
 	// Start pipeline and event bridge
 	if err := p.Start(ctx); err != nil {
 		log.Printf("[WebRTCRealtimeServer] session %s failed to start pipeline: %v", h.session.ID, err)
@@ -414,7 +805,11 @@ func (h *webrtcRealtimeEventHandler) handlePipelineOutput(ctx context.Context, p
 			// This handler is for any additional processing if needed
 
 			if msg.Type == pipeline.MsgTypeAudio {
-				h.session.SendAudio(msg.AudioData.Data, msg.AudioData.SampleRate, msg.AudioData.Channels)
+				start := time.Now()
+				err := h.session.SendAudio(msg.AudioData.Data, msg.AudioData.SampleRate, msg.AudioData.Channels)
+				if h.diag != nil {
+					h.diag.RecordSend(time.Since(start), err)
+				}
 			}
 		}
 	}
@@ -425,6 +820,41 @@ func (h *webrtcRealtimeEventHandler) OnAudioReceived(data []byte, sampleRate, ch
 	h.session.PushAudio(data, sampleRate, channels)
 }
 
+// webrtcRealtimeTrackEventHandler handles connections with several inbound
+// audio tracks (WebRTCRealtimeConfig.MultiTrackAudio), pushing each track's
+// audio tagged with its track ID.
+type webrtcRealtimeTrackEventHandler struct {
+	*webrtcRealtimeEventHandler
+}
+
+func (h *webrtcRealtimeTrackEventHandler) OnAudioTrackAdded(track connection.AudioTrackInfo) {
+	log.Printf("[WebRTCRealtimeServer] session %s audio track added: %s (stream %s)", h.session.ID, track.ID, track.StreamID)
+	h.publishTrackEvent(pipeline.EventAudioTrackAdded, track)
+}
+
+func (h *webrtcRealtimeTrackEventHandler) OnTrackAudioReceived(track connection.AudioTrackInfo, data []byte, sampleRate, channels int, timestamp time.Time) {
+	h.session.PushTrackAudio(track.ID, data, sampleRate, channels)
+}
+
+func (h *webrtcRealtimeTrackEventHandler) OnAudioTrackRemoved(track connection.AudioTrackInfo) {
+	log.Printf("[WebRTCRealtimeServer] session %s audio track removed: %s", h.session.ID, track.ID)
+	h.publishTrackEvent(pipeline.EventAudioTrackRemoved, track)
+}
+
+// publishTrackEvent publishes a track event on the session's pipeline bus.
+// Tracks added before the pipeline exists are read with AudioTracks instead.
+func (h *webrtcRealtimeTrackEventHandler) publishTrackEvent(eventType pipeline.EventType, track connection.AudioTrackInfo) {
+	p := h.session.GetPipeline()
+	if p == nil {
+		return
+	}
+	p.Bus().Publish(pipeline.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Payload:   pipeline.AudioTrackPayload{TrackID: track.ID, StreamID: track.StreamID},
+	})
+}
+
 func (h *webrtcRealtimeEventHandler) OnImageReceived(data []byte, mimeType string, width, height int, timestamp time.Time) {
 	// Push image to session's pipeline
 	if p := h.session.GetPipeline(); p != nil {
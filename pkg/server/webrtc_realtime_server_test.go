@@ -0,0 +1,327 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/realtime-ai/realtime-ai/pkg/connection"
+	"github.com/realtime-ai/realtime-ai/pkg/elements"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/realtimeapi"
+	"github.com/realtime-ai/realtime-ai/pkg/realtimeapi/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRealtimeConnection is a WebRTCRealtimeConnection without a PeerConnection.
+// It reports rate as its negotiated clock rate, 48000 if not set.
+type fakeRealtimeConnection struct {
+	closed atomic.Bool
+	rate   int
+}
+
+func (c *fakeRealtimeConnection) PeerID() string      { return "peer" }
+func (c *fakeRealtimeConnection) SessionID() string   { return "sess_test" }
+func (c *fakeRealtimeConnection) ResumeToken() string { return "resume-secret" }
+func (c *fakeRealtimeConnection) RegisterEventHandler(handler connection.WebRTCRealtimeEventHandler) {
+}
+func (c *fakeRealtimeConnection) SendEvent(event events.ServerEvent) error { return nil }
+func (c *fakeRealtimeConnection) SendAudio(data []byte, sampleRate, channels int) error {
+	return nil
+}
+func (c *fakeRealtimeConnection) Start(ctx context.Context) error { return nil }
+func (c *fakeRealtimeConnection) Close() error                    { c.closed.Store(true); return nil }
+func (c *fakeRealtimeConnection) SupportsRTPAudio() bool          { return true }
+func (c *fakeRealtimeConnection) SampleRate() int {
+	if c.rate > 0 {
+		return c.rate
+	}
+	return 48000
+}
+func (c *fakeRealtimeConnection) AudioTracks() []connection.AudioTrackInfo { return nil }
+func (c *fakeRealtimeConnection) Renegotiate(offer webrtc.SessionDescription) (*webrtc.SessionDescription, error) {
+	return nil, nil
+}
+func (c *fakeRealtimeConnection) Resume(pc *webrtc.PeerConnection) error { return nil }
+
+// stopRecorder records whether the pipeline stopped it.
+type stopRecorder struct {
+	*pipeline.BaseElement
+	stopped atomic.Bool
+}
+
+func (e *stopRecorder) Start(ctx context.Context) error { return nil }
+func (e *stopRecorder) Stop() error {
+	e.stopped.Store(true)
+	return nil
+}
+
+// newReconnectHandler returns the event handler of a connected session with a
+// running pipeline, and the states reported to OnConnectionStateChange.
+func newReconnectHandler(t *testing.T, timeout time.Duration) (*webrtcRealtimeEventHandler, *stopRecorder, func() []connection.ConnectionState) {
+	t.Helper()
+
+	config := DefaultWebRTCRealtimeConfig()
+	config.ResumeGracePeriod = timeout
+	server := NewWebRTCRealtimeServer(config)
+
+	var mu sync.Mutex
+	var states []connection.ConnectionState
+	server.OnConnectionStateChange(func(ctx context.Context, conn connection.WebRTCRealtimeConnection, state connection.ConnectionState) {
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	})
+
+	conn := &fakeRealtimeConnection{}
+	session := realtimeapi.NewSessionWithID(context.Background(), conn.SessionID(), &webrtcConnectionTransport{conn: conn}, realtimeapi.DefaultSessionConfig())
+	t.Cleanup(func() { session.Close() })
+
+	elem := &stopRecorder{BaseElement: pipeline.NewBaseElement("recorder", 10)}
+	p := pipeline.NewPipeline("reconnect-test")
+	p.AddElement(elem)
+	require.NoError(t, p.Start(session.Context()))
+	session.SetPipeline(p)
+
+	handler := &webrtcRealtimeEventHandler{conn: conn, session: session, server: server}
+	handler.OnConnectionStateChange(webrtc.PeerConnectionStateConnected)
+
+	return handler, elem, func() []connection.ConnectionState {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]connection.ConnectionState(nil), states...)
+	}
+}
+
+func TestWebRTCRealtimeReconnect(t *testing.T) {
+	handler, elem, states := newReconnectHandler(t, 100*time.Millisecond)
+
+	// The connection drops: the session waits for the client to reattach
+	handler.OnConnectionStateChange(webrtc.PeerConnectionStateDisconnected)
+	assert.False(t, elem.stopped.Load(), "pipeline stopped on disconnect")
+	assert.NoError(t, handler.session.Context().Err())
+
+	// The client reattaches a new PeerConnection within the window
+	handler.OnConnectionStateChange(webrtc.PeerConnectionStateConnected)
+
+	time.Sleep(200 * time.Millisecond)
+	assert.False(t, elem.stopped.Load(), "pipeline stopped after reattach")
+	assert.NoError(t, handler.session.Context().Err())
+	assert.Equal(t, []connection.ConnectionState{
+		connection.ConnectionStateConnected,
+		connection.ConnectionStateReconnecting,
+		connection.ConnectionStateConnected,
+	}, states())
+}
+
+func TestWebRTCRealtimeReconnectTimeout(t *testing.T) {
+	handler, elem, _ := newReconnectHandler(t, 50*time.Millisecond)
+
+	handler.OnConnectionStateChange(webrtc.PeerConnectionStateFailed)
+	assert.False(t, elem.stopped.Load())
+
+	// Nobody reattaches: the session and its pipeline are torn down
+	assert.Eventually(t, elem.stopped.Load, time.Second, 10*time.Millisecond)
+	assert.Error(t, handler.session.Context().Err())
+}
+
+func TestWebRTCRealtimeReconnectDisabled(t *testing.T) {
+	handler, elem, states := newReconnectHandler(t, 0)
+
+	handler.OnConnectionStateChange(webrtc.PeerConnectionStateDisconnected)
+	assert.True(t, elem.stopped.Load())
+	assert.Equal(t, connection.ConnectionStateDisconnected, states()[1])
+}
+
+// newOwnedSession registers a live session created by the client "alice" and
+// returns the server, which authenticates "alice" and "bob" by bearer token.
+func newOwnedSession(t *testing.T) *WebRTCRealtimeServer {
+	t.Helper()
+
+	config := DefaultWebRTCRealtimeConfig()
+	config.ResumeGracePeriod = time.Minute
+	config.Authenticator = BearerTokenAuthenticator(map[string]string{"alice": "token-a", "bob": "token-b"})
+	server := NewWebRTCRealtimeServer(config)
+
+	conn := &fakeRealtimeConnection{}
+	ctx := ContextWithIdentity(context.Background(), &Identity{Subject: "alice"})
+	session := realtimeapi.NewSessionWithID(ctx, conn.SessionID(), &webrtcConnectionTransport{conn: conn}, realtimeapi.DefaultSessionConfig())
+	t.Cleanup(func() { session.Close() })
+
+	server.sessions[session.ID] = session
+	server.connections[session.ID] = conn
+	server.resumeTokens[conn.ResumeToken()] = session.ID
+	return server
+}
+
+// sendNegotiate sends a negotiation request as the client with the bearer token auth.
+func sendNegotiate(t *testing.T, server *WebRTCRealtimeServer, method, query, auth string, req negotiateRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	r := httptest.NewRequest(method, "/session"+query, bytes.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+auth)
+	rec := httptest.NewRecorder()
+	server.HandleNegotiate(rec, r)
+	return rec
+}
+
+func TestWebRTCRealtimeResumeOwnership(t *testing.T) {
+	offer := webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: "v=0"}
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		auth     string
+		token    string
+		wantCode int
+	}{
+		{name: "renegotiate by owner", method: http.MethodPatch, auth: "token-a", token: "resume-secret", wantCode: http.StatusOK},
+		{name: "renegotiate by another client", method: http.MethodPatch, auth: "token-b", token: "resume-secret", wantCode: http.StatusForbidden},
+		{name: "renegotiate with session ID only", method: http.MethodPatch, query: "?session_id=sess_test", auth: "token-a", wantCode: http.StatusNotFound},
+		{name: "renegotiate with wrong token", method: http.MethodPatch, auth: "token-a", token: "guess", wantCode: http.StatusNotFound},
+		{name: "resume by another client", method: http.MethodPost, auth: "token-b", token: "resume-secret", wantCode: http.StatusForbidden},
+		{name: "resume with wrong token", method: http.MethodPost, auth: "token-a", token: "guess", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newOwnedSession(t)
+			rec := sendNegotiate(t, server, tt.method, tt.query, tt.auth, negotiateRequest{SessionDescription: offer, ResumeToken: tt.token})
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestWebRTCRealtimeNegotiateResumeToken(t *testing.T) {
+	server := NewWebRTCRealtimeServer(DefaultWebRTCRealtimeConfig())
+	server.api = webrtc.NewAPI()
+
+	var conn connection.WebRTCRealtimeConnection
+	server.OnConnectionCreated(func(ctx context.Context, c connection.WebRTCRealtimeConnection, session *realtimeapi.Session) {
+		conn = c
+		t.Cleanup(func() { session.Close() })
+	})
+
+	rec := postNegotiate(t, server, "", newClientOffer(t))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var resp realtimeNegotiateResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, conn.SessionID(), resp.SessionID)
+	assert.Equal(t, conn.ResumeToken(), resp.ResumeToken)
+	assert.NotContains(t, resp.ResumeToken, resp.SessionID)
+	assert.Len(t, resp.ResumeToken, 36)
+}
+
+// postNegotiate posts offer to HandleNegotiate with the given query string.
+func postNegotiate(t *testing.T, server *WebRTCRealtimeServer, query string, offer webrtc.SessionDescription) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(offer)
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	server.HandleNegotiate(rec, httptest.NewRequest(http.MethodPost, "/session"+query, bytes.NewReader(body)))
+	return rec
+}
+
+func TestWebRTCRealtimeOutputSampleRate(t *testing.T) {
+	// The client negotiated a 16kHz Opus clock instead of 48kHz
+	conn := &fakeRealtimeConnection{rate: 16000}
+	session := realtimeapi.NewSessionWithID(context.Background(), conn.SessionID(), &webrtcConnectionTransport{conn: conn}, realtimeapi.DefaultSessionConfig())
+	t.Cleanup(func() { session.Close() })
+	assert.Equal(t, 16000, session.OutputSampleRate())
+
+	// The diagnostic pipeline resamples its 24kHz test signal to the negotiated rate
+	diag := elements.NewLoopbackDiagnosticElementWithConfig(elements.LoopbackDiagnosticConfig{SampleRate: 24000})
+	p, err := diagnosticPipeline(diag, 24000)(session.Context(), session)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(session.Context()))
+	defer p.Stop()
+
+	msg, err := p.PullTimeout(time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, msg.AudioData)
+	assert.Equal(t, 16000, msg.AudioData.SampleRate)
+}
+
+func TestWebRTCRealtimeSessionLimit(t *testing.T) {
+	config := DefaultWebRTCRealtimeConfig()
+	config.MaxConcurrentSessions = 2
+	server := NewWebRTCRealtimeServer(config)
+
+	var rejected [][2]int
+	server.OnSessionLimit(func(active, limit int) {
+		rejected = append(rejected, [2]int{active, limit})
+	})
+
+	// Negotiations in progress hold a slot until they register a session
+	active, ok := server.reserveSession()
+	require.True(t, ok)
+	assert.Equal(t, 0, active)
+	active, ok = server.reserveSession()
+	require.True(t, ok)
+	assert.Equal(t, 1, active)
+	assert.Equal(t, 2, server.ActiveSessions())
+
+	active, ok = server.reserveSession()
+	assert.False(t, ok)
+	assert.Equal(t, 2, active)
+
+	rec := postNegotiate(t, server, "", webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: "v=0"})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, [][2]int{{2, 2}}, rejected)
+
+	// A failed negotiation gives its slot back
+	server.releaseReservation()
+	assert.Equal(t, 1, server.ActiveSessions())
+	_, ok = server.reserveSession()
+	assert.True(t, ok)
+}
+
+func TestWebRTCRealtimeSessionLimitDisabled(t *testing.T) {
+	server := NewWebRTCRealtimeServer(DefaultWebRTCRealtimeConfig())
+	for i := 0; i < 100; i++ {
+		_, ok := server.reserveSession()
+		require.True(t, ok)
+	}
+	assert.Equal(t, 100, server.ActiveSessions())
+}
+
+func TestWebRTCRealtimeNegotiateModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantModel string
+	}{
+		{name: "default model", wantCode: http.StatusCreated, wantModel: "gemini-2.5-flash-native-audio-preview-12-2025"},
+		{name: "allowed model", query: "?model=gemini-2.0-flash", wantCode: http.StatusCreated, wantModel: "gemini-2.0-flash"},
+		{name: "model not allowed", query: "?model=gpt-4o", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewWebRTCRealtimeServer(DefaultWebRTCRealtimeConfig())
+			server.api = webrtc.NewAPI()
+
+			var model string
+			server.OnConnectionCreated(func(ctx context.Context, conn connection.WebRTCRealtimeConnection, session *realtimeapi.Session) {
+				model = session.Config.Model
+				t.Cleanup(func() { session.Close() })
+			})
+
+			rec := postNegotiate(t, server, tt.query, newClientOffer(t))
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.wantModel, model)
+		})
+	}
+}
@@ -0,0 +1,385 @@
+// Package server provides HTTP and WebSocket server implementations.
+//
+// WebSocketAudioServer implements a WebSocket server for clients that stream
+// raw PCM audio, e.g. a browser capturing 16kHz microphone audio.
+//
+// Protocol:
+//   - Client → server: binary frames of 16-bit little-endian PCM at
+//     SampleRate, or JSON {"type":"text","payload":"..."} messages
+//   - Server → client: pipeline audio as binary PCM frames at SampleRate,
+//     pipeline text (e.g. transcripts) as JSON {"type":"text","payload":"..."}
+//
+// Each connection gets its own pipeline from the WebSocketAudioPipelineFactory.
+// Client input is pushed into the pipeline and everything the pipeline
+// outputs is sent back to the client.
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/connection"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+)
+
+// wsAudioInputBuffer is the number of client messages buffered while the
+// pipeline is being created and started (about 10s of 20ms frames).
+const wsAudioInputBuffer = 500
+
+// WebSocketAudioServerConfig holds configuration for WebSocketAudioServer.
+type WebSocketAudioServerConfig struct {
+	// Address is the listen address (e.g., ":8080")
+	Address string
+
+	// WebSocketPath is the path for WebSocket connections (default: "/audio")
+	WebSocketPath string
+
+	// SampleRate of the PCM exchanged with clients (default: 16000)
+	SampleRate int
+
+	// Channels of the PCM exchanged with clients (default: 1)
+	Channels int
+
+	// FrameMs splits incoming audio into frames of this duration before it
+	// enters the pipeline (default: 20). Negative delivers client messages
+	// as received.
+	FrameMs int
+
+	// ReadBufferSize for WebSocket (default: 1024)
+	ReadBufferSize int
+
+	// WriteBufferSize for WebSocket (default: 1024)
+	WriteBufferSize int
+
+	// Authenticator, if set, validates the upgrade request before a session
+	// is created. The identity is available to the PipelineFactory through
+	// IdentityFromContext.
+	Authenticator Authenticator
+}
+
+// WebSocketAudioPipelineFactory creates pipelines for raw audio WebSocket connections.
+type WebSocketAudioPipelineFactory interface {
+	// CreatePipeline creates a new pipeline for a client connection.
+	// The pipeline receives the client's audio; its output is sent back
+	// to the client by the server.
+	CreatePipeline(ctx context.Context, conn connection.Connection) (*pipeline.Pipeline, error)
+}
+
+// WebSocketAudioServer handles raw PCM audio WebSocket connections.
+type WebSocketAudioServer struct {
+	config          WebSocketAudioServerConfig
+	pipelineFactory WebSocketAudioPipelineFactory
+
+	upgrader websocket.Upgrader
+	server   *http.Server
+
+	// Active sessions
+	sessions   map[string]*WebSocketAudioSession
+	sessionsMu sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// WebSocketAudioSession represents an active client connection.
+type WebSocketAudioSession struct {
+	Connection connection.Connection
+	Pipeline   *pipeline.Pipeline
+	PeerID     string
+	StartTime  time.Time
+
+	cancel context.CancelFunc
+}
+
+// NewWebSocketAudioServer creates a new raw audio WebSocket server.
+func NewWebSocketAudioServer(config WebSocketAudioServerConfig, factory WebSocketAudioPipelineFactory) *WebSocketAudioServer {
+	// Set defaults
+	if config.WebSocketPath == "" {
+		config.WebSocketPath = "/audio"
+	}
+	if config.SampleRate == 0 {
+		config.SampleRate = connection.DefaultWSAudioSampleRate
+	}
+	if config.Channels == 0 {
+		config.Channels = 1
+	}
+	if config.FrameMs == 0 {
+		config.FrameMs = connection.DefaultWSAudioFrameMs
+	}
+	if config.ReadBufferSize == 0 {
+		config.ReadBufferSize = 1024
+	}
+	if config.WriteBufferSize == 0 {
+		config.WriteBufferSize = 1024
+	}
+
+	return &WebSocketAudioServer{
+		config:          config,
+		pipelineFactory: factory,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  config.ReadBufferSize,
+			WriteBufferSize: config.WriteBufferSize,
+			CheckOrigin:     func(r *http.Request) bool { return true },
+		},
+		sessions: make(map[string]*WebSocketAudioSession),
+	}
+}
+
+// Start starts the server.
+func (s *WebSocketAudioServer) Start(ctx context.Context) error {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.config.WebSocketPath, s.handleWebSocket)
+	mux.HandleFunc("/health", s.handleHealth)
+
+	s.server = &http.Server{
+		Addr:    s.config.Address,
+		Handler: mux,
+	}
+
+	log.Printf("[WebSocketAudioServer] Starting server on %s", s.config.Address)
+	log.Printf("[WebSocketAudioServer] WebSocket endpoint: %s (%dHz, %d channel(s))",
+		s.config.WebSocketPath, s.config.SampleRate, s.config.Channels)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("[WebSocketAudioServer] Server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the server gracefully.
+func (s *WebSocketAudioServer) Stop() error {
+	log.Printf("[WebSocketAudioServer] Stopping server...")
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	// Close all sessions
+	s.sessionsMu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[string]*WebSocketAudioSession)
+	s.sessionsMu.Unlock()
+
+	for _, session := range sessions {
+		session.cancel()
+		session.Pipeline.Stop()
+		session.Connection.Close()
+	}
+
+	// Shutdown HTTP server
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.server.Shutdown(ctx)
+	}
+
+	s.wg.Wait()
+	log.Printf("[WebSocketAudioServer] Server stopped")
+	return nil
+}
+
+// connectionConfig returns the connection configuration for new clients.
+func (s *WebSocketAudioServer) connectionConfig() connection.WebSocketConfig {
+	cfg := connection.DefaultWebSocketAudioConfig()
+	cfg.SampleRate = s.config.SampleRate
+	cfg.Channels = s.config.Channels
+	cfg.FrameMs = max(s.config.FrameMs, 0)
+	return cfg
+}
+
+// handleWebSocket handles incoming client connections.
+func (s *WebSocketAudioServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[WebSocketAudioServer] WebSocket connection from %s", r.RemoteAddr)
+
+	authCtx, ok := authenticate(w, r, s.config.Authenticator)
+	if !ok {
+		return
+	}
+
+	// Upgrade HTTP to WebSocket
+	wsConn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[WebSocketAudioServer] WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	peerID := uuid.New().String()
+	handler := &wsAudioSessionHandler{
+		server: s,
+		peerID: peerID,
+		in:     make(chan *pipeline.PipelineMessage, wsAudioInputBuffer),
+	}
+	conn := connection.NewWebSocketConnectionWithConfig(peerID, wsConn, s.connectionConfig())
+	conn.RegisterEventHandler(handler)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	if id, ok := IdentityFromContext(authCtx); ok {
+		ctx = ContextWithIdentity(ctx, id)
+	}
+
+	// Create pipeline using factory
+	p, err := s.pipelineFactory.CreatePipeline(ctx, conn)
+	if err != nil {
+		log.Printf("[WebSocketAudioServer] Failed to create pipeline for %s: %v", peerID, err)
+		cancel()
+		conn.Close()
+		return
+	}
+
+	// Store session
+	session := &WebSocketAudioSession{
+		Connection: conn,
+		Pipeline:   p,
+		PeerID:     peerID,
+		StartTime:  time.Now(),
+		cancel:     cancel,
+	}
+
+	s.sessionsMu.Lock()
+	s.sessions[peerID] = session
+	s.sessionsMu.Unlock()
+
+	// The client may have left while the pipeline was being created
+	if handler.closed.Load() {
+		s.removeSession(peerID)
+		return
+	}
+
+	log.Printf("[WebSocketAudioServer] Session created for %s", peerID)
+
+	// Start pipeline
+	if err := p.Start(ctx); err != nil {
+		log.Printf("[WebSocketAudioServer] Failed to start pipeline for %s: %v", peerID, err)
+		s.removeSession(peerID)
+		conn.Close()
+		return
+	}
+
+	go s.forwardInputToPipeline(ctx, handler.in, p)
+	go s.forwardPipelineOutput(ctx, p, conn)
+}
+
+// forwardInputToPipeline pushes client audio and text into the pipeline.
+func (s *WebSocketAudioServer) forwardInputToPipeline(ctx context.Context, in <-chan *pipeline.PipelineMessage, p *pipeline.Pipeline) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-in:
+			p.Push(msg)
+		}
+	}
+}
+
+// forwardPipelineOutput sends pipeline output back to the client.
+func (s *WebSocketAudioServer) forwardPipelineOutput(ctx context.Context, p *pipeline.Pipeline, conn connection.Connection) {
+	for {
+		msg, err := p.PullContext(ctx)
+		if err != nil {
+			return
+		}
+		if msg.Type == pipeline.MsgTypeAudio || msg.Type == pipeline.MsgTypeData {
+			conn.SendMessage(msg)
+		}
+	}
+}
+
+// handleHealth handles health check requests.
+func (s *WebSocketAudioServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.sessionsMu.RLock()
+	sessionCount := len(s.sessions)
+	s.sessionsMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"status":"ok","sessions":%d}`, sessionCount)
+}
+
+// removeSession stops the session's pipeline and removes it from tracking.
+func (s *WebSocketAudioServer) removeSession(peerID string) {
+	s.sessionsMu.Lock()
+	session, ok := s.sessions[peerID]
+	delete(s.sessions, peerID)
+	s.sessionsMu.Unlock()
+
+	if !ok {
+		return
+	}
+	session.cancel()
+	session.Pipeline.Stop()
+	log.Printf("[WebSocketAudioServer] Session removed for %s (duration: %v)",
+		peerID, time.Since(session.StartTime))
+}
+
+// GetSession returns a session by peer ID.
+func (s *WebSocketAudioServer) GetSession(peerID string) *WebSocketAudioSession {
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+	return s.sessions[peerID]
+}
+
+// Hangup ends the session with the given peer ID from the server side.
+// The client receives a "hangup" message before the connection is closed.
+func (s *WebSocketAudioServer) Hangup(peerID, reason string) error {
+	session := s.GetSession(peerID)
+	if session == nil {
+		return fmt.Errorf("no active session for %s", peerID)
+	}
+	return session.Connection.Hangup(reason)
+}
+
+// GetActiveSessions returns all active sessions.
+func (s *WebSocketAudioServer) GetActiveSessions() []*WebSocketAudioSession {
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+
+	sessions := make([]*WebSocketAudioSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// wsAudioSessionHandler buffers client input until the pipeline is running
+// and cleans up the session when the client disconnects.
+type wsAudioSessionHandler struct {
+	server *WebSocketAudioServer
+	peerID string
+	in     chan *pipeline.PipelineMessage
+	closed atomic.Bool
+}
+
+func (h *wsAudioSessionHandler) OnConnectionStateChange(state connection.ConnectionState) {
+	log.Printf("[WebSocketAudioServer] Connection %s state changed: %v", h.peerID, state)
+
+	if state == connection.ConnectionStateClosed || state == connection.ConnectionStateDisconnected {
+		h.closed.Store(true)
+		h.server.removeSession(h.peerID)
+	}
+}
+
+func (h *wsAudioSessionHandler) OnMessage(msg *pipeline.PipelineMessage) {
+	select {
+	case h.in <- msg:
+	default:
+		log.Printf("[WebSocketAudioServer] Input buffer full for %s, dropping message", h.peerID)
+	}
+}
+
+func (h *wsAudioSessionHandler) OnError(err error) {
+	log.Printf("[WebSocketAudioServer] Connection %s error: %v", h.peerID, err)
+}
@@ -57,6 +57,21 @@ type WebSocketRealtimeConfig struct {
 
 	// WriteBufferSize is the WebSocket write buffer size.
 	WriteBufferSize int
+
+	// PingInterval is how often the server pings each client.
+	// 0 disables keepalive and the read deadline.
+	PingInterval time.Duration
+
+	// PongTimeout is how long a client may stay silent after a ping before
+	// its session is closed and its pipeline stopped (default: PingInterval).
+	PongTimeout time.Duration
+
+	// WriteTimeout bounds each write to a client. 0 means no deadline.
+	WriteTimeout time.Duration
+
+	// EventBridge selects which pipeline events are sent to clients as
+	// OpenAI Realtime server events.
+	EventBridge bridge.Config
 }
 
 // DefaultWebSocketRealtimeConfig returns the default server configuration.
@@ -71,6 +86,10 @@ func DefaultWebSocketRealtimeConfig() *WebSocketRealtimeConfig {
 		DefaultSessionConfig: realtimeapi.DefaultSessionConfig(),
 		ReadBufferSize:       4096,
 		WriteBufferSize:      4096,
+		PingInterval:         20 * time.Second,
+		PongTimeout:          10 * time.Second,
+		WriteTimeout:         10 * time.Second,
+		EventBridge:          bridge.OpenAIConfig(),
 	}
 }
 
@@ -235,7 +254,9 @@ func (s *WebSocketRealtimeServer) handleWebSocket(w http.ResponseWriter, r *http
 	sessionConfig := s.config.DefaultSessionConfig
 	sessionConfig.Model = model
 
-	session := realtimeapi.NewSession(s.ctx, conn, sessionConfig)
+	transport := realtimeapi.NewWebSocketTransport(conn)
+	transport.SetWriteTimeout(s.config.WriteTimeout)
+	session := realtimeapi.NewSessionWithTransport(s.ctx, transport, sessionConfig)
 
 	// Register session
 	s.registerSession(session, clientIP)
@@ -262,7 +283,7 @@ func (s *WebSocketRealtimeServer) handleWebSocket(w http.ResponseWriter, r *http
 		session.SetPipeline(p)
 
 		// Create and start EventBridge for pipeline-to-WebSocket event translation
-		eb := bridge.NewEventBridge(p.Bus(), session, session.ID)
+		eb := bridge.NewEventBridgeWithConfig(p.Bus(), session, session.ID, s.config.EventBridge)
 		session.SetEventBridge(eb)
 		if err := eb.Start(session.Context()); err != nil {
 			log.Printf("[WebSocketRealtimeServer] Failed to start event bridge: %v", err)
@@ -292,12 +313,16 @@ func (s *WebSocketRealtimeServer) handleWebSocket(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Ping the client and reap it when it goes silent
+	ka := newKeepalive(conn, s.config.PingInterval, s.config.PongTimeout, s.config.WriteTimeout)
+	ka.Start(session.Context())
+
 	// Handle incoming messages
-	s.handleSession(session, conn)
+	s.handleSession(session, conn, ka)
 }
 
 // handleSession handles messages for a session.
-func (s *WebSocketRealtimeServer) handleSession(session *realtimeapi.Session, conn *websocket.Conn) {
+func (s *WebSocketRealtimeServer) handleSession(session *realtimeapi.Session, conn *websocket.Conn, ka *keepalive) {
 	defer session.Close()
 
 	for {
@@ -310,11 +335,14 @@ func (s *WebSocketRealtimeServer) handleSession(session *realtimeapi.Session, co
 		// Read message from WebSocket connection
 		_, data, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if isTimeout(err) {
+				log.Printf("[WebSocketRealtimeServer] [session %s] client unresponsive, closing session", session.ID)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("[WebSocketRealtimeServer] [session %s] WebSocket read error: %v", session.ID, err)
 			}
 			return
 		}
+		ka.Extend()
 
 		// Parse event
 		event, err := events.ParseClientEvent(data)
@@ -0,0 +1,41 @@
+// Package storage provides a unified interface for object storage backends
+// (Amazon S3, Google Cloud Storage, MinIO, etc.) used to archive recorded
+// sessions without keeping them on local disk.
+package storage
+
+import (
+	"context"
+)
+
+// MinPartSize is the smallest part size accepted by S3-compatible multipart
+// uploads for every part except the last one.
+const MinPartSize = 5 * 1024 * 1024
+
+// Upload is an in-progress multipart upload of a single object.
+type Upload interface {
+	// Key returns the object key being uploaded.
+	Key() string
+
+	// UploadPart uploads one part. Parts are numbered from 1 and must be
+	// uploaded in order; every part except the last must be at least MinPartSize.
+	UploadPart(ctx context.Context, partNumber int, data []byte) error
+
+	// Complete assembles the uploaded parts into the final object.
+	Complete(ctx context.Context) error
+
+	// Abort cancels the upload and discards the uploaded parts.
+	Abort(ctx context.Context) error
+}
+
+// ObjectStore is the main interface for object storage backends.
+type ObjectStore interface {
+	// Name returns the backend name (e.g., "s3", "gcs")
+	Name() string
+
+	// Put uploads a small object in a single request.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// CreateUpload starts a multipart upload, used for objects whose size is
+	// not known in advance (e.g., audio of a long call).
+	CreateUpload(ctx context.Context, key string, contentType string) (Upload, error)
+}
@@ -0,0 +1,430 @@
+// Package storage provides object storage backends for archiving recorded sessions.
+//
+// S3Store implements ObjectStore for S3-compatible services on top of the S3
+// REST API with AWS Signature V4, using only net/http (no cloud SDK dependency).
+//
+// Features:
+//   - Amazon S3 (virtual-hosted or path-style addressing)
+//   - Google Cloud Storage via its S3-compatible XML API and HMAC keys
+//   - Any other S3-compatible service (MinIO, Cloudflare R2, ...)
+//   - Multipart uploads for objects of unknown size
+//
+// Usage:
+//
+//	store, err := storage.NewS3Store(storage.S3Config{
+//	    Bucket: "call-recordings",
+//	    Region: "eu-west-1",
+//	})
+//
+//	// Google Cloud Storage (HMAC key from GCS interoperability settings)
+//	store, err := storage.NewGCSStore("call-recordings", accessKey, secret)
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsEndpoint        = "https://storage.googleapis.com"
+	s3DefaultRegion    = "us-east-1"
+	s3RequestTimeout   = 60 * time.Second
+	s3SigningAlgorithm = "AWS4-HMAC-SHA256"
+)
+
+// S3Config holds configuration for an S3-compatible object store.
+type S3Config struct {
+	// Endpoint is the service URL (default: https://s3.<region>.amazonaws.com)
+	Endpoint string
+
+	// Region used for request signing (default: AWS_REGION env var, then us-east-1)
+	Region string
+
+	// Bucket to store objects in
+	Bucket string
+
+	// Credentials (default: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+	// AWS_SESSION_TOKEN env vars)
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// UsePathStyle addresses the bucket as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<endpoint>/<key> (required by MinIO and most self-hosted services)
+	UsePathStyle bool
+
+	// HTTPClient to use (default: client with a 60s timeout)
+	HTTPClient *http.Client
+}
+
+// S3Store implements ObjectStore for S3-compatible services.
+type S3Store struct {
+	name       string
+	config     S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+var _ ObjectStore = (*S3Store)(nil)
+
+// NewS3Store creates an object store for Amazon S3 or an S3-compatible service.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	return newS3Store("s3", config)
+}
+
+// NewGCSStore creates an object store for Google Cloud Storage using its
+// S3-compatible XML API. The credentials are a GCS HMAC key.
+func NewGCSStore(bucket, accessKeyID, secretAccessKey string) (*S3Store, error) {
+	return newS3Store("gcs", S3Config{
+		Endpoint:        gcsEndpoint,
+		Region:          "auto",
+		Bucket:          bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+	})
+}
+
+func newS3Store(name string, config S3Config) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = s3DefaultRegion
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if config.SecretAccessKey == "" {
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if config.SessionToken == "" {
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("access key ID and secret access key are required")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", config.Endpoint)
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: s3RequestTimeout}
+	}
+
+	return &S3Store{
+		name:       name,
+		config:     config,
+		endpoint:   endpoint,
+		httpClient: httpClient,
+		now:        time.Now,
+	}, nil
+}
+
+// Name returns the backend name.
+func (s *S3Store) Name() string {
+	return s.name
+}
+
+// Put uploads a small object in a single request.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// CreateUpload starts a multipart upload.
+func (s *S3Store) CreateUpload(ctx context.Context, key string, contentType string) (Upload, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%s: failed to decode create upload response: %w", s.name, err)
+	}
+	if result.UploadID == "" {
+		return nil, fmt.Errorf("%s: create upload response has no upload ID", s.name)
+	}
+
+	return &s3Upload{store: s, key: key, uploadID: result.UploadID}, nil
+}
+
+// s3Upload is an in-progress S3 multipart upload.
+type s3Upload struct {
+	store    *S3Store
+	key      string
+	uploadID string
+
+	mu    sync.Mutex
+	parts []s3CompletedPart
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (u *s3Upload) Key() string {
+	return u.key
+}
+
+func (u *s3Upload) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {u.uploadID},
+	}
+	resp, err := u.store.do(ctx, http.MethodPut, u.key, query, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	u.mu.Lock()
+	u.parts = append(u.parts, s3CompletedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+	u.mu.Unlock()
+	return nil
+}
+
+func (u *s3Upload) Complete(ctx context.Context) error {
+	u.mu.Lock()
+	parts := append([]s3CompletedPart(nil), u.parts...)
+	u.mu.Unlock()
+
+	if len(parts) == 0 {
+		return fmt.Errorf("%s: cannot complete upload of %s without parts", u.store.name, u.key)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	resp, err := u.store.do(ctx, http.MethodPost, u.key, url.Values{"uploadId": {u.uploadID}}, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 may report a failure with status 200 after the response has started
+	data, _ := io.ReadAll(resp.Body)
+	if bytes.Contains(data, []byte("<Error>")) {
+		return parseS3Error(u.store.name, http.MethodPost, u.key, resp.StatusCode, data)
+	}
+	return nil
+}
+
+func (u *s3Upload) Abort(ctx context.Context) error {
+	resp, err := u.store.do(ctx, http.MethodDelete, u.key, url.Values{"uploadId": {u.uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request and returns the response if it succeeded.
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.sign(req, body)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s %s: %w", s.name, method, key, err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, parseS3Error(s.name, method, key, resp.StatusCode, data)
+	}
+	return resp, nil
+}
+
+// objectURL builds the request URL for an object key.
+func (s *S3Store) objectURL(key string, query url.Values) string {
+	u := *s.endpoint
+	path := "/" + strings.TrimPrefix(key, "/")
+	if s.config.UsePathStyle {
+		path = "/" + s.config.Bucket + path
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+	return u.String()
+}
+
+// sign adds AWS Signature V4 headers to the request.
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+	payloadHex := hex.EncodeToString(payloadHash[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHex)
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	// Canonical headers: every header set above plus Content-Type
+	names := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.config.SessionToken != "" {
+		names = append(names, "x-amz-security-token")
+	}
+	if req.Header.Get("Content-Type") != "" {
+		names = append(names, "content-type")
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHex,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3SigningAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by key, as required by
+// Signature V4 (parameters without a value keep the trailing "=").
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything except unreserved characters
+// (and "/" unless encodeSlash is set).
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// parseS3Error converts an S3 XML error response into an error.
+func parseS3Error(name, method, key string, status int, body []byte) error {
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("%s: %s %s: %s: %s (status %d)", name, method, key, s3Err.Code, s3Err.Message, status)
+	}
+	return fmt.Errorf("%s: %s %s: status %d", name, method, key, status)
+}
@@ -0,0 +1,405 @@
+// Package tts provides streaming text-to-speech providers.
+//
+// CartesiaTTSProvider implements StreamingTTSProvider using the Cartesia
+// Sonic WebSocket API. Audio is streamed back as raw 16-bit PCM chunks while
+// the text is still being synthesized, which keeps time-to-first-byte low.
+//
+// Features:
+//   - Raw PCM output at a configurable sample rate (default 24kHz)
+//   - Speed from the "speed" request option (0.6-1.5, default 1.0)
+//   - Cancelling the context cancels the generation on the server and closes
+//     the audio channel promptly, for barge-in
+//
+// Usage:
+//
+//	provider, err := tts.NewCartesiaTTSProvider(tts.CartesiaTTSConfig{
+//		APIKey:  os.Getenv("CARTESIA_API_KEY"),
+//		VoiceID: "your-voice-id",
+//	})
+//	ttsElement := elements.NewUniversalTTSElement(provider)
+//
+// Reference: https://docs.cartesia.ai/api-reference/tts/websocket
+package tts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+const (
+	cartesiaWSEndpoint       = "wss://api.cartesia.ai/tts/websocket"
+	cartesiaAPIVersion       = "2025-04-16"
+	cartesiaDefaultModel     = "sonic-3"
+	cartesiaDefaultRate      = 24000
+	cartesiaConnectTimeout   = 10 * time.Second
+	cartesiaCancelWriteLimit = time.Second
+)
+
+// CartesiaTTSConfig holds the configuration for Cartesia TTS
+type CartesiaTTSConfig struct {
+	APIKey     string  // Required: Cartesia API key (defaults to CARTESIA_API_KEY)
+	VoiceID    string  // Required: Voice ID to use
+	Model      string  // Optional: Model ID (default: sonic-3)
+	SampleRate int     // Optional: PCM output sample rate (default: 24000)
+	Speed      float64 // Optional: Default speed 0.6-1.5 (default: 1.0)
+
+	// Optional: WebSocket URL override (proxies, tests)
+	URL string
+
+	// Optional: extra headers sent on every connection (API gateway keys, org IDs,
+	// tracing headers); they override the provider's own headers of the same name
+	Headers map[string]string
+}
+
+// CartesiaTTSProvider implements StreamingTTSProvider using the Cartesia WebSocket API
+type CartesiaTTSProvider struct {
+	apiKey     string
+	voiceID    string
+	model      string
+	sampleRate int
+	speed      float64
+	url        string
+	headers    map[string]string
+
+	mu      sync.RWMutex
+	streams map[*utils.WSHealth]struct{} // Health of in-flight stream connections
+	failed  bool                         // Last stream lost its connection
+}
+
+// NewCartesiaTTSProvider creates a new Cartesia TTS provider
+func NewCartesiaTTSProvider(config CartesiaTTSConfig) (*CartesiaTTSProvider, error) {
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("CARTESIA_API_KEY")
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("Cartesia API key is required")
+	}
+	if config.VoiceID == "" {
+		return nil, fmt.Errorf("Cartesia Voice ID is required")
+	}
+	if config.Model == "" {
+		config.Model = cartesiaDefaultModel
+	}
+	if config.SampleRate == 0 {
+		config.SampleRate = cartesiaDefaultRate
+	}
+	if config.Speed == 0 {
+		config.Speed = 1.0
+	}
+	if config.URL == "" {
+		config.URL = cartesiaWSEndpoint
+	}
+
+	return &CartesiaTTSProvider{
+		apiKey:     config.APIKey,
+		voiceID:    config.VoiceID,
+		model:      config.Model,
+		sampleRate: config.SampleRate,
+		speed:      config.Speed,
+		url:        config.URL,
+		headers:    config.Headers,
+		streams:    make(map[*utils.WSHealth]struct{}),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *CartesiaTTSProvider) Name() string {
+	return "cartesia"
+}
+
+// Synthesize converts text to speech (batch mode - collects all audio)
+func (p *CartesiaTTSProvider) Synthesize(ctx context.Context, req *SynthesizeRequest) (*SynthesizeResponse, error) {
+	if err := p.ValidateConfig(); err != nil {
+		return nil, err
+	}
+
+	audioChan, errChan := p.StreamSynthesize(ctx, req)
+
+	var audioData []byte
+	for chunk := range audioChan {
+		audioData = append(audioData, chunk...)
+	}
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+
+	return &SynthesizeResponse{
+		AudioData: audioData,
+		AudioFormat: AudioFormat{
+			SampleRate: p.sampleRate,
+			Channels:   1,
+			MediaType:  pipeline.AudioMediaTypePCM,
+			Encoding:   "pcm_s16le",
+		},
+	}, nil
+}
+
+// StreamSynthesize streams audio data as it's generated. When ctx is
+// cancelled the generation is cancelled, the audio channel is closed and
+// ctx.Err() is sent on the error channel.
+func (p *CartesiaTTSProvider) StreamSynthesize(ctx context.Context, req *SynthesizeRequest) (<-chan []byte, <-chan error) {
+	audioChan := make(chan []byte, 100)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(audioChan)
+		defer close(errChan)
+
+		if err := p.doStreamSynthesize(ctx, req, audioChan); err != nil {
+			errChan <- err
+		}
+	}()
+
+	return audioChan, errChan
+}
+
+// StreamAudioFormat returns the format of StreamSynthesize chunks.
+// It implements StreamFormatProvider.
+func (p *CartesiaTTSProvider) StreamAudioFormat() AudioFormat {
+	return AudioFormat{
+		SampleRate: p.sampleRate,
+		Channels:   1,
+		MediaType:  pipeline.AudioMediaTypePCM,
+		Encoding:   "pcm_s16le",
+	}
+}
+
+// doStreamSynthesize sends one generation request and reads its audio
+func (p *CartesiaTTSProvider) doStreamSynthesize(ctx context.Context, req *SynthesizeRequest, audioChan chan<- []byte) error {
+	voiceID := req.Voice
+	if voiceID == "" {
+		voiceID = p.voiceID
+	}
+	speed := p.speed
+	if s, ok := req.Options["speed"].(float64); ok && s > 0 {
+		speed = s
+	}
+
+	headers := http.Header{}
+	headers.Set("X-API-Key", p.apiKey)
+	headers.Set("Cartesia-Version", cartesiaAPIVersion)
+	utils.MergeHeaders(headers, p.headers)
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: cartesiaConnectTimeout,
+	}
+	conn, _, err := dialer.DialContext(ctx, p.url, headers)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p.setFailed(true)
+		return fmt.Errorf("failed to connect to Cartesia WebSocket: %w", err)
+	}
+	defer conn.Close()
+
+	health := utils.NewWSHealth(conn, utils.DefaultWSPingInterval)
+	p.trackStream(health)
+	defer p.untrackStream(health)
+
+	contextID := uuid.New().String()
+	request := cartesiaTTSRequest{
+		ModelID:    p.model,
+		Transcript: req.Text,
+		Voice:      cartesiaVoice{Mode: "id", ID: voiceID},
+		Language:   cartesiaLanguage(req.Language),
+		ContextID:  contextID,
+		OutputFormat: cartesiaOutputFormat{
+			Container:  "raw",
+			Encoding:   "pcm_s16le",
+			SampleRate: p.sampleRate,
+		},
+		GenerationConfig: &cartesiaGenerationConfig{Speed: speed},
+	}
+	if err := conn.WriteJSON(request); err != nil {
+		p.setFailed(true)
+		return fmt.Errorf("failed to send Cartesia request: %w", err)
+	}
+
+	log.Printf("[Cartesia-TTS] Sent %d chars (voice: %s, context: %s)", len(req.Text), voiceID, contextID)
+
+	// On cancellation, stop the generation and unblock the read loop
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetWriteDeadline(time.Now().Add(cartesiaCancelWriteLimit))
+			conn.WriteJSON(cartesiaCancelRequest{ContextID: contextID, Cancel: true})
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	return p.readLoop(ctx, conn, health, contextID, audioChan)
+}
+
+// readLoop forwards audio chunks of contextID until the generation is done
+func (p *CartesiaTTSProvider) readLoop(ctx context.Context, conn *websocket.Conn, health *utils.WSHealth, contextID string, audioChan chan<- []byte) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Printf("[Cartesia-TTS] Generation cancelled (context: %s)", contextID)
+				return ctx.Err()
+			}
+			p.setFailed(true)
+			return fmt.Errorf("Cartesia WebSocket read error: %w", err)
+		}
+		health.Touch()
+
+		var resp cartesiaTTSResponse
+		if err := json.Unmarshal(message, &resp); err != nil {
+			log.Printf("[Cartesia-TTS] Failed to parse response: %v", err)
+			continue
+		}
+		if resp.ContextID != "" && resp.ContextID != contextID {
+			continue
+		}
+
+		switch resp.Type {
+		case "chunk":
+			audioData, err := base64.StdEncoding.DecodeString(resp.Data)
+			if err != nil {
+				log.Printf("[Cartesia-TTS] Failed to decode audio: %v", err)
+				continue
+			}
+			select {
+			case audioChan <- audioData:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+		case "done":
+			p.setFailed(false)
+			return nil
+
+		case "error":
+			p.setFailed(false)
+			return fmt.Errorf("Cartesia TTS error (status %d): %s", resp.StatusCode, resp.Error)
+		}
+	}
+}
+
+// cartesiaLanguage converts a language tag such as "en-US" to the ISO 639-1
+// code Cartesia expects
+func cartesiaLanguage(language string) string {
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return strings.ToLower(language)
+}
+
+// Healthy reports whether the provider currently has working connections.
+// Every in-flight stream must have heard from the server within the last two
+// ping intervals; when idle, it reports whether the last stream lost its
+// connection.
+func (p *CartesiaTTSProvider) Healthy() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for health := range p.streams {
+		if !health.Healthy() {
+			return false
+		}
+	}
+	return !p.failed
+}
+
+func (p *CartesiaTTSProvider) trackStream(health *utils.WSHealth) {
+	p.mu.Lock()
+	p.streams[health] = struct{}{}
+	p.mu.Unlock()
+}
+
+func (p *CartesiaTTSProvider) untrackStream(health *utils.WSHealth) {
+	health.Close()
+	p.mu.Lock()
+	delete(p.streams, health)
+	p.mu.Unlock()
+}
+
+func (p *CartesiaTTSProvider) setFailed(failed bool) {
+	p.mu.Lock()
+	p.failed = failed
+	p.mu.Unlock()
+}
+
+// GetSupportedVoices returns the configured voice ID. Cartesia voices are
+// listed through its REST API.
+func (p *CartesiaTTSProvider) GetSupportedVoices() []string {
+	return []string{p.voiceID}
+}
+
+// GetDefaultVoice returns the configured voice ID
+func (p *CartesiaTTSProvider) GetDefaultVoice() string {
+	return p.voiceID
+}
+
+// ValidateConfig validates the provider configuration
+func (p *CartesiaTTSProvider) ValidateConfig() error {
+	if p.apiKey == "" {
+		return fmt.Errorf("Cartesia API key is not set")
+	}
+	if p.voiceID == "" {
+		return fmt.Errorf("Cartesia Voice ID is not set")
+	}
+	return nil
+}
+
+// WebSocket message types
+
+type cartesiaTTSRequest struct {
+	ModelID          string                    `json:"model_id"`
+	Transcript       string                    `json:"transcript"`
+	Voice            cartesiaVoice             `json:"voice"`
+	Language         string                    `json:"language,omitempty"`
+	ContextID        string                    `json:"context_id"`
+	OutputFormat     cartesiaOutputFormat      `json:"output_format"`
+	GenerationConfig *cartesiaGenerationConfig `json:"generation_config,omitempty"`
+}
+
+type cartesiaVoice struct {
+	Mode string `json:"mode"`
+	ID   string `json:"id"`
+}
+
+type cartesiaOutputFormat struct {
+	Container  string `json:"container"`
+	Encoding   string `json:"encoding"`
+	SampleRate int    `json:"sample_rate"`
+}
+
+type cartesiaGenerationConfig struct {
+	Speed float64 `json:"speed,omitempty"`
+}
+
+type cartesiaCancelRequest struct {
+	ContextID string `json:"context_id"`
+	Cancel    bool   `json:"cancel"`
+}
+
+type cartesiaTTSResponse struct {
+	Type       string `json:"type"` // "chunk", "done", "timestamps" or "error"
+	Data       string `json:"data,omitempty"`
+	Done       bool   `json:"done,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	ContextID  string `json:"context_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Ensure CartesiaTTSProvider implements StreamingTTSProvider
+var _ StreamingTTSProvider = (*CartesiaTTSProvider)(nil)
@@ -0,0 +1,185 @@
+package tts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// mockCartesiaServer accepts one connection per test. handle receives the
+// connection and the parsed generation request.
+func mockCartesiaServer(t *testing.T, handle func(conn *websocket.Conn, req cartesiaTTSRequest)) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "test-api-key" || r.Header.Get("Cartesia-Version") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var req cartesiaTTSRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		handle(conn, req)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func cartesiaChunk(contextID string, audio []byte) cartesiaTTSResponse {
+	return cartesiaTTSResponse{
+		Type:       "chunk",
+		Data:       base64.StdEncoding.EncodeToString(audio),
+		StatusCode: 206,
+		ContextID:  contextID,
+	}
+}
+
+func newTestCartesiaProvider(t *testing.T, url string) *CartesiaTTSProvider {
+	t.Helper()
+	provider, err := NewCartesiaTTSProvider(CartesiaTTSConfig{
+		APIKey:     "test-api-key",
+		VoiceID:    "test-voice",
+		SampleRate: 16000,
+		URL:        url,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return provider
+}
+
+func TestNewCartesiaTTSProvider(t *testing.T) {
+	t.Setenv("CARTESIA_API_KEY", "")
+	if _, err := NewCartesiaTTSProvider(CartesiaTTSConfig{VoiceID: "v"}); err == nil {
+		t.Error("Expected error when API key is missing")
+	}
+	if _, err := NewCartesiaTTSProvider(CartesiaTTSConfig{APIKey: "k"}); err == nil {
+		t.Error("Expected error when voice ID is missing")
+	}
+
+	provider, err := NewCartesiaTTSProvider(CartesiaTTSConfig{APIKey: "k", VoiceID: "v"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if provider.Name() != "cartesia" || provider.model != "sonic-3" || provider.sampleRate != 24000 || provider.speed != 1.0 {
+		t.Errorf("Unexpected defaults: %s %s %d %v", provider.Name(), provider.model, provider.sampleRate, provider.speed)
+	}
+}
+
+func TestCartesiaStreamSynthesize_ChunksBeforeDone(t *testing.T) {
+	requests := make(chan cartesiaTTSRequest, 1)
+	release := make(chan struct{})
+	url := mockCartesiaServer(t, func(conn *websocket.Conn, req cartesiaTTSRequest) {
+		requests <- req
+		// First chunk goes out while the rest of the text is still "synthesizing"
+		conn.WriteJSON(cartesiaChunk(req.ContextID, []byte{1, 2, 3, 4}))
+		<-release
+		conn.WriteJSON(cartesiaChunk("other-context", []byte{9, 9}))
+		conn.WriteJSON(cartesiaChunk(req.ContextID, []byte{5, 6}))
+		conn.WriteJSON(cartesiaTTSResponse{Type: "done", Done: true, StatusCode: 200, ContextID: req.ContextID})
+	})
+
+	provider := newTestCartesiaProvider(t, url)
+	audioChan, errChan := provider.StreamSynthesize(context.Background(), &SynthesizeRequest{
+		Text:     "Hello there, how can I help you today?",
+		Language: "en-US",
+		Options:  map[string]interface{}{"speed": 1.2},
+	})
+
+	req := <-requests
+	if req.ModelID != "sonic-3" || req.Voice.ID != "test-voice" || req.Voice.Mode != "id" || req.Language != "en" {
+		t.Errorf("Unexpected request %+v", req)
+	}
+	if req.OutputFormat != (cartesiaOutputFormat{Container: "raw", Encoding: "pcm_s16le", SampleRate: 16000}) {
+		t.Errorf("Unexpected output format %+v", req.OutputFormat)
+	}
+	if req.GenerationConfig == nil || req.GenerationConfig.Speed != 1.2 {
+		t.Errorf("Expected speed 1.2, got %+v", req.GenerationConfig)
+	}
+
+	select {
+	case chunk := <-audioChan:
+		if string(chunk) != string([]byte{1, 2, 3, 4}) {
+			t.Errorf("Unexpected first chunk %v", chunk)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("First chunk should arrive before the generation is done")
+	}
+	close(release)
+
+	var rest []byte
+	for chunk := range audioChan {
+		rest = append(rest, chunk...)
+	}
+	if string(rest) != string([]byte{5, 6}) {
+		t.Errorf("Expected only this context's audio, got %v", rest)
+	}
+	if err := <-errChan; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !provider.Healthy() {
+		t.Error("Expected provider to be healthy after a completed stream")
+	}
+}
+
+func TestCartesiaStreamSynthesize_Cancel(t *testing.T) {
+	cancelled := make(chan cartesiaCancelRequest, 1)
+	url := mockCartesiaServer(t, func(conn *websocket.Conn, req cartesiaTTSRequest) {
+		conn.WriteJSON(cartesiaChunk(req.ContextID, []byte{1, 2}))
+		var msg cartesiaCancelRequest
+		if err := conn.ReadJSON(&msg); err == nil {
+			cancelled <- msg
+		}
+	})
+
+	provider := newTestCartesiaProvider(t, url)
+	ctx, cancel := context.WithCancel(context.Background())
+	audioChan, errChan := provider.StreamSynthesize(ctx, &SynthesizeRequest{Text: "A long answer that gets interrupted"})
+
+	<-audioChan
+	cancel()
+
+	select {
+	case msg := <-cancelled:
+		if !msg.Cancel || msg.ContextID == "" {
+			t.Errorf("Unexpected cancel message %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Server did not receive a cancel message")
+	}
+
+	for range audioChan {
+	}
+	if err := <-errChan; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCartesiaSynthesize_Error(t *testing.T) {
+	url := mockCartesiaServer(t, func(conn *websocket.Conn, req cartesiaTTSRequest) {
+		resp := cartesiaTTSResponse{Type: "error", Done: true, StatusCode: 400, ContextID: req.ContextID, Error: "invalid voice"}
+		data, _ := json.Marshal(resp)
+		conn.WriteMessage(websocket.TextMessage, data)
+	})
+
+	provider := newTestCartesiaProvider(t, url)
+	_, err := provider.Synthesize(context.Background(), &SynthesizeRequest{Text: "Hello"})
+	if err == nil || !strings.Contains(err.Error(), "invalid voice") {
+		t.Errorf("Expected invalid voice error, got %v", err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"net/url"
 
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
 const (
@@ -50,6 +51,14 @@ type ElevenLabsHTTPTTSConfig struct {
 	LatencyOptimization  int     // Optional: Latency optimization level 0-4 (default: 3)
 	Stability            float64 // Optional: Voice stability 0-1 (default: 0.5)
 	SimilarityBoost      float64 // Optional: Similarity boost 0-1 (default: 0.75)
+
+	// Optional: text-to-speech base URLs to fail over between, in priority order
+	// (e.g. "https://api.us.elevenlabs.io/v1/text-to-speech"; default: api.elevenlabs.io)
+	Endpoints []string
+
+	// Optional: extra headers sent on every request (API gateway keys, org IDs,
+	// tracing headers); they override the provider's own headers of the same name
+	Headers map[string]string
 }
 
 // ElevenLabsHTTPTTSProvider implements StreamingTTSProvider using HTTP streaming
@@ -62,6 +71,8 @@ type ElevenLabsHTTPTTSProvider struct {
 	stability           float64
 	similarityBoost     float64
 	httpClient          *http.Client
+	endpoints           *utils.EndpointPool
+	headers             map[string]string
 }
 
 // NewElevenLabsHTTPTTSProvider creates a new ElevenLabs HTTP TTS provider
@@ -98,6 +109,11 @@ func NewElevenLabsHTTPTTSProvider(config ElevenLabsHTTPTTSConfig) (*ElevenLabsHT
 		similarityBoost = 0.75
 	}
 
+	endpoints := utils.NewEndpointPool(config.Endpoints, 0, 0)
+	if len(endpoints.Candidates()) == 0 {
+		endpoints = utils.NewEndpointPool([]string{elevenLabsHTTPEndpoint}, 0, 0)
+	}
+
 	return &ElevenLabsHTTPTTSProvider{
 		apiKey:              config.APIKey,
 		voiceID:             config.VoiceID,
@@ -107,6 +123,8 @@ func NewElevenLabsHTTPTTSProvider(config ElevenLabsHTTPTTSConfig) (*ElevenLabsHT
 		stability:           stability,
 		similarityBoost:     similarityBoost,
 		httpClient:          &http.Client{},
+		endpoints:           endpoints,
+		headers:             config.Headers,
 	}, nil
 }
 
@@ -115,6 +133,18 @@ func (p *ElevenLabsHTTPTTSProvider) Name() string {
 	return "elevenlabs-http"
 }
 
+// Prewarm opens a connection to the text-to-speech endpoint before the first
+// synthesis. It implements pipeline.Prewarmer.
+func (p *ElevenLabsHTTPTTSProvider) Prewarm(ctx context.Context) error {
+	return prewarmHTTP(ctx, p.httpClient, p.endpoints.Candidates(), p.headers)
+}
+
+// Healthy reports whether at least one endpoint is healthy.
+// It implements pipeline.HealthChecker.
+func (p *ElevenLabsHTTPTTSProvider) Healthy() bool {
+	return p.endpoints.Healthy()
+}
+
 // Synthesize converts text to speech (batch mode - collects all audio)
 func (p *ElevenLabsHTTPTTSProvider) Synthesize(ctx context.Context, req *SynthesizeRequest) (*SynthesizeResponse, error) {
 	if err := p.ValidateConfig(); err != nil {
@@ -178,6 +208,17 @@ func (p *ElevenLabsHTTPTTSProvider) StreamSynthesize(ctx context.Context, req *S
 	return audioChan, errChan
 }
 
+// StreamAudioFormat returns the format of StreamSynthesize chunks.
+// It implements StreamFormatProvider.
+func (p *ElevenLabsHTTPTTSProvider) StreamAudioFormat() AudioFormat {
+	return AudioFormat{
+		SampleRate: elevenLabsHTTPSampleRate,
+		Channels:   1,
+		MediaType:  pipeline.AudioMediaTypePCM,
+		Encoding:   "pcm_s16le",
+	}
+}
+
 // doStreamSynthesize performs the actual HTTP streaming request
 func (p *ElevenLabsHTTPTTSProvider) doStreamSynthesize(ctx context.Context, req *SynthesizeRequest, audioChan chan<- []byte) error {
 	// Build URL with voice ID and query parameters
@@ -190,7 +231,7 @@ func (p *ElevenLabsHTTPTTSProvider) doStreamSynthesize(ctx context.Context, req
 	params.Set("output_format", elevenLabsHTTPOutputFormat)
 	params.Set("optimize_streaming_latency", fmt.Sprintf("%d", p.latencyOptimization))
 
-	requestURL := fmt.Sprintf("%s/%s/stream?%s", elevenLabsHTTPEndpoint, voiceID, params.Encode())
+	query := params.Encode()
 
 	// Create request body
 	requestBody := elevenLabsHTTPRequestBody{
@@ -208,19 +249,21 @@ func (p *ElevenLabsHTTPTTSProvider) doStreamSynthesize(ctx context.Context, req
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("xi-api-key", p.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "audio/mpeg") // Server returns binary audio
+	// Send request, failing over between regions
+	resp, err := doWithFailover(ctx, p.httpClient, p.endpoints, p.endpoints.Candidates(), func(endpoint string) (*http.Request, error) {
+		requestURL := fmt.Sprintf("%s/%s/stream?%s", endpoint, voiceID, query)
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
 
-	// Send request
-	resp, err := p.httpClient.Do(httpReq)
+		// Set headers
+		httpReq.Header.Set("xi-api-key", p.apiKey)
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Accept", "audio/mpeg") // Server returns binary audio
+		utils.MergeHeaders(httpReq.Header, p.headers)
+		return httpReq, nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to send HTTP request: %w", err)
 	}
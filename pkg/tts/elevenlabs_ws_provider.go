@@ -21,6 +21,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
 const (
@@ -50,6 +51,10 @@ type ElevenLabsWSTTSConfig struct {
 	VoiceID string  // Required: Voice ID to use
 	Model   string  // Optional: Model ID (default: eleven_turbo_v2_5)
 	Speed   float64 // Optional: Speed 0.7-1.2 (default: 1.0)
+
+	// Optional: extra headers sent on every connection (API gateway keys, org IDs,
+	// tracing headers); they override the provider's own headers of the same name
+	Headers map[string]string
 }
 
 // ElevenLabsWSTTSProvider implements StreamingTTSProvider using WebSocket
@@ -58,8 +63,11 @@ type ElevenLabsWSTTSProvider struct {
 	voiceID string
 	model   string
 	speed   float64
+	headers map[string]string
 
-	mu sync.RWMutex
+	mu      sync.RWMutex
+	streams map[*utils.WSHealth]struct{} // Health of in-flight stream connections
+	failed  bool                         // Last stream lost its connection
 }
 
 // NewElevenLabsWSTTSProvider creates a new ElevenLabs WebSocket TTS provider
@@ -86,6 +94,8 @@ func NewElevenLabsWSTTSProvider(config ElevenLabsWSTTSConfig) (*ElevenLabsWSTTSP
 		voiceID: config.VoiceID,
 		model:   model,
 		speed:   speed,
+		headers: config.Headers,
+		streams: make(map[*utils.WSHealth]struct{}),
 	}, nil
 }
 
@@ -181,14 +191,20 @@ func (p *ElevenLabsWSTTSProvider) doStreamSynthesize(ctx context.Context, req *S
 	// Set headers
 	headers := http.Header{}
 	headers.Set("xi-api-key", p.apiKey)
+	utils.MergeHeaders(headers, p.headers)
 
 	// Connect
 	conn, _, err := dialer.DialContext(ctx, wsURL, headers)
 	if err != nil {
+		p.setFailed(true)
 		return fmt.Errorf("failed to connect to ElevenLabs WebSocket: %w", err)
 	}
 	defer conn.Close()
 
+	health := utils.NewWSHealth(conn, utils.DefaultWSPingInterval)
+	p.trackStream(health)
+	defer p.untrackStream(health)
+
 	log.Printf("[ElevenLabs-TTS] WebSocket connected")
 
 	// Track connection state
@@ -199,7 +215,7 @@ func (p *ElevenLabsWSTTSProvider) doStreamSynthesize(ctx context.Context, req *S
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		p.readLoop(ctx, conn, audioChan, &closed)
+		p.readLoop(ctx, conn, health, audioChan, &closed)
 	}()
 
 	// Send initialization message (BOS - Beginning of Stream)
@@ -253,7 +269,9 @@ func (p *ElevenLabsWSTTSProvider) doStreamSynthesize(ctx context.Context, req *S
 }
 
 // readLoop reads audio chunks from WebSocket
-func (p *ElevenLabsWSTTSProvider) readLoop(ctx context.Context, conn *websocket.Conn, audioChan chan<- []byte, closed *atomic.Bool) {
+func (p *ElevenLabsWSTTSProvider) readLoop(ctx context.Context, conn *websocket.Conn, health *utils.WSHealth, audioChan chan<- []byte, closed *atomic.Bool) {
+	defer health.Close()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -265,9 +283,11 @@ func (p *ElevenLabsWSTTSProvider) readLoop(ctx context.Context, conn *websocket.
 		if err != nil {
 			if !closed.Load() && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 				log.Printf("[ElevenLabs-TTS] WebSocket read error: %v", err)
+				p.setFailed(true)
 			}
 			return
 		}
+		health.Touch()
 
 		// Parse response
 		var resp elevenlabsTTSResponse
@@ -279,6 +299,7 @@ func (p *ElevenLabsWSTTSProvider) readLoop(ctx context.Context, conn *websocket.
 		// Check for final message
 		if resp.IsFinal {
 			log.Printf("[ElevenLabs-TTS] Received final message")
+			p.setFailed(false)
 			return
 		}
 
@@ -299,6 +320,41 @@ func (p *ElevenLabsWSTTSProvider) readLoop(ctx context.Context, conn *websocket.
 	}
 }
 
+// Healthy reports whether the provider currently has working connections.
+// Every in-flight stream must have heard from the server within the last two
+// ping intervals; when idle, it reports whether the last stream lost its
+// connection.
+func (p *ElevenLabsWSTTSProvider) Healthy() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for health := range p.streams {
+		if !health.Healthy() {
+			return false
+		}
+	}
+	return !p.failed
+}
+
+func (p *ElevenLabsWSTTSProvider) trackStream(health *utils.WSHealth) {
+	p.mu.Lock()
+	p.streams[health] = struct{}{}
+	p.mu.Unlock()
+}
+
+func (p *ElevenLabsWSTTSProvider) untrackStream(health *utils.WSHealth) {
+	health.Close()
+	p.mu.Lock()
+	delete(p.streams, health)
+	p.mu.Unlock()
+}
+
+func (p *ElevenLabsWSTTSProvider) setFailed(failed bool) {
+	p.mu.Lock()
+	p.failed = failed
+	p.mu.Unlock()
+}
+
 // GetSupportedVoices returns a list of known voice IDs
 func (p *ElevenLabsWSTTSProvider) GetSupportedVoices() []string {
 	return elevenLabsVoices
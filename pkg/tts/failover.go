@@ -0,0 +1,69 @@
+// Package tts provides streaming text-to-speech providers.
+//
+// Endpoint failover for the HTTP providers (OpenAI, ElevenLabs HTTP). When a
+// provider is configured with several equivalent endpoints (e.g. regions), a
+// request that cannot connect, is rate limited (429) or gets a 5xx is retried
+// on the next endpoint. The endpoint health tracked by utils.EndpointPool is
+// reported through the provider's Healthy().
+package tts
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+// doWithFailover sends the request built for each endpoint in turn, moving on to
+// the next one when an endpoint is unreachable, rate limited (429) or returns a 5xx.
+// Outcomes are reported to pool (which may be nil).
+//
+// Non-retryable responses, and the response from the last endpoint, are returned
+// to the caller unchanged so it can check the status code as usual.
+func doWithFailover(ctx context.Context, client *http.Client, pool *utils.EndpointPool, endpoints []string, newRequest func(endpoint string) (*http.Request, error)) (*http.Response, error) {
+	var lastErr error
+	for i, endpoint := range endpoints {
+		last := i == len(endpoints)-1
+
+		req, err := newRequest(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			lastErr = err
+			reportEndpointFailure(pool, endpoint, err.Error())
+			continue
+		}
+
+		if !utils.IsRetryableStatus(resp.StatusCode) {
+			if pool != nil && resp.StatusCode < http.StatusBadRequest {
+				pool.ReportSuccess(endpoint)
+			}
+			return resp, nil
+		}
+
+		reportEndpointFailure(pool, endpoint, resp.Status)
+		if last {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return nil, lastErr
+}
+
+func reportEndpointFailure(pool *utils.EndpointPool, endpoint, reason string) {
+	if pool == nil {
+		return
+	}
+	if pool.ReportFailure(endpoint) {
+		log.Printf("[TTS] Endpoint %s marked unhealthy: %s", endpoint, reason)
+	}
+}
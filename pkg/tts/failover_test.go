@@ -0,0 +1,106 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newStatusServer returns a server that always responds with status and body, counting hits
+func newStatusServer(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestOpenAITTSProvider_Failover(t *testing.T) {
+	down, downHits := newStatusServer(t, http.StatusServiceUnavailable, "overloaded")
+	up, upHits := newStatusServer(t, http.StatusOK, "audio")
+
+	provider := NewOpenAITTSProvider("test-key")
+	provider.SetBaseURLs([]string{down.URL + "/v1", up.URL + "/v1"})
+
+	for i := 0; i < 3; i++ {
+		resp, err := provider.Synthesize(context.Background(), &SynthesizeRequest{Text: "hello"})
+		if err != nil {
+			t.Fatalf("Synthesize() error = %v", err)
+		}
+		if string(resp.AudioData) != "audio" {
+			t.Fatalf("AudioData = %q, want %q", resp.AudioData, "audio")
+		}
+	}
+
+	// The failing endpoint is skipped once it has been marked unhealthy
+	if got := downHits.Load(); got != 2 {
+		t.Errorf("unhealthy endpoint hit %d times, want 2", got)
+	}
+	if got := upHits.Load(); got != 3 {
+		t.Errorf("healthy endpoint hit %d times, want 3", got)
+	}
+	if !provider.Healthy() {
+		t.Error("Healthy() = false with one endpoint up")
+	}
+}
+
+func TestOpenAITTSProvider_NoFailoverOnClientError(t *testing.T) {
+	bad, _ := newStatusServer(t, http.StatusUnauthorized, "invalid key")
+	up, upHits := newStatusServer(t, http.StatusOK, "audio")
+
+	provider := NewOpenAITTSProvider("test-key")
+	provider.SetBaseURLs([]string{bad.URL, up.URL})
+
+	_, err := provider.Synthesize(context.Background(), &SynthesizeRequest{Text: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Synthesize() error = %v, want 401", err)
+	}
+	if upHits.Load() != 0 {
+		t.Error("client errors should not fail over")
+	}
+}
+
+func TestOpenAITTSProvider_AllEndpointsDown(t *testing.T) {
+	a, _ := newStatusServer(t, http.StatusTooManyRequests, "slow down")
+	b, _ := newStatusServer(t, http.StatusBadGateway, "bad gateway")
+
+	provider := NewOpenAITTSProvider("test-key")
+	provider.SetBaseURLs([]string{a.URL, b.URL})
+
+	_, err := provider.Synthesize(context.Background(), &SynthesizeRequest{Text: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("Synthesize() error = %v, want the last endpoint's 502", err)
+	}
+}
+
+func TestElevenLabsHTTPTTSProvider_Failover(t *testing.T) {
+	up, upHits := newStatusServer(t, http.StatusOK, "pcm")
+
+	provider, err := NewElevenLabsHTTPTTSProvider(ElevenLabsHTTPTTSConfig{
+		APIKey:    "test-api-key",
+		VoiceID:   "test-voice-id",
+		Endpoints: []string{"http://127.0.0.1:1/v1/text-to-speech", up.URL + "/v1/text-to-speech"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	resp, err := provider.Synthesize(context.Background(), &SynthesizeRequest{Text: "hello"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if string(resp.AudioData) != "pcm" {
+		t.Fatalf("AudioData = %q, want %q", resp.AudioData, "pcm")
+	}
+	if upHits.Load() != 1 {
+		t.Errorf("fallback endpoint hit %d times, want 1", upHits.Load())
+	}
+}
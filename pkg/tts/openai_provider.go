@@ -31,6 +31,7 @@ import (
 	"strings"
 
 	"github.com/realtime-ai/realtime-ai/pkg/pipeline"
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
 )
 
 const (
@@ -65,6 +66,12 @@ type OpenAITTSProvider struct {
 	model        string
 	instructions string // Voice style instructions
 	httpClient   *http.Client
+
+	// endpoints is set by SetBaseURLs; nil means the single default endpoint
+	endpoints *utils.EndpointPool
+
+	// headers are extra headers set by SetHeaders
+	headers map[string]string
 }
 
 // OpenAITTSRequest represents the request payload for OpenAI TTS API
@@ -118,6 +125,64 @@ func (p *OpenAITTSProvider) GetInstructions() string {
 	return p.instructions
 }
 
+// SetHeaders sets extra headers sent on every request (API gateway keys,
+// org IDs, tracing headers). They override the provider's own headers of the
+// same name.
+func (p *OpenAITTSProvider) SetHeaders(headers map[string]string) {
+	p.headers = headers
+}
+
+// SetBaseURLs configures OpenAI-compatible base URLs (e.g. "https://api.openai.com/v1")
+// to fail over between. They are tried in order; an endpoint that keeps failing
+// or rate limiting is skipped until it recovers.
+func (p *OpenAITTSProvider) SetBaseURLs(baseURLs []string) {
+	urls := make([]string, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		if baseURL != "" {
+			urls = append(urls, openAISpeechURL(baseURL))
+		}
+	}
+	if len(urls) == 0 {
+		p.endpoints = nil
+		return
+	}
+	p.endpoints = utils.NewEndpointPool(urls, 0, 0)
+}
+
+// Healthy reports whether at least one configured endpoint is healthy.
+// It implements pipeline.HealthChecker.
+func (p *OpenAITTSProvider) Healthy() bool {
+	if p.endpoints == nil {
+		return true
+	}
+	return p.endpoints.Healthy()
+}
+
+// Prewarm opens a connection to the speech endpoint before the first
+// synthesis. It implements pipeline.Prewarmer.
+func (p *OpenAITTSProvider) Prewarm(ctx context.Context) error {
+	return prewarmHTTP(ctx, p.httpClient, p.speechEndpoints(), p.headers)
+}
+
+// speechEndpoints returns the speech URLs to try for a request, in order
+func (p *OpenAITTSProvider) speechEndpoints() []string {
+	if p.endpoints != nil {
+		return p.endpoints.Candidates()
+	}
+	if envBaseURL := os.Getenv("OPENAI_BASE_URL"); envBaseURL != "" {
+		return []string{openAISpeechURL(envBaseURL)}
+	}
+	return []string{openAITTSEndpoint}
+}
+
+// openAISpeechURL returns the audio/speech URL under an OpenAI base URL
+func openAISpeechURL(baseURL string) string {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + "audio/speech"
+}
+
 // Synthesize converts text to speech using OpenAI TTS API (non-streaming)
 func (p *OpenAITTSProvider) Synthesize(ctx context.Context, req *SynthesizeRequest) (*SynthesizeResponse, error) {
 	if err := p.ValidateConfig(); err != nil {
@@ -168,28 +233,19 @@ func (p *OpenAITTSProvider) Synthesize(ctx context.Context, req *SynthesizeReque
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Determine base URL
-	baseURL := openAITTSEndpoint
-	if envBaseURL := os.Getenv("OPENAI_BASE_URL"); envBaseURL != "" {
-		baseURL = envBaseURL
-		if baseURL[len(baseURL)-1] != '/' {
-			baseURL += "/"
+	// Send request, failing over between endpoints
+	resp, err := doWithFailover(ctx, p.httpClient, p.endpoints, p.speechEndpoints(), func(endpoint string) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
-		baseURL += "audio/speech"
-	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL, bytes.NewReader(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Send request
-	resp, err := p.httpClient.Do(httpReq)
+		// Set headers
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+		utils.MergeHeaders(httpReq.Header, p.headers)
+		return httpReq, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -284,31 +340,22 @@ func (p *OpenAITTSProvider) doStreamSynthesize(ctx context.Context, req *Synthes
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Determine base URL
-	baseURL := openAITTSEndpoint
-	if envBaseURL := os.Getenv("OPENAI_BASE_URL"); envBaseURL != "" {
-		baseURL = envBaseURL
-		if baseURL[len(baseURL)-1] != '/' {
-			baseURL += "/"
-		}
-		baseURL += "audio/speech"
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL, bytes.NewReader(payloadBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-	httpReq.Header.Set("Accept", "text/event-stream")
-
 	log.Printf("[OpenAI-TTS] Starting SSE stream with voice: %s", voice)
 
-	// Send request
-	resp, err := p.httpClient.Do(httpReq)
+	// Send request, failing over between endpoints
+	resp, err := doWithFailover(ctx, p.httpClient, p.endpoints, p.speechEndpoints(), func(endpoint string) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+		httpReq.Header.Set("Accept", "text/event-stream")
+		utils.MergeHeaders(httpReq.Header, p.headers)
+		return httpReq, nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
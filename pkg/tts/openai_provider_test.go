@@ -2,6 +2,8 @@ package tts
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -122,6 +124,61 @@ func TestOpenAITTSProvider_Instructions(t *testing.T) {
 	}
 }
 
+func TestOpenAITTSProvider_Headers(t *testing.T) {
+	received := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header
+		w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	provider := NewOpenAITTSProvider("test-key")
+	provider.SetBaseURLs([]string{srv.URL + "/v1"})
+	provider.SetHeaders(map[string]string{
+		"X-Gateway-Key":       "gateway-secret",
+		"OpenAI-Organization": "org_1",
+	})
+
+	if _, err := provider.Synthesize(context.Background(), &SynthesizeRequest{Text: "hello"}); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	got := <-received
+	if got.Get("X-Gateway-Key") != "gateway-secret" || got.Get("OpenAI-Organization") != "org_1" {
+		t.Errorf("Custom headers not sent, got %v", got)
+	}
+	if got.Get("Authorization") != "Bearer test-key" {
+		t.Errorf("Authorization = %q, want the provider's own header", got.Get("Authorization"))
+	}
+}
+
+func TestOpenAITTSProvider_Prewarm(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer srv.Close()
+
+	provider := NewOpenAITTSProvider("test-key")
+	provider.SetBaseURLs([]string{srv.URL + "/v1"})
+	provider.SetHeaders(map[string]string{"X-Gateway-Key": "gateway-secret"})
+
+	if err := provider.Prewarm(context.Background()); err != nil {
+		t.Fatalf("Prewarm() error = %v, want nil for any HTTP response", err)
+	}
+
+	got := <-received
+	if got.Method != http.MethodHead || got.URL.Path != "/v1/audio/speech" {
+		t.Errorf("Prewarm sent %s %s, want HEAD /v1/audio/speech", got.Method, got.URL.Path)
+	}
+	if got.Header.Get("X-Gateway-Key") != "gateway-secret" {
+		t.Errorf("Custom headers not sent, got %v", got.Header)
+	}
+
+	var _ pipeline.Prewarmer = provider
+}
+
 func TestOpenAITTSProvider_GetAudioFormat(t *testing.T) {
 	provider := NewOpenAITTSProvider("test-key")
 
@@ -0,0 +1,43 @@
+// Package tts provides streaming text-to-speech providers.
+//
+// Connection prewarming for the HTTP providers (OpenAI, ElevenLabs HTTP),
+// which implement pipeline.Prewarmer with prewarmHTTP. The first synthesis of
+// a call otherwise pays for DNS, TCP and TLS before any audio is produced;
+// with Pipeline.SetPrewarmOnConnect that cost is paid while the user is
+// still connecting.
+package tts
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/realtime-ai/realtime-ai/pkg/utils"
+)
+
+// prewarmHTTP sends a HEAD request to the first reachable endpoint so the
+// TLS handshake is done before the first synthesis. The status code is
+// ignored: any response leaves a warm connection in client's pool.
+func prewarmHTTP(ctx context.Context, client *http.Client, endpoints []string, headers map[string]string) error {
+	var lastErr error
+	for _, endpoint := range endpoints {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		utils.MergeHeaders(req.Header, headers)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			lastErr = err
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil
+	}
+	return lastErr
+}
@@ -59,3 +59,9 @@ type StreamingTTSProvider interface {
 	// Returns a channel that receives audio chunks and an error channel
 	StreamSynthesize(ctx context.Context, req *SynthesizeRequest) (<-chan []byte, <-chan error)
 }
+
+// StreamFormatProvider is implemented by streaming providers whose
+// StreamSynthesize chunks have a fixed format, known before the first chunk
+type StreamFormatProvider interface {
+	StreamAudioFormat() AudioFormat
+}
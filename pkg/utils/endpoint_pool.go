@@ -0,0 +1,169 @@
+// Package utils provides shared utilities for providers and connections.
+//
+// EndpointPool 在服务商的多个等价端点（如不同区域）之间做故障转移。
+// 连续失败的端点进入冷却期并排到候选列表末尾，冷却结束或请求成功后恢复。
+//
+// 使用示例:
+//
+//	pool := utils.NewEndpointPool([]string{primaryURL, backupURL}, 0, 0)
+//	for _, url := range pool.Candidates() {
+//		resp, err := client.Do(newRequest(url))
+//		if err != nil || utils.IsRetryableStatus(resp.StatusCode) {
+//			pool.ReportFailure(url)
+//			continue
+//		}
+//		pool.ReportSuccess(url)
+//		break
+//	}
+package utils
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEndpointFailThreshold 连续失败多少次后将端点标记为不健康
+	DefaultEndpointFailThreshold = 2
+	// DefaultEndpointCooldown 不健康端点的冷却时间，之后重新参与尝试
+	DefaultEndpointCooldown = 30 * time.Second
+)
+
+// EndpointPool 在多个等价端点（如不同区域）之间做故障转移。
+//
+// 调用方按 Candidates 返回的顺序逐个尝试，并用 ReportSuccess/ReportFailure 反馈结果。
+// 端点连续失败达到阈值后进入冷却期，冷却期内排到候选列表末尾；
+// 冷却结束或任意一次成功后恢复健康。所有端点都不健康时仍会全部尝试，不会直接放弃。
+type EndpointPool struct {
+	mu            sync.Mutex
+	endpoints     []*endpointState
+	failThreshold int
+	cooldown      time.Duration
+
+	now func() time.Time // 便于测试替换
+}
+
+type endpointState struct {
+	url       string
+	failures  int       // 连续失败次数
+	downUntil time.Time // 冷却结束时间
+}
+
+// NewEndpointPool 创建端点池，endpoints 的顺序即优先级。
+// failThreshold <= 0 时使用 DefaultEndpointFailThreshold，cooldown <= 0 时使用 DefaultEndpointCooldown。
+func NewEndpointPool(endpoints []string, failThreshold int, cooldown time.Duration) *EndpointPool {
+	if failThreshold <= 0 {
+		failThreshold = DefaultEndpointFailThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultEndpointCooldown
+	}
+
+	p := &EndpointPool{
+		failThreshold: failThreshold,
+		cooldown:      cooldown,
+		now:           time.Now,
+	}
+	seen := make(map[string]bool, len(endpoints))
+	for _, url := range endpoints {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		p.endpoints = append(p.endpoints, &endpointState{url: url})
+	}
+	return p
+}
+
+// Candidates 返回本次请求应依次尝试的端点：
+// 健康端点按配置顺序在前，不健康端点按冷却结束时间排在后面
+func (p *EndpointPool) Candidates() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var healthy, down []*endpointState
+	for _, ep := range p.endpoints {
+		if now.Before(ep.downUntil) {
+			down = append(down, ep)
+		} else {
+			healthy = append(healthy, ep)
+		}
+	}
+	sort.SliceStable(down, func(i, j int) bool {
+		return down[i].downUntil.Before(down[j].downUntil)
+	})
+
+	urls := make([]string, 0, len(p.endpoints))
+	for _, ep := range append(healthy, down...) {
+		urls = append(urls, ep.url)
+	}
+	return urls
+}
+
+// ReportSuccess 记录一次成功，端点立即恢复健康
+func (p *EndpointPool) ReportSuccess(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ep := p.find(url); ep != nil {
+		ep.failures = 0
+		ep.downUntil = time.Time{}
+	}
+}
+
+// ReportFailure 记录一次失败（连接错误、限流或服务端错误），返回端点是否因此被标记为不健康
+func (p *EndpointPool) ReportFailure(url string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep := p.find(url)
+	if ep == nil {
+		return false
+	}
+	ep.failures++
+	if ep.failures >= p.failThreshold {
+		ep.downUntil = p.now().Add(p.cooldown)
+		return true
+	}
+	return false
+}
+
+// Status 返回每个端点当前是否健康
+func (p *EndpointPool) Status() map[string]bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	status := make(map[string]bool, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		status[ep.url] = !now.Before(ep.downUntil)
+	}
+	return status
+}
+
+// Healthy 报告是否至少有一个端点健康
+func (p *EndpointPool) Healthy() bool {
+	for _, ok := range p.Status() {
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *EndpointPool) find(url string) *endpointState {
+	for _, ep := range p.endpoints {
+		if ep.url == url {
+			return ep
+		}
+	}
+	return nil
+}
+
+// IsRetryableStatus 判断 HTTP 状态码是否应换一个端点重试：限流（429）和服务端错误（5xx）
+func IsRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
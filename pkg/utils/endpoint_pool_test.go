@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEndpointPoolFailover(t *testing.T) {
+	now := time.Unix(1000, 0)
+	p := NewEndpointPool([]string{"a", "b", "c", "a", ""}, 2, 10*time.Second)
+	p.now = func() time.Time { return now }
+
+	if got := p.Candidates(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("Candidates() = %v, want [a b c]", got)
+	}
+
+	// 一次失败不足以标记不健康
+	if p.ReportFailure("a") {
+		t.Fatal("endpoint marked unhealthy after a single failure")
+	}
+	if got := p.Candidates(); got[0] != "a" {
+		t.Fatalf("Candidates() = %v, want a first", got)
+	}
+
+	if !p.ReportFailure("a") {
+		t.Fatal("endpoint not marked unhealthy at threshold")
+	}
+	if got := p.Candidates(); !reflect.DeepEqual(got, []string{"b", "c", "a"}) {
+		t.Fatalf("Candidates() = %v, want [b c a]", got)
+	}
+	if p.Status()["a"] || !p.Healthy() {
+		t.Fatalf("Status() = %v", p.Status())
+	}
+
+	// 冷却期结束后恢复原有顺序
+	now = now.Add(11 * time.Second)
+	if got := p.Candidates(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("Candidates() after cooldown = %v, want [a b c]", got)
+	}
+}
+
+func TestEndpointPoolAllDown(t *testing.T) {
+	now := time.Unix(1000, 0)
+	p := NewEndpointPool([]string{"a", "b"}, 1, 10*time.Second)
+	p.now = func() time.Time { return now }
+
+	p.ReportFailure("a")
+	now = now.Add(time.Second)
+	p.ReportFailure("b")
+
+	// 全部不健康时仍然返回所有端点，最早恢复的在前
+	if got := p.Candidates(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("Candidates() = %v, want [a b]", got)
+	}
+	if p.Healthy() {
+		t.Fatal("Healthy() = true with all endpoints down")
+	}
+
+	p.ReportSuccess("b")
+	if got := p.Candidates(); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Fatalf("Candidates() = %v, want [b a]", got)
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusBadRequest:          false,
+		http.StatusUnauthorized:        false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	}
+	for code, want := range cases {
+		if got := IsRetryableStatus(code); got != want {
+			t.Errorf("IsRetryableStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
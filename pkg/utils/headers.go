@@ -0,0 +1,62 @@
+// Package utils provides shared utilities for providers and connections.
+//
+// 自定义请求头的支持，供各 STT/LLM/TTS Provider 的 Headers 配置共用。
+// 经 API 网关或代理访问服务商时，通常需要额外的网关密钥、组织 ID 或追踪头。
+//
+// 主要功能:
+//   - MergeHeaders: 合并到 HTTP 请求或 WebSocket 握手的请求头
+//   - HeaderTransport / NewHeaderClient: 包装 http.Client，用于不能逐个请求设置请求头的 SDK
+//
+// 使用示例:
+//
+//	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+//	    APIKey:     apiKey,
+//	    HTTPClient: utils.NewHeaderClient(map[string]string{"X-Gateway-Key": key}),
+//	})
+package utils
+
+import "net/http"
+
+// MergeHeaders 把自定义请求头（API 网关密钥、组织 ID、追踪头等）合并到 h 中。
+// 自定义头在 Provider 自己的请求头之后设置，同名时覆盖（如网关要求不同的 Authorization）。
+// h 为 nil 时返回新建的 Header，便于直接用于 WebSocket 握手。
+func MergeHeaders(h http.Header, headers map[string]string) http.Header {
+	if h == nil {
+		h = make(http.Header, len(headers))
+	}
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	return h
+}
+
+// HeaderTransport 在每个请求上添加自定义请求头的 http.RoundTripper，
+// 用于无法逐个请求设置请求头的 SDK 客户端
+type HeaderTransport struct {
+	Base    http.RoundTripper // 实际发送请求的 Transport，nil 时使用 http.DefaultTransport
+	Headers map[string]string // 创建后视为只读
+}
+
+// RoundTrip 实现 http.RoundTripper，不修改调用方的原始请求
+func (t *HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if len(t.Headers) == 0 {
+		return base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	MergeHeaders(req.Header, t.Headers)
+	return base.RoundTrip(req)
+}
+
+// NewHeaderClient 返回在每个请求上添加 headers 的 http.Client；headers 为空时返回 nil，
+// 调用方此时应沿用 SDK 的默认客户端
+func NewHeaderClient(headers map[string]string) *http.Client {
+	if len(headers) == 0 {
+		return nil
+	}
+	return &http.Client{Transport: &HeaderTransport{Headers: headers}}
+}
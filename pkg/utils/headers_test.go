@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMergeHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer provider-key")
+	h.Set("Content-Type", "application/json")
+
+	MergeHeaders(h, map[string]string{
+		"authorization":   "Bearer gateway-key", // 同名头覆盖 Provider 的默认值
+		"X-Org-ID":        "org_1",
+		"X-Request-Trace": "abc",
+	})
+	if got := h.Get("Authorization"); got != "Bearer gateway-key" {
+		t.Errorf("Authorization = %q, want the custom value", got)
+	}
+	if got := h.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want it untouched", got)
+	}
+	if got := h.Get("X-Org-Id"); got != "org_1" {
+		t.Errorf("X-Org-ID = %q, want org_1", got)
+	}
+
+	if got := MergeHeaders(nil, map[string]string{"X-Key": "v"}).Get("X-Key"); got != "v" {
+		t.Errorf("MergeHeaders(nil) X-Key = %q, want v", got)
+	}
+}
+
+func TestHeaderClient(t *testing.T) {
+	if NewHeaderClient(nil) != nil {
+		t.Fatal("NewHeaderClient(nil) should return nil so the SDK default is used")
+	}
+
+	received := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Own", "1")
+
+	resp, err := NewHeaderClient(map[string]string{"X-Gateway-Key": "secret"}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got := <-received
+	if got.Get("X-Gateway-Key") != "secret" || got.Get("X-Own") != "1" {
+		t.Errorf("server got headers %v, want both the custom and the request's own", got)
+	}
+	// 调用方的原始请求不被修改
+	if req.Header.Get("X-Gateway-Key") != "" {
+		t.Error("HeaderTransport modified the caller's request")
+	}
+}
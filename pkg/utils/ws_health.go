@@ -0,0 +1,110 @@
+// Package utils provides shared utilities for providers and connections.
+//
+// WSHealth 检测服务商 WebSocket 连接是否仍然存活，供各 Provider 实现 Healthy()。
+// 周期性发送 ping，超过两个 ping 间隔收不到任何数据即视为不健康，
+// 不必等待读错误就能发现静默死掉的连接。
+//
+// 使用示例:
+//
+//	health := utils.NewWSHealth(conn, 0)
+//	defer health.Close()
+//	// 读循环中每收到一条消息
+//	health.Touch()
+//	// 其他协程中
+//	alive := health.Healthy()
+package utils
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultWSPingInterval 是 WSHealth 默认的 ping 间隔
+const DefaultWSPingInterval = 10 * time.Second
+
+// WSHealth 跟踪一个 WebSocket 连接的存活状态。
+//
+// 它周期性发送 ping，并把收到的任何消息或 pong 视为对端仍然存活。
+// 超过两个 ping 间隔没有收到任何数据时，连接被视为不健康——
+// 这样可以发现 "已连接但收不到数据" 的静默死连接，而不必等待一个可能永远不会到来的读错误。
+type WSHealth struct {
+	conn     *websocket.Conn
+	interval time.Duration
+	lastSeen atomic.Int64 // UnixNano
+	dead     atomic.Bool
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewWSHealth 为 conn 创建存活检测并启动 ping 协程。
+// 它会替换 conn 的 pong handler，因此必须在读循环开始之前调用。
+// interval <= 0 时使用 DefaultWSPingInterval。
+func NewWSHealth(conn *websocket.Conn, interval time.Duration) *WSHealth {
+	if interval <= 0 {
+		interval = DefaultWSPingInterval
+	}
+	h := &WSHealth{
+		conn:     conn,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+	h.Touch()
+
+	conn.SetPongHandler(func(string) error {
+		h.Touch()
+		return nil
+	})
+
+	go h.pingLoop()
+	return h
+}
+
+// Touch 记录一次来自对端的活动，读循环每收到一条消息时调用
+func (h *WSHealth) Touch() {
+	h.lastSeen.Store(time.Now().UnixNano())
+}
+
+// LastSeen 返回最近一次收到对端数据的时间
+func (h *WSHealth) LastSeen() time.Time {
+	return time.Unix(0, h.lastSeen.Load())
+}
+
+// Healthy 报告连接是否仍然存活：未被关闭，且最近两个 ping 间隔内收到过数据
+func (h *WSHealth) Healthy() bool {
+	if h == nil || h.dead.Load() {
+		return false
+	}
+	return time.Since(h.LastSeen()) <= 2*h.interval
+}
+
+// Close 将连接标记为已断开并停止 ping 协程，不会关闭底层连接。
+// 读循环退出时应调用它。
+func (h *WSHealth) Close() {
+	if h == nil {
+		return
+	}
+	h.dead.Store(true)
+	h.once.Do(func() { close(h.stop) })
+}
+
+func (h *WSHealth) pingLoop() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			// WriteControl 可以与其他写操作并发调用
+			deadline := time.Now().Add(h.interval)
+			if err := h.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				h.Close()
+				return
+			}
+		}
+	}
+}
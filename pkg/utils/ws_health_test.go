@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newWSPair 启动一个 WebSocket 服务端，返回客户端连接
+// respond 为 false 时服务端不读取数据，因此也不会回复 pong
+func newWSPair(t *testing.T, respond bool) *websocket.Conn {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if !respond {
+			<-done
+			return
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readUntilClosed 模拟 provider 的读循环
+func readUntilClosed(conn *websocket.Conn, health *WSHealth) {
+	defer health.Close()
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		health.Touch()
+	}
+}
+
+func TestWSHealthPong(t *testing.T) {
+	conn := newWSPair(t, true)
+	health := NewWSHealth(conn, 50*time.Millisecond)
+	go readUntilClosed(conn, health)
+
+	time.Sleep(300 * time.Millisecond)
+	if !health.Healthy() {
+		t.Errorf("Expected connection answering pings to be healthy, last seen %v ago", time.Since(health.LastSeen()))
+	}
+
+	conn.Close()
+	time.Sleep(50 * time.Millisecond)
+	if health.Healthy() {
+		t.Error("Expected closed connection to be unhealthy")
+	}
+}
+
+func TestWSHealthSilentPeer(t *testing.T) {
+	conn := newWSPair(t, false)
+	health := NewWSHealth(conn, 50*time.Millisecond)
+	go readUntilClosed(conn, health)
+
+	if !health.Healthy() {
+		t.Error("Expected new connection to be healthy")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if health.Healthy() {
+		t.Error("Expected connection without pongs to be unhealthy")
+	}
+}
+
+func TestWSHealthNil(t *testing.T) {
+	var health *WSHealth
+	if health.Healthy() {
+		t.Error("Expected nil WSHealth to be unhealthy")
+	}
+	health.Close()
+}